@@ -9,10 +9,15 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
+	"expvar"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -20,10 +25,14 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/flare"
+	"github.com/DataDog/datadog-agent/pkg/logs"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -41,8 +50,10 @@ func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/hostname", getHostname).Methods("GET")
 	r.HandleFunc("/flare", makeFlare).Methods("POST")
 	r.HandleFunc("/stop", stopAgent).Methods("POST")
+	r.HandleFunc("/flush", flushAgent).Methods("POST")
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/dogstatsd-stats", getDogstatsdStats).Methods("GET")
+	r.HandleFunc("/stream-logs", streamLogs).Methods("POST")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
@@ -51,8 +62,14 @@ func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
 	r.HandleFunc("/config", getRuntimeConfig).Methods("GET")
+	r.HandleFunc("/config/list-runtime", getRuntimeSettings).Methods("GET")
+	r.HandleFunc("/config/{setting}", getRuntimeSetting).Methods("GET")
+	r.HandleFunc("/config/{setting}", setRuntimeSetting).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
+	r.HandleFunc("/secrets/refresh", refreshSecrets).Methods("POST")
+	r.HandleFunc("/api-keys/refresh", refreshAPIKeys).Methods("POST")
+	r.HandleFunc("/check-runners", setCheckRunners).Methods("POST")
 }
 
 func stopAgent(w http.ResponseWriter, r *http.Request) {
@@ -62,6 +79,42 @@ func stopAgent(w http.ResponseWriter, r *http.Request) {
 	w.Write(j)
 }
 
+// flushAgent flushes the metrics and the logs collected so far, it is only
+// available in serverless mode where the agent can be frozen after an invocation.
+func flushAgent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !config.Datadog.GetBool("serverless.enabled") {
+		body, _ := json.Marshal(map[string]string{
+			"error":      "The flush is only available in serverless mode",
+			"error_type": "not enabled",
+		})
+		w.WriteHeader(400)
+		w.Write(body)
+		return
+	}
+
+	timeout := time.Duration(config.Datadog.GetInt("serverless.flush_timeout")) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	if agg := aggregator.GetDefaultAggregator(); agg != nil {
+		if err := agg.ForceFlush(ctx); err != nil {
+			log.Errorf("Unable to flush the metrics: %s", err)
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), 500)
+			return
+		}
+	}
+	if err := logs.Flush(ctx); err != nil {
+		log.Errorf("Unable to flush the logs: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	j, _ := json.Marshal("")
+	w.Write(j)
+}
+
 func getVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	av, _ := version.New(version.AgentVersion, version.Commit)
@@ -205,6 +258,57 @@ func getDogstatsdStats(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+// streamLogs streams the messages processed by logs-agent matching the
+// filters sent in the body of the request. The stream ends before the write
+// timeout of the server, clients reconnect to keep on streaming.
+func streamLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var filters diagnostic.Filters
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil && len(body) > 0 {
+		err = json.Unmarshal(body, &filters)
+	}
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	receiver := logs.GetMessageReceiver()
+	if !ok || receiver == nil {
+		body, _ := json.Marshal(map[string]string{"error": "The logs agent is not running"})
+		http.Error(w, string(body), 503)
+		return
+	}
+
+	lines, unsubscribe := receiver.Subscribe(filters)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.WriteHeader(200)
+	flusher.Flush()
+
+	window := config.Datadog.GetDuration("server_timeout")*time.Second - time.Second
+	if window < time.Second {
+		window = time.Second
+	}
+	end := time.After(window)
+	for {
+		select {
+		case line := <-lines:
+			w.Write([]byte(line))
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-end:
+			return
+		}
+	}
+}
+
 func getFormattedStatus(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for the formatted status. Making formatted status.")
 	s, err := status.GetAndFormatStatus()
@@ -256,6 +360,11 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
+	response.Resolutions = autodiscovery.GetResolutions()
+	response.FirstRunErrors = make(map[string]string)
+	for id, err := range runner.GetFirstRunErrors() {
+		response.FirstRunErrors[string(id)] = err
+	}
 
 	jsonConfig, err := json.Marshal(response)
 	if err != nil {
@@ -280,9 +389,10 @@ func getRuntimeConfig(w http.ResponseWriter, r *http.Request) {
 }
 
 func getTaggerList(w http.ResponseWriter, r *http.Request) {
-	// query at the highest cardinality between checks and dogstatsd cardinalities
-	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), int(tagger.DogstatsdCardinality)))
+	// query at the highest cardinality between checks, dogstatsd and logs cardinalities
+	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), max(int(tagger.DogstatsdCardinality), int(tagger.LogsCardinality))))
 	response := tagger.List(cardinality)
+	json.Unmarshal([]byte(expvar.Get("tagger").String()), &response.Stats)
 
 	jsonTags, err := json.Marshal(response)
 	if err != nil {
@@ -312,6 +422,90 @@ func secretInfo(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonInfo)
 }
 
+// refreshSecrets fetches again the secrets decrypted so far, reloading the
+// components using the ones that changed
+func refreshSecrets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	changed, err := secrets.RefreshAll()
+	if err != nil {
+		log.Errorf("Unable to refresh the secrets: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	j, _ := json.Marshal(map[string][]string{"changed": changed})
+	w.Write(j)
+}
+
+// setCheckRunners sets the number of check runners of the collector, 0
+// lets it update the number with the number of checks
+func setCheckRunners(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var runners struct {
+		CheckRunners *int `json:"check_runners"`
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &runners)
+	}
+	if err == nil && runners.CheckRunners == nil {
+		err = fmt.Errorf("missing number of check runners")
+	}
+	if err == nil && common.Coll == nil {
+		err = fmt.Errorf("the collector is not running")
+	}
+	if err == nil {
+		err = common.Coll.SetCheckRunners(*runners.CheckRunners)
+	}
+	if err != nil {
+		log.Errorf("Unable to set the number of check runners: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	config.Datadog.Set("check_runners", *runners.CheckRunners)
+
+	j, _ := json.Marshal("")
+	w.Write(j)
+}
+
+// refreshAPIKeys rotates the api keys used by the forwarder. The new main api
+// key can be sent in the body of the request, otherwise the api keys are read
+// from the configuration file and the secrets backend again.
+func refreshAPIKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var keys struct {
+		APIKey string `json:"api_key"`
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil && len(body) > 0 {
+		err = json.Unmarshal(body, &keys)
+	}
+	if err == nil {
+		if keys.APIKey != "" {
+			config.Datadog.Set("api_key", strings.TrimSpace(keys.APIKey))
+		} else {
+			err = config.RefreshAPIKeys()
+		}
+	}
+	if err == nil {
+		err = common.UpdateForwarderAPIKeys()
+	}
+	if err != nil {
+		log.Errorf("Unable to refresh the API keys: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	j, _ := json.Marshal("")
+	w.Write(j)
+}
+
 // max returns the maximum value between a and b.
 func max(a, b int) int {
 	if a > b {
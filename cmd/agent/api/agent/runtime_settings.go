@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func getRuntimeSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	body, err := json.Marshal(settings.RuntimeSettings())
+	if err != nil {
+		log.Errorf("Unable to marshal the runtime settings: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(body)
+}
+
+func getRuntimeSetting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	name := mux.Vars(r)["setting"]
+
+	value, err := settings.GetRuntimeSetting(name)
+	if err != nil {
+		log.Errorf("Unable to get the runtime setting %s: %s", name, err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"value": value})
+	w.Write(body)
+}
+
+func setRuntimeSetting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	name := mux.Vars(r)["setting"]
+
+	var req settings.SetRequest
+	var revertAfter time.Duration
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err == nil && req.RevertAfter != "" {
+		revertAfter, err = time.ParseDuration(req.RevertAfter)
+		if err == nil && revertAfter < 0 {
+			err = fmt.Errorf("negative revert delay %s", req.RevertAfter)
+		}
+	}
+	if err == nil {
+		err = settings.SetRuntimeSetting(name, req.Value, revertAfter)
+	}
+	if err != nil {
+		log.Errorf("Unable to set the runtime setting %s: %s", name, err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	j, _ := json.Marshal("")
+	w.Write(j)
+}
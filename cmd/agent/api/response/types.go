@@ -11,19 +11,31 @@ import (
 
 // ConfigCheckResponse holds the config check response
 type ConfigCheckResponse struct {
-	Configs         []integration.Config            `json:"configs"`
-	ResolveWarnings map[string][]string             `json:"resolve_warnings"`
-	ConfigErrors    map[string]string               `json:"config_errors"`
-	Unresolved      map[string][]integration.Config `json:"unresolved"`
+	Configs         []integration.Config                     `json:"configs"`
+	ResolveWarnings map[string][]string                      `json:"resolve_warnings"`
+	ConfigErrors    map[string]string                        `json:"config_errors"`
+	Unresolved      map[string][]integration.Config          `json:"unresolved"`
+	Resolutions     map[string]integration.ServiceResolution `json:"resolutions"`
+	FirstRunErrors  map[string]string                        `json:"first_run_errors"`
 }
 
 // TaggerListResponse holds the tagger list response
 type TaggerListResponse struct {
 	Entities map[string]TaggerListEntity `json:"entities"`
+	Stats    map[string]interface{}      `json:"stats,omitempty"`
 }
 
 // TaggerListEntity holds the tagging info about an entity
 type TaggerListEntity struct {
-	Sources []string `json:"sources"`
-	Tags    []string `json:"tags"`
+	Sources    []string                      `json:"sources"`
+	Tags       []string                      `json:"tags"`
+	SourceTags map[string][]string           `json:"source_tags"`
+	Conflicts  map[string]TaggerListConflict `json:"conflicts,omitempty"`
+}
+
+// TaggerListConflict holds, by source, the tags sent with different values
+// for the same tag key, and whether they were kept or dropped by priority
+type TaggerListConflict struct {
+	Kept    map[string][]string `json:"kept"`
+	Dropped map[string][]string `json:"dropped,omitempty"`
 }
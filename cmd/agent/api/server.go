@@ -11,6 +11,7 @@ sending commands and receiving infos.
 package api
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -18,18 +19,30 @@ import (
 	stdLog "log"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
 	"github.com/DataDog/datadog-agent/cmd/agent/api/agent"
 	"github.com/DataDog/datadog-agent/cmd/agent/api/check"
+	"github.com/DataDog/datadog-agent/pkg/api/ipc"
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
-	"github.com/gorilla/mux"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 var (
-	listener net.Listener
+	listener         net.Listener
+	grpcServer       *grpc.Server
+	grpcSocketServer *grpc.Server
 )
 
 // StartServer creates the router and starts the HTTP server
@@ -40,6 +53,8 @@ func StartServer() error {
 	// IPC REST API server
 	agent.SetupHandlers(r.PathPrefix("/agent").Subrouter())
 	check.SetupHandlers(r.PathPrefix("/check").Subrouter())
+	// internal metrics of the agent, in the Prometheus exposition format
+	r.Handle("/telemetry", telemetry.Handler())
 
 	// Validate token for every request
 	r.Use(validateToken)
@@ -90,6 +105,45 @@ func StartServer() error {
 	tlsListener := tls.NewListener(listener, &tlsConfig)
 
 	go srv.Serve(tlsListener)
+
+	// the gRPC api serves the other agent processes, it's served on its own
+	// port as the tagger streams are long-lived
+	if port := config.Datadog.GetInt("cmd_grpc_port"); port > 0 {
+		grpcListener, err := net.Listen("tcp", fmt.Sprintf("localhost:%v", port))
+		if err != nil {
+			return fmt.Errorf("Unable to create the gRPC api server: %v", err)
+		}
+		grpcServer = grpc.NewServer(
+			grpc.Creds(credentials.NewTLS(&tlsConfig)),
+			grpc.UnaryInterceptor(validateUnaryToken),
+			grpc.StreamInterceptor(validateStreamToken),
+		)
+		ipc.Register(grpcServer)
+		go grpcServer.Serve(grpcListener)
+	}
+
+	// on the unix socket, the clients authenticate with the IPC certificate
+	// instead of the session token
+	if socketPath := config.Datadog.GetString("cmd_grpc_socket"); socketPath != "" {
+		if err := startSocketServer(socketPath); err != nil {
+			log.Errorf("Unable to serve the gRPC api on %s: %v", socketPath, err)
+		}
+	}
+	return nil
+}
+
+func startSocketServer(socketPath string) error {
+	creds, err := ipc.ServerCredentials()
+	if err != nil {
+		return err
+	}
+	socketListener, err := ipc.Listen(socketPath)
+	if err != nil {
+		return err
+	}
+	grpcSocketServer = grpc.NewServer(grpc.Creds(creds))
+	ipc.Register(grpcSocketServer)
+	go grpcSocketServer.Serve(socketListener)
 	return nil
 }
 
@@ -99,6 +153,12 @@ func StopServer() {
 	if listener != nil {
 		listener.Close()
 	}
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+	if grpcSocketServer != nil {
+		grpcSocketServer.Stop()
+	}
 }
 
 // ServerAddress retruns the server address.
@@ -114,3 +174,37 @@ func validateToken(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// validateStreamToken checks the session token sent in the authorization
+// metadata of the gRPC streams
+func validateStreamToken(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := validateGRPCToken(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// validateUnaryToken checks the session token sent in the authorization
+// metadata of the gRPC calls
+func validateUnaryToken(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := validateGRPCToken(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func validateGRPCToken(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	auth := md.Get("authorization")
+	if len(auth) == 0 {
+		return status.Error(codes.Unauthenticated, "no session token provided")
+	}
+	tok := strings.Split(auth[0], " ")
+	if tok[0] != "Bearer" {
+		return status.Errorf(codes.Unauthenticated, "unsupported authorization scheme: %s", tok[0])
+	}
+	if len(tok) < 2 || tok[1] != util.GetAuthToken() {
+		return status.Error(codes.PermissionDenied, "invalid session token")
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -21,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/profiling"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status"
@@ -28,14 +30,17 @@ import (
 )
 
 var (
-	checkRate  bool
-	checkTimes int
-	checkPause int
-	checkName  string
-	checkDelay int
-	logLevel   string
-	formatJSON bool
-	breakPoint string
+	checkRate      bool
+	checkTimes     int
+	checkPause     int
+	checkName      string
+	checkDelay     int
+	logLevel       string
+	formatJSON     bool
+	formatTable    bool
+	breakPoint     string
+	instanceFilter string
+	profileCheck   bool
 )
 
 // Make the check cmd aggregator never flush by setting a very high interval
@@ -44,20 +49,25 @@ const checkCmdFlushInterval = time.Hour
 func init() {
 	AgentCmd.AddCommand(checkCmd)
 
-	checkCmd.Flags().BoolVarP(&checkRate, "check-rate", "r", false, "check rates by running the check twice with a 1sec-pause between the 2 runs")
+	checkCmd.Flags().BoolVarP(&checkRate, "check-rate", "r", false, "check rates by running the check twice with a pause between the 2 runs (1sec unless --pause is set)")
 	checkCmd.Flags().IntVarP(&checkTimes, "check-times", "t", 1, "number of times to run the check")
 	checkCmd.Flags().IntVar(&checkPause, "pause", 0, "pause between multiple runs of the check, in milliseconds")
 	checkCmd.Flags().StringVarP(&logLevel, "log-level", "l", "", "set the log level (default 'off')")
 	checkCmd.Flags().IntVarP(&checkDelay, "delay", "d", 100, "delay between running the check and grabbing the metrics in miliseconds")
 	checkCmd.Flags().BoolVarP(&formatJSON, "json", "", false, "format aggregator and check runner output as json")
+	checkCmd.Flags().BoolVarP(&formatTable, "table", "", false, "format aggregator output as tables")
+	checkCmd.Flags().StringVarP(&instanceFilter, "instance-filter", "", "", "only run the instances whose configuration has the given key=value, e.g. host=localhost")
 	checkCmd.Flags().StringVarP(&breakPoint, "breakpoint", "b", "", "set a breakpoint at a particular line number (Python checks only)")
+	checkCmd.Flags().BoolVarP(&profileCheck, "profile", "", false, "write the CPU and heap profiles of the check runs to check_profiles_dir")
 	checkCmd.SetArgs([]string{"checkName"})
 }
 
 var checkCmd = &cobra.Command{
 	Use:   "check <check_name>",
 	Short: "Run the specified check",
-	Long:  `Use this to run a specific check with a specific rate`,
+	Long: `Use this to run a specific check with a specific rate.
+The command exits with a non-zero status when the check returns an error.`,
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		overrides := make(map[string]interface{})
 
@@ -65,6 +75,10 @@ var checkCmd = &cobra.Command{
 			color.NoColor = true
 		}
 
+		if formatJSON && formatTable {
+			return fmt.Errorf("the --json and --table options can't be used together")
+		}
+
 		if logLevel != "" {
 			// Python calls config.Datadog.GetString("log_level")
 			overrides["log_level"] = logLevel
@@ -139,6 +153,13 @@ var checkCmd = &cobra.Command{
 			}
 		}
 
+		if instanceFilter != "" {
+			if err := filterInstances(allConfigs, checkName, instanceFilter); err != nil {
+				fmt.Fprintln(color.Output, fmt.Sprintf("\n%s: %s", color.RedString("Error"), err))
+				return err
+			}
+		}
+
 		cs := collector.GetChecksByNameForConfigs(checkName, allConfigs)
 		if len(cs) == 0 {
 			for check, error := range autodiscovery.GetConfigErrors() {
@@ -170,9 +191,13 @@ var checkCmd = &cobra.Command{
 		}
 
 		var instancesData []interface{}
+		var failedChecks []string
 
 		for _, c := range cs {
 			s := runCheck(c, agg)
+			if s.TotalErrors > 0 {
+				failedChecks = append(failedChecks, string(c.ID()))
+			}
 
 			// Sleep for a while to allow the aggregator to finish ingesting all the metrics/events/sc
 			time.Sleep(time.Duration(checkDelay) * time.Millisecond)
@@ -199,7 +224,11 @@ var checkCmd = &cobra.Command{
 				}
 				instancesData = append(instancesData, instanceData)
 			} else {
-				printMetrics(agg)
+				if formatTable {
+					printMetricsTable(agg)
+				} else {
+					printMetrics(agg)
+				}
 				checkStatus, _ := status.GetCheckStatus(c, s)
 				fmt.Println(string(checkStatus))
 			}
@@ -217,6 +246,9 @@ var checkCmd = &cobra.Command{
 			color.Yellow("Check has run only once, if some metrics are missing you can try again with --check-rate to see any other metric if available.")
 		}
 
+		if len(failedChecks) > 0 {
+			return fmt.Errorf("%d check instance(s) returned an error: %s", len(failedChecks), strings.Join(failedChecks, ", "))
+		}
 		return nil
 	},
 }
@@ -229,12 +261,20 @@ func runCheck(c check.Check, agg *aggregator.BufferedAggregator) *check.Stats {
 		if checkTimes > 2 {
 			color.Yellow("The check-rate option is overriding check-times to 2")
 		}
-		if pause > 0 {
-			color.Yellow("The check-rate option is overriding pause to 1000ms")
-		}
 		times = 2
-		pause = 1000
+		if pause == 0 {
+			pause = 1000
+		}
 	}
+
+	var profiler *profiling.Profiler
+	if profileCheck {
+		var err error
+		if profiler, err = profiling.Start(c, config.Datadog.GetString("check_profiles_dir")); err != nil {
+			fmt.Fprintln(color.Output, fmt.Sprintf("%s: unable to profile %s: %s", color.RedString("Error"), c.ID(), err))
+		}
+	}
+
 	for i := 0; i < times; i++ {
 		t0 := time.Now()
 		err := c.Run()
@@ -246,6 +286,17 @@ func runCheck(c check.Check, agg *aggregator.BufferedAggregator) *check.Stats {
 		}
 	}
 
+	if profiler != nil {
+		paths, err := profiler.Stop()
+		if err != nil {
+			fmt.Fprintln(color.Output, fmt.Sprintf("%s: unable to write the profiles of %s: %s", color.RedString("Error"), c.ID(), err))
+		}
+		if len(paths) > 0 {
+			fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Profiles")))
+			fmt.Println(strings.Join(paths, "\n"))
+		}
+	}
+
 	return s
 }
 
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// filterInstances only keeps the instances of the checkName configs whose
+// configuration has a top-level key matching the "key=value" filter
+func filterInstances(configs []integration.Config, checkName, filter string) error {
+	parts := strings.SplitN(filter, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid instance filter %q, expected key=value", filter)
+	}
+	key, value := parts[0], parts[1]
+
+	matched := 0
+	for idx := range configs {
+		conf := &configs[idx]
+		if conf.Name != checkName {
+			continue
+		}
+
+		var instances []integration.Data
+		for _, instance := range conf.Instances {
+			var data map[string]interface{}
+			if err := yaml.Unmarshal(instance, &data); err != nil {
+				continue
+			}
+			if v, found := data[key]; found && fmt.Sprint(v) == value {
+				instances = append(instances, instance)
+			}
+		}
+		conf.Instances = instances
+		matched += len(instances)
+	}
+
+	if matched == 0 {
+		return fmt.Errorf("no instance of %s matching %s", checkName, filter)
+	}
+	return nil
+}
+
+// printMetricsTable prints the data submitted by the check as tables
+func printMetricsTable(agg *aggregator.BufferedAggregator) {
+	if series := agg.GetSeries(); len(series) != 0 {
+		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Series")))
+		printSeriesTable(color.Output, series)
+	}
+
+	if sketches := agg.GetSketches(); len(sketches) != 0 {
+		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Sketches")))
+		printSketchesTable(color.Output, sketches)
+	}
+
+	if serviceChecks := agg.GetServiceChecks(); len(serviceChecks) != 0 {
+		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Service Checks")))
+		printServiceChecksTable(color.Output, serviceChecks)
+	}
+
+	if events := agg.GetEvents(); len(events) != 0 {
+		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Events")))
+		printEventsTable(color.Output, events)
+	}
+}
+
+func printSeriesTable(out io.Writer, series metrics.Series) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tTYPE\tVALUE\tTAGS")
+	for _, serie := range series {
+		for _, point := range serie.Points {
+			fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", serie.Name, serie.MType, point.Value, strings.Join(serie.Tags, ","))
+		}
+	}
+	w.Flush()
+}
+
+func printSketchesTable(out io.Writer, sketches metrics.SketchSeriesList) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tCOUNT\tMIN\tMAX\tAVG\tTAGS")
+	for _, sketch := range sketches {
+		for _, point := range sketch.Points {
+			if point.Sketch == nil {
+				continue
+			}
+			basic := point.Sketch.Basic
+			fmt.Fprintf(w, "%s\t%d\t%v\t%v\t%v\t%s\n", sketch.Name, basic.Cnt, basic.Min, basic.Max, basic.Avg, strings.Join(sketch.Tags, ","))
+		}
+	}
+	w.Flush()
+}
+
+func printServiceChecksTable(out io.Writer, serviceChecks metrics.ServiceChecks) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tMESSAGE\tTAGS")
+	for _, sc := range serviceChecks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sc.CheckName, sc.Status, sc.Message, strings.Join(sc.Tags, ","))
+	}
+	w.Flush()
+}
+
+func printEventsTable(out io.Writer, events metrics.Events) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TITLE\tALERT TYPE\tTAGS")
+	for _, event := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", event.Title, event.AlertType, strings.Join(event.Tags, ","))
+	}
+	w.Flush()
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestFilterInstances(t *testing.T) {
+	configs := []integration.Config{
+		{
+			Name: "redis",
+			Instances: []integration.Data{
+				integration.Data("host: localhost\nport: 6379"),
+				integration.Data("host: redis.local\nport: 6380"),
+			},
+		},
+		{
+			Name:      "nginx",
+			Instances: []integration.Data{integration.Data("host: localhost")},
+		},
+	}
+
+	require.NoError(t, filterInstances(configs, "redis", "port=6380"))
+	assert.Equal(t, []integration.Data{integration.Data("host: redis.local\nport: 6380")}, configs[0].Instances)
+	assert.Len(t, configs[1].Instances, 1)
+
+	assert.Error(t, filterInstances(configs, "redis", "host=localhost"))
+	assert.Error(t, filterInstances(configs, "redis", "port"))
+	assert.Error(t, filterInstances(configs, "redis", "=6380"))
+}
+
+func TestPrintSeriesTable(t *testing.T) {
+	var out bytes.Buffer
+	printSeriesTable(&out, metrics.Series{
+		{
+			Name:   "redis.net.clients",
+			Points: []metrics.Point{{Ts: 1, Value: 12}},
+			Tags:   []string{"redis_host:localhost", "redis_port:6379"},
+			MType:  metrics.APIGaugeType,
+		},
+	})
+
+	assert.Equal(t, "METRIC             TYPE   VALUE  TAGS\n"+
+		"redis.net.clients  gauge  12     redis_host:localhost,redis_port:6379\n", out.String())
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func init() {
+	AgentCmd.AddCommand(checkRunnersCommand)
+}
+
+var checkRunnersCommand = &cobra.Command{
+	Use:   "check-runners <number>",
+	Short: "Set the number of check runners of a running Agent, 0 to let the Agent set it with the number of checks.",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		numRunners, err := strconv.Atoi(args[0])
+		if err != nil || numRunners < 0 {
+			return fmt.Errorf("invalid number of check runners: %s", args[0])
+		}
+
+		if err := common.SetupConfigWithoutSecrets(confFilePath); err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		if err := util.SetAuthToken(); err != nil {
+			return err
+		}
+
+		return setCheckRunners(numRunners)
+	},
+}
+
+func setCheckRunners(numRunners int) error {
+	c := util.GetClient(false)
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/check-runners", config.Datadog.GetInt("cmd_port"))
+
+	body, _ := json.Marshal(map[string]int{"check_runners": numRunners})
+	r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap)
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return fmt.Errorf("Error setting the number of check runners: %s", e)
+		}
+		return fmt.Errorf("Could not reach agent: %v\nMake sure the agent is running before setting the number of check runners", err)
+	}
+
+	fmt.Printf("Number of check runners set to %d\n", numRunners)
+	return nil
+}
@@ -6,12 +6,19 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/config/validate"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -20,8 +27,18 @@ var (
 	configJSON bool
 )
 
+var (
+	revertAfter time.Duration
+)
+
 func init() {
 	AgentCmd.AddCommand(configCommand)
+	configCommand.AddCommand(listRuntimeSettingsCommand)
+	configCommand.AddCommand(setRuntimeSettingCommand)
+	configCommand.AddCommand(getRuntimeSettingCommand)
+	configCommand.AddCommand(validateConfigCommand)
+	setRuntimeSettingCommand.Flags().DurationVarP(&revertAfter, "revert-after", "", 0, "revert the setting to its previous value after this duration, like 30m (default: never)")
+	validateConfigCommand.Flags().BoolVarP(&configJSON, "json", "j", false, "print out the issues in JSON")
 }
 
 var configCommand = &cobra.Command{
@@ -69,3 +86,179 @@ func requestConfig() (string, error) {
 
 	return string(r), nil
 }
+
+var listRuntimeSettingsCommand = &cobra.Command{
+	Use:   "list-runtime",
+	Short: "List the settings that can be changed at runtime",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupConfigCommand(); err != nil {
+			return err
+		}
+
+		r, err := doConfigRequest("GET", "list-runtime", nil)
+		if err != nil {
+			return err
+		}
+		var runtimeSettings map[string]settings.SettingInfo
+		if err := json.Unmarshal(r, &runtimeSettings); err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(runtimeSettings))
+		for name := range runtimeSettings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w := tabwriter.NewWriter(color.Output, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE\tREVERT AT\tDESCRIPTION")
+		for _, name := range names {
+			info := runtimeSettings[name]
+			revertAt := ""
+			if info.RevertAt != nil {
+				revertAt = info.RevertAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%v\t%s\t%s\t%s\n", name, info.Value, info.Source, revertAt, info.Description)
+		}
+		return w.Flush()
+	},
+}
+
+var setRuntimeSettingCommand = &cobra.Command{
+	Use:   "set <setting> <value>",
+	Short: "Change a setting of a running agent, see list-runtime",
+	Long:  ``,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupConfigCommand(); err != nil {
+			return err
+		}
+
+		req := settings.SetRequest{Value: args[1]}
+		if revertAfter > 0 {
+			req.RevertAfter = revertAfter.String()
+		}
+		body, _ := json.Marshal(req)
+		if _, err := doConfigRequest("POST", args[0], bytes.NewBuffer(body)); err != nil {
+			return err
+		}
+
+		if revertAfter > 0 {
+			fmt.Printf("%s set to %s, it will be reverted in %s\n", args[0], args[1], revertAfter)
+		} else {
+			fmt.Printf("%s set to %s\n", args[0], args[1])
+		}
+		return nil
+	},
+}
+
+var getRuntimeSettingCommand = &cobra.Command{
+	Use:   "get <setting>",
+	Short: "Print the value of a setting of a running agent, see list-runtime",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupConfigCommand(); err != nil {
+			return err
+		}
+
+		r, err := doConfigRequest("GET", args[0], nil)
+		if err != nil {
+			return err
+		}
+		var setting map[string]interface{}
+		if err := json.Unmarshal(r, &setting); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is set to: %v\n", args[0], setting["value"])
+		return nil
+	},
+}
+
+var validateConfigCommand = &cobra.Command{
+	Use:          "validate",
+	Short:        "Check datadog.yaml and the files of conf.d for unknown keys, wrong types and deprecated options",
+	Long:         ``,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the syntax errors of datadog.yaml are reported with the other issues
+		if err := common.SetupConfigWithoutSecrets(confFilePath); err != nil && config.Datadog.ConfigFileUsed() == "" {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		issues, err := validate.ValidateAgentConfig(config.Datadog.ConfigFileUsed())
+		if err != nil {
+			return err
+		}
+		confdIssues, err := validate.ValidateConfd(config.Datadog.GetString("confd_path"))
+		if err != nil {
+			return err
+		}
+		issues = append(issues, confdIssues...)
+
+		if configJSON {
+			if issues == nil {
+				issues = []validate.Issue{}
+			}
+			out, err := json.MarshalIndent(issues, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		} else {
+			for _, issue := range issues {
+				if issue.Severity == validate.SeverityError {
+					fmt.Fprintln(color.Output, color.RedString(issue.String()))
+				} else {
+					fmt.Fprintln(color.Output, color.YellowString(issue.String()))
+				}
+			}
+			errors := validate.CountErrors(issues)
+			fmt.Fprintf(color.Output, "%d error(s), %d warning(s)\n", errors, len(issues)-errors)
+		}
+
+		if errors := validate.CountErrors(issues); errors > 0 {
+			return fmt.Errorf("the configuration has %d error(s)", errors)
+		}
+		return nil
+	},
+}
+
+func setupConfigCommand() error {
+	if err := common.SetupConfigWithoutSecrets(confFilePath); err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+	if flagNoColor {
+		color.NoColor = true
+	}
+	return util.SetAuthToken()
+}
+
+// doConfigRequest queries the runtime settings endpoints of the agent
+func doConfigRequest(method, path string, body io.Reader) ([]byte, error) {
+	c := util.GetClient(false)
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/config/%s", config.Datadog.GetInt("cmd_port"), path)
+
+	var r []byte
+	var err error
+	if method == "POST" {
+		r, err = util.DoPost(c, urlstr, "application/json", body)
+	} else {
+		r, err = util.DoGet(c, urlstr)
+	}
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap)
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return nil, fmt.Errorf(e)
+		}
+		return nil, fmt.Errorf("Could not reach agent: %v \nMake sure the agent is running before changing its runtime settings and contact support if you continue having issues", err)
+	}
+	return r, nil
+}
@@ -6,25 +6,33 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	// register the diagnosis of the host
+	_ "github.com/DataDog/datadog-agent/pkg/diagnose/host"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+var jsonDiagnose bool
+
 func init() {
 	AgentCmd.AddCommand(diagnoseCommand)
+	diagnoseCommand.Flags().BoolVarP(&jsonDiagnose, "json", "j", false, "print out the report of the diagnosis as json")
 }
 
 var diagnoseCommand = &cobra.Command{
 	Use:   "diagnose",
 	Short: "Execute some connectivity diagnosis on your system",
-	Long:  ``,
+	Long:  `Execute the connectivity and permission diagnosis registered by the components of the agent, and print how to fix the failing ones.`,
 	Run:   doDiagnose,
 }
 
@@ -55,8 +63,21 @@ func doDiagnose(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 
-	err = diagnose.RunAll(color.Output)
+	if !jsonDiagnose {
+		err = diagnose.RunAll(color.Output)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	report, err := diagnose.RunAllWithReport(ioutil.Discard)
+	if err != nil {
+		panic(err)
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
 		panic(err)
 	}
+	fmt.Println(string(b))
 }
@@ -14,8 +14,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var hostnameDebug bool
+
 func init() {
 	AgentCmd.AddCommand(getHostnameCommand)
+	getHostnameCommand.Flags().BoolVarP(&hostnameDebug, "debug", "d", false, "explain how the hostname was resolved")
 }
 
 var getHostnameCommand = &cobra.Command{
@@ -32,6 +35,11 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("unable to set up global agent configuration: %v", err)
 	}
+
+	if hostnameDebug {
+		return printHostnameResolution()
+	}
+
 	hname, err := util.GetHostname()
 	if err != nil {
 		return fmt.Errorf("Error getting the hostname: %v", err)
@@ -40,3 +48,28 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 	fmt.Println(hname)
 	return nil
 }
+
+// printHostnameResolution prints the outcome of every provider of the
+// hostname resolution, in order
+func printHostnameResolution() error {
+	resolution, err := util.ResolveHostname()
+
+	fmt.Println("Providers, in order:")
+	for _, candidate := range resolution.Candidates {
+		switch {
+		case candidate.Skipped != "":
+			fmt.Printf("  %s: skipped, %s\n", candidate.Provider, candidate.Skipped)
+		case candidate.Error != "":
+			fmt.Printf("  %s: error, %s\n", candidate.Provider, candidate.Error)
+		default:
+			fmt.Printf("  %s: %s\n", candidate.Provider, candidate.Hostname)
+		}
+	}
+	fmt.Println()
+
+	if err != nil {
+		return fmt.Errorf("Error getting the hostname: %v", err)
+	}
+	fmt.Printf("Hostname: %s (provider: %s)\n", resolution.Hostname, resolution.Provider)
+	return nil
+}
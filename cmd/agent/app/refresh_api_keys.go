@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func init() {
+	AgentCmd.AddCommand(refreshAPIKeysCommand)
+}
+
+var refreshAPIKeysCommand = &cobra.Command{
+	Use:   "refresh-api-keys",
+	Short: "Make a running Agent read its API keys from the configuration and the secrets backend again.",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.SetupConfigWithoutSecrets(confFilePath); err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		if err := util.SetAuthToken(); err != nil {
+			return err
+		}
+
+		return refreshAPIKeys()
+	},
+}
+
+func refreshAPIKeys() error {
+	c := util.GetClient(false)
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/api-keys/refresh", config.Datadog.GetInt("cmd_port"))
+
+	r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap)
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return fmt.Errorf("Error refreshing the API keys: %s", e)
+		}
+		return fmt.Errorf("Could not reach agent: %v\nMake sure the agent is running before refreshing the API keys", err)
+	}
+
+	fmt.Println("API keys successfully refreshed")
+	return nil
+}
@@ -41,6 +41,9 @@ const hostMetadataCollectorInterval = 14400
 // run the agent checks metadata collector every 600 seconds (10 minutes)
 const agentChecksMetadataCollectorInterval = 600
 
+// run the inventories metadata collector every 600 seconds (10 minutes)
+const inventoriesMetadataCollectorInterval = 600
+
 // run the resources metadata collector every 300 seconds (5 minutes) by default, configurable
 const defaultResourcesMetadataCollectorInterval = 300
 
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+)
+
+// initRuntimeSettings registers the settings that can be changed at runtime
+// with `agent config set`
+func initRuntimeSettings() error {
+	if err := settings.RegisterRuntimeSetting(settings.LogLevelRuntimeSetting{}); err != nil {
+		return err
+	}
+	if err := settings.RegisterRuntimeSetting(dsdStatsRuntimeSetting{}); err != nil {
+		return err
+	}
+	if err := settings.RegisterRuntimeSetting(dsdMetricBlocklistRuntimeSetting{}); err != nil {
+		return err
+	}
+	if err := settings.RegisterRuntimeSetting(&settings.ProfilingRuntimeSetting{}); err != nil {
+		return err
+	}
+	return settings.RegisterRuntimeSetting(checkProfilingRuntimeSetting{})
+}
+
+// dsdStatsRuntimeSetting toggles the metrics statistics of dogstatsd, shown
+// by `agent dogstatsd-stats`
+type dsdStatsRuntimeSetting struct{}
+
+func (s dsdStatsRuntimeSetting) Name() string {
+	return "dogstatsd_stats"
+}
+
+func (s dsdStatsRuntimeSetting) Description() string {
+	return "Enable the metrics statistics of dogstatsd: true or false"
+}
+
+func (s dsdStatsRuntimeSetting) ConfigKeys() []string {
+	return []string{"dogstatsd_metrics_stats_enable"}
+}
+
+func (s dsdStatsRuntimeSetting) Get() (interface{}, error) {
+	if common.DSD == nil {
+		return false, nil
+	}
+	return common.DSD.MetricsStatsEnabled(), nil
+}
+
+func (s dsdStatsRuntimeSetting) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if common.DSD == nil {
+		return fmt.Errorf("dogstatsd is not running")
+	}
+
+	common.DSD.EnableMetricsStats(enabled)
+	config.Datadog.Set("dogstatsd_metrics_stats_enable", enabled)
+	return nil
+}
+
+// dsdMetricBlocklistRuntimeSetting changes the names of the metrics dropped
+// by dogstatsd
+type dsdMetricBlocklistRuntimeSetting struct{}
+
+func (s dsdMetricBlocklistRuntimeSetting) Name() string {
+	return "statsd_metric_blocklist"
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) Description() string {
+	return "Set the comma separated names of the metrics dropped by dogstatsd"
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) ConfigKeys() []string {
+	return []string{"statsd_metric_blocklist"}
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) Get() (interface{}, error) {
+	if common.DSD == nil {
+		return strings.Join(config.Datadog.GetStringSlice("statsd_metric_blocklist"), ","), nil
+	}
+	return strings.Join(common.DSD.MetricBlocklist(), ","), nil
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) Set(value string) error {
+	if common.DSD == nil {
+		return fmt.Errorf("dogstatsd is not running")
+	}
+
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	common.DSD.SetMetricBlocklist(names)
+	config.Datadog.Set("statsd_metric_blocklist", names)
+	return nil
+}
+
+// checkProfilingRuntimeSetting profiles the next run of some checks, to
+// find the ones using the CPU or the memory of the agent
+type checkProfilingRuntimeSetting struct{}
+
+func (s checkProfilingRuntimeSetting) Name() string {
+	return "check_profiling"
+}
+
+func (s checkProfilingRuntimeSetting) Description() string {
+	return "Profile the next run of the comma separated check names or instance IDs, written to check_profiles_dir"
+}
+
+func (s checkProfilingRuntimeSetting) ConfigKeys() []string {
+	return nil
+}
+
+func (s checkProfilingRuntimeSetting) Get() (interface{}, error) {
+	if common.Coll == nil {
+		return "", nil
+	}
+	return strings.Join(common.Coll.ProfiledChecks(), ","), nil
+}
+
+func (s checkProfilingRuntimeSetting) Set(value string) error {
+	if common.Coll == nil {
+		return fmt.Errorf("the collector is not running")
+	}
+
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return common.Coll.ProfileChecks(names)
+}
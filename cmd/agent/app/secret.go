@@ -6,6 +6,7 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -20,6 +21,7 @@ import (
 )
 
 func init() {
+	secretInfoCommand.AddCommand(secretRefreshCommand)
 	AgentCmd.AddCommand(secretInfoCommand)
 }
 
@@ -50,6 +52,55 @@ var secretInfoCommand = &cobra.Command{
 	},
 }
 
+var secretRefreshCommand = &cobra.Command{
+	Use:   "refresh",
+	Short: "Make a running Agent fetch the decrypted secrets again, reloading the API keys and checks using the ones that changed.",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.SetupConfigWithoutSecrets(confFilePath); err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		if err := util.SetAuthToken(); err != nil {
+			return err
+		}
+
+		return refreshSecrets()
+	},
+}
+
+func refreshSecrets() error {
+	c := util.GetClient(false)
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/secrets/refresh", config.Datadog.GetInt("cmd_port"))
+
+	r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap)
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return fmt.Errorf("Error refreshing the secrets: %s", e)
+		}
+		return fmt.Errorf("Could not reach agent: %v\nMake sure the agent is running before refreshing the secrets", err)
+	}
+
+	var result struct {
+		Changed []string `json:"changed"`
+	}
+	if err = json.Unmarshal(r, &result); err != nil {
+		return fmt.Errorf("Could not Unmarshal agent answer: %s", r)
+	}
+	if len(result.Changed) == 0 {
+		fmt.Println("Secrets successfully refreshed, none changed")
+		return nil
+	}
+	fmt.Printf("Secrets successfully refreshed, %d changed:\n", len(result.Changed))
+	for _, handle := range result.Changed {
+		fmt.Printf("- %s\n", handle)
+	}
+	return nil
+}
+
 func showSecretInfo() error {
 	c := util.GetClient(false)
 	apiConfigURL := fmt.Sprintf("https://localhost:%v/agent/secrets", config.Datadog.GetInt("cmd_port"))
@@ -31,10 +31,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/fips"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
 
@@ -42,7 +45,11 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/containers"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/goexpvar"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/net"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/nvidia"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/processes"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
 
 	// register metadata providers
@@ -122,6 +129,16 @@ func StartAgent() error {
 
 	log.Infof("Starting Datadog Agent v%v", version.AgentVersion)
 
+	if err := fips.Check(); err != nil {
+		return log.Errorf("Error while checking the FIPS mode, exiting: %v", err)
+	}
+	if fips.Enabled() {
+		log.Info("FIPS mode enabled: the TLS connections use the FIPS validated crypto module")
+	}
+	if err := util.CheckTLSConfig(); err != nil {
+		return log.Errorf("Error while checking the TLS configuration, exiting: %v", err)
+	}
+
 	// Setup expvar server
 	var port = config.Datadog.GetString("expvar_port")
 	go http.ListenAndServe("127.0.0.1:"+port, http.DefaultServeMux)
@@ -157,6 +174,11 @@ func StartAgent() error {
 		log.Errorf("Unable to initialize host metadata: %v", err)
 	}
 
+	// register the settings that can be changed at runtime through the cmd API
+	if err = initRuntimeSettings(); err != nil {
+		log.Warnf("Can't initialize the runtime settings: %v", err)
+	}
+
 	// start the cmd HTTP server
 	if runtime.GOOS != "android" {
 		if err = api.StartServer(); err != nil {
@@ -177,7 +199,12 @@ func StartAgent() error {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	common.Forwarder = forwarder.NewDefaultForwarder(keysPerDomain)
+	keysPerDomainPerType, err := config.GetAdditionalEndpointsPerType()
+	if err != nil {
+		log.Error("Misconfiguration of agent endpoints per data type: ", err)
+	}
+	fwd := forwarder.NewDefaultForwarderWithEndpointsPerType(keysPerDomain, keysPerDomainPerType)
+	common.Forwarder = fwd
 	log.Debugf("Starting forwarder")
 	common.Forwarder.Start()
 	log.Debugf("Forwarder started")
@@ -187,6 +214,18 @@ func StartAgent() error {
 	agg := aggregator.InitAggregator(s, hostname, "agent")
 	agg.AddAgentStartupTelemetry(version.AgentVersion)
 
+	// report forwarder failovers and configuration reloads as events
+	_, eventIn, _ := agg.GetChannels()
+	sendAgentEvent := func(e metrics.Event) {
+		e.Host = hostname
+		select {
+		case eventIn <- e:
+		default:
+			log.Warnf("Dropping agent event: %s", e.Title)
+		}
+	}
+	fwd.SetFailoverEventHandler(sendAgentEvent)
+
 	// start dogstatsd
 	if config.Datadog.GetBool("use_dogstatsd") {
 		var err error
@@ -215,6 +254,22 @@ func StartAgent() error {
 	// start the autoconfig, this will immediately run any configured check
 	common.StartAutoConfig()
 
+	// reload the components using secrets when they are refreshed
+	common.SetupSecretsRefresh()
+
+	// apply the changes of datadog.yaml that don't require a restart
+	common.SetupConfigWatcher(sendAgentEvent)
+
+	// apply the runtime settings of the remote configuration
+	if config.Datadog.GetBool("remote_configuration.enabled") {
+		common.RemoteConfig, err = remoteconfig.NewClientFromConfig()
+		if err != nil {
+			log.Errorf("Could not start the remote configuration: %s", err)
+		} else {
+			common.RemoteConfig.Start()
+		}
+	}
+
 	// setup the metadata collector, this needs a working Python env to function
 	if config.Datadog.GetBool("enable_metadata_collection") {
 		err = setupMetadataCollection(s, hostname)
@@ -239,7 +294,7 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 	if err == nil {
 		log.Debugf("Adding configured providers to the metadata collector")
 		for _, c := range C {
-			if c.Name == "host" || c.Name == "agent_checks" {
+			if c.Name == "host" || c.Name == "agent_checks" || c.Name == "inventories" {
 				continue
 			}
 			if c.Name == "resources" {
@@ -269,6 +324,12 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 	if err != nil {
 		return log.Error("Agent Checks metadata is supposed to be always available in the catalog!")
 	}
+	if config.Datadog.GetBool("inventories_enabled") {
+		err = metadata.SetupInventories(common.MetadataScheduler, common.Coll, inventoriesMetadataCollectorInterval*time.Second)
+		if err != nil {
+			log.Warn("Could not add inventories metadata provider: ", err)
+		}
+	}
 	if addDefaultResourcesCollector && runtime.GOOS == "linux" {
 		err = common.MetadataScheduler.AddCollector("resources", defaultResourcesMetadataCollectorInterval*time.Second)
 		if err != nil {
@@ -293,6 +354,9 @@ func StopAgent() {
 	// gracefully shut down any component
 	common.MainCtxCancel()
 
+	if common.RemoteConfig != nil {
+		common.RemoteConfig.Stop()
+	}
 	if common.DSD != nil {
 		common.DSD.Stop()
 	}
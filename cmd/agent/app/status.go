@@ -23,13 +23,15 @@ import (
 var (
 	jsonStatus      bool
 	prettyPrintJSON bool
+	rawJSONStatus   bool
 	statusFilePath  string
 )
 
 func init() {
 	AgentCmd.AddCommand(statusCmd)
-	statusCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
+	statusCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out json, with a versioned schema")
 	statusCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
+	statusCmd.Flags().BoolVarP(&rawJSONStatus, "raw-json", "", false, "print out the raw json served by the agent, without a stable schema")
 	statusCmd.Flags().StringVarP(&statusFilePath, "file", "o", "", "Output the status command to a file")
 }
 
@@ -50,7 +52,7 @@ var statusCmd = &cobra.Command{
 }
 
 func requestStatus() error {
-	if !prettyPrintJSON && !jsonStatus {
+	if !prettyPrintJSON && !jsonStatus && !rawJSONStatus {
 		fmt.Printf("Getting the status from the agent.\n\n")
 	}
 	var e error
@@ -78,12 +80,27 @@ func requestStatus() error {
 	}
 
 	// The rendering is done in the client so that the agent has less work to do
-	if prettyPrintJSON {
+	if rawJSONStatus && prettyPrintJSON {
 		var prettyJSON bytes.Buffer
 		json.Indent(&prettyJSON, r, "", "  ")
 		s = prettyJSON.String()
-	} else if jsonStatus {
+	} else if rawJSONStatus {
 		s = string(r)
+	} else if prettyPrintJSON || jsonStatus {
+		structured, err := status.FormatJSONStatus(r)
+		if err != nil {
+			return err
+		}
+		var out []byte
+		if prettyPrintJSON {
+			out, err = json.MarshalIndent(structured, "", "  ")
+		} else {
+			out, err = json.Marshal(structured)
+		}
+		if err != nil {
+			return err
+		}
+		s = string(out)
 	} else {
 		formattedStatus, err := status.FormatStatus(r)
 		if err != nil {
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+)
+
+var (
+	streamLogsFilters  diagnostic.Filters
+	streamLogsDuration time.Duration
+)
+
+func init() {
+	AgentCmd.AddCommand(streamLogsCmd)
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Name, "name", "", "Filter by the name of the integration")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Type, "type", "", "Filter by the type of the logs: file, docker, tcp...")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Source, "source", "", "Filter by source")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Service, "service", "", "Filter by service")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Container, "container", "", "Filter by the name or the id of the container")
+	streamLogsCmd.Flags().DurationVarP(&streamLogsDuration, "duration", "d", 0, "Stop streaming after this duration, like 30s, streams until interrupted when 0")
+}
+
+var streamLogsCmd = &cobra.Command{
+	Use:   "stream-logs",
+	Short: "Stream the logs being processed by a running agent",
+	Long:  `Stream the logs processed by a running agent, once its processing rules are applied and its tags are attached, before they are sent.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.SetupConfigWithoutSecrets(confFilePath); err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		if err := util.SetAuthToken(); err != nil {
+			return err
+		}
+
+		return streamLogs()
+	},
+}
+
+func streamLogs() error {
+	body, err := json.Marshal(&streamLogsFilters)
+	if err != nil {
+		return err
+	}
+
+	c := util.GetClient(false)
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/stream-logs", config.Datadog.GetInt("cmd_port"))
+
+	var deadline time.Time
+	if streamLogsDuration > 0 {
+		deadline = time.Now().Add(streamLogsDuration)
+	}
+
+	// the agent ends each stream before the timeout of its api server,
+	// reconnect until the duration is over
+	for deadline.IsZero() || time.Now().Before(deadline) {
+		if !deadline.IsZero() {
+			c.Timeout = time.Until(deadline)
+		}
+		err = util.DoPostChunked(c, urlstr, "application/json", bytes.NewBuffer(body), func(chunk []byte) {
+			os.Stdout.Write(chunk)
+		})
+		if err != nil && !deadline.IsZero() && !time.Now().Before(deadline) {
+			// the client timed out at the end of the duration
+			return nil
+		}
+		if err != nil {
+			var errMap = make(map[string]string)
+			json.Unmarshal([]byte(err.Error()), &errMap)
+			// If the error has been marshalled into a json object, check it and return it properly
+			if e, found := errMap["error"]; found {
+				return fmt.Errorf("Error streaming the logs: %s", e)
+			}
+			return fmt.Errorf("Could not reach agent: %v\nMake sure the agent is running before streaming its logs", err)
+		}
+	}
+	return nil
+}
@@ -26,9 +26,11 @@ func init() {
 }
 
 var taggerListCommand = &cobra.Command{
-	Use:   "tagger-list",
+	Use:   "tagger-list [entity filter...]",
 	Short: "Print the tagger content of a running agent",
-	Long:  ``,
+	Long: `Print the entities of the tagger of a running agent, with their tags and the
+tags sent by every source, followed by the tagger stats. Only the entities whose
+ID contains one of the filters are printed, if any.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		err := common.SetupConfigWithoutSecrets(confFilePath)
 		if err != nil {
@@ -60,22 +62,20 @@ var taggerListCommand = &cobra.Command{
 			return err
 		}
 
-		for entity, tagItem := range tr.Entities {
+		entities := make([]string, 0, len(tr.Entities))
+		for entity := range tr.Entities {
+			if len(args) == 0 || containsAny(entity, args) {
+				entities = append(entities, entity)
+			}
+		}
+		sort.Strings(entities)
+
+		for _, entity := range entities {
+			tagItem := tr.Entities[entity]
 			fmt.Fprintln(color.Output, fmt.Sprintf("\n=== Entity %s ===", color.GreenString(entity)))
 
-			fmt.Fprint(color.Output, "Tags: [")
-			// sort tags for easy comparison
-			sort.Slice(tagItem.Tags, func(i, j int) bool {
-				return tagItem.Tags[i] < tagItem.Tags[j]
-			})
-			for i, tag := range tagItem.Tags {
-				tagInfo := strings.Split(tag, ":")
-				fmt.Fprintf(color.Output, fmt.Sprintf("%s:%s", color.BlueString(tagInfo[0]), color.CyanString(strings.Join(tagInfo[1:], ":"))))
-				if i != len(tagItem.Tags)-1 {
-					fmt.Fprintf(color.Output, " ")
-				}
-			}
-			fmt.Fprintln(color.Output, "]")
+			fmt.Fprint(color.Output, "Tags: ")
+			printTags(tagItem.Tags)
 			fmt.Fprint(color.Output, "Sources: [")
 			sort.Slice(tagItem.Sources, func(i, j int) bool {
 				return tagItem.Sources[i] < tagItem.Sources[j]
@@ -87,9 +87,80 @@ var taggerListCommand = &cobra.Command{
 				}
 			}
 			fmt.Fprintln(color.Output, "]")
+			// the tags of every source, before they are merged by priority
+			for _, source := range tagItem.Sources {
+				fmt.Fprintf(color.Output, "  %s: ", color.BlueString(source))
+				printTags(tagItem.SourceTags[source])
+			}
+			printConflicts(tagItem.Conflicts)
 			fmt.Fprintln(color.Output, "===")
 		}
 
+		if len(tr.Stats) > 0 {
+			stats, _ := json.MarshalIndent(tr.Stats, "", "  ")
+			fmt.Fprintln(color.Output, fmt.Sprintf("\n=== Tagger stats ===\n%s", stats))
+		}
+
 		return nil
 	},
 }
+
+// printTags prints sorted tags, with their name and value highlighted
+func printTags(tags []string) {
+	sort.Strings(tags)
+	fmt.Fprint(color.Output, "[")
+	for i, tag := range tags {
+		tagInfo := strings.Split(tag, ":")
+		fmt.Fprintf(color.Output, fmt.Sprintf("%s:%s", color.BlueString(tagInfo[0]), color.CyanString(strings.Join(tagInfo[1:], ":"))))
+		if i != len(tags)-1 {
+			fmt.Fprintf(color.Output, " ")
+		}
+	}
+	fmt.Fprintln(color.Output, "]")
+}
+
+// printConflicts prints the tag keys sent with different values by several
+// sources, with the tags kept and dropped by priority
+func printConflicts(conflicts map[string]response.TaggerListConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+	tagNames := make([]string, 0, len(conflicts))
+	for tagName := range conflicts {
+		tagNames = append(tagNames, tagName)
+	}
+	sort.Strings(tagNames)
+
+	fmt.Fprintln(color.Output, "Conflicts:")
+	for _, tagName := range tagNames {
+		fmt.Fprintf(color.Output, "  %s:\n", color.YellowString(tagName))
+		for _, source := range sortedKeys(conflicts[tagName].Kept) {
+			fmt.Fprintf(color.Output, "    kept from %s: ", color.BlueString(source))
+			printTags(conflicts[tagName].Kept[source])
+		}
+		for _, source := range sortedKeys(conflicts[tagName].Dropped) {
+			fmt.Fprintf(color.Output, "    dropped from %s: ", color.BlueString(source))
+			printTags(conflicts[tagName].Dropped[source])
+		}
+	}
+}
+
+// sortedKeys returns the sorted sources of a tags by source map
+func sortedKeys(tags map[string][]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsAny returns whether the entity ID contains one of the filters
+func containsAny(entity string, filters []string) bool {
+	for _, filter := range filters {
+		if strings.Contains(entity, filter) {
+			return true
+		}
+	}
+	return false
+}
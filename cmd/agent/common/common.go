@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig"
 	"github.com/DataDog/datadog-agent/pkg/util/executable"
 )
 
@@ -33,6 +34,9 @@ var (
 	// MetadataScheduler is responsible to orchestrate metadata collection
 	MetadataScheduler *metadata.Scheduler
 
+	// RemoteConfig is the client of the remote configuration service, nil when disabled
+	RemoteConfig *remoteconfig.Client
+
 	// Forwarder is the global forwarder instance
 	Forwarder forwarder.Forwarder
 
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/config/watcher"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// configReloaders are the settings of datadog.yaml applied without restart
+var configReloaders = map[string]watcher.Reloader{
+	"log_level":            reloadLogLevel,
+	"tags":                 reloadTags,
+	"proxy":                reloadProxy,
+	"additional_endpoints": reloadAdditionalEndpoints,
+}
+
+// SetupConfigWatcher reloads the settings of datadog.yaml that can change
+// without restart when the file changes, checking it every
+// `config_watch_interval` seconds until MainCtx is done. The reloads are
+// reported to eventHandler.
+func SetupConfigWatcher(eventHandler func(metrics.Event)) {
+	interval := config.Datadog.GetInt("config_watch_interval")
+	if interval <= 0 {
+		return
+	}
+
+	var decrypt func([]byte, string) ([]byte, error)
+	if config.Datadog.GetString("secret_backend_command") != "" {
+		decrypt = secrets.Decrypt
+	}
+	w, err := watcher.NewWatcher(config.Datadog.ConfigFileUsed(), configReloaders, decrypt)
+	if err != nil {
+		log.Errorf("Unable to watch the configuration file: %s", err)
+		return
+	}
+	w.SetEventHandler(eventHandler)
+	go w.Run(MainCtx, time.Duration(interval)*time.Second)
+}
+
+func reloadLogLevel(value interface{}) error {
+	level := "info"
+	if value != nil {
+		level = cast.ToString(value)
+	}
+	return settings.SetRuntimeSettingFromSource("log_level", level, 0, settings.SourceConfigFile)
+}
+
+// reloadTags changes the host tags, sent with the next host metadata payload
+func reloadTags(value interface{}) error {
+	tags := []string{}
+	if value != nil {
+		var err error
+		if tags, err = cast.ToStringSliceE(value); err != nil {
+			return fmt.Errorf("invalid tags: %s", err)
+		}
+	}
+	config.Datadog.Set("tags", tags)
+	return nil
+}
+
+// reloadProxy changes the proxies used by the new requests. The proxies
+// authenticating connections are set up when the agent starts.
+func reloadProxy(value interface{}) error {
+	section := map[string]interface{}{}
+	if value != nil {
+		var err error
+		if section, err = cast.ToStringMapE(value); err != nil {
+			return fmt.Errorf("invalid proxy settings: %s", err)
+		}
+	}
+	p := &config.Proxy{
+		HTTP:     cast.ToString(section["http"]),
+		HTTPS:    cast.ToString(section["https"]),
+		NoProxy:  cast.ToStringSlice(section["no_proxy"]),
+		Auth:     cast.ToString(section["auth"]),
+		Username: cast.ToString(section["username"]),
+		Password: cast.ToString(section["password"]),
+	}
+	if current := config.GetProxies(); util.IsConnectionAuth(p.Auth) || current != nil && util.IsConnectionAuth(current.Auth) {
+		return watcher.ErrRestartRequired
+	}
+	config.ReloadProxies(p)
+	return nil
+}
+
+// reloadAdditionalEndpoints changes the api keys of the additional
+// endpoints, adding or removing domains requires a restart
+func reloadAdditionalEndpoints(value interface{}) error {
+	previous := config.Datadog.Get("additional_endpoints")
+	previousEndpoints, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return err
+	}
+
+	config.Datadog.Set("additional_endpoints", value)
+	endpoints, err := config.GetMultipleEndpoints()
+	if err == nil && !sameDomains(previousEndpoints, endpoints) {
+		err = watcher.ErrRestartRequired
+	}
+	if err == nil {
+		err = UpdateForwarderAPIKeys()
+	}
+	if err != nil {
+		config.Datadog.Set("additional_endpoints", previous)
+	}
+	return err
+}
+
+func sameDomains(a, b map[string][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for domain := range a {
+		if _, found := b[domain]; !found {
+			return false
+		}
+	}
+	return true
+}
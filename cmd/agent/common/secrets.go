@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// apiKeysUpdater is implemented by the forwarders whose api keys can be rotated at runtime
+type apiKeysUpdater interface {
+	UpdateAPIKeys(keysPerDomains map[string][]string, keysPerDomainsPerType map[string]map[string][]string) error
+}
+
+// UpdateForwarderAPIKeys makes the forwarder use the api keys of the configuration
+func UpdateForwarderAPIKeys() error {
+	updater, ok := Forwarder.(apiKeysUpdater)
+	if !ok {
+		return fmt.Errorf("the forwarder doesn't support rotating the API keys")
+	}
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return err
+	}
+	keysPerDomainPerType, err := config.GetAdditionalEndpointsPerType()
+	if err != nil {
+		return err
+	}
+	return updater.UpdateAPIKeys(keysPerDomain, keysPerDomainPerType)
+}
+
+// SetupSecretsRefresh reloads the api keys and the checks using secrets
+// changed by a refresh, and refreshes the secrets every
+// `secret_refresh_interval` seconds until MainCtx is done.
+func SetupSecretsRefresh() {
+	secrets.RegisterRefreshCallback(onSecretsRefresh)
+
+	interval := config.Datadog.GetInt("secret_refresh_interval")
+	if interval <= 0 || config.Datadog.GetString("secret_backend_command") == "" {
+		return
+	}
+	log.Infof("Refreshing the secrets every %d seconds", interval)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-MainCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := secrets.RefreshAll(); err != nil {
+					log.Errorf("Unable to refresh the secrets: %s", err)
+				}
+			}
+		}
+	}()
+}
+
+// onSecretsRefresh reloads the components using the origins of the secrets
+// that changed
+func onSecretsRefresh(origins []string) {
+	mainConfig := filepath.Base(config.Datadog.ConfigFileUsed())
+	for _, origin := range origins {
+		if origin == "datadog.yaml" || origin == mainConfig {
+			log.Info("Secrets used by the main configuration changed, reloading the API keys")
+			err := config.ReloadAPIKeys()
+			if err == nil {
+				err = UpdateForwarderAPIKeys()
+			}
+			if err != nil {
+				log.Errorf("Unable to reload the API keys: %s", err)
+			}
+			break
+		}
+	}
+
+	if AC != nil {
+		AC.ReloadConfigs(origins)
+	}
+}
@@ -135,6 +135,7 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
+	response.Resolutions = autodiscovery.GetResolutions()
 
 	jsonConfig, err := json.Marshal(response)
 	if err != nil {
@@ -22,6 +22,7 @@ import (
 func installClusterCheckEndpoints(r *mux.Router, sc clusteragent.ServerContext) {
 	r.HandleFunc("/clusterchecks/status/{nodeName}", postCheckStatus(sc)).Methods("POST")
 	r.HandleFunc("/clusterchecks/configs/{nodeName}", getCheckConfigs(sc)).Methods("GET")
+	r.HandleFunc("/clusterchecks/forwarded", postForwardedStatuses(sc)).Methods("POST")
 	r.HandleFunc("/clusterchecks", getState(sc)).Methods("GET")
 }
 
@@ -83,6 +84,35 @@ func getCheckConfigs(sc clusteragent.ServerContext) func(w http.ResponseWriter,
 	}
 }
 
+// postForwardedStatuses is used by the followers to forward the node-agent reports to the leader
+func postForwardedStatuses(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http.Request) {
+	if sc.ClusterCheckHandler == nil {
+		return clusterChecksDisabledHandler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !sc.ClusterCheckHandler.IsLeader() {
+			// No redirection, the follower drops the reports
+			http.Error(w, "not leading", http.StatusServiceUnavailable)
+			incrementRequestMetric("postForwardedStatuses", http.StatusServiceUnavailable)
+			return
+		}
+
+		decoder := json.NewDecoder(r.Body)
+		var forwarded cctypes.ForwardedStatuses
+		err := decoder.Decode(&forwarded)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			incrementRequestMetric("postForwardedStatuses", http.StatusInternalServerError)
+			return
+		}
+
+		sc.ClusterCheckHandler.PostForwardedStatuses(forwarded)
+		w.WriteHeader(http.StatusOK)
+		incrementRequestMetric("postForwardedStatuses", http.StatusOK)
+	}
+}
+
 // getState is used by the clustercheck config
 func getState(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http.Request) {
 	if sc.ClusterCheckHandler == nil {
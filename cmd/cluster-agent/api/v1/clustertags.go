@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// installClusterTagsEndpoints registers v1 API endpoints for the cluster tags
+func installClusterTagsEndpoints(r *mux.Router, sc clusteragent.ServerContext) {
+	r.HandleFunc("/tags/cluster", getClusterTags(sc)).Methods("GET")
+}
+
+// getClusterTags is used by the node agents to get the tags common to all the
+// nodes of the cluster
+func getClusterTags(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/tags/cluster
+			If-None-Match: "<version>" (optional)
+		Outputs
+			Status: 200
+			Returns: apiv1.ClusterTags
+			Example: {"version":"d4a1f7c2b3e5a6f8","tags":["kube_cluster_name:foo","project:bar"],"namespace_labels":{"default":{"team":"infra"}}}
+
+			Status: 304
+			The tags didn't change since the version sent in If-None-Match
+
+			Status: 404
+			Returns: string
+			Example: "cluster tags are not available"
+	*/
+	return func(w http.ResponseWriter, r *http.Request) {
+		if sc.ClusterTagsStore == nil {
+			http.Error(w, "cluster tags are not available", http.StatusNotFound)
+			incrementRequestMetric("getClusterTags", http.StatusNotFound)
+			return
+		}
+
+		clusterTags := sc.ClusterTagsStore.Get()
+		etag := strconv.Quote(clusterTags.Version)
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			incrementRequestMetric("getClusterTags", http.StatusNotModified)
+			return
+		}
+
+		tagsBytes, err := json.Marshal(clusterTags)
+		if err != nil {
+			log.Errorf("Could not process the cluster tags: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			incrementRequestMetric("getClusterTags", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(tagsBytes)
+		incrementRequestMetric("getClusterTags", http.StatusOK)
+	}
+}
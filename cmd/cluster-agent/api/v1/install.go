@@ -43,6 +43,8 @@ func Install(r *mux.Router, sc clusteragent.ServerContext) {
 	r.HandleFunc("/tags/pod/{nodeName}", getPodMetadataForNode).Methods("GET")
 	r.HandleFunc("/tags/pod", getAllMetadata).Methods("GET")
 	r.HandleFunc("/tags/node/{nodeName}", getNodeMetadata).Methods("GET")
+	r.HandleFunc("/tags/namespace/{ns}", getNamespaceMetadata).Methods("GET")
+	installClusterTagsEndpoints(r, sc)
 	installClusterCheckEndpoints(r, sc)
 	installEndpointsCheckEndpoints(r, sc)
 }
@@ -106,6 +108,47 @@ func getNodeMetadata(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf("Could not find labels on the node: %s", nodeName)))
 }
 
+// getNamespaceMetadata is only used when the node agent hits the DCA for the labels of a namespace
+func getNamespaceMetadata(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/tags/namespace/default
+		Outputs
+			Status: 200
+			Returns: map[string]string
+			Example: {"label1": "value1", "label2": "value2"}
+
+			Status: 404
+			Returns: string
+			Example: 404 page not found
+
+			Status: 500
+			Returns: string
+			Example: "namespaces \"foo\" not found"
+	*/
+
+	vars := mux.Vars(r)
+	var labelBytes []byte
+	ns := vars["ns"]
+	nsLabels, err := as.GetNamespaceLabels(ns)
+	if err != nil {
+		log.Errorf("Could not retrieve the labels of the namespace %s: %v", ns, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		incrementRequestMetric("getNamespaceMetadata", http.StatusInternalServerError)
+		return
+	}
+	labelBytes, err = json.Marshal(nsLabels)
+	if err != nil {
+		log.Errorf("Could not process the labels of the namespace %s: %v", ns, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		incrementRequestMetric("getNamespaceMetadata", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(labelBytes)
+	incrementRequestMetric("getNamespaceMetadata", http.StatusOK)
+}
+
 // getPodMetadata is only used when the node agent hits the DCA for the tags list.
 // It returns a list of all the tags that can be directly used in the tagger of the agent.
 func getPodMetadata(w http.ResponseWriter, r *http.Request) {
@@ -24,7 +24,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clustertags"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
@@ -158,7 +160,11 @@ func start(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	f := forwarder.NewDefaultForwarder(keysPerDomain)
+	keysPerDomainPerType, err := config.GetAdditionalEndpointsPerType()
+	if err != nil {
+		log.Error("Misconfiguration of agent endpoints per data type: ", err)
+	}
+	f := forwarder.NewDefaultForwarderWithEndpointsPerType(keysPerDomain, keysPerDomainPerType)
 	f.Start()
 	s := serializer.NewSerializer(f)
 
@@ -185,6 +191,9 @@ func start(cmd *cobra.Command, args []string) error {
 		if err := apiserver.StartControllers(ctx); err != nil {
 			log.Errorf("Could not start controllers: %v", err)
 		}
+		if config.Datadog.GetBool("admission_controller.enabled") {
+			setupAdmissionController(mainCtx, apiCl, le, stopCh)
+		}
 	}
 
 	// Setup a channel to catch OS signals
@@ -197,9 +206,13 @@ func start(cmd *cobra.Command, args []string) error {
 
 	// Start the cluster-check discovery if configured
 	clusterCheckHandler := setupClusterCheck(mainCtx)
+	// Collect the tags served to the node agents
+	clusterTagsStore := clustertags.NewStore()
+	go clusterTagsStore.Run(mainCtx)
 	// start the cmd HTTPS server
 	sc := clusteragent.ServerContext{
 		ClusterCheckHandler: clusterCheckHandler,
+		ClusterTagsStore:    clusterTagsStore,
 	}
 	if err = api.StartServer(sc); err != nil {
 		return log.Errorf("Error while starting api server, exiting: %v", err)
@@ -244,6 +257,23 @@ func start(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func setupAdmissionController(ctx context.Context, apiCl *apiserver.APIClient, le *leaderelection.LeaderEngine, stopCh chan struct{}) {
+	server, err := admission.NewServer()
+	if err != nil {
+		log.Errorf("Could not setup the admission controller: %v", err)
+		return
+	}
+	controller := admission.NewController(apiCl.Cl, le)
+	go controller.Run(stopCh)
+	go func() {
+		if err := server.Run(ctx, controller); err != nil {
+			log.Errorf("Error in the admission controller server: %v", err)
+		}
+	}()
+
+	log.Info("Started the admission controller")
+}
+
 func setupClusterCheck(ctx context.Context) *clusterchecks.Handler {
 	if !config.Datadog.GetBool("cluster_checks.enabled") {
 		log.Debug("Cluster check Autodiscovery disabled")
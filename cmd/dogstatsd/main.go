@@ -156,7 +156,11 @@ func start(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	f := forwarder.NewDefaultForwarder(keysPerDomain)
+	keysPerDomainPerType, err := config.GetAdditionalEndpointsPerType()
+	if err != nil {
+		log.Error("Misconfiguration of agent endpoints per data type: ", err)
+	}
+	f := forwarder.NewDefaultForwarderWithEndpointsPerType(keysPerDomain, keysPerDomainPerType)
 	f.Start()
 	s := serializer.NewSerializer(f)
 
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	zstd "github.com/DataDog/zstd.v0.5"
 
 	"github.com/DataDog/datadog-agent/pkg/process/checks"
 	"github.com/DataDog/datadog-agent/pkg/process/config"
@@ -21,6 +22,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
+func init() {
+	// the intake expects the payloads compressed with this version of zstd
+	model.RegisterZstd(zstd.Compress, zstd.Decompress)
+}
+
 type checkPayload struct {
 	messages []model.MessageBody
 	endpoint string
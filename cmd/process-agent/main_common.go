@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/process/statsd"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/remote"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -106,9 +107,19 @@ func runAgent(exit chan bool) {
 		os.Exit(1)
 	}
 
-	// Tagger must be initialized after agent config has been setup
-	tagger.Init()
-	defer tagger.Stop()
+	// Tagger must be initialized after agent config has been setup. The
+	// containers are tagged by the tagger of the core agent when its gRPC api
+	// is available, the process-agent doesn't collect their tags then.
+	if socketPath := ddconfig.Datadog.GetString("cmd_grpc_socket"); socketPath != "" {
+		tagger.InitCardinalities()
+		remoteTagger := remote.NewTagger(socketPath, tagger.ProcessCardinality)
+		remoteTagger.Start()
+		defer remoteTagger.Stop()
+		checks.UseRemoteTagger(remoteTagger)
+	} else {
+		tagger.Init()
+		defer tagger.Stop()
+	}
 
 	err = initInfo(cfg)
 	if err != nil {
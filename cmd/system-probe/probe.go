@@ -15,6 +15,8 @@ import (
 	"github.com/mailru/easyjson"
 
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/ebpf/oomkill"
+	"github.com/DataDog/datadog-agent/pkg/ebpf/tcpqueuelength"
 	"github.com/DataDog/datadog-agent/pkg/process/config"
 	"github.com/DataDog/datadog-agent/pkg/process/net"
 )
@@ -27,28 +29,32 @@ var ErrTracerUnsupported = errors.New("tracer unsupported")
 type SystemProbe struct {
 	cfg *config.AgentConfig
 
-	supported bool
-	tracer    *ebpf.Tracer
-	conn      net.Conn
+	supported  bool
+	tracer     *ebpf.Tracer
+	oomWatcher *oomkill.Watcher
+	conn       net.Conn
+	startTime  time.Time
 }
 
 // CreateSystemProbe creates a SystemProbe as well as it's UDS socket after confirming that the OS supports BPF-based
-// system probe
+// system probe. The tcp_queue_length and oom_kill checks are served even when the network tracer is unavailable.
 func CreateSystemProbe(cfg *config.AgentConfig) (*SystemProbe, error) {
 	var err error
 	nt := &SystemProbe{}
 
-	// Checking whether the current OS + kernel version is supported by the tracer
-	if nt.supported, err = ebpf.IsTracerSupportedByOS(cfg.ExcludedBPFLinuxVersions); err != nil {
-		return nil, fmt.Errorf("%s: %s", ErrTracerUnsupported, err)
+	if cfg.EnableOOMKill {
+		if nt.oomWatcher, err = oomkill.NewWatcher(); err != nil {
+			log.Errorf("unable to watch the OOM kills: %s", err)
+		}
 	}
 
-	log.Infof("Creating tracer for: %s", filepath.Base(os.Args[0]))
-
-	t, err := ebpf.NewTracer(config.SysProbeConfigFromConfig(cfg))
-	if err != nil {
-		return nil, err
+	if nt.tracer, err = createTracer(cfg); err != nil {
+		if nt.oomWatcher == nil && !cfg.EnableTCPQueueLength {
+			return nil, err
+		}
+		log.Errorf("unable to create the network tracer, only serving the checks: %s", err)
 	}
+	nt.supported = nt.tracer != nil
 
 	// Setting up the unix socket
 	uds, err := net.NewUDSListener(cfg)
@@ -56,12 +62,24 @@ func CreateSystemProbe(cfg *config.AgentConfig) (*SystemProbe, error) {
 		return nil, err
 	}
 
-	nt.tracer = t
 	nt.cfg = cfg
 	nt.conn = uds
+	nt.startTime = time.Now()
 	return nt, nil
 }
 
+// createTracer creates the network tracer after confirming that the OS supports it
+func createTracer(cfg *config.AgentConfig) (*ebpf.Tracer, error) {
+	// Checking whether the current OS + kernel version is supported by the tracer
+	if _, err := ebpf.IsTracerSupportedByOS(cfg.ExcludedBPFLinuxVersions); err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrTracerUnsupported, err)
+	}
+
+	log.Infof("Creating tracer for: %s", filepath.Base(os.Args[0]))
+
+	return ebpf.NewTracer(config.SysProbeConfigFromConfig(cfg))
+}
+
 // Run makes available the HTTP endpoint for network collection
 func (nt *SystemProbe) Run() {
 	// if a debug port is specified, we expose the default handler to that port
@@ -75,6 +93,46 @@ func (nt *SystemProbe) Run() {
 
 	httpMux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {})
 
+	httpMux.HandleFunc("/debug/status", func(w http.ResponseWriter, req *http.Request) {
+		writeAsJSON(w, nt.getStatus())
+	})
+
+	if nt.tracer != nil {
+		nt.handleNetwork(httpMux)
+	}
+
+	if nt.cfg.EnableTCPQueueLength {
+		procRoot := config.SysProbeConfigFromConfig(nt.cfg).ProcRoot
+		httpMux.HandleFunc("/check/tcp_queue_length", func(w http.ResponseWriter, req *http.Request) {
+			stats, err := tcpqueuelength.GetStats(procRoot)
+			if err != nil {
+				log.Errorf("unable to retrieve the tcp queue length stats: %s", err)
+				w.WriteHeader(500)
+				return
+			}
+
+			writeAsJSON(w, stats)
+		})
+	}
+
+	if nt.oomWatcher != nil {
+		httpMux.HandleFunc("/check/oom_kill", func(w http.ResponseWriter, req *http.Request) {
+			writeAsJSON(w, nt.oomWatcher.GetOOMKills())
+		})
+	}
+
+	go func() {
+		heartbeat := time.NewTicker(15 * time.Second)
+		for range heartbeat.C {
+			statsd.Client.Gauge("datadog.system_probe.agent", 1, []string{"version:" + Version}, 1)
+		}
+	}()
+
+	http.Serve(nt.conn.GetListener(), httpMux)
+}
+
+// handleNetwork registers the endpoints of the network tracer
+func (nt *SystemProbe) handleNetwork(httpMux *http.ServeMux) {
 	var runCounter uint64
 	httpMux.HandleFunc("/connections", func(w http.ResponseWriter, req *http.Request) {
 		start := time.Now()
@@ -124,14 +182,57 @@ func (nt *SystemProbe) Run() {
 		writeAsJSON(w, stats)
 	})
 
-	go func() {
-		heartbeat := time.NewTicker(15 * time.Second)
-		for range heartbeat.C {
-			statsd.Client.Gauge("datadog.system_probe.agent", 1, []string{"version:" + Version}, 1)
+	httpMux.HandleFunc("/debug/ebpf", func(w http.ResponseWriter, req *http.Request) {
+		info, err := nt.tracer.DebugEBPF()
+		if err != nil {
+			log.Errorf("unable to retrieve the eBPF programs and maps: %s", err)
+			w.WriteHeader(500)
+			return
 		}
-	}()
 
-	http.Serve(nt.conn.GetListener(), httpMux)
+		writeAsJSON(w, info)
+	})
+
+	httpMux.HandleFunc("/debug/offsets", func(w http.ResponseWriter, req *http.Request) {
+		offsets, err := nt.tracer.DebugOffsets()
+		if err != nil {
+			log.Errorf("unable to retrieve the guessed offsets: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		writeAsJSON(w, offsets)
+	})
+
+	httpMux.HandleFunc("/debug/errors", func(w http.ResponseWriter, req *http.Request) {
+		errors, err := nt.tracer.DebugErrors()
+		if err != nil {
+			log.Errorf("unable to retrieve the tracer errors: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		writeAsJSON(w, errors)
+	})
+}
+
+// getStatus returns the version and the features enabled in the system-probe
+func (nt *SystemProbe) getStatus() map[string]interface{} {
+	status := map[string]interface{}{
+		"version":      Version,
+		"git_commit":   GitCommit,
+		"go_version":   GoVersion,
+		"uptime":       time.Since(nt.startTime).String(),
+		"network":      nt.tracer != nil,
+		"conntrack":    nt.cfg.EnableConntrack,
+		"tcp_queue":    nt.cfg.EnableTCPQueueLength,
+		"oom_kill":     nt.oomWatcher != nil,
+		"collect_ipv6": !nt.cfg.DisableIPv6Tracing,
+	}
+	if kernelVersion, err := ebpf.CurrentKernelVersion(); err == nil {
+		status["kernel_version_code"] = kernelVersion
+	}
+	return status
 }
 
 func logRequests(client string, count uint64, connectionsCount int, start time.Time) {
@@ -177,5 +278,10 @@ func writeAsJSON(w http.ResponseWriter, data interface{}) {
 // Close will stop all system probe activities
 func (nt *SystemProbe) Close() {
 	nt.conn.Stop()
-	nt.tracer.Stop()
+	if nt.tracer != nil {
+		nt.tracer.Stop()
+	}
+	if nt.oomWatcher != nil {
+		nt.oomWatcher.Close()
+	}
 }
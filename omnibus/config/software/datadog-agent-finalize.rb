@@ -37,6 +37,8 @@ build do
             delete "#{conf_dir}/apm.yaml.default"
             # load isn't supported by windows
             delete "#{conf_dir}/load.d"
+            # nvml is only built on linux
+            delete "#{conf_dir}/nvml.d"
 
             # cleanup clutter
             delete "#{install_dir}/etc"
@@ -86,6 +88,8 @@ build do
 
             # remove windows specific configs
             delete "/etc/datadog-agent/conf.d/winproc.d"
+            delete "/etc/datadog-agent/conf.d/winservices.d"
+            delete "/etc/datadog-agent/conf.d/wineventlog.d"
 
             # cleanup clutter
             delete "#{install_dir}/etc"
@@ -103,9 +107,17 @@ build do
         elsif osx?
             # Remove linux specific configs
             delete "#{install_dir}/etc/conf.d/file_handle.d"
+            delete "#{install_dir}/etc/conf.d/tcp_queue_length.d"
+            delete "#{install_dir}/etc/conf.d/oom_kill.d"
+            delete "#{install_dir}/etc/conf.d/nvml.d"
+            delete "#{install_dir}/etc/conf.d/kernel_limits.d"
+            delete "#{install_dir}/etc/conf.d/journald.d"
+            delete "#{install_dir}/etc/conf.d/sensors.d"
 
             # remove windows specific configs
             delete "#{install_dir}/etc/conf.d/winproc.d"
+            delete "#{install_dir}/etc/conf.d/winservices.d"
+            delete "#{install_dir}/etc/conf.d/wineventlog.d"
 
             # Nothing to move on osx, the confs already live in /opt/datadog-agent/etc/
         end
@@ -6,6 +6,7 @@
 package aggregator
 
 import (
+	"context"
 	"expvar"
 	"fmt"
 	"sort"
@@ -21,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 )
 
 // DefaultFlushInterval aggregator default flush interval
@@ -51,6 +53,7 @@ func newFlushTimeStats(name string) {
 
 func addFlushTime(name string, value int64) {
 	flushTimeStats[name].add(value)
+	tlmFlushTime.Observe(float64(value)/float64(time.Second), name)
 }
 
 func newFlushCountStats(name string) {
@@ -59,6 +62,7 @@ func newFlushCountStats(name string) {
 
 func addFlushCount(name string, value int64) {
 	flushCountStats[name].add(value)
+	tlmFlushed.Add(float64(value), name)
 }
 
 func expStatsMap(statsMap map[string]*Stats) func() interface{} {
@@ -94,6 +98,17 @@ var (
 	aggregatorEvent                   = expvar.Int{}
 	aggregatorHostnameUpdate          = expvar.Int{}
 
+	tlmFlushed     = telemetry.NewCounter("aggregator", "flushed", []string{"data_type"}, "Number of series, sketches, service checks and events flushed.")
+	tlmFlushErrors = telemetry.NewCounter("aggregator", "flush_errors", []string{"data_type"}, "Number of flushes that failed to be sent to the forwarder.")
+	tlmFlushTime   = telemetry.NewHistogram("aggregator", "flush_time_seconds", []string{"flush"}, "Duration of the flushes.", nil)
+	tlmProcessed   = telemetry.NewCounter("aggregator", "processed", []string{"data_type"}, "Number of samples, service checks and events received by the aggregator.")
+	// the counters of the samples are resolved once, they're incremented
+	// for every sample
+	tlmCheckMetrics     = tlmProcessed.WithValues("check_metrics")
+	tlmDogstatsdMetrics = tlmProcessed.WithValues("dogstatsd_metrics")
+	tlmServiceChecks    = tlmProcessed.WithValues("service_checks")
+	tlmEvents           = tlmProcessed.WithValues("events")
+
 	// Hold series to be added to aggregated series on each flush
 	recurrentSeries     metrics.Series
 	recurrentSeriesLock sync.Mutex
@@ -144,6 +159,11 @@ func InitAggregatorWithFlushInterval(s serializer.MetricSerializer, hostname, ag
 	return aggregatorInstance
 }
 
+// GetDefaultAggregator returns the Singleton instance if it is initialized, nil otherwise.
+func GetDefaultAggregator() *BufferedAggregator {
+	return aggregatorInstance
+}
+
 // SetDefaultAggregator allows to force a custom Aggregator as the default one and run it.
 // This is useful for testing or benchmarking.
 func SetDefaultAggregator(agg *BufferedAggregator) {
@@ -173,8 +193,9 @@ type BufferedAggregator struct {
 	serializer         serializer.MetricSerializer
 	hostname           string
 	hostnameUpdate     chan string
-	hostnameUpdateDone chan struct{}    // signals that the hostname update is finished
-	TickerChan         <-chan time.Time // For test/benchmark purposes: it allows the flush to be controlled from the outside
+	hostnameUpdateDone chan struct{}      // signals that the hostname update is finished
+	forceFlush         chan chan struct{} // receives the requests of ForceFlush
+	TickerChan         <-chan time.Time   // For test/benchmark purposes: it allows the flush to be controlled from the outside
 	health             *health.Handle
 	agentName          string // Name of the agent for telemetry metrics (agent / cluster-agent)
 }
@@ -200,6 +221,7 @@ func NewBufferedAggregator(s serializer.MetricSerializer, hostname, agentName st
 		hostname:           hostname,
 		hostnameUpdate:     make(chan string),
 		hostnameUpdateDone: make(chan struct{}),
+		forceFlush:         make(chan chan struct{}),
 		health:             health.Register("aggregator"),
 		agentName:          agentName,
 	}
@@ -344,7 +366,13 @@ func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, tim
 
 // GetSeries grabs all the series from the queue and clears the queue
 func (agg *BufferedAggregator) GetSeries() metrics.Series {
-	series := agg.sampler.flush(timeNowNano())
+	return agg.getSeries(timeNowNano())
+}
+
+// getSeries grabs the series of the buckets ending before timestamp and
+// clears them
+func (agg *BufferedAggregator) getSeries(timestamp float64) metrics.Series {
+	series := agg.sampler.flush(timestamp)
 	agg.mu.Lock()
 	for _, checkSampler := range agg.checkSamplers {
 		series = append(series, checkSampler.flush()...)
@@ -353,8 +381,8 @@ func (agg *BufferedAggregator) GetSeries() metrics.Series {
 	return series
 }
 
-func (agg *BufferedAggregator) flushSeries(start time.Time) {
-	series := agg.GetSeries()
+func (agg *BufferedAggregator) flushSeries(start time.Time, timestamp float64) {
+	series := agg.getSeries(timestamp)
 
 	recurrentSeriesLock.Lock()
 	// Adding recurrentSeries to the flushed ones
@@ -408,6 +436,15 @@ func (agg *BufferedAggregator) flushSeries(start time.Time) {
 		SourceTypeName: "System",
 	})
 
+	// Send along a metric that counts the number of times we had to split payloads because they were too big.
+	series = append(series, &metrics.Serie{
+		Name:           fmt.Sprintf("n_o_i_n_d_e_x.datadog.%s.payload.split", agg.agentName),
+		Points:         []metrics.Point{{Value: float64(split.GetPayloadSplits()), Ts: float64(start.Unix())}},
+		Host:           agg.hostname,
+		MType:          metrics.APIGaugeType,
+		SourceTypeName: "System",
+	})
+
 	addFlushCount("Series", int64(len(series)))
 
 	// For debug purposes print out all metrics/tag combinations
@@ -425,6 +462,7 @@ func (agg *BufferedAggregator) flushSeries(start time.Time) {
 		if err != nil {
 			log.Warnf("Error flushing series: %v", err)
 			aggregatorSeriesFlushErrors.Add(1)
+			tlmFlushErrors.Inc("Series")
 		}
 		addFlushTime("ChecksMetricSampleFlushTime", int64(time.Since(start)))
 		aggregatorSeriesFlushed.Add(int64(len(series)))
@@ -467,6 +505,7 @@ func (agg *BufferedAggregator) flushServiceChecks(start time.Time) {
 		if err != nil {
 			log.Warnf("Error flushing service checks: %v", err)
 			aggregatorServiceCheckFlushErrors.Add(1)
+			tlmFlushErrors.Inc("ServiceChecks")
 		}
 		addFlushTime("ServiceCheckFlushTime", int64(time.Since(start)))
 		aggregatorServiceCheckFlushed.Add(int64(len(serviceChecks)))
@@ -475,15 +514,21 @@ func (agg *BufferedAggregator) flushServiceChecks(start time.Time) {
 
 // GetSketches grabs all the sketches from the queue and clears the queue
 func (agg *BufferedAggregator) GetSketches() metrics.SketchSeriesList {
+	return agg.getSketches(timeNowNano())
+}
+
+// getSketches grabs the sketches of the buckets ending before timestamp and
+// clears them
+func (agg *BufferedAggregator) getSketches(timestamp float64) metrics.SketchSeriesList {
 	agg.mu.Lock()
 	defer agg.mu.Unlock()
 
-	return agg.distSampler.flush(timeNowNano())
+	return agg.distSampler.flush(timestamp)
 }
 
-func (agg *BufferedAggregator) flushSketches(start time.Time) {
+func (agg *BufferedAggregator) flushSketches(start time.Time, timestamp float64) {
 	// Serialize and forward in a separate goroutine
-	sketchSeries := agg.GetSketches()
+	sketchSeries := agg.getSketches(timestamp)
 	addFlushCount("Sketches", int64(len(sketchSeries)))
 	if len(sketchSeries) == 0 {
 		return
@@ -495,6 +540,7 @@ func (agg *BufferedAggregator) flushSketches(start time.Time) {
 		if err != nil {
 			log.Warnf("Error flushing sketch: %v", err)
 			aggregatorSketchesFlushErrors.Add(1)
+			tlmFlushErrors.Inc("Sketches")
 		}
 		addFlushTime("MetricSketchFlushTime", int64(time.Since(start)))
 		aggregatorSketchesFlushed.Add(int64(len(sketchSeries)))
@@ -533,6 +579,7 @@ func (agg *BufferedAggregator) flushEvents(start time.Time) {
 		if err != nil {
 			log.Warnf("Error flushing events: %v", err)
 			aggregatorEventsFlushErrors.Add(1)
+			tlmFlushErrors.Inc("Events")
 		}
 		addFlushTime("EventFlushTime", int64(time.Since(start)))
 		aggregatorEventsFlushed.Add(int64(len(events)))
@@ -540,12 +587,38 @@ func (agg *BufferedAggregator) flushEvents(start time.Time) {
 }
 
 func (agg *BufferedAggregator) flush(start time.Time) {
-	agg.flushSeries(start)
-	agg.flushSketches(start)
+	agg.flushBefore(start, timeNowNano())
+}
+
+// flushBefore flushes the service checks, the events and the metrics of the
+// buckets ending before timestamp.
+func (agg *BufferedAggregator) flushBefore(start time.Time, timestamp float64) {
+	agg.flushSeries(start, timestamp)
+	agg.flushSketches(start, timestamp)
 	agg.flushServiceChecks(start)
 	agg.flushEvents(start)
 }
 
+// ForceFlush flushes the data received so far right away, including the
+// metrics of the current bucket, and returns once it is handed over to the
+// serializer. It is meant for the serverless mode where the agent can be
+// frozen at any time after an invocation. It gives up when ctx is done, as
+// the aggregator may be busy or stopped.
+func (agg *BufferedAggregator) ForceFlush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case agg.forceFlush <- done:
+	case <-ctx.Done():
+		return fmt.Errorf("the aggregator didn't start the flush: %s", ctx.Err())
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the aggregator didn't complete the flush: %s", ctx.Err())
+	}
+}
+
 func (agg *BufferedAggregator) run() {
 	if agg.TickerChan == nil {
 		flushPeriod := agg.flushInterval
@@ -559,33 +632,46 @@ func (agg *BufferedAggregator) run() {
 			agg.flush(start)
 			addFlushTime("MainFlushTime", int64(time.Since(start)))
 			aggregatorNumberOfFlush.Add(1)
+		case done := <-agg.forceFlush:
+			start := time.Now()
+			agg.flushBefore(start, timeNowNano()+bucketSize)
+			addFlushTime("MainFlushTime", int64(time.Since(start)))
+			aggregatorNumberOfFlush.Add(1)
+			close(done)
 
 		case checkMetric := <-agg.checkMetricIn:
 			aggregatorChecksMetricSample.Add(1)
+			tlmCheckMetrics.Inc()
 			agg.handleSenderSample(checkMetric)
 
 		case metric := <-agg.metricIn:
 			aggregatorDogstatsdMetricSample.Add(1)
+			tlmDogstatsdMetrics.Inc()
 			agg.addSample(metric, timeNowNano())
 		case event := <-agg.eventIn:
 			aggregatorEvent.Add(1)
+			tlmEvents.Inc()
 			agg.addEvent(event)
 		case serviceCheck := <-agg.serviceCheckIn:
 			aggregatorServiceCheck.Add(1)
+			tlmServiceChecks.Inc()
 			agg.addServiceCheck(serviceCheck)
 
 		case metrics := <-agg.bufferedMetricIn:
 			aggregatorDogstatsdMetricSample.Add(int64(len(metrics)))
+			tlmDogstatsdMetrics.Add(float64(len(metrics)))
 			for _, sample := range metrics {
 				agg.addSample(sample, timeNowNano())
 			}
 		case serviceChecks := <-agg.bufferedServiceCheckIn:
 			aggregatorServiceCheck.Add(int64(len(serviceChecks)))
+			tlmServiceChecks.Add(float64(len(serviceChecks)))
 			for _, serviceCheck := range serviceChecks {
 				agg.addServiceCheck(*serviceCheck)
 			}
 		case events := <-agg.bufferedEventIn:
 			aggregatorEvent.Add(int64(len(events)))
+			tlmEvents.Add(float64(len(events)))
 			for _, event := range events {
 				agg.addEvent(*event)
 			}
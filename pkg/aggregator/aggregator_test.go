@@ -7,6 +7,7 @@ package aggregator
 
 import (
 	// stdlib
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -173,6 +174,12 @@ func TestDefaultData(t *testing.T) {
 		Host:           agg.hostname,
 		MType:          metrics.APIGaugeType,
 		SourceTypeName: "System",
+	}, &metrics.Serie{
+		Name:           fmt.Sprintf("n_o_i_n_d_e_x.datadog.%s.payload.split", agg.agentName),
+		Points:         []metrics.Point{{Value: 0, Ts: float64(start.Unix())}},
+		Host:           agg.hostname,
+		MType:          metrics.APIGaugeType,
+		SourceTypeName: "System",
 	}}
 
 	s.On("SendSeries", series).Return(nil).Times(1)
@@ -239,6 +246,12 @@ func TestRecurentSeries(t *testing.T) {
 		Host:           agg.hostname,
 		MType:          metrics.APIGaugeType,
 		SourceTypeName: "System",
+	}, &metrics.Serie{
+		Name:           fmt.Sprintf("n_o_i_n_d_e_x.datadog.%s.payload.split", agg.agentName),
+		Points:         []metrics.Point{{Value: 0, Ts: float64(start.Unix())}},
+		Host:           agg.hostname,
+		MType:          metrics.APIGaugeType,
+		SourceTypeName: "System",
 	}}
 
 	s.On("SendServiceChecks", agentUp).Return(nil).Times(1)
@@ -256,3 +269,32 @@ func TestRecurentSeries(t *testing.T) {
 	s.AssertNotCalled(t, "SendSketch")
 
 }
+
+func TestGetSeriesOfCurrentBucket(t *testing.T) {
+	resetAggregator()
+	agg := NewBufferedAggregator(nil, "hostname", "agent", DefaultFlushInterval)
+
+	now := timeNowNano()
+	agg.addSample(&metrics.MetricSample{
+		Name:       "my.metric",
+		Value:      1,
+		Mtype:      metrics.GaugeType,
+		SampleRate: 1,
+	}, now)
+
+	// the current bucket is only flushed by a forced flush
+	assert.Len(t, agg.getSeries(now), 0)
+	series := agg.getSeries(now + bucketSize)
+	require.Len(t, series, 1)
+	assert.Equal(t, "my.metric", series[0].Name)
+}
+
+func TestForceFlushTimeout(t *testing.T) {
+	resetAggregator()
+	agg := NewBufferedAggregator(nil, "hostname", "agent", DefaultFlushInterval)
+
+	// the aggregator isn't running, the flush gives up after the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, agg.ForceFlush(ctx))
+}
@@ -32,8 +32,14 @@ func Serve(ctx context.Context, port int) error {
 		return err
 	}
 
+	// The liveness is served on any other path, as it used to be the only check
+	mux := http.NewServeMux()
+	mux.Handle("/live", healthHandler{getStatus: health.GetLiveNonBlocking})
+	mux.Handle("/ready", healthHandler{getStatus: health.GetReadyNonBlocking})
+	mux.Handle("/", healthHandler{getStatus: health.GetLiveNonBlocking})
+
 	srv := &http.Server{
-		Handler:           healthHandler{},
+		Handler:           mux,
 		ReadTimeout:       defaultTimeout,
 		ReadHeaderTimeout: defaultTimeout,
 		WriteTimeout:      defaultTimeout,
@@ -54,12 +60,17 @@ func closeOnContext(ctx context.Context, srv *http.Server) {
 	srv.Shutdown(timeout)
 }
 
-type healthHandler struct{}
+// healthHandler serves the status of the components, with a 500 status
+// code when one of them is unhealthy
+type healthHandler struct {
+	getStatus func() (health.Status, error)
+}
 
 func (h healthHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
-	health, err := health.GetStatusNonBlocking()
+	health, err := h.getStatus()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	if len(health.Unhealthy) > 0 {
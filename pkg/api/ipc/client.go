@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package ipc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/DataDog/datadog-agent/pkg/api/pb"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	taggerpb "github.com/DataDog/datadog-agent/pkg/tagger/pb"
+)
+
+const (
+	dialTimeout = 5 * time.Second
+	callTimeout = 5 * time.Second
+)
+
+// Client is a typed client of the gRPC api of the core agent
+type Client struct {
+	conn *grpc.ClientConn
+
+	Agent        pb.AgentClient
+	Config       pb.ConfigClient
+	WorkloadMeta pb.WorkloadMetaClient
+	Tagger       taggerpb.TaggerClient
+}
+
+// NewClient connects to the api served on socketPath, authenticating with
+// the IPC certificate created by the core agent. Requires that the config has been set up before
+// calling.
+func NewClient(socketPath string) (*Client, error) {
+	if socketPath == "" {
+		return nil, fmt.Errorf("cmd_grpc_socket is not set")
+	}
+	cert, pool, err := security.FetchIPCCert()
+	if err != nil {
+		return nil, err
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		ServerName:   "localhost",
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+	})
+	dialer := func(addr string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout("unix", addr, timeout)
+	}
+	conn, err := grpc.Dial(socketPath,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDialer(dialer),
+		grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %s", socketPath, err)
+	}
+	return &Client{
+		conn:         conn,
+		Agent:        pb.NewAgentClient(conn),
+		Config:       pb.NewConfigClient(conn),
+		WorkloadMeta: pb.NewWorkloadMetaClient(conn),
+		Tagger:       taggerpb.NewTaggerClient(conn),
+	}, nil
+}
+
+// Close closes the connection to the api
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// GetHostname returns the hostname of the core agent serving the api on
+// socketPath
+func GetHostname(socketPath string) (string, error) {
+	client, err := NewClient(socketPath)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	reply, err := client.Agent.GetHostname(ctx, &pb.HostnameRequest{})
+	if err != nil {
+		return "", err
+	}
+	return reply.Hostname, nil
+}
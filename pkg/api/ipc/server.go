@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package ipc implements the gRPC api between the agent processes: the
+services exposed by the core agent, the unix socket they are served on,
+and the typed client used by the other agent processes.
+*/
+package ipc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/api/pb"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	taggerpb "github.com/DataDog/datadog-agent/pkg/tagger/pb"
+	taggerserver "github.com/DataDog/datadog-agent/pkg/tagger/server"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/collectors"
+)
+
+// For testing purpose
+var (
+	getHostname    = util.GetHostname
+	listContainers = detectContainers
+	tag            = tagger.Tag
+)
+
+var (
+	detector     *collectors.Detector
+	detectorLock sync.Mutex
+)
+
+// Server implements the Agent, Config and WorkloadMeta gRPC services
+type Server struct{}
+
+// NewServer returns a new Server
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Register registers the services of the api, including the tagger
+// stream, on a gRPC server
+func Register(s *grpc.Server) {
+	srv := NewServer()
+	pb.RegisterAgentServer(s, srv)
+	pb.RegisterConfigServer(s, srv)
+	pb.RegisterWorkloadMetaServer(s, srv)
+	taggerpb.RegisterTaggerServer(s, taggerserver.NewServer())
+}
+
+// GetHostname returns the hostname of the agent
+func (s *Server) GetHostname(ctx context.Context, in *pb.HostnameRequest) (*pb.HostnameReply, error) {
+	hostname, err := getHostname()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get the hostname: %s", err)
+	}
+	return &pb.HostnameReply{Hostname: hostname}, nil
+}
+
+// GetConfig returns the JSON encoded values of the requested configuration
+// keys, the keys that aren't set are left out of the reply
+func (s *Server) GetConfig(ctx context.Context, in *pb.ConfigRequest) (*pb.ConfigReply, error) {
+	values := make(map[string]string, len(in.Keys))
+	for _, key := range in.Keys {
+		if !config.Datadog.IsSet(key) {
+			continue
+		}
+		raw, err := json.Marshal(util.GetJSONSerializableMap(config.Datadog.Get(key)))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to encode %s: %s", key, err)
+		}
+		values[key] = string(raw)
+	}
+	return &pb.ConfigReply{Values: values}, nil
+}
+
+// ListContainers returns the containers running on the host, with their
+// tags at the requested cardinality
+func (s *Server) ListContainers(ctx context.Context, in *pb.ContainersRequest) (*pb.ContainersReply, error) {
+	cardinality, err := taggerserver.ToTagCardinality(in.Cardinality)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctrs, err := listContainers()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "unable to list the containers: %s", err)
+	}
+	reply := &pb.ContainersReply{Containers: make([]*pb.Container, 0, len(ctrs))}
+	for _, ctr := range ctrs {
+		tags, err := tag(ctr.EntityID, cardinality)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to get the tags of %s: %s", ctr.EntityID, err)
+		}
+		reply.Containers = append(reply.Containers, &pb.Container{
+			Id:       ctr.ID,
+			EntityId: ctr.EntityID,
+			Name:     ctr.Name,
+			Image:    ctr.Image,
+			State:    ctr.State,
+			Created:  ctr.Created,
+			Tags:     tags,
+		})
+	}
+	return reply, nil
+}
+
+// detectContainers lists the containers with the preferred source
+func detectContainers() ([]*containers.Container, error) {
+	detectorLock.Lock()
+	defer detectorLock.Unlock()
+	if detector == nil {
+		detector = collectors.NewDetector("")
+	}
+	l, _, err := detector.GetPreferred()
+	if err != nil {
+		return nil, err
+	}
+	return l.List()
+}
+
+// Listen listens on the unix socket of the api, readable by the user of
+// the agent only. A stale socket left by a previous run is removed.
+func Listen(socketPath string) (net.Listener, error) {
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to remove the stale socket %s: %s", socketPath, err)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("unable to set the permissions of %s: %s", socketPath, err)
+	}
+	return listener, nil
+}
+
+// ServerCredentials returns the mutual TLS credentials of the api server:
+// the clients must present the IPC certificate, created if it doesn't exist
+func ServerCredentials() (credentials.TransportCredentials, error) {
+	cert, pool, err := security.CreateOrFetchIPCCert()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}), nil
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build !windows
+
+package ipc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/api/pb"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	tagcollectors "github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	taggerpb "github.com/DataDog/datadog-agent/pkg/tagger/pb"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func TestServerClient(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("ipc_cert_file_path", filepath.Join(testDir, "ipc_cert.pem"))
+	defer mockConfig.Set("ipc_cert_file_path", "")
+	mockConfig.Set("cmd_port", 5101)
+
+	getHostname = func() (string, error) { return "my-host", nil }
+	listContainers = func() ([]*containers.Container, error) {
+		return []*containers.Container{{ID: "abc", EntityID: "docker://abc", Name: "redis", Image: "redis:5", State: containers.ContainerRunningState}}, nil
+	}
+	tag = func(entity string, cardinality tagcollectors.TagCardinality) ([]string, error) {
+		if cardinality != tagcollectors.OrchestratorCardinality {
+			return []string{"short_image:redis"}, nil
+		}
+		return []string{"container_id:" + entity}, nil
+	}
+	defer func() {
+		getHostname = util.GetHostname
+		listContainers = detectContainers
+		tag = tagger.Tag
+	}()
+
+	socketPath := filepath.Join(testDir, "agent.sock")
+	listener, err := Listen(socketPath)
+	require.NoError(t, err)
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	creds, err := ServerCredentials()
+	require.NoError(t, err)
+	s := grpc.NewServer(grpc.Creds(creds))
+	Register(s)
+	go s.Serve(listener)
+	defer s.Stop()
+
+	client, err := NewClient(socketPath)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	hostname, err := client.Agent.GetHostname(ctx, &pb.HostnameRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "my-host", hostname.Hostname)
+
+	values, err := client.Config.GetConfig(ctx, &pb.ConfigRequest{Keys: []string{"cmd_port", "not_set"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cmd_port": "5101"}, values.Values)
+
+	// the tags are at the requested cardinality
+	ctrs, err := client.WorkloadMeta.ListContainers(ctx, &pb.ContainersRequest{Cardinality: taggerpb.TagCardinality_ORCHESTRATOR})
+	require.NoError(t, err)
+	require.Len(t, ctrs.Containers, 1)
+	assert.Equal(t, "redis", ctrs.Containers[0].Name)
+	assert.Equal(t, []string{"container_id:docker://abc"}, ctrs.Containers[0].Tags)
+	ctrs, err = client.WorkloadMeta.ListContainers(ctx, &pb.ContainersRequest{})
+	require.NoError(t, err)
+	require.Len(t, ctrs.Containers, 1)
+	assert.Equal(t, []string{"short_image:redis"}, ctrs.Containers[0].Tags)
+	_, err = client.WorkloadMeta.ListContainers(ctx, &pb.ContainersRequest{Cardinality: 42})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the clients without the IPC certificate are rejected
+	mockConfig.Set("ipc_cert_file_path", filepath.Join(testDir, "other_cert.pem"))
+	_, _, err = security.CreateOrFetchIPCCert()
+	require.NoError(t, err)
+	_, err = NewClient(socketPath)
+	assert.Error(t, err)
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	socketPath := filepath.Join(testDir, "agent.sock")
+	require.NoError(t, ioutil.WriteFile(socketPath, []byte("stale"), 0600))
+	listener, err := Listen(socketPath)
+	require.NoError(t, err)
+	listener.Close()
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package security
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// minForcedReload rate-limits the reloads triggered by unknown tokens
+const minForcedReload = 5 * time.Second
+
+// ClusterAgentAuthToken holds the token shared between the Cluster Agent and
+// the node agents. When it is read from a file, it is reloaded periodically
+// so that it can be rotated by updating the file (a mounted secret) without
+// restarting the agents. After a rotation, the previous token stays valid for
+// a grace period, while the other agents load the new one.
+type ClusterAgentAuthToken struct {
+	m               sync.Mutex
+	current         string
+	previous        string
+	previousExpiry  time.Time
+	lastLoad        time.Time
+	refreshInterval time.Duration
+	gracePeriod     time.Duration
+	load            func() (string, error)
+}
+
+// NewClusterAgentAuthToken loads the cluster agent auth token, see GetClusterAgentAuthToken
+func NewClusterAgentAuthToken() (*ClusterAgentAuthToken, error) {
+	t := &ClusterAgentAuthToken{
+		refreshInterval: config.Datadog.GetDuration("cluster_agent.auth_token_refresh_interval") * time.Second,
+		gracePeriod:     config.Datadog.GetDuration("cluster_agent.auth_token_grace_period") * time.Second,
+		load:            GetClusterAgentAuthToken,
+	}
+	if config.Datadog.GetString("cluster_agent.auth_token") != "" {
+		// The configured token can't change at runtime
+		t.refreshInterval = 0
+	}
+
+	// Like GetClusterAgentAuthToken, the token is returned along with validation errors
+	token, err := t.load()
+	t.current = token
+	t.lastLoad = time.Now()
+	return t, err
+}
+
+// Get returns the current token, reloading it when the refresh interval elapsed.
+func (t *ClusterAgentAuthToken) Get() string {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.refreshInterval > 0 && time.Since(t.lastLoad) >= t.refreshInterval {
+		t.reload()
+	}
+	return t.current
+}
+
+// IsValid returns true if the token is the current token, or the previous
+// one within its grace period. An unknown token triggers a reload, in case
+// the other agent loaded a rotated token first.
+func (t *ClusterAgentAuthToken) IsValid(token string) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.refreshInterval > 0 && time.Since(t.lastLoad) >= t.refreshInterval {
+		t.reload()
+	}
+	if t.isValid(token) {
+		return true
+	}
+	if t.refreshInterval > 0 && time.Since(t.lastLoad) >= minForcedReload {
+		t.reload()
+		return t.isValid(token)
+	}
+	return false
+}
+
+func (t *ClusterAgentAuthToken) isValid(token string) bool {
+	if token == "" {
+		return false
+	}
+	if token == t.current {
+		return true
+	}
+	return token == t.previous && time.Now().Before(t.previousExpiry)
+}
+
+// reload loads the token, keeping the previous one valid if it changed.
+// Errors keep the current token in use.
+func (t *ClusterAgentAuthToken) reload() {
+	t.lastLoad = time.Now()
+	token, err := t.load()
+	if err != nil {
+		log.Warnf("Cannot reload the cluster agent auth token, keeping the current one: %s", err)
+		return
+	}
+	if token == t.current {
+		return
+	}
+	log.Infof("The cluster agent auth token changed, the previous one stays valid for %s", t.gracePeriod)
+	t.previous = t.current
+	t.previousExpiry = t.lastLoad.Add(t.gracePeriod)
+	t.current = token
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package security
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	firstToken  = "01234567890123456789012345678901"
+	secondToken = "abcdefghijabcdefghijabcdefghijab"
+)
+
+func TestClusterAgentAuthTokenFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "cluster-agent-token-")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(firstToken + "\n")
+	require.NoError(t, err)
+	f.Close()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("cluster_agent.auth_token", "")
+	mockConfig.Set("cluster_agent.auth_token_file_path", f.Name())
+	defer mockConfig.Set("cluster_agent.auth_token_file_path", "")
+
+	token, err := NewClusterAgentAuthToken()
+	require.NoError(t, err)
+	assert.Equal(t, firstToken, token.Get())
+	assert.Equal(t, 60*time.Second, token.refreshInterval)
+
+	// The configured token takes precedence and is never reloaded
+	mockConfig.Set("cluster_agent.auth_token", secondToken)
+	defer mockConfig.Set("cluster_agent.auth_token", "")
+	token, err = NewClusterAgentAuthToken()
+	require.NoError(t, err)
+	assert.Equal(t, secondToken, token.Get())
+	assert.Equal(t, time.Duration(0), token.refreshInterval)
+}
+
+func TestClusterAgentAuthTokenRotation(t *testing.T) {
+	loaded := firstToken
+	var loadErr error
+	token := &ClusterAgentAuthToken{
+		current:         firstToken,
+		lastLoad:        time.Now(),
+		refreshInterval: time.Hour,
+		gracePeriod:     time.Minute,
+		load: func() (string, error) {
+			return loaded, loadErr
+		},
+	}
+
+	assert.True(t, token.IsValid(firstToken))
+	assert.False(t, token.IsValid(""))
+
+	// Not reloaded before the refresh interval
+	loaded = secondToken
+	assert.Equal(t, firstToken, token.Get())
+
+	// Reloaded after the refresh interval, the previous token stays valid
+	token.lastLoad = time.Now().Add(-2 * time.Hour)
+	assert.Equal(t, secondToken, token.Get())
+	assert.True(t, token.IsValid(firstToken))
+	assert.True(t, token.IsValid(secondToken))
+
+	// Until the end of the grace period
+	token.previousExpiry = time.Now().Add(-time.Second)
+	assert.False(t, token.IsValid(firstToken))
+	assert.True(t, token.IsValid(secondToken))
+
+	// Errors keep the current token
+	token.lastLoad = time.Now().Add(-2 * time.Hour)
+	loadErr = errors.New("unavailable")
+	assert.Equal(t, secondToken, token.Get())
+}
+
+func TestClusterAgentAuthTokenForcedReload(t *testing.T) {
+	loaded := firstToken
+	token := &ClusterAgentAuthToken{
+		current:         firstToken,
+		lastLoad:        time.Now(),
+		refreshInterval: time.Hour,
+		gracePeriod:     time.Minute,
+		load: func() (string, error) {
+			return loaded, nil
+		},
+	}
+
+	// The other agent loaded the rotated token first, rate-limited reload
+	loaded = secondToken
+	assert.False(t, token.IsValid(secondToken))
+	token.lastLoad = time.Now().Add(-minForcedReload)
+	assert.True(t, token.IsValid(secondToken))
+	assert.True(t, token.IsValid(firstToken))
+
+	// No reload with the refresh disabled
+	token.refreshInterval = 0
+	token.lastLoad = time.Now().Add(-time.Hour)
+	loaded = "another-token-another-token-another"
+	assert.False(t, token.IsValid(loaded))
+}
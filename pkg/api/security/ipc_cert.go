@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const ipcCertName = "ipc_cert.pem"
+
+// GetIPCCertFilepath returns the path to the certificate of the gRPC api
+// between the agent processes.
+func GetIPCCertFilepath() string {
+	if config.Datadog.GetString("ipc_cert_file_path") != "" {
+		return config.Datadog.GetString("ipc_cert_file_path")
+	}
+	return filepath.Join(filepath.Dir(config.Datadog.ConfigFileUsed()), ipcCertName)
+}
+
+// ipcCertRetryInterval is the wait between the reads of the IPC certificate
+// while the core agent creates it. It is replaced in tests.
+var ipcCertRetryInterval = time.Second
+
+const ipcCertReadAttempts = 5
+
+// CreateOrFetchIPCCert returns the certificate of the gRPC api between the
+// agent processes, and the pool of certificates trusting it. The agent
+// processes present it both as servers and as clients, so that only the
+// users allowed to read the file can use the api. The certificate is
+// created if it doesn't exist, only the core agent should call it.
+// Requires that the config has been set up before calling.
+func CreateOrFetchIPCCert() (tls.Certificate, *x509.CertPool, error) {
+	certFile := GetIPCCertFilepath()
+
+	if _, e := os.Stat(certFile); os.IsNotExist(e) {
+		if e := createIPCCert(certFile); e != nil {
+			return tls.Certificate{}, nil, fmt.Errorf("error creating the IPC certificate: %s", e)
+		}
+	}
+	return readIPCCert(certFile)
+}
+
+// FetchIPCCert returns the certificate of the gRPC api between the agent
+// processes, and the pool of certificates trusting it. The certificate is
+// created by the core agent, it is waited for if it doesn't exist yet.
+// Requires that the config has been set up before calling.
+func FetchIPCCert() (tls.Certificate, *x509.CertPool, error) {
+	certFile := GetIPCCertFilepath()
+
+	for attempt := 1; ; attempt++ {
+		_, e := os.Stat(certFile)
+		if !os.IsNotExist(e) || attempt == ipcCertReadAttempts {
+			break
+		}
+		log.Debugf("The IPC certificate %s doesn't exist yet, retrying in %v", certFile, ipcCertRetryInterval)
+		time.Sleep(ipcCertRetryInterval)
+	}
+	return readIPCCert(certFile)
+}
+
+// createIPCCert writes a new certificate to a temporary file and links it to
+// certFile, so that the other processes never read a partial certificate
+// and that only one certificate is kept when they race to create it
+func createIPCCert(certFile string) error {
+	_, certPEM, key, e := GenerateRootCert([]string{"127.0.0.1", "localhost"}, 2048)
+	if e != nil {
+		return e
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	tmpFile, e := ioutil.TempFile(filepath.Dir(certFile), ipcCertName)
+	if e != nil {
+		return e
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	// the certificate is as sensitive as the auth token, and saved alike
+	if e = saveAuthToken(string(certPEM)+string(keyPEM), tmpFile.Name()); e != nil {
+		return e
+	}
+	if e = os.Link(tmpFile.Name(), certFile); e != nil {
+		if os.IsExist(e) {
+			log.Debugf("The IPC certificate %s was created concurrently, using it", certFile)
+			return nil
+		}
+		return e
+	}
+	log.Infof("Saved a new IPC certificate to %s", certFile)
+	return nil
+}
+
+func readIPCCert(certFile string) (tls.Certificate, *x509.CertPool, error) {
+	raw, e := ioutil.ReadFile(certFile)
+	if e != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("unable to access the IPC certificate: %s", e)
+	}
+	cert, e := tls.X509KeyPair(raw, raw)
+	if e != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("invalid IPC certificate: %s", e)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return tls.Certificate{}, nil, fmt.Errorf("invalid IPC certificate: no certificate found in %s", certFile)
+	}
+	return cert, pool, nil
+}
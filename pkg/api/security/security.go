@@ -16,6 +16,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"fmt"
 	"net"
@@ -158,7 +159,8 @@ func DeleteAuthToken() error {
 
 // GetClusterAgentAuthToken load the authentication token from:
 // 1st. the configuration value of "cluster_agent.auth_token" in datadog.yaml
-// 2nd. from the filesystem
+// 2nd. the file set in "cluster_agent.auth_token_file_path", typically a mounted secret
+// 3rd. from the filesystem
 // If using the token from the filesystem, the token file must be next to the datadog.yaml
 // with the filename: cluster_agent.auth_token
 func GetClusterAgentAuthToken() (string, error) {
@@ -168,6 +170,15 @@ func GetClusterAgentAuthToken() (string, error) {
 		return authToken, validateAuthToken(authToken)
 	}
 
+	if tokenPath := config.Datadog.GetString("cluster_agent.auth_token_file_path"); tokenPath != "" {
+		b, err := ioutil.ReadFile(tokenPath)
+		if err != nil {
+			return "", fmt.Errorf("empty cluster_agent.auth_token and cannot read %s: %s", tokenPath, err)
+		}
+		authToken = strings.TrimSpace(string(b))
+		return authToken, validateAuthToken(authToken)
+	}
+
 	// load the cluster agent auth token from filesystem
 	tokenAbsPath := filepath.Join(config.FileUsedDir(), clusterAgentAuthTokenFilename)
 	log.Debugf("Empty cluster_agent.auth_token, loading from %s", tokenAbsPath)
@@ -8,11 +8,14 @@
 package security
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,3 +44,68 @@ func TestFetchAuthTokenValidGen(t *testing.T) {
 	_, err = os.Stat(expectTokenPath)
 	require.Nil(t, err)
 }
+
+func TestFetchIPCCert(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-etc-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	mockConfig := config.Mock()
+	certPath := filepath.Join(testDir, "ipc_cert.pem")
+	mockConfig.Set("ipc_cert_file_path", certPath)
+	defer mockConfig.Set("ipc_cert_file_path", "")
+	ipcCertRetryInterval = time.Millisecond
+	defer func() { ipcCertRetryInterval = time.Second }()
+
+	// the clients don't create the certificate
+	_, _, err = FetchIPCCert()
+	assert.Error(t, err)
+	_, err = os.Stat(certPath)
+	assert.True(t, os.IsNotExist(err))
+
+	cert, pool, err := CreateOrFetchIPCCert()
+	require.NoError(t, err)
+	assert.NotNil(t, pool)
+	info, err := os.Stat(certPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the certificate is reused once created
+	again, _, err := CreateOrFetchIPCCert()
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, again.Certificate)
+	client, _, err := FetchIPCCert()
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, client.Certificate)
+
+	// no temporary file is left behind
+	files, err := ioutil.ReadDir(testDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestCreateOrFetchIPCCertConcurrent(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-etc-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("ipc_cert_file_path", filepath.Join(testDir, "ipc_cert.pem"))
+	defer mockConfig.Set("ipc_cert_file_path", "")
+
+	certs := make([]tls.Certificate, 4)
+	var wg sync.WaitGroup
+	for i := range certs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cert, _, err := CreateOrFetchIPCCert()
+			assert.NoError(t, err)
+			certs[i] = cert
+		}(i)
+	}
+	wg.Wait()
+	for _, cert := range certs[1:] {
+		assert.Equal(t, certs[0].Certificate, cert.Certificate)
+	}
+}
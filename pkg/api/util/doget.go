@@ -74,3 +74,39 @@ func DoPost(c *http.Client, url string, contentType string, body io.Reader) (res
 	}
 	return resp, nil
 }
+
+// DoPostChunked performs an HTTP POST request whose response is streamed,
+// calling onChunk with the chunks of the body as they are received. It
+// returns once the response is complete.
+func DoPostChunked(c *http.Client, url string, contentType string, body io.Reader, onChunk func([]byte)) error {
+	req, e := http.NewRequest("POST", url, body)
+	if e != nil {
+		return e
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+GetAuthToken())
+
+	r, e := c.Do(req)
+	if e != nil {
+		return e
+	}
+	defer r.Body.Close()
+	if r.StatusCode >= 400 {
+		resp, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("%s", resp)
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, e := r.Body.Read(buf)
+		if n > 0 {
+			onChunk(buf[:n])
+		}
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+	}
+}
@@ -15,7 +15,7 @@ import (
 
 var (
 	token    string
-	dcaToken string
+	dcaToken *security.ClusterAgentAuthToken
 )
 
 // SetAuthToken sets the session token
@@ -41,19 +41,22 @@ func GetAuthToken() string {
 // Requires that the config has been set up before calling
 func SetDCAAuthToken() error {
 	// Noop if dcaToken is already set
-	if dcaToken != "" {
+	if dcaToken != nil {
 		return nil
 	}
 
 	// dcaToken is only set once, no need to mutex protect
 	var err error
-	dcaToken, err = security.GetClusterAgentAuthToken()
+	dcaToken, err = security.NewClusterAgentAuthToken()
 	return err
 }
 
 // GetDCAAuthToken gets the session token
 func GetDCAAuthToken() string {
-	return dcaToken
+	if dcaToken == nil {
+		return ""
+	}
+	return dcaToken.Get()
 }
 
 // Validate validates an http request
@@ -103,7 +106,8 @@ func ValidateDCARequest(w http.ResponseWriter, r *http.Request) error {
 		return err
 	}
 
-	if len(tok) != 2 || tok[1] != GetDCAAuthToken() {
+	// The previous token is accepted for a while after a rotation
+	if len(tok) != 2 || dcaToken == nil || !dcaToken.IsValid(tok[1]) {
 		err = fmt.Errorf("invalid session token")
 		http.Error(w, err.Error(), 403)
 	}
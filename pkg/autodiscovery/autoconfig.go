@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
 )
@@ -29,6 +30,9 @@ var (
 	listenerCandidateIntl = 30 * time.Second
 	acErrors              *expvar.Map
 	errorStats            = newAcErrorStats()
+	resolutions           = newResolutionStats()
+
+	tlmScheduledConfigs = telemetry.NewGauge("autodiscovery", "scheduled_configs", []string{"provider"}, "Number of configurations scheduled, by provider.")
 )
 
 func init() {
@@ -245,11 +249,17 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 // schedule takes a slice of configs and schedule them
 func (ac *AutoConfig) schedule(configs []integration.Config) {
 	ac.scheduler.Schedule(configs)
+	for _, config := range configs {
+		tlmScheduledConfigs.Inc(config.Provider)
+	}
 }
 
 // unschedule takes a slice of configs and unschedule them
 func (ac *AutoConfig) unschedule(configs []integration.Config) {
 	ac.scheduler.Unschedule(configs)
+	for _, config := range configs {
+		tlmScheduledConfigs.Dec(config.Provider)
+	}
 }
 
 // processNewConfig store (in template cache) and resolves a given config into a slice of resolved configs
@@ -413,6 +423,43 @@ func (ac *AutoConfig) RemoveScheduler(name string) {
 	ac.scheduler.Deregister(name)
 }
 
+// ReloadConfigs unschedules the configurations with one of the given names
+// and schedules them again, decrypting their secrets with the values
+// currently known by the secrets package. It is used when secrets are
+// refreshed.
+func (ac *AutoConfig) ReloadConfigs(names []string) {
+	ac.m.Lock()
+	defer ac.m.Unlock()
+
+	toReload := make(map[string]bool, len(names))
+	for _, name := range names {
+		toReload[name] = true
+	}
+
+	// resolved templates are removed along with their template below
+	var removed []integration.Config
+	for _, c := range ac.store.getLoadedConfigs() {
+		if toReload[c.Name] && c.Entity == "" {
+			removed = append(removed, c)
+		}
+	}
+	ac.processRemovedConfigs(removed)
+
+	for _, pd := range ac.providers {
+		for _, c := range pd.configs {
+			if !toReload[c.Name] {
+				continue
+			}
+			log.Infof("Reloading the configuration %s from the %v provider", c.Name, pd.provider)
+			if c.IsTemplate() {
+				ac.removeConfigTemplates([]integration.Config{c})
+			}
+			c.Provider = pd.provider.String()
+			ac.schedule(ac.processNewConfig(c))
+		}
+	}
+}
+
 func decryptConfig(conf integration.Config) (integration.Config, error) {
 	var err error
 
@@ -460,6 +507,7 @@ func (ac *AutoConfig) removeConfigTemplates(configs []integration.Config) {
 			configs := ac.store.getConfigsForTemplate(tplDigest)
 			ac.store.removeConfigsForTemplate(tplDigest)
 			ac.processRemovedConfigs(configs)
+			resolutions.removeTemplate(tplDigest)
 
 			// Remove template from the cache
 			err := ac.store.templateCache.Del(c)
@@ -502,7 +550,7 @@ func (ac *AutoConfig) resolveTemplate(tpl integration.Config) []integration.Conf
 				log.Warnf("Service %s was removed before we could resolve its config", serviceID)
 				continue
 			}
-			resolvedConfig, err := ac.resolveTemplateForService(tpl, svc)
+			resolvedConfig, err := ac.resolveTemplateForService(tpl, svc, id)
 			if err != nil {
 				continue
 			}
@@ -520,14 +568,24 @@ func (ac *AutoConfig) resolveTemplate(tpl integration.Config) []integration.Conf
 }
 
 // resolveTemplateForService calls the config resolver for the template against the service
-// and stores the resolved config and service mapping if successful
-func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
-	resolvedConfig, err := configresolver.Resolve(tpl, svc)
+// and stores the resolved config and service mapping if successful. The
+// outcome is recorded for troubleshooting, along with the matching AD identifier.
+func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc listeners.Service, adID string) (integration.Config, error) {
+	resolvedConfig, variables, err := configresolver.ResolveWithVariables(tpl, svc)
+	resolution := integration.TemplateResolution{
+		Name:         tpl.Name,
+		Provider:     tpl.Provider,
+		ADIdentifier: adID,
+		Variables:    variables,
+	}
 	if err != nil {
 		newErr := fmt.Errorf("error resolving template %s for service %s: %v", tpl.Name, svc.GetEntity(), err)
 		errorStats.setResolveWarning(tpl.Name, newErr.Error())
+		resolution.Error = err.Error()
+		resolutions.setTemplateResolution(svc.GetEntity(), tpl.Digest(), resolution)
 		return tpl, log.Warn(newErr)
 	}
+	resolutions.setTemplateResolution(svc.GetEntity(), tpl.Digest(), resolution)
 	ac.store.setLoadedConfig(resolvedConfig)
 	ac.store.addConfigForService(svc.GetEntity(), resolvedConfig)
 	ac.store.addConfigForTemplate(tpl.Digest(), resolvedConfig)
@@ -559,6 +617,12 @@ func GetResolveWarnings() map[string][]string {
 	return errorStats.getResolveWarnings()
 }
 
+// GetResolutions returns, for every known service, the templates that
+// matched it and the outcome of their resolution
+func GetResolutions() map[string]integration.ServiceResolution {
+	return resolutions.getResolutions()
+}
+
 // processNewService takes a service, tries to match it against templates and
 // triggers scheduling events if it finds a valid config for it.
 func (ac *AutoConfig) processNewService(svc listeners.Service) {
@@ -566,31 +630,30 @@ func (ac *AutoConfig) processNewService(svc listeners.Service) {
 	ac.store.setServiceForEntity(svc, svc.GetEntity())
 
 	// get all the templates matching service identifiers
-	var templates []integration.Config
 	ADIdentifiers, err := svc.GetADIdentifiers()
 	if err != nil {
 		log.Errorf("Failed to get AD identifiers for service %s, it will not be monitored - %s", svc.GetEntity(), err)
 		return
 	}
+	resolutions.setService(svc.GetEntity(), ADIdentifiers)
 	for _, adID := range ADIdentifiers {
 		// map the AD identifier to this service for reverse lookup
 		ac.store.setADIDForServices(adID, svc.GetEntity())
-		tpls, err := ac.store.templateCache.Get(adID)
+		templates, err := ac.store.templateCache.Get(adID)
 		if err != nil {
 			log.Debugf("Unable to fetch templates from the cache: %v", err)
 		}
-		templates = append(templates, tpls...)
-	}
 
-	for _, template := range templates {
-		// resolve the template
-		resolvedConfig, err := ac.resolveTemplateForService(template, svc)
-		if err != nil {
-			continue
-		}
+		for _, template := range templates {
+			// resolve the template
+			resolvedConfig, err := ac.resolveTemplateForService(template, svc, adID)
+			if err != nil {
+				continue
+			}
 
-		// ask the Collector to schedule the checks
-		ac.schedule([]integration.Config{resolvedConfig})
+			// ask the Collector to schedule the checks
+			ac.schedule([]integration.Config{resolvedConfig})
+		}
 	}
 	// FIXME: schedule new services as well
 	ac.schedule([]integration.Config{
@@ -606,6 +669,7 @@ func (ac *AutoConfig) processNewService(svc listeners.Service) {
 // processDelService takes a service, stops its associated checks, and updates the cache
 func (ac *AutoConfig) processDelService(svc listeners.Service) {
 	ac.store.removeServiceForEntity(svc.GetEntity())
+	resolutions.removeService(svc.GetEntity())
 	configs := ac.store.getConfigsForService(svc.GetEntity())
 	ac.store.removeConfigsForService(svc.GetEntity())
 	ac.processRemovedConfigs(configs)
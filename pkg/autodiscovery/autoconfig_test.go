@@ -344,3 +344,41 @@ func TestRemoveTemplate(t *testing.T) {
 	ac.removeConfigTemplates([]integration.Config{tpl})
 	assert.Len(t, ac.GetLoadedConfigs(), 1)
 }
+
+type recordingScheduler struct {
+	scheduled   []string
+	unscheduled []string
+}
+
+func (s *recordingScheduler) Schedule(configs []integration.Config) {
+	for _, c := range configs {
+		s.scheduled = append(s.scheduled, c.Name)
+	}
+}
+
+func (s *recordingScheduler) Unschedule(configs []integration.Config) {
+	for _, c := range configs {
+		s.unscheduled = append(s.unscheduled, c.Name)
+	}
+}
+
+func (s *recordingScheduler) Stop() {}
+
+func TestReloadConfigs(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	sch := &recordingScheduler{}
+	ac.AddScheduler("recording", sch, false)
+
+	pd := newConfigPoller(&MockProvider{}, false, 0)
+	pd.configs = []integration.Config{{Name: "memory"}, {Name: "disk"}}
+	ac.providers = append(ac.providers, pd)
+	for _, c := range pd.configs {
+		ac.schedule(ac.processNewConfig(c))
+	}
+	assert.Len(t, ac.GetLoadedConfigs(), 2)
+
+	ac.ReloadConfigs([]string{"disk", "unknown"})
+	assert.Equal(t, []string{"disk"}, sch.unscheduled)
+	assert.Equal(t, []string{"memory", "disk", "disk"}, sch.scheduled)
+	assert.Len(t, ac.GetLoadedConfigs(), 2)
+}
@@ -7,10 +7,12 @@ package autodiscovery
 
 import (
 	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -61,13 +63,18 @@ func (pd *configPoller) start(ac *AutoConfig) {
 		return
 	}
 	pd.stopChan = make(chan struct{})
-	pd.healthHandle = health.Register(fmt.Sprintf("ad-config-provider-%s", pd.provider.String()))
+	pd.healthHandle = health.RegisterReadiness(fmt.Sprintf("ad-config-provider-%s", pd.provider.String()))
 	pd.isPolling = true
 	go pd.poll(ac)
 }
 
 // poll polls config of the corresponding config provider
 func (pd *configPoller) poll(ac *AutoConfig) {
+	if watcher, ok := pd.provider.(providers.WatchableConfigProvider); ok {
+		pd.watch(ac, watcher)
+		return
+	}
+
 	ticker := time.NewTicker(pd.pollInterval)
 	for {
 		select {
@@ -78,39 +85,80 @@ func (pd *configPoller) poll(ac *AutoConfig) {
 			return
 		case <-ticker.C:
 			log.Tracef("Polling %s config provider", pd.provider.String())
-			// Check if the CPupdate cache is up to date. Fill it and trigger a Collect() if outdated.
-			upToDate, err := pd.provider.IsUpToDate()
-			if err != nil {
-				log.Errorf("Cache processing of %v configuration provider failed: %v", pd.provider, err)
-			}
-			if upToDate == true {
-				log.Debugf("No modifications in the templates stored in %v configuration provider", pd.provider)
-				break
-			}
-
-			// retrieve the list of newly added configurations as well
-			// as removed configurations
-			newConfigs, removedConfigs := pd.collect()
-			if len(newConfigs) > 0 || len(removedConfigs) > 0 {
-				log.Infof("%v provider: collected %d new configurations, removed %d", pd.provider, len(newConfigs), len(removedConfigs))
-			} else {
-				log.Debugf("%v provider: no configuration change", pd.provider)
-			}
-			// Process removed configs first to handle the case where a
-			// container churn would result in the same configuration hash.
-			ac.processRemovedConfigs(removedConfigs)
-			// We can also remove any cached template
-			ac.removeConfigTemplates(removedConfigs)
-
-			for _, config := range newConfigs {
-				config.Provider = pd.provider.String()
-				resolvedConfigs := ac.processNewConfig(config)
-				ac.schedule(resolvedConfigs)
-			}
+			pd.refresh(ac)
+		}
+	}
+}
+
+// watch waits for change notifications from a config provider able to watch
+// its backend, and periodically resyncs it in case a change was missed.
+func (pd *configPoller) watch(ac *AutoConfig, watcher providers.WatchableConfigProvider) {
+	watchStop := make(chan struct{})
+	changes := watcher.Watch(watchStop)
+	resyncInterval := config.Datadog.GetDuration("ad_config_resync_interval") * time.Second
+	resync := time.NewTimer(withJitter(resyncInterval))
+	for {
+		select {
+		case <-pd.healthHandle.C:
+		case <-pd.stopChan:
+			close(watchStop)
+			pd.healthHandle.Deregister()
+			resync.Stop()
+			return
+		case <-changes:
+			log.Debugf("Change notified by %s config provider", pd.provider.String())
+			pd.refresh(ac)
+		case <-resync.C:
+			log.Tracef("Resyncing %s config provider", pd.provider.String())
+			pd.refresh(ac)
+			resync.Reset(withJitter(resyncInterval))
 		}
 	}
 }
 
+// refresh checks whether the provider's cache is up to date and if not,
+// collects its configurations and schedules the ones that changed.
+func (pd *configPoller) refresh(ac *AutoConfig) {
+	// Check if the CPupdate cache is up to date. Fill it and trigger a Collect() if outdated.
+	upToDate, err := pd.provider.IsUpToDate()
+	if err != nil {
+		log.Errorf("Cache processing of %v configuration provider failed: %v", pd.provider, err)
+	}
+	if upToDate == true {
+		log.Debugf("No modifications in the templates stored in %v configuration provider", pd.provider)
+		return
+	}
+
+	// retrieve the list of newly added configurations as well
+	// as removed configurations
+	newConfigs, removedConfigs := pd.collect()
+	if len(newConfigs) > 0 || len(removedConfigs) > 0 {
+		log.Infof("%v provider: collected %d new configurations, removed %d", pd.provider, len(newConfigs), len(removedConfigs))
+	} else {
+		log.Debugf("%v provider: no configuration change", pd.provider)
+	}
+	// Process removed configs first to handle the case where a
+	// container churn would result in the same configuration hash.
+	ac.processRemovedConfigs(removedConfigs)
+	// We can also remove any cached template
+	ac.removeConfigTemplates(removedConfigs)
+
+	for _, config := range newConfigs {
+		config.Provider = pd.provider.String()
+		resolvedConfigs := ac.processNewConfig(config)
+		ac.schedule(resolvedConfigs)
+	}
+}
+
+// withJitter adds a random jitter of up to 10% to the given duration, so
+// that agents started together don't hit the backend at the same time.
+func withJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/10+1))
+}
+
 // collect is just a convenient wrapper to fetch configurations from a provider and
 // see what changed from the last time we called Collect().
 func (pd *configPoller) collect() ([]integration.Config, []integration.Config) {
@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), withJitter(0))
+	for i := 0; i < 100; i++ {
+		d := withJitter(10 * time.Second)
+		assert.True(t, d >= 10*time.Second)
+		assert.True(t, d <= 11*time.Second)
+	}
+}
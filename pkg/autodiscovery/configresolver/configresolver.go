@@ -7,6 +7,7 @@ package configresolver
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -18,6 +19,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 )
 
+const redactedValue = "********"
+
 type variableGetter func(key []byte, svc listeners.Service) ([]byte, error)
 
 var templateVariables = map[string]variableGetter{
@@ -31,6 +34,17 @@ var templateVariables = map[string]variableGetter{
 // Resolve takes a template and a service and generates a config with
 // valid connection info and relevant tags.
 func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
+	resolvedConfig, _, err := ResolveWithVariables(tpl, svc)
+	return resolvedConfig, err
+}
+
+// ResolveWithVariables behaves like Resolve, and also returns the template
+// variables resolved so far, indexed by their raw form (e.g. `%%host%%`).
+// The variables are returned even if the resolution failed, for debugging.
+// Values coming from environment variables are redacted as they might
+// hold secrets.
+func ResolveWithVariables(tpl integration.Config, svc listeners.Service) (integration.Config, map[string]string, error) {
+	variables := make(map[string]string)
 	// Copy original template
 	resolvedConfig := integration.Config{
 		Name:            tpl.Name,
@@ -48,9 +62,17 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 	copy(resolvedConfig.InitConfig, tpl.InitConfig)
 	copy(resolvedConfig.Instances, tpl.Instances)
 
+	if len(tpl.LogsConfig) > 0 {
+		logsConfig, err := resolveLogsConfig(tpl, svc, variables)
+		if err != nil {
+			return integration.Config{}, variables, err
+		}
+		resolvedConfig.LogsConfig = logsConfig
+	}
+
 	tags, err := svc.GetTags()
 	if err != nil {
-		return resolvedConfig, err
+		return resolvedConfig, variables, err
 	}
 	for i := 0; i < len(tpl.Instances); i++ {
 		// Copy original content from template
@@ -59,8 +81,9 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 			if f, found := templateVariables[string(v.Name)]; found {
 				resolvedVar, err := f(v.Key, svc)
 				if err != nil {
-					return integration.Config{}, err
+					return integration.Config{}, variables, err
 				}
+				variables[string(v.Raw)] = variableTrace(v.Name, resolvedVar)
 				// init config vars are replaced by the first found
 				resolvedConfig.InitConfig = bytes.Replace(resolvedConfig.InitConfig, v.Raw, resolvedVar, -1)
 				resolvedConfig.Instances[i] = bytes.Replace(resolvedConfig.Instances[i], v.Raw, resolvedVar, -1)
@@ -68,10 +91,55 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 		}
 		err = resolvedConfig.Instances[i].MergeAdditionalTags(tags)
 		if err != nil {
-			return resolvedConfig, err
+			return resolvedConfig, variables, err
+		}
+	}
+	return resolvedConfig, variables, nil
+}
+
+// resolveLogsConfig returns a copy of the template logs config with its
+// template variables resolved for the service. Logs configs attached to
+// container labels or pod annotations are JSON arrays: resolved values
+// are escaped to keep the document valid.
+func resolveLogsConfig(tpl integration.Config, svc listeners.Service, variables map[string]string) (integration.Data, error) {
+	resolved := make(integration.Data, len(tpl.LogsConfig))
+	copy(resolved, tpl.LogsConfig)
+
+	isJSON := bytes.HasPrefix(bytes.TrimSpace(resolved), []byte("["))
+	for _, v := range tpl.GetTemplateVariablesForLogs() {
+		f, found := templateVariables[string(v.Name)]
+		if !found {
+			continue
+		}
+		resolvedVar, err := f(v.Key, svc)
+		if err != nil {
+			return nil, err
 		}
+		variables[string(v.Raw)] = variableTrace(v.Name, resolvedVar)
+		if isJSON {
+			resolvedVar = escapeJSONString(resolvedVar)
+		}
+		resolved = bytes.Replace(resolved, v.Raw, resolvedVar, -1)
+	}
+	return resolved, nil
+}
+
+// variableTrace returns the value of a resolved variable as exposed for debugging
+func variableTrace(name []byte, value []byte) string {
+	if string(name) == "env" {
+		return redactedValue
+	}
+	return string(value)
+}
+
+// escapeJSONString escapes a value to be inserted in a JSON string
+func escapeJSONString(value []byte) []byte {
+	escaped, err := json.Marshal(string(value))
+	if err != nil || len(escaped) < 2 {
+		return value
 	}
-	return resolvedConfig, nil
+	// Strip the surrounding quotes
+	return escaped[1 : len(escaped)-1]
 }
 
 func getHost(tplVar []byte, svc listeners.Service) ([]byte, error) {
@@ -400,6 +400,60 @@ func TestResolve(t *testing.T) {
 				Entity:        "a5901276aed1",
 			},
 		},
+		//// logs config testing
+		{
+			testName: "logs config with template variables",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Hosts:         map[string]string{"bridge": "127.0.0.1"},
+				Ports:         newFakeContainerPorts(),
+			},
+			tpl: integration.Config{
+				ADIdentifiers: []string{"redis"},
+				Provider:      "docker",
+				LogsConfig:    integration.Data(`[{"source":"redis","service":"redis-%%port%%","tags":["env:%%env_test_envvar_key%%"]}]`),
+			},
+			out: integration.Config{
+				ADIdentifiers: []string{"redis"},
+				Provider:      "docker",
+				LogsConfig:    integration.Data(`[{"source":"redis","service":"redis-3","tags":["env:test_value"]}]`),
+				Instances:     []integration.Data{},
+				InitConfig:    integration.Data{},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "logs config escapes resolved values",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Hostname:      `my"host`,
+			},
+			tpl: integration.Config{
+				ADIdentifiers: []string{"redis"},
+				LogsConfig:    integration.Data(`[{"source":"redis","service":"%%hostname%%"}]`),
+			},
+			out: integration.Config{
+				ADIdentifiers: []string{"redis"},
+				LogsConfig:    integration.Data(`[{"source":"redis","service":"my\"host"}]`),
+				Instances:     []integration.Data{},
+				InitConfig:    integration.Data{},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "logs config with unresolvable variable",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				ADIdentifiers: []string{"redis"},
+				LogsConfig:    integration.Data(`[{"source":"redis","service":"%%env_test_envvar_not_set%%"}]`),
+			},
+			errorString: "failed to retrieve envvar test_envvar_not_set, skipping service a5901276aed1",
+		},
 		//// unknown tag
 		{
 			testName: "invalid %%FOO%% tag",
@@ -442,3 +496,34 @@ func newFakeContainerPorts() []listeners.ContainerPort {
 		{Port: 3, Name: "baz"},
 	}
 }
+
+func TestResolveWithVariables(t *testing.T) {
+	os.Setenv("test_envvar_secret", "s3cr3t")
+	defer os.Unsetenv("test_envvar_secret")
+
+	svc := &dummyService{
+		ID:            "a5901276aed1",
+		ADIdentifiers: []string{"redis"},
+		Hosts:         map[string]string{"bridge": "127.0.0.1"},
+		Ports:         newFakeContainerPorts(),
+	}
+	tpl := integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%\nport: %%port_bar%%\npass: %%env_test_envvar_secret%%")},
+	}
+
+	_, variables, err := ResolveWithVariables(tpl, svc)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"%%host%%":                   "127.0.0.1",
+		"%%port_bar%%":               "2",
+		"%%env_test_envvar_secret%%": redactedValue,
+	}, variables)
+
+	// Variables resolved before the failure are still returned
+	tpl.Instances = []integration.Data{integration.Data("host: %%host%%\nport: %%port_unknown%%")}
+	_, variables, err = ResolveWithVariables(tpl, svc)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]string{"%%host%%": "127.0.0.1"}, variables)
+}
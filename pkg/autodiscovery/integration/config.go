@@ -162,6 +162,12 @@ func (c *Config) GetTemplateVariablesForInstance(i int) []tmplvar.TemplateVar {
 	return tmplvar.Parse(c.Instances[i])
 }
 
+// GetTemplateVariablesForLogs returns a slice of raw template variables
+// it found in the logs config template.
+func (c *Config) GetTemplateVariablesForLogs() []tmplvar.TemplateVar {
+	return tmplvar.Parse(c.LogsConfig)
+}
+
 // GetNameForInstance returns the name from an instance if specified, fallback on namespace
 func (c *Data) GetNameForInstance() string {
 	commonOptions := CommonInstanceConfig{}
@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package integration
+
+// ServiceResolution describes how the templates matching a discovered
+// service were resolved, for troubleshooting purposes.
+type ServiceResolution struct {
+	Entity        string               `json:"entity"`
+	ADIdentifiers []string             `json:"ad_identifiers"`
+	Templates     []TemplateResolution `json:"templates"`
+}
+
+// TemplateResolution describes the outcome of the resolution of a template
+// against a service: the variables that were resolved, and the reason of the
+// failure if the config was rejected.
+type TemplateResolution struct {
+	Name         string            `json:"name"`
+	Provider     string            `json:"provider"`
+	ADIdentifier string            `json:"ad_identifier"`
+	Variables    map[string]string `json:"variables"`
+	Error        string            `json:"error,omitempty"`
+}
@@ -6,6 +6,9 @@
 package listeners
 
 import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -36,7 +39,7 @@ func ComputeContainerServiceIDs(entity string, image string, labels map[string]s
 		return ids
 	}
 
-	// Add Image names (long then short if different)
+	// Add Image names (long then short if different), then their aliases
 	long, short, _, err := containers.SplitImageName(image)
 	if err != nil {
 		log.Warnf("error while spliting image name: %s", err)
@@ -47,5 +50,50 @@ func ComputeContainerServiceIDs(entity string, image string, labels map[string]s
 	if len(short) > 0 && short != long {
 		ids = append(ids, short)
 	}
+	return appendIdentifierAliases(ids, image)
+}
+
+// appendIdentifierAliases adds to ids the AD identifiers configured in
+// ad_identifier_aliases for an image. Aliases can be declared for the
+// full image name (with tag), the long name (registry and prefix, without tag),
+// the short name or the image digest, and are looked up in that order.
+// Identifiers already present in ids are not duplicated.
+func appendIdentifierAliases(ids []string, image string) []string {
+	if image == "" {
+		return ids
+	}
+	aliases := config.Datadog.GetStringMapStringSlice("ad_identifier_aliases")
+	if len(aliases) == 0 {
+		return ids
+	}
+
+	var candidates []string
+	nameWithTag, digest := image, ""
+	if pos := strings.LastIndex(image, "@"); pos > 0 {
+		nameWithTag, digest = image[:pos], image[pos+1:]
+	}
+	candidates = append(candidates, nameWithTag)
+	if long, short, _, err := containers.SplitImageName(image); err == nil {
+		candidates = append(candidates, long, short)
+	}
+	if digest != "" {
+		candidates = append(candidates, digest)
+	}
+
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	for _, candidate := range candidates {
+		// viper lowercases map keys, image names are lowercase by spec
+		for _, alias := range aliases[strings.ToLower(candidate)] {
+			if _, found := seen[alias]; found || alias == "" {
+				continue
+			}
+			log.Debugf("Adding AD identifier alias %q for image %q", alias, image)
+			seen[alias] = struct{}{}
+			ids = append(ids, alias)
+		}
+	}
 	return ids
 }
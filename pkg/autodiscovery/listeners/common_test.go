@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestComputeContainerServiceIDsWithAliases(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("ad_identifier_aliases", map[string]interface{}{
+		"registry.corp/foo-redis":    "redisdb",
+		"bar-redis":                  []string{"redisdb", "redis"},
+		"sha256:3d1ee1c2c2f4":        "nginx",
+		"registry.corp/pinned:1.2.3": "pinned-check",
+	})
+	defer mockConfig.Set("ad_identifier_aliases", map[string][]string{})
+
+	for _, tc := range []struct {
+		image    string
+		labels   map[string]string
+		expected []string
+	}{
+		{
+			image:    "registry.corp/foo-redis:v3",
+			expected: []string{"docker://deadbeef", "registry.corp/foo-redis", "foo-redis", "redisdb"},
+		},
+		{
+			image:    "registry.corp/team/bar-redis",
+			expected: []string{"docker://deadbeef", "registry.corp/team/bar-redis", "bar-redis", "redisdb", "redis"},
+		},
+		{
+			image:    "registry.corp/custom-nginx@sha256:3d1ee1c2c2f4",
+			expected: []string{"docker://deadbeef", "registry.corp/custom-nginx", "custom-nginx", "nginx"},
+		},
+		{
+			image:    "registry.corp/pinned:1.2.3",
+			expected: []string{"docker://deadbeef", "registry.corp/pinned", "pinned", "pinned-check"},
+		},
+		{
+			// Identifiers are not duplicated
+			image:    "redisdb",
+			expected: []string{"docker://deadbeef", "redisdb"},
+		},
+		{
+			// AD template in labels, no image identifier nor alias
+			image:    "registry.corp/foo-redis:v3",
+			labels:   map[string]string{"com.datadoghq.ad.instances": "[{}]"},
+			expected: []string{"docker://deadbeef"},
+		},
+		{
+			// ID override label
+			image:    "registry.corp/foo-redis:v3",
+			labels:   map[string]string{"com.datadoghq.ad.check.id": "custom"},
+			expected: []string{"custom"},
+		},
+	} {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ComputeContainerServiceIDs("docker://deadbeef", tc.image, tc.labels))
+		})
+	}
+}
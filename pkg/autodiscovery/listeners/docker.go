@@ -72,7 +72,7 @@ func NewDockerListener() (ServiceListener, error) {
 		filter:     filter,
 		services:   make(map[string]Service),
 		stop:       make(chan bool),
-		health:     health.Register("ad-dockerlistener"),
+		health:     health.RegisterReadiness("ad-dockerlistener"),
 	}, nil
 }
 
@@ -200,8 +200,9 @@ func (l *DockerListener) createService(cID string) {
 			log.Warnf("error while resolving image name: %s", err)
 			image = ""
 		}
-		if l.filter.IsExcluded(cInspect.Name, image) {
-			log.Debugf("container %s filtered out: name %q image %q", cID[:12], cInspect.Name, image)
+		namespace := cInspect.Config.Labels[containers.KubeNamespaceLabel]
+		if l.filter.IsExcluded(cInspect.Name, image, namespace) {
+			log.Debugf("container %s filtered out: name %q image %q namespace %q", cID[:12], cInspect.Name, image, namespace)
 			return
 		}
 		if findKubernetesInLabels(cInspect.Config.Labels) {
@@ -279,6 +280,7 @@ func (l *DockerListener) removeService(cID string) {
 // If the special label was not set, the priority order is the following:
 //   1. Long image name
 //   2. Short image name
+//   3. Aliases configured in ad_identifier_aliases
 func (l *DockerListener) getConfigIDFromPs(co types.Container) []string {
 	image, err := l.dockerUtil.ResolveImageName(co.Image)
 	if err != nil {
@@ -338,9 +340,10 @@ func (l *DockerListener) isExcluded(co types.Container) bool {
 		log.Warnf("error while resolving image name: %s", err)
 		image = ""
 	}
+	namespace := co.Labels[containers.KubeNamespaceLabel]
 	for _, name := range co.Names {
-		if l.filter.IsExcluded(name, image) {
-			log.Debugf("container %s filtered out: name %q image %q", co.ID[:12], name, image)
+		if l.filter.IsExcluded(name, image, namespace) {
+			log.Debugf("container %s filtered out: name %q image %q namespace %q", co.ID[:12], name, image, namespace)
 			return true
 		}
 	}
@@ -358,6 +361,7 @@ func (l *DockerListener) isExcluded(co types.Container) bool {
 // If the special label was not set, the priority order is the following:
 //   1. Long image name
 //   2. Short image name
+//   3. Aliases configured in ad_identifier_aliases
 func (s *DockerService) GetADIdentifiers() ([]string, error) {
 	if len(s.adIdentifiers) == 0 {
 		du, err := docker.GetDockerUtil()
@@ -62,7 +62,7 @@ func NewECSListener() (ServiceListener, error) {
 		stop:     make(chan bool),
 		filter:   filter,
 		t:        time.NewTicker(2 * time.Second),
-		health:   health.Register("ad-ecslistener"),
+		health:   health.RegisterReadiness("ad-ecslistener"),
 	}, nil
 }
 
@@ -122,7 +122,7 @@ func (l *ECSListener) refreshServices(firstRun bool) {
 			log.Debugf("container %s is in status %s - skipping", c.DockerID, c.KnownStatus)
 			continue
 		}
-		if l.filter.IsExcluded(c.DockerName, c.Image) {
+		if l.filter.IsExcluded(c.DockerName, c.Image, "") {
 			log.Debugf("container %s filtered out: name %q image %q", c.DockerID[:12], c.DockerName, c.Image)
 			continue
 		}
@@ -205,6 +205,7 @@ func (s *ECSService) GetEntity() string {
 // If the special label was not set, the priority order is the following:
 //   1. Long image name
 //   2. Short image name
+//   3. Aliases configured in ad_identifier_aliases
 func (s *ECSService) GetADIdentifiers() ([]string, error) {
 	return s.ADIdentifiers, nil
 }
@@ -77,7 +77,7 @@ func NewKubeletListener() (ServiceListener, error) {
 		services: make(map[string]Service),
 		ticker:   time.NewTicker(15 * time.Second),
 		stop:     make(chan bool),
-		health:   health.Register("ad-kubeletlistener"),
+		health:   health.RegisterReadiness("ad-kubeletlistener"),
 	}, nil
 }
 
@@ -133,6 +133,10 @@ func (l *KubeletListener) processNewPods(pods []*kubelet.Pod, firstRun bool) {
 			for _, container := range pod.Status.Containers {
 				l.createService(container.ID, pod, firstRun)
 			}
+			if l.filter.IsNamespaceExcluded(pod.Metadata.Namespace) {
+				log.Debugf("pod %s filtered out: namespace %q", pod.Metadata.Name, pod.Metadata.Namespace)
+				continue
+			}
 			l.createPodService(pod, firstRun)
 		}
 	}
@@ -203,8 +207,8 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 	var containerName string
 	for _, container := range pod.Status.Containers {
 		if container.ID == svc.entity {
-			if l.filter.IsExcluded(container.Name, container.Image) {
-				log.Debugf("container %s filtered out: name %q image %q", container.ID, container.Name, container.Image)
+			if l.filter.IsExcluded(container.Name, container.Image, pod.Metadata.Namespace) {
+				log.Debugf("container %s filtered out: name %q image %q namespace %q", container.ID, container.Name, container.Image, pod.Metadata.Namespace)
 				return
 			}
 			containerName = container.Name
@@ -226,6 +230,7 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 			if len(short) > 0 && short != container.Image {
 				svc.adIdentifiers = append(svc.adIdentifiers, short)
 			}
+			svc.adIdentifiers = appendIdentifierAliases(svc.adIdentifiers, container.Image)
 			break
 		}
 	}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
@@ -27,6 +29,9 @@ type ClusterChecksConfigProvider struct {
 	lastChange     int64
 	nodeName       string
 	flushedConfigs bool
+	clcRunner      bool
+	capacity       int
+	configs        []integration.Config
 }
 
 // NewClusterChecksConfigProvider returns a new ConfigProvider collecting
@@ -38,6 +43,8 @@ func NewClusterChecksConfigProvider(cfg config.ConfigurationProviders) (ConfigPr
 	}
 
 	c.nodeName, _ = util.GetHostname()
+	c.clcRunner = config.Datadog.GetBool("clc_runner_enabled")
+	c.capacity = config.Datadog.GetInt("clc_runner_capacity")
 	if cfg.GraceTimeSeconds > 0 {
 		c.graceDuration = time.Duration(cfg.GraceTimeSeconds) * time.Second
 	}
@@ -78,6 +85,11 @@ func (c *ClusterChecksConfigProvider) IsUpToDate() (bool, error) {
 	status := types.NodeStatus{
 		LastChange: c.lastChange,
 	}
+	if c.clcRunner {
+		status.ClcRunner = true
+		status.Capacity = c.capacity
+		status.Stats = c.getRunnerStats()
+	}
 
 	reply, err := c.dcaClient.PostClusterCheckStatus(c.nodeName, status)
 	if err != nil {
@@ -121,10 +133,33 @@ func (c *ClusterChecksConfigProvider) Collect() ([]integration.Config, error) {
 
 	c.flushedConfigs = false
 	c.lastChange = reply.LastChange
+	c.configs = reply.Configs
 	log.Tracef("Storing last change %d", c.lastChange)
 	return reply.Configs, nil
 }
 
+// getRunnerStats returns the execution stats of the cluster checks
+// dispatched to this agent, for the cluster-agent to balance them
+func (c *ClusterChecksConfigProvider) getRunnerStats() types.CLCRunnerStats {
+	checkStats := runner.GetCheckStats()
+	stats := make(types.CLCRunnerStats)
+	for _, conf := range c.configs {
+		for _, instance := range conf.Instances {
+			id := check.BuildID(conf.Name, instance, conf.InitConfig)
+			s, found := checkStats[conf.Name][id]
+			if !found {
+				continue
+			}
+			stats[string(id)] = types.CLCRunnerStat{
+				AverageExecutionTime: s.AverageExecutionTime,
+				MetricSamples:        s.MetricSamples,
+				LastExecFailed:       s.LastError != "",
+			}
+		}
+	}
+	return stats
+}
+
 func init() {
 	RegisterProvider("clusterchecks", NewClusterChecksConfigProvider)
 }
@@ -13,14 +13,20 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	consul "github.com/hashicorp/consul/api"
+	"golang.org/x/net/context"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+// consulWatchWaitTime is the maximum duration of the blocking queries
+// used to watch the templates
+const consulWatchWaitTime = 5 * time.Minute
+
 // Abstractions for testing
 type consulKVBackend interface {
 	Keys(prefix, separator string, q *consul.QueryOptions) ([]string, *consul.QueryMeta, error)
@@ -145,6 +151,48 @@ func (p *ConsulConfigProvider) IsUpToDate() (bool, error) {
 	return true, nil
 }
 
+// Watch runs blocking queries on the template directory and notifies the
+// returned channel of any change, until the stop channel is closed.
+func (p *ConsulConfigProvider) Watch(stop <-chan struct{}) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	go func() {
+		var waitIndex uint64
+		for {
+			opts := &consul.QueryOptions{WaitIndex: waitIndex, WaitTime: consulWatchWaitTime}
+			_, meta, err := p.Client.KV().List(p.TemplateDir, opts.WithContext(ctx))
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil || meta == nil {
+				log.Debugf("Blocking query on %s failed, retrying in %s: %v", p.TemplateDir, watchRetryDelay, err)
+				if !sleepOrStop(watchRetryDelay, stop) {
+					return
+				}
+				continue
+			}
+			// The first query only sets the index to wait on. An index going
+			// backwards means it was reset on the consul side: resync as well.
+			if waitIndex != 0 && meta.LastIndex != waitIndex {
+				notifyChange(changes)
+			}
+			waitIndex = meta.LastIndex
+			if waitIndex == 0 {
+				// Never run non-blocking queries in a loop
+				waitIndex = 1
+			}
+		}
+	}()
+
+	return changes
+}
+
 // getIdentifiers gets folders at the root of the TemplateDir
 // verifies they have the right content to be a valid template
 // and return their names.
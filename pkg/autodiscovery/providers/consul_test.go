@@ -10,6 +10,7 @@ package providers
 import (
 	"errors"
 	"testing"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 	"github.com/stretchr/testify/assert"
@@ -54,11 +55,12 @@ func (m *consulKVMock) Keys(prefix, separator string, q *consul.QueryOptions) ([
 
 func (m *consulKVMock) List(prefix string, q *consul.QueryOptions) (consul.KVPairs, *consul.QueryMeta, error) {
 	args := m.Called(prefix, q)
+	meta, _ := args.Get(1).(*consul.QueryMeta)
 	kvpairs, kvpairs_ok := args.Get(0).(consul.KVPairs)
 	if kvpairs_ok {
-		return kvpairs, nil, nil
+		return kvpairs, meta, nil
 	}
-	return nil, nil, args.Error(2)
+	return nil, meta, args.Error(2)
 }
 
 //
@@ -348,3 +350,43 @@ func TestIsUpToDate(t *testing.T) {
 	provider.AssertExpectations(t)
 	kv.AssertExpectations(t)
 }
+
+func TestConsulWatch(t *testing.T) {
+	kv := &consulKVMock{}
+	provider := &consulMock{kv: kv}
+
+	waitIndex := func(idx uint64) interface{} {
+		return mock.MatchedBy(func(q *consul.QueryOptions) bool {
+			return q.WaitIndex == idx && q.WaitTime == consulWatchWaitTime
+		})
+	}
+	// First query sets the index, the second one returns a change
+	kv.On("List", "/datadog/check_configs", waitIndex(0)).Return(consul.KVPairs{}, &consul.QueryMeta{LastIndex: 10}, nil).Once()
+	kv.On("List", "/datadog/check_configs", waitIndex(10)).Return(consul.KVPairs{}, &consul.QueryMeta{LastIndex: 12}, nil).Once()
+	// Then block until the watch is stopped
+	kv.On("List", "/datadog/check_configs", waitIndex(12)).Run(func(args mock.Arguments) {
+		<-args.Get(1).(*consul.QueryOptions).Context().Done()
+	}).Return(nil, nil, errors.New("context canceled"))
+
+	consulCli := ConsulConfigProvider{
+		Client:      provider,
+		TemplateDir: "/datadog/check_configs",
+		cache:       NewCPCache(),
+	}
+
+	stop := make(chan struct{})
+	changes := consulCli.Watch(stop)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for a change notification")
+	}
+	close(stop)
+
+	select {
+	case <-changes:
+		assert.Fail(t, "unexpected change notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+	kv.AssertExpectations(t)
+}
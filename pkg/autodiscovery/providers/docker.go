@@ -69,7 +69,7 @@ func (d *DockerConfigProvider) Collect() ([]integration.Config, error) {
 func (d *DockerConfigProvider) listen() {
 	d.Lock()
 	d.streaming = true
-	d.health = health.Register("ad-dockerprovider")
+	d.health = health.RegisterReadiness("ad-dockerprovider")
 	d.Unlock()
 
 CONNECT:
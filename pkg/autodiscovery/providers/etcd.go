@@ -23,6 +23,7 @@ import (
 
 type etcdBackend interface {
 	Get(ctx context.Context, key string, opts *client.GetOptions) (*client.Response, error)
+	Watcher(key string, opts *client.WatcherOptions) client.Watcher
 }
 
 // EtcdConfigProvider implements the Config Provider interface
@@ -185,6 +186,40 @@ func (p *EtcdConfigProvider) IsUpToDate() (bool, error) {
 	return true, nil
 }
 
+// Watch watches the template directory recursively and notifies the returned
+// channel of any change, until the stop channel is closed.
+func (p *EtcdConfigProvider) Watch(stop <-chan struct{}) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	go func() {
+		watcher := p.Client.Watcher(p.templateDir, &client.WatcherOptions{Recursive: true})
+		for {
+			_, err := watcher.Next(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Debugf("Watch on %s failed, retrying in %s: %s", p.templateDir, watchRetryDelay, err)
+				if !sleepOrStop(watchRetryDelay, stop) {
+					return
+				}
+				// Events may have been missed (e.g. cleared index), start
+				// a new watcher from the current index and trigger a refresh
+				watcher = p.Client.Watcher(p.templateDir, &client.WatcherOptions{Recursive: true})
+			}
+			notifyChange(changes)
+		}
+	}()
+
+	return changes
+}
+
 // String returns a string representation of the EtcdConfigProvider
 func (p *EtcdConfigProvider) String() string {
 	return Etcd
@@ -8,11 +8,15 @@
 package providers
 
 import (
+	"errors"
+	"testing"
+	"time"
+
 	"github.com/coreos/etcd/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
-	"testing"
 )
 
 type etcdTest struct {
@@ -28,6 +32,21 @@ func (m *etcdTest) Get(ctx context.Context, key string, opts *client.GetOptions)
 	return nil, args.Error(1)
 }
 
+func (m *etcdTest) Watcher(key string, opts *client.WatcherOptions) client.Watcher {
+	args := m.Called(key, opts)
+	return args.Get(0).(client.Watcher)
+}
+
+type etcdWatcherTest struct {
+	mock.Mock
+}
+
+func (m *etcdWatcherTest) Next(ctx context.Context) (*client.Response, error) {
+	args := m.Called(ctx)
+	resp, _ := args.Get(0).(*client.Response)
+	return resp, args.Error(1)
+}
+
 func createTestNode(key string) *client.Node {
 	return &client.Node{
 		Key:           key,
@@ -172,3 +191,34 @@ func TestETCDIsUpToDate(t *testing.T) {
 	assert.Equal(t, 2, etcd.cache.NumAdTemplates)
 	backend.AssertExpectations(t)
 }
+
+func TestETCDWatch(t *testing.T) {
+	backend := &etcdTest{}
+	watcher := &etcdWatcherTest{}
+	backend.On("Watcher", "/datadog/tpl", &client.WatcherOptions{Recursive: true}).Return(watcher)
+
+	// One change, then block until the watch is stopped
+	watcher.On("Next", mock.Anything).Return(&client.Response{Action: "set"}, nil).Once()
+	watcher.On("Next", mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, errors.New("context canceled"))
+
+	etcd := EtcdConfigProvider{Client: backend, templateDir: "/datadog/tpl", cache: NewCPCache()}
+
+	stop := make(chan struct{})
+	changes := etcd.Watch(stop)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for a change notification")
+	}
+	close(stop)
+
+	select {
+	case <-changes:
+		assert.Fail(t, "unexpected change notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+	backend.AssertNumberOfCalls(t, "Watcher", 1)
+	watcher.AssertNumberOfCalls(t, "Next", 2)
+}
@@ -63,3 +63,16 @@ type ConfigProvider interface {
 	String() string
 	IsUpToDate() (bool, error)
 }
+
+// WatchableConfigProvider is implemented by config providers able to
+// notify template changes from their backend instead of being polled.
+//
+// Watch starts watching the templates in a background goroutine and returns
+// a channel receiving a value every time a change is detected. The watch is
+// stopped when the stop channel is closed. Notifications are coalesced, and
+// are not guaranteed to be exhaustive: the caller is expected to resync
+// periodically.
+type WatchableConfigProvider interface {
+	ConfigProvider
+	Watch(stop <-chan struct{}) <-chan struct{}
+}
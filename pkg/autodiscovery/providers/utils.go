@@ -22,6 +22,9 @@ const (
 	checkNamePath  string = "check_names"
 	initConfigPath string = "init_configs"
 	logsConfigPath string = "logs"
+
+	// watchRetryDelay is the delay before re-establishing a failed watch
+	watchRetryDelay = 5 * time.Second
 )
 
 func init() {
@@ -213,3 +216,23 @@ func GetPollInterval(cp config.ConfigurationProviders) time.Duration {
 	}
 	return config.Datadog.GetDuration("ad_config_poll_interval") * time.Second
 }
+
+// notifyChange does a non-blocking send on a buffered changes channel, so
+// that consecutive notifications are coalesced until they are consumed.
+func notifyChange(changes chan struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+// sleepOrStop waits for the given duration, returning false if the stop
+// channel was closed in the meantime.
+func sleepOrStop(d time.Duration, stop <-chan struct{}) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-stop:
+		return false
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"math"
 	"path"
+	"reflect"
 	"strings"
 	"time"
 
@@ -26,6 +27,8 @@ const sessionTimeout = 1 * time.Second
 type zkBackend interface {
 	Get(key string) ([]byte, *zk.Stat, error)
 	Children(key string) ([]string, *zk.Stat, error)
+	GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error)
 }
 
 // ZookeeperConfigProvider implements the Config Provider interface It should
@@ -116,6 +119,119 @@ func (z *ZookeeperConfigProvider) IsUpToDate() (bool, error) {
 	return true, nil
 }
 
+// Watch sets watches on the template dir, the template folders and their
+// template keys, and notifies the returned channel of any change, until the
+// stop channel is closed. Zookeeper watches only fire once: the ones that
+// fired are set again, along with the ones of the new folders and keys.
+func (z *ZookeeperConfigProvider) Watch(stop <-chan struct{}) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		watches := make(map[string]<-chan zk.Event)
+		for {
+			if err := z.setWatches(watches); err != nil {
+				log.Debugf("Watch on %s failed, retrying in %s: %s", z.templateDir, watchRetryDelay, err)
+				if !sleepOrStop(watchRetryDelay, stop) {
+					return
+				}
+				// Changes may have been missed while the watches were not set
+				notifyChange(changes)
+				continue
+			}
+
+			key, event, ok := waitForEvent(watches, stop)
+			if !ok {
+				return
+			}
+			if event.Type == zk.EventNotWatching {
+				// The session was lost, zookeeper dropped all the watches
+				watches = make(map[string]<-chan zk.Event)
+			} else {
+				delete(watches, key)
+			}
+			notifyChange(changes)
+		}
+	}()
+
+	return changes
+}
+
+// setWatches sets the watches missing from the given map, which holds the
+// event channels by zookeeper path
+func (z *ZookeeperConfigProvider) setWatches(watches map[string]<-chan zk.Event) error {
+	children, err := z.watchChildren(z.templateDir, watches)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		nodePath := path.Join(z.templateDir, child)
+		nodes, err := z.watchChildren(nodePath, watches)
+		if err == zk.ErrNoNode {
+			// deleted since listed, the watch of the parent fired
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		for _, tplKey := range nodes {
+			switch tplKey {
+			case instancePath, checkNamePath, initConfigPath:
+			default:
+				continue
+			}
+			keyPath := path.Join(nodePath, tplKey)
+			if _, found := watches[keyPath]; found {
+				continue
+			}
+			_, _, events, err := z.client.GetW(keyPath)
+			if err == zk.ErrNoNode {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("couldn't watch key '%s' from zookeeper: %s", keyPath, err)
+			}
+			watches[keyPath] = events
+		}
+	}
+	return nil
+}
+
+// watchChildren lists the children of a zookeeper node, setting a watch on
+// them if it's not set yet
+func (z *ZookeeperConfigProvider) watchChildren(key string, watches map[string]<-chan zk.Event) ([]string, error) {
+	if _, found := watches[key]; found {
+		children, _, err := z.client.Children(key)
+		return children, err
+	}
+	children, _, events, err := z.client.ChildrenW(key)
+	if err != nil {
+		return nil, err
+	}
+	watches[key] = events
+	return children, nil
+}
+
+// waitForEvent waits for one of the watches to fire and returns its path and
+// event, or false if the stop channel was closed in the meantime
+func waitForEvent(watches map[string]<-chan zk.Event, stop <-chan struct{}) (string, zk.Event, bool) {
+	keys := make([]string, 0, len(watches))
+	cases := make([]reflect.SelectCase, 0, len(watches)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(stop)})
+	for key, events := range watches {
+		keys = append(keys, key)
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(events)})
+	}
+
+	chosen, value, received := reflect.Select(cases)
+	if chosen == 0 {
+		return "", zk.Event{}, false
+	}
+	var event zk.Event
+	if received {
+		event = value.Interface().(zk.Event)
+	}
+	return keys[chosen-1], event, true
+}
+
 // getIdentifiers gets folders at the root of the template dir
 // verifies they have the right content to be a valid template
 // and return their names.
@@ -10,6 +10,7 @@ package providers
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +52,19 @@ func (m *zkTest) Children(key string) ([]string, *zk.Stat, error) {
 	return nil, nil, args.Error(2)
 }
 
+func (m *zkTest) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	args := m.Called(key)
+	events, _ := args.Get(0).(chan zk.Event)
+	return nil, nil, events, args.Error(1)
+}
+
+func (m *zkTest) ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	args := m.Called(key)
+	array, _ := args.Get(0).([]string)
+	events, _ := args.Get(1).(chan zk.Event)
+	return array, nil, events, args.Error(2)
+}
+
 //
 // Tests
 //
@@ -221,3 +235,40 @@ func TestZKIsUpToDate(t *testing.T) {
 	assert.True(t, update)
 	backend.AssertExpectations(t)
 }
+
+func TestZookeeperWatch(t *testing.T) {
+	backend := &zkTest{}
+	rootEvents := make(chan zk.Event, 1)
+	redisEvents := make(chan zk.Event, 1)
+	instancesEvents := make(chan zk.Event, 1)
+	backend.On("ChildrenW", "/datadog/tpl").Return([]string{"redis"}, rootEvents, nil).Once()
+	backend.On("ChildrenW", "/datadog/tpl/redis").Return([]string{"check_names", "init_configs", "instances", "other"}, redisEvents, nil).Once()
+	backend.On("GetW", "/datadog/tpl/redis/check_names").Return(make(chan zk.Event, 1), nil).Once()
+	backend.On("GetW", "/datadog/tpl/redis/init_configs").Return(make(chan zk.Event, 1), nil).Once()
+	backend.On("GetW", "/datadog/tpl/redis/instances").Return(instancesEvents, nil).Once()
+
+	provider := ZookeeperConfigProvider{client: backend, templateDir: "/datadog/tpl", cache: NewCPCache()}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	changes := provider.Watch(stop)
+
+	// only the watch that fired is set again, the others are still set
+	backend.On("Children", "/datadog/tpl").Return([]string{"redis"}, nil, nil).Once()
+	backend.On("Children", "/datadog/tpl/redis").Return([]string{"check_names", "init_configs", "instances"}, nil, nil).Once()
+	newInstancesEvents := make(chan zk.Event, 1)
+	backend.On("GetW", "/datadog/tpl/redis/instances").Return(newInstancesEvents, nil).Once()
+	instancesEvents <- zk.Event{Type: zk.EventNodeDataChanged, Path: "/datadog/tpl/redis/instances"}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for a change notification")
+	}
+
+	select {
+	case <-changes:
+		assert.Fail(t, "unexpected change notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+	backend.AssertExpectations(t)
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// resolutionStats records, for every service known to AutoConfig, which
+// templates matched it and how they were resolved
+type resolutionStats struct {
+	services map[string]*serviceResolution // service entity -> resolution
+	m        sync.RWMutex
+}
+
+type serviceResolution struct {
+	adIdentifiers []string
+	templates     map[string]integration.TemplateResolution // template digest -> resolution
+}
+
+// newResolutionStats returns an empty resolutionStats
+func newResolutionStats() *resolutionStats {
+	return &resolutionStats{
+		services: make(map[string]*serviceResolution),
+	}
+}
+
+// setService resets the resolutions recorded for a service
+func (rs *resolutionStats) setService(entity string, adIdentifiers []string) {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	rs.services[entity] = &serviceResolution{
+		adIdentifiers: adIdentifiers,
+		templates:     make(map[string]integration.TemplateResolution),
+	}
+}
+
+// removeService forgets about a service
+func (rs *resolutionStats) removeService(entity string) {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	delete(rs.services, entity)
+}
+
+// setTemplateResolution records the resolution of a template for a service
+func (rs *resolutionStats) setTemplateResolution(entity string, tplDigest string, res integration.TemplateResolution) {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	svc, found := rs.services[entity]
+	if !found {
+		svc = &serviceResolution{templates: make(map[string]integration.TemplateResolution)}
+		rs.services[entity] = svc
+	}
+	svc.templates[tplDigest] = res
+}
+
+// removeTemplate forgets about the resolutions of a template for all services
+func (rs *resolutionStats) removeTemplate(tplDigest string) {
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	for _, svc := range rs.services {
+		delete(svc.templates, tplDigest)
+	}
+}
+
+// getResolutions returns a copy of the recorded resolutions, indexed by
+// service entity, with the templates sorted by name
+func (rs *resolutionStats) getResolutions() map[string]integration.ServiceResolution {
+	rs.m.RLock()
+	defer rs.m.RUnlock()
+
+	resolutions := make(map[string]integration.ServiceResolution, len(rs.services))
+	for entity, svc := range rs.services {
+		templates := make([]integration.TemplateResolution, 0, len(svc.templates))
+		for _, res := range svc.templates {
+			templates = append(templates, res)
+		}
+		sort.Slice(templates, func(i, j int) bool {
+			if templates[i].Name != templates[j].Name {
+				return templates[i].Name < templates[j].Name
+			}
+			return templates[i].Provider < templates[j].Provider
+		})
+		resolutions[entity] = integration.ServiceResolution{
+			Entity:        entity,
+			ADIdentifiers: svc.adIdentifiers,
+			Templates:     templates,
+		}
+	}
+	return resolutions
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestResolutionStats(t *testing.T) {
+	rs := newResolutionStats()
+	rs.setService("docker://foo", []string{"redis"})
+	rs.setTemplateResolution("docker://foo", "digest2", integration.TemplateResolution{
+		Name:         "redisdb",
+		Provider:     "file",
+		ADIdentifier: "redis",
+		Error:        "no port found",
+	})
+	rs.setTemplateResolution("docker://foo", "digest1", integration.TemplateResolution{
+		Name:         "apache",
+		Provider:     "file",
+		ADIdentifier: "redis",
+		Variables:    map[string]string{"%%host%%": "127.0.0.1"},
+	})
+	// Services unknown yet are created on the fly
+	rs.setTemplateResolution("docker://bar", "digest1", integration.TemplateResolution{Name: "apache"})
+
+	resolutions := rs.getResolutions()
+	assert.Len(t, resolutions, 2)
+	foo := resolutions["docker://foo"]
+	assert.Equal(t, "docker://foo", foo.Entity)
+	assert.Equal(t, []string{"redis"}, foo.ADIdentifiers)
+	assert.Len(t, foo.Templates, 2)
+	assert.Equal(t, "apache", foo.Templates[0].Name)
+	assert.Equal(t, "redisdb", foo.Templates[1].Name)
+	assert.Equal(t, "no port found", foo.Templates[1].Error)
+
+	rs.removeTemplate("digest1")
+	resolutions = rs.getResolutions()
+	assert.Len(t, resolutions["docker://foo"].Templates, 1)
+	assert.Len(t, resolutions["docker://bar"].Templates, 0)
+
+	// Resetting a service forgets its previous resolutions
+	rs.setService("docker://foo", []string{"redis", "custom"})
+	assert.Len(t, rs.getResolutions()["docker://foo"].Templates, 0)
+
+	rs.removeService("docker://foo")
+	rs.removeService("docker://bar")
+	assert.Len(t, rs.getResolutions(), 0)
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCertificate(t *testing.T) {
+	hosts := []string{"webhook.default.svc", "webhook.default.svc.cluster.local"}
+	now := time.Now()
+	certPEM, keyPEM, err := generateCertificate(hosts, now, now.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	assert.NoError(t, err)
+
+	cert, err := parseCertificate(certPEM)
+	require.NoError(t, err)
+	assert.Equal(t, hosts, cert.DNSNames)
+	assert.True(t, cert.IsCA)
+	assert.WithinDuration(t, now.Add(24*time.Hour), cert.NotAfter, time.Second)
+}
+
+func TestNeedsRotation(t *testing.T) {
+	hosts := []string{"webhook.default.svc"}
+	now := time.Now()
+	certPEM, _, err := generateCertificate(hosts, now, now.Add(10*24*time.Hour))
+	require.NoError(t, err)
+
+	assert.False(t, needsRotation(certPEM, hosts, now, 24*time.Hour))
+	// expires within the threshold
+	assert.True(t, needsRotation(certPEM, hosts, now.Add(9*24*time.Hour+time.Minute), 24*time.Hour))
+	// the service changed
+	assert.True(t, needsRotation(certPEM, []string{"other.default.svc"}, now, 24*time.Hour))
+	// invalid certificate
+	assert.True(t, needsRotation([]byte("foo"), hosts, now, 24*time.Hour))
+	assert.True(t, needsRotation(nil, hosts, now, 24*time.Hour))
+}
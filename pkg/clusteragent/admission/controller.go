@@ -0,0 +1,349 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// reconcileInterval is the frequency at which the certificate and the
+	// webhook configuration are checked.
+	reconcileInterval = time.Minute
+
+	// injectPath is the path of the webhook injecting the config in the pods.
+	injectPath = "/inject"
+
+	certificateKey = "cert.pem"
+	privateKeyKey  = "key.pem"
+
+	// namespaceNameLabel is set by the API server on every namespace since
+	// Kubernetes 1.21, the older ones match every namespace.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+
+	defaultTimeoutSeconds = 5
+)
+
+// leaderElector avoids the import cycle with the apiserver package.
+type leaderElector interface {
+	IsLeader() bool
+}
+
+// Controller keeps the certificate of the webhook server in a secret and the
+// mutating webhook configuration up to date. The leader creates and rotates
+// the certificate and registers the webhook, every replica serves the
+// certificate stored in the secret.
+type Controller struct {
+	client              kubernetes.Interface
+	le                  leaderElector
+	namespace           string
+	serviceName         string
+	secretName          string
+	webhookName         string
+	failurePolicy       admissionregistrationv1beta1.FailurePolicyType
+	timeoutSeconds      int32
+	validity            time.Duration
+	expirationThreshold time.Duration
+
+	m           sync.RWMutex
+	certPEM     []byte
+	certificate *tls.Certificate
+}
+
+// NewController returns a new admission controller.
+func NewController(client kubernetes.Interface, le leaderElector) *Controller {
+	return &Controller{
+		client:              client,
+		le:                  le,
+		namespace:           common.GetResourcesNamespace(),
+		serviceName:         config.Datadog.GetString("admission_controller.service_name"),
+		secretName:          config.Datadog.GetString("admission_controller.certificate.secret_name"),
+		webhookName:         config.Datadog.GetString("admission_controller.webhook_name"),
+		failurePolicy:       getFailurePolicy(),
+		timeoutSeconds:      getTimeoutSeconds(),
+		validity:            config.Datadog.GetDuration("admission_controller.certificate.validity_bound") * time.Hour,
+		expirationThreshold: config.Datadog.GetDuration("admission_controller.certificate.expiration_threshold") * time.Hour,
+	}
+}
+
+// getFailurePolicy returns the configured failure policy of the webhook,
+// Ignore unless Fail is configured.
+func getFailurePolicy() admissionregistrationv1beta1.FailurePolicyType {
+	policy := admissionregistrationv1beta1.FailurePolicyType(config.Datadog.GetString("admission_controller.failure_policy"))
+	switch policy {
+	case admissionregistrationv1beta1.Ignore, admissionregistrationv1beta1.Fail:
+		return policy
+	default:
+		log.Warnf("Unknown admission controller failure policy %q, using %q", policy, admissionregistrationv1beta1.Ignore)
+		return admissionregistrationv1beta1.Ignore
+	}
+}
+
+// getTimeoutSeconds returns the configured timeout of the webhook calls,
+// between 1 and 30 seconds as required by the API server.
+func getTimeoutSeconds() int32 {
+	timeout := config.Datadog.GetInt("admission_controller.timeout_seconds")
+	if timeout < 1 || timeout > 30 {
+		log.Warnf("Invalid admission controller timeout %d, it must be between 1 and 30 seconds, using %d", timeout, defaultTimeoutSeconds)
+		return defaultTimeoutSeconds
+	}
+	return int32(timeout)
+}
+
+// Run reconciles the certificate and the webhook until stopCh is closed.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	log.Infof("Starting the admission controller")
+	defer log.Infof("Stopping the admission controller")
+
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.reconcile(); err != nil {
+			log.Errorf("Could not reconcile the admission controller: %v", err)
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetCertificate returns the certificate of the webhook server, it is meant
+// to be used as the GetCertificate of a tls.Config.
+func (c *Controller) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	if c.certificate == nil {
+		return nil, errors.New("the certificate of the admission controller is not available yet")
+	}
+	return c.certificate, nil
+}
+
+// hosts returns the names of the service of the webhook server.
+func (c *Controller) hosts() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", c.serviceName, c.namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", c.serviceName, c.namespace),
+	}
+}
+
+// reconcile rotates the certificate and updates the webhook when leader, and
+// loads the certificate from the secret.
+func (c *Controller) reconcile() error {
+	secret, err := c.client.CoreV1().Secrets(c.namespace).Get(c.secretName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err != nil {
+		secret = nil
+	}
+	if c.le.IsLeader() {
+		if secret, err = c.reconcileSecret(secret); err != nil {
+			return fmt.Errorf("could not reconcile the secret %s/%s: %v", c.namespace, c.secretName, err)
+		}
+		if err = c.reconcileWebhook(secret.Data[certificateKey]); err != nil {
+			return fmt.Errorf("could not reconcile the webhook %s: %v", c.webhookName, err)
+		}
+	}
+	if secret == nil {
+		return fmt.Errorf("the secret %s/%s is not created yet", c.namespace, c.secretName)
+	}
+	return c.loadCertificate(secret.Data[certificateKey], secret.Data[privateKeyKey])
+}
+
+// reconcileSecret creates the secret or rotates its certificate when it
+// expires soon, and returns the up to date secret.
+func (c *Controller) reconcileSecret(secret *corev1.Secret) (*corev1.Secret, error) {
+	if secret != nil && !needsRotation(secret.Data[certificateKey], c.hosts(), time.Now(), c.expirationThreshold) {
+		return secret, nil
+	}
+	now := time.Now()
+	certPEM, keyPEM, err := generateCertificate(c.hosts(), now, now.Add(c.validity))
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]byte{
+		certificateKey: certPEM,
+		privateKeyKey:  keyPEM,
+	}
+	if secret == nil {
+		log.Infof("Creating the certificate of the admission controller in the secret %s/%s", c.namespace, c.secretName)
+		return c.client.CoreV1().Secrets(c.namespace).Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.secretName,
+				Namespace: c.namespace,
+			},
+			Data: data,
+		})
+	}
+	log.Infof("Rotating the certificate of the admission controller in the secret %s/%s", c.namespace, c.secretName)
+	secret = secret.DeepCopy()
+	secret.Data = data
+	return c.client.CoreV1().Secrets(c.namespace).Update(secret)
+}
+
+// reconcileWebhook creates or updates the mutating webhook configuration.
+func (c *Controller) reconcileWebhook(caBundle []byte) error {
+	webhooks := c.newWebhooks(caBundle)
+	current, err := c.client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(c.webhookName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		log.Infof("Creating the mutating webhook configuration %s", c.webhookName)
+		_, err = c.client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Create(&admissionregistrationv1beta1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: c.webhookName,
+			},
+			Webhooks: webhooks,
+		})
+		if err != nil {
+			return err
+		}
+		return c.patchTimeout(webhooks)
+	}
+	if err != nil {
+		return err
+	}
+	if !webhooksEqual(current.Webhooks, webhooks) {
+		log.Infof("Updating the mutating webhook configuration %s", c.webhookName)
+		current = current.DeepCopy()
+		current.Webhooks = webhooks
+		if _, err = c.client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Update(current); err != nil {
+			return err
+		}
+	}
+	return c.patchTimeout(webhooks)
+}
+
+// patchTimeout sets the timeout of the webhooks, the API server waits 30
+// seconds by default. The field is patched as it's missing from the
+// vendored API types, the API server doesn't write unchanged objects and
+// the ones older than Kubernetes 1.14 drop it.
+func (c *Controller) patchTimeout(webhooks []admissionregistrationv1beta1.Webhook) error {
+	type webhookTimeout struct {
+		Name           string `json:"name"`
+		TimeoutSeconds int32  `json:"timeoutSeconds"`
+	}
+	patch := struct {
+		Webhooks []webhookTimeout `json:"webhooks"`
+	}{}
+	for _, webhook := range webhooks {
+		patch.Webhooks = append(patch.Webhooks, webhookTimeout{Name: webhook.Name, TimeoutSeconds: c.timeoutSeconds})
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Patch(c.webhookName, types.StrategicMergePatchType, data)
+	return err
+}
+
+// newWebhooks returns the webhooks of the configuration.
+func (c *Controller) newWebhooks(caBundle []byte) []admissionregistrationv1beta1.Webhook {
+	path := injectPath
+	failurePolicy := c.failurePolicy
+	return []admissionregistrationv1beta1.Webhook{
+		{
+			Name: "inject.admission.datadoghq.com",
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: c.namespace,
+					Name:      c.serviceName,
+					Path:      &path,
+				},
+				CABundle: caBundle,
+			},
+			Rules: []admissionregistrationv1beta1.RuleWithOperations{
+				{
+					Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create},
+					Rule: admissionregistrationv1beta1.Rule{
+						APIGroups:   []string{""},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"pods"},
+					},
+				},
+			},
+			FailurePolicy:     &failurePolicy,
+			NamespaceSelector: c.namespaceSelector(),
+		},
+	}
+}
+
+// namespaceSelector excludes the system namespace and the one of the cluster
+// agent from the webhook, so that the pods of the control plane and of the
+// cluster agent itself can be created while the webhook is unavailable.
+func (c *Controller) namespaceSelector() *metav1.LabelSelector {
+	excluded := []string{"kube-system"}
+	if c.namespace != "kube-system" {
+		excluded = append(excluded, c.namespace)
+	}
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      namespaceNameLabel,
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   excluded,
+			},
+		},
+	}
+}
+
+// webhooksEqual compares the fields of the webhooks set by the controller,
+// the other ones are defaulted by the API server.
+func webhooksEqual(current, desired []admissionregistrationv1beta1.Webhook) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		if current[i].Name != desired[i].Name ||
+			!reflect.DeepEqual(current[i].ClientConfig, desired[i].ClientConfig) ||
+			!reflect.DeepEqual(current[i].Rules, desired[i].Rules) ||
+			!reflect.DeepEqual(current[i].FailurePolicy, desired[i].FailurePolicy) ||
+			!reflect.DeepEqual(current[i].NamespaceSelector, desired[i].NamespaceSelector) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadCertificate sets the certificate served when it changed.
+func (c *Controller) loadCertificate(certPEM, keyPEM []byte) error {
+	c.m.RLock()
+	unchanged := c.certificate != nil && bytes.Equal(c.certPEM, certPEM)
+	c.m.RUnlock()
+	if unchanged {
+		return nil
+	}
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid certificate in the secret %s/%s: %v", c.namespace, c.secretName, err)
+	}
+	log.Debugf("Loaded the certificate of the admission controller")
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.certPEM = certPEM
+	c.certificate = &certificate
+	return nil
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+type fakeLeaderElector bool
+
+func (le fakeLeaderElector) IsLeader() bool { return bool(le) }
+
+func newTestController(client *fake.Clientset, leader bool) *Controller {
+	return &Controller{
+		client:              client,
+		le:                  fakeLeaderElector(leader),
+		namespace:           "default",
+		serviceName:         "datadog-admission-controller",
+		secretName:          "webhook-certificate",
+		webhookName:         "datadog-webhook",
+		failurePolicy:       admissionregistrationv1beta1.Ignore,
+		timeoutSeconds:      5,
+		validity:            10 * 24 * time.Hour,
+		expirationThreshold: 24 * time.Hour,
+	}
+}
+
+func TestReconcileLeader(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	c := newTestController(client, true)
+
+	_, err := c.GetCertificate(nil)
+	assert.Error(t, err)
+
+	require.NoError(t, c.reconcile())
+	secret, err := client.CoreV1().Secrets("default").Get("webhook-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	certPEM := secret.Data[certificateKey]
+	assert.False(t, needsRotation(certPEM, c.hosts(), time.Now(), c.expirationThreshold))
+
+	webhook, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("datadog-webhook", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, webhook.Webhooks, 1)
+	assert.Equal(t, certPEM, webhook.Webhooks[0].ClientConfig.CABundle)
+	assert.Equal(t, "datadog-admission-controller", webhook.Webhooks[0].ClientConfig.Service.Name)
+	assert.Equal(t, admissionregistrationv1beta1.Ignore, *webhook.Webhooks[0].FailurePolicy)
+	require.NotNil(t, webhook.Webhooks[0].NamespaceSelector)
+	assert.Equal(t, []metav1.LabelSelectorRequirement{
+		{
+			Key:      namespaceNameLabel,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   []string{"kube-system", "default"},
+		},
+	}, webhook.Webhooks[0].NamespaceSelector.MatchExpressions)
+
+	var patch []byte
+	for _, action := range client.Actions() {
+		if p, ok := action.(clienttesting.PatchAction); ok && action.GetResource().Resource == "mutatingwebhookconfigurations" {
+			patch = p.GetPatch()
+		}
+	}
+	assert.JSONEq(t, `{"webhooks":[{"name":"inject.admission.datadoghq.com","timeoutSeconds":5}]}`, string(patch))
+
+	certificate, err := c.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, certificate)
+
+	// nothing changes while the certificate is valid
+	require.NoError(t, c.reconcile())
+	secret, err = client.CoreV1().Secrets("default").Get("webhook-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, certPEM, secret.Data[certificateKey])
+
+	// the certificate is rotated and the webhook updated when it expires soon
+	c.expirationThreshold = 11 * 24 * time.Hour
+	c.failurePolicy = admissionregistrationv1beta1.Fail
+	require.NoError(t, c.reconcile())
+	secret, err = client.CoreV1().Secrets("default").Get("webhook-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, certPEM, secret.Data[certificateKey])
+	webhook, err = client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("datadog-webhook", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, secret.Data[certificateKey], webhook.Webhooks[0].ClientConfig.CABundle)
+	assert.Equal(t, admissionregistrationv1beta1.Fail, *webhook.Webhooks[0].FailurePolicy)
+
+	rotated, err := c.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, certificate, rotated)
+}
+
+func TestReconcileFollower(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	follower := newTestController(client, false)
+
+	// the follower waits for the leader to create the secret
+	assert.Error(t, follower.reconcile())
+	_, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("datadog-webhook", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	require.NoError(t, newTestController(client, true).reconcile())
+	require.NoError(t, follower.reconcile())
+	_, err = follower.GetCertificate(nil)
+	assert.NoError(t, err)
+}
+
+func TestNamespaceSelectorKubeSystem(t *testing.T) {
+	c := newTestController(fake.NewSimpleClientset(), true)
+	c.namespace = "kube-system"
+	assert.Equal(t, []string{"kube-system"}, c.namespaceSelector().MatchExpressions[0].Values)
+}
+
+func TestGetTimeoutSeconds(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("admission_controller.timeout_seconds", 5)
+
+	mockConfig.Set("admission_controller.timeout_seconds", 10)
+	assert.Equal(t, int32(10), getTimeoutSeconds())
+	mockConfig.Set("admission_controller.timeout_seconds", 0)
+	assert.Equal(t, int32(defaultTimeoutSeconds), getTimeoutSeconds())
+	mockConfig.Set("admission_controller.timeout_seconds", 31)
+	assert.Equal(t, int32(defaultTimeoutSeconds), getTimeoutSeconds())
+}
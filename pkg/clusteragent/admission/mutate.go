@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// tracerAnnotationFormat is the annotation requesting the injection of
+	// the tracer library of a language, its value is the version.
+	tracerAnnotationFormat = "admission.datadoghq.com/%s-lib.version"
+
+	tracerVolumeName = "datadog-auto-instrumentation"
+	tracerMountPath  = "/datadog-lib"
+)
+
+// tracerVersionPattern matches the versions usable as the tag of an image,
+// so that the annotation can't change the image reference.
+var tracerVersionPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// tagLabels are the standard labels of a pod and the env vars of their tags.
+var tagLabels = []struct {
+	label   string
+	envName string
+}{
+	{"tags.datadoghq.com/env", "DD_ENV"},
+	{"tags.datadoghq.com/service", "DD_SERVICE"},
+	{"tags.datadoghq.com/version", "DD_VERSION"},
+}
+
+// tracerLibrary describes how the tracer library of a language is loaded.
+type tracerLibrary struct {
+	language string
+	envName  string
+	envValue string
+	// separator joins envValue to the existing value of the env var
+	separator string
+}
+
+var tracerLibraries = []tracerLibrary{
+	{"java", "JAVA_TOOL_OPTIONS", "-javaagent:" + tracerMountPath + "/dd-java-agent.jar", " "},
+	{"js", "NODE_OPTIONS", "--require=" + tracerMountPath + "/node_modules/dd-trace/init", " "},
+	{"python", "PYTHONPATH", tracerMountPath + "/", ":"},
+}
+
+// injectConfig adds the env vars pointing the containers of the pod to the
+// agent and tagging their data, the env vars already set are left untouched.
+func injectConfig(pod *corev1.Pod) {
+	envVars := []corev1.EnvVar{
+		{
+			Name: "DD_AGENT_HOST",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.hostIP"},
+			},
+		},
+		{
+			Name:  "DD_TRACE_AGENT_PORT",
+			Value: strconv.Itoa(config.Datadog.GetInt("admission_controller.inject_config.trace_agent_port")),
+		},
+		{
+			Name: "DD_ENTITY_ID",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.uid"},
+			},
+		},
+	}
+	for _, tag := range tagLabels {
+		if value, found := pod.Labels[tag.label]; found {
+			envVars = append(envVars, corev1.EnvVar{Name: tag.envName, Value: value})
+		}
+	}
+	for i := range pod.Spec.Containers {
+		for _, envVar := range envVars {
+			injectEnv(&pod.Spec.Containers[i], envVar)
+		}
+	}
+}
+
+// injectTracer adds an init container copying the tracer library requested
+// by the annotations of the pod to a volume shared with its containers, and
+// the env vars loading it. The libraries already injected are skipped, so
+// that the pod can be mutated again.
+func injectTracer(pod *corev1.Pod) {
+	injected := false
+	for _, lib := range tracerLibraries {
+		annotation := fmt.Sprintf(tracerAnnotationFormat, lib.language)
+		version, found := pod.Annotations[annotation]
+		if !found {
+			continue
+		}
+		if !tracerVersionPattern.MatchString(version) {
+			log.Warnf("Invalid version %q in the annotation %s of the pod %s/%s, the %s library is not injected", version, annotation, pod.Namespace, pod.Name, lib.language)
+			continue
+		}
+		initName := fmt.Sprintf("datadog-lib-%s-init", lib.language)
+		if hasInitContainer(pod, initName) {
+			continue
+		}
+		image := fmt.Sprintf("%s/dd-lib-%s-init:%s", config.Datadog.GetString("admission_controller.inject_tracer.container_registry"), lib.language, version)
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+			Name:         initName,
+			Image:        image,
+			Command:      []string{"sh", "copy-lib.sh", tracerMountPath},
+			VolumeMounts: []corev1.VolumeMount{{Name: tracerVolumeName, MountPath: tracerMountPath}},
+		})
+		for i := range pod.Spec.Containers {
+			appendEnv(&pod.Spec.Containers[i], lib.envName, lib.envValue, lib.separator)
+		}
+		injected = true
+	}
+	if !injected || hasVolume(pod, tracerVolumeName) {
+		return
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name:         tracerVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      tracerVolumeName,
+			MountPath: tracerMountPath,
+		})
+	}
+}
+
+// injectEnv adds the env var to the container unless it is already set.
+func injectEnv(container *corev1.Container, envVar corev1.EnvVar) {
+	for _, env := range container.Env {
+		if env.Name == envVar.Name {
+			return
+		}
+	}
+	container.Env = append(container.Env, envVar)
+}
+
+// appendEnv appends value to the env var of the container, or sets it.
+func appendEnv(container *corev1.Container, name, value, separator string) {
+	for i, env := range container.Env {
+		if env.Name != name {
+			continue
+		}
+		if env.ValueFrom != nil || strings.Contains(env.Value, value) {
+			// can't append to a value read from another resource
+			return
+		}
+		if env.Value == "" {
+			container.Env[i].Value = value
+		} else {
+			container.Env[i].Value = env.Value + separator + value
+		}
+		return
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+}
+
+// hasVolume returns whether the pod has a volume named name.
+func hasVolume(pod *corev1.Pod, name string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasInitContainer returns whether the pod has an init container named name.
+func hasInitContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
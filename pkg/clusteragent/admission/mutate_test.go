@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(labels, annotations map[string]string, containers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Containers: containers,
+		},
+	}
+}
+
+func envByName(container corev1.Container) map[string]corev1.EnvVar {
+	envs := make(map[string]corev1.EnvVar)
+	for _, env := range container.Env {
+		envs[env.Name] = env
+	}
+	return envs
+}
+
+func TestInjectConfig(t *testing.T) {
+	pod := newPod(
+		map[string]string{"tags.datadoghq.com/env": "prod", "tags.datadoghq.com/service": "web"},
+		nil,
+		corev1.Container{Name: "app", Env: []corev1.EnvVar{{Name: "DD_AGENT_HOST", Value: "datadog-agent"}}},
+		corev1.Container{Name: "sidecar"},
+	)
+	injectConfig(pod)
+
+	app := envByName(pod.Spec.Containers[0])
+	assert.Len(t, app, 5)
+	// the env vars set are left untouched
+	assert.Equal(t, "datadog-agent", app["DD_AGENT_HOST"].Value)
+	assert.Nil(t, app["DD_AGENT_HOST"].ValueFrom)
+	assert.Equal(t, "8126", app["DD_TRACE_AGENT_PORT"].Value)
+	assert.Equal(t, "metadata.uid", app["DD_ENTITY_ID"].ValueFrom.FieldRef.FieldPath)
+	assert.Equal(t, "prod", app["DD_ENV"].Value)
+	assert.Equal(t, "web", app["DD_SERVICE"].Value)
+
+	sidecar := envByName(pod.Spec.Containers[1])
+	assert.Len(t, sidecar, 5)
+	assert.Equal(t, "status.hostIP", sidecar["DD_AGENT_HOST"].ValueFrom.FieldRef.FieldPath)
+	assert.NotContains(t, sidecar, "DD_VERSION")
+}
+
+func TestInjectTracer(t *testing.T) {
+	pod := newPod(
+		nil,
+		map[string]string{"admission.datadoghq.com/java-lib.version": "v0.30.0"},
+		corev1.Container{Name: "app", Env: []corev1.EnvVar{{Name: "JAVA_TOOL_OPTIONS", Value: "-Xmx1g"}}},
+	)
+	injectTracer(pod)
+
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, "datadog-lib-java-init", pod.Spec.InitContainers[0].Name)
+	assert.Equal(t, "gcr.io/datadoghq/dd-lib-java-init:v0.30.0", pod.Spec.InitContainers[0].Image)
+	require.Len(t, pod.Spec.Volumes, 1)
+	assert.Equal(t, tracerVolumeName, pod.Spec.Volumes[0].Name)
+	assert.NotNil(t, pod.Spec.Volumes[0].EmptyDir)
+
+	app := pod.Spec.Containers[0]
+	assert.Equal(t, "-Xmx1g -javaagent:/datadog-lib/dd-java-agent.jar", envByName(app)["JAVA_TOOL_OPTIONS"].Value)
+	require.Len(t, app.VolumeMounts, 1)
+	assert.Equal(t, tracerMountPath, app.VolumeMounts[0].MountPath)
+}
+
+func TestInjectTracerIdempotent(t *testing.T) {
+	pod := newPod(
+		nil,
+		map[string]string{"admission.datadoghq.com/python-lib.version": "v0.34.0"},
+		corev1.Container{Name: "app", Env: []corev1.EnvVar{{Name: "PYTHONPATH", Value: "/app"}}},
+	)
+	injectTracer(pod)
+	injectTracer(pod)
+
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, "datadog-lib-python-init", pod.Spec.InitContainers[0].Name)
+	assert.Len(t, pod.Spec.Volumes, 1)
+	app := pod.Spec.Containers[0]
+	assert.Equal(t, "/app:/datadog-lib/", envByName(app)["PYTHONPATH"].Value)
+	assert.Len(t, app.VolumeMounts, 1)
+}
+
+func TestInjectTracerInvalidVersion(t *testing.T) {
+	for _, version := range []string{"", "latest@sha256:0123", "v1/../../other", ".hidden", "v1 v2"} {
+		pod := newPod(
+			nil,
+			map[string]string{"admission.datadoghq.com/js-lib.version": version},
+			corev1.Container{Name: "app"},
+		)
+		injectTracer(pod)
+
+		assert.Empty(t, pod.Spec.InitContainers, version)
+		assert.Empty(t, pod.Spec.Volumes, version)
+		assert.Empty(t, pod.Spec.Containers[0].Env, version)
+	}
+}
+
+func TestInjectTracerNotRequested(t *testing.T) {
+	pod := newPod(nil, map[string]string{"foo": "bar"}, corev1.Container{Name: "app"})
+	injectTracer(pod)
+
+	assert.Empty(t, pod.Spec.InitContainers)
+	assert.Empty(t, pod.Spec.Volumes)
+	assert.Empty(t, pod.Spec.Containers[0].Env)
+	assert.Empty(t, pod.Spec.Containers[0].VolumeMounts)
+}
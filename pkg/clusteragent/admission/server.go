@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	stdLog "log"
+	"net/http"
+	"reflect"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxRequestSize bounds the size of the admission reviews read.
+const maxRequestSize = 10 * 1024 * 1024
+
+// patchOperation is an operation of a JSON patch.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Server serves the webhook mutating the pods matching the pod selector.
+type Server struct {
+	selector      labels.Selector
+	failurePolicy admissionregistrationv1beta1.FailurePolicyType
+	injectConfig  bool
+	injectTracer  bool
+}
+
+// NewServer returns a new webhook server.
+func NewServer() (*Server, error) {
+	selector, err := labels.Parse(config.Datadog.GetString("admission_controller.pod_selector"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid admission_controller.pod_selector: %v", err)
+	}
+	return &Server{
+		selector:      selector,
+		failurePolicy: getFailurePolicy(),
+		injectConfig:  config.Datadog.GetBool("admission_controller.inject_config.enabled"),
+		injectTracer:  config.Datadog.GetBool("admission_controller.inject_tracer.enabled"),
+	}, nil
+}
+
+// Run serves the webhook with the certificate of the controller until ctx is
+// done.
+func (s *Server) Run(ctx context.Context, controller *Controller) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(injectPath, s.handleInject)
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", config.Datadog.GetInt("admission_controller.port")),
+		Handler: mux,
+		ErrorLog: stdLog.New(&config.ErrorLogWriter{
+			AdditionalDepth: 4, // Use a stack depth of 4 on top of the default one to get a relevant filename in the stdlib
+		}, "Error from the admission controller http server: ", 0), // log errors to seelog,
+		TLSConfig: &tls.Config{
+			GetCertificate: controller.GetCertificate,
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	log.Infof("Starting the admission controller server on %s", srv.Addr)
+	if err := srv.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// handleInject answers the admission reviews of the pods.
+func (s *Server) handleInject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	review := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+	review.Response = s.mutate(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+	response, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not encode the response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// mutate returns the response to the admission request of a pod, with the
+// patch injecting the config when the pod matches the selector. The pod is
+// rejected on errors only when the failure policy is Fail.
+func (s *Server) mutate(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	pod := corev1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		return s.failure(fmt.Errorf("could not decode the pod: %v", err))
+	}
+	if !s.selector.Matches(labels.Set(pod.Labels)) {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	patch, err := json.Marshal(s.patch(&pod))
+	if err != nil {
+		return s.failure(fmt.Errorf("could not encode the patch: %v", err))
+	}
+	log.Debugf("Injecting the config in the pod %s/%s", req.Namespace, podName(&pod))
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// patch returns the operations injecting the config in the pod.
+func (s *Server) patch(pod *corev1.Pod) []patchOperation {
+	mutated := pod.DeepCopy()
+	if s.injectConfig {
+		injectConfig(mutated)
+	}
+	if s.injectTracer {
+		injectTracer(mutated)
+	}
+	// add replaces the value of the fields already set
+	ops := []patchOperation{}
+	if !reflect.DeepEqual(pod.Spec.InitContainers, mutated.Spec.InitContainers) {
+		ops = append(ops, patchOperation{Op: "add", Path: "/spec/initContainers", Value: mutated.Spec.InitContainers})
+	}
+	if !reflect.DeepEqual(pod.Spec.Containers, mutated.Spec.Containers) {
+		ops = append(ops, patchOperation{Op: "add", Path: "/spec/containers", Value: mutated.Spec.Containers})
+	}
+	if !reflect.DeepEqual(pod.Spec.Volumes, mutated.Spec.Volumes) {
+		ops = append(ops, patchOperation{Op: "add", Path: "/spec/volumes", Value: mutated.Spec.Volumes})
+	}
+	return ops
+}
+
+// failure returns the response to a request that could not be handled.
+func (s *Server) failure(err error) *admissionv1beta1.AdmissionResponse {
+	log.Warnf("Could not mutate a pod: %v", err)
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: s.failurePolicy != admissionregistrationv1beta1.Fail,
+		Result: &metav1.Status{
+			Message: err.Error(),
+		},
+	}
+}
+
+// podName returns the name of the pod, its generate name prefix when it is
+// not known yet.
+func podName(pod *corev1.Pod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
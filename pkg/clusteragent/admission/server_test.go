@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func review(t *testing.T, s *Server, object []byte) *admissionv1beta1.AdmissionResponse {
+	body, err := json.Marshal(admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{
+			UID:       types.UID("123"),
+			Namespace: "default",
+			Object:    runtime.RawExtension{Raw: object},
+		},
+	})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	s.handleInject(rec, httptest.NewRequest(http.MethodPost, injectPath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	response := admissionv1beta1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotNil(t, response.Response)
+	assert.Equal(t, types.UID("123"), response.Response.UID)
+	return response.Response
+}
+
+func TestHandleInject(t *testing.T) {
+	s, err := NewServer()
+	require.NoError(t, err)
+
+	pod, err := json.Marshal(newPod(
+		map[string]string{"admission.datadoghq.com/enabled": "true"},
+		map[string]string{"admission.datadoghq.com/python-lib.version": "v0.28.0"},
+		corev1.Container{Name: "app"},
+	))
+	require.NoError(t, err)
+	response := review(t, s, pod)
+	assert.True(t, response.Allowed)
+	require.NotNil(t, response.PatchType)
+	assert.Equal(t, admissionv1beta1.PatchTypeJSONPatch, *response.PatchType)
+
+	ops := []patchOperation{}
+	require.NoError(t, json.Unmarshal(response.Patch, &ops))
+	require.Len(t, ops, 3)
+	assert.Equal(t, "/spec/initContainers", ops[0].Path)
+	assert.Equal(t, "/spec/containers", ops[1].Path)
+	assert.Equal(t, "/spec/volumes", ops[2].Path)
+	for _, op := range ops {
+		assert.Equal(t, "add", op.Op)
+	}
+}
+
+func TestHandleInjectNotSelected(t *testing.T) {
+	s, err := NewServer()
+	require.NoError(t, err)
+
+	pod, err := json.Marshal(newPod(map[string]string{"admission.datadoghq.com/enabled": "false"}, nil, corev1.Container{Name: "app"}))
+	require.NoError(t, err)
+	response := review(t, s, pod)
+	assert.True(t, response.Allowed)
+	assert.Nil(t, response.Patch)
+}
+
+func TestHandleInjectFailurePolicy(t *testing.T) {
+	s, err := NewServer()
+	require.NoError(t, err)
+	response := review(t, s, []byte(`{"spec": "invalid"}`))
+	assert.True(t, response.Allowed)
+	assert.NotEmpty(t, response.Result.Message)
+
+	config.Datadog.Set("admission_controller.failure_policy", "Fail")
+	defer config.Datadog.Set("admission_controller.failure_policy", "Ignore")
+	s, err = NewServer()
+	require.NoError(t, err)
+	assert.Equal(t, admissionregistrationv1beta1.Fail, s.failurePolicy)
+	response = review(t, s, []byte(`{"spec": "invalid"}`))
+	assert.False(t, response.Allowed)
+}
+
+func TestHandleInjectInvalidRequest(t *testing.T) {
+	s, err := NewServer()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	s.handleInject(rec, httptest.NewRequest(http.MethodPost, injectPath, bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.handleInject(rec, httptest.NewRequest(http.MethodGet, injectPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestNewServerInvalidSelector(t *testing.T) {
+	config.Datadog.Set("admission_controller.pod_selector", "foo in (")
+	defer config.Datadog.Set("admission_controller.pod_selector", "admission.datadoghq.com/enabled=true")
+	_, err := NewServer()
+	assert.Error(t, err)
+}
@@ -131,3 +131,12 @@ func NewMetadataResponse() *MetadataResponse {
 		Nodes: make(map[string]*MetadataResponseBundle),
 	}
 }
+
+// ClusterTags is the payload of /api/v1/tags/cluster, it holds the tags
+// common to all the nodes of the cluster. Version changes whenever the tags
+// or the namespace labels change.
+type ClusterTags struct {
+	Version         string                       `json:"version"`
+	Tags            []string                     `json:"tags"`
+	NamespaceLabels map[string]map[string]string `json:"namespace_labels,omitempty"`
+}
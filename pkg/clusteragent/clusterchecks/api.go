@@ -20,7 +20,7 @@ const notReadyReason = "Startup in progress"
 // requests. Current known responses:
 //   - 302, string: follower, leader IP in string
 //   - 503, string: not ready, error string returned
-//   - 200, "": leader and ready for serving requests
+//   - 200, "": leader, or follower with a fresh shared state, ready for serving requests
 func (h *Handler) ShouldHandle() (int, string) {
 	h.m.RLock()
 	defer h.m.RUnlock()
@@ -29,6 +29,9 @@ func (h *Handler) ShouldHandle() (int, string) {
 	case leader:
 		return http.StatusOK, ""
 	case follower:
+		if h.shared != nil && h.shared.isFresh() {
+			return http.StatusOK, ""
+		}
 		return http.StatusFound, fmt.Sprintf("%s:%d", h.leaderIP, h.port)
 	default:
 		return http.StatusServiceUnavailable, notReadyReason
@@ -52,6 +55,10 @@ func (h *Handler) GetState() (types.StateResponse, error) {
 
 // GetConfigs returns configurations dispatched to a given node
 func (h *Handler) GetConfigs(nodeName string) (types.ConfigResponse, error) {
+	if h.servesSharedState() {
+		return h.shared.getConfigs(nodeName)
+	}
+
 	configs, lastChange, err := h.dispatcher.getNodeConfigs(nodeName)
 	response := types.ConfigResponse{
 		Configs:    configs,
@@ -62,6 +69,11 @@ func (h *Handler) GetConfigs(nodeName string) (types.ConfigResponse, error) {
 
 // PostStatus handles status reports from the node agents
 func (h *Handler) PostStatus(nodeName string, status types.NodeStatus) (types.StatusResponse, error) {
+	if h.servesSharedState() {
+		upToDate := h.shared.processNodeStatus(nodeName, status)
+		return types.StatusResponse{IsUpToDate: upToDate}, nil
+	}
+
 	upToDate, err := h.dispatcher.processNodeStatus(nodeName, status)
 	response := types.StatusResponse{
 		IsUpToDate: upToDate,
@@ -71,6 +83,10 @@ func (h *Handler) PostStatus(nodeName string, status types.NodeStatus) (types.St
 
 // GetEndpointsConfigs returns endpoints configurations dispatched to a given node
 func (h *Handler) GetEndpointsConfigs(nodeName string) (types.ConfigResponse, error) {
+	if h.servesSharedState() {
+		return h.shared.getEndpointsConfigs(nodeName), nil
+	}
+
 	configs, err := h.dispatcher.getEndpointsConfigs(nodeName)
 	response := types.ConfigResponse{
 		Configs:    configs,
@@ -78,3 +94,30 @@ func (h *Handler) GetEndpointsConfigs(nodeName string) (types.ConfigResponse, er
 	}
 	return response, err
 }
+
+// PostForwardedStatuses handles the node-agent reports forwarded by the followers
+func (h *Handler) PostForwardedStatuses(forwarded types.ForwardedStatuses) {
+	for nodeName, status := range forwarded.Statuses {
+		h.dispatcher.processNodeStatus(nodeName, status)
+	}
+	if len(forwarded.EndpointsNodes) > 0 {
+		h.dispatcher.recordEndpointsNodes(forwarded.EndpointsNodes...)
+	}
+}
+
+// IsLeader returns true if the cluster-agent is leading
+func (h *Handler) IsLeader() bool {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.state == leader
+}
+
+// servesSharedState returns true if the node-agent requests
+// are served from the state shared by the leader
+func (h *Handler) servesSharedState() bool {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.state == follower && h.shared != nil
+}
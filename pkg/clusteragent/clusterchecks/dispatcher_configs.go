@@ -30,8 +30,10 @@ func (d *dispatcher) getState() (types.StateResponse, error) {
 	}
 	for _, node := range d.store.nodes {
 		n := types.StateNodeResponse{
-			Name:    node.name,
-			Configs: makeConfigArray(node.digestToConfig),
+			Name:      node.name,
+			ClcRunner: node.lastStatus.ClcRunner,
+			Capacity:  node.lastStatus.Capacity,
+			Configs:   makeConfigArray(node.digestToConfig),
 		}
 		response.Nodes = append(response.Nodes, n)
 	}
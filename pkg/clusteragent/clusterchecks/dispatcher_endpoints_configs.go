@@ -8,15 +8,21 @@
 package clusterchecks
 
 import (
+	"bytes"
+	"strconv"
+
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	v1 "k8s.io/api/core/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
 )
 
 // getEndpointsConfigs provides configs templates of endpoints checks queried by node name.
 // Exposed to node agents by the cluster agent api.
 func (d *dispatcher) getEndpointsConfigs(nodeName string) ([]integration.Config, error) {
+	d.recordEndpointsNodes(nodeName)
+
 	err := d.updateEndpointsChecks()
 	if err != nil {
 		log.Errorf("Cannot update check maps: %s", err)
@@ -25,16 +31,52 @@ func (d *dispatcher) getEndpointsConfigs(nodeName string) ([]integration.Config,
 	return d.store.endpointsChecks[nodeName], nil
 }
 
-// updateEndpointsChecks updates stored endpoints configs.
+// recordEndpointsNodes stores the time node agents queried their endpoints checks.
+func (d *dispatcher) recordEndpointsNodes(nodeNames ...string) {
+	now := timestampNow()
+	d.store.Lock()
+	defer d.store.Unlock()
+	for _, nodeName := range nodeNames {
+		d.store.endpointsNodes[nodeName] = now
+	}
+}
+
+// updateEndpointsChecks updates stored endpoints configs and dispatches the
+// fallback checks of the endpoints no node agent can run to the cluster
+// check runners. The fallback checks are diffed with the dispatched ones,
+// the concurrent updates are serialized so that none is lost or dispatched
+// twice.
+func (d *dispatcher) updateEndpointsChecks() error {
+	d.endpointsUpdateMutex.Lock()
+	defer d.endpointsUpdateMutex.Unlock()
+
+	fallbackChecks, err := d.refreshEndpointsChecks()
+	if err != nil {
+		return err
+	}
+	d.updateFallbackChecks(fallbackChecks)
+	return nil
+}
+
+// refreshEndpointsChecks updates stored endpoints configs and returns the
+// fallback checks.
 // The function validates cached configs by listing their corresponding
 // *v1.Endpoints objects and checking if endpoints are backed by pods,
 // if validated, store them as endpoints checks with their correspendent node name.
 // Listing the *v1.Endpoints object keeps pods' UIDs updated as they will
 // be added as AD identifiers in the endpoints config templates in buildEndpointsChecks.
-func (d *dispatcher) updateEndpointsChecks() error {
+// Once the warmup is over, the endpoints not backed by a pod or running on a
+// node whose agent doesn't query its endpoints checks are resolved as
+// fallback checks by buildFallbackChecks.
+func (d *dispatcher) refreshEndpointsChecks() ([]integration.Config, error) {
 	endpointsChecks := make(map[string][]integration.Config)
+	var fallbackChecks []integration.Config
 	d.store.Lock()
 	defer d.store.Unlock()
+	minPollTimestamp := timestampNow() - d.nodeExpirationSeconds
+	hasLocalAgent := func(nodeName string) bool {
+		return d.store.endpointsNodes[nodeName] >= minPollTimestamp
+	}
 	for _, epInfo := range d.store.endpointsCache {
 		namespace := epInfo.Namespace
 		name := epInfo.Name
@@ -45,65 +87,192 @@ func (d *dispatcher) updateEndpointsChecks() error {
 		kendpoints, err := d.endpointsLister.Endpoints(namespace).Get(name)
 		if err != nil {
 			log.Errorf("Cannot get Kubernetes endpoints:%s/%s : %s", namespace, name, err)
-			return err
+			return nil, err
 		}
 		if hasPodRef(kendpoints) {
 			// Only consider endpoints backed by pods
 			newEndpointsChecks := buildEndpointsChecks(kendpoints, epInfo)
 			endpointsChecks = unionMaps(endpointsChecks, newEndpointsChecks)
 		}
+		if d.store.active {
+			fallbackChecks = append(fallbackChecks, buildFallbackChecks(kendpoints, epInfo, hasLocalAgent)...)
+		}
 	}
 	// Store the up-to-date generated endpoints config checks
 	d.store.endpointsChecks = endpointsChecks
-	return nil
+	return fallbackChecks, nil
+}
+
+// updateFallbackChecks dispatches the new fallback checks as cluster checks
+// and removes the ones not needed anymore. endpointsUpdateMutex must be held.
+func (d *dispatcher) updateFallbackChecks(configs []integration.Config) {
+	fallbackChecks := make(map[string]integration.Config)
+	for _, config := range configs {
+		patched, err := d.patchConfiguration(config)
+		if err != nil {
+			log.Warnf("Cannot patch configuration %s: %s", config.Digest(), err)
+			continue
+		}
+		fallbackChecks[patched.Digest()] = patched
+	}
+
+	d.store.RLock()
+	previousChecks := d.store.fallbackChecks
+	d.store.RUnlock()
+	for digest, config := range fallbackChecks {
+		if _, found := previousChecks[digest]; !found {
+			d.add(config)
+		}
+	}
+	for digest, config := range previousChecks {
+		if _, found := fallbackChecks[digest]; !found {
+			d.remove(config)
+		}
+	}
+
+	d.store.Lock()
+	d.store.fallbackChecks = fallbackChecks
+	d.store.Unlock()
 }
 
-// hasPodRef checks if an *v1.Endpoints object is backed by pod.
+// hasPodRef checks if an *v1.Endpoints object is backed by at least one pod.
 func hasPodRef(kendpoints *v1.Endpoints) bool {
 	for i := range kendpoints.Subsets {
 		for j := range kendpoints.Subsets[i].Addresses {
-			if kendpoints.Subsets[i].Addresses[j].TargetRef == nil {
-				return false
+			if isPodAddress(kendpoints.Subsets[i].Addresses[j]) {
+				return true
 			}
-			return kendpoints.Subsets[i].Addresses[j].TargetRef.Kind == "Pod"
 		}
 	}
 	return false
 }
 
+// isPodAddress checks if an endpoint address targets a pod
+// scheduled on a known node.
+func isPodAddress(address v1.EndpointAddress) bool {
+	if address.TargetRef == nil || address.NodeName == nil {
+		return false
+	}
+	return address.TargetRef.Kind == kubePodKind
+}
+
 // buildEndpointsChecks returns a map of node names as keys with their
 // corresponding endpoints configs as values.
-// The function gets node names and pod uids from the *v1.Endpoints object.
+// One config is generated per endpoint address backed by a pod, pods
+// exposed in several subsets (multiple ports) are only considered once.
 // The function adds the corresponding pod uid and service entity as AD identifiers
-// to the validated config templates using updateADIdentifiers.
+// to the validated config templates using updateADIdentifiers, and tags
+// the instances with the address IP using addEndpointTags.
 func buildEndpointsChecks(kendpoints *v1.Endpoints, epInfo *types.EndpointsInfo) map[string][]integration.Config {
 	nodesEndpointsMapping := make(map[string][]integration.Config)
+	seenPods := make(map[ktypes.UID]struct{})
 	for i := range kendpoints.Subsets {
 		for j := range kendpoints.Subsets[i].Addresses {
-			if nodeName := kendpoints.Subsets[i].Addresses[j].NodeName; nodeName != nil {
-				podUID := string(kendpoints.Subsets[i].Addresses[j].TargetRef.UID)
-				for _, config := range epInfo.Configs {
-					nodesEndpointsMapping[*nodeName] = append(nodesEndpointsMapping[*nodeName], updateADIdentifiers(config, podUID, epInfo.ServiceEntity))
+			address := kendpoints.Subsets[i].Addresses[j]
+			if !isPodAddress(address) {
+				log.Tracef("Ignoring endpoint address %s of %s/%s: not backed by a scheduled pod", address.IP, epInfo.Namespace, epInfo.Name)
+				continue
+			}
+			podUID := address.TargetRef.UID
+			if _, found := seenPods[podUID]; found {
+				continue
+			}
+			seenPods[podUID] = struct{}{}
+			nodeName := *address.NodeName
+			for _, config := range epInfo.Configs {
+				endpointConfig := updateADIdentifiers(config, string(podUID), epInfo.ServiceEntity)
+				if err := addEndpointTags(&endpointConfig, address.IP); err != nil {
+					log.Warnf("Cannot tag endpoints check %s for %s/%s: %s", config.Name, epInfo.Namespace, epInfo.Name, err)
 				}
+				nodesEndpointsMapping[nodeName] = append(nodesEndpointsMapping[nodeName], endpointConfig)
 			}
 		}
 	}
 	return nodesEndpointsMapping
 }
 
+// buildFallbackChecks returns the endpoints configs of the addresses no node
+// agent can run: the ones not backed by a pod or backed by a pod scheduled on
+// a node without agent querying its endpoints checks. The templates are
+// resolved with the address of the endpoint as the runners can't resolve
+// them with the pod, the ones that can't be resolved are skipped.
+func buildFallbackChecks(kendpoints *v1.Endpoints, epInfo *types.EndpointsInfo, hasLocalAgent func(nodeName string) bool) []integration.Config {
+	var fallbackChecks []integration.Config
+	seenIPs := make(map[string]struct{})
+	for i := range kendpoints.Subsets {
+		var port int32
+		if len(kendpoints.Subsets[i].Ports) > 0 {
+			port = kendpoints.Subsets[i].Ports[0].Port
+		}
+		for j := range kendpoints.Subsets[i].Addresses {
+			address := kendpoints.Subsets[i].Addresses[j]
+			if isPodAddress(address) && hasLocalAgent(*address.NodeName) {
+				continue
+			}
+			if _, found := seenIPs[address.IP]; found {
+				continue
+			}
+			seenIPs[address.IP] = struct{}{}
+			for _, config := range epInfo.Configs {
+				fallbackConfig, ok := resolveEndpointTemplate(config, address.IP, port)
+				if !ok {
+					log.Debugf("Cannot run endpoints check %s for %s/%s on a cluster check runner: the template can't be resolved with the endpoint address", config.Name, epInfo.Namespace, epInfo.Name)
+					continue
+				}
+				if err := addEndpointTags(&fallbackConfig, address.IP); err != nil {
+					log.Warnf("Cannot tag endpoints check %s for %s/%s: %s", config.Name, epInfo.Namespace, epInfo.Name, err)
+				}
+				fallbackChecks = append(fallbackChecks, fallbackConfig)
+			}
+		}
+	}
+	return fallbackChecks
+}
+
+// resolveEndpointTemplate returns a cluster check config of an endpoints
+// config template, its %%host%% and %%port%% template variables are replaced
+// by the address and port of the endpoint. It returns false if the template
+// uses other template variables.
+func resolveEndpointTemplate(tpl integration.Config, ip string, port int32) (integration.Config, bool) {
+	replacer := func(data integration.Data) (integration.Data, bool) {
+		resolved := bytes.Replace(data, []byte("%%host%%"), []byte(ip), -1)
+		if port != 0 {
+			resolved = bytes.Replace(resolved, []byte("%%port%%"), []byte(strconv.Itoa(int(port))), -1)
+		}
+		return integration.Data(resolved), !bytes.Contains(resolved, []byte("%%"))
+	}
+	config := integration.Config{
+		Name:         tpl.Name,
+		MetricConfig: tpl.MetricConfig,
+		LogsConfig:   tpl.LogsConfig,
+		ClusterCheck: true,
+		Provider:     tpl.Provider,
+		Instances:    make([]integration.Data, len(tpl.Instances)),
+	}
+	var ok bool
+	if config.InitConfig, ok = replacer(tpl.InitConfig); !ok {
+		return config, false
+	}
+	for i := range tpl.Instances {
+		if config.Instances[i], ok = replacer(tpl.Instances[i]); !ok {
+			return config, false
+		}
+	}
+	return config, true
+}
+
 // updateADIdentifiers generates a config template for an endpoints check
 // with adding pod entity and kube service entity as AD identifiers.
 func updateADIdentifiers(config integration.Config, podUID, svcEntity string) integration.Config {
 	updatedConfig := integration.Config{
-		Name:            config.Name,
-		Instances:       config.Instances,
-		InitConfig:      config.InitConfig,
-		MetricConfig:    config.MetricConfig,
-		LogsConfig:      config.LogsConfig,
-		ADIdentifiers:   []string{},
-		ClusterCheck:    config.ClusterCheck,
-		Provider:        config.Provider,
-		EndpointsChecks: config.EndpointsChecks,
+		Name:          config.Name,
+		Instances:     config.Instances,
+		InitConfig:    config.InitConfig,
+		MetricConfig:  config.MetricConfig,
+		LogsConfig:    config.LogsConfig,
+		ADIdentifiers: []string{},
+		ClusterCheck:  config.ClusterCheck,
+		Provider:      config.Provider,
 	}
 	updatedConfig.ADIdentifiers = append(updatedConfig.ADIdentifiers, config.ADIdentifiers...)
 	updatedConfig.ADIdentifiers = append(updatedConfig.ADIdentifiers, getPodEntity(podUID))
@@ -111,6 +280,23 @@ func updateADIdentifiers(config integration.Config, podUID, svcEntity string) in
 	return updatedConfig
 }
 
+// addEndpointTags injects the endpoint IP as a tag in all instances of
+// an endpoints check. The instances are copied to keep the template intact.
+func addEndpointTags(config *integration.Config, ip string) error {
+	if ip == "" || len(config.Instances) == 0 {
+		return nil
+	}
+	instances := make([]integration.Data, len(config.Instances))
+	copy(instances, config.Instances)
+	for i := range instances {
+		if err := instances[i].MergeAdditionalTags([]string{kubeEndpointIPTag + ip}); err != nil {
+			return err
+		}
+	}
+	config.Instances = instances
+	return nil
+}
+
 // unionMaps returns the union of two maps containing endpoints checks.
 func unionMaps(first, second map[string][]integration.Config) map[string][]integration.Config {
 	for k, v := range second {
@@ -10,6 +10,7 @@ package clusterchecks
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -28,6 +29,9 @@ type dispatcher struct {
 	nodeExpirationSeconds int64
 	extraTags             []string
 	endpointsLister       v1.EndpointsLister
+	// endpointsUpdateMutex serializes the updates of the endpoints and
+	// fallback checks, run by the api handlers and the run loops
+	endpointsUpdateMutex sync.Mutex
 }
 
 func newDispatcher() *dispatcher {
@@ -164,6 +168,13 @@ func (d *dispatcher) run(ctx context.Context) {
 				danglingConfs := d.retrieveAndClearDangling()
 				d.reschedule(danglingConfs)
 			}
+
+			// Dispatch the endpoints checks no node agent runs anymore
+			if d.endpointsLister != nil {
+				if err := d.updateEndpointsChecks(); err != nil {
+					log.Warnf("Cannot update endpoints checks: %s", err)
+				}
+			}
 		}
 	}
 }
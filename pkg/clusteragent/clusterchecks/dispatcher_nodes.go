@@ -63,23 +63,70 @@ func (d *dispatcher) processNodeStatus(nodeName string, status types.NodeStatus)
 	return false, nil
 }
 
+// getSharedState returns the configurations dispatched to each node, for
+// the followers to serve them. It returns false until the dispatching is active.
+func (d *dispatcher) getSharedState() (types.SharedState, bool) {
+	d.store.RLock()
+	defer d.store.RUnlock()
+
+	if !d.store.active {
+		return types.SharedState{}, false
+	}
+
+	state := types.SharedState{
+		UpdatedAt: timestampNow(),
+		Nodes:     make(map[string]types.SharedNodeState),
+	}
+	for name, node := range d.store.nodes {
+		node.RLock()
+		state.Nodes[name] = types.SharedNodeState{
+			LastChange: node.lastConfigChange,
+			Configs:    makeConfigArray(node.digestToConfig),
+		}
+		node.RUnlock()
+	}
+	for name, configs := range d.store.endpointsChecks {
+		node := state.Nodes[name]
+		node.EndpointsConfigs = configs
+		state.Nodes[name] = node
+	}
+	return state, true
+}
+
 // getLeastBusyNode returns the name of the node that is assigned
-// the lowest number of checks. In case of equality, one is chosen
-// randomly, based on map iterations being randomized.
+// the lowest number of checks. If cluster check runners are registered,
+// the checks are only dispatched to them. Nodes that reached their
+// capacity are skipped. In case of equality, the node reporting the
+// lowest total execution time is chosen, then one is chosen randomly,
+// based on map iterations being randomized.
 func (d *dispatcher) getLeastBusyNode() string {
 	var leastBusyNode string
 	minCheckCount := int(-1)
+	var minExecutionTime int64
 
 	d.store.RLock()
 	defer d.store.RUnlock()
 
+	runnersOnly := d.store.hasRunners()
+
 	for name, store := range d.store.nodes {
 		if name == "" {
 			continue
 		}
-		if minCheckCount == -1 || len(store.digestToConfig) < minCheckCount {
+		store.RLock()
+		eligible := !store.isFull() && (!runnersOnly || store.lastStatus.ClcRunner)
+		checkCount := len(store.digestToConfig)
+		executionTime := store.totalExecutionTime()
+		store.RUnlock()
+
+		if !eligible {
+			continue
+		}
+		if minCheckCount == -1 || checkCount < minCheckCount ||
+			(checkCount == minCheckCount && executionTime < minExecutionTime) {
 			leastBusyNode = name
-			minCheckCount = len(store.digestToConfig)
+			minCheckCount = checkCount
+			minExecutionTime = executionTime
 		}
 	}
 	return leastBusyNode
@@ -10,6 +10,7 @@ package clusterchecks
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,7 +22,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ktypes "k8s.io/apimachinery/pkg/types"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 func generateIntegration(name string) integration.Config {
@@ -199,6 +203,49 @@ func TestGetLeastBusyNode(t *testing.T) {
 	requireNotLocked(t, dispatcher.store)
 }
 
+func TestGetLeastBusyNodeWithRunners(t *testing.T) {
+	dispatcher := newDispatcher()
+
+	// node1 runs no check but is not a cluster check runner
+	dispatcher.processNodeStatus("node1", types.NodeStatus{})
+	dispatcher.addConfig(generateIntegration("A"), "runner1")
+	dispatcher.processNodeStatus("runner1", types.NodeStatus{ClcRunner: true, Capacity: 2})
+	assert.Equal(t, "runner1", dispatcher.getLeastBusyNode())
+
+	// Equal check count, runner2 reports a lower execution time
+	dispatcher.addConfig(generateIntegration("B"), "runner2")
+	dispatcher.processNodeStatus("runner1", types.NodeStatus{
+		ClcRunner: true,
+		Capacity:  2,
+		Stats:     types.CLCRunnerStats{"A": {AverageExecutionTime: 500}},
+	})
+	dispatcher.processNodeStatus("runner2", types.NodeStatus{
+		ClcRunner: true,
+		Stats:     types.CLCRunnerStats{"B": {AverageExecutionTime: 100}},
+	})
+	assert.Equal(t, "runner2", dispatcher.getLeastBusyNode())
+
+	// runner1 reached its capacity
+	dispatcher.addConfig(generateIntegration("C"), "runner1")
+	dispatcher.addConfig(generateIntegration("D"), "runner2")
+	dispatcher.addConfig(generateIntegration("E"), "runner2")
+	assert.Equal(t, "runner2", dispatcher.getLeastBusyNode())
+
+	state, err := dispatcher.getState()
+	assert.NoError(t, err)
+	for _, node := range state.Nodes {
+		switch node.Name {
+		case "runner1":
+			assert.True(t, node.ClcRunner)
+			assert.Equal(t, 2, node.Capacity)
+		case "node1":
+			assert.False(t, node.ClcRunner)
+		}
+	}
+
+	requireNotLocked(t, dispatcher.store)
+}
+
 func TestExpireNodes(t *testing.T) {
 	dispatcher := newDispatcher()
 
@@ -437,13 +484,16 @@ func TestBuildEndpointsChecks(t *testing.T) {
 			{
 				Addresses: []v1.EndpointAddress{
 					{IP: "10.0.0.1", Hostname: "testhost1", NodeName: &nodename1, TargetRef: &v1.ObjectReference{
-						UID: ktypes.UID("pod-uid-1"),
+						UID:  ktypes.UID("pod-uid-1"),
+						Kind: "Pod",
 					}},
 					{IP: "10.0.0.2", Hostname: "testhost2", NodeName: &nodename2, TargetRef: &v1.ObjectReference{
-						UID: ktypes.UID("pod-uid-2"),
+						UID:  ktypes.UID("pod-uid-2"),
+						Kind: "Pod",
 					}},
 					{IP: "10.0.0.3", Hostname: "testhost3", NodeName: &nodename3, TargetRef: &v1.ObjectReference{
-						UID: ktypes.UID("pod-uid-3"),
+						UID:  ktypes.UID("pod-uid-3"),
+						Kind: "Pod",
 					}},
 				},
 			},
@@ -454,16 +504,30 @@ func TestBuildEndpointsChecks(t *testing.T) {
 			{
 				Addresses: []v1.EndpointAddress{
 					{IP: "10.0.0.1", Hostname: "testhost1", NodeName: &nodename1, TargetRef: &v1.ObjectReference{
-						UID: ktypes.UID("pod-uid-1"),
+						UID:  ktypes.UID("pod-uid-1"),
+						Kind: "Pod",
 					}},
 					{IP: "10.0.0.2", Hostname: "testhost2", NodeName: &nodename2, TargetRef: &v1.ObjectReference{
-						UID: ktypes.UID("pod-uid-2"),
+						UID:  ktypes.UID("pod-uid-2"),
+						Kind: "Pod",
 					}},
 					{IP: "10.0.0.3", Hostname: "testhost3", NodeName: &nodename2, TargetRef: &v1.ObjectReference{
-						UID: ktypes.UID("pod-uid-3"),
+						UID:  ktypes.UID("pod-uid-3"),
+						Kind: "Pod",
 					}},
 				},
 			},
+			{
+				// Same pods exposed on another port, must not be duplicated
+				Addresses: []v1.EndpointAddress{
+					{IP: "10.0.0.1", Hostname: "testhost1", NodeName: &nodename1, TargetRef: &v1.ObjectReference{
+						UID:  ktypes.UID("pod-uid-1"),
+						Kind: "Pod",
+					}},
+					// Not backed by a pod, must be ignored
+					{IP: "10.0.0.4", Hostname: "external"},
+				},
+			},
 		},
 	}
 	endpointsInfo := &types.EndpointsInfo{
@@ -520,3 +584,205 @@ func TestBuildEndpointsChecks(t *testing.T) {
 	assert.Equal(t, expectedResult2["nodename1"], result["nodename1"])
 	assert.Equal(t, expectedResult2["nodename2"], result["nodename2"])
 }
+
+func TestBuildEndpointsChecksInstanceTags(t *testing.T) {
+	nodename := "nodename1"
+	kendpoints := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					{IP: "10.0.0.1", NodeName: &nodename, TargetRef: &v1.ObjectReference{
+						UID:  ktypes.UID("pod-uid-1"),
+						Kind: "Pod",
+					}},
+					{IP: "10.0.0.2", NodeName: &nodename, TargetRef: &v1.ObjectReference{
+						UID:  ktypes.UID("pod-uid-2"),
+						Kind: "Pod",
+					}},
+				},
+			},
+		},
+	}
+	template := integration.Config{
+		Name:          "http_check",
+		ADIdentifiers: []string{"kube_endpoint://default/myservice"},
+		Instances:     []integration.Data{integration.Data("url: http://%%host%%\ntags: [\"foo:bar\"]")},
+	}
+	endpointsInfo := &types.EndpointsInfo{
+		Namespace:     "default",
+		Name:          "myservice",
+		ServiceEntity: "kube_service://myservice-uid",
+		Configs:       []integration.Config{template},
+	}
+
+	result := buildEndpointsChecks(kendpoints, endpointsInfo)
+	require.Len(t, result["nodename1"], 2)
+	assert.Contains(t, string(result["nodename1"][0].Instances[0]), "kube_endpoint_ip:10.0.0.1")
+	assert.Contains(t, string(result["nodename1"][0].Instances[0]), "foo:bar")
+	assert.Contains(t, string(result["nodename1"][1].Instances[0]), "kube_endpoint_ip:10.0.0.2")
+	assert.NotEqual(t, result["nodename1"][0].Digest(), result["nodename1"][1].Digest())
+
+	// The template must not be modified
+	assert.NotContains(t, string(template.Instances[0]), "kube_endpoint_ip")
+}
+
+func TestHasPodRef(t *testing.T) {
+	nodename := "nodename1"
+	for name, tc := range map[string]struct {
+		addresses []v1.EndpointAddress
+		expected  bool
+	}{
+		"no address": {nil, false},
+		"no target": {[]v1.EndpointAddress{{IP: "10.0.0.1"}}, false},
+		"pod after external address": {[]v1.EndpointAddress{
+			{IP: "10.0.0.1"},
+			{IP: "10.0.0.2", NodeName: &nodename, TargetRef: &v1.ObjectReference{Kind: "Pod"}},
+		}, true},
+		"pod not scheduled": {[]v1.EndpointAddress{
+			{IP: "10.0.0.1", TargetRef: &v1.ObjectReference{Kind: "Pod"}},
+		}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			kendpoints := &v1.Endpoints{Subsets: []v1.EndpointSubset{{Addresses: tc.addresses}}}
+			assert.Equal(t, tc.expected, hasPodRef(kendpoints))
+		})
+	}
+}
+
+func TestBuildFallbackChecks(t *testing.T) {
+	nodename1 := "nodename1"
+	nodename2 := "nodename2"
+	kendpoints := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{
+				Ports: []v1.EndpointPort{{Port: 8080}},
+				Addresses: []v1.EndpointAddress{
+					{IP: "10.0.0.1", NodeName: &nodename1, TargetRef: &v1.ObjectReference{
+						UID:  ktypes.UID("pod-uid-1"),
+						Kind: "Pod",
+					}},
+					// No agent on nodename2
+					{IP: "10.0.0.2", NodeName: &nodename2, TargetRef: &v1.ObjectReference{
+						UID:  ktypes.UID("pod-uid-2"),
+						Kind: "Pod",
+					}},
+					// Not backed by a pod
+					{IP: "10.0.0.3"},
+				},
+			},
+			{
+				// Same address exposed on another port, must not be duplicated
+				Ports: []v1.EndpointPort{{Port: 9090}},
+				Addresses: []v1.EndpointAddress{
+					{IP: "10.0.0.3"},
+				},
+			},
+		},
+	}
+	endpointsInfo := &types.EndpointsInfo{
+		Namespace:     "default",
+		Name:          "myservice",
+		ServiceEntity: "kube_service://myservice-uid",
+		Configs: []integration.Config{
+			{
+				Name:          "http_check",
+				ADIdentifiers: []string{"kube_endpoint://default/myservice"},
+				Instances:     []integration.Data{integration.Data("url: http://%%host%%:%%port%%")},
+			},
+			{
+				// Can't be resolved without the pod
+				Name:          "redisdb",
+				ADIdentifiers: []string{"kube_endpoint://default/myservice"},
+				Instances:     []integration.Data{integration.Data("host: %%host%%\npassword: %%env_REDIS_PASSWORD%%")},
+			},
+		},
+	}
+	hasLocalAgent := func(nodeName string) bool { return nodeName == nodename1 }
+
+	result := buildFallbackChecks(kendpoints, endpointsInfo, hasLocalAgent)
+	require.Len(t, result, 2)
+	for i, ip := range []string{"10.0.0.2", "10.0.0.3"} {
+		assert.Equal(t, "http_check", result[i].Name)
+		assert.True(t, result[i].ClusterCheck)
+		assert.Empty(t, result[i].ADIdentifiers)
+		assert.Contains(t, string(result[i].Instances[0]), fmt.Sprintf("http://%s:8080", ip))
+		assert.Contains(t, string(result[i].Instances[0]), kubeEndpointIPTag+ip)
+	}
+
+	// The template must not be modified
+	assert.Equal(t, "url: http://%%host%%:%%port%%", string(endpointsInfo.Configs[0].Instances[0]))
+}
+
+func TestUpdateFallbackChecks(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.processNodeStatus("runner1", types.NodeStatus{ClcRunner: true})
+
+	checkA := generateIntegration("A")
+	checkA.Instances = []integration.Data{integration.Data("url: http://10.0.0.1")}
+	checkB := generateIntegration("B")
+	checkB.Instances = []integration.Data{integration.Data("url: http://10.0.0.2")}
+
+	dispatcher.updateFallbackChecks([]integration.Config{checkA, checkB})
+	configs, _, err := dispatcher.getNodeConfigs("runner1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, extractCheckNames(configs))
+	assert.Len(t, dispatcher.store.fallbackChecks, 2)
+
+	// The agent of the pod of B is back, B is removed from the runner
+	dispatcher.updateFallbackChecks([]integration.Config{checkA})
+	configs, _, err = dispatcher.getNodeConfigs("runner1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A"}, extractCheckNames(configs))
+	assert.Len(t, dispatcher.store.fallbackChecks, 1)
+
+	dispatcher.updateFallbackChecks(nil)
+	allConfigs, err := dispatcher.getAllConfigs()
+	require.NoError(t, err)
+	assert.Len(t, allConfigs, 0)
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestUpdateEndpointsChecksConcurrently(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.store.active = true
+	dispatcher.processNodeStatus("runner1", types.NodeStatus{ClcRunner: true})
+
+	// An endpoint not backed by a pod runs as a fallback check
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "myservice"},
+		Subsets: []v1.EndpointSubset{{
+			Ports:     []v1.EndpointPort{{Port: 8080}},
+			Addresses: []v1.EndpointAddress{{IP: "10.0.0.1"}},
+		}},
+	}))
+	dispatcher.endpointsLister = listersv1.NewEndpointsLister(indexer)
+	dispatcher.store.endpointsCache[ktypes.UID("myservice-uid")] = &types.EndpointsInfo{
+		Namespace:     "default",
+		Name:          "myservice",
+		ServiceEntity: "kube_service://myservice-uid",
+		Configs: []integration.Config{{
+			Name:      "http_check",
+			Instances: []integration.Data{integration.Data("url: http://%%host%%:%%port%%")},
+		}},
+	}
+
+	// The node agents and the run loop update the checks at the same time
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, dispatcher.updateEndpointsChecks())
+		}()
+	}
+	wg.Wait()
+
+	configs, _, err := dispatcher.getNodeConfigs("runner1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http_check"}, extractCheckNames(configs))
+	assert.Len(t, dispatcher.store.fallbackChecks, 1)
+
+	requireNotLocked(t, dispatcher.store)
+}
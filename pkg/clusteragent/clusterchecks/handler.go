@@ -10,6 +10,7 @@ package clusterchecks
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -48,6 +49,7 @@ type Handler struct {
 	warmupDuration       time.Duration
 	leaderStatusCallback types.LeaderIPCallback
 	leadershipChan       chan state
+	shared               *sharedState // Nil unless the shared state is enabled
 	m                    sync.RWMutex // Below fields protected by the mutex
 	state                state
 	leaderIP             string
@@ -75,6 +77,15 @@ func NewHandler(ac pluggableAutoConfig) (*Handler, error) {
 			return nil, err
 		}
 		h.leaderStatusCallback = callback
+
+		if config.Datadog.GetBool("cluster_checks.shared_state_enabled") {
+			shared, err := newSharedState()
+			if err != nil {
+				log.Errorf("Cannot create the shared state, followers will redirect to the leader: %s", err)
+			} else {
+				h.shared = shared
+			}
+		}
 	}
 
 	// Cache a pointer to the handler for the agent status command
@@ -97,6 +108,10 @@ func (h *Handler) Run(ctx context.Context) {
 	}
 	h.m.Unlock()
 
+	if h.shared != nil {
+		go h.runSharedState(ctx)
+	}
+
 	for {
 		// Follower / unknown
 		select {
@@ -228,3 +243,49 @@ func (h *Handler) updateLeaderIP() error {
 
 	return nil
 }
+
+// runSharedState saves the dispatching state when leading, or loads it
+// and forwards the node-agent reports to the leader when following
+func (h *Handler) runSharedState(ctx context.Context) {
+	syncTicker := time.NewTicker(h.shared.interval)
+	defer syncTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-syncTicker.C:
+			h.syncSharedState()
+		}
+	}
+}
+
+func (h *Handler) syncSharedState() {
+	h.m.RLock()
+	currentState := h.state
+	leaderURL := fmt.Sprintf("https://%s:%d/api/v1/clusterchecks/forwarded", h.leaderIP, h.port)
+	h.m.RUnlock()
+
+	switch currentState {
+	case leader:
+		// Apply the reports received while following
+		h.PostForwardedStatuses(h.shared.takePending())
+
+		if h.dispatcher.endpointsLister != nil {
+			if err := h.dispatcher.updateEndpointsChecks(); err != nil {
+				log.Warnf("Cannot update endpoints checks: %s", err)
+			}
+		}
+		if err := h.shared.publish(h.dispatcher); err != nil {
+			// Followers redirect to the leader once the saved state is stale
+			log.Errorf("Cannot save the cluster checks shared state: %s", err)
+		}
+	case follower:
+		if err := h.shared.refresh(); err != nil {
+			log.Debugf("Cannot load the cluster checks shared state: %s", err)
+		}
+		if err := h.shared.forward(leaderURL); err != nil {
+			log.Debugf("Cannot forward the node statuses to the leader: %s", err)
+		}
+	}
+}
@@ -19,6 +19,8 @@ const (
 	kubeServiceIDPrefix  = "kube_service://"
 	KubePodPrefix        = "kubernetes_pod://"
 	kubeEndpointIDPrefix = "kube_endpoint://"
+	kubeEndpointIPTag    = "kube_endpoint_ip:"
+	kubePodKind          = "Pod"
 )
 
 // makeConfigArray flattens a map of configs into a slice. Creating a new slice
@@ -44,7 +46,7 @@ func isKubeServiceCheck(config integration.Config) bool {
 
 // getServiceUID retrieves service UID from service config
 func getServiceUID(config integration.Config) string {
-	return strings.TrimLeft(config.Entity, kubeServiceIDPrefix)
+	return strings.TrimPrefix(config.Entity, kubeServiceIDPrefix)
 }
 
 // getPodEntity returns pod entity
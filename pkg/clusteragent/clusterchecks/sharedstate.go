@@ -0,0 +1,221 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// sharedStateMaxAge is the number of sync intervals after which
+	// followers stop serving a shared state the leader didn't update
+	sharedStateMaxAge = 3
+	// defaultSharedStateSyncInterval is used when the configured interval is invalid
+	defaultSharedStateSyncInterval = 10 * time.Second
+)
+
+// stateStore persists the shared state, see sharedstate_kube.go
+type stateStore interface {
+	save(state types.SharedState) error
+	load() (types.SharedState, error)
+}
+
+// sharedState allows all the cluster-agent replicas to serve the node-agents:
+// the leader periodically saves its dispatching state in a store, followers
+// load it to answer the node-agents and forward their statuses to the leader.
+type sharedState struct {
+	store          stateStore
+	interval       time.Duration
+	client         *http.Client
+	m              sync.RWMutex // Below fields protected by the mutex
+	state          types.SharedState
+	statuses       map[string]types.NodeStatus // Statuses to forward to the leader
+	endpointsNodes map[string]struct{}         // Endpoints queries to forward to the leader
+}
+
+func newSharedState() (*sharedState, error) {
+	store, err := newStateStore()
+	if err != nil {
+		return nil, err
+	}
+	return newSharedStateWithStore(store), nil
+}
+
+func newSharedStateWithStore(store stateStore) *sharedState {
+	client := util.GetClient(false)
+	// Forwarded statuses are dropped if the recipient is not leading anymore
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	client.Timeout = 5 * time.Second
+
+	interval := config.Datadog.GetDuration("cluster_checks.shared_state_sync_interval") * time.Second
+	if interval <= 0 {
+		log.Warnf("Invalid cluster_checks.shared_state_sync_interval %v, using %v", interval, defaultSharedStateSyncInterval)
+		interval = defaultSharedStateSyncInterval
+	}
+
+	return &sharedState{
+		store:          store,
+		interval:       interval,
+		client:         client,
+		statuses:       make(map[string]types.NodeStatus),
+		endpointsNodes: make(map[string]struct{}),
+	}
+}
+
+// isFresh returns true if the last state loaded was recently saved by the leader
+func (s *sharedState) isFresh() bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	maxAge := int64(sharedStateMaxAge * s.interval / time.Second)
+	return s.state.UpdatedAt > 0 && timestampNow()-s.state.UpdatedAt <= maxAge
+}
+
+// getConfigs returns configurations dispatched to a given node
+func (s *sharedState) getConfigs(nodeName string) (types.ConfigResponse, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	node, found := s.state.Nodes[nodeName]
+	if !found {
+		return types.ConfigResponse{}, fmt.Errorf("node %s is unknown", nodeName)
+	}
+	return types.ConfigResponse{
+		Configs:    node.Configs,
+		LastChange: node.LastChange,
+	}, nil
+}
+
+// processNodeStatus queues the status for the leader, and returns true if
+// the last configuration change matches the one sent by the node-agent.
+func (s *sharedState) processNodeStatus(nodeName string, status types.NodeStatus) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.statuses[nodeName] = status
+	// Nodes unknown to the leader have no configuration dispatched yet
+	return s.state.Nodes[nodeName].LastChange == status.LastChange
+}
+
+// getEndpointsConfigs returns endpoints configurations dispatched to a given node
+func (s *sharedState) getEndpointsConfigs(nodeName string) types.ConfigResponse {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.endpointsNodes[nodeName] = struct{}{}
+	return types.ConfigResponse{
+		Configs:    s.state.Nodes[nodeName].EndpointsConfigs,
+		LastChange: 0,
+	}
+}
+
+// takePending returns the node-agent reports received since the last call
+func (s *sharedState) takePending() types.ForwardedStatuses {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	pending := types.ForwardedStatuses{}
+	if len(s.statuses) > 0 {
+		pending.Statuses = s.statuses
+		s.statuses = make(map[string]types.NodeStatus)
+	}
+	for nodeName := range s.endpointsNodes {
+		pending.EndpointsNodes = append(pending.EndpointsNodes, nodeName)
+	}
+	s.endpointsNodes = make(map[string]struct{})
+	return pending
+}
+
+// refresh loads the state saved by the leader
+func (s *sharedState) refresh() error {
+	state, err := s.store.load()
+	if err != nil {
+		return err
+	}
+	s.m.Lock()
+	s.state = state
+	s.m.Unlock()
+	return nil
+}
+
+// publish saves the state of the dispatcher, if it is active
+func (s *sharedState) publish(d *dispatcher) error {
+	state, active := d.getSharedState()
+	if !active {
+		return nil
+	}
+	return s.store.save(state)
+}
+
+// forward sends the pending node-agent reports to the leader's url
+func (s *sharedState) forward(url string) error {
+	pending := s.takePending()
+	if len(pending.Statuses) == 0 && len(pending.EndpointsNodes) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+util.GetDCAAuthToken())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from the leader: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encodeSharedState serializes and compresses the state, to fit
+// the states of large clusters in the store
+func encodeSharedState(state types.SharedState) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(state); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSharedState is the reverse of encodeSharedState
+func decodeSharedState(data []byte) (types.SharedState, error) {
+	var state types.SharedState
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return state, err
+	}
+	defer zr.Close()
+	err = json.NewDecoder(zr).Decode(&state)
+	return state, err
+}
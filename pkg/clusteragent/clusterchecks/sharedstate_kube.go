@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build clusterchecks
+// +build kubeapiserver
+
+package clusterchecks
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+)
+
+const (
+	sharedStateConfigMap = "datadog-cluster-checks-state"
+	sharedStateKey       = "state.json.gz"
+	// maxConfigMapSize is the maximum size of the data of a ConfigMap
+	// accepted by the apiserver
+	maxConfigMapSize = 1024 * 1024
+)
+
+// configMapStateStore stores the shared state in a ConfigMap
+type configMapStateStore struct {
+	client    corev1.ConfigMapsGetter
+	namespace string
+}
+
+// newStateStore returns a store backed by a ConfigMap of the resources namespace
+func newStateStore() (stateStore, error) {
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+	return &configMapStateStore{
+		client:    ac.Cl.CoreV1(),
+		namespace: common.GetResourcesNamespace(),
+	}, nil
+}
+
+func (s *configMapStateStore) save(state types.SharedState) error {
+	data, err := encodeSharedState(state)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxConfigMapSize {
+		return fmt.Errorf("the state is %d bytes, larger than the %d bytes of the ConfigMap %s", len(data), maxConfigMapSize, sharedStateConfigMap)
+	}
+
+	cm, err := s.client.ConfigMaps(s.namespace).Get(sharedStateConfigMap, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = s.client.ConfigMaps(s.namespace).Create(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      sharedStateConfigMap,
+				Namespace: s.namespace,
+			},
+			BinaryData: map[string][]byte{sharedStateKey: data},
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	cm.BinaryData = map[string][]byte{sharedStateKey: data}
+	_, err = s.client.ConfigMaps(s.namespace).Update(cm)
+	return err
+}
+
+func (s *configMapStateStore) load() (types.SharedState, error) {
+	cm, err := s.client.ConfigMaps(s.namespace).Get(sharedStateConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return types.SharedState{}, err
+	}
+	data, found := cm.BinaryData[sharedStateKey]
+	if !found {
+		return types.SharedState{}, fmt.Errorf("no %s in the ConfigMap %s", sharedStateKey, sharedStateConfigMap)
+	}
+	return decodeSharedState(data)
+}
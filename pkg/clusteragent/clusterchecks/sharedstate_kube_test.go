@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build clusterchecks
+// +build kubeapiserver
+
+package clusterchecks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+)
+
+func TestConfigMapStateStore(t *testing.T) {
+	store := &configMapStateStore{
+		client:    fake.NewSimpleClientset().CoreV1(),
+		namespace: "default",
+	}
+	_, err := store.load()
+	assert.Error(t, err)
+
+	state := types.SharedState{
+		UpdatedAt: 1234,
+		Nodes:     map[string]types.SharedNodeState{"node1": {LastChange: 42}},
+	}
+	require.NoError(t, store.save(state))
+	require.NoError(t, store.save(state))
+	loaded, err := store.load()
+	require.NoError(t, err)
+	assert.Equal(t, state, loaded)
+
+	// States larger than a ConfigMap are not saved
+	large := types.SharedState{UpdatedAt: 5678, Nodes: make(map[string]types.SharedNodeState)}
+	name := make([]byte, 32)
+	for i := 0; i < 60000; i++ {
+		_, err := rand.Read(name)
+		require.NoError(t, err)
+		large.Nodes[hex.EncodeToString(name)] = types.SharedNodeState{}
+	}
+	assert.Error(t, store.save(large))
+	loaded, err = store.load()
+	require.NoError(t, err)
+	assert.Equal(t, state, loaded)
+}
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// fakeStateStore keeps the encoded state in memory
+type fakeStateStore struct {
+	sync.Mutex
+	data []byte
+}
+
+func (s *fakeStateStore) save(state types.SharedState) error {
+	data, err := encodeSharedState(state)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.data = data
+	return nil
+}
+
+func (s *fakeStateStore) load() (types.SharedState, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.data == nil {
+		return types.SharedState{}, errors.New("no state saved")
+	}
+	return decodeSharedState(s.data)
+}
+
+func TestSharedStateFollower(t *testing.T) {
+	store := &fakeStateStore{}
+
+	leader := &Handler{
+		dispatcher: newDispatcher(),
+		state:      leader,
+		shared:     newSharedStateWithStore(store),
+	}
+	follower := &Handler{
+		dispatcher: newDispatcher(),
+		state:      follower,
+		leaderIP:   "1.2.3.4",
+		port:       5005,
+		shared:     newSharedStateWithStore(store),
+	}
+
+	// Nothing saved by the leader yet, redirect
+	require.Error(t, follower.shared.refresh())
+	code, _ := follower.ShouldHandle()
+	assert.Equal(t, http.StatusFound, code)
+
+	// Nothing saved during the warmup
+	leader.dispatcher.processNodeStatus("node1", types.NodeStatus{})
+	require.NoError(t, leader.shared.publish(leader.dispatcher))
+	require.Error(t, follower.shared.refresh())
+
+	leader.dispatcher.store.active = true
+	leader.dispatcher.addConfig(generateIntegration("A"), "node1")
+	require.NoError(t, leader.shared.publish(leader.dispatcher))
+	require.NoError(t, follower.shared.refresh())
+	code, _ = follower.ShouldHandle()
+	assert.Equal(t, http.StatusOK, code)
+
+	// The follower serves the configurations dispatched by the leader
+	expected, err := leader.GetConfigs("node1")
+	require.NoError(t, err)
+	configs, err := follower.GetConfigs("node1")
+	require.NoError(t, err)
+	assert.Equal(t, expected.LastChange, configs.LastChange)
+	assert.Equal(t, []string{"A"}, extractCheckNames(configs.Configs))
+	_, err = follower.GetConfigs("node2")
+	assert.Error(t, err)
+
+	status, err := follower.PostStatus("node1", types.NodeStatus{LastChange: expected.LastChange})
+	require.NoError(t, err)
+	assert.True(t, status.IsUpToDate)
+	status, err = follower.PostStatus("node2", types.NodeStatus{ClcRunner: true})
+	require.NoError(t, err)
+	assert.True(t, status.IsUpToDate)
+	_, err = follower.GetEndpointsConfigs("node3")
+	require.NoError(t, err)
+
+	// The reports are forwarded to the leader
+	var forwarded types.ForwardedStatuses
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&forwarded))
+		leader.PostForwardedStatuses(forwarded)
+	}))
+	defer server.Close()
+
+	require.NoError(t, follower.shared.forward(server.URL))
+	assert.Len(t, forwarded.Statuses, 2)
+	assert.Equal(t, []string{"node3"}, forwarded.EndpointsNodes)
+	assert.Contains(t, leader.dispatcher.store.nodes, "node2")
+	assert.True(t, leader.dispatcher.store.nodes["node2"].lastStatus.ClcRunner)
+	assert.Contains(t, leader.dispatcher.store.endpointsNodes, "node3")
+	requireNotLocked(t, leader.dispatcher.store)
+
+	// Nothing left to forward
+	forwarded = types.ForwardedStatuses{}
+	require.NoError(t, follower.shared.forward(server.URL))
+	assert.Nil(t, forwarded.Statuses)
+
+	// Stale states are not served
+	follower.shared.m.Lock()
+	follower.shared.state.UpdatedAt = timestampNow() - int64(10*follower.shared.interval/time.Second)
+	follower.shared.m.Unlock()
+	code, reason := follower.ShouldHandle()
+	assert.Equal(t, http.StatusFound, code)
+	assert.Equal(t, "1.2.3.4:5005", reason)
+}
+
+func TestSharedStateInterval(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("cluster_checks.shared_state_sync_interval", 10)
+
+	mockConfig.Set("cluster_checks.shared_state_sync_interval", 30)
+	assert.Equal(t, 30*time.Second, newSharedStateWithStore(&fakeStateStore{}).interval)
+
+	// Invalid intervals fall back to the default
+	mockConfig.Set("cluster_checks.shared_state_sync_interval", 0)
+	assert.Equal(t, defaultSharedStateSyncInterval, newSharedStateWithStore(&fakeStateStore{}).interval)
+	mockConfig.Set("cluster_checks.shared_state_sync_interval", -5)
+	assert.Equal(t, defaultSharedStateSyncInterval, newSharedStateWithStore(&fakeStateStore{}).interval)
+}
@@ -28,6 +28,8 @@ type clusterStore struct {
 	danglingConfigs map[string]integration.Config       // Configs we could not dispatch to any node
 	endpointsCache  map[ktypes.UID]*types.EndpointsInfo // Endpoints configs and info retrieved from services configs, keys are services UIDs
 	endpointsChecks map[string][]integration.Config     // Endpoints checks to be consumed by node agents
+	endpointsNodes  map[string]int64                    // Last time node agents queried their endpoints checks, keys are node names
+	fallbackChecks  map[string]integration.Config       // Endpoints checks dispatched as cluster checks, keys are digests
 }
 
 func newClusterStore() *clusterStore {
@@ -45,6 +47,8 @@ func (s *clusterStore) reset() {
 	s.danglingConfigs = make(map[string]integration.Config)
 	s.endpointsCache = make(map[ktypes.UID]*types.EndpointsInfo)
 	s.endpointsChecks = make(map[string][]integration.Config)
+	s.endpointsNodes = make(map[string]int64)
+	s.fallbackChecks = make(map[string]integration.Config)
 }
 
 // getNodeStore retrieves the store struct for a given node name, if it exists
@@ -66,6 +70,19 @@ func (s *clusterStore) getOrCreateNodeStore(nodeName string) *nodeStore {
 	return node
 }
 
+// hasRunners returns true if a node registered as a cluster check runner
+func (s *clusterStore) hasRunners() bool {
+	for _, node := range s.nodes {
+		node.RLock()
+		isRunner := node.lastStatus.ClcRunner
+		node.RUnlock()
+		if isRunner {
+			return true
+		}
+	}
+	return false
+}
+
 // clearDangling resets the danglingConfigs map to a new empty one
 func (s *clusterStore) clearDangling() {
 	s.danglingConfigs = make(map[string]integration.Config)
@@ -95,6 +112,21 @@ func (s *nodeStore) addConfig(config integration.Config) {
 	dispatchedConfigs.WithLabelValues(s.name).Inc()
 }
 
+// isFull returns true if the node reached the number of checks it advertised
+func (s *nodeStore) isFull() bool {
+	return s.lastStatus.Capacity > 0 && len(s.digestToConfig) >= s.lastStatus.Capacity
+}
+
+// totalExecutionTime returns the sum of the average execution times of the
+// checks reported by the node, in milliseconds
+func (s *nodeStore) totalExecutionTime() int64 {
+	var total int64
+	for _, stat := range s.lastStatus.Stats {
+		total += stat.AverageExecutionTime
+	}
+	return total
+}
+
 func (s *nodeStore) removeConfig(digest string) {
 	_, found := s.digestToConfig[digest]
 	if !found {
@@ -11,7 +11,21 @@ import (
 
 // NodeStatus holds the status report from the node-agent
 type NodeStatus struct {
-	LastChange int64 `json:"last_change"`
+	LastChange int64          `json:"last_change"`
+	ClcRunner  bool           `json:"clc_runner,omitempty"` // Dedicated cluster check runner
+	Capacity   int            `json:"capacity,omitempty"`   // Maximum number of cluster checks, 0 for no limit
+	Stats      CLCRunnerStats `json:"stats,omitempty"`      // Execution stats of the cluster checks, reported by the runners
+}
+
+// CLCRunnerStats holds the execution stats of the cluster checks
+// run by a cluster check runner, indexed by check ID
+type CLCRunnerStats map[string]CLCRunnerStat
+
+// CLCRunnerStat holds the execution stats of a cluster check
+type CLCRunnerStat struct {
+	AverageExecutionTime int64 `json:"avg_execution_time"` // in milliseconds
+	MetricSamples        int64 `json:"metric_samples"`
+	LastExecFailed       bool  `json:"last_execution_failed"`
 }
 
 // StatusResponse holds the DCA response for a status report
@@ -35,8 +49,10 @@ type StateResponse struct {
 
 // StateNodeResponse is a chunk of StateResponse
 type StateNodeResponse struct {
-	Name    string               `json:"name"`
-	Configs []integration.Config `json:"configs"`
+	Name      string               `json:"name"`
+	ClcRunner bool                 `json:"clc_runner"`
+	Capacity  int                  `json:"capacity"`
+	Configs   []integration.Config `json:"configs"`
 }
 
 // Stats holds statistics for the agent status command
@@ -54,6 +70,26 @@ type Stats struct {
 	TotalConfigs    int
 }
 
+// SharedState holds the dispatching state saved by the leading cluster-agent,
+// for the followers to serve the node-agents
+type SharedState struct {
+	UpdatedAt int64                      `json:"updated_at"`
+	Nodes     map[string]SharedNodeState `json:"nodes"`
+}
+
+// SharedNodeState is a chunk of SharedState
+type SharedNodeState struct {
+	LastChange       int64                `json:"last_change"`
+	Configs          []integration.Config `json:"configs,omitempty"`
+	EndpointsConfigs []integration.Config `json:"endpoints_configs,omitempty"`
+}
+
+// ForwardedStatuses holds the reports received by a follower, forwarded to the leader
+type ForwardedStatuses struct {
+	Statuses       map[string]NodeStatus `json:"statuses,omitempty"`        // Node statuses, keys are node names
+	EndpointsNodes []string              `json:"endpoints_nodes,omitempty"` // Nodes that queried their endpoints checks
+}
+
 // LeaderIPCallback describes the leader-election method we
 // need and allows to inject a custom one for tests
 type LeaderIPCallback func() (string, error)
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package clustertags
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+// getNamespaceLabels returns the labels of all the namespaces of the cluster.
+var getNamespaceLabels = func() (map[string]map[string]string, error) {
+	cl, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := cl.Cl.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]map[string]string, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		labels[ns.Name] = ns.Labels
+	}
+	return labels, nil
+}
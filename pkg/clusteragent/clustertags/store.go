@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package clustertags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	apiv1 "github.com/DataDog/datadog-agent/pkg/clusteragent/api/v1"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/azure"
+	"github.com/DataDog/datadog-agent/pkg/util/ec2"
+	"github.com/DataDog/datadog-agent/pkg/util/gce"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const defaultRefreshInterval = 5 * time.Minute
+
+// cloudAccount gets the ID of the cloud account the cluster runs in.
+type cloudAccount struct {
+	tagName string
+	getID   func() (string, error)
+}
+
+// declare these as vars to ease testing
+var (
+	getClusterName = clustername.GetClusterName
+	cloudAccounts  = []cloudAccount{
+		{tagName: "aws_account", getID: ec2.GetAccountID},
+		{tagName: "project", getID: gce.GetProjectID},
+		{tagName: "subscription_id", getID: azure.GetSubscriptionID},
+	}
+)
+
+// Store holds the tags common to all the nodes of the cluster. They are
+// collected once per refresh interval by the Cluster Agent, so that the node
+// agents don't query the cloud provider APIs for them.
+type Store struct {
+	m               sync.RWMutex
+	clusterTags     apiv1.ClusterTags
+	refreshInterval time.Duration
+}
+
+// NewStore returns a new cluster tags store.
+func NewStore() *Store {
+	refreshInterval := time.Duration(config.Datadog.GetInt("cluster_tags.refresh_interval")) * time.Second
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+	return &Store{
+		refreshInterval: refreshInterval,
+	}
+}
+
+// Run collects the cluster tags until the context is done.
+func (s *Store) Run(ctx context.Context) {
+	s.refresh()
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// Get returns the current cluster tags.
+func (s *Store) Get() apiv1.ClusterTags {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.clusterTags
+}
+
+// refresh collects the cluster tags, the namespace labels are kept when they
+// can't be listed.
+func (s *Store) refresh() {
+	tags := collectTags()
+	namespaceLabels, err := getNamespaceLabels()
+	if err != nil {
+		log.Debugf("Could not list the labels of the namespaces: %v", err)
+		namespaceLabels = s.Get().NamespaceLabels
+	}
+	s.set(tags, namespaceLabels)
+}
+
+// set updates the cluster tags and their version when they changed.
+func (s *Store) set(tags []string, namespaceLabels map[string]map[string]string) {
+	sort.Strings(tags)
+	version, err := computeVersion(tags, namespaceLabels)
+	if err != nil {
+		log.Errorf("Could not compute the version of the cluster tags: %v", err)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if version == s.clusterTags.Version {
+		return
+	}
+	s.clusterTags = apiv1.ClusterTags{
+		Version:         version,
+		Tags:            tags,
+		NamespaceLabels: namespaceLabels,
+	}
+	log.Debugf("Cluster tags updated to version %s: %v", version, tags)
+}
+
+// collectTags returns the name of the cluster and the tags of the cloud
+// account it runs in.
+func collectTags() []string {
+	tags := []string{}
+	if clusterName := getClusterName(); clusterName != "" {
+		tags = append(tags, fmt.Sprintf("kube_cluster_name:%s", clusterName))
+	}
+	for _, account := range cloudAccounts {
+		id, err := account.getID()
+		if err != nil {
+			log.Tracef("No %s cluster tag: %v", account.tagName, err)
+			continue
+		}
+		tags = append(tags, fmt.Sprintf("%s:%s", account.tagName, id))
+		break
+	}
+	return tags
+}
+
+// computeVersion returns a hash of the cluster tags, encoding/json sorts the
+// keys of the maps so that it only depends on their content.
+func computeVersion(tags []string, namespaceLabels map[string]map[string]string) (string, error) {
+	content, err := json.Marshal(apiv1.ClusterTags{Tags: tags, NamespaceLabels: namespaceLabels})
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write(content)
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
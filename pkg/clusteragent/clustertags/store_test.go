@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package clustertags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreRefresh(t *testing.T) {
+	defer func(cn func() string, ca []cloudAccount, nl func() (map[string]map[string]string, error)) {
+		getClusterName, cloudAccounts, getNamespaceLabels = cn, ca, nl
+	}(getClusterName, cloudAccounts, getNamespaceLabels)
+
+	clusterName := "foo"
+	getClusterName = func() string { return clusterName }
+	cloudAccounts = []cloudAccount{
+		{tagName: "aws_account", getID: func() (string, error) { return "", errors.New("not on ec2") }},
+		{tagName: "project", getID: func() (string, error) { return "bar", nil }},
+		{tagName: "subscription_id", getID: func() (string, error) { return "baz", nil }},
+	}
+	namespaceLabels := map[string]map[string]string{"default": {"team": "infra"}}
+	var namespaceErr error
+	getNamespaceLabels = func() (map[string]map[string]string, error) { return namespaceLabels, namespaceErr }
+
+	s := NewStore()
+	s.refresh()
+	clusterTags := s.Get()
+	assert.Equal(t, []string{"kube_cluster_name:foo", "project:bar"}, clusterTags.Tags)
+	assert.Equal(t, namespaceLabels, clusterTags.NamespaceLabels)
+	assert.NotEmpty(t, clusterTags.Version)
+
+	// the version only changes with the tags
+	s.refresh()
+	assert.Equal(t, clusterTags.Version, s.Get().Version)
+
+	clusterName = "qux"
+	s.refresh()
+	assert.Equal(t, []string{"kube_cluster_name:qux", "project:bar"}, s.Get().Tags)
+	assert.NotEqual(t, clusterTags.Version, s.Get().Version)
+	clusterTags = s.Get()
+
+	// the namespace labels are kept when they can't be listed
+	namespaceLabels, namespaceErr = nil, errors.New("forbidden")
+	s.refresh()
+	assert.Equal(t, clusterTags, s.Get())
+
+	namespaceLabels, namespaceErr = map[string]map[string]string{"default": {"team": "data"}}, nil
+	s.refresh()
+	assert.Equal(t, namespaceLabels, s.Get().NamespaceLabels)
+	assert.NotEqual(t, clusterTags.Version, s.Get().Version)
+}
@@ -10,6 +10,7 @@ package custommetrics
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -28,8 +29,9 @@ import (
 )
 
 type externalMetric struct {
-	info  provider.ExternalMetricInfo
-	value external_metrics.ExternalMetricValue
+	info      provider.ExternalMetricInfo
+	value     external_metrics.ExternalMetricValue
+	timestamp int64
 }
 
 type datadogProvider struct {
@@ -42,16 +44,21 @@ type datadogProvider struct {
 	isServing       bool
 	timestamp       int64
 	maxAge          int64
+	metricMaxAge    int64
 }
 
 // NewDatadogProvider creates a Custom Metrics and External Metrics Provider.
 func NewDatadogProvider(ctx context.Context, client dynamic.Interface, mapper apimeta.RESTMapper, store Store) provider.MetricsProvider {
 	maxAge := config.Datadog.GetInt64("external_metrics_provider.local_copy_refresh_rate")
+	// A metric is stale once it is older than the processor tolerates and the leader missed two refreshes.
+	metricMaxAge := int64(math.Max(config.Datadog.GetFloat64("external_metrics_provider.max_age"), 3*config.Datadog.GetFloat64("external_metrics_provider.rollup")))
+	metricMaxAge += 2 * config.Datadog.GetInt64("external_metrics_provider.refresh_period")
 	d := &datadogProvider{
-		client: client,
-		mapper: mapper,
-		store:  store,
-		maxAge: maxAge,
+		client:       client,
+		mapper:       mapper,
+		store:        store,
+		maxAge:       maxAge,
+		metricMaxAge: metricMaxAge,
 	}
 	go d.externalMetricsSetter(ctx)
 	return d
@@ -114,6 +121,7 @@ func (p *datadogProvider) externalMetricsSetter(ctx context.Context) {
 					MetricLabels: metric.Labels,
 					Value:        q,
 				}
+				extMetric.timestamp = metric.Timestamp
 				externalMetricsList = append(externalMetricsList, extMetric)
 			}
 			p.externalMetrics = externalMetricsList
@@ -183,6 +191,11 @@ func (p *datadogProvider) GetExternalMetric(namespace string, metricSelector lab
 		// If tags with capital letters are used (as the label selector in the HPA), no metrics will be retrieved from Datadog.
 		if info.Metric == strings.ToLower(metric.info.Metric) &&
 			metricSelector.Matches(labels.Set(metric.value.MetricLabels)) {
+			// Do not serve a value the leader could not refresh, the HPA controller keeps the current replicas instead.
+			if time.Now().Unix()-metric.timestamp > p.metricMaxAge {
+				log.Debugf("External metric %s{%v} is stale, last updated at %s", metric.info.Metric, metric.value.MetricLabels, time.Unix(metric.timestamp, 0).Format(time.RFC850))
+				continue
+			}
 			metricValue := metricFromDatadog
 			metricValue.Timestamp = metav1.Now()
 			matchingMetrics = append(matchingMetrics, metricValue)
@@ -11,6 +11,7 @@ import (
 	"math"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/kubernetes-incubator/custom-metrics-apiserver/pkg/provider"
 	"github.com/stretchr/testify/require"
@@ -268,6 +269,7 @@ func TestGetExternalMetric(t *testing.T) {
 				externalMetrics: test.metricsStored,
 				isServing:       true,
 				maxAge:          math.MaxInt32, // to avoid flackiness
+				metricMaxAge:    math.MaxInt32,
 			}
 			output, err := dp.GetExternalMetric(test.compared.namespace, test.compared.labels.AsSelector(), test.compared.name)
 			require.NoError(t, err)
@@ -280,3 +282,34 @@ func TestGetExternalMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExternalMetricStale(t *testing.T) {
+	metricLabels := map[string]string{"foo": "bar"}
+	dp := datadogProvider{
+		externalMetrics: []externalMetric{
+			{
+				info:      provider.ExternalMetricInfo{Metric: "fresh"},
+				value:     external_metrics.ExternalMetricValue{MetricName: "fresh", MetricLabels: metricLabels},
+				timestamp: time.Now().Unix() - 30,
+			},
+			{
+				info:      provider.ExternalMetricInfo{Metric: "stale"},
+				value:     external_metrics.ExternalMetricValue{MetricName: "stale", MetricLabels: metricLabels},
+				timestamp: time.Now().Unix() - 300,
+			},
+		},
+		isServing:    true,
+		timestamp:    time.Now().Unix(),
+		maxAge:       30,
+		metricMaxAge: 120,
+	}
+
+	output, err := dp.GetExternalMetric("default", labels.Set(metricLabels).AsSelector(), provider.ExternalMetricInfo{Metric: "fresh"})
+	require.NoError(t, err)
+	require.Len(t, output.Items, 1)
+	require.Equal(t, "fresh", output.Items[0].MetricName)
+
+	output, err = dp.GetExternalMetric("default", labels.Set(metricLabels).AsSelector(), provider.ExternalMetricInfo{Metric: "stale"})
+	require.NoError(t, err)
+	require.Len(t, output.Items, 0)
+}
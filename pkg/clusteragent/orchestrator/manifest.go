@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+)
+
+// BuildManifest returns the manifest of a pod, deployment, replicaset or
+// node. The resource is copied before its sensitive values are scrubbed.
+func BuildManifest(obj interface{}, scrubber *DataScrubber) (*model.Manifest, error) {
+	switch resource := obj.(type) {
+	case *corev1.Pod:
+		pod := resource.DeepCopy()
+		scrubber.ScrubPodSpec(&pod.Spec)
+		return newManifest(model.ManifestType_pod, &pod.ObjectMeta, pod)
+	case *appsv1.Deployment:
+		deployment := resource.DeepCopy()
+		scrubber.ScrubPodSpec(&deployment.Spec.Template.Spec)
+		return newManifest(model.ManifestType_deployment, &deployment.ObjectMeta, deployment)
+	case *appsv1.ReplicaSet:
+		replicaSet := resource.DeepCopy()
+		scrubber.ScrubPodSpec(&replicaSet.Spec.Template.Spec)
+		return newManifest(model.ManifestType_replicaSet, &replicaSet.ObjectMeta, replicaSet)
+	case *corev1.Node:
+		node := resource.DeepCopy()
+		return newManifest(model.ManifestType_node, &node.ObjectMeta, node)
+	default:
+		return nil, fmt.Errorf("unsupported resource type %T", obj)
+	}
+}
+
+// newManifest encodes a copy of a resource. The configuration applied by
+// kubectl is dropped from its annotations as it holds the unscrubbed spec.
+func newManifest(manifestType model.ManifestType, meta *metav1.ObjectMeta, obj interface{}) (*model.Manifest, error) {
+	delete(meta.Annotations, corev1.LastAppliedConfigAnnotation)
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Manifest{
+		Type:            manifestType,
+		Uid:             string(meta.UID),
+		ResourceVersion: meta.ResourceVersion,
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		Content:         content,
+	}, nil
+}
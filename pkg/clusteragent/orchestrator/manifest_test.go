@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+)
+
+func TestBuildManifestPod(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:             "123",
+			ResourceVersion: "42",
+			Namespace:       "default",
+			Name:            "redis",
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"spec":{"containers":[{"env":[{"name":"REDIS_PASSWORD","value":"foo"}]}]}}`,
+				"team":                             "storage",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "redis",
+				Env:  []corev1.EnvVar{{Name: "REDIS_PASSWORD", Value: "foo"}},
+			}},
+		},
+	}
+	manifest, err := BuildManifest(pod, NewDataScrubber(defaultSensitiveWords))
+	require.NoError(t, err)
+
+	assert.Equal(t, model.ManifestType_pod, manifest.Type)
+	assert.Equal(t, "123", manifest.Uid)
+	assert.Equal(t, "42", manifest.ResourceVersion)
+	assert.Equal(t, "default", manifest.Namespace)
+	assert.Equal(t, "redis", manifest.Name)
+	assert.False(t, manifest.Deleted)
+
+	var content corev1.Pod
+	require.NoError(t, json.Unmarshal(manifest.Content, &content))
+	assert.Equal(t, redactedValue, content.Spec.Containers[0].Env[0].Value)
+	assert.Equal(t, map[string]string{"team": "storage"}, content.Annotations)
+	assert.NotContains(t, string(manifest.Content), "foo")
+
+	// the resource of the informer cache is left untouched
+	assert.Equal(t, "foo", pod.Spec.Containers[0].Env[0].Value)
+	assert.Len(t, pod.Annotations, 2)
+}
+
+func TestBuildManifestDeployment(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{UID: "456", Name: "redis"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Env: []corev1.EnvVar{{Name: "AUTH_TOKEN", Value: "foo"}},
+					}},
+				},
+			},
+		},
+	}
+	manifest, err := BuildManifest(deployment, NewDataScrubber(defaultSensitiveWords))
+	require.NoError(t, err)
+	assert.Equal(t, model.ManifestType_deployment, manifest.Type)
+
+	var content appsv1.Deployment
+	require.NoError(t, json.Unmarshal(manifest.Content, &content))
+	assert.Equal(t, redactedValue, content.Spec.Template.Spec.Containers[0].Env[0].Value)
+	assert.Equal(t, "foo", deployment.Spec.Template.Spec.Containers[0].Env[0].Value)
+}
+
+func TestBuildManifestUnsupported(t *testing.T) {
+	_, err := BuildManifest(&corev1.Service{}, NewDataScrubber(defaultSensitiveWords))
+	assert.Error(t, err)
+}
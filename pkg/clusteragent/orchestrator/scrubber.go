@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// redactedValue replaces the values of the sensitive environment variables
+const redactedValue = "********"
+
+var defaultSensitiveWords = []string{
+	"password", "passwd", "mysql_pwd",
+	"access_token", "auth_token",
+	"api_key", "apikey",
+	"secret", "credentials", "stripetoken"}
+
+// DataScrubber redacts the values of the environment variables and
+// arguments of the containers whose name contains a sensitive word
+type DataScrubber struct {
+	sensitiveWords []string
+}
+
+// NewDefaultDataScrubber returns a DataScrubber matching the default
+// sensitive words and the ones of orchestrator_explorer.custom_sensitive_words
+func NewDefaultDataScrubber() *DataScrubber {
+	words := append([]string{}, defaultSensitiveWords...)
+	words = append(words, config.Datadog.GetStringSlice("orchestrator_explorer.custom_sensitive_words")...)
+	return NewDataScrubber(words)
+}
+
+// NewDataScrubber returns a DataScrubber matching the given words
+func NewDataScrubber(words []string) *DataScrubber {
+	ds := &DataScrubber{}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			ds.sensitiveWords = append(ds.sensitiveWords, word)
+		}
+	}
+	return ds
+}
+
+// IsSensitive returns true if the name contains a sensitive word, the
+// matching is case insensitive
+func (ds *DataScrubber) IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range ds.sensitiveWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScrubPodSpec redacts the sensitive environment variables and arguments of
+// the containers and init containers of the pod spec, in place
+func (ds *DataScrubber) ScrubPodSpec(spec *corev1.PodSpec) {
+	for i := range spec.InitContainers {
+		ds.ScrubContainer(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		ds.ScrubContainer(&spec.Containers[i])
+	}
+}
+
+// ScrubContainer redacts the sensitive environment variables and arguments
+// of the container, in place. The references to secrets and config maps are
+// kept as they don't hold the values.
+func (ds *DataScrubber) ScrubContainer(container *corev1.Container) {
+	for i, env := range container.Env {
+		if env.Value != "" && ds.IsSensitive(env.Name) {
+			container.Env[i].Value = redactedValue
+		}
+	}
+	ds.ScrubCommand(container.Command)
+	ds.ScrubCommand(container.Args)
+}
+
+// ScrubCommand redacts the values of the sensitive arguments, in place. The
+// values are either joined to the argument, like `--password=foo` or
+// `password:foo`, or passed as the next argument, like `--password foo`.
+// The arguments holding a whole command line, like the ones of `sh -c`, are
+// scrubbed word by word.
+func (ds *DataScrubber) ScrubCommand(cmdline []string) {
+	redactNext := false
+	for i, arg := range cmdline {
+		words := strings.Split(arg, " ")
+		for j, word := range words {
+			words[j], redactNext = ds.scrubWord(word, redactNext)
+		}
+		cmdline[i] = strings.Join(words, " ")
+	}
+}
+
+// scrubWord returns the scrubbed word, and whether the next one holds the
+// value of a sensitive flag
+func (ds *DataScrubber) scrubWord(word string, redact bool) (string, bool) {
+	if word == "" {
+		return word, redact
+	}
+	if redact && !strings.HasPrefix(word, "-") {
+		return redactedValue, false
+	}
+	if i := strings.IndexAny(word, "=:"); i > 0 {
+		if ds.IsSensitive(word[:i]) {
+			return word[:i+1] + redactedValue, false
+		}
+		return word, false
+	}
+	return word, strings.HasPrefix(word, "-") && ds.IsSensitive(word)
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIsSensitive(t *testing.T) {
+	scrubber := NewDataScrubber(append(defaultSensitiveWords, " Custom_Word "))
+
+	for _, name := range []string{"PASSWORD", "db_password", "MYSQL_PWD", "DD_API_KEY", "aws_secret_access_key", "custom_word_value"} {
+		assert.True(t, scrubber.IsSensitive(name), name)
+	}
+	for _, name := range []string{"HOME", "DD_SITE", "PATH", ""} {
+		assert.False(t, scrubber.IsSensitive(name), name)
+	}
+}
+
+func TestScrubPodSpec(t *testing.T) {
+	scrubber := NewDataScrubber(defaultSensitiveWords)
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Env: []corev1.EnvVar{{Name: "INIT_PASSWORD", Value: "foo"}},
+		}},
+		Containers: []corev1.Container{{
+			Command: []string{"redis-server", "--requirepass", "foo"},
+			Args:    []string{"--api_key=bar", "--port", "6379"},
+			Env: []corev1.EnvVar{
+				{Name: "DD_API_KEY", Value: "bar"},
+				{Name: "DD_SITE", Value: "datadoghq.com"},
+				{
+					Name: "DB_PASSWORD",
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{Key: "password"},
+					},
+				},
+			},
+		}},
+	}
+	scrubber.ScrubPodSpec(&spec)
+
+	assert.Equal(t, redactedValue, spec.InitContainers[0].Env[0].Value)
+	assert.Equal(t, redactedValue, spec.Containers[0].Env[0].Value)
+	assert.Equal(t, "datadoghq.com", spec.Containers[0].Env[1].Value)
+	// references to secrets are kept
+	assert.Equal(t, "", spec.Containers[0].Env[2].Value)
+	assert.Equal(t, "password", spec.Containers[0].Env[2].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, []string{"redis-server", "--requirepass", "foo"}, spec.Containers[0].Command)
+	assert.Equal(t, []string{"--api_key=********", "--port", "6379"}, spec.Containers[0].Args)
+}
+
+func TestScrubCommand(t *testing.T) {
+	scrubber := NewDataScrubber(defaultSensitiveWords)
+
+	for _, tc := range []struct {
+		cmdline  []string
+		expected []string
+	}{
+		{
+			cmdline:  []string{"mysqld", "--password=foo", "--port=3306"},
+			expected: []string{"mysqld", "--password=********", "--port=3306"},
+		},
+		{
+			cmdline:  []string{"agent", "-api_key", "foo", "-v"},
+			expected: []string{"agent", "-api_key", "********", "-v"},
+		},
+		{
+			cmdline:  []string{"agent", "--secret", "--verbose"},
+			expected: []string{"agent", "--secret", "--verbose"},
+		},
+		{
+			cmdline:  []string{"sh", "-c", "mysql -u root --password foo && echo password:bar"},
+			expected: []string{"sh", "-c", "mysql -u root --password ******** && echo password:********"},
+		},
+		{
+			cmdline:  []string{"nginx", "-g", "daemon off;", "--conf=/etc/secret/nginx.conf"},
+			expected: []string{"nginx", "-g", "daemon off;", "--conf=/etc/secret/nginx.conf"},
+		},
+	} {
+		scrubber.ScrubCommand(tc.cmdline)
+		assert.Equal(t, tc.expected, tc.cmdline)
+	}
+}
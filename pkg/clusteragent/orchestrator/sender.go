@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	orchestratorURLPrefix = "https://orchestrator."
+	orchestratorPath      = "/api/v1/orchestrator"
+	requestTimeout        = 30 * time.Second
+)
+
+// Sender ships the manifests to the orchestrator intake, in messages of at
+// most maxPerMessage manifests
+type Sender struct {
+	client        *http.Client
+	url           string
+	apiKey        string
+	hostname      string
+	clusterName   string
+	clusterID     string
+	maxPerMessage int
+	groupID       int32
+}
+
+// NewSender returns a new Sender for the manifests of the cluster
+func NewSender(clusterName, clusterID string) *Sender {
+	hostname, _ := util.GetHostname()
+	return &Sender{
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: util.CreateHTTPTransport(),
+		},
+		url:           config.GetMainEndpoint(orchestratorURLPrefix, "orchestrator_explorer.orchestrator_dd_url") + orchestratorPath,
+		apiKey:        config.Datadog.GetString("api_key"),
+		hostname:      hostname,
+		clusterName:   clusterName,
+		clusterID:     clusterID,
+		maxPerMessage: config.Datadog.GetInt("orchestrator_explorer.max_per_message"),
+	}
+}
+
+// Send ships the manifests, the messages of a same call share a group ID.
+// The last error is returned when some messages could not be sent.
+func (s *Sender) Send(manifests []*model.Manifest) error {
+	if len(manifests) == 0 {
+		return nil
+	}
+	chunks := chunkManifests(manifests, s.maxPerMessage)
+	groupID := atomic.AddInt32(&s.groupID, 1)
+
+	var lastErr error
+	for _, chunk := range chunks {
+		msg := &model.CollectorManifest{
+			ClusterName: s.clusterName,
+			ClusterId:   s.clusterID,
+			Manifests:   chunk,
+			GroupId:     groupID,
+			GroupSize:   int32(len(chunks)),
+		}
+		if err := s.post(msg); err != nil {
+			log.Warnf("Could not send %d manifests to the orchestrator intake: %s", len(chunk), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (s *Sender) post(msg *model.CollectorManifest) error {
+	body, err := model.EncodeMessage(model.Message{
+		Header: model.MessageHeader{
+			Version:  model.MessageV3,
+			Encoding: model.MessageEncodingProtobuf,
+			Type:     model.TypeCollectorManifest,
+		},
+		Body: msg,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode message: %s", err)
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("X-Dd-APIKey", s.apiKey)
+	req.Header.Add("X-Dd-Hostname", s.hostname)
+	req.Header.Add("X-Dd-Orchestrator-ClusterID", s.clusterID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error submitting payload to %s: %s", util.SanitizeURL(s.url), err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response from %s: %s", util.SanitizeURL(s.url), resp.Status)
+	}
+	return nil
+}
+
+// chunkManifests splits the manifests in chunks of at most size manifests,
+// in a single chunk when size is not positive
+func chunkManifests(manifests []*model.Manifest, size int) [][]*model.Manifest {
+	if size <= 0 {
+		return [][]*model.Manifest{manifests}
+	}
+	var chunks [][]*model.Manifest
+	for len(manifests) > size {
+		chunks = append(chunks, manifests[:size])
+		manifests = manifests[size:]
+	}
+	return append(chunks, manifests)
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+)
+
+func TestSenderSend(t *testing.T) {
+	var messages []*model.CollectorManifest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "abc", r.Header.Get("X-Dd-APIKey"))
+		assert.Equal(t, "cluster-id", r.Header.Get("X-Dd-Orchestrator-ClusterID"))
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		msg, err := model.DecodeMessage(body)
+		require.NoError(t, err)
+		messages = append(messages, msg.Body.(*model.CollectorManifest))
+	}))
+	defer ts.Close()
+
+	sender := &Sender{
+		client:        ts.Client(),
+		url:           ts.URL + orchestratorPath,
+		apiKey:        "abc",
+		clusterName:   "cluster",
+		clusterID:     "cluster-id",
+		maxPerMessage: 2,
+	}
+	manifests := []*model.Manifest{{Uid: "1"}, {Uid: "2"}, {Uid: "3"}}
+	require.NoError(t, sender.Send(manifests))
+
+	require.Len(t, messages, 2)
+	assert.Len(t, messages[0].Manifests, 2)
+	assert.Len(t, messages[1].Manifests, 1)
+	for _, msg := range messages {
+		assert.Equal(t, "cluster", msg.ClusterName)
+		assert.Equal(t, "cluster-id", msg.ClusterId)
+		assert.Equal(t, int32(1), msg.GroupId)
+		assert.Equal(t, int32(2), msg.GroupSize)
+	}
+
+	// nothing to send
+	require.NoError(t, sender.Send(nil))
+	assert.Len(t, messages, 2)
+}
+
+func TestSenderSendError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	sender := &Sender{client: ts.Client(), url: ts.URL + orchestratorPath}
+	assert.Error(t, sender.Send([]*model.Manifest{{Uid: "1"}}))
+}
+
+func TestChunkManifests(t *testing.T) {
+	manifests := []*model.Manifest{{Uid: "1"}, {Uid: "2"}, {Uid: "3"}}
+	assert.Len(t, chunkManifests(manifests, 0), 1)
+	assert.Len(t, chunkManifests(manifests, 3), 1)
+	assert.Len(t, chunkManifests(manifests, 2), 2)
+	assert.Len(t, chunkManifests(manifests, 1), 3)
+}
@@ -28,6 +28,7 @@ type Stats struct {
 	AverageExecutionTime int64     // average run duration
 	LastExecutionTime    int64     // most recent run duration, provided for convenience
 	LastError            string    // error that occurred in the last run, if any
+	FirstRunError        string    // error that occurred in the first run after scheduling, if any
 	LastWarnings         []string  // warnings that occurred in the last run, if any
 	UpdateTimestamp      int64     // latest update to this instance, unix timestamp in seconds
 	m                    sync.Mutex
@@ -51,6 +52,10 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 	tms := t.Nanoseconds() / 1e6
 	cs.LastExecutionTime = tms
 	cs.ExecutionTimes[cs.TotalRuns%uint64(len(cs.ExecutionTimes))] = tms
+	if cs.TotalRuns == 0 && err != nil {
+		// kept for the check lifetime, a misconfiguration usually fails the first run
+		cs.FirstRunError = err.Error()
+	}
 	cs.TotalRuns++
 	var totalExecutionTime int64
 	ringSize := cs.TotalRuns
@@ -95,3 +100,11 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 		}
 	}
 }
+
+// GetFirstRunError returns the error of the first run, it can be called while
+// the check runs
+func (cs *Stats) GetFirstRunError() string {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+	return cs.FirstRunError
+}
@@ -191,6 +191,51 @@ func (c *Collector) StopCheck(id check.ID) error {
 	return nil
 }
 
+// SetCheckRunners sets the number of check runners, 0 lets the collector
+// update it with the number of checks again
+func (c *Collector) SetCheckRunners(numWorkers int) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.state != started {
+		return fmt.Errorf("the collector is not running")
+	}
+	if numWorkers < 0 {
+		return fmt.Errorf("invalid number of check runners: %d", numWorkers)
+	}
+
+	c.runner.SetNumWorkers(numWorkers)
+	if numWorkers == 0 {
+		c.runner.UpdateNumWorkers(c.checkInstances)
+	}
+	return nil
+}
+
+// ProfileChecks profiles the next run of the checks with the given names
+// or IDs, the profiles are written to `check_profiles_dir`
+func (c *Collector) ProfileChecks(names []string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.state != started {
+		return fmt.Errorf("the collector is not running")
+	}
+	c.runner.ProfileChecks(names)
+	return nil
+}
+
+// ProfiledChecks returns the names and IDs of the checks whose next run is
+// profiled
+func (c *Collector) ProfiledChecks() []string {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	if c.state != started {
+		return []string{}
+	}
+	return c.runner.ProfiledChecks()
+}
+
 // check if the check is on the list
 func (c *Collector) find(id check.ID) bool {
 	c.m.RLock()
@@ -228,6 +273,19 @@ func (c *Collector) getAllInstanceIDs(checkName string) []check.ID {
 	return instances
 }
 
+// GetAllChecks returns the check instances currently scheduled
+func (c *Collector) GetAllChecks() []check.Check {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	checks := make([]check.Check, 0, len(c.checks))
+	for _, check := range c.checks {
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
 // ReloadAllCheckInstances completely restarts a check with a new configuration
 func (c *Collector) ReloadAllCheckInstances(name string, newInstances []check.Check) ([]check.ID, error) {
 	if !c.started() {
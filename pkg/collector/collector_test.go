@@ -181,6 +181,19 @@ func (suite *CollectorTestSuite) TestGetAllInstanceIDs() {
 	}
 }
 
+func (suite *CollectorTestSuite) TestGetAllChecks() {
+	assert.Empty(suite.T(), suite.c.GetAllChecks())
+
+	ch1 := NewCheckUnique("foo", "TestCheck1")
+	ch2 := NewCheckUnique("bar", "TestCheck2")
+	_, err := suite.c.RunCheck(ch1)
+	assert.Nil(suite.T(), err)
+	_, err = suite.c.RunCheck(ch2)
+	assert.Nil(suite.T(), err)
+
+	assert.ElementsMatch(suite.T(), []check.Check{ch1, ch2}, suite.c.GetAllChecks())
+}
+
 func (suite *CollectorTestSuite) TestReloadAllCheckInstances() {
 	// Schedule 2 check instances
 	ch1 := NewCheckUnique("foo", "TestCheck")
@@ -41,6 +41,7 @@ type KubeASConfig struct {
 	CollectOShiftQuotas      bool     `yaml:"collect_openshift_clusterquotas"`
 	FilteredEventType        []string `yaml:"filtered_event_types"`
 	EventCollectionTimeoutMs int      `yaml:"kubernetes_event_read_timeout_ms"`
+	EventsRollupWindow       int      `yaml:"events_rollup_window"`
 }
 
 // KubeASCheck grabs metrics and events from the API server.
@@ -52,6 +53,7 @@ type KubeASCheck struct {
 	configMapAvailable    bool
 	ac                    *apiserver.APIClient
 	oshiftAPILevel        apiserver.OpenShiftAPILevel
+	rollup                *kubernetesEventRollup
 }
 
 func (c *KubeASConfig) parse(data []byte) error {
@@ -59,6 +61,7 @@ func (c *KubeASConfig) parse(data []byte) error {
 	c.CollectEvent = config.Datadog.GetBool("collect_kubernetes_events")
 	c.CollectOShiftQuotas = true
 	c.EventCollectionTimeoutMs = config.Datadog.GetInt("kubernetes_event_collection_timeout")
+	c.EventsRollupWindow = config.Datadog.GetInt("kubernetes_events_rollup_window")
 
 	return yaml.Unmarshal(data, c)
 }
@@ -164,12 +167,16 @@ func (k *KubeASCheck) Run() error {
 		k.Warnf("Could not submit new event %s", err.Error())
 	}
 	// We send the events in 2 steps to make sure the new events are initializing the aggregation keys and as modified events have a different payload.
-	if len(modifiedEvents) == 0 {
-		return nil
+	if len(modifiedEvents) > 0 {
+		err = k.processEvents(sender, modifiedEvents, true)
+		if err != nil {
+			k.Warnf("Could not submit modified event %s", err.Error())
+		}
 	}
-	err = k.processEvents(sender, modifiedEvents, true)
-	if err != nil {
-		k.Warnf("Could not submit modified event %s", err.Error())
+
+	// Summarize the events repeated over several objects.
+	if k.instance.EventsRollupWindow > 0 {
+		k.rollupEvents(sender, time.Now(), newEvents, modifiedEvents)
 	}
 	return nil
 }
@@ -213,6 +220,8 @@ func (k *KubeASCheck) eventCollectionInit() {
 			k.latestEventToken = "0"
 
 		case err == apiserver.ErrNotFound:
+			// the token is stored as soon as events are collected, when the ConfigMap exists
+			k.configMapAvailable = found
 			k.latestEventToken = "0"
 
 		case err == nil:
@@ -345,6 +354,28 @@ ITER_EVENTS:
 	return nil
 }
 
+// rollupEvents aggregates the events by kind, reason and namespace of their
+// involved object and submits the rollups once the window is elapsed.
+func (k *KubeASCheck) rollupEvents(sender aggregator.Sender, now time.Time, eventLists ...[]*v1.Event) {
+	if k.rollup == nil {
+		k.rollup = newKubernetesEventRollup(time.Duration(k.instance.EventsRollupWindow)*time.Second, now)
+	}
+	for _, events := range eventLists {
+	ITER_EVENTS:
+		for _, event := range events {
+			for _, action := range k.instance.FilteredEventType {
+				if event.Reason == action {
+					continue ITER_EVENTS
+				}
+			}
+			k.rollup.addEvent(event)
+		}
+	}
+	for _, rollup := range k.rollup.flush(now) {
+		sender.Event(rollup)
+	}
+}
+
 func init() {
 	core.RegisterCheck(kubernetesAPIServerCheckName, KubernetesASFactory)
 }
@@ -180,6 +180,7 @@ func TestProcessBundledEvents(t *testing.T) {
 	res := (mocked.Calls[0].Arguments.Get(0)).(metrics.Event).Text
 	assert.Contains(t, res, "2 **Scheduled**")
 	assert.Contains(t, res, "3 **Started**")
+	assert.Contains(t, (mocked.Calls[0].Arguments.Get(0)).(metrics.Event).Tags, "pod_name:dca-789976f5d7-2ljx6")
 	mocked.AssertNumberOfCalls(t, "Event", 1)
 	mocked.AssertExpectations(t)
 
@@ -192,7 +193,7 @@ func TestProcessBundledEvents(t *testing.T) {
 		Title:          "Events from the machine-blue Node",
 		Text:           "%%% \n30 **MissingClusterDNS**: MountVolume.SetUp succeeded\n \n _Events emitted by the kubelet seen at " + time.Unix(709675200, 0).String() + "_ \n\n %%%",
 		Priority:       "normal",
-		Tags:           []string{"namespace:default", "source_component:kubelet"},
+		Tags:           []string{"namespace:default", "source_component:kubelet", "kube_kind:Node", "kube_name:localhost", "kube_namespace:default"},
 		AggregationKey: "kubernetes_apiserver:e63e74fa-f566-11e7-9749-0e4863e1cbf4",
 		SourceTypeName: "kubernetes",
 		Ts:             709675200,
@@ -220,7 +221,7 @@ func TestProcessBundledEvents(t *testing.T) {
 		Title:          "Events from the machine-blue Node",
 		Text:           "%%% \n30 **MissingClusterDNS**: MountVolume.SetUp succeeded\n \n _Events emitted by the kubelet seen at " + time.Unix(709675200, 0).String() + "_ \n\n %%%",
 		Priority:       "normal",
-		Tags:           []string{"namespace:default", "source_component:kubelet"},
+		Tags:           []string{"namespace:default", "source_component:kubelet", "kube_kind:Node", "kube_name:localhost", "kube_namespace:default"},
 		AggregationKey: "kubernetes_apiserver:e63e74fa-f566-11e7-9749-0e4863e1cbf4",
 		SourceTypeName: "kubernetes",
 		Ts:             709675200,
@@ -260,7 +261,7 @@ func TestProcessEvent(t *testing.T) {
 		Title:          "Events from the dca-789976f5d7-2ljx6 ReplicaSet",
 		Text:           "%%% \n2 **Scheduled**: Successfully assigned dca-789976f5d7-2ljx6 to ip-10-0-0-54\n \n _New events emitted by the default-scheduler seen at " + time.Unix(709662600000, 0).String() + "_ \n\n %%%",
 		Priority:       "normal",
-		Tags:           []string{"source_component:default-scheduler", "namespace:default"},
+		Tags:           []string{"source_component:default-scheduler", "namespace:default", "kube_kind:ReplicaSet", "kube_name:dca-789976f5d7-2ljx6", "kube_namespace:default"},
 		AggregationKey: "kubernetes_apiserver:e6417a7f-f566-11e7-9749-0e4863e1cbf4",
 		SourceTypeName: "kubernetes",
 		Ts:             709662600,
@@ -289,3 +290,82 @@ func TestProcessEvent(t *testing.T) {
 	mocked.AssertNotCalled(t, "Event")
 	mocked.AssertExpectations(t)
 }
+
+func TestRollupEvents(t *testing.T) {
+	// We want to check that the events are aggregated by kind, reason and namespace over the window,
+	// and that the occurrences of a modified event are only counted once.
+	ev1 := createEvent(2, "default", "web-1", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "kubelet", "machine-blue", "BackOff", "Back-off restarting failed container", 709662600)
+	ev1.UID = "ev1"
+	ev1.Type = v1.EventTypeWarning
+	ev2 := createEvent(1, "default", "web-2", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf5", "kubelet", "machine-green", "BackOff", "Back-off restarting failed container", 709662610)
+	ev2.UID = "ev2"
+	ev3 := createEvent(1, "kube-system", "dns-1", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf6", "kubelet", "machine-green", "BackOff", "Back-off restarting failed container", 709662620)
+	ev3.UID = "ev3"
+	ev4 := createEvent(1, "default", "web-1", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "kubelet", "machine-blue", "ignored", "Filtered", 709662620)
+	ev1Modified := createEvent(5, "default", "web-1", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "kubelet", "machine-blue", "BackOff", "Back-off restarting failed container", 709662630)
+	ev1Modified.UID = "ev1"
+
+	kubeASCheck := &KubeASCheck{
+		instance: &KubeASConfig{
+			FilteredEventType:  []string{"ignored"},
+			EventsRollupWindow: 300,
+		},
+		CheckBase:             core.NewCheckBase(kubernetesAPIServerCheckName),
+		KubeAPIServerHostname: "hostname",
+	}
+	now := time.Unix(709662600, 0)
+
+	// Nothing is submitted before the end of the window
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	kubeASCheck.rollupEvents(mocked, now, []*v1.Event{ev1, ev2, ev3, ev4})
+	kubeASCheck.rollupEvents(mocked, now.Add(time.Minute), nil, []*v1.Event{ev1Modified})
+	mocked.AssertNotCalled(t, "Event")
+
+	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
+	kubeASCheck.rollupEvents(mocked, now.Add(5*time.Minute))
+	mocked.AssertNumberOfCalls(t, "Event", 2)
+	mocked.AssertEvent(t, metrics.Event{
+		Title:          "6 BackOff events on 2 Pod in the default namespace",
+		Text:           "%%% \n**BackOff**: web-1, web-2 \n _Seen between " + time.Unix(709662600, 0).String() + " and " + time.Unix(709662630, 0).String() + "_ \n\n %%%",
+		Priority:       "low",
+		AlertType:      "warning",
+		Tags:           []string{"kube_kind:Pod", "kube_reason:BackOff", "namespace:default", "kube_namespace:default"},
+		AggregationKey: "kubernetes_apiserver:rollup:Pod:BackOff:default",
+		SourceTypeName: "kubernetes",
+		Ts:             709662630,
+		EventType:      "kubernetes_apiserver",
+	}, 0)
+	mocked.AssertEvent(t, metrics.Event{
+		Title:          "1 BackOff events on 1 Pod in the kube-system namespace",
+		Text:           "%%% \n**BackOff**: dns-1 \n _Seen between " + time.Unix(709662620, 0).String() + " and " + time.Unix(709662620, 0).String() + "_ \n\n %%%",
+		Priority:       "low",
+		AlertType:      "info",
+		Tags:           []string{"kube_kind:Pod", "kube_reason:BackOff", "namespace:kube-system", "kube_namespace:kube-system"},
+		AggregationKey: "kubernetes_apiserver:rollup:Pod:BackOff:kube-system",
+		SourceTypeName: "kubernetes",
+		Ts:             709662620,
+		EventType:      "kubernetes_apiserver",
+	}, 0)
+
+	// A new window starts empty
+	mocked = mocksender.NewMockSender(kubeASCheck.ID())
+	kubeASCheck.rollupEvents(mocked, now.Add(10*time.Minute))
+	mocked.AssertNotCalled(t, "Event")
+
+	// The counts are kept across windows, an event resent without a new
+	// occurrence isn't counted
+	kubeASCheck.rollupEvents(mocked, now.Add(11*time.Minute), nil, []*v1.Event{ev1Modified})
+	kubeASCheck.rollupEvents(mocked, now.Add(15*time.Minute))
+	mocked.AssertNotCalled(t, "Event")
+
+	ev1Modified.Count = 7
+	kubeASCheck.rollupEvents(mocked, now.Add(16*time.Minute), nil, []*v1.Event{ev1Modified})
+	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
+	kubeASCheck.rollupEvents(mocked, now.Add(20*time.Minute))
+	mocked.AssertNumberOfCalls(t, "Event", 1)
+	assert.Equal(t, "2 BackOff events on 1 Pod in the default namespace", mocked.Calls[0].Arguments.Get(0).(metrics.Event).Title)
+
+	// The counts of the events not seen for an hour are dropped
+	kubeASCheck.rollupEvents(mocked, now.Add(2*time.Hour))
+	assert.Empty(t, kubeASCheck.rollup.lastCounts)
+}
@@ -21,6 +21,8 @@ import (
 type kubernetesEventBundle struct {
 	objUid        types.UID      // Unique object Identifier used as the Aggregation key
 	namespace     string         // namespace of the bundle
+	kind          string         // kind of the involved object
+	name          string         // name of the involved object
 	readableKey   string         // Formated key used in the Title in the events
 	component     string         // Used to identify the Kubernetes component which generated the event
 	events        []*v1.Event    // List of events in the bundle
@@ -56,6 +58,8 @@ func (k *kubernetesEventBundle) addEvent(event *v1.Event) error {
 	k.lastTimestamp = math.Max(k.timeStamp, float64(event.LastTimestamp.Unix()))
 
 	k.countByAction[fmt.Sprintf("**%s**: %s\n", event.Reason, event.Message)] += int(event.Count)
+	k.kind = event.InvolvedObject.Kind
+	k.name = event.InvolvedObject.Name
 	k.readableKey = fmt.Sprintf("%s %s", event.InvolvedObject.Name, event.InvolvedObject.Kind)
 
 	if event.InvolvedObject.Kind == "Node" || event.InvolvedObject.Kind == "Pod" {
@@ -83,12 +87,9 @@ func (k *kubernetesEventBundle) formatEvents(modified bool, clusterName string)
 		SourceTypeName: "kubernetes",
 		EventType:      kubernetesAPIServerCheckName,
 		Ts:             int64(k.timeStamp),
-		Tags:           []string{fmt.Sprintf("source_component:%s", k.component)},
+		Tags:           k.tags(),
 		AggregationKey: fmt.Sprintf("kubernetes_apiserver:%s", k.objUid),
 	}
-	if k.namespace != "" {
-		output.Tags = append(output.Tags, fmt.Sprintf("namespace:%s", k.namespace))
-	}
 	if modified {
 		output.Text = "%%% \n" + fmt.Sprintf("%s \n _Events emitted by the %s seen at %s_ \n", formatStringIntMap(k.countByAction), k.component, time.Unix(int64(k.lastTimestamp), 0)) + "\n %%%"
 		output.Ts = int64(k.lastTimestamp)
@@ -98,6 +99,22 @@ func (k *kubernetesEventBundle) formatEvents(modified bool, clusterName string)
 	return output, nil
 }
 
+// tags returns the tags of the bundle, identifying its involved object
+func (k *kubernetesEventBundle) tags() []string {
+	tags := []string{
+		fmt.Sprintf("source_component:%s", k.component),
+		fmt.Sprintf("kube_kind:%s", k.kind),
+		fmt.Sprintf("kube_name:%s", k.name),
+	}
+	if k.namespace != "" {
+		tags = append(tags, fmt.Sprintf("namespace:%s", k.namespace), fmt.Sprintf("kube_namespace:%s", k.namespace))
+	}
+	if k.kind == "Pod" {
+		tags = append(tags, fmt.Sprintf("pod_name:%s", k.name))
+	}
+	return tags
+}
+
 func formatStringIntMap(input map[string]int) string {
 	var parts []string
 	for k, v := range input {
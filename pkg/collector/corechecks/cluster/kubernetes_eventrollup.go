@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	// maxRollupObjects is the number of involved objects listed in a rollup.
+	maxRollupObjects = 10
+	// lastCountTTL is how long the count of an event is kept after it was
+	// last seen, the default event TTL of the apiserver.
+	lastCountTTL = time.Hour
+)
+
+// rollupKey identifies the events aggregated in a rollup.
+type rollupKey struct {
+	kind      string
+	reason    string
+	namespace string
+}
+
+// rollupEntry counts the occurrences of the events of a rollup key.
+type rollupEntry struct {
+	count     int
+	objects   map[string]struct{}
+	warning   bool
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// lastCount is the count of an event when it was last seen.
+type lastCount struct {
+	count int32
+	seen  time.Time
+}
+
+// kubernetesEventRollup aggregates the Kubernetes events by kind, reason and
+// namespace of their involved object over a window, to summarize the events
+// repeated over many objects.
+type kubernetesEventRollup struct {
+	window      time.Duration
+	windowStart time.Time
+	entries     map[rollupKey]*rollupEntry
+	// lastCounts is the count of the events seen during the last hour, the
+	// occurrences of a modified event are the difference with its last
+	// count, across windows
+	lastCounts map[types.UID]*lastCount
+}
+
+func newKubernetesEventRollup(window time.Duration, now time.Time) *kubernetesEventRollup {
+	return &kubernetesEventRollup{
+		window:      window,
+		windowStart: now,
+		entries:     make(map[rollupKey]*rollupEntry),
+		lastCounts:  make(map[types.UID]*lastCount),
+	}
+}
+
+// addEvent counts the new occurrences of the event.
+func (r *kubernetesEventRollup) addEvent(event *v1.Event) {
+	if event == nil || event.InvolvedObject.Kind == "" || event.Reason == "" {
+		return
+	}
+	occurrences := int(event.Count)
+	if occurrences <= 0 {
+		// events without count
+		occurrences = 1
+	}
+	if last, found := r.lastCounts[event.UID]; found {
+		occurrences = int(event.Count - last.count)
+	}
+	if event.UID != "" {
+		r.lastCounts[event.UID] = &lastCount{count: event.Count, seen: r.windowStart}
+	}
+	if occurrences <= 0 {
+		// resent without a new occurrence
+		return
+	}
+
+	key := rollupKey{
+		kind:      event.InvolvedObject.Kind,
+		reason:    event.Reason,
+		namespace: event.InvolvedObject.Namespace,
+	}
+	entry, found := r.entries[key]
+	if !found {
+		entry = &rollupEntry{
+			objects:   make(map[string]struct{}),
+			firstSeen: event.LastTimestamp.Time,
+		}
+		r.entries[key] = entry
+	}
+	entry.count += occurrences
+	entry.objects[event.InvolvedObject.Name] = struct{}{}
+	entry.warning = entry.warning || event.Type == v1.EventTypeWarning
+	if event.LastTimestamp.Time.Before(entry.firstSeen) {
+		entry.firstSeen = event.LastTimestamp.Time
+	}
+	if event.LastTimestamp.Time.After(entry.lastSeen) {
+		entry.lastSeen = event.LastTimestamp.Time
+	}
+}
+
+// flush returns the rollups of the window and starts a new one once the
+// window is elapsed, nil otherwise. The counts of the events not seen for
+// lastCountTTL are dropped.
+func (r *kubernetesEventRollup) flush(now time.Time) []metrics.Event {
+	if now.Sub(r.windowStart) < r.window {
+		return nil
+	}
+	var events []metrics.Event
+	for key, entry := range r.entries {
+		events = append(events, entry.format(key))
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].AggregationKey < events[j].AggregationKey })
+
+	r.windowStart = now
+	r.entries = make(map[rollupKey]*rollupEntry)
+	for uid, last := range r.lastCounts {
+		if now.Sub(last.seen) > lastCountTTL {
+			delete(r.lastCounts, uid)
+		}
+	}
+	return events
+}
+
+// format returns the Datadog event summarizing the entry.
+func (e *rollupEntry) format(key rollupKey) metrics.Event {
+	names := make([]string, 0, len(e.objects))
+	for name := range e.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	objects := strings.Join(names, ", ")
+	if len(names) > maxRollupObjects {
+		objects = fmt.Sprintf("%s and %d more", strings.Join(names[:maxRollupObjects], ", "), len(names)-maxRollupObjects)
+	}
+
+	title := fmt.Sprintf("%d %s events on %d %s", e.count, key.reason, len(names), key.kind)
+	tags := []string{
+		fmt.Sprintf("kube_kind:%s", key.kind),
+		fmt.Sprintf("kube_reason:%s", key.reason),
+	}
+	if key.namespace != "" {
+		title = fmt.Sprintf("%s in the %s namespace", title, key.namespace)
+		tags = append(tags, fmt.Sprintf("namespace:%s", key.namespace), fmt.Sprintf("kube_namespace:%s", key.namespace))
+	}
+	alertType := metrics.EventAlertTypeInfo
+	if e.warning {
+		alertType = metrics.EventAlertTypeWarning
+	}
+	return metrics.Event{
+		Title:          title,
+		Text:           "%%% \n" + fmt.Sprintf("**%s**: %s \n _Seen between %s and %s_ \n", key.reason, objects, e.firstSeen, e.lastSeen) + "\n %%%",
+		Priority:       metrics.EventPriorityLow,
+		AlertType:      alertType,
+		SourceTypeName: "kubernetes",
+		EventType:      kubernetesAPIServerCheckName,
+		Ts:             e.lastSeen.Unix(),
+		Tags:           tags,
+		AggregationKey: fmt.Sprintf("kubernetes_apiserver:rollup:%s:%s:%s", key.kind, key.reason, key.namespace),
+	}
+}
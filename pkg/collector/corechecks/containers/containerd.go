@@ -24,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
@@ -48,6 +49,7 @@ type ContainerdCheck struct {
 type ContainerdConfig struct {
 	ContainerdFilters []string `yaml:"filters"`
 	CollectEvents     bool     `yaml:"collect_events"`
+	Namespaces        []string `yaml:"namespaces"`
 }
 
 func init() {
@@ -68,6 +70,9 @@ func (co *ContainerdConfig) Parse(data []byte) error {
 	if err := yaml.Unmarshal(data, co); err != nil {
 		return err
 	}
+	if len(co.Namespaces) == 0 {
+		co.Namespaces = []string{config.Datadog.GetString("containerd_namespace")}
+	}
 	return nil
 }
 
@@ -108,26 +113,43 @@ func (c *ContainerdCheck) Run() error {
 		return errHealth
 	}
 	sender.ServiceCheck("containerd.health", metrics.ServiceCheckOK, "", nil, "")
-	ns := cu.Namespace()
 
 	if c.instance.CollectEvents {
 		if c.sub == nil {
-			c.sub = CreateEventSubscriber("ContainerdCheck", ns, c.instance.ContainerdFilters)
+			c.sub = CreateEventSubscriber("ContainerdCheck", c.instance.Namespaces[0], c.instance.ContainerdFilters)
 		}
 
 		if !c.sub.IsRunning() {
 			// Keep track of the health of the Containerd socket
 			c.sub.CheckEvents(cu)
 		}
-		events := c.sub.Flush(time.Now().Unix())
+		// The subscription isn't scoped to a namespace, only keep the events of the collected namespaces
+		events := filterNamespaces(c.sub.Flush(time.Now().Unix()), c.instance.Namespaces)
 		// Process events
 		computeEvents(events, sender, c.filters)
 	}
 
-	computeMetrics(sender, cu, c.filters)
+	for _, ns := range c.instance.Namespaces {
+		computeImages(sender, cu, c.filters, ns)
+		computeMetrics(sender, cu, c.filters, ns)
+	}
 	return nil
 }
 
+// filterNamespaces returns the events that happened in one of the namespaces
+func filterNamespaces(events []containerdEvent, namespaces []string) []containerdEvent {
+	filtered := make([]containerdEvent, 0, len(events))
+	for _, e := range events {
+		for _, ns := range namespaces {
+			if e.Namespace == ns {
+				filtered = append(filtered, e)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
 // compute events converts Containerd events into Datadog events
 func computeEvents(events []containerdEvent, sender aggregator.Sender, fil *ddContainers.Filter) {
 	for _, e := range events {
@@ -138,7 +160,7 @@ func computeEvents(events []containerdEvent, sender aggregator.Sender, fil *ddCo
 			continue
 		}
 		if split[1] == "images" {
-			if fil.IsExcluded("", e.ID) {
+			if fil.IsExcluded("", e.ID, "") {
 				continue
 			}
 		}
@@ -149,6 +171,9 @@ func computeEvents(events []containerdEvent, sender aggregator.Sender, fil *ddCo
 			AggregationKey: fmt.Sprintf("containerd:%s", e.Topic),
 		}
 		output.Text = e.Message
+		if e.Namespace != "" {
+			output.Tags = append(output.Tags, fmt.Sprintf("namespace:%s", e.Namespace))
+		}
 		if len(e.Extra) > 0 {
 			for k, v := range e.Extra {
 				output.Tags = append(output.Tags, fmt.Sprintf("%s:%s", k, v))
@@ -169,15 +194,33 @@ func computeEvents(events []containerdEvent, sender aggregator.Sender, fil *ddCo
 	}
 }
 
-func computeMetrics(sender aggregator.Sender, cu cutil.ContainerdItf, fil *ddContainers.Filter) {
-	containers, err := cu.Containers()
+// computeImages reports the number of images of a namespace, excluded images are not counted
+func computeImages(sender aggregator.Sender, cu cutil.ContainerdItf, fil *ddContainers.Filter, ns string) {
+	images, err := cu.ListImages(ns)
 	if err != nil {
-		log.Errorf(err.Error())
+		log.Errorf("Could not list the images of the namespace %s: %v", ns, err)
+		return
+	}
+
+	count := 0
+	for _, img := range images {
+		if fil.IsExcluded("", img.Name(), "") {
+			continue
+		}
+		count++
+	}
+	sender.Gauge("containerd.image.count", float64(count), "", []string{fmt.Sprintf("namespace:%s", ns)})
+}
+
+func computeMetrics(sender aggregator.Sender, cu cutil.ContainerdItf, fil *ddContainers.Filter, ns string) {
+	containers, err := cu.Containers(ns)
+	if err != nil {
+		log.Errorf("Could not list the containers of the namespace %s: %v", ns, err)
 		return
 	}
 
 	for _, ctn := range containers {
-		info, err := cu.Info(ctn)
+		info, err := cu.Info(ns, ctn)
 		if err != nil {
 			log.Errorf("Could not retrieve the metadata of the container: %s", ctn.ID()[:12])
 			continue
@@ -197,8 +240,9 @@ func computeMetrics(sender aggregator.Sender, cu cutil.ContainerdItf, fil *ddCon
 			continue
 		}
 		tags = append(tags, taggerTags...)
+		tags = append(tags, fmt.Sprintf("namespace:%s", ns))
 
-		metricTask, errTask := cu.TaskMetrics(ctn)
+		metricTask, errTask := cu.TaskMetrics(ns, ctn)
 		if errTask != nil {
 			log.Tracef("Could not retrieve metrics from task %s: %s", ctn.ID()[:12], errTask.Error())
 			continue
@@ -226,7 +270,7 @@ func computeMetrics(sender aggregator.Sender, cu cutil.ContainerdItf, fil *ddCon
 			computeHugetlb(sender, metrics.Hugetlb, tags)
 		}
 
-		size, err := cu.ImageSize(ctn)
+		size, err := cu.ImageSize(ns, ctn)
 		if err != nil {
 			log.Errorf("Could not retrieve the size of the image of %s: %v", ctn.ID(), err.Error())
 			continue
@@ -236,8 +280,9 @@ func computeMetrics(sender aggregator.Sender, cu cutil.ContainerdItf, fil *ddCon
 }
 
 func isExcluded(ctn containers.Container, fil *ddContainers.Filter) bool {
-	// The container name is not available in Containerd, we only rely on image name based exclusion
-	return fil.IsExcluded("", ctn.Image)
+	// The container name is not available in Containerd, we only rely on image name
+	// and kubernetes namespace (set as a label by the CRI plugin) based exclusion
+	return fil.IsExcluded("", ctn.Image, ctn.Labels[ddContainers.KubeNamespaceLabel])
 }
 
 func convertTasktoMetrics(metricTask *containerdTypes.Metric) (*cgroups.Metrics, error) {
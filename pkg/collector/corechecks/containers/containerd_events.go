@@ -211,7 +211,7 @@ func (s *subscriber) run(ctx context.Context, ev containerd.EventService) error
 				log.Tracef("Unsupported event type from Containerd: %s ", message.Topic)
 			}
 		case e := <-errC:
-			// As we only collect events from one containerd namespace, using this bool is sufficient.
+			// The subscription covers all the namespaces with a single routine, using this bool is sufficient.
 			s.Lock()
 			s.isRunning = false
 			s.Unlock()
@@ -27,24 +27,25 @@ import (
 
 type mockItf struct {
 	mockEvents      func() containerd.EventService
-	mockContainer   func() ([]containerd.Container, error)
+	mockContainer   func(namespace string) ([]containerd.Container, error)
 	mockMetadata    func() (containerd.Version, error)
-	mockImageSize   func(ctn containerd.Container) (int64, error)
-	mockTaskMetrics func(ctn containerd.Container) (*types.Metric, error)
-	mockInfo        func(ctn containerd.Container) (containers.Container, error)
+	mockImageSize   func(namespace string, ctn containerd.Container) (int64, error)
+	mockTaskMetrics func(namespace string, ctn containerd.Container) (*types.Metric, error)
+	mockInfo        func(namespace string, ctn containerd.Container) (containers.Container, error)
 	mockNamespace   func() string
+	mockListImages  func(namespace string) ([]containerd.Image, error)
 }
 
-func (m *mockItf) ImageSize(ctn containerd.Container) (int64, error) {
-	return m.mockImageSize(ctn)
+func (m *mockItf) ImageSize(namespace string, ctn containerd.Container) (int64, error) {
+	return m.mockImageSize(namespace, ctn)
 }
 
-func (m *mockItf) Info(ctn containerd.Container) (containers.Container, error) {
-	return m.mockInfo(ctn)
+func (m *mockItf) Info(namespace string, ctn containerd.Container) (containers.Container, error) {
+	return m.mockInfo(namespace, ctn)
 }
 
-func (m *mockItf) TaskMetrics(ctn containerd.Container) (*types.Metric, error) {
-	return m.mockTaskMetrics(ctn)
+func (m *mockItf) TaskMetrics(namespace string, ctn containerd.Container) (*types.Metric, error) {
+	return m.mockTaskMetrics(namespace, ctn)
 }
 
 func (m *mockItf) Metadata() (containerd.Version, error) {
@@ -55,8 +56,12 @@ func (m *mockItf) Namespace() string {
 	return m.mockNamespace()
 }
 
-func (m *mockItf) Containers() ([]containerd.Container, error) {
-	return m.mockContainer()
+func (m *mockItf) ListImages(namespace string) ([]containerd.Image, error) {
+	return m.mockListImages(namespace)
+}
+
+func (m *mockItf) Containers(namespace string) ([]containerd.Container, error) {
+	return m.mockContainer(namespace)
 }
 
 func (m *mockItf) GetEvents() containerd.EventService {
@@ -9,11 +9,13 @@ package containers
 
 import (
 	"encoding/json"
+	"fmt"
 	"sort"
 	"testing"
 	"time"
 
 	"github.com/containerd/cgroups"
+	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/api/types"
 	"github.com/containerd/containerd/containers"
 	"github.com/containerd/typeurl"
@@ -131,10 +133,11 @@ func TestComputeEvents(t *testing.T) {
 				Extra:     map[string]string{"foo": "baz"},
 				Message:   "Image yyy updated",
 				ID:        "yyy",
+				Namespace: "k8s.io",
 			},
 			},
 			expectedTitle: "Event on images from Containerd",
-			expectedTags:  []string{"foo:baz"},
+			expectedTags:  []string{"namespace:k8s.io", "foo:baz"},
 			numberEvents:  1,
 		},
 		{
@@ -338,3 +341,69 @@ func TestIsExcluded(t *testing.T) {
 	isEc = isExcluded(c, containerdCheck.filters)
 	require.False(t, isEc)
 }
+
+// TestFilterNamespaces checks that only the events of the collected namespaces are kept
+func TestFilterNamespaces(t *testing.T) {
+	events := []containerdEvent{
+		{ID: "a", Namespace: "k8s.io"},
+		{ID: "b", Namespace: "moby"},
+		{ID: "c", Namespace: "default"},
+	}
+
+	filtered := filterNamespaces(events, []string{"k8s.io", "moby"})
+	require.Len(t, filtered, 2)
+	require.Equal(t, "a", filtered[0].ID)
+	require.Equal(t, "b", filtered[1].ID)
+
+	require.Len(t, filterNamespaces(events, []string{"foo"}), 0)
+}
+
+type mockImage struct {
+	containerd.Image
+	name string
+}
+
+func (m *mockImage) Name() string {
+	return m.name
+}
+
+// TestComputeImages checks the count of images per namespace
+func TestComputeImages(t *testing.T) {
+	containerdCheck := &ContainerdCheck{
+		instance:  &ContainerdConfig{},
+		CheckBase: corechecks.NewCheckBase("containerd"),
+	}
+	mocked := mocksender.NewMockSender(containerdCheck.ID())
+	mocked.SetupAcceptAll()
+	var err error
+	containerdCheck.filters, err = containersutil.GetSharedFilter()
+	require.NoError(t, err)
+
+	itf := &mockItf{
+		mockListImages: func(namespace string) ([]containerd.Image, error) {
+			if namespace != "moby" {
+				return nil, fmt.Errorf("unexpected namespace %s", namespace)
+			}
+			return []containerd.Image{
+				&mockImage{name: "redis"},
+				&mockImage{name: "nginx"},
+				// excluded by the shared filter
+				&mockImage{name: "kubernetes/pause"},
+			}, nil
+		},
+	}
+
+	computeImages(mocked, itf, containerdCheck.filters, "moby")
+	mocked.AssertMetric(t, "Gauge", "containerd.image.count", 2, "", []string{"namespace:moby"})
+}
+
+// TestParseNamespaces checks the namespaces default to `containerd_namespace`
+func TestParseNamespaces(t *testing.T) {
+	c := &ContainerdConfig{}
+	require.NoError(t, c.Parse([]byte("collect_events: true")))
+	require.Equal(t, []string{"k8s.io"}, c.Namespaces)
+
+	c = &ContainerdConfig{}
+	require.NoError(t, c.Parse([]byte("namespaces: [k8s.io, moby]")))
+	require.Equal(t, []string{"k8s.io", "moby"}, c.Namespaces)
+}
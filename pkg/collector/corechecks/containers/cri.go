@@ -8,6 +8,8 @@
 package containers
 
 import (
+	"strings"
+
 	yaml "gopkg.in/yaml.v2"
 	pb "k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2"
 
@@ -90,6 +92,13 @@ func (c *CRICheck) Run() error {
 	}
 	c.processContainerStats(sender, util.Runtime, containerStats)
 
+	runtimeContainers, err := util.ListContainers()
+	if err != nil {
+		c.Warnf("Cannot list the containers of the CRI: %s", err)
+	} else {
+		c.processContainerStates(sender, util.Runtime, runtimeContainers)
+	}
+
 	sender.Commit()
 	return nil
 }
@@ -112,3 +121,18 @@ func (c *CRICheck) processContainerStats(sender aggregator.Sender, runtime strin
 		}
 	}
 }
+
+// processContainerStates reports the number of containers in each state
+func (c *CRICheck) processContainerStates(sender aggregator.Sender, runtime string, runtimeContainers []*pb.Container) {
+	counts := make(map[pb.ContainerState]int, len(pb.ContainerState_name))
+	for _, container := range runtimeContainers {
+		counts[container.GetState()]++
+	}
+
+	tags := []string{"runtime:" + runtime}
+	for value, name := range pb.ContainerState_name {
+		// CONTAINER_RUNNING -> cri.containers.running
+		state := strings.ToLower(strings.TrimPrefix(name, "CONTAINER_"))
+		sender.Gauge("cri.containers."+state, float64(counts[pb.ContainerState(value)]), "", tags)
+	}
+}
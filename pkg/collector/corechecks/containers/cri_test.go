@@ -38,3 +38,26 @@ func TestCRIprocessContainerStats(t *testing.T) {
 	mocked.On("Gauge", "cri.disk.inodes", float64(0), "", []string{"runtime:fakeruntime"})
 	criCheck.processContainerStats(mocked, "fakeruntime", stats)
 }
+
+func TestCRIprocessContainerStates(t *testing.T) {
+	criCheck := &CRICheck{
+		CheckBase: core.NewCheckBase(criCheckName),
+		instance:  &CRIConfig{},
+	}
+
+	runtimeContainers := []*pb.Container{
+		{Id: "foo", State: pb.ContainerState_CONTAINER_RUNNING},
+		{Id: "bar", State: pb.ContainerState_CONTAINER_RUNNING},
+		{Id: "baz", State: pb.ContainerState_CONTAINER_EXITED},
+	}
+
+	mocked := mocksender.NewMockSender(criCheck.ID())
+	mocked.SetupAcceptAll()
+	criCheck.processContainerStates(mocked, "fakeruntime", runtimeContainers)
+
+	tags := []string{"runtime:fakeruntime"}
+	mocked.AssertMetric(t, "Gauge", "cri.containers.running", 2, "", tags)
+	mocked.AssertMetric(t, "Gauge", "cri.containers.exited", 1, "", tags)
+	mocked.AssertMetric(t, "Gauge", "cri.containers.created", 0, "", tags)
+	mocked.AssertMetric(t, "Gauge", "cri.containers.unknown", 0, "", tags)
+}
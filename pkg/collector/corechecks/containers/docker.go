@@ -47,6 +47,7 @@ type DockerConfig struct {
 	Tags                     []string           `yaml:"tags"` // Used only by the configuration converter v5 → v6
 	CollectEvent             bool               `yaml:"collect_events"`
 	FilteredEventType        []string           `yaml:"filtered_event_types"`
+	AlertEventTypes          []string           `yaml:"alert_event_types"`
 	CappedMetrics            map[string]float64 `yaml:"capped_metrics"`
 }
 
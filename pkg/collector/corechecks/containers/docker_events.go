@@ -73,9 +73,21 @@ func (d *DockerCheck) reportExitCodes(events []*docker.ContainerEvent, sender ag
 	return nil
 }
 
-// reportEvents aggregates and sends events to the Datadog event feed
+// reportEvents aggregates and sends events to the Datadog event feed, the
+// events of the alert types are sent one by one instead.
 func (d *DockerCheck) reportEvents(events []*docker.ContainerEvent, sender aggregator.Sender) error {
-	bundles := aggregateEvents(events, d.instance.FilteredEventType)
+	var toBundle []*docker.ContainerEvent
+	for _, event := range events {
+		if matchFilter(event.Action, d.instance.AlertEventTypes) && !matchFilter(event.Action, d.instance.FilteredEventType) {
+			if alert, isAlert := toAlertEvent(event, d.dockerHostname); isAlert {
+				sender.Event(alert)
+				continue
+			}
+		}
+		toBundle = append(toBundle, event)
+	}
+
+	bundles := aggregateEvents(toBundle, d.instance.FilteredEventType)
 
 	for _, bundle := range bundles {
 		ev, err := bundle.toDatadogEvent(d.dockerHostname)
@@ -88,6 +100,54 @@ func (d *DockerCheck) reportEvents(events []*docker.ContainerEvent, sender aggre
 	return nil
 }
 
+// toAlertEvent converts the events of a container being OOM killed, exiting
+// with a non-zero exit code or changing its health status to a Datadog event.
+// It returns false for the other events, like a container exiting with 0.
+func toAlertEvent(event *docker.ContainerEvent, hostname string) (metrics.Event, bool) {
+	output := metrics.Event{
+		Priority:       metrics.EventPriorityNormal,
+		Host:           hostname,
+		SourceTypeName: dockerCheckName,
+		EventType:      dockerCheckName,
+		Ts:             event.Timestamp.Unix(),
+		AggregationKey: fmt.Sprintf("docker:%s", event.ImageName),
+	}
+
+	switch event.Action {
+	case "oom":
+		output.Title = fmt.Sprintf("Container %s was killed by the OOM killer on %s", event.ContainerName, hostname)
+		output.AlertType = metrics.EventAlertTypeError
+	case "die":
+		exitCode, err := strconv.ParseInt(event.Attributes["exitCode"], 10, 32)
+		if err != nil || exitCode == 0 {
+			return output, false
+		}
+		output.Title = fmt.Sprintf("Container %s exited with %d on %s", event.ContainerName, exitCode, hostname)
+		output.AlertType = metrics.EventAlertTypeError
+	case "health_status":
+		status := event.Attributes[docker.HealthStatusAttribute]
+		switch status {
+		case "unhealthy":
+			output.AlertType = metrics.EventAlertTypeWarning
+		case "healthy":
+			output.AlertType = metrics.EventAlertTypeSuccess
+		default:
+			return output, false
+		}
+		output.Title = fmt.Sprintf("Container %s is %s on %s", event.ContainerName, status, hostname)
+	default:
+		return output, false
+	}
+	output.Text = fmt.Sprintf("%%%%%% \nImage: %s\n %%%%%%", event.ImageName)
+
+	tags, err := tagger.Tag(event.ContainerEntityName(), collectors.HighCardinality)
+	if err != nil {
+		log.Debugf("no tags for %s: %s", event.ContainerID, err)
+	}
+	output.Tags = append([]string{fmt.Sprintf("event_type:%s", event.Action)}, tags...)
+	return output, true
+}
+
 // aggregateEvents converts a bunch of ContainerEvent to bundles aggregated by
 // image name. It also filters out unwanted event types.
 func aggregateEvents(events []*docker.ContainerEvent, filteredActions []string) map[string]*dockerEventBundle {
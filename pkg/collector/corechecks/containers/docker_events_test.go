@@ -8,6 +8,7 @@
 package containers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -195,3 +196,94 @@ func TestAggregateEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestReportAlertEvents(t *testing.T) {
+	dockerCheck := &DockerCheck{
+		instance: &DockerConfig{
+			AlertEventTypes:   []string{"oom", "die", "health_status"},
+			FilteredEventType: []string{"top"},
+		},
+		dockerHostname: "testhost",
+	}
+	mockSender := mocksender.NewMockSender(dockerCheck.ID())
+	mockSender.SetupAcceptAll()
+
+	events := []*docker.ContainerEvent{
+		{
+			Action:        "oom",
+			ContainerName: "hungry",
+			ImageName:     "test_image",
+		},
+		{
+			Action:        "die",
+			Attributes:    map[string]string{"exitCode": "137"},
+			ContainerName: "hungry",
+			ImageName:     "test_image",
+		},
+		{
+			Action:        "die",
+			Attributes:    map[string]string{"exitCode": "0"},
+			ContainerName: "goodOne",
+			ImageName:     "test_image",
+		},
+		{
+			Action:        "health_status",
+			Attributes:    map[string]string{"health_status": "unhealthy"},
+			ContainerName: "sick",
+			ImageName:     "test_image",
+		},
+		{
+			Action:        "start",
+			ContainerName: "goodOne",
+			ImageName:     "test_image",
+		},
+	}
+	err := dockerCheck.reportEvents(events, mockSender)
+	assert.Nil(t, err)
+
+	// 3 alerts and a bundle for the clean exit and the start
+	mockSender.AssertNumberOfCalls(t, "Event", 4)
+	sent := map[string]metrics.Event{}
+	for _, call := range mockSender.Calls {
+		if call.Method == "Event" {
+			ev := call.Arguments.Get(0).(metrics.Event)
+			sent[ev.Title] = ev
+		}
+	}
+
+	oom := sent["Container hungry was killed by the OOM killer on testhost"]
+	assert.Equal(t, metrics.EventAlertTypeError, oom.AlertType)
+	assert.Contains(t, oom.Tags, "event_type:oom")
+	assert.Equal(t, metrics.EventAlertTypeError, sent["Container hungry exited with 137 on testhost"].AlertType)
+	assert.Equal(t, metrics.EventAlertTypeWarning, sent["Container sick is unhealthy on testhost"].AlertType)
+	bundled := 0
+	for title, ev := range sent {
+		if strings.HasPrefix(title, "test_image ") {
+			bundled++
+			assert.Contains(t, ev.Text, "DIE\tgoodOne")
+			assert.Contains(t, ev.Text, "START\tgoodOne")
+		}
+	}
+	assert.Equal(t, 1, bundled)
+}
+
+func TestToAlertEvent(t *testing.T) {
+	for _, ev := range []*docker.ContainerEvent{
+		{Action: "start"},
+		{Action: "die", Attributes: map[string]string{"exitCode": "0"}},
+		{Action: "die", Attributes: map[string]string{}},
+		{Action: "health_status", Attributes: map[string]string{"health_status": "starting"}},
+	} {
+		_, isAlert := toAlertEvent(ev, "testhost")
+		assert.False(t, isAlert, ev.Action)
+	}
+
+	alert, isAlert := toAlertEvent(&docker.ContainerEvent{
+		Action:        "health_status",
+		Attributes:    map[string]string{"health_status": "healthy"},
+		ContainerName: "cured",
+	}, "testhost")
+	assert.True(t, isAlert)
+	assert.Equal(t, metrics.EventAlertTypeSuccess, alert.AlertType)
+	assert.Equal(t, "Container cured is healthy on testhost", alert.Title)
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubelet
+
+package containers
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeletSummaryCheckName = "kubelet_summary"
+)
+
+// For testing purpose
+var (
+	getStatsSummary = func() (*kubelet.StatsSummary, error) {
+		ku, err := kubelet.GetKubeUtil()
+		if err != nil {
+			return nil, err
+		}
+		return ku.GetStatsSummary()
+	}
+	entityTags = tagger.Tag
+)
+
+// KubeletSummaryCheck reports the network and ephemeral storage usage of
+// the pods, read from the kubelet /stats/summary endpoint. The other pod and
+// container metrics are reported by the kubelet integration.
+type KubeletSummaryCheck struct {
+	core.CheckBase
+}
+
+func init() {
+	core.RegisterCheck(kubeletSummaryCheckName, KubeletSummaryFactory)
+}
+
+// KubeletSummaryFactory is exported for integration testing
+func KubeletSummaryFactory() check.Check {
+	return &KubeletSummaryCheck{
+		CheckBase: core.NewCheckBase(kubeletSummaryCheckName),
+	}
+}
+
+// Configure parses the check configuration and init the check
+func (c *KubeletSummaryCheck) Configure(config, initConfig integration.Data) error {
+	return c.CommonConfigure(config)
+}
+
+// Run executes the check
+func (c *KubeletSummaryCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	summary, err := getStatsSummary()
+	if err != nil {
+		c.Warnf("Cannot get the stats summary from the kubelet: %s", err)
+		return err
+	}
+
+	for _, pod := range summary.Pods {
+		tags := c.podTags(pod.PodRef)
+		if pod.Network != nil {
+			submitPodNetworkMetrics(sender, pod.Network, tags)
+		}
+		if pod.EphemeralStorage != nil && pod.EphemeralStorage.UsedBytes != nil {
+			sender.Gauge("kubernetes.ephemeral_storage.usage", float64(*pod.EphemeralStorage.UsedBytes), "", tags)
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// podTags returns the tags of a pod from the tagger, the pods it doesn't know
+// yet are tagged with their name and namespace
+func (c *KubeletSummaryCheck) podTags(ref kubelet.PodReference) []string {
+	tags, err := entityTags(kubelet.PodUIDToEntityName(ref.UID), collectors.OrchestratorCardinality)
+	if err != nil {
+		log.Debugf("Could not collect tags for pod %s/%s: %s", ref.Namespace, ref.Name, err)
+	}
+	if len(tags) == 0 {
+		tags = []string{"pod_name:" + ref.Name, "kube_namespace:" + ref.Namespace}
+	}
+	return tags
+}
+
+// submitPodNetworkMetrics submits the counters of every interface of a pod,
+// or the ones of its default interface when the kubelet doesn't list them
+func submitPodNetworkMetrics(sender aggregator.Sender, network *kubelet.NetworkStats, tags []string) {
+	interfaces := network.Interfaces
+	if len(interfaces) == 0 {
+		interfaces = []kubelet.InterfaceStats{network.InterfaceStats}
+	}
+
+	for _, iface := range interfaces {
+		ifaceTags := append(append([]string{}, tags...), "interface:"+iface.Name)
+		rate := func(name string, value *uint64) {
+			if value != nil {
+				sender.Rate(name, float64(*value), "", ifaceTags)
+			}
+		}
+		rate("kubernetes.pod.network.rx_bytes", iface.RxBytes)
+		rate("kubernetes.pod.network.tx_bytes", iface.TxBytes)
+		rate("kubernetes.pod.network.rx_errors", iface.RxErrors)
+		rate("kubernetes.pod.network.tx_errors", iface.TxErrors)
+	}
+}
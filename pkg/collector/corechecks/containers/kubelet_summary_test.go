@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubelet
+
+package containers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+func uint64Ptr(v uint64) *uint64 { return &v }
+
+func TestKubeletSummaryCheck(t *testing.T) {
+	summary := &kubelet.StatsSummary{
+		Pods: []kubelet.PodStats{
+			{
+				PodRef: kubelet.PodReference{Name: "redis-75586d7d7c-mcczh", Namespace: "default", UID: "ee5cd9c3"},
+				Network: &kubelet.NetworkStats{
+					InterfaceStats: kubelet.InterfaceStats{Name: "eth0", RxBytes: uint64Ptr(60224), TxBytes: uint64Ptr(41072)},
+					Interfaces: []kubelet.InterfaceStats{
+						{Name: "eth0", RxBytes: uint64Ptr(60224), RxErrors: uint64Ptr(0), TxBytes: uint64Ptr(41072), TxErrors: uint64Ptr(1)},
+						{Name: "tunl0", RxBytes: uint64Ptr(10), TxBytes: uint64Ptr(20)},
+					},
+				},
+				EphemeralStorage: &kubelet.FsStats{UsedBytes: uint64Ptr(40960)},
+			},
+			{
+				PodRef: kubelet.PodReference{Name: "nginx", Namespace: "web", UID: "2d4eb0c0"},
+				Network: &kubelet.NetworkStats{
+					InterfaceStats: kubelet.InterfaceStats{Name: "eth0", RxBytes: uint64Ptr(100), TxBytes: uint64Ptr(200)},
+				},
+			},
+			{
+				// the kubelet doesn't report the usage of the host network pods
+				PodRef: kubelet.PodReference{Name: "static-pod", Namespace: "kube-system", UID: "9f2c1a7e"},
+			},
+		},
+	}
+	getStatsSummary = func() (*kubelet.StatsSummary, error) { return summary, nil }
+	entityTags = func(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+		assert.Equal(t, collectors.OrchestratorCardinality, cardinality)
+		if entity == kubelet.PodUIDToEntityName("ee5cd9c3") {
+			return []string{"pod_name:redis-75586d7d7c-mcczh", "kube_namespace:default", "kube_deployment:redis"}, nil
+		}
+		return nil, fmt.Errorf("unknown entity %s", entity)
+	}
+
+	check := KubeletSummaryFactory().(*KubeletSummaryCheck)
+	require.NoError(t, check.Configure(nil, nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	redisTags := []string{"pod_name:redis-75586d7d7c-mcczh", "kube_namespace:default", "kube_deployment:redis"}
+	eth0Tags := append(append([]string{}, redisTags...), "interface:eth0")
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.rx_bytes", 60224, "", eth0Tags)
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.tx_bytes", 41072, "", eth0Tags)
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.rx_errors", 0, "", eth0Tags)
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.tx_errors", 1, "", eth0Tags)
+	tunl0Tags := append(append([]string{}, redisTags...), "interface:tunl0")
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.rx_bytes", 10, "", tunl0Tags)
+	sender.AssertNotCalled(t, "Rate", "kubernetes.pod.network.rx_errors", mock.Anything, mock.Anything, tunl0Tags)
+	sender.AssertMetric(t, "Gauge", "kubernetes.ephemeral_storage.usage", 40960, "", redisTags)
+
+	// the pods unknown to the tagger are tagged with their name and namespace
+	nginxTags := []string{"pod_name:nginx", "kube_namespace:web", "interface:eth0"}
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.rx_bytes", 100, "", nginxTags)
+	sender.AssertMetric(t, "Rate", "kubernetes.pod.network.tx_bytes", 200, "", nginxTags)
+
+	sender.AssertNumberOfCalls(t, "Gauge", 1)
+	sender.AssertNumberOfCalls(t, "Rate", 8)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
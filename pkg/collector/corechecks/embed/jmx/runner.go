@@ -51,12 +51,7 @@ func (r *runner) initRunner() {
 
 func (r *runner) startRunner() error {
 
-	lifecycleMgmt := true
-	if runtime.GOOS == "windows" {
-		lifecycleMgmt = false
-	}
-
-	err := r.jmxfetch.Start(lifecycleMgmt)
+	err := r.jmxfetch.Start(true)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,283 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package goexpvar
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+)
+
+const (
+	goExpvarCheckName = "go_expvar"
+
+	defaultNamespace          = "go_expvar"
+	defaultExpvarPath         = "/debug/vars"
+	defaultMaxReturnedMetrics = 350
+	defaultTimeout            = 10
+
+	gaugeType            = "gauge"
+	rateType             = "rate"
+	countType            = "count"
+	monotonicCounterType = "monotonic_counter"
+	// deprecated, kept for the configurations of the Python check
+	counterType = "counter"
+)
+
+// The memstats of the Go runtime reported without configuration,
+// see https://golang.org/pkg/runtime/#MemStats
+var (
+	defaultGaugeMemstats = []string{"Alloc", "TotalAlloc", "HeapAlloc", "HeapSys", "HeapIdle", "HeapInuse", "HeapReleased", "HeapObjects"}
+	defaultRateMemstats  = []string{"Lookups", "Mallocs", "Frees", "PauseTotalNs", "NumGC"}
+)
+
+type expvarMetricConfig struct {
+	// Path is the path of the value in the expvar payload, its keys are
+	// separated by slashes and are regular expressions matching whole keys
+	Path  string   `yaml:"path"`
+	Alias string   `yaml:"alias"`
+	Type  string   `yaml:"type"`
+	Tags  []string `yaml:"tags"`
+}
+
+type expvarInstanceConfig struct {
+	ExpvarURL          string               `yaml:"expvar_url"`
+	Namespace          string               `yaml:"namespace"`
+	Metrics            []expvarMetricConfig `yaml:"metrics"`
+	MaxReturnedMetrics int                  `yaml:"max_returned_metrics"`
+	Timeout            int                  `yaml:"timeout"`
+}
+
+// ExpvarCheck reports the values published by a Go program with the expvar
+// package. It replaces the go_expvar Python check.
+type ExpvarCheck struct {
+	core.CheckBase
+	url                string
+	namespace          string
+	metrics            []expvarMetricConfig
+	maxReturnedMetrics int
+	client             *http.Client
+	// lastNumGC is the number of GC cycles at the previous run, to report
+	// the pauses of the cycles since then
+	lastNumGC float64
+}
+
+// Run executes the check
+func (c *ExpvarCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	data, err := c.getData()
+	if err != nil {
+		return err
+	}
+
+	tags := []string{fmt.Sprintf("expvar_url:%s", c.url)}
+	c.submitGCPauses(sender, data, tags)
+	c.submitMetrics(sender, data, tags)
+
+	sender.Commit()
+	return nil
+}
+
+func (c *ExpvarCheck) getData() (interface{}, error) {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, c.url)
+	}
+
+	var data interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("could not decode the expvar payload of %s: %s", c.url, err)
+	}
+	return data, nil
+}
+
+// submitGCPauses reports the pauses of the GC cycles since the previous run.
+// memstats.PauseNs is a circular buffer of the pauses of the last 256 cycles.
+func (c *ExpvarCheck) submitGCPauses(sender aggregator.Sender, data interface{}, tags []string) {
+	root, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	memstats, ok := root["memstats"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	numGC, ok := memstats["NumGC"].(float64)
+	if !ok || numGC == c.lastNumGC {
+		return
+	}
+	pauses, ok := memstats["PauseNs"].([]interface{})
+	if !ok || len(pauses) != 256 {
+		return
+	}
+
+	start := int(c.lastNumGC) % 256
+	end := (int(numGC)+255)%256 + 1
+	var values []interface{}
+	if start < end {
+		values = pauses[start:end]
+	} else {
+		values = append(append(values, pauses[start:]...), pauses[:end]...)
+	}
+	c.lastNumGC = numGC
+
+	name := normalize("memstats.PauseNs", c.namespace)
+	for _, v := range values {
+		if pause, ok := v.(float64); ok {
+			sender.Histogram(name, pause, "", tags)
+		}
+	}
+}
+
+func (c *ExpvarCheck) submitMetrics(sender aggregator.Sender, data interface{}, tags []string) {
+	count := 0
+	for _, metric := range c.metrics {
+		values := deepGet(data, splitPath(metric.Path), nil)
+		if len(values) == 0 {
+			c.Warnf("No results matching path %s", metric.Path)
+			continue
+		}
+
+		metricTags := append(append([]string{}, metric.Tags...), tags...)
+		for _, v := range values {
+			value, ok := toFloat(v.value)
+			if !ok {
+				c.Warnf("Unreportable value for path %s: %v", metric.Path, v.value)
+				continue
+			}
+
+			if count >= c.maxReturnedMetrics {
+				c.Warnf("Reporting more metrics than the allowed maximum %d, set max_returned_metrics to report more", c.maxReturnedMetrics)
+				return
+			}
+
+			name := metric.Alias
+			valueTags := metricTags
+			if name == "" {
+				name = normalize(joinPath(v.path), c.namespace)
+			} else {
+				// the values of a path with wildcards are told apart by their path
+				valueTags = append(append([]string{}, metricTags...), fmt.Sprintf("path:%s", joinPath(v.path)))
+			}
+
+			submit(sender, metric.Type, name, value, valueTags)
+			count++
+		}
+	}
+}
+
+func submit(sender aggregator.Sender, metricType, name string, value float64, tags []string) {
+	switch metricType {
+	case rateType:
+		sender.Rate(name, value, "", tags)
+	case countType:
+		sender.Count(name, value, "", tags)
+	case monotonicCounterType:
+		sender.MonotonicCount(name, value, "", tags)
+	case counterType:
+		sender.Counter(name, value, "", tags)
+	default:
+		sender.Gauge(name, value, "", tags)
+	}
+}
+
+// Configure configures the go_expvar check
+func (c *ExpvarCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	conf := expvarInstanceConfig{
+		Namespace:          defaultNamespace,
+		MaxReturnedMetrics: defaultMaxReturnedMetrics,
+		Timeout:            defaultTimeout,
+	}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+	if conf.ExpvarURL == "" {
+		return fmt.Errorf("missing expvar_url")
+	}
+	u, err := url.Parse(conf.ExpvarURL)
+	if err != nil {
+		return fmt.Errorf("invalid expvar_url %s: %s", conf.ExpvarURL, err)
+	}
+	if u.Path == "" {
+		u.Path = defaultExpvarPath
+	}
+
+	c.url = u.String()
+	c.namespace = conf.Namespace
+	c.maxReturnedMetrics = conf.MaxReturnedMetrics
+	c.client = &http.Client{Timeout: time.Duration(conf.Timeout) * time.Second}
+	c.metrics = defaultMetrics()
+	for _, metric := range conf.Metrics {
+		if err := validateMetric(&metric); err != nil {
+			c.Warnf("Skipping the metric %s: %s", metric.Path, err)
+			continue
+		}
+		c.metrics = append(c.metrics, metric)
+	}
+	return nil
+}
+
+func validateMetric(metric *expvarMetricConfig) error {
+	if metric.Path == "" {
+		return fmt.Errorf("no path")
+	}
+	for _, key := range splitPath(metric.Path) {
+		if _, err := compileKey(key); err != nil {
+			return err
+		}
+	}
+	switch metric.Type {
+	case "":
+		metric.Type = gaugeType
+	case gaugeType, rateType, countType, monotonicCounterType, counterType:
+	default:
+		return fmt.Errorf("unsupported metric type %s", metric.Type)
+	}
+	return nil
+}
+
+func defaultMetrics() []expvarMetricConfig {
+	metrics := make([]expvarMetricConfig, 0, len(defaultGaugeMemstats)+len(defaultRateMemstats))
+	for _, name := range defaultGaugeMemstats {
+		metrics = append(metrics, expvarMetricConfig{Path: "memstats/" + name, Type: gaugeType})
+	}
+	for _, name := range defaultRateMemstats {
+		metrics = append(metrics, expvarMetricConfig{Path: "memstats/" + name, Type: rateType})
+	}
+	return metrics
+}
+
+func goExpvarFactory() check.Check {
+	return &ExpvarCheck{
+		CheckBase: core.NewCheckBase(goExpvarCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(goExpvarCheckName, goExpvarFactory)
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package goexpvar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func newExpvarServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/debug/vars" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeFile(w, r, "testdata/expvar.json")
+	}))
+}
+
+func TestExpvarCheck(t *testing.T) {
+	server := newExpvarServer()
+	defer server.Close()
+
+	check := goExpvarFactory().(*ExpvarCheck)
+	require.NoError(t, check.Configure(integration.Data(`
+expvar_url: `+server.URL+`
+namespace: myapp
+metrics:
+  - path: memstats/BySize/1/Mallocs
+    type: monotonic_counter
+  - path: routes/get_.*/count
+    alias: myapp.routes.count
+    tags: ["method:get"]
+  - path: healthy
+  - path: version
+  - path: missing
+`), nil))
+
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	urlTag := "expvar_url:" + server.URL + "/debug/vars"
+	tags := []string{urlTag}
+	mock.AssertMetric(t, "Gauge", "myapp.memstats.alloc", 1024, "", tags)
+	mock.AssertMetric(t, "Gauge", "myapp.memstats.heap_objects", 100, "", tags)
+	mock.AssertMetric(t, "Rate", "myapp.memstats.pause_total_ns", 55000, "", tags)
+	mock.AssertMetric(t, "Rate", "myapp.memstats.num_gc", 10, "", tags)
+	mock.AssertMetric(t, "MonotonicCount", "myapp.memstats.by_size.1.mallocs", 12, "", tags)
+	mock.AssertMetric(t, "Gauge", "myapp.routes.count", 4, "", []string{"method:get", urlTag, "path:routes.get_users.count"})
+	mock.AssertMetric(t, "Gauge", "myapp.routes.count", 2, "", []string{"method:get", urlTag, "path:routes.get_groups.count"})
+	mock.AssertMetric(t, "Gauge", "myapp.healthy", 1, "", tags)
+	mock.AssertNumberOfCalls(t, "Gauge", 8+2+1)
+	mock.AssertNumberOfCalls(t, "Rate", 5)
+
+	// the pauses of the 10 GC cycles
+	mock.AssertMetric(t, "Histogram", "myapp.memstats.pause_ns", 1000, "", tags)
+	mock.AssertMetric(t, "Histogram", "myapp.memstats.pause_ns", 10000, "", tags)
+	mock.AssertNumberOfCalls(t, "Histogram", 10)
+	// the version isn't a number and the path is missing
+	assert.Len(t, check.GetWarnings(), 2)
+
+	// no GC cycle since the previous run
+	mock.ResetCalls()
+	require.NoError(t, check.Run())
+	mock.AssertNotCalled(t, "Histogram", "myapp.memstats.pause_ns", 1000.0, "", tags)
+}
+
+func TestExpvarCheckMaxReturnedMetrics(t *testing.T) {
+	server := newExpvarServer()
+	defer server.Close()
+
+	check := goExpvarFactory().(*ExpvarCheck)
+	require.NoError(t, check.Configure(integration.Data("expvar_url: "+server.URL+"\nmax_returned_metrics: 3"), nil))
+
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	mock.AssertNumberOfCalls(t, "Gauge", 3)
+	mock.AssertNumberOfCalls(t, "Rate", 0)
+	assert.Len(t, check.GetWarnings(), 1)
+}
+
+func TestExpvarCheckConfigure(t *testing.T) {
+	check := goExpvarFactory().(*ExpvarCheck)
+	assert.Error(t, check.Configure(integration.Data("namespace: myapp"), nil))
+
+	require.NoError(t, check.Configure(integration.Data(`
+expvar_url: http://localhost:8080
+metrics:
+  - type: gauge
+  - path: foo
+    type: histogram
+  - path: foo/[a-
+  - path: foo/bar
+`), nil))
+	assert.Equal(t, "http://localhost:8080/debug/vars", check.url)
+	assert.Equal(t, "go_expvar", check.namespace)
+	assert.Len(t, check.metrics, len(defaultMetrics())+1)
+	assert.Equal(t, "gauge", check.metrics[len(check.metrics)-1].Type)
+	assert.Len(t, check.GetWarnings(), 3)
+
+	require.NoError(t, check.Configure(integration.Data("expvar_url: http://localhost:8080/custom/vars"), nil))
+	assert.Equal(t, "http://localhost:8080/custom/vars", check.url)
+}
+
+func TestGCPausesWrapAround(t *testing.T) {
+	pauses := make([]interface{}, 256)
+	for i := range pauses {
+		pauses[i] = float64(i)
+	}
+	data := map[string]interface{}{
+		"memstats": map[string]interface{}{"NumGC": float64(258), "PauseNs": pauses},
+	}
+
+	check := &ExpvarCheck{namespace: "go_expvar", lastNumGC: 254}
+	mock := mocksender.NewMockSender("")
+	mock.SetupAcceptAll()
+	check.submitGCPauses(mock, data, nil)
+
+	// the cycles 255 to 258 are at the indexes 254, 255, 0 and 1
+	for _, v := range []float64{254, 255, 0, 1} {
+		mock.AssertMetric(t, "Histogram", "go_expvar.memstats.pause_ns", v, "", nil)
+	}
+	mock.AssertNumberOfCalls(t, "Histogram", 4)
+}
+
+func TestDeepGet(t *testing.T) {
+	data := map[string]interface{}{
+		"key1": map[string]interface{}{
+			"key2": []interface{}{
+				map[string]interface{}{"name": "object1", "value": 42.0},
+				map[string]interface{}{"name": "object2", "value": 72.0},
+			},
+		},
+	}
+
+	assert.Equal(t, []pathValue{
+		{path: []string{"key1", "key2", "1", "value"}, value: 72.0},
+	}, deepGet(data, splitPath("key1/key2/1/value"), nil))
+	assert.Equal(t, []pathValue{
+		{path: []string{"key1", "key2", "1", "name"}, value: "object2"},
+		{path: []string{"key1", "key2", "1", "value"}, value: 72.0},
+	}, deepGet(data, splitPath("key1/key2/1/.*"), nil))
+	assert.Equal(t, []pathValue{
+		{path: []string{"key1", "key2", "0", "value"}, value: 42.0},
+		{path: []string{"key1", "key2", "1", "value"}, value: 72.0},
+	}, deepGet(data, splitPath("key1/key2/.*/value"), nil))
+	assert.Empty(t, deepGet(data, splitPath("key1/key"), nil))
+	assert.Empty(t, deepGet(data, splitPath("key1/key2/0/value/deeper"), nil))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "go_expvar.memstats.pause_total_ns", normalize("memstats.PauseTotalNs", "go_expvar"))
+	assert.Equal(t, "go_expvar.memstats.by_size.1.mallocs", normalize("memstats.BySize.1.Mallocs", "go_expvar"))
+	assert.Equal(t, "datadog.agent.forwarder.transactions.http_errors", normalize("forwarder.Transactions.HTTPErrors", "datadog.agent"))
+	assert.Equal(t, "datadog.agent.dogstatsd_udp.packets", normalize("dogstatsd-udp.Packets", "datadog.agent"))
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package goexpvar
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The metric names are normalized like the Python checks do it
+var (
+	firstCapRe        = regexp.MustCompile(`(.)([A-Z][a-z]+)`)
+	allCapRe          = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	invalidCharsRe    = regexp.MustCompile(`([^a-zA-Z0-9_.]+)|(^[^a-zA-Z]+)`)
+	dotUnderscoreRe   = regexp.MustCompile(`_*\._*`)
+	multiUnderscoreRe = regexp.MustCompile(`__+`)
+)
+
+// pathValue is a value of the expvar payload and the keys leading to it
+type pathValue struct {
+	path  []string
+	value interface{}
+}
+
+func splitPath(path string) []string {
+	return strings.Split(path, "/")
+}
+
+func joinPath(keys []string) string {
+	return strings.Join(keys, ".")
+}
+
+func compileKey(key string) (*regexp.Regexp, error) {
+	return regexp.Compile("^" + key + "$")
+}
+
+// deepGet returns the values matching the keys in nested objects and arrays,
+// the keys are regular expressions matching the object keys and the array
+// indexes. For instance, with
+//
+//   {"routes": {"get_users": {"count": 4}, "get_groups": {"count": 2}}}
+//
+// the keys ["routes", "get_.*", "count"] return the counts of both routes.
+func deepGet(content interface{}, keys []string, traversed []string) []pathValue {
+	if len(keys) == 0 {
+		return []pathValue{{path: traversed, value: content}}
+	}
+
+	keyRe, err := compileKey(keys[0])
+	if err != nil {
+		return nil
+	}
+
+	var results []pathValue
+	visit := func(key string, value interface{}) {
+		if keyRe.MatchString(key) {
+			path := append(append([]string{}, traversed...), key)
+			results = append(results, deepGet(value, keys[1:], path)...)
+		}
+	}
+
+	switch c := content.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(c))
+		for k := range c {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			visit(k, c[k])
+		}
+	case []interface{}:
+		for i, v := range c {
+			visit(strconv.Itoa(i), v)
+		}
+	}
+	return results
+}
+
+// toFloat coerces the values the Python check reported: numbers, booleans
+// and strings holding numbers
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// normalize turns a path into a snake case metric name in a namespace,
+// memstats.PauseTotalNs becomes <namespace>.memstats.pause_total_ns
+func normalize(path, namespace string) string {
+	return toSnakeCase(namespace) + "." + toSnakeCase(path)
+}
+
+func toSnakeCase(name string) string {
+	name = firstCapRe.ReplaceAllString(name, "${1}_${2}")
+	name = strings.ToLower(allCapRe.ReplaceAllString(name, "${1}_${2}"))
+	name = invalidCharsRe.ReplaceAllString(name, "_")
+	name = dotUnderscoreRe.ReplaceAllString(name, ".")
+	name = multiUnderscoreRe.ReplaceAllString(name, "_")
+	return strings.Trim(name, "_")
+}
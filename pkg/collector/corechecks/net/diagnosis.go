@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package net
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func init() {
+	diagnosis.Register("NTP offset", diagnoseNTP)
+}
+
+// diagnoseNTP checks the clock offset of the host against the default ntp
+// servers of the ntp check
+func diagnoseNTP() error {
+	cfg := new(ntpConfig)
+	if err := cfg.parse([]byte(""), []byte("")); err != nil {
+		return err
+	}
+	c := &NTPCheck{cfg: cfg}
+
+	offsets := []float64{}
+	for _, r := range c.queryServers() {
+		if r.err != nil {
+			log.Warn(r.err)
+			continue
+		}
+		log.Infof("clock offset reported by %s: %vs", r.host, r.offset)
+		offsets = append(offsets, r.offset)
+	}
+
+	clockOffset, err := consensusOffset(offsets)
+	if err != nil {
+		return diagnosis.Fail(err, "allow the outgoing UDP traffic on port 123 to the ntp servers")
+	}
+	if math.Abs(clockOffset) > float64(cfg.instance.OffsetThreshold) {
+		return diagnosis.Fail(
+			fmt.Errorf("the clock offset %vs is higher than %vs", clockOffset, cfg.instance.OffsetThreshold),
+			"synchronize the clock of the host with an ntp server, the intake drops the points timestamped too far from the current time",
+		)
+	}
+	log.Infof("the clock offset is %vs", clockOffset)
+	return nil
+}
@@ -11,7 +11,9 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -95,6 +97,13 @@ type networkStats interface {
 	ProtoCounters(protocols []string) ([]net.ProtoCountersStat, error)
 	Connections(kind string) ([]net.ConnectionStat, error)
 	NetstatTCPExtCounters() (map[string]int64, error)
+	ConntrackStats() (conntrackStats, error)
+}
+
+// conntrackStats is the number of entries of the conntrack table and its size
+type conntrackStats struct {
+	count int64
+	max   int64
 }
 
 type defaultNetworkStats struct{}
@@ -115,6 +124,10 @@ func (n defaultNetworkStats) NetstatTCPExtCounters() (map[string]int64, error) {
 	return netstatTCPExtCounters()
 }
 
+func (n defaultNetworkStats) ConntrackStats() (conntrackStats, error) {
+	return readConntrackStats()
+}
+
 // Run executes the check
 func (c *NetworkCheck) Run() error {
 	sender, err := aggregator.GetSender(c.ID())
@@ -178,6 +191,14 @@ func (c *NetworkCheck) Run() error {
 		submitConnectionsMetrics(sender, "tcp6", tcpStateMetricsSuffixMapping, connectionsStats)
 	}
 
+	// The conntrack table is only available if the nf_conntrack module is loaded
+	conntrack, err := c.net.ConntrackStats()
+	if err != nil {
+		log.Debugf("Could not collect the conntrack metrics: %s", err)
+	} else {
+		submitConntrackMetrics(sender, conntrack)
+	}
+
 	sender.Commit()
 	return nil
 }
@@ -202,6 +223,8 @@ func submitInterfaceMetrics(sender aggregator.Sender, interfaceIO net.IOCounters
 	sender.Rate("system.net.packets_in.error", float64(interfaceIO.Errin), "", tags)
 	sender.Rate("system.net.packets_out.count", float64(interfaceIO.PacketsSent), "", tags)
 	sender.Rate("system.net.packets_out.error", float64(interfaceIO.Errout), "", tags)
+	sender.Rate("system.net.packets_in.drop", float64(interfaceIO.Dropin), "", tags)
+	sender.Rate("system.net.packets_out.drop", float64(interfaceIO.Dropout), "", tags)
 }
 
 func submitProtocolMetrics(sender aggregator.Sender, protocolStats net.ProtoCountersStat) {
@@ -230,9 +253,47 @@ func submitConnectionsMetrics(sender aggregator.Sender, protocolName string, sta
 	}
 }
 
+// submitConntrackMetrics reports the usage of the conntrack table, new
+// connections are dropped once it's full.
+func submitConntrackMetrics(sender aggregator.Sender, conntrack conntrackStats) {
+	sender.Gauge("system.net.conntrack.count", float64(conntrack.count), "", nil)
+	sender.Gauge("system.net.conntrack.max", float64(conntrack.max), "", nil)
+	if conntrack.max > 0 {
+		sender.Gauge("system.net.conntrack.saturation", 100*float64(conntrack.count)/float64(conntrack.max), "", nil)
+	}
+}
+
+func hostProc(paths ...string) string {
+	root := os.Getenv("HOST_PROC")
+	if root == "" {
+		root = "/proc"
+	}
+	return filepath.Join(append([]string{root}, paths...)...)
+}
+
+func readConntrackStats() (conntrackStats, error) {
+	count, err := readIntFile(hostProc("sys/net/netfilter/nf_conntrack_count"))
+	if err != nil {
+		return conntrackStats{}, err
+	}
+	max, err := readIntFile(hostProc("sys/net/netfilter/nf_conntrack_max"))
+	if err != nil {
+		return conntrackStats{}, err
+	}
+	return conntrackStats{count: count, max: max}, nil
+}
+
+func readIntFile(path string) (int64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+}
+
 func netstatTCPExtCounters() (map[string]int64, error) {
 
-	f, err := os.Open("/proc/net/netstat")
+	f, err := os.Open(hostProc("net/netstat"))
 	if err != nil {
 		return nil, err
 	}
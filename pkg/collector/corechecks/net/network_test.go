@@ -8,12 +8,17 @@
 package net
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	"github.com/shirou/gopsutil/net"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type fakeNetworkStats struct {
@@ -31,6 +36,8 @@ type fakeNetworkStats struct {
 	connectionStatsTCP6Error    error
 	netstatTCPExtCountersValues map[string]int64
 	netstatTCPExtCountersError  error
+	conntrackStatsValues        conntrackStats
+	conntrackStatsError         error
 }
 
 // IOCounters returns the inner values of counterStats and counterStatsError
@@ -62,6 +69,10 @@ func (n *fakeNetworkStats) NetstatTCPExtCounters() (map[string]int64, error) {
 	return n.netstatTCPExtCountersValues, n.netstatTCPExtCountersError
 }
 
+func (n *fakeNetworkStats) ConntrackStats() (conntrackStats, error) {
+	return n.conntrackStatsValues, n.conntrackStatsError
+}
+
 func TestDefaultConfiguration(t *testing.T) {
 	check := NetworkCheck{}
 	check.Configure([]byte(``), []byte(``))
@@ -100,6 +111,8 @@ func TestNetworkCheck(t *testing.T) {
 				Errin:       13,
 				PacketsSent: 14,
 				Errout:      15,
+				Dropin:      36,
+				Dropout:     37,
 			},
 			{
 				Name:        "lo0",
@@ -256,6 +269,7 @@ func TestNetworkCheck(t *testing.T) {
 			"TCPBacklogDrop":  34,
 			"TCPRetransFail":  35,
 		},
+		conntrackStatsValues: conntrackStats{count: 250, max: 1000},
 	}
 
 	networkCheck := NetworkCheck{
@@ -288,6 +302,8 @@ collect_connection_state: true
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_in.error", float64(13), "", eth0Tags)
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.count", float64(14), "", eth0Tags)
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.error", float64(15), "", eth0Tags)
+	mockSender.AssertCalled(t, "Rate", "system.net.packets_in.drop", float64(36), "", eth0Tags)
+	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.drop", float64(37), "", eth0Tags)
 
 	lo0Tags := []string{"device:lo0"}
 	mockSender.AssertCalled(t, "Rate", "system.net.bytes_rcvd", float64(16), "", lo0Tags)
@@ -342,9 +358,52 @@ collect_connection_state: true
 	mockSender.AssertCalled(t, "Gauge", "system.net.tcp6.closing", float64(12), "", customTags)
 	mockSender.AssertCalled(t, "Gauge", "system.net.tcp6.listening", float64(2), "", customTags)
 
+	mockSender.AssertCalled(t, "Gauge", "system.net.conntrack.count", float64(250), "", customTags)
+	mockSender.AssertCalled(t, "Gauge", "system.net.conntrack.max", float64(1000), "", customTags)
+	mockSender.AssertCalled(t, "Gauge", "system.net.conntrack.saturation", float64(25), "", customTags)
+
 	mockSender.AssertCalled(t, "Commit")
 }
 
+func TestConntrackUnavailable(t *testing.T) {
+	networkCheck := NetworkCheck{
+		net: &fakeNetworkStats{
+			conntrackStatsError: errors.New("nf_conntrack not loaded"),
+		},
+	}
+	err := networkCheck.Configure([]byte(``), []byte(``))
+	assert.Nil(t, err)
+
+	mockSender := mocksender.NewMockSender(networkCheck.ID())
+	mockSender.SetupAcceptAll()
+
+	err = networkCheck.Run()
+	assert.Nil(t, err)
+
+	mockSender.AssertNotCalled(t, "Gauge", "system.net.conntrack.count", mock.Anything, mock.Anything, mock.Anything)
+	mockSender.AssertNotCalled(t, "Gauge", "system.net.conntrack.saturation", mock.Anything, mock.Anything, mock.Anything)
+	mockSender.AssertCalled(t, "Commit")
+}
+
+func TestReadConntrackStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sys/net/netfilter"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sys/net/netfilter/nf_conntrack_count"), []byte("42\n"), 0644))
+
+	os.Setenv("HOST_PROC", dir)
+	defer os.Unsetenv("HOST_PROC")
+
+	_, err = readConntrackStats()
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sys/net/netfilter/nf_conntrack_max"), []byte("65536\n"), 0644))
+	stats, err := readConntrackStats()
+	require.NoError(t, err)
+	assert.Equal(t, conntrackStats{count: 42, max: 65536}, stats)
+}
+
 func TestExcludedInterfaces(t *testing.T) {
 	net := &fakeNetworkStats{
 		counterStats: []net.IOCountersStat{
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/beevik/ntp"
@@ -23,7 +24,14 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-const ntpCheckName = "ntp"
+const (
+	ntpCheckName = "ntp"
+
+	// minOutlierDeviation is the deviation from the median offset under which
+	// a server is never considered as an outlier, so that the jitter of
+	// servers agreeing with each other doesn't get them discarded.
+	minOutlierDeviation = 0.1
+)
 
 var (
 	ntpExpVar = expvar.NewFloat("ntpOffset")
@@ -40,6 +48,7 @@ type NTPCheck struct {
 
 type ntpInstanceConfig struct {
 	OffsetThreshold       int      `yaml:"offset_threshold"`
+	WarningThreshold      float64  `yaml:"warning_offset_threshold"`
 	Host                  string   `yaml:"host"`
 	Hosts                 []string `yaml:"hosts"`
 	Port                  string   `yaml:"port"`
@@ -145,8 +154,22 @@ func (c *NTPCheck) Run() error {
 	var serviceCheckStatus metrics.ServiceCheckStatus
 	serviceCheckMessage := ""
 	offsetThreshold := c.cfg.instance.OffsetThreshold
+	warningThreshold := c.cfg.instance.WarningThreshold
+
+	offsets := []float64{}
+	for _, r := range c.queryServers() {
+		tags := []string{fmt.Sprintf("ntp_server:%s", r.host)}
+		if r.err != nil {
+			log.Info(r.err)
+			sender.Gauge("ntp.server.reachable", 0, "", tags)
+			continue
+		}
+		sender.Gauge("ntp.server.reachable", 1, "", tags)
+		sender.Gauge("ntp.server.offset", r.offset, "", tags)
+		offsets = append(offsets, r.offset)
+	}
 
-	clockOffset, err := c.queryOffset()
+	clockOffset, err := consensusOffset(offsets)
 	if err != nil {
 		log.Info(err)
 		serviceCheckStatus = metrics.ServiceCheckUnknown
@@ -154,6 +177,9 @@ func (c *NTPCheck) Run() error {
 		if int(math.Abs(clockOffset)) > offsetThreshold {
 			serviceCheckStatus = metrics.ServiceCheckCritical
 			serviceCheckMessage = fmt.Sprintf("Offset %v is higher than offset threshold (%v secs)", clockOffset, offsetThreshold)
+		} else if warningThreshold > 0 && math.Abs(clockOffset) > warningThreshold {
+			serviceCheckStatus = metrics.ServiceCheckWarning
+			serviceCheckMessage = fmt.Sprintf("Offset %v is higher than warning offset threshold (%v secs)", clockOffset, warningThreshold)
 		} else {
 			serviceCheckStatus = metrics.ServiceCheckOK
 		}
@@ -171,38 +197,82 @@ func (c *NTPCheck) Run() error {
 	return nil
 }
 
-func (c *NTPCheck) queryOffset() (float64, error) {
-	offsets := []float64{}
+// serverResult is the clock offset reported by an ntp server, in seconds
+type serverResult struct {
+	host   string
+	offset float64
+	err    error
+}
 
-	for _, host := range c.cfg.instance.Hosts {
-		response, err := ntpQuery(host, ntp.QueryOptions{Version: c.cfg.instance.Version})
-		if err != nil {
-			log.Infof("There was an error querying the ntp host %s: %s", host, err)
-			continue
-		}
-		err = response.Validate()
-		if err != nil {
-			log.Infof("The ntp response is not valid for host %s: %s", host, err)
-			continue
-		}
-		offsets = append(offsets, response.ClockOffset.Seconds())
+// queryServers queries the ntp servers concurrently, the results are in the
+// order of the configured servers.
+func (c *NTPCheck) queryServers() []serverResult {
+	results := make([]serverResult, len(c.cfg.instance.Hosts))
+	options := ntp.QueryOptions{
+		Version: c.cfg.instance.Version,
+		Timeout: time.Duration(c.cfg.instance.Timeout) * time.Second,
 	}
 
+	var wg sync.WaitGroup
+	for i, host := range c.cfg.instance.Hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			results[i].host = host
+			response, err := ntpQuery(host, options)
+			if err != nil {
+				results[i].err = fmt.Errorf("There was an error querying the ntp host %s: %s", host, err)
+				return
+			}
+			if err = response.Validate(); err != nil {
+				results[i].err = fmt.Errorf("The ntp response is not valid for host %s: %s", host, err)
+				return
+			}
+			results[i].offset = response.ClockOffset.Seconds()
+		}(i, host)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// consensusOffset returns the median of the offsets reported by the servers,
+// after discarding the outliers: the offsets further from the median than
+// three times the median absolute deviation.
+func consensusOffset(offsets []float64) (float64, error) {
 	if len(offsets) == 0 {
 		return .0, fmt.Errorf("Failed to get clock offset from any ntp host")
 	}
 
-	var median float64
+	m := median(offsets)
+	deviations := make([]float64, 0, len(offsets))
+	for _, o := range offsets {
+		deviations = append(deviations, math.Abs(o-m))
+	}
+	maxDeviation := math.Max(3*median(deviations), minOutlierDeviation)
 
-	sort.Float64s(offsets)
-	length := len(offsets)
-	if length%2 == 0 {
-		median = (offsets[length/2-1] + offsets[length/2]) / 2.0
-	} else {
-		median = offsets[length/2]
+	inliers := make([]float64, 0, len(offsets))
+	for _, o := range offsets {
+		if math.Abs(o-m) > maxDeviation {
+			log.Infof("Discarding the ntp offset %v, too far from the median offset %v", o, m)
+			continue
+		}
+		inliers = append(inliers, o)
 	}
 
-	return median, nil
+	return median(inliers), nil
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	length := len(sorted)
+	if length%2 == 0 {
+		return (sorted[length/2-1] + sorted[length/2]) / 2.0
+	}
+	return sorted[length/2]
 }
 
 func ntpFactory() check.Check {
@@ -8,6 +8,7 @@ package net
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -29,6 +31,12 @@ timeout: 5
 	offset = 10
 )
 
+// expectServerMetrics accepts the per-server metrics, asserted in TestNTPServerMetrics
+func expectServerMetrics(m *mocksender.MockSender) {
+	isServerMetric := mock.MatchedBy(func(name string) bool { return strings.HasPrefix(name, "ntp.server.") })
+	m.On("Gauge", isServerMetric, mock.AnythingOfType("float64"), "", mock.AnythingOfType("[]string")).Return()
+}
+
 func testNTPQueryError(host string, opt ntp.QueryOptions) (*ntp.Response, error) {
 	return nil, fmt.Errorf("test error from NTP")
 }
@@ -59,6 +67,7 @@ func TestNTPOK(t *testing.T) {
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
 	mockSender.On("Gauge", "ntp.offset", float64(21), "", []string(nil)).Return().Times(1)
 	mockSender.On("ServiceCheck",
@@ -72,7 +81,7 @@ func TestNTPOK(t *testing.T) {
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+	mockSender.AssertCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -89,6 +98,7 @@ func TestNTPCritical(t *testing.T) {
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
 	mockSender.On("Gauge", "ntp.offset", float64(100), "", []string(nil)).Return().Times(1)
 	mockSender.On("ServiceCheck",
@@ -102,7 +112,7 @@ func TestNTPCritical(t *testing.T) {
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+	mockSender.AssertCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -118,6 +128,7 @@ func TestNTPError(t *testing.T) {
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
 	mockSender.On("ServiceCheck",
 		"ntp.in_sync",
@@ -130,7 +141,7 @@ func TestNTPError(t *testing.T) {
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 0)
+	mockSender.AssertNotCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -146,6 +157,7 @@ func TestNTPInvalid(t *testing.T) {
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
 	mockSender.On("ServiceCheck",
 		"ntp.in_sync",
@@ -158,7 +170,7 @@ func TestNTPInvalid(t *testing.T) {
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 0)
+	mockSender.AssertNotCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -175,6 +187,7 @@ func TestNTPNegativeOffsetCritical(t *testing.T) {
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
 	mockSender.On("Gauge", "ntp.offset", float64(-100), "", []string(nil)).Return().Times(1)
 	mockSender.On("ServiceCheck",
@@ -188,7 +201,7 @@ func TestNTPNegativeOffsetCritical(t *testing.T) {
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+	mockSender.AssertCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -216,8 +229,10 @@ hosts:
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
-	mockSender.On("Gauge", "ntp.offset", float64(2), "", []string(nil)).Return().Times(1)
+	// 400 is discarded as an outlier, the offset is the median of 1 and 2
+	mockSender.On("Gauge", "ntp.offset", float64(1.5), "", []string(nil)).Return().Times(1)
 	mockSender.On("ServiceCheck",
 		"ntp.in_sync",
 		metrics.ServiceCheckOK,
@@ -229,7 +244,7 @@ hosts:
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+	mockSender.AssertCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -257,6 +272,7 @@ hosts:
 	ntpCheck.Configure(ntpCfg, ntpInitCfg)
 
 	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	expectServerMetrics(mockSender)
 
 	mockSender.On("Gauge", "ntp.offset", float64(400), "", []string(nil)).Return().Times(1)
 	mockSender.On("ServiceCheck",
@@ -270,7 +286,7 @@ hosts:
 	ntpCheck.Run()
 
 	mockSender.AssertExpectations(t)
-	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+	mockSender.AssertCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 1)
 	mockSender.AssertNumberOfCalls(t, "Commit", 1)
 }
@@ -343,3 +359,97 @@ func TestDefaultHostConfig(t *testing.T) {
 
 	assert.Equal(t, expectedHosts, ntpCheck.cfg.instance.Hosts)
 }
+
+func TestNTPServerMetrics(t *testing.T) {
+	var ntpCfg = []byte(`
+hosts:
+  - 1
+  - 2
+  - unreachable
+`)
+	ntpQuery = func(host string, opt ntp.QueryOptions) (*ntp.Response, error) {
+		o, err := strconv.Atoi(host)
+		if err != nil {
+			return nil, fmt.Errorf("test error from NTP")
+		}
+		return &ntp.Response{
+			ClockOffset: time.Duration(o) * time.Second,
+			Stratum:     1,
+		}, nil
+	}
+	defer func() { ntpQuery = ntp.QueryWithOptions }()
+
+	ntpCheck := new(NTPCheck)
+	ntpCheck.Configure(ntpCfg, []byte(""))
+
+	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	mockSender.SetupAcceptAll()
+	ntpCheck.Run()
+
+	mockSender.AssertMetric(t, "Gauge", "ntp.server.reachable", 1, "", []string{"ntp_server:1"})
+	mockSender.AssertMetric(t, "Gauge", "ntp.server.offset", 1, "", []string{"ntp_server:1"})
+	mockSender.AssertMetric(t, "Gauge", "ntp.server.reachable", 1, "", []string{"ntp_server:2"})
+	mockSender.AssertMetric(t, "Gauge", "ntp.server.offset", 2, "", []string{"ntp_server:2"})
+	mockSender.AssertMetric(t, "Gauge", "ntp.server.reachable", 0, "", []string{"ntp_server:unreachable"})
+	mockSender.AssertNotCalled(t, "Gauge", "ntp.server.offset", mock.Anything, "", []string{"ntp_server:unreachable"})
+	mockSender.AssertMetric(t, "Gauge", "ntp.offset", 1.5, "", nil)
+}
+
+func TestNTPWarning(t *testing.T) {
+	var ntpCfg = []byte(`
+offset_threshold: 60
+warning_offset_threshold: 0.5
+`)
+	offset = 1
+	ntpQuery = testNTPQuery
+	defer func() { ntpQuery = ntp.QueryWithOptions }()
+
+	ntpCheck := new(NTPCheck)
+	ntpCheck.Configure(ntpCfg, []byte(""))
+
+	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+	mockSender.SetupAcceptAll()
+	ntpCheck.Run()
+
+	mockSender.AssertServiceCheck(t, "ntp.in_sync", metrics.ServiceCheckWarning, "", nil, "Offset 1 is higher than warning offset threshold (0.5 secs)")
+}
+
+func TestConsensusOffset(t *testing.T) {
+	_, err := consensusOffset(nil)
+	assert.Error(t, err)
+
+	for _, tc := range []struct {
+		offsets  []float64
+		expected float64
+	}{
+		{[]float64{0.01}, 0.01},
+		// servers agreeing within the minimum deviation are all kept
+		{[]float64{0.01, 0.02, 0.05, 0.08}, 0.035},
+		// a falseticker is discarded
+		{[]float64{0.01, 0.02, 0.03, 12}, 0.02},
+		{[]float64{-30, 0.01, 0.02, 0.03}, 0.02},
+		// two servers can't be told apart
+		{[]float64{0.01, 12}, 6.005},
+	} {
+		o, err := consensusOffset(tc.offsets)
+		assert.NoError(t, err)
+		assert.InDelta(t, tc.expected, o, 1e-9, "offsets %v", tc.offsets)
+	}
+}
+
+func TestDiagnoseNTP(t *testing.T) {
+	ntpQuery = testNTPQuery
+	defer func() { ntpQuery = ntp.QueryWithOptions }()
+
+	offset = 10
+	assert.NoError(t, diagnoseNTP())
+
+	offset = 120
+	defer func() { offset = 10 }()
+	err := diagnoseNTP()
+	assert.IsType(t, &diagnosis.Failure{}, err)
+
+	ntpQuery = testNTPQueryError
+	err = diagnoseNTP()
+	assert.IsType(t, &diagnosis.Failure{}, err)
+}
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package net
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	syntheticCheckName = "synthetic"
+
+	// the metrics and service checks are the ones of the http_check and
+	// tcp_check integrations, so that their instances can be migrated
+	httpCanConnect = "http.can_connect"
+	httpSSLCert    = "http.ssl_cert"
+	tcpCanConnect  = "tcp.can_connect"
+
+	// maximum size of the response body matched against content_match
+	maxContentSize = 1024 * 1024
+)
+
+// SyntheticCheck probes an HTTP(S) endpoint or a TCP port, reporting its
+// response time, whether the response is the expected one and the
+// expiration of the certificate of HTTPS endpoints. It's a native
+// replacement of the http_check and tcp_check integrations.
+type SyntheticCheck struct {
+	core.CheckBase
+	cfg    *syntheticConfig
+	client *http.Client
+}
+
+type syntheticInstanceConfig struct {
+	Name                       string            `yaml:"name"`
+	URL                        string            `yaml:"url"`
+	Method                     string            `yaml:"method"`
+	Headers                    map[string]string `yaml:"headers"`
+	Data                       string            `yaml:"data"`
+	ContentMatch               string            `yaml:"content_match"`
+	StatusCode                 string            `yaml:"http_response_status_code"`
+	TLSVerify                  *bool             `yaml:"tls_verify"`
+	CheckCertificateExpiration *bool             `yaml:"check_certificate_expiration"`
+	DaysWarning                float64           `yaml:"days_warning"`
+	DaysCritical               float64           `yaml:"days_critical"`
+	Host                       string            `yaml:"host"`
+	Port                       int               `yaml:"port"`
+	Timeout                    float64           `yaml:"timeout"`
+}
+
+type syntheticConfig struct {
+	instance     syntheticInstanceConfig
+	contentMatch *regexp.Regexp
+	statusCode   *regexp.Regexp
+}
+
+func (c *syntheticConfig) parse(data []byte) error {
+	var instance syntheticInstanceConfig
+	defaultTrue := true
+
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+	if instance.Name == "" {
+		return fmt.Errorf("the name of the instance must be set")
+	}
+	if instance.URL == "" && instance.Host == "" {
+		return fmt.Errorf("either url or host must be set")
+	}
+	if instance.URL != "" && instance.Host != "" {
+		return fmt.Errorf("url and host are mutually exclusive")
+	}
+	if instance.Host != "" && instance.Port == 0 {
+		return fmt.Errorf("the port of the host must be set")
+	}
+
+	if instance.Method == "" {
+		instance.Method = http.MethodGet
+	}
+	instance.Method = strings.ToUpper(instance.Method)
+	if instance.StatusCode == "" {
+		instance.StatusCode = `(1|2|3)\d\d`
+	}
+	if instance.TLSVerify == nil {
+		instance.TLSVerify = &defaultTrue
+	}
+	if instance.CheckCertificateExpiration == nil {
+		instance.CheckCertificateExpiration = &defaultTrue
+	}
+	if instance.DaysWarning == 0 {
+		instance.DaysWarning = 14
+	}
+	if instance.DaysCritical == 0 {
+		instance.DaysCritical = 7
+	}
+	if instance.Timeout == 0 {
+		instance.Timeout = 10
+	}
+
+	statusCode, err := regexp.Compile("^" + instance.StatusCode + "$")
+	if err != nil {
+		return fmt.Errorf("invalid http_response_status_code: %s", err)
+	}
+	c.statusCode = statusCode
+	if instance.ContentMatch != "" {
+		if c.contentMatch, err = regexp.Compile(instance.ContentMatch); err != nil {
+			return fmt.Errorf("invalid content_match: %s", err)
+		}
+	}
+	c.instance = instance
+
+	return nil
+}
+
+func (c *syntheticConfig) timeout() time.Duration {
+	return time.Duration(c.instance.Timeout * float64(time.Second))
+}
+
+// Configure parses the check configuration and sets up the HTTP client
+func (c *SyntheticCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	cfg := &syntheticConfig{}
+	if err := cfg.parse(data); err != nil {
+		return err
+	}
+	c.cfg = cfg
+
+	// connections aren't reused so that every probe measures a full request
+	c.client = &http.Client{
+		Timeout: cfg.timeout(),
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: !*cfg.instance.TLSVerify},
+			DisableKeepAlives: true,
+		},
+	}
+
+	return nil
+}
+
+// Run executes the check
+func (c *SyntheticCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	tags := []string{fmt.Sprintf("instance:%s", c.cfg.instance.Name)}
+	if c.cfg.instance.URL != "" {
+		c.probeHTTP(sender, append(tags, fmt.Sprintf("url:%s", c.cfg.instance.URL)))
+	} else {
+		c.probeTCP(sender, append(tags, fmt.Sprintf("target_host:%s", c.cfg.instance.Host), fmt.Sprintf("port:%d", c.cfg.instance.Port)))
+	}
+	return nil
+}
+
+func (c *SyntheticCheck) probeHTTP(sender aggregator.Sender, tags []string) {
+	instance := c.cfg.instance
+
+	req, err := http.NewRequest(instance.Method, instance.URL, strings.NewReader(instance.Data))
+	if err != nil {
+		sender.ServiceCheck(httpCanConnect, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return
+	}
+	for name, value := range instance.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+		} else {
+			req.Header.Set(name, value)
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		sender.ServiceCheck(httpCanConnect, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxContentSize))
+	elapsed := time.Since(start)
+	if err != nil {
+		sender.ServiceCheck(httpCanConnect, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("could not read the response: %s", err))
+		return
+	}
+	sender.Gauge("network.http.response_time", elapsed.Seconds(), "", tags)
+
+	if c.cfg.statusCode.MatchString(strconv.Itoa(resp.StatusCode)) {
+		if c.cfg.contentMatch != nil && !c.cfg.contentMatch.Match(body) {
+			sender.ServiceCheck(httpCanConnect, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("content %q not found in the response", instance.ContentMatch))
+		} else {
+			sender.ServiceCheck(httpCanConnect, metrics.ServiceCheckOK, "", tags, "")
+		}
+	} else {
+		sender.ServiceCheck(httpCanConnect, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("incorrect HTTP return code for url %s, expected %s, got %d", instance.URL, instance.StatusCode, resp.StatusCode))
+	}
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 && *instance.CheckCertificateExpiration {
+		left := resp.TLS.PeerCertificates[0].NotAfter.Sub(timeNow())
+		daysLeft := left.Hours() / 24
+		sender.Gauge("http.ssl.days_left", daysLeft, "", tags)
+		sender.Gauge("http.ssl.seconds_left", left.Seconds(), "", tags)
+
+		if daysLeft <= 0 {
+			sender.ServiceCheck(httpSSLCert, metrics.ServiceCheckCritical, "", tags, "The certificate has expired")
+		} else if daysLeft < instance.DaysCritical {
+			sender.ServiceCheck(httpSSLCert, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("The certificate expires in %.1f days", daysLeft))
+		} else if daysLeft < instance.DaysWarning {
+			sender.ServiceCheck(httpSSLCert, metrics.ServiceCheckWarning, "", tags, fmt.Sprintf("The certificate expires in %.1f days", daysLeft))
+		} else {
+			sender.ServiceCheck(httpSSLCert, metrics.ServiceCheckOK, "", tags, "")
+		}
+	}
+}
+
+func (c *SyntheticCheck) probeTCP(sender aggregator.Sender, tags []string) {
+	address := net.JoinHostPort(c.cfg.instance.Host, strconv.Itoa(c.cfg.instance.Port))
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, c.cfg.timeout())
+	if err != nil {
+		sender.ServiceCheck(tcpCanConnect, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+
+	sender.Gauge("network.tcp.response_time", elapsed.Seconds(), "", tags)
+	sender.ServiceCheck(tcpCanConnect, metrics.ServiceCheckOK, "", tags, "")
+}
+
+func syntheticFactory() check.Check {
+	return &SyntheticCheck{
+		CheckBase: core.NewCheckBase(syntheticCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(syntheticCheckName, syntheticFactory)
+}
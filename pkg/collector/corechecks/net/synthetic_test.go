@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package net
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func runSyntheticCheck(t *testing.T, config string) *mocksender.MockSender {
+	check := syntheticFactory().(*SyntheticCheck)
+	require.NoError(t, check.Configure([]byte(config), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return sender
+}
+
+func TestSyntheticHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" || string(body) != "ping" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"status": "pong"}`)
+	}))
+	defer server.Close()
+
+	config := fmt.Sprintf("name: api\nurl: %s\nmethod: post\nheaders:\n  X-Token: secret\ndata: ping\n", server.URL)
+	tags := []string{"instance:api", "url:" + server.URL}
+
+	sender := runSyntheticCheck(t, config)
+	sender.AssertCalled(t, "Gauge", "network.http.response_time", mock.AnythingOfType("float64"), "", tags)
+	sender.AssertServiceCheck(t, httpCanConnect, metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertNotCalled(t, "ServiceCheck", httpSSLCert, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	sender = runSyntheticCheck(t, config+`content_match: '"status": "pong"'`)
+	sender.AssertServiceCheck(t, httpCanConnect, metrics.ServiceCheckOK, "", tags, "")
+
+	sender = runSyntheticCheck(t, config+"content_match: unavailable")
+	sender.AssertCalled(t, "ServiceCheck", httpCanConnect, metrics.ServiceCheckCritical, "", tags, `content "unavailable" not found in the response`)
+
+	// the request is rejected without the header
+	sender = runSyntheticCheck(t, fmt.Sprintf("name: api\nurl: %s\nmethod: post\ndata: ping", server.URL))
+	sender.AssertCalled(t, "ServiceCheck", httpCanConnect, metrics.ServiceCheckCritical, "", mock.Anything, mock.Anything)
+
+	sender = runSyntheticCheck(t, fmt.Sprintf("name: api\nurl: %s\nmethod: post\ndata: ping\nhttp_response_status_code: 400", server.URL))
+	sender.AssertCalled(t, "ServiceCheck", httpCanConnect, metrics.ServiceCheckOK, "", mock.Anything, "")
+}
+
+func TestSyntheticHTTPTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	sender := runSyntheticCheck(t, fmt.Sprintf("name: slow\nurl: %s\ntimeout: 0.1", server.URL))
+	sender.AssertCalled(t, "ServiceCheck", httpCanConnect, metrics.ServiceCheckCritical, "", mock.Anything, mock.Anything)
+	sender.AssertNotCalled(t, "Gauge", "network.http.response_time", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSyntheticHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	notAfter := server.Certificate().NotAfter
+
+	config := fmt.Sprintf("name: secure\nurl: %s\ntls_verify: false", server.URL)
+	tags := []string{"instance:secure", "url:" + server.URL}
+
+	for _, tc := range []struct {
+		daysLeft int
+		status   metrics.ServiceCheckStatus
+	}{
+		{30, metrics.ServiceCheckOK},
+		{10, metrics.ServiceCheckWarning},
+		{3, metrics.ServiceCheckCritical},
+		{-1, metrics.ServiceCheckCritical},
+	} {
+		now := notAfter.Add(-time.Duration(tc.daysLeft) * 24 * time.Hour)
+		timeNow = func() time.Time { return now }
+
+		sender := runSyntheticCheck(t, config)
+		sender.AssertServiceCheck(t, httpCanConnect, metrics.ServiceCheckOK, "", tags, "")
+		sender.AssertMetric(t, "Gauge", "http.ssl.days_left", float64(tc.daysLeft), "", tags)
+		sender.AssertCalled(t, "ServiceCheck", httpSSLCert, tc.status, "", tags, mock.Anything)
+	}
+	timeNow = time.Now
+
+	// the certificate of the test server isn't trusted
+	sender := runSyntheticCheck(t, fmt.Sprintf("name: secure\nurl: %s", server.URL))
+	sender.AssertCalled(t, "ServiceCheck", httpCanConnect, metrics.ServiceCheckCritical, "", tags, mock.Anything)
+
+	sender = runSyntheticCheck(t, config+"\ncheck_certificate_expiration: false")
+	sender.AssertNotCalled(t, "ServiceCheck", httpSSLCert, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSyntheticTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tags := []string{"instance:listener", "target_host:127.0.0.1", fmt.Sprintf("port:%d", port)}
+	config := fmt.Sprintf("name: listener\nhost: 127.0.0.1\nport: %d", port)
+
+	sender := runSyntheticCheck(t, config)
+	sender.AssertCalled(t, "Gauge", "network.tcp.response_time", mock.AnythingOfType("float64"), "", tags)
+	sender.AssertServiceCheck(t, tcpCanConnect, metrics.ServiceCheckOK, "", tags, "")
+
+	listener.Close()
+	sender = runSyntheticCheck(t, config)
+	sender.AssertCalled(t, "ServiceCheck", tcpCanConnect, metrics.ServiceCheckCritical, "", tags, mock.Anything)
+}
+
+func TestSyntheticConfig(t *testing.T) {
+	for _, config := range []string{
+		"url: http://localhost",
+		"name: empty",
+		"name: both\nurl: http://localhost\nhost: localhost\nport: 80",
+		"name: noport\nhost: localhost",
+		"name: regexp\nurl: http://localhost\ncontent_match: '('",
+	} {
+		check := syntheticFactory().(*SyntheticCheck)
+		assert.Error(t, check.Configure([]byte(config), nil), config)
+	}
+}
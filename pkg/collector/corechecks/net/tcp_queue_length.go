@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build linux
+
+package net
+
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/ebpf/tcpqueuelength"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/sysprobe"
+)
+
+const tcpQueueLengthCheckName = "tcp_queue_length"
+
+// For testing purpose
+var entityForPID = containers.EntityForPID
+
+// TCPQueueLengthCheck reports the fill of the TCP buffers of the containers,
+// measured by the system-probe. Full buffers reveal applications that can't
+// keep up with their peers, which the byte counters don't show.
+type TCPQueueLengthCheck struct {
+	core.CheckBase
+	client *http.Client
+}
+
+// Run executes the check
+func (c *TCPQueueLengthCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	stats, err := c.getStats()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		tags, err := namespaceTags(s.Pid)
+		if err != nil {
+			log.Debugf("Skipping the network namespace %d: %s", s.NetNS, err)
+			continue
+		}
+		sender.Gauge("tcp_queue.sockets", float64(s.Sockets), "", tags)
+		submitBufferUsage(sender, "tcp_queue.read_buffer", s.ReadBuffer, tags)
+		submitBufferUsage(sender, "tcp_queue.write_buffer", s.WriteBuffer, tags)
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func submitBufferUsage(sender aggregator.Sender, prefix string, usage tcpqueuelength.BufferUsage, tags []string) {
+	sender.Gauge(prefix+".p50", usage.P50, "", tags)
+	sender.Gauge(prefix+".p95", usage.P95, "", tags)
+	sender.Gauge(prefix+".max", usage.Max, "", tags)
+}
+
+// namespaceTags returns the tags of the container of a network namespace,
+// none for the namespace of the host
+func namespaceTags(pid int) ([]string, error) {
+	entity, err := entityForPID(int32(pid))
+	if err == containers.ErrNoContainerMatch {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tagger.Tag(entity, collectors.HighCardinality)
+}
+
+func (c *TCPQueueLengthCheck) getStats() ([]tcpqueuelength.Stats, error) {
+	var stats []tcpqueuelength.Stats
+	if err := sysprobe.GetCheck(c.client, tcpQueueLengthCheckName, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Configure configures the tcp_queue_length check
+func (c *TCPQueueLengthCheck) Configure(rawInstance integration.Data, rawInitConfig integration.Data) error {
+	if err := c.CommonConfigure(rawInstance); err != nil {
+		return err
+	}
+
+	c.client = sysprobe.NewClient()
+	return nil
+}
+
+func tcpQueueLengthFactory() check.Check {
+	return &TCPQueueLengthCheck{
+		CheckBase: core.NewCheckBase(tcpQueueLengthCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(tcpQueueLengthCheckName, tcpQueueLengthFactory)
+}
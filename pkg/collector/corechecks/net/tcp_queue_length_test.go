@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build linux
+
+package net
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func TestTCPQueueLengthCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sysprobe")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "sysprobe.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/check/tcp_queue_length", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"netns": 4026531993, "pid": 1, "sockets": 12, "read_buffer": {"p50": 0, "p95": 10, "max": 25}, "write_buffer": {"p50": 1, "p95": 50, "max": 100}},
+			{"netns": 4026532281, "pid": 4242, "sockets": 3, "read_buffer": {}, "write_buffer": {}}
+		]`))
+	})
+	go http.Serve(listener, mux)
+	defer listener.Close()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("system_probe_config.sysprobe_socket", socketPath)
+	defer mockConfig.Set("system_probe_config.sysprobe_socket", "")
+
+	entityForPID = func(pid int32) (string, error) {
+		if pid == 1 {
+			return "", containers.ErrNoContainerMatch
+		}
+		return "", containers.ErrNoRuntimeMatch
+	}
+	defer func() { entityForPID = containers.EntityForPID }()
+
+	check := tcpQueueLengthFactory().(*TCPQueueLengthCheck)
+	require.NoError(t, check.Configure(nil, nil))
+
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	var hostTags []string
+	mock.AssertMetric(t, "Gauge", "tcp_queue.sockets", 12, "", hostTags)
+	mock.AssertMetric(t, "Gauge", "tcp_queue.read_buffer.p95", 10, "", hostTags)
+	mock.AssertMetric(t, "Gauge", "tcp_queue.read_buffer.max", 25, "", hostTags)
+	mock.AssertMetric(t, "Gauge", "tcp_queue.write_buffer.p50", 1, "", hostTags)
+	mock.AssertMetric(t, "Gauge", "tcp_queue.write_buffer.max", 100, "", hostTags)
+	// the namespace whose container can't be found is skipped
+	mock.AssertNumberOfCalls(t, "Gauge", 7)
+}
+
+func TestTCPQueueLengthCheckNoSystemProbe(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("system_probe_config.sysprobe_socket", "/does/not/exist.sock")
+	defer mockConfig.Set("system_probe_config.sysprobe_socket", "")
+
+	check := tcpQueueLengthFactory().(*TCPQueueLengthCheck)
+	require.NoError(t, check.Configure(nil, nil))
+
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+	require.Error(t, check.Run())
+}
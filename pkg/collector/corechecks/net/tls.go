@@ -0,0 +1,287 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	tlsCheckName = "tls"
+
+	tlsCanConnect     = "tls.can_connect"
+	tlsCertValidation = "tls.cert_validation"
+	tlsCertExpiration = "tls.cert_expiration"
+)
+
+// for testing purpose
+var timeNow = time.Now
+
+// TLSCheck reports the expiration of the TLS certificate of an endpoint, or
+// of a local certificate file, and whether its chain is valid.
+type TLSCheck struct {
+	core.CheckBase
+	cfg   *tlsConfig
+	roots *x509.CertPool
+}
+
+type tlsInstanceConfig struct {
+	Server           string  `yaml:"server"`
+	Port             int     `yaml:"port"`
+	ServerHostname   string  `yaml:"server_hostname"`
+	LocalCertPath    string  `yaml:"local_cert_path"`
+	CACert           string  `yaml:"ca_cert"`
+	ValidateCert     *bool   `yaml:"validate_cert"`
+	ValidateHostname *bool   `yaml:"validate_hostname"`
+	DaysWarning      float64 `yaml:"days_warning"`
+	DaysCritical     float64 `yaml:"days_critical"`
+	Timeout          int     `yaml:"timeout"`
+}
+
+type tlsConfig struct {
+	instance tlsInstanceConfig
+}
+
+func (c *tlsConfig) parse(data []byte) error {
+	var instance tlsInstanceConfig
+	defaultValidate := true
+
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+	if instance.Server == "" && instance.LocalCertPath == "" {
+		return fmt.Errorf("either server or local_cert_path must be set")
+	}
+	if instance.Server != "" && instance.LocalCertPath != "" {
+		return fmt.Errorf("server and local_cert_path are mutually exclusive")
+	}
+
+	if instance.Port == 0 {
+		instance.Port = 443
+	}
+	if instance.ServerHostname == "" {
+		instance.ServerHostname = instance.Server
+	}
+	if instance.ValidateCert == nil {
+		instance.ValidateCert = &defaultValidate
+	}
+	if instance.ValidateHostname == nil {
+		instance.ValidateHostname = &defaultValidate
+	}
+	if instance.DaysWarning == 0 {
+		instance.DaysWarning = 14
+	}
+	if instance.DaysCritical == 0 {
+		instance.DaysCritical = 7
+	}
+	if instance.Timeout == 0 {
+		instance.Timeout = 10
+	}
+	c.instance = instance
+
+	return nil
+}
+
+// Configure parses the check configuration and loads the custom CA
+func (c *TLSCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	cfg := &tlsConfig{}
+	if err := cfg.parse(data); err != nil {
+		return err
+	}
+
+	c.roots = nil
+	if cfg.instance.CACert != "" {
+		roots, err := loadRoots(cfg.instance.CACert)
+		if err != nil {
+			return err
+		}
+		c.roots = roots
+	}
+	c.cfg = cfg
+
+	return nil
+}
+
+// loadRoots returns the system roots extended with the certificates of a
+// PEM file
+func loadRoots(path string) (*x509.CertPool, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		log.Debugf("Could not load the system certificates, only using %s: %s", path, err)
+		roots = x509.NewCertPool()
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the CA certificates: %s", err)
+	}
+	if !roots.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+	return roots, nil
+}
+
+// Run executes the check
+func (c *TLSCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	instance := c.cfg.instance
+
+	var tags []string
+	var certs []*x509.Certificate
+	if instance.LocalCertPath != "" {
+		tags = []string{fmt.Sprintf("local_cert_path:%s", instance.LocalCertPath)}
+		certs, err = readCertificates(instance.LocalCertPath)
+		if err != nil {
+			sender.ServiceCheck(tlsCertValidation, metrics.ServiceCheckCritical, "", tags, err.Error())
+			sender.Commit()
+			return err
+		}
+	} else {
+		tags = []string{
+			fmt.Sprintf("server:%s", instance.Server),
+			fmt.Sprintf("port:%d", instance.Port),
+			fmt.Sprintf("server_hostname:%s", instance.ServerHostname),
+		}
+		certs, err = c.fetchCertificates()
+		if err != nil {
+			sender.ServiceCheck(tlsCanConnect, metrics.ServiceCheckCritical, "", tags, err.Error())
+			sender.Commit()
+			return err
+		}
+		sender.ServiceCheck(tlsCanConnect, metrics.ServiceCheckOK, "", tags, "")
+	}
+
+	if *instance.ValidateCert {
+		if err := c.verify(certs); err != nil {
+			sender.ServiceCheck(tlsCertValidation, metrics.ServiceCheckCritical, "", tags, err.Error())
+		} else {
+			sender.ServiceCheck(tlsCertValidation, metrics.ServiceCheckOK, "", tags, "")
+		}
+	}
+
+	left := certs[0].NotAfter.Sub(timeNow())
+	daysLeft := left.Hours() / 24
+	sender.Gauge("tls.days_left", daysLeft, "", tags)
+	sender.Gauge("tls.seconds_left", left.Seconds(), "", tags)
+
+	if daysLeft <= 0 {
+		sender.ServiceCheck(tlsCertExpiration, metrics.ServiceCheckCritical, "", tags, "The certificate has expired")
+	} else if daysLeft < instance.DaysCritical {
+		sender.ServiceCheck(tlsCertExpiration, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("The certificate expires in %.1f days", daysLeft))
+	} else if daysLeft < instance.DaysWarning {
+		sender.ServiceCheck(tlsCertExpiration, metrics.ServiceCheckWarning, "", tags, fmt.Sprintf("The certificate expires in %.1f days", daysLeft))
+	} else {
+		sender.ServiceCheck(tlsCertExpiration, metrics.ServiceCheckOK, "", tags, "")
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// fetchCertificates returns the certificate chain presented by the server,
+// the chain is verified separately so that invalid certificates are still
+// reported
+func (c *TLSCheck) fetchCertificates() ([]*x509.Certificate, error) {
+	instance := c.cfg.instance
+	address := net.JoinHostPort(instance.Server, strconv.Itoa(instance.Port))
+	dialer := &net.Dialer{Timeout: time.Duration(instance.Timeout) * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         instance.ServerHostname,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %s", address, err)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate presented by %s", address)
+	}
+	return certs, nil
+}
+
+// readCertificates reads the PEM certificates of a file, the first one is
+// the certificate to check and the next ones are its intermediates
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the certificate: %s", err)
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the certificate: %s", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+	return certs, nil
+}
+
+// verify verifies the chain of the certificate against the roots, and its
+// hostname when enabled
+func (c *TLSCheck) verify(certs []*x509.Certificate) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	options := x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		CurrentTime:   timeNow(),
+	}
+	if *c.cfg.instance.ValidateHostname {
+		options.DNSName = c.cfg.instance.ServerHostname
+	}
+	_, err := certs[0].Verify(options)
+	return err
+}
+
+func tlsFactory() check.Check {
+	return &TLSCheck{
+		CheckBase: core.NewCheckBase(tlsCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(tlsCheckName, tlsFactory)
+}
@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package net
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate valid for the given number of days,
+// signed by the parent or self-signed as a CA without parent
+func newTestCert(t *testing.T, name string, days int, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    timeNow().Add(-24 * time.Hour),
+		NotAfter:     timeNow().Add(time.Duration(days) * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		template.DNSNames = []string{name}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func writePEM(t *testing.T, dir, name string, certs ...*testCert) string {
+	var content []byte
+	for _, c := range certs {
+		content = append(content, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})...)
+	}
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, content, 0644))
+	return path
+}
+
+// serveTLS serves the certificate until the listener is closed
+func serveTLS(t *testing.T, leaf *testCert) net.Listener {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}},
+	})
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return listener
+}
+
+func runTLSCheck(t *testing.T, config string) (*mocksender.MockSender, error) {
+	check := tlsFactory().(*TLSCheck)
+	require.NoError(t, check.Configure([]byte(config), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	return sender, check.Run()
+}
+
+func TestTLSServer(t *testing.T) {
+	// the certificates validity has a second precision
+	now := time.Now().Truncate(time.Second)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	dir, err := ioutil.TempDir("", "tls-check")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "Test CA", 365, nil)
+	caPath := writePEM(t, dir, "ca.pem", ca)
+	listener := serveTLS(t, newTestCert(t, "example.com", 30, ca))
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tags := []string{"server:127.0.0.1", fmt.Sprintf("port:%d", port), "server_hostname:example.com"}
+	config := fmt.Sprintf("server: 127.0.0.1\nport: %d\nserver_hostname: example.com\n", port)
+
+	// Valid certificate
+	sender, err := runTLSCheck(t, config+"ca_cert: "+caPath)
+	assert.NoError(t, err)
+	sender.AssertServiceCheck(t, tlsCanConnect, metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertServiceCheck(t, tlsCertValidation, metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertServiceCheck(t, tlsCertExpiration, metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertMetric(t, "Gauge", "tls.days_left", 30, "", tags)
+	sender.AssertMetric(t, "Gauge", "tls.seconds_left", 30*24*3600, "", tags)
+
+	// Unknown authority
+	sender, err = runTLSCheck(t, config)
+	assert.NoError(t, err)
+	sender.AssertCalled(t, "ServiceCheck", tlsCertValidation, metrics.ServiceCheckCritical, "", tags, mock.Anything)
+	sender.AssertMetric(t, "Gauge", "tls.days_left", 30, "", tags)
+
+	// Wrong hostname, unless its validation is disabled
+	otherConfig := fmt.Sprintf("server: 127.0.0.1\nport: %d\nserver_hostname: other.com\nca_cert: %s\n", port, caPath)
+	otherTags := []string{"server:127.0.0.1", fmt.Sprintf("port:%d", port), "server_hostname:other.com"}
+	sender, err = runTLSCheck(t, otherConfig)
+	assert.NoError(t, err)
+	sender.AssertCalled(t, "ServiceCheck", tlsCertValidation, metrics.ServiceCheckCritical, "", otherTags, mock.Anything)
+
+	sender, err = runTLSCheck(t, otherConfig+"validate_hostname: false")
+	assert.NoError(t, err)
+	sender.AssertServiceCheck(t, tlsCertValidation, metrics.ServiceCheckOK, "", otherTags, "")
+}
+
+func TestTLSServerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	tags := []string{"server:127.0.0.1", fmt.Sprintf("port:%d", port), "server_hostname:127.0.0.1"}
+	sender, err := runTLSCheck(t, fmt.Sprintf("server: 127.0.0.1\nport: %d\ntimeout: 1", port))
+	assert.Error(t, err)
+	sender.AssertCalled(t, "ServiceCheck", tlsCanConnect, metrics.ServiceCheckCritical, "", tags, mock.Anything)
+	sender.AssertNotCalled(t, "Gauge", "tls.days_left", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTLSLocalCert(t *testing.T) {
+	// the certificates validity has a second precision
+	now := time.Now().Truncate(time.Second)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	dir, err := ioutil.TempDir("", "tls-check")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := newTestCert(t, "Test CA", 365, nil)
+	caPath := writePEM(t, dir, "ca.pem", root)
+
+	for _, tc := range []struct {
+		days   int
+		status metrics.ServiceCheckStatus
+	}{
+		{days: 30, status: metrics.ServiceCheckOK},
+		{days: 10, status: metrics.ServiceCheckWarning},
+		{days: 3, status: metrics.ServiceCheckCritical},
+		{days: -1, status: metrics.ServiceCheckCritical},
+	} {
+		t.Run(fmt.Sprintf("%d days", tc.days), func(t *testing.T) {
+			// the file holds the chain, starting with the leaf
+			path := writePEM(t, dir, "cert.pem", newTestCert(t, "example.com", tc.days, root), root)
+			tags := []string{"local_cert_path:" + path}
+
+			sender, err := runTLSCheck(t, fmt.Sprintf("local_cert_path: %s\nca_cert: %s", path, caPath))
+			assert.NoError(t, err)
+			sender.AssertMetric(t, "Gauge", "tls.days_left", float64(tc.days), "", tags)
+			sender.AssertCalled(t, "ServiceCheck", tlsCertExpiration, tc.status, "", tags, mock.Anything)
+			if tc.days > 0 {
+				sender.AssertServiceCheck(t, tlsCertValidation, metrics.ServiceCheckOK, "", tags, "")
+			} else {
+				sender.AssertCalled(t, "ServiceCheck", tlsCertValidation, metrics.ServiceCheckCritical, "", tags, mock.Anything)
+			}
+		})
+	}
+
+	// Missing file
+	tags := []string{"local_cert_path:" + filepath.Join(dir, "missing.pem")}
+	sender, err := runTLSCheck(t, "local_cert_path: "+filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+	sender.AssertCalled(t, "ServiceCheck", tlsCertValidation, metrics.ServiceCheckCritical, "", tags, mock.Anything)
+}
+
+func TestTLSConfig(t *testing.T) {
+	for _, config := range []string{
+		"",
+		"server: example.com\nlocal_cert_path: /etc/ssl/cert.pem",
+	} {
+		check := tlsFactory().(*TLSCheck)
+		assert.Error(t, check.Configure([]byte(config), nil))
+	}
+
+	cfg := &tlsConfig{}
+	require.NoError(t, cfg.parse([]byte("server: example.com")))
+	assert.Equal(t, 443, cfg.instance.Port)
+	assert.Equal(t, "example.com", cfg.instance.ServerHostname)
+	assert.True(t, *cfg.instance.ValidateCert)
+	assert.True(t, *cfg.instance.ValidateHostname)
+	assert.Equal(t, float64(14), cfg.instance.DaysWarning)
+	assert.Equal(t, float64(7), cfg.instance.DaysCritical)
+}
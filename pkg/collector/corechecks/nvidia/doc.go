@@ -3,8 +3,8 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-2019 Datadog, Inc.
 
-// +build jmx
+/*
+Package nvidia provides core checks for NVIDIA GPUs
 
-package jmxfetch
-
-func (j *JMXFetch) Monitor() {}
+*/
+package nvidia
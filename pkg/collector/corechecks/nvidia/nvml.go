@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build linux,nvml
+
+package nvidia
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NVIDIA/gpu-monitoring-tools/bindings/go/nvml"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	nvmlCheckName = "nvml"
+	mib           = 1024 * 1024
+)
+
+// For testing purpose
+var (
+	nvmlInit         = nvml.Init
+	nvmlShutdown     = nvml.Shutdown
+	nvmlDeviceCount  = nvml.GetDeviceCount
+	nvmlNewDevice    = nvml.NewDevice
+	nvmlDeviceStatus = (*nvml.Device).Status
+	entityForPID     = containers.EntityForPID
+	entityTags       = tagger.Tag
+)
+
+// NVMLCheck reports the usage of the NVIDIA GPUs of the host, read from the
+// NVIDIA Management Library, and the GPU memory used by the containers.
+type NVMLCheck struct {
+	core.CheckBase
+	sync.Mutex
+	initialized bool
+	devices     []*nvml.Device
+}
+
+// Run executes the check
+func (c *NVMLCheck) Run() error {
+	c.Lock()
+	defer c.Unlock()
+
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	for i, device := range c.devices {
+		tags := deviceTags(i, device)
+		status, err := nvmlDeviceStatus(device)
+		if err != nil {
+			log.Warnf("Could not get the status of the GPU %d: %s", i, err)
+			continue
+		}
+		submitDeviceMetrics(sender, device, status, tags)
+		submitProcessMetrics(sender, status.Processes, tags)
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func deviceTags(index int, device *nvml.Device) []string {
+	tags := []string{
+		fmt.Sprintf("gpu_index:%d", index),
+		fmt.Sprintf("gpu_uuid:%s", device.UUID),
+	}
+	if device.Model != nil {
+		tags = append(tags, fmt.Sprintf("gpu_model:%s", *device.Model))
+	}
+	return tags
+}
+
+// submitDeviceMetrics submits the metrics of a GPU, the values the device
+// doesn't support are nil
+func submitDeviceMetrics(sender aggregator.Sender, device *nvml.Device, status *nvml.DeviceStatus, tags []string) {
+	gaugeUint := func(name string, value *uint) {
+		if value != nil {
+			sender.Gauge(name, float64(*value), "", tags)
+		}
+	}
+	gaugeMiB := func(name string, value *uint64) {
+		if value != nil {
+			sender.Gauge(name, float64(*value)*mib, "", tags)
+		}
+	}
+
+	gaugeUint("nvidia.gpu.utilization", status.Utilization.GPU)
+	gaugeUint("nvidia.gpu.memory.utilization", status.Utilization.Memory)
+	gaugeUint("nvidia.gpu.encoder.utilization", status.Utilization.Encoder)
+	gaugeUint("nvidia.gpu.decoder.utilization", status.Utilization.Decoder)
+	gaugeMiB("nvidia.gpu.memory.used", status.Memory.Global.Used)
+	gaugeMiB("nvidia.gpu.memory.free", status.Memory.Global.Free)
+	gaugeMiB("nvidia.gpu.memory.total", device.Memory)
+	gaugeUint("nvidia.gpu.temperature", status.Temperature)
+	gaugeUint("nvidia.gpu.fan_speed", status.FanSpeed)
+	gaugeUint("nvidia.gpu.power.usage", status.Power)
+	gaugeUint("nvidia.gpu.power.limit", device.Power)
+	gaugeUint("nvidia.gpu.clock.cores", status.Clocks.Cores)
+	gaugeUint("nvidia.gpu.clock.memory", status.Clocks.Memory)
+}
+
+// submitProcessMetrics submits the GPU memory used by the processes, summed
+// by container. The processes running on the host are tagged with their
+// name.
+func submitProcessMetrics(sender aggregator.Sender, processes []nvml.ProcessInfo, tags []string) {
+	usages := make(map[string]uint64)
+	usageTags := make(map[string][]string)
+	for _, p := range processes {
+		processTags, err := processTags(p)
+		if err != nil {
+			log.Debugf("Could not get the tags of the process %d: %s", p.PID, err)
+		}
+		sort.Strings(processTags)
+		key := strings.Join(processTags, ",")
+		usages[key] += p.MemoryUsed
+		usageTags[key] = processTags
+	}
+
+	for key, used := range usages {
+		sender.Gauge("nvidia.gpu.process.memory.used", float64(used)*mib, "", append(append([]string{}, tags...), usageTags[key]...))
+	}
+}
+
+// processTags returns the tags of the container of a process, or the name of
+// the process when it runs on the host
+func processTags(p nvml.ProcessInfo) ([]string, error) {
+	entity, err := entityForPID(int32(p.PID))
+	if err == containers.ErrNoContainerMatch {
+		return []string{fmt.Sprintf("process_name:%s", p.Name)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entityTags(entity, collectors.HighCardinality)
+}
+
+// Configure configures the nvml check
+func (c *NVMLCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// the check is configured again when it's reloaded, NVML counts the
+	// initializations so the previous one is released first
+	c.shutdown()
+	if err := nvmlInit(); err != nil {
+		return fmt.Errorf("could not initialize NVML, is the NVIDIA driver installed? %s", err)
+	}
+	c.initialized = true
+
+	count, err := nvmlDeviceCount()
+	if err != nil {
+		c.shutdown()
+		return fmt.Errorf("could not count the GPUs: %s", err)
+	}
+	c.devices = make([]*nvml.Device, 0, count)
+	for i := uint(0); i < count; i++ {
+		device, err := nvmlNewDevice(i)
+		if err != nil {
+			c.shutdown()
+			return fmt.Errorf("could not get the GPU %d: %s", i, err)
+		}
+		c.devices = append(c.devices, device)
+	}
+	return nil
+}
+
+// Stop releases NVML
+func (c *NVMLCheck) Stop() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.shutdown()
+}
+
+// shutdown releases NVML if the check initialized it, the lock must be held
+func (c *NVMLCheck) shutdown() {
+	if !c.initialized {
+		return
+	}
+	if err := nvmlShutdown(); err != nil {
+		log.Warnf("Could not shut down NVML: %s", err)
+	}
+	c.initialized = false
+	c.devices = nil
+}
+
+func nvmlFactory() check.Check {
+	return &NVMLCheck{
+		CheckBase: core.NewCheckBase(nvmlCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(nvmlCheckName, nvmlFactory)
+}
@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build linux,nvml
+
+package nvidia
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/NVIDIA/gpu-monitoring-tools/bindings/go/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func uintPtr(v uint) *uint       { return &v }
+func uint64Ptr(v uint64) *uint64 { return &v }
+func stringPtr(v string) *string { return &v }
+
+func mockNVML(devices []*nvml.Device, statuses map[string]*nvml.DeviceStatus) func() {
+	nvmlInit = func() error { return nil }
+	nvmlShutdown = func() error { return nil }
+	nvmlDeviceCount = func() (uint, error) { return uint(len(devices)), nil }
+	nvmlNewDevice = func(idx uint) (*nvml.Device, error) { return devices[idx], nil }
+	nvmlDeviceStatus = func(d *nvml.Device) (*nvml.DeviceStatus, error) {
+		status, found := statuses[d.UUID]
+		if !found {
+			return nil, fmt.Errorf("GPU lost")
+		}
+		return status, nil
+	}
+	entityForPID = func(pid int32) (string, error) {
+		switch pid {
+		case 100, 101:
+			return "docker://abc", nil
+		case 200:
+			return "", containers.ErrNoRuntimeMatch
+		default:
+			return "", containers.ErrNoContainerMatch
+		}
+	}
+	entityTags = func(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+		return []string{"container_name:trainer", "image_name:tensorflow"}, nil
+	}
+
+	return func() {
+		nvmlInit = nvml.Init
+		nvmlShutdown = nvml.Shutdown
+		nvmlDeviceCount = nvml.GetDeviceCount
+		nvmlNewDevice = nvml.NewDevice
+		nvmlDeviceStatus = (*nvml.Device).Status
+		entityForPID = containers.EntityForPID
+		entityTags = tagger.Tag
+	}
+}
+
+func TestNVMLCheck(t *testing.T) {
+	devices := []*nvml.Device{
+		{UUID: "GPU-1", Model: stringPtr("Tesla V100-SXM2-16GB"), Memory: uint64Ptr(16160), Power: uintPtr(300)},
+		{UUID: "GPU-2"},
+	}
+	statuses := map[string]*nvml.DeviceStatus{
+		"GPU-1": {
+			Power:       uintPtr(60),
+			Temperature: uintPtr(45),
+			Utilization: nvml.UtilizationInfo{GPU: uintPtr(87), Memory: uintPtr(40)},
+			Memory:      nvml.MemoryInfo{Global: nvml.DeviceMemory{Used: uint64Ptr(1200), Free: uint64Ptr(14960)}},
+			Processes: []nvml.ProcessInfo{
+				{PID: 100, Name: "python", MemoryUsed: 500},
+				{PID: 101, Name: "python", MemoryUsed: 300},
+				{PID: 42, Name: "Xorg", MemoryUsed: 200},
+				{PID: 200, Name: "python", MemoryUsed: 200},
+			},
+		},
+	}
+	defer mockNVML(devices, statuses)()
+
+	check := nvmlFactory().(*NVMLCheck)
+	require.NoError(t, check.Configure(nil, nil))
+	require.Len(t, check.devices, 2)
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	tags := []string{"gpu_index:0", "gpu_uuid:GPU-1", "gpu_model:Tesla V100-SXM2-16GB"}
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.utilization", 87, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.memory.utilization", 40, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.memory.used", 1200*mib, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.memory.free", 14960*mib, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.memory.total", 16160*mib, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.temperature", 45, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.power.usage", 60, "", tags)
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.power.limit", 300, "", tags)
+	// not supported by the device
+	sender.AssertNotCalled(t, "Gauge", "nvidia.gpu.fan_speed", mock.Anything, mock.Anything, mock.Anything)
+
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.process.memory.used", 800*mib, "", append(tags, "container_name:trainer", "image_name:tensorflow"))
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.process.memory.used", 200*mib, "", append(tags, "process_name:Xorg"))
+	sender.AssertMetric(t, "Gauge", "nvidia.gpu.process.memory.used", 200*mib, "", tags)
+
+	// the status of the second GPU can't be read
+	sender.AssertNotCalled(t, "Gauge", "nvidia.gpu.utilization", mock.Anything, mock.Anything, []string{"gpu_index:1", "gpu_uuid:GPU-2"})
+}
+
+func TestNVMLCheckNoDriver(t *testing.T) {
+	defer mockNVML(nil, nil)()
+	nvmlInit = func() error { return fmt.Errorf("could not load NVML library") }
+
+	check := nvmlFactory().(*NVMLCheck)
+	assert.Error(t, check.Configure(nil, nil))
+}
+
+func TestNVMLCheckShutdown(t *testing.T) {
+	defer mockNVML([]*nvml.Device{{UUID: "GPU-1"}}, nil)()
+	initialized := 0
+	nvmlInit = func() error {
+		initialized++
+		return nil
+	}
+	nvmlShutdown = func() error {
+		initialized--
+		return nil
+	}
+
+	check := nvmlFactory().(*NVMLCheck)
+	require.NoError(t, check.Configure(nil, nil))
+	assert.Equal(t, 1, initialized)
+
+	// reloading the check releases the previous initialization
+	require.NoError(t, check.Configure(nil, nil))
+	assert.Equal(t, 1, initialized)
+
+	check.Stop()
+	assert.Equal(t, 0, initialized)
+	assert.Empty(t, check.devices)
+	check.Stop()
+	assert.Equal(t, 0, initialized)
+
+	// NVML is released when the GPUs can't be listed
+	nvmlDeviceCount = func() (uint, error) { return 0, fmt.Errorf("driver mismatch") }
+	assert.Error(t, check.Configure(nil, nil))
+	assert.Equal(t, 0, initialized)
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	collectorPath = "/api/v1/collector"
+	httpTimeout   = 20 * time.Second
+)
+
+// collectorClient posts process payloads to the process intake, the way the
+// process agent does
+type collectorClient struct {
+	client   *http.Client
+	url      string
+	apiKey   string
+	hostname string
+}
+
+func newCollectorClient(hostname string) *collectorClient {
+	return &collectorClient{
+		client:   &http.Client{Timeout: httpTimeout, Transport: util.CreateHTTPTransport()},
+		url:      config.GetMainEndpoint("https://process.", "process_config.process_dd_url") + collectorPath,
+		apiKey:   config.Datadog.GetString("api_key"),
+		hostname: hostname,
+	}
+}
+
+// post sends a payload and checks the response of the intake. The payload
+// isn't compressed: the zstd version of the process agent payloads can't be
+// linked in the agent.
+func (c *collectorClient) post(payload *model.CollectorProc) error {
+	body, err := model.EncodeMessage(model.Message{
+		Header: model.MessageHeader{
+			Version:  model.MessageV3,
+			Encoding: model.MessageEncodingProtobuf,
+			Type:     model.TypeCollectorProc,
+		},
+		Body: payload,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode the payload: %s", err)
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("X-Dd-APIKey", c.apiKey)
+	req.Header.Add("X-Dd-Hostname", c.hostname)
+	req.Header.Add("X-Dd-Processagentversion", version.AgentVersion)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not submit the payload to %s: %s", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return fmt.Errorf("unexpected response from %s: %s", c.url, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	msg, err := model.DecodeMessage(data)
+	if err != nil {
+		// the payload was accepted
+		log.Debugf("Could not decode the response of the intake: %s", err)
+		return nil
+	}
+	if res, ok := msg.Body.(*model.ResCollector); ok && res.Message != "" {
+		return fmt.Errorf("error in the response of the intake: %s", res.Message)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processes
+
+import (
+	"sort"
+
+	"github.com/shirou/gopsutil/process"
+
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// For testing purpose
+var (
+	sampleProcesses = gopsutilSamples
+	describeProcess = gopsutilDescription
+)
+
+// procSample is the resource usage of a process, the cheap part of its
+// description read for every process to select the top ones
+type procSample struct {
+	pid        int32
+	createTime int64
+	userTime   float64 // seconds
+	systemTime float64 // seconds
+	rss        uint64
+	vms        uint64
+	swap       uint64
+}
+
+// procUsage is the usage of a process between two samples
+type procUsage struct {
+	sample    *procSample
+	userPct   float64
+	systemPct float64
+}
+
+func (u procUsage) totalPct() float64 {
+	return u.userPct + u.systemPct
+}
+
+// procDescription is the part of the description of a process only read for
+// the processes reported
+type procDescription struct {
+	cmdline    []string
+	exe        string
+	cwd        string
+	ppid       int32
+	user       string
+	uid        int32
+	gid        int32
+	numThreads int32
+	nice       int32
+	status     string
+}
+
+// gopsutilSamples samples the processes of the host, by pid. The processes
+// exiting while they are read are skipped.
+func gopsutilSamples() (map[int32]*procSample, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	samples := make(map[int32]*procSample, len(procs))
+	for _, p := range procs {
+		createTime, err := p.CreateTime()
+		if err != nil {
+			continue
+		}
+		times, err := p.Times()
+		if err != nil {
+			continue
+		}
+		mem, err := p.MemoryInfo()
+		if err != nil {
+			continue
+		}
+		samples[p.Pid] = &procSample{
+			pid:        p.Pid,
+			createTime: createTime,
+			userTime:   times.User,
+			systemTime: times.System,
+			rss:        mem.RSS,
+			vms:        mem.VMS,
+			swap:       mem.Swap,
+		}
+	}
+	return samples, nil
+}
+
+// gopsutilDescription describes a process, only its command line is required
+// the other fields aren't available on every platform or to every user.
+func gopsutilDescription(pid int32) (*procDescription, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	cmdline, err := p.CmdlineSlice()
+	if err != nil {
+		return nil, err
+	}
+	d := &procDescription{cmdline: cmdline}
+	d.exe, _ = p.Exe()
+	d.cwd, _ = p.Cwd()
+	d.ppid, _ = p.Ppid()
+	d.user, _ = p.Username()
+	if uids, err := p.Uids(); err == nil && len(uids) > 0 {
+		d.uid = uids[0]
+	}
+	if gids, err := p.Gids(); err == nil && len(gids) > 0 {
+		d.gid = gids[0]
+	}
+	d.numThreads, _ = p.NumThreads()
+	d.nice, _ = p.Nice()
+	d.status, _ = p.Status()
+	return d, nil
+}
+
+// computeUsages returns the CPU usage of the processes sampled twice, in
+// percent of a core like top, over the elapsed seconds
+func computeUsages(samples, lastSamples map[int32]*procSample, elapsed float64) []procUsage {
+	usages := make([]procUsage, 0, len(samples))
+	for pid, s := range samples {
+		last, found := lastSamples[pid]
+		// the pid may have been reused
+		if !found || last.createTime != s.createTime {
+			continue
+		}
+		usage := procUsage{sample: s}
+		if elapsed > 0 {
+			usage.userPct = clampPct((s.userTime - last.userTime) / elapsed * 100)
+			usage.systemPct = clampPct((s.systemTime - last.systemTime) / elapsed * 100)
+		}
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+func clampPct(pct float64) float64 {
+	if pct < 0 {
+		return 0
+	}
+	return pct
+}
+
+// topUsages returns the union of the top processes by CPU and by RSS, the
+// processes using the most CPU first
+func topUsages(usages []procUsage, top int) []procUsage {
+	if len(usages) <= top {
+		sort.Slice(usages, func(i, j int) bool { return usages[i].totalPct() > usages[j].totalPct() })
+		return usages
+	}
+
+	selected := make(map[int32]bool, 2*top)
+	sort.Slice(usages, func(i, j int) bool { return usages[i].sample.rss > usages[j].sample.rss })
+	for _, u := range usages[:top] {
+		selected[u.sample.pid] = true
+	}
+
+	sort.Slice(usages, func(i, j int) bool { return usages[i].totalPct() > usages[j].totalPct() })
+	result := make([]procUsage, 0, 2*top)
+	for i, u := range usages {
+		if i < top || selected[u.sample.pid] {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// formatProcess builds the payload of a process, nil if it can't be described
+func formatProcess(u procUsage, scrubber *argsScrubber) *model.Process {
+	d, err := describeProcess(u.sample.pid)
+	if err != nil {
+		log.Debugf("Could not describe the process %d: %s", u.sample.pid, err)
+		return nil
+	}
+	// kernel threads have no command line
+	if len(d.cmdline) == 0 {
+		return nil
+	}
+
+	return &model.Process{
+		Pid: u.sample.pid,
+		Command: &model.Command{
+			Args: scrubber.scrub(d.cmdline),
+			Cwd:  d.cwd,
+			Ppid: d.ppid,
+			Exe:  d.exe,
+		},
+		User: &model.ProcessUser{
+			Name: d.user,
+			Uid:  d.uid,
+			Gid:  d.gid,
+		},
+		Memory: &model.MemoryStat{
+			Rss:  u.sample.rss,
+			Vms:  u.sample.vms,
+			Swap: u.sample.swap,
+		},
+		Cpu: &model.CPUStat{
+			TotalPct:   float32(u.totalPct()),
+			UserPct:    float32(u.userPct),
+			SystemPct:  float32(u.systemPct),
+			NumThreads: d.numThreads,
+			Cpus:       []*model.SingleCPUStat{},
+			Nice:       d.nice,
+			UserTime:   int64(u.sample.userTime),
+			SystemTime: int64(u.sample.systemTime),
+		},
+		CreateTime: u.sample.createTime,
+		State:      model.ProcessState(model.ProcessState_value[d.status]),
+	}
+}
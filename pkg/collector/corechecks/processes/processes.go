@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processes
+
+import (
+	"fmt"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	liveProcessesCheckName = "live_processes"
+	defaultTop             = 25
+	maxTop                 = 500
+)
+
+type liveProcessesConfig struct {
+	Top                  int      `yaml:"top"`
+	ScrubArgs            *bool    `yaml:"scrub_args"`
+	CustomSensitiveWords []string `yaml:"custom_sensitive_words"`
+	StripProcArguments   bool     `yaml:"strip_proc_arguments"`
+}
+
+// LiveProcessesCheck reports the top processes of the host by CPU and by
+// memory to the live process view, for the hosts not running the process
+// agent. The first run only samples the processes, the CPU usage is
+// computed from the second one.
+type LiveProcessesCheck struct {
+	core.CheckBase
+	top         int
+	scrubber    *argsScrubber
+	client      *collectorClient
+	hostname    string
+	sysInfo     *model.SystemInfo
+	lastSamples map[int32]*procSample
+	lastRun     time.Time
+	groupID     int32
+}
+
+// Run executes the check
+func (c *LiveProcessesCheck) Run() error {
+	now := time.Now()
+	samples, err := sampleProcesses()
+	if err != nil {
+		return fmt.Errorf("could not list the processes: %s", err)
+	}
+	lastSamples, lastRun := c.lastSamples, c.lastRun
+	c.lastSamples, c.lastRun = samples, now
+	if lastSamples == nil {
+		return nil
+	}
+
+	usages := computeUsages(samples, lastSamples, now.Sub(lastRun).Seconds())
+	procs := make([]*model.Process, 0, 2*c.top)
+	for _, u := range topUsages(usages, c.top) {
+		if p := formatProcess(u, c.scrubber); p != nil {
+			procs = append(procs, p)
+		}
+	}
+
+	if c.sysInfo == nil {
+		if c.sysInfo, err = collectSystemInfo(); err != nil {
+			log.Warnf("Could not collect the system information: %s", err)
+		}
+	}
+
+	c.groupID++
+	payload := &model.CollectorProc{
+		HostName:  c.hostname,
+		Info:      c.sysInfo,
+		Processes: procs,
+		GroupId:   c.groupID,
+		GroupSize: 1,
+	}
+	log.Debugf("Sending %d processes out of %d", len(procs), len(samples))
+	return c.client.post(payload)
+}
+
+// Configure configures the live_processes check
+func (c *LiveProcessesCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	if config.Datadog.GetString("process_config.enabled") == "true" {
+		return fmt.Errorf("the processes are already collected by the process agent, disable either of them")
+	}
+
+	conf := liveProcessesConfig{}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+	c.top = conf.Top
+	if c.top <= 0 {
+		c.top = defaultTop
+	}
+	if c.top > maxTop {
+		return fmt.Errorf("top must be lower than %d", maxTop)
+	}
+
+	scrubArgs := conf.ScrubArgs == nil || *conf.ScrubArgs
+	scrubber, err := newArgsScrubber(scrubArgs, conf.StripProcArguments, conf.CustomSensitiveWords)
+	if err != nil {
+		return err
+	}
+	c.scrubber = scrubber
+
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Error getting hostname for the live_processes check: %s", err)
+	}
+	c.hostname = hostname
+	c.client = newCollectorClient(hostname)
+	return nil
+}
+
+func liveProcessesFactory() check.Check {
+	return &LiveProcessesCheck{
+		CheckBase: core.NewCheckBase(liveProcessesCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(liveProcessesCheckName, liveProcessesFactory)
+}
@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+)
+
+func mockProcesses(samples ...map[int32]*procSample) func() {
+	run := 0
+	sampleProcesses = func() (map[int32]*procSample, error) {
+		s := samples[run]
+		run++
+		return s, nil
+	}
+	describeProcess = func(pid int32) (*procDescription, error) {
+		switch pid {
+		case 1:
+			return &procDescription{cmdline: []string{"/sbin/init"}, user: "root", status: "S"}, nil
+		case 2:
+			// kernel thread
+			return &procDescription{}, nil
+		case 3:
+			return nil, fmt.Errorf("process exited")
+		default:
+			return &procDescription{cmdline: []string{"mysqld", "--password=foo", "--port", "3306"}, user: "mysql", ppid: 1, status: "R"}, nil
+		}
+	}
+	return func() {
+		sampleProcesses = gopsutilSamples
+		describeProcess = gopsutilDescription
+	}
+}
+
+// newIntakeServer returns a server decoding the process payloads it receives
+func newIntakeServer(t *testing.T, payloads chan<- *model.CollectorProc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, collectorPath, r.URL.Path)
+		assert.Equal(t, "abcdef", r.Header.Get("X-Dd-APIKey"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		msg, err := model.DecodeMessage(body)
+		require.NoError(t, err)
+		payloads <- msg.Body.(*model.CollectorProc)
+
+		res, err := model.EncodeMessage(model.Message{
+			Header: model.MessageHeader{Version: model.MessageV3, Encoding: model.MessageEncodingProtobuf, Type: model.TypeResCollector},
+			Body:   &model.ResCollector{},
+		})
+		require.NoError(t, err)
+		w.Write(res)
+	}))
+}
+
+func TestLiveProcessesCheck(t *testing.T) {
+	first := map[int32]*procSample{
+		1: {pid: 1, createTime: 10, userTime: 1, systemTime: 1, rss: 1000},
+		2: {pid: 2, createTime: 10, systemTime: 5},
+		4: {pid: 4, createTime: 20, userTime: 10, systemTime: 2, rss: 5000},
+		5: {pid: 5, createTime: 30, userTime: 10, rss: 100},
+	}
+	second := map[int32]*procSample{
+		1: {pid: 1, createTime: 10, userTime: 1, systemTime: 1, rss: 1000},
+		2: {pid: 2, createTime: 10, systemTime: 8},
+		4: {pid: 4, createTime: 20, userTime: 12, systemTime: 2, rss: 6000},
+		// pid reused
+		5: {pid: 5, createTime: 40, userTime: 20, rss: 100},
+		// new process
+		6: {pid: 6, createTime: 50, userTime: 20, rss: 100},
+	}
+	defer mockProcesses(first, second)()
+
+	payloads := make(chan *model.CollectorProc, 1)
+	server := newIntakeServer(t, payloads)
+	defer server.Close()
+	config.Datadog.Set("process_config.process_dd_url", server.URL)
+	config.Datadog.Set("api_key", "abcdef")
+	defer config.Datadog.Set("process_config.process_dd_url", "")
+	defer config.Datadog.Set("api_key", "")
+
+	check := liveProcessesFactory().(*LiveProcessesCheck)
+	require.NoError(t, check.Configure(integration.Data("top: 1"), nil))
+
+	// the first run only samples the processes
+	require.NoError(t, check.Run())
+	assert.Len(t, payloads, 0)
+
+	check.lastRun = check.lastRun.Add(-10 * time.Second)
+	require.NoError(t, check.Run())
+	require.Len(t, payloads, 1)
+	payload := <-payloads
+
+	assert.Equal(t, int32(1), payload.GroupSize)
+	// the top process by CPU is a kernel thread, the top process by memory is kept
+	require.Len(t, payload.Processes, 1)
+	p := payload.Processes[0]
+	assert.Equal(t, int32(4), p.Pid)
+	assert.Equal(t, []string{"mysqld", "--password=********", "--port", "3306"}, p.Command.Args)
+	assert.Equal(t, "mysql", p.User.Name)
+	assert.Equal(t, uint64(6000), p.Memory.Rss)
+	assert.InDelta(t, 20, p.Cpu.TotalPct, 0.5)
+	assert.InDelta(t, 20, p.Cpu.UserPct, 0.5)
+	assert.Equal(t, model.ProcessState_R, p.State)
+}
+
+func TestTopUsages(t *testing.T) {
+	usages := []procUsage{
+		{sample: &procSample{pid: 1, rss: 100}, userPct: 50},
+		{sample: &procSample{pid: 2, rss: 500}, userPct: 1},
+		{sample: &procSample{pid: 3, rss: 200}, systemPct: 30},
+		{sample: &procSample{pid: 4, rss: 50}},
+	}
+
+	pids := func(usages []procUsage) []int32 {
+		result := []int32{}
+		for _, u := range usages {
+			result = append(result, u.sample.pid)
+		}
+		return result
+	}
+	assert.Equal(t, []int32{1, 2}, pids(topUsages(usages, 1)))
+	assert.Equal(t, []int32{1, 3, 2}, pids(topUsages(usages, 2)))
+	assert.Equal(t, []int32{1, 3, 2, 4}, pids(topUsages(usages, 10)))
+}
+
+func TestLiveProcessesConfigure(t *testing.T) {
+	check := liveProcessesFactory().(*LiveProcessesCheck)
+	require.NoError(t, check.Configure(nil, nil))
+	assert.Equal(t, defaultTop, check.top)
+
+	assert.Error(t, check.Configure(integration.Data("top: 1000"), nil))
+	assert.Error(t, check.Configure(integration.Data("custom_sensitive_words: ['pass-word']"), nil))
+
+	config.Datadog.Set("process_config.enabled", "true")
+	defer config.Datadog.Set("process_config.enabled", "")
+	assert.Error(t, check.Configure(nil, nil))
+}
+
+func TestScrubber(t *testing.T) {
+	scrubber, err := newArgsScrubber(true, false, []string{"*token", "db_*_key"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		cmdline  []string
+		expected []string
+	}{
+		{[]string{"agent", "start"}, []string{"agent", "start"}},
+		{[]string{"mysql", "--password=foo", "-u", "root"}, []string{"mysql", "--password=********", "-u", "root"}},
+		{[]string{"app", "-PASSWD", "foo"}, []string{"app", "-PASSWD", "********"}},
+		{[]string{"app --api_key:foo --secret bar"}, []string{"app", "--api_key:********", "--secret", "********"}},
+		{[]string{"app", "--github_token=foo"}, []string{"app", "--github_token=********"}},
+		{[]string{"app", "--db_mysql_key=foo"}, []string{"app", "--db_mysql_key=********"}},
+	} {
+		assert.Equal(t, tc.expected, scrubber.scrub(tc.cmdline))
+	}
+
+	disabled, err := newArgsScrubber(false, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mysql", "--password=foo"}, disabled.scrub([]string{"mysql", "--password=foo"}))
+
+	stripping, err := newArgsScrubber(true, true, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mysql"}, stripping.scrub([]string{"mysql --password=foo"}))
+
+	for _, word := range []string{"*", "a**b", "pass-word"} {
+		_, err := newArgsScrubber(true, false, []string{word})
+		assert.Error(t, err, word)
+	}
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultSensitiveWords are the words the process agent scrubs by default
+var defaultSensitiveWords = []string{
+	"password", "passwd", "mysql_pwd",
+	"access_token", "auth_token",
+	"api_key", "apikey",
+	"secret", "credentials", "stripetoken",
+}
+
+var forbiddenSymbols = regexp.MustCompile("[^a-zA-Z0-9_*]")
+
+// argsScrubber hides the values of the arguments of the command lines named
+// after a sensitive word, like the process agent does: `--password=foo` and
+// `-password foo` become `--password=********` and `-password ********`.
+type argsScrubber struct {
+	enabled   bool
+	stripArgs bool
+	patterns  []*regexp.Regexp
+}
+
+func newArgsScrubber(enabled, stripArgs bool, customWords []string) (*argsScrubber, error) {
+	s := &argsScrubber{enabled: enabled, stripArgs: stripArgs}
+	for _, word := range append(defaultSensitiveWords, customWords...) {
+		pattern, err := sensitiveWordPattern(word)
+		if err != nil {
+			return nil, err
+		}
+		s.patterns = append(s.patterns, pattern)
+	}
+	return s, nil
+}
+
+// sensitiveWordPattern compiles a sensitive word to the regex matching the
+// arguments named after it. Words are made of word characters, `*` matches
+// any part of an argument name.
+func sensitiveWordPattern(word string) (*regexp.Regexp, error) {
+	if forbiddenSymbols.MatchString(word) {
+		return nil, fmt.Errorf("invalid sensitive word %q: only alphanumeric characters, underscores and wildcards are allowed", word)
+	}
+	if strings.Trim(word, "*") == "" || strings.Contains(word, "**") {
+		return nil, fmt.Errorf("invalid sensitive word %q: wildcards must be single and next to other characters", word)
+	}
+
+	var enhanced strings.Builder
+	for i, r := range word {
+		switch {
+		case r != '*':
+			enhanced.WriteRune(r)
+		case i == len(word)-1:
+			enhanced.WriteString("[^ =:]*")
+		default:
+			enhanced.WriteString("[^\\s=:$/]*")
+		}
+	}
+	return regexp.Compile("(?P<key>( +| -{1,2})(?i)" + enhanced.String() + ")(?P<delimiter> +|=|:)(?P<value>[^\\s]*)")
+}
+
+// scrub returns the command line to report
+func (s *argsScrubber) scrub(cmdline []string) []string {
+	if s.stripArgs {
+		// the whole command line may be in the first element
+		if len(cmdline) > 0 {
+			return []string{strings.Split(cmdline[0], " ")[0]}
+		}
+		return cmdline
+	}
+	if !s.enabled {
+		return cmdline
+	}
+
+	raw := strings.Join(cmdline, " ")
+	changed := false
+	for _, pattern := range s.patterns {
+		if pattern.MatchString(raw) {
+			changed = true
+			raw = pattern.ReplaceAllString(raw, "${key}${delimiter}********")
+		}
+	}
+	if !changed {
+		return cmdline
+	}
+	return strings.Split(raw, " ")
+}
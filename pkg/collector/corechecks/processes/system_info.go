@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processes
+
+import (
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/mem"
+
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+)
+
+// collectSystemInfo collects the description of the host sent with the
+// processes, like the process agent does once at startup
+func collectSystemInfo() (*model.SystemInfo, error) {
+	hi, err := host.Info()
+	if err != nil {
+		return nil, err
+	}
+	cpuInfo, err := cpu.Info()
+	if err != nil {
+		return nil, err
+	}
+	mi, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, err
+	}
+	cpus := make([]*model.CPUInfo, 0, len(cpuInfo))
+	for _, c := range cpuInfo {
+		cpus = append(cpus, &model.CPUInfo{
+			Number:     c.CPU,
+			Vendor:     c.VendorID,
+			Family:     c.Family,
+			Model:      c.Model,
+			PhysicalId: c.PhysicalID,
+			CoreId:     c.CoreID,
+			Cores:      c.Cores,
+			Mhz:        int64(c.Mhz),
+			CacheSize:  c.CacheSize,
+		})
+	}
+
+	return &model.SystemInfo{
+		Uuid: hi.HostID,
+		Os: &model.OSInfo{
+			Name:          hi.OS,
+			Platform:      hi.Platform,
+			Family:        hi.PlatformFamily,
+			Version:       hi.PlatformVersion,
+			KernelVersion: hi.KernelVersion,
+		},
+		Cpus:        cpus,
+		TotalMemory: int64(mi.Total),
+	}, nil
+}
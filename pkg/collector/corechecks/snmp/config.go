@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	defaultPort               = 161
+	defaultVersion            = 2
+	defaultTimeout            = 1
+	defaultRetries            = 5
+	defaultOidBatchSize       = 10
+	defaultBulkMaxRepetitions = 10
+
+	gaugeType          = "gauge"
+	counterType        = "counter"
+	monotonicCountType = "monotonic_count"
+)
+
+// symbolConfig is an OID and the name of the metric or tag it's reported as
+type symbolConfig struct {
+	OID  string `yaml:"OID"`
+	Name string `yaml:"name"`
+}
+
+// metricTagConfig tags the metrics of a table rows with either a column of
+// the table or a component of the row index, starting at 1
+type metricTagConfig struct {
+	Tag    string       `yaml:"tag"`
+	Index  int          `yaml:"index"`
+	Column symbolConfig `yaml:"column"`
+}
+
+// metricsConfig is either a scalar OID, set with `OID` and `name` or with
+// `symbol`, or columns of a table, set with `symbols`
+type metricsConfig struct {
+	OID        string            `yaml:"OID"`
+	Name       string            `yaml:"name"`
+	Symbol     symbolConfig      `yaml:"symbol"`
+	Table      symbolConfig      `yaml:"table"`
+	Symbols    []symbolConfig    `yaml:"symbols"`
+	MetricTags []metricTagConfig `yaml:"metric_tags"`
+	ForcedType string            `yaml:"forced_type"`
+}
+
+func (m *metricsConfig) isScalar() bool {
+	return m.Symbol.OID != ""
+}
+
+type profileDefinition struct {
+	SysObjectID string          `yaml:"sysobjectid"`
+	Metrics     []metricsConfig `yaml:"metrics"`
+}
+
+type profileConfig struct {
+	DefinitionFile string `yaml:"definition_file"`
+}
+
+type snmpInitConfig struct {
+	Profiles           map[string]profileConfig `yaml:"profiles"`
+	OidBatchSize       int                      `yaml:"oid_batch_size"`
+	BulkMaxRepetitions int                      `yaml:"bulk_max_repetitions"`
+}
+
+type snmpInstanceConfig struct {
+	IPAddress       string          `yaml:"ip_address"`
+	Port            uint16          `yaml:"port"`
+	SnmpVersion     int             `yaml:"snmp_version"`
+	CommunityString string          `yaml:"community_string"`
+	Timeout         int             `yaml:"timeout"`
+	Retries         int             `yaml:"retries"`
+	User            string          `yaml:"user"`
+	AuthProtocol    string          `yaml:"authProtocol"`
+	AuthKey         string          `yaml:"authKey"`
+	PrivProtocol    string          `yaml:"privProtocol"`
+	PrivKey         string          `yaml:"privKey"`
+	ContextName     string          `yaml:"context_name"`
+	Metrics         []metricsConfig `yaml:"metrics"`
+	Profile         string          `yaml:"profile"`
+}
+
+type snmpConfig struct {
+	instance           snmpInstanceConfig
+	oidBatchSize       int
+	bulkMaxRepetitions int
+	// profiles are the metrics of the devices, by profile name
+	profiles map[string]profileDefinition
+}
+
+func (c *snmpConfig) parse(data []byte, initData []byte) error {
+	instance := snmpInstanceConfig{
+		Port:        defaultPort,
+		SnmpVersion: defaultVersion,
+		Timeout:     defaultTimeout,
+		Retries:     defaultRetries,
+	}
+	initConf := snmpInitConfig{
+		OidBatchSize:       defaultOidBatchSize,
+		BulkMaxRepetitions: defaultBulkMaxRepetitions,
+	}
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(initData, &initConf); err != nil {
+		return err
+	}
+
+	if instance.IPAddress == "" {
+		return fmt.Errorf("missing ip_address")
+	}
+	switch instance.SnmpVersion {
+	case 1, 2:
+		if instance.CommunityString == "" {
+			return fmt.Errorf("missing community_string")
+		}
+	case 3:
+		if instance.User == "" {
+			return fmt.Errorf("missing user")
+		}
+	default:
+		return fmt.Errorf("unsupported snmp_version %d", instance.SnmpVersion)
+	}
+	if initConf.OidBatchSize <= 0 {
+		return fmt.Errorf("oid_batch_size must be positive")
+	}
+	if initConf.BulkMaxRepetitions <= 0 || initConf.BulkMaxRepetitions > 255 {
+		return fmt.Errorf("bulk_max_repetitions must be between 1 and 255")
+	}
+
+	for i := range instance.Metrics {
+		if err := validateMetrics(&instance.Metrics[i]); err != nil {
+			return err
+		}
+	}
+
+	profiles := make(map[string]profileDefinition, len(initConf.Profiles))
+	for name, profile := range initConf.Profiles {
+		definition, err := loadProfile(profile.DefinitionFile)
+		if err != nil {
+			return fmt.Errorf("could not load the profile %s: %s", name, err)
+		}
+		profiles[name] = definition
+	}
+	if instance.Profile != "" {
+		if _, found := profiles[instance.Profile]; !found {
+			return fmt.Errorf("unknown profile %s", instance.Profile)
+		}
+	}
+
+	c.instance = instance
+	c.oidBatchSize = initConf.OidBatchSize
+	c.bulkMaxRepetitions = initConf.BulkMaxRepetitions
+	c.profiles = profiles
+	return nil
+}
+
+// loadProfile reads the definition of a profile, relative paths are relative
+// to the profiles directory of the check configuration
+func loadProfile(definitionFile string) (profileDefinition, error) {
+	definition := profileDefinition{}
+	if definitionFile == "" {
+		return definition, fmt.Errorf("missing definition_file")
+	}
+	if !filepath.IsAbs(definitionFile) {
+		definitionFile = filepath.Join(config.Datadog.GetString("confd_path"), snmpCheckName+".d", "profiles", definitionFile)
+	}
+
+	data, err := ioutil.ReadFile(definitionFile)
+	if err != nil {
+		return definition, err
+	}
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return definition, err
+	}
+	if _, err := path.Match(definition.SysObjectID, ""); err != nil {
+		return definition, fmt.Errorf("invalid sysobjectid %s: %s", definition.SysObjectID, err)
+	}
+	for i := range definition.Metrics {
+		if err := validateMetrics(&definition.Metrics[i]); err != nil {
+			return definition, err
+		}
+	}
+	return definition, nil
+}
+
+// validateMetrics checks and normalizes the configuration of metrics. MIB
+// names can't be resolved, OIDs are required.
+func validateMetrics(m *metricsConfig) error {
+	if m.OID != "" || m.Name != "" {
+		m.Symbol = symbolConfig{OID: m.OID, Name: m.Name}
+	}
+	if m.Symbol.OID == "" && len(m.Symbols) == 0 {
+		return fmt.Errorf("either a symbol or symbols are required, with their OIDs: MIB names aren't supported")
+	}
+	if m.Symbol.OID != "" && len(m.Symbols) != 0 {
+		return fmt.Errorf("a metric can't have both a symbol and symbols")
+	}
+
+	m.Symbol.OID = normalizeOID(m.Symbol.OID)
+	if m.isScalar() && m.Symbol.Name == "" {
+		return fmt.Errorf("missing name for the OID %s", m.Symbol.OID)
+	}
+	for i := range m.Symbols {
+		m.Symbols[i].OID = normalizeOID(m.Symbols[i].OID)
+		if m.Symbols[i].OID == "" || m.Symbols[i].Name == "" {
+			return fmt.Errorf("the symbols of the table %s require an OID and a name", m.Table.Name)
+		}
+	}
+	for i := range m.MetricTags {
+		tag := &m.MetricTags[i]
+		tag.Column.OID = normalizeOID(tag.Column.OID)
+		if tag.Tag == "" {
+			return fmt.Errorf("missing tag name in the metric_tags of the table %s", m.Table.Name)
+		}
+		if (tag.Index > 0) == (tag.Column.OID != "") {
+			return fmt.Errorf("the tag %s requires either an index or a column OID", tag.Tag)
+		}
+	}
+
+	switch m.ForcedType {
+	case "", gaugeType, counterType, monotonicCountType:
+	default:
+		return fmt.Errorf("unsupported forced_type %s", m.ForcedType)
+	}
+	return nil
+}
+
+// normalizeOID strips the leading dot of an OID, as returned by the devices
+func normalizeOID(oid string) string {
+	return strings.TrimPrefix(oid, ".")
+}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// fetchResult holds the values returned by a device, by OID for the scalars
+// and by column OID and row index for the tables
+type fetchResult struct {
+	scalars map[string]gosnmp.SnmpPDU
+	columns map[string]map[string]gosnmp.SnmpPDU
+	// errors are the failed requests, the values of the other requests are
+	// still reported
+	errors []error
+}
+
+// fetch queries the scalar OIDs by batches and walks the columns of the tables
+func fetch(session snmpSession, scalarOIDs []string, columnOIDs []string, batchSize int) *fetchResult {
+	result := &fetchResult{
+		scalars: make(map[string]gosnmp.SnmpPDU, len(scalarOIDs)),
+		columns: make(map[string]map[string]gosnmp.SnmpPDU, len(columnOIDs)),
+	}
+
+	for start := 0; start < len(scalarOIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(scalarOIDs) {
+			end = len(scalarOIDs)
+		}
+		packet, err := session.Get(scalarOIDs[start:end])
+		if err != nil {
+			result.errors = append(result.errors, fmt.Errorf("could not get the OIDs %s: %s", strings.Join(scalarOIDs[start:end], ", "), err))
+			continue
+		}
+		for _, pdu := range packet.Variables {
+			if !hasValue(pdu) {
+				log.Debugf("No value for the OID %s", pdu.Name)
+				continue
+			}
+			result.scalars[normalizeOID(pdu.Name)] = pdu
+		}
+	}
+
+	for _, column := range columnOIDs {
+		pdus, err := session.Walk(column)
+		if err != nil {
+			result.errors = append(result.errors, fmt.Errorf("could not walk the OID %s: %s", column, err))
+			continue
+		}
+		rows := make(map[string]gosnmp.SnmpPDU, len(pdus))
+		prefix := column + "."
+		for _, pdu := range pdus {
+			name := normalizeOID(pdu.Name)
+			if !strings.HasPrefix(name, prefix) || !hasValue(pdu) {
+				continue
+			}
+			rows[strings.TrimPrefix(name, prefix)] = pdu
+		}
+		result.columns[column] = rows
+	}
+	return result
+}
+
+func hasValue(pdu gosnmp.SnmpPDU) bool {
+	switch pdu.Type {
+	case gosnmp.Null, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return false
+	}
+	return true
+}
+
+// metricValue converts a value to a metric value, counters are reported as
+// rates unless another type is forced
+func metricValue(pdu gosnmp.SnmpPDU) (value float64, isCounter bool, err error) {
+	switch pdu.Type {
+	case gosnmp.Counter32, gosnmp.Counter64:
+		isCounter = true
+		fallthrough
+	case gosnmp.Integer, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		value, _ = new(big.Float).SetInt(gosnmp.ToBigInt(pdu.Value)).Float64()
+		return value, isCounter, nil
+	case gosnmp.OctetString:
+		// some devices report numbers as strings
+		if b, ok := pdu.Value.([]byte); ok {
+			value, err = strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+			return value, false, err
+		}
+	}
+	return 0, false, fmt.Errorf("unsupported value type %#x", pdu.Type)
+}
+
+// tagValue converts a value to a tag value
+func tagValue(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return normalizeOID(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
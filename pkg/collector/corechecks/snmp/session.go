@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/soniah/gosnmp"
+)
+
+// For testing purpose
+var newSession = newGosnmpSession
+
+// snmpSession is the connection to a device
+type snmpSession interface {
+	Connect() error
+	Close() error
+	Get(oids []string) (*gosnmp.SnmpPacket, error)
+	// Walk returns the values of the subtree of an OID, with bulk requests
+	// from SNMP v2c on
+	Walk(rootOID string) ([]gosnmp.SnmpPDU, error)
+}
+
+type gosnmpSession struct {
+	gosnmp.GoSNMP
+}
+
+func newGosnmpSession(c *snmpConfig) (snmpSession, error) {
+	s := &gosnmpSession{gosnmp.GoSNMP{
+		Target:         c.instance.IPAddress,
+		Port:           c.instance.Port,
+		Transport:      "udp",
+		Timeout:        time.Duration(c.instance.Timeout) * time.Second,
+		Retries:        c.instance.Retries,
+		MaxOids:        c.oidBatchSize,
+		MaxRepetitions: uint8(c.bulkMaxRepetitions),
+	}}
+
+	switch c.instance.SnmpVersion {
+	case 1:
+		s.Version = gosnmp.Version1
+		s.Community = c.instance.CommunityString
+	case 2:
+		s.Version = gosnmp.Version2c
+		s.Community = c.instance.CommunityString
+	case 3:
+		params, flags, err := usmParameters(&c.instance)
+		if err != nil {
+			return nil, err
+		}
+		s.Version = gosnmp.Version3
+		s.SecurityModel = gosnmp.UserSecurityModel
+		s.SecurityParameters = params
+		s.MsgFlags = flags
+		s.ContextName = c.instance.ContextName
+	}
+	return s, nil
+}
+
+// usmParameters returns the user based security parameters of SNMP v3. The
+// protocols are named like in gosnmp or like in pysnmp, for the
+// configurations of the Python check.
+func usmParameters(instance *snmpInstanceConfig) (*gosnmp.UsmSecurityParameters, gosnmp.SnmpV3MsgFlags, error) {
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:               instance.User,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+	flags := gosnmp.NoAuthNoPriv
+
+	if instance.AuthKey != "" {
+		switch strings.ToLower(instance.AuthProtocol) {
+		case "", "md5", "usmhmacmd5authprotocol":
+			params.AuthenticationProtocol = gosnmp.MD5
+		case "sha", "usmhmacshaauthprotocol":
+			params.AuthenticationProtocol = gosnmp.SHA
+		default:
+			return nil, flags, fmt.Errorf("unsupported authProtocol %s", instance.AuthProtocol)
+		}
+		params.AuthenticationPassphrase = instance.AuthKey
+		flags = gosnmp.AuthNoPriv
+	}
+
+	if instance.PrivKey != "" {
+		if instance.AuthKey == "" {
+			return nil, flags, fmt.Errorf("privKey requires authKey")
+		}
+		switch strings.ToLower(instance.PrivProtocol) {
+		case "", "des", "usmdesprivprotocol":
+			params.PrivacyProtocol = gosnmp.DES
+		case "aes", "usmaescfb128protocol":
+			params.PrivacyProtocol = gosnmp.AES
+		default:
+			return nil, flags, fmt.Errorf("unsupported privProtocol %s", instance.PrivProtocol)
+		}
+		params.PrivacyPassphrase = instance.PrivKey
+		flags = gosnmp.AuthPriv
+	}
+	return params, flags, nil
+}
+
+func (s *gosnmpSession) Close() error {
+	if s.Conn == nil {
+		return nil
+	}
+	return s.Conn.Close()
+}
+
+func (s *gosnmpSession) Walk(rootOID string) ([]gosnmp.SnmpPDU, error) {
+	if s.Version == gosnmp.Version1 {
+		return s.WalkAll(rootOID)
+	}
+	return s.BulkWalkAll(rootOID)
+}
@@ -0,0 +1,267 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/soniah/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// the snmp name is taken by the Python check, which the Python loader
+	// would load first
+	snmpCheckName = "snmp_core"
+
+	sysObjectIDOID   = "1.3.6.1.2.1.1.2.0"
+	serviceCheckName = "snmp.can_check"
+)
+
+// SNMPCheck polls a device over SNMP. The metrics are either configured in
+// the instance or defined by a profile, selected in the configuration or
+// from the sysObjectID of the device.
+type SNMPCheck struct {
+	core.CheckBase
+	cfg *snmpConfig
+	// metrics are the resolved metrics of the device, once its profile is
+	// known
+	metrics []metricsConfig
+}
+
+// Run executes the check
+func (c *SNMPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	tags := []string{fmt.Sprintf("snmp_device:%s", c.cfg.instance.IPAddress)}
+
+	err = c.collect(sender, tags)
+	if err != nil {
+		sender.ServiceCheck(serviceCheckName, metrics.ServiceCheckCritical, "", tags, err.Error())
+	}
+	sender.Commit()
+	return err
+}
+
+// collect polls the device and submits the metrics and the service check,
+// it returns an error when the device can't be polled at all
+func (c *SNMPCheck) collect(sender aggregator.Sender, tags []string) error {
+	session, err := newSession(c.cfg)
+	if err != nil {
+		return err
+	}
+	if err := session.Connect(); err != nil {
+		return fmt.Errorf("could not connect to %s: %s", c.cfg.instance.IPAddress, err)
+	}
+	defer session.Close()
+
+	deviceMetrics, err := c.resolveMetrics(session)
+	if err != nil {
+		return err
+	}
+	scalarOIDs, columnOIDs := requestedOIDs(deviceMetrics)
+	result := fetch(session, scalarOIDs, columnOIDs, c.cfg.oidBatchSize)
+
+	requests := len(columnOIDs) + (len(scalarOIDs)+c.cfg.oidBatchSize-1)/c.cfg.oidBatchSize
+	if len(result.errors) > 0 && len(result.errors) == requests {
+		return result.errors[0]
+	}
+
+	for _, m := range deviceMetrics {
+		submitMetrics(sender, m, result, tags)
+	}
+
+	if len(result.errors) > 0 {
+		messages := make([]string, 0, len(result.errors))
+		for _, err := range result.errors {
+			messages = append(messages, err.Error())
+		}
+		sender.ServiceCheck(serviceCheckName, metrics.ServiceCheckWarning, "", tags, strings.Join(messages, "\n"))
+		return nil
+	}
+	sender.ServiceCheck(serviceCheckName, metrics.ServiceCheckOK, "", tags, "")
+	return nil
+}
+
+// resolveMetrics returns the metrics of the device. Without configured
+// metrics nor profile, the profile is detected from the sysObjectID of the
+// device at the first successful run.
+func (c *SNMPCheck) resolveMetrics(session snmpSession) ([]metricsConfig, error) {
+	if c.metrics != nil {
+		return c.metrics, nil
+	}
+
+	profile := c.cfg.instance.Profile
+	if profile == "" && len(c.cfg.instance.Metrics) == 0 {
+		packet, err := session.Get([]string{sysObjectIDOID})
+		if err != nil {
+			return nil, fmt.Errorf("could not get the sysObjectID of the device: %s", err)
+		}
+		if len(packet.Variables) != 1 || !hasValue(packet.Variables[0]) {
+			return nil, fmt.Errorf("no sysObjectID reported by the device")
+		}
+		sysObjectID := tagValue(packet.Variables[0])
+		if profile = matchProfile(c.cfg.profiles, sysObjectID); profile == "" {
+			return nil, fmt.Errorf("no profile matching the sysObjectID %s", sysObjectID)
+		}
+		log.Infof("Using the profile %s for the device %s", profile, c.cfg.instance.IPAddress)
+	}
+
+	deviceMetrics := append([]metricsConfig{}, c.cfg.instance.Metrics...)
+	if profile != "" {
+		deviceMetrics = append(deviceMetrics, c.cfg.profiles[profile].Metrics...)
+	}
+	c.metrics = deviceMetrics
+	return c.metrics, nil
+}
+
+// matchProfile returns the profile whose sysobjectid pattern matches the
+// sysObjectID of a device, the most specific one if several do
+func matchProfile(profiles map[string]profileDefinition, sysObjectID string) string {
+	matched, matchedPattern := "", ""
+	for name, profile := range profiles {
+		if ok, _ := path.Match(profile.SysObjectID, sysObjectID); !ok {
+			continue
+		}
+		if len(profile.SysObjectID) > len(matchedPattern) || (len(profile.SysObjectID) == len(matchedPattern) && name < matched) {
+			matched, matchedPattern = name, profile.SysObjectID
+		}
+	}
+	return matched
+}
+
+// requestedOIDs returns the scalar OIDs to get and the column OIDs to walk
+func requestedOIDs(deviceMetrics []metricsConfig) (scalarOIDs []string, columnOIDs []string) {
+	seen := make(map[string]bool)
+	add := func(oids []string, oid string) []string {
+		if seen[oid] {
+			return oids
+		}
+		seen[oid] = true
+		return append(oids, oid)
+	}
+
+	for _, m := range deviceMetrics {
+		if m.isScalar() {
+			scalarOIDs = add(scalarOIDs, m.Symbol.OID)
+			continue
+		}
+		for _, symbol := range m.Symbols {
+			columnOIDs = add(columnOIDs, symbol.OID)
+		}
+		for _, tag := range m.MetricTags {
+			if tag.Column.OID != "" {
+				columnOIDs = add(columnOIDs, tag.Column.OID)
+			}
+		}
+	}
+	return scalarOIDs, columnOIDs
+}
+
+func submitMetrics(sender aggregator.Sender, m metricsConfig, result *fetchResult, tags []string) {
+	if m.isScalar() {
+		if pdu, found := result.scalars[m.Symbol.OID]; found {
+			submit(sender, m.ForcedType, m.Symbol.Name, pdu, tags)
+		}
+		return
+	}
+
+	for _, symbol := range m.Symbols {
+		for index, pdu := range result.columns[symbol.OID] {
+			tableTags := append(append([]string{}, tags...), rowTags(m.MetricTags, index, result)...)
+			submit(sender, m.ForcedType, symbol.Name, pdu, tableTags)
+		}
+	}
+}
+
+// rowTags returns the tags of the row of a table
+func rowTags(metricTags []metricTagConfig, index string, result *fetchResult) []string {
+	tags := make([]string, 0, len(metricTags))
+	indexes := strings.Split(index, ".")
+	for _, tag := range metricTags {
+		if tag.Index > 0 {
+			if tag.Index > len(indexes) {
+				log.Debugf("The index %s has no component %d for the tag %s", index, tag.Index, tag.Tag)
+				continue
+			}
+			tags = append(tags, fmt.Sprintf("%s:%s", tag.Tag, indexes[tag.Index-1]))
+			continue
+		}
+		if pdu, found := result.columns[tag.Column.OID][index]; found {
+			tags = append(tags, fmt.Sprintf("%s:%s", tag.Tag, tagValue(pdu)))
+		}
+	}
+	return tags
+}
+
+func submit(sender aggregator.Sender, forcedType string, name string, pdu gosnmp.SnmpPDU, tags []string) {
+	value, isCounter, err := metricValue(pdu)
+	if err != nil {
+		log.Debugf("Skipping the value of %s for %s: %s", pdu.Name, name, err)
+		return
+	}
+	metricType := forcedType
+	if metricType == "" {
+		metricType = gaugeType
+		if isCounter {
+			metricType = counterType
+		}
+	}
+
+	name = "snmp." + name
+	switch metricType {
+	case counterType:
+		sender.Rate(name, value, "", tags)
+	case monotonicCountType:
+		sender.MonotonicCount(name, value, "", tags)
+	default:
+		sender.Gauge(name, value, "", tags)
+	}
+}
+
+// Configure configures the snmp_core check
+func (c *SNMPCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	cfg := &snmpConfig{}
+	if err := cfg.parse(data, initConfig); err != nil {
+		return err
+	}
+	if len(cfg.instance.Metrics) == 0 && cfg.instance.Profile == "" && len(cfg.profiles) == 0 {
+		return fmt.Errorf("no metrics nor profiles configured")
+	}
+	// checks the SNMP v3 parameters
+	if _, err := newSession(cfg); err != nil {
+		return err
+	}
+
+	c.cfg = cfg
+	c.metrics = nil
+	return nil
+}
+
+func snmpFactory() check.Check {
+	return &SNMPCheck{
+		CheckBase: core.NewCheckBase(snmpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(snmpCheckName, snmpFactory)
+}
@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// fakeSession is a device exposing a fixed set of values
+type fakeSession struct {
+	values map[string]gosnmp.SnmpPDU
+	// failing are the OIDs whose requests fail
+	failing map[string]bool
+}
+
+func newFakeSession(pdus ...gosnmp.SnmpPDU) *fakeSession {
+	s := &fakeSession{values: make(map[string]gosnmp.SnmpPDU), failing: make(map[string]bool)}
+	for _, pdu := range pdus {
+		s.values[pdu.Name] = pdu
+	}
+	return s
+}
+
+func (s *fakeSession) Connect() error { return nil }
+func (s *fakeSession) Close() error   { return nil }
+
+func (s *fakeSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	packet := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		if s.failing[oid] {
+			return nil, fmt.Errorf("request timeout")
+		}
+		pdu, found := s.values["."+oid]
+		if !found {
+			pdu = gosnmp.SnmpPDU{Name: "." + oid, Type: gosnmp.NoSuchObject}
+		}
+		packet.Variables = append(packet.Variables, pdu)
+	}
+	return packet, nil
+}
+
+func (s *fakeSession) Walk(rootOID string) ([]gosnmp.SnmpPDU, error) {
+	if s.failing[rootOID] {
+		return nil, fmt.Errorf("request timeout")
+	}
+	pdus := []gosnmp.SnmpPDU{}
+	for name, pdu := range s.values {
+		if strings.HasPrefix(name, "."+rootOID+".") {
+			pdus = append(pdus, pdu)
+		}
+	}
+	sort.Slice(pdus, func(i, j int) bool { return pdus[i].Name < pdus[j].Name })
+	return pdus, nil
+}
+
+func mockSession(session snmpSession) func() {
+	newSession = func(c *snmpConfig) (snmpSession, error) {
+		if _, err := newGosnmpSession(c); err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+	return func() { newSession = newGosnmpSession }
+}
+
+func routerSession() *fakeSession {
+	return newFakeSession(
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1045"},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(20)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.6.5.0", Type: gosnmp.Counter32, Value: uint(42)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: []byte("eth1")},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(100)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(200)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.16.1", Type: gosnmp.Counter64, Value: uint64(1000)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.16.2", Type: gosnmp.Counter64, Value: uint64(2000)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.4.22.1.4.3.10.0.0.1", Type: gosnmp.Integer, Value: 3},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.9.9.109.1.1.1.1.7.1", Type: gosnmp.Gauge32, Value: uint(12)},
+	)
+}
+
+func profilesInitConfig(t *testing.T) integration.Data {
+	dir, err := filepath.Abs("testdata/profiles")
+	require.NoError(t, err)
+	return integration.Data(`
+profiles:
+  generic-router:
+    definition_file: ` + filepath.Join(dir, "generic-router.yaml") + `
+  cisco-switch:
+    definition_file: ` + filepath.Join(dir, "cisco-switch.yaml"))
+}
+
+func TestSNMPCheckMetrics(t *testing.T) {
+	defer mockSession(routerSession())()
+
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure(integration.Data(`
+ip_address: 10.0.0.2
+community_string: public
+metrics:
+  - OID: .1.3.6.1.2.1.6.5.0
+    name: tcpActiveOpens
+  - symbol:
+      OID: 1.3.6.1.2.1.6.9.0
+      name: tcpCurrEstab
+  - OID: 1.3.6.1.2.1.1.3.0
+    name: sysUpTimeInstance
+    forced_type: monotonic_count
+  - table:
+      OID: 1.3.6.1.2.1.2.2
+      name: ifTable
+    symbols:
+      - OID: 1.3.6.1.2.1.2.2.1.10
+        name: ifInOctets
+    metric_tags:
+      - tag: interface
+        column:
+          OID: 1.3.6.1.2.1.2.2.1.2
+          name: ifDescr
+  - table:
+      OID: 1.3.6.1.2.1.4.22
+      name: ipNetToMediaTable
+    symbols:
+      - OID: 1.3.6.1.2.1.4.22.1.4
+        name: ipNetToMediaType
+    metric_tags:
+      - tag: interface_index
+        index: 1
+      - tag: missing_index
+        index: 10
+`), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	tags := []string{"snmp_device:10.0.0.2"}
+	sender.AssertMetric(t, "Rate", "snmp.tcpActiveOpens", 42, "", tags)
+	sender.AssertNotCalled(t, "Gauge", "snmp.tcpCurrEstab", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.sysUpTimeInstance", 20, "", tags)
+	sender.AssertMetric(t, "Rate", "snmp.ifInOctets", 100, "", []string{"snmp_device:10.0.0.2", "interface:eth0"})
+	sender.AssertMetric(t, "Rate", "snmp.ifInOctets", 200, "", []string{"snmp_device:10.0.0.2", "interface:eth1"})
+	sender.AssertMetric(t, "Gauge", "snmp.ipNetToMediaType", 3, "", []string{"snmp_device:10.0.0.2", "interface_index:3"})
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckOK, "", tags, "")
+}
+
+func TestSNMPCheckProfileDetection(t *testing.T) {
+	defer mockSession(routerSession())()
+
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure(integration.Data(`
+ip_address: 10.0.0.2
+community_string: public
+`), profilesInitConfig(t)))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	// the most specific profile is selected
+	tags := []string{"snmp_device:10.0.0.2"}
+	sender.AssertMetric(t, "Gauge", "snmp.cpmCPUTotal1minRev", 12, "", tags)
+	sender.AssertNotCalled(t, "Rate", "snmp.ifInOctets", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckOK, "", tags, "")
+}
+
+func TestSNMPCheckProfile(t *testing.T) {
+	defer mockSession(routerSession())()
+
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure(integration.Data(`
+ip_address: 10.0.0.2
+community_string: public
+profile: generic-router
+`), profilesInitConfig(t)))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", 20, "", []string{"snmp_device:10.0.0.2"})
+	sender.AssertMetric(t, "Rate", "snmp.ifOutOctets", 2000, "", []string{"snmp_device:10.0.0.2", "interface:eth1"})
+	sender.AssertNotCalled(t, "Gauge", "snmp.cpmCPUTotal1minRev", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSNMPCheckUnreachable(t *testing.T) {
+	session := routerSession()
+	defer mockSession(session)()
+
+	check := snmpFactory().(*SNMPCheck)
+	require.NoError(t, check.Configure(integration.Data(`
+ip_address: 10.0.0.2
+community_string: public
+profile: generic-router
+`), profilesInitConfig(t)))
+
+	// some requests fail
+	session.failing["1.3.6.1.2.1.2.2.1.16"] = true
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", 20, "", []string{"snmp_device:10.0.0.2"})
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckWarning, "", []string{"snmp_device:10.0.0.2"},
+		"could not walk the OID 1.3.6.1.2.1.2.2.1.16: request timeout")
+
+	// every request fails
+	for _, oid := range []string{"1.3.6.1.2.1.1.3.0", "1.3.6.1.2.1.2.2.1.10", "1.3.6.1.2.1.2.2.1.2"} {
+		session.failing[oid] = true
+	}
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	assert.Error(t, check.Run())
+	sender.AssertServiceCheck(t, "snmp.can_check", metrics.ServiceCheckCritical, "", []string{"snmp_device:10.0.0.2"},
+		"could not get the OIDs 1.3.6.1.2.1.1.3.0: request timeout")
+}
+
+func TestSNMPCheckConfigure(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		instance string
+		err      string
+	}{
+		{"no ip", "community_string: public", "missing ip_address"},
+		{"no community", "ip_address: 10.0.0.2", "missing community_string"},
+		{"no user", "ip_address: 10.0.0.2\nsnmp_version: 3", "missing user"},
+		{"bad version", "ip_address: 10.0.0.2\nsnmp_version: 4", "unsupported snmp_version 4"},
+		{"no metrics", "ip_address: 10.0.0.2\ncommunity_string: public", "no metrics nor profiles configured"},
+		{"MIB names", "ip_address: 10.0.0.2\ncommunity_string: public\nmetrics:\n  - MIB: UDP-MIB\n    name: udpInDatagrams",
+			"either a symbol or symbols are required, with their OIDs: MIB names aren't supported"},
+		{"unknown profile", "ip_address: 10.0.0.2\ncommunity_string: public\nprofile: foo", "unknown profile foo"},
+		{"bad tag", "ip_address: 10.0.0.2\ncommunity_string: public\nmetrics:\n  - symbols: [{OID: 1.2.3, name: foo}]\n    metric_tags: [{tag: bar}]",
+			"the tag bar requires either an index or a column OID"},
+		{"bad auth", "ip_address: 10.0.0.2\nsnmp_version: 3\nuser: foo\nauthKey: bar\nauthProtocol: sha512\nmetrics: [{OID: 1.2.3, name: foo}]", "unsupported authProtocol sha512"},
+		{"priv without auth", "ip_address: 10.0.0.2\nsnmp_version: 3\nuser: foo\nprivKey: bar\nmetrics: [{OID: 1.2.3, name: foo}]", "privKey requires authKey"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			check := snmpFactory().(*SNMPCheck)
+			err := check.Configure(integration.Data(tc.instance), nil)
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+		})
+	}
+}
+
+func TestUsmParameters(t *testing.T) {
+	params, flags, err := usmParameters(&snmpInstanceConfig{User: "foo", AuthKey: "bar", AuthProtocol: "usmHMACSHAAuthProtocol", PrivKey: "baz", PrivProtocol: "AES"})
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.AuthPriv, flags)
+	assert.Equal(t, gosnmp.SHA, params.AuthenticationProtocol)
+	assert.Equal(t, gosnmp.AES, params.PrivacyProtocol)
+
+	params, flags, err = usmParameters(&snmpInstanceConfig{User: "foo", AuthKey: "bar"})
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.AuthNoPriv, flags)
+	assert.Equal(t, gosnmp.MD5, params.AuthenticationProtocol)
+	assert.Equal(t, gosnmp.NoPriv, params.PrivacyProtocol)
+
+	_, flags, err = usmParameters(&snmpInstanceConfig{User: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.NoAuthNoPriv, flags)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/shirou/gopsutil/cpu"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -24,12 +25,20 @@ const cpuCheckName = "cpu"
 var times = cpu.Times
 var cpuInfo = cpu.Info
 
+type cpuInstanceConfig struct {
+	ReportPerCore       bool `yaml:"report_per_core"`
+	AggregateByNUMANode bool `yaml:"aggregate_by_numa_node"`
+}
+
 // CPUCheck doesn't need additional fields
 type CPUCheck struct {
 	core.CheckBase
-	nbCPU       float64
-	lastNbCycle float64
-	lastTimes   cpu.TimesStat
+	instance      cpuInstanceConfig
+	nbCPU         float64
+	lastNbCycle   float64
+	lastTimes     cpu.TimesStat
+	lastCoreTimes map[string]cpu.TimesStat
+	coreNodes     map[string]string
 }
 
 // Run executes the check
@@ -50,6 +59,13 @@ func (c *CPUCheck) Run() error {
 	}
 	t := cpuTimes[0]
 
+	// the per-core metrics are committed along with the global ones
+	if c.instance.ReportPerCore {
+		if err := c.collectPerCore(sender); err != nil {
+			log.Errorf("system.CPUCheck: %s", err)
+		}
+	}
+
 	nbCycle := t.Total() / c.nbCPU
 
 	if c.lastNbCycle != 0 {
@@ -83,6 +99,13 @@ func (c *CPUCheck) Configure(data integration.Data, initConfig integration.Data)
 	if err != nil {
 		return err
 	}
+	if err := yaml.Unmarshal(data, &c.instance); err != nil {
+		return err
+	}
+	if c.instance.ReportPerCore {
+		c.lastCoreTimes = make(map[string]cpu.TimesStat)
+		c.coreNodes = getCoreNodes()
+	}
 	// NOTE: This runs before the python checks, so we should be good, but cpuInfo()
 	//       on windows initializes COM to the multithreaded model. Therefore,
 	//       if a python check has run on this native windows thread prior and
@@ -0,0 +1,219 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build !windows
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/cpu"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// For testing purpose
+var sysfsPath = hostSys
+
+func hostSys() string {
+	if path := os.Getenv("HOST_SYS"); path != "" {
+		return path
+	}
+	return "/sys"
+}
+
+// corePercents holds the share of the time a core spent in each state since
+// the previous run, in percent.
+type corePercents struct {
+	user   float64
+	system float64
+	iowait float64
+	idle   float64
+	stolen float64
+	guest  float64
+}
+
+func computeCorePercents(t, last cpu.TimesStat) (corePercents, bool) {
+	total := t.Total() - last.Total()
+	if total <= 0 {
+		return corePercents{}, false
+	}
+	toPercent := 100 / total
+	return corePercents{
+		user:   ((t.User + t.Nice) - (last.User + last.Nice)) * toPercent,
+		system: ((t.System + t.Irq + t.Softirq) - (last.System + last.Irq + last.Softirq)) * toPercent,
+		iowait: (t.Iowait - last.Iowait) * toPercent,
+		idle:   (t.Idle - last.Idle) * toPercent,
+		stolen: (t.Steal - last.Steal) * toPercent,
+		guest:  (t.Guest - last.Guest) * toPercent,
+	}, true
+}
+
+func (p *corePercents) add(o corePercents) {
+	p.user += o.user
+	p.system += o.system
+	p.iowait += o.iowait
+	p.idle += o.idle
+	p.stolen += o.stolen
+	p.guest += o.guest
+}
+
+func (p corePercents) send(sender aggregator.Sender, prefix string, tags []string) {
+	sender.Gauge(prefix+".user", p.user, "", tags)
+	sender.Gauge(prefix+".system", p.system, "", tags)
+	sender.Gauge(prefix+".iowait", p.iowait, "", tags)
+	sender.Gauge(prefix+".idle", p.idle, "", tags)
+	sender.Gauge(prefix+".stolen", p.stolen, "", tags)
+	sender.Gauge(prefix+".guest", p.guest, "", tags)
+}
+
+// numaNode accumulates the metrics of the cores of a NUMA node
+type numaNode struct {
+	percents  corePercents
+	cores     int
+	frequency float64
+	freqCores int
+}
+
+// collectPerCore reports the metrics of every core, or of every NUMA node if
+// aggregateByNUMANode is set. Nothing is reported on the first run as the
+// metrics are computed from the times of the previous run.
+func (c *CPUCheck) collectPerCore(sender aggregator.Sender) error {
+	coreTimes, err := times(true)
+	if err != nil {
+		return fmt.Errorf("could not retrieve per-core cpu stats: %s", err)
+	}
+
+	nodes := make(map[string]*numaNode)
+	for _, t := range coreTimes {
+		last, found := c.lastCoreTimes[t.CPU]
+		c.lastCoreTimes[t.CPU] = t
+		if !found {
+			continue
+		}
+		percents, ok := computeCorePercents(t, last)
+		if !ok {
+			continue
+		}
+		frequency, hasFrequency := coreFrequency(t.CPU)
+		node, hasNode := c.coreNodes[t.CPU]
+
+		if !c.instance.AggregateByNUMANode {
+			tags := []string{fmt.Sprintf("core:%s", strings.TrimPrefix(t.CPU, "cpu"))}
+			if hasNode {
+				tags = append(tags, fmt.Sprintf("numa_node:%s", node))
+			}
+			percents.send(sender, "system.cpu.core", tags)
+			if hasFrequency {
+				sender.Gauge("system.cpu.core.frequency", frequency, "", tags)
+			}
+			continue
+		}
+
+		if !hasNode {
+			continue
+		}
+		n, found := nodes[node]
+		if !found {
+			n = &numaNode{}
+			nodes[node] = n
+		}
+		n.percents.add(percents)
+		n.cores++
+		if hasFrequency {
+			n.frequency += frequency
+			n.freqCores++
+		}
+	}
+
+	for node, n := range nodes {
+		tags := []string{fmt.Sprintf("numa_node:%s", node)}
+		cores := float64(n.cores)
+		corePercents{
+			user:   n.percents.user / cores,
+			system: n.percents.system / cores,
+			iowait: n.percents.iowait / cores,
+			idle:   n.percents.idle / cores,
+			stolen: n.percents.stolen / cores,
+			guest:  n.percents.guest / cores,
+		}.send(sender, "system.cpu.numa", tags)
+		if n.freqCores > 0 {
+			sender.Gauge("system.cpu.numa.frequency", n.frequency/float64(n.freqCores), "", tags)
+		}
+	}
+	return nil
+}
+
+// coreFrequency returns the current frequency of a core in MHz, it's only
+// available if the cpufreq driver is loaded.
+func coreFrequency(core string) (float64, bool) {
+	content, err := ioutil.ReadFile(filepath.Join(sysfsPath(), "devices/system/cpu", core, "cpufreq/scaling_cur_freq"))
+	if err != nil {
+		return 0, false
+	}
+	khz, err := strconv.ParseFloat(strings.TrimSpace(string(content)), 64)
+	if err != nil {
+		return 0, false
+	}
+	return khz / 1000, true
+}
+
+// getCoreNodes returns the NUMA node of every core, keyed by the core name
+// used by gopsutil (`cpu<N>`). It's empty on systems without NUMA support.
+func getCoreNodes() map[string]string {
+	coreNodes := make(map[string]string)
+	nodeDirs, err := filepath.Glob(filepath.Join(sysfsPath(), "devices/system/node/node[0-9]*"))
+	if err != nil {
+		return coreNodes
+	}
+	for _, dir := range nodeDirs {
+		node := strings.TrimPrefix(filepath.Base(dir), "node")
+		content, err := ioutil.ReadFile(filepath.Join(dir, "cpulist"))
+		if err != nil {
+			log.Debugf("system.CPUCheck: could not read the cores of the NUMA node %s: %s", node, err)
+			continue
+		}
+		cores, err := parseCPUList(strings.TrimSpace(string(content)))
+		if err != nil {
+			log.Debugf("system.CPUCheck: could not parse the cores of the NUMA node %s: %s", node, err)
+			continue
+		}
+		for _, core := range cores {
+			coreNodes[fmt.Sprintf("cpu%d", core)] = node
+		}
+	}
+	return coreNodes
+}
+
+// parseCPUList parses the lists of cores of the kernel, like `0-3,8,10-11`
+func parseCPUList(list string) ([]int, error) {
+	cores := []int{}
+	if list == "" {
+		return cores, nil
+	}
+	for _, r := range strings.Split(list, ",") {
+		bounds := strings.SplitN(r, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		for core := first; core <= last; core++ {
+			cores = append(cores, core)
+		}
+	}
+	return cores, nil
+}
@@ -7,10 +7,16 @@
 package system
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/shirou/gopsutil/cpu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -102,3 +108,113 @@ func TestCPUCheckLinux(t *testing.T) {
 	mock.AssertNumberOfCalls(t, "Gauge", 6)
 	mock.AssertNumberOfCalls(t, "Commit", 1)
 }
+
+var (
+	firstCoreSample = []cpu.TimesStat{
+		{CPU: "cpu0", User: 1000, System: 500, Idle: 8500},
+		{CPU: "cpu1", User: 2000, System: 1000, Idle: 7000},
+	}
+	secondCoreSample = []cpu.TimesStat{
+		{CPU: "cpu0", User: 1050, System: 520, Idle: 8520, Iowait: 10},
+		{CPU: "cpu1", User: 2030, System: 1010, Idle: 7060},
+	}
+)
+
+var coreSample = firstCoreSample
+
+func CPUTimesPerCore(percpu bool) ([]cpu.TimesStat, error) {
+	if percpu {
+		return coreSample, nil
+	}
+	return sample, nil
+}
+
+// writeSysfs creates a sysfs tree with cpu0 on NUMA node 0, cpu1 on NUMA
+// node 1, and the frequency of cpu0
+func writeSysfs(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "sysfs")
+	require.NoError(t, err)
+	files := map[string]string{
+		"devices/system/node/node0/cpulist":                "0\n",
+		"devices/system/node/node1/cpulist":                "1\n",
+		"devices/system/cpu/cpu0/cpufreq/scaling_cur_freq": "2400000\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestCPUCheckPerCore(t *testing.T) {
+	dir := writeSysfs(t)
+	defer os.RemoveAll(dir)
+	sysfsPath = func() string { return dir }
+	defer func() { sysfsPath = hostSys }()
+
+	times = CPUTimesPerCore
+	cpuInfo = CPUInfo
+	cpuCheck := new(CPUCheck)
+	require.NoError(t, cpuCheck.Configure(integration.Data("report_per_core: true"), nil))
+
+	mock := mocksender.NewMockSender(cpuCheck.ID())
+	mock.SetupAcceptAll()
+
+	sample, coreSample = firstSample, firstCoreSample
+	cpuCheck.Run()
+	mock.AssertNotCalled(t, "Gauge", "system.cpu.core.user", 50.0, "", []string{"core:0", "numa_node:0"})
+
+	sample, coreSample = secondSample, secondCoreSample
+	cpuCheck.Run()
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.user", 50.0, "", []string{"core:0", "numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.system", 20.0, "", []string{"core:0", "numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.iowait", 10.0, "", []string{"core:0", "numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.idle", 20.0, "", []string{"core:0", "numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.frequency", 2400.0, "", []string{"core:0", "numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.user", 30.0, "", []string{"core:1", "numa_node:1"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.core.idle", 60.0, "", []string{"core:1", "numa_node:1"})
+	mock.AssertNotCalled(t, "Gauge", "system.cpu.core.frequency", 0.0, "", []string{"core:1", "numa_node:1"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.user", 0.1913803067769472, "", []string(nil))
+}
+
+func TestCPUCheckPerNUMANode(t *testing.T) {
+	dir := writeSysfs(t)
+	defer os.RemoveAll(dir)
+	// both cores on the same node
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "devices/system/node/node0/cpulist"), []byte("0-1\n"), 0644))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "devices/system/node/node1")))
+	sysfsPath = func() string { return dir }
+	defer func() { sysfsPath = hostSys }()
+
+	times = CPUTimesPerCore
+	cpuInfo = CPUInfo
+	cpuCheck := new(CPUCheck)
+	require.NoError(t, cpuCheck.Configure(integration.Data("report_per_core: true\naggregate_by_numa_node: true"), nil))
+
+	mock := mocksender.NewMockSender(cpuCheck.ID())
+	mock.SetupAcceptAll()
+
+	sample, coreSample = firstSample, firstCoreSample
+	cpuCheck.Run()
+	sample, coreSample = secondSample, secondCoreSample
+	cpuCheck.Run()
+
+	mock.AssertMetric(t, "Gauge", "system.cpu.numa.user", 40.0, "", []string{"numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.numa.idle", 40.0, "", []string{"numa_node:0"})
+	mock.AssertMetric(t, "Gauge", "system.cpu.numa.frequency", 2400.0, "", []string{"numa_node:0"})
+	mock.AssertNotCalled(t, "Gauge", "system.cpu.core.user", 50.0, "", []string{"core:0", "numa_node:0"})
+}
+
+func TestParseCPUList(t *testing.T) {
+	cores, err := parseCPUList("0-3,8,10-11")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cores)
+
+	cores, err = parseCPUList("")
+	require.NoError(t, err)
+	assert.Empty(t, cores)
+
+	_, err = parseCPUList("0-a")
+	assert.Error(t, err)
+}
@@ -9,6 +9,7 @@ package system
 import (
 	"regexp"
 	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -21,6 +22,10 @@ const (
 	diskCheckName = "disk"
 	diskMetric    = "system.disk.%s"
 	inodeMetric   = "system.fs.inodes.%s"
+
+	// defaultDiskTimeout is how long we wait for the usage of a partition,
+	// network mounts may hang forever
+	defaultDiskTimeout = 5 * time.Second
 )
 
 type diskConfig struct {
@@ -32,6 +37,8 @@ type diskConfig struct {
 	excludedMountpointRe *regexp.Regexp
 	allPartitions        bool
 	deviceTagRe          map[*regexp.Regexp][]string
+	tagByLabel           bool
+	timeout              time.Duration
 }
 
 func (c *DiskCheck) excludeDisk(mountpoint, device, fstype string) bool {
@@ -80,7 +87,10 @@ func (c *DiskCheck) excludeDisk(mountpoint, device, fstype string) bool {
 
 func (c *DiskCheck) instanceConfigure(data integration.Data) error {
 	conf := make(map[interface{}]interface{})
-	c.cfg = &diskConfig{}
+	c.cfg = &diskConfig{
+		tagByLabel: true,
+		timeout:    defaultDiskTimeout,
+	}
 	err := yaml.Unmarshal([]byte(data), &conf)
 	if err != nil {
 		return err
@@ -92,16 +102,16 @@ func (c *DiskCheck) instanceConfigure(data integration.Data) error {
 	}
 
 	excludedFilesystems, found := conf["excluded_filesystems"]
-	if excludedFilesystems, ok := excludedFilesystems.([]string); found && ok {
-		c.cfg.excludedFilesystems = excludedFilesystems
+	if excludedFilesystems, ok := excludedFilesystems.([]interface{}); found && ok {
+		c.cfg.excludedFilesystems = toStringSlice(excludedFilesystems)
 	}
 
 	// Force exclusion of CDROM (iso9660) from disk check
 	c.cfg.excludedFilesystems = append(c.cfg.excludedFilesystems, "iso9660")
 
 	excludedDisks, found := conf["excluded_disks"]
-	if excludedDisks, ok := excludedDisks.([]string); found && ok {
-		c.cfg.excludedDisks = excludedDisks
+	if excludedDisks, ok := excludedDisks.([]interface{}); found && ok {
+		c.cfg.excludedDisks = toStringSlice(excludedDisks)
 	}
 
 	excludedDiskRe, found := conf["excluded_disk_re"]
@@ -130,6 +140,16 @@ func (c *DiskCheck) instanceConfigure(data integration.Data) error {
 		c.cfg.allPartitions = allPartitions
 	}
 
+	tagByLabel, found := conf["tag_by_label"]
+	if tagByLabel, ok := tagByLabel.(bool); found && ok {
+		c.cfg.tagByLabel = tagByLabel
+	}
+
+	timeout, found := conf["timeout"]
+	if timeout, ok := timeout.(int); found && ok {
+		c.cfg.timeout = time.Duration(timeout) * time.Second
+	}
+
 	deviceTagRe, found := conf["device_tag_re"]
 	if deviceTagRe, ok := deviceTagRe.(map[interface{}]interface{}); found && ok {
 		c.cfg.deviceTagRe = make(map[*regexp.Regexp][]string)
@@ -149,6 +169,17 @@ func (c *DiskCheck) instanceConfigure(data integration.Data) error {
 	return nil
 }
 
+// toStringSlice returns the strings of a list parsed from the YAML configuration
+func toStringSlice(list []interface{}) []string {
+	strs := make([]string, 0, len(list))
+	for _, e := range list {
+		if str, ok := e.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
 func stringSliceContain(slice []string, x string) bool {
 	for _, e := range slice {
 		if e == x {
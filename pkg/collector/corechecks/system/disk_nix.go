@@ -8,7 +8,15 @@
 package system
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/shirou/gopsutil/disk"
 
@@ -22,8 +30,11 @@ import (
 var (
 	diskPartitions = disk.Partitions
 	diskUsage      = disk.Usage
+	blkidOutput    = runBlkid
 )
 
+var blkidLabelRe = regexp.MustCompile(`(?:^|\s)LABEL="([^"]*)"`)
+
 // DiskCheck stores disk-specific additional fields
 type DiskCheck struct {
 	core.CheckBase
@@ -56,13 +67,18 @@ func (c *DiskCheck) collectPartitionMetrics(sender aggregator.Sender) error {
 		return err
 	}
 
+	var devicesLabel map[string][]string
+	if c.cfg.tagByLabel && runtime.GOOS == "linux" {
+		devicesLabel = getDevicesLabel()
+	}
+
 	for _, partition := range partitions {
 		if c.excludeDisk(partition.Mountpoint, partition.Device, partition.Fstype) {
 			continue
 		}
 
 		// Get disk metrics here to be able to exclude on total usage
-		usage, err := diskUsage(partition.Mountpoint)
+		usage, err := c.diskUsageWithTimeout(partition.Mountpoint)
 		if err != nil {
 			log.Warnf("Unable to get disk metrics of %s mount point: %s", partition.Mountpoint, err)
 			continue
@@ -85,6 +101,7 @@ func (c *DiskCheck) collectPartitionMetrics(sender aggregator.Sender) error {
 			deviceName = partition.Device
 		}
 		tags = append(tags, fmt.Sprintf("device:%s", deviceName))
+		tags = append(tags, devicesLabel[partition.Device]...)
 
 		tags = c.applyDeviceTags(partition.Device, partition.Mountpoint, tags)
 
@@ -94,6 +111,66 @@ func (c *DiskCheck) collectPartitionMetrics(sender aggregator.Sender) error {
 	return nil
 }
 
+// diskUsageWithTimeout returns the usage of a mount point, giving up after the
+// configured timeout as network mounts may hang forever.
+func (c *DiskCheck) diskUsageWithTimeout(mountpoint string) (*disk.UsageStat, error) {
+	if c.cfg.timeout <= 0 {
+		return diskUsage(mountpoint)
+	}
+
+	type result struct {
+		usage *disk.UsageStat
+		err   error
+	}
+	// buffered so that a hanging call doesn't leak once it returns
+	done := make(chan result, 1)
+	go func() {
+		usage, err := diskUsage(mountpoint)
+		done <- result{usage, err}
+	}()
+
+	timer := time.NewTimer(c.cfg.timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.usage, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("timed out after %s", c.cfg.timeout)
+	}
+}
+
+// getDevicesLabel returns the label tags of the devices listed by blkid
+func getDevicesLabel() map[string][]string {
+	out, err := blkidOutput()
+	if err != nil {
+		log.Debugf("Unable to get the labels of the devices: %s", err)
+		return nil
+	}
+	return parseBlkidOutput(out)
+}
+
+// parseBlkidOutput parses the `<device>: LABEL="<label>" ...` lines printed by blkid
+func parseBlkidOutput(out []byte) map[string][]string {
+	devicesLabel := make(map[string][]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		if m := blkidLabelRe.FindStringSubmatch(fields[1]); m != nil {
+			devicesLabel[fields[0]] = []string{fmt.Sprintf("label:%s", m[1]), fmt.Sprintf("device_label:%s", m[1])}
+		}
+	}
+	return devicesLabel
+}
+
+func runBlkid() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDiskTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, "blkid").Output()
+}
+
 func (c *DiskCheck) collectDiskMetrics(sender aggregator.Sender) error {
 	iomap, err := ioCounters()
 	if err != nil {
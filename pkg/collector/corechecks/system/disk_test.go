@@ -8,11 +8,15 @@ package system
 
 import (
 	"regexp"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/shirou/gopsutil/disk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -83,6 +87,10 @@ func diskUsageSampler(mountpoint string) (*disk.UsageStat, error) {
 	return diskUsageSamples[mountpoint], nil
 }
 
+func emptyBlkidSampler() ([]byte, error) {
+	return nil, nil
+}
+
 func diskIoSampler(names ...string) (map[string]disk.IOCountersStat, error) {
 	return diskIoSamples, nil
 }
@@ -92,6 +100,7 @@ func TestDiskCheck(t *testing.T) {
 	diskPartitions = diskSampler
 	diskUsage = diskUsageSampler
 	ioCounters = diskIoSampler
+	blkidOutput = emptyBlkidSampler
 	diskCheck := new(DiskCheck)
 	diskCheck.Configure(nil, nil)
 
@@ -134,6 +143,7 @@ func TestDiskCheckExcludedDiskFilsystem(t *testing.T) {
 	diskPartitions = diskSampler
 	diskUsage = diskUsageSampler
 	ioCounters = diskIoSampler
+	blkidOutput = emptyBlkidSampler
 	diskCheck := new(DiskCheck)
 	diskCheck.Configure(nil, nil)
 
@@ -161,6 +171,7 @@ func TestDiskCheckExcludedRe(t *testing.T) {
 	diskPartitions = diskSampler
 	diskUsage = diskUsageSampler
 	ioCounters = diskIoSampler
+	blkidOutput = emptyBlkidSampler
 	diskCheck := new(DiskCheck)
 	diskCheck.Configure(nil, nil)
 
@@ -188,6 +199,7 @@ func TestDiskCheckTags(t *testing.T) {
 	diskPartitions = diskSampler
 	diskUsage = diskUsageSampler
 	ioCounters = diskIoSampler
+	blkidOutput = emptyBlkidSampler
 	diskCheck := new(DiskCheck)
 
 	config := integration.Data([]byte("use_mount: true\ntag_by_filesystem: true\nall_partitions: true\ndevice_tag_re:\n  /boot/efi: role:esp\n  /dev/sda2: device_type:sata,disk_size:large"))
@@ -228,3 +240,76 @@ func TestDiskCheckTags(t *testing.T) {
 	mock.AssertNumberOfCalls(t, "Rate", expectedRates)
 	mock.AssertNumberOfCalls(t, "Commit", 1)
 }
+
+func TestDiskCheckConfigure(t *testing.T) {
+	diskCheck := new(DiskCheck)
+
+	config := integration.Data([]byte("excluded_filesystems:\n  - tmpfs\nexcluded_disks:\n  - /dev/sda2\ntag_by_label: false\ntimeout: 2"))
+	require.NoError(t, diskCheck.Configure(config, nil))
+
+	assert.Equal(t, []string{"tmpfs", "iso9660"}, diskCheck.cfg.excludedFilesystems)
+	assert.Equal(t, []string{"/dev/sda2"}, diskCheck.cfg.excludedDisks)
+	assert.False(t, diskCheck.cfg.tagByLabel)
+	assert.Equal(t, 2*time.Second, diskCheck.cfg.timeout)
+
+	// defaults
+	require.NoError(t, diskCheck.Configure(nil, nil))
+	assert.True(t, diskCheck.cfg.tagByLabel)
+	assert.Equal(t, defaultDiskTimeout, diskCheck.cfg.timeout)
+}
+
+func TestParseBlkidOutput(t *testing.T) {
+	out := []byte(`/dev/sda1: LABEL="boot" UUID="5BCA-7A0B" TYPE="vfat" PARTLABEL="EFI" PARTUUID="5a9c"
+/dev/sda2: UUID="0d2ec8d4" TYPE="ext4" PARTLABEL="root" PARTUUID="e8a4"
+/dev/sdb1: UUID="1c6f" LABEL="data disk" TYPE="xfs"
+`)
+	labels := parseBlkidOutput(out)
+
+	assert.Equal(t, map[string][]string{
+		"/dev/sda1": {"label:boot", "device_label:boot"},
+		"/dev/sdb1": {"label:data disk", "device_label:data disk"},
+	}, labels)
+}
+
+func TestDiskCheckLabels(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("labels are only collected on Linux")
+	}
+	diskPartitions = diskSampler
+	diskUsage = diskUsageSampler
+	ioCounters = diskIoSampler
+	blkidOutput = func() ([]byte, error) {
+		return []byte(`/dev/sda1: LABEL="boot" TYPE="vfat"`), nil
+	}
+	defer func() { blkidOutput = runBlkid }()
+	diskCheck := new(DiskCheck)
+	diskCheck.Configure(nil, nil)
+
+	mock := mocksender.NewMockSender(diskCheck.ID())
+	mock.SetupAcceptAll()
+
+	diskCheck.Run()
+	mock.AssertMetric(t, "Gauge", "system.disk.total", 523248.0, "", []string{"device:/dev/sda1", "label:boot", "device_label:boot"})
+	mock.AssertMetric(t, "Gauge", "system.disk.total", 50825728.0, "", []string{"device:/dev/sda2"})
+}
+
+func TestDiskUsageTimeout(t *testing.T) {
+	hang := make(chan struct{})
+	defer close(hang)
+	diskUsage = func(mountpoint string) (*disk.UsageStat, error) {
+		if mountpoint == "/mnt/nfs" {
+			<-hang
+		}
+		return diskUsageSamples[mountpoint], nil
+	}
+	diskCheck := new(DiskCheck)
+	diskCheck.Configure(nil, nil)
+	diskCheck.cfg.timeout = 10 * time.Millisecond
+
+	usage, err := diskCheck.diskUsageWithTimeout("/")
+	require.NoError(t, err)
+	assert.Equal(t, diskUsageSamples["/"], usage)
+
+	_, err = diskCheck.diskUsageWithTimeout("/mnt/nfs")
+	assert.Error(t, err)
+}
@@ -8,11 +8,15 @@ package system
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"strconv"
 	"strings"
 
+	yaml "gopkg.in/yaml.v2"
+
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -25,6 +29,7 @@ var fileNrHandle = "/proc/sys/fs/file-nr"
 
 type fhCheck struct {
 	core.CheckBase
+	instance fhInstanceConfig
 }
 
 func (c *fhCheck) getFileNrValues(fn string) ([]string, error) {
@@ -82,11 +87,29 @@ func (c *fhCheck) Run() error {
 	sender.Gauge("system.fs.file_handles.in_use", fhInUse, "", nil)
 	sender.Gauge("system.fs.file_handles.used", allocatedFh-allocatedUnusedFh, "", nil)
 	sender.Gauge("system.fs.file_handles.max", maxFh, "", nil)
+	c.collectProcesses(sender)
 	sender.Commit()
 
 	return nil
 }
 
+// Configure the file handles check
+func (c *fhCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+	c.instance = fhInstanceConfig{}
+	if err := yaml.Unmarshal(data, &c.instance); err != nil {
+		return err
+	}
+	for _, p := range c.instance.Processes {
+		if p.Name == "" {
+			return fmt.Errorf("the name of the processes to monitor must be set")
+		}
+	}
+	return nil
+}
+
 func fhFactory() check.Check {
 	return &fhCheck{
 		CheckBase: core.NewCheckBase(fileHandlesCheckName),
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build !windows
+
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const fhProcessServiceCheck = "file_handle.process.usage"
+
+// For testing
+var procfsPath = hostProc
+
+func hostProc() string {
+	if path := os.Getenv("HOST_PROC"); path != "" {
+		return path
+	}
+	return "/proc"
+}
+
+// fhProcessConfig is a process whose file descriptors are monitored. The
+// thresholds are ratios of the soft limit of open files of the process.
+type fhProcessConfig struct {
+	Name              string  `yaml:"name"`
+	WarningThreshold  float64 `yaml:"warning_threshold"`
+	CriticalThreshold float64 `yaml:"critical_threshold"`
+}
+
+type fhInstanceConfig struct {
+	Processes []fhProcessConfig `yaml:"processes"`
+}
+
+// processFdUsage is the number of open file descriptors of a process and its
+// soft limit, 0 if unlimited
+type processFdUsage struct {
+	open  float64
+	limit float64
+}
+
+func (u processFdUsage) ratio() float64 {
+	if u.limit <= 0 {
+		return 0
+	}
+	return u.open / u.limit
+}
+
+// collectProcesses reports the file descriptors of the monitored processes,
+// with a service check per process name based on the process closest to its
+// limit. The processes of other users can only be inspected if the agent has
+// the permission to read their /proc/<pid>/fd directory.
+func (c *fhCheck) collectProcesses(sender aggregator.Sender) {
+	if len(c.instance.Processes) == 0 {
+		return
+	}
+
+	pidsByName, err := listProcesses()
+	if err != nil {
+		log.Warnf("Could not list the processes: %s", err)
+		return
+	}
+
+	for _, p := range c.instance.Processes {
+		tags := []string{fmt.Sprintf("process_name:%s", p.Name)}
+
+		pids := pidsByName[p.Name]
+		if len(pids) == 0 {
+			sender.ServiceCheck(fhProcessServiceCheck, metrics.ServiceCheckUnknown, "", tags, "no process found")
+			continue
+		}
+
+		var open float64
+		var highest processFdUsage
+		inspected := 0
+		for _, pid := range pids {
+			usage, err := getProcessFdUsage(pid)
+			if err != nil {
+				log.Debugf("Could not get the file descriptors of the process %s (%s): %s", p.Name, pid, err)
+				continue
+			}
+			inspected++
+			open += usage.open
+			if usage.ratio() >= highest.ratio() {
+				highest = usage
+			}
+		}
+		if inspected == 0 {
+			sender.ServiceCheck(fhProcessServiceCheck, metrics.ServiceCheckUnknown, "", tags, "could not read the file descriptors of the process, check the permissions of the agent")
+			continue
+		}
+
+		ratio := highest.ratio()
+		sender.Gauge("system.fs.file_handles.process.open", open, "", tags)
+		sender.Gauge("system.fs.file_handles.process.in_use", ratio, "", tags)
+
+		status := metrics.ServiceCheckOK
+		message := ""
+		if p.CriticalThreshold > 0 && ratio >= p.CriticalThreshold {
+			status = metrics.ServiceCheckCritical
+		} else if p.WarningThreshold > 0 && ratio >= p.WarningThreshold {
+			status = metrics.ServiceCheckWarning
+		}
+		if status != metrics.ServiceCheckOK {
+			message = fmt.Sprintf("%.0f of %.0f file descriptors in use", highest.open, highest.limit)
+		}
+		sender.ServiceCheck(fhProcessServiceCheck, status, "", tags, message)
+	}
+}
+
+// listProcesses returns the pids of the processes, by process name
+func listProcesses() (map[string][]string, error) {
+	entries, err := ioutil.ReadDir(procfsPath())
+	if err != nil {
+		return nil, err
+	}
+	pidsByName := make(map[string][]string)
+	for _, entry := range entries {
+		pid := entry.Name()
+		if _, err := strconv.Atoi(pid); err != nil || !entry.IsDir() {
+			continue
+		}
+		comm, err := ioutil.ReadFile(filepath.Join(procfsPath(), pid, "comm"))
+		if err != nil {
+			// the process exited
+			continue
+		}
+		name := strings.TrimSpace(string(comm))
+		pidsByName[name] = append(pidsByName[name], pid)
+	}
+	return pidsByName, nil
+}
+
+func getProcessFdUsage(pid string) (processFdUsage, error) {
+	fds, err := ioutil.ReadDir(filepath.Join(procfsPath(), pid, "fd"))
+	if err != nil {
+		return processFdUsage{}, err
+	}
+	limit, err := getOpenFilesLimit(pid)
+	if err != nil {
+		return processFdUsage{}, err
+	}
+	return processFdUsage{open: float64(len(fds)), limit: limit}, nil
+}
+
+// getOpenFilesLimit returns the soft limit of open files of a process, read
+// from the `Max open files  <soft>  <hard>  files` line of its limits
+func getOpenFilesLimit(pid string) (float64, error) {
+	f, err := os.Open(filepath.Join(procfsPath(), pid, "limits"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(fields[0], 64)
+	}
+	return 0, fmt.Errorf("no open files limit found")
+}
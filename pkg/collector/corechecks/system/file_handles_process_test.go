@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build !windows
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const limitsSample = `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            %s                 4096                 files
+Max locked memory         16777216             16777216             bytes
+`
+
+// writeProcess adds a process to a fake procfs, without fd directory if fds is negative
+func writeProcess(t *testing.T, root, pid, name, limit string, fds int) {
+	dir := filepath.Join(root, pid)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "comm"), []byte(name+"\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "limits"), []byte(fmt.Sprintf(limitsSample, limit)), 0644))
+	if fds < 0 {
+		return
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0755))
+	for i := 0; i < fds; i++ {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "fd", fmt.Sprint(i)), nil, 0644))
+	}
+}
+
+func TestFhCheckProcesses(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	procfsPath = func() string { return root }
+	defer func() { procfsPath = hostProc }()
+
+	writeProcess(t, root, "1", "nginx", "4", 3)
+	writeProcess(t, root, "2", "nginx", "1024", 1)
+	writeProcess(t, root, "3", "redis-server", "unlimited", 2)
+	writeProcess(t, root, "4", "postgres", "1024", -1)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "file-nr"), samplecontent1, 0644))
+	fileNrHandle = filepath.Join(root, "file-nr")
+
+	fileHandleCheck := new(fhCheck)
+	config := integration.Data(`processes:
+  - name: nginx
+    warning_threshold: 0.7
+    critical_threshold: 0.9
+  - name: redis-server
+    warning_threshold: 0.7
+  - name: postgres
+  - name: haproxy
+`)
+	require.NoError(t, fileHandleCheck.Configure(config, nil))
+
+	mock := mocksender.NewMockSender(fileHandleCheck.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, fileHandleCheck.Run())
+
+	nginx := []string{"process_name:nginx"}
+	mock.AssertMetric(t, "Gauge", "system.fs.file_handles.process.open", 4, "", nginx)
+	mock.AssertMetric(t, "Gauge", "system.fs.file_handles.process.in_use", 0.75, "", nginx)
+	mock.AssertServiceCheck(t, fhProcessServiceCheck, metrics.ServiceCheckWarning, "", nginx, "3 of 4 file descriptors in use")
+
+	redis := []string{"process_name:redis-server"}
+	mock.AssertMetric(t, "Gauge", "system.fs.file_handles.process.open", 2, "", redis)
+	mock.AssertMetric(t, "Gauge", "system.fs.file_handles.process.in_use", 0, "", redis)
+	mock.AssertServiceCheck(t, fhProcessServiceCheck, metrics.ServiceCheckOK, "", redis, "")
+
+	mock.AssertServiceCheck(t, fhProcessServiceCheck, metrics.ServiceCheckUnknown, "", []string{"process_name:postgres"}, "could not read the file descriptors of the process, check the permissions of the agent")
+	mock.AssertServiceCheck(t, fhProcessServiceCheck, metrics.ServiceCheckUnknown, "", []string{"process_name:haproxy"}, "no process found")
+	mock.AssertNumberOfCalls(t, "ServiceCheck", 4)
+}
+
+func TestFhCheckConfigure(t *testing.T) {
+	fileHandleCheck := new(fhCheck)
+	assert.Error(t, fileHandleCheck.Configure(integration.Data("processes:\n  - warning_threshold: 0.5"), nil))
+	assert.NoError(t, fileHandleCheck.Configure(nil, nil))
+	assert.Empty(t, fileHandleCheck.instance.Processes)
+}
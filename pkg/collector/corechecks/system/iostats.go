@@ -38,12 +38,43 @@ func (c *IOCheck) commonConfigure(data integration.Data, initConfig integration.
 	blacklistRe, ok := conf["device_blacklist_re"]
 	if ok && blacklistRe != "" {
 		if regex, ok := blacklistRe.(string); ok {
-			c.blacklist, err = regexp.Compile(regex)
+			if c.blacklist, err = regexp.Compile(regex); err != nil {
+				return err
+			}
+		}
+	}
+
+	whitelistRe, ok := conf["device_whitelist_re"]
+	if ok && whitelistRe != "" {
+		if regex, ok := whitelistRe.(string); ok {
+			c.whitelist, err = regexp.Compile(regex)
 		}
 	}
 	return err
 }
 
+// isDeviceExcluded returns whether a device is excluded by the blacklist or
+// not included by the whitelist, the blacklist takes precedence. A device may
+// have several names, like the name of the device-mapper devices.
+func (c *IOCheck) isDeviceExcluded(names ...string) bool {
+	if c.blacklist != nil {
+		for _, name := range names {
+			if c.blacklist.MatchString(name) {
+				return true
+			}
+		}
+	}
+	if c.whitelist == nil {
+		return false
+	}
+	for _, name := range names {
+		if c.whitelist.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
 func init() {
 	core.RegisterCheck(iostatsCheckName, ioFactory)
 }
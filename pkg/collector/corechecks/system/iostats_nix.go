@@ -10,8 +10,11 @@ package system
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
@@ -33,6 +36,7 @@ var (
 type IOCheck struct {
 	core.CheckBase
 	blacklist *regexp.Regexp
+	whitelist *regexp.Regexp
 	ts        int64
 	stats     map[string]disk.IOCountersStat
 }
@@ -80,7 +84,12 @@ func (c *IOCheck) nixIO() error {
 
 	var tagbuff bytes.Buffer
 	for device, ioStats := range iomap {
-		if c.blacklist != nil && c.blacklist.MatchString(device) {
+		mapperName, isMapped := deviceMapperName(device)
+		if isMapped {
+			if c.isDeviceExcluded(device, mapperName) {
+				continue
+			}
+		} else if c.isDeviceExcluded(device) {
 			continue
 		}
 
@@ -91,6 +100,9 @@ func (c *IOCheck) nixIO() error {
 		if ioStats.Label != "" {
 			tags = append(tags, fmt.Sprintf("device_label:%s", ioStats.Label))
 		}
+		if isMapped {
+			tags = append(tags, fmt.Sprintf("device_name:%s", mapperName))
+		}
 
 		sender.Rate("system.io.r_s", float64(ioStats.ReadCount), "", tags)
 		sender.Rate("system.io.w_s", float64(ioStats.WriteCount), "", tags)
@@ -169,6 +181,21 @@ func (c *IOCheck) nixIO() error {
 	return nil
 }
 
+// deviceMapperName returns the name of a device-mapper device, like the
+// `<vg>-<lv>` name of the LVM logical volumes, the kernel only names them
+// dm-<minor> in /proc/diskstats.
+func deviceMapperName(device string) (string, bool) {
+	if !strings.HasPrefix(device, "dm-") {
+		return "", false
+	}
+	content, err := ioutil.ReadFile(filepath.Join(sysfsPath(), "block", device, "dm/name"))
+	if err != nil {
+		return "", false
+	}
+	name := strings.TrimSpace(string(content))
+	return name, name != ""
+}
+
 // Run executes the check
 func (c *IOCheck) Run() error {
 	sender, err := aggregator.GetSender(c.ID())
@@ -8,12 +8,17 @@
 package system
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/shirou/gopsutil/disk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var currentStats = map[string]disk.IOCountersStat{
@@ -100,3 +105,46 @@ func TestIoStatsOverflow(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestIOCheckDeviceMapper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sys")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "block/dm-0/dm"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "block/dm-0/dm/name"), []byte("vg0-root\n"), 0644))
+	sysfsPath = func() string { return dir }
+	defer func() { sysfsPath = hostSys }()
+
+	ioCounters = func(names ...string) (map[string]disk.IOCountersStat, error) {
+		return map[string]disk.IOCountersStat{
+			"dm-0": {ReadCount: 1, Name: "dm-0"},
+			"dm-1": {ReadCount: 2, Name: "dm-1"},
+			"sda":  {ReadCount: 3, Name: "sda"},
+		}, nil
+	}
+
+	ioCheck := new(IOCheck)
+	require.NoError(t, ioCheck.Configure(nil, integration.Data(`device_whitelist_re: "^(sd|vg0-)"`)))
+
+	mock := mocksender.NewMockSender(ioCheck.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, ioCheck.Run())
+
+	mock.AssertMetric(t, "Rate", "system.io.r_s", 1, "", []string{"device:dm-0", "device_name:vg0-root"})
+	mock.AssertMetric(t, "Rate", "system.io.r_s", 3, "", []string{"device:sda"})
+	mock.AssertNumberOfCalls(t, "Rate", 8)
+}
+
+func TestIOCheckDeviceFilters(t *testing.T) {
+	ioCheck := new(IOCheck)
+	require.NoError(t, ioCheck.Configure(nil, integration.Data(`
+device_blacklist_re: "^sdb"
+device_whitelist_re: "^sd"
+`)))
+	assert.False(t, ioCheck.isDeviceExcluded("sda"))
+	assert.True(t, ioCheck.isDeviceExcluded("sdb1"))
+	assert.True(t, ioCheck.isDeviceExcluded("nvme0n1"))
+	assert.False(t, ioCheck.isDeviceExcluded("dm-0", "sda-root"))
+
+	assert.Error(t, ioCheck.Configure(nil, integration.Data(`device_whitelist_re: "["`)))
+}
@@ -41,6 +41,7 @@ const (
 type IOCheck struct {
 	core.CheckBase
 	blacklist    *regexp.Regexp
+	whitelist    *regexp.Regexp
 	counters     map[string]*pdhutil.PdhMultiInstanceCounterSet
 	counternames map[string]string
 }
@@ -106,8 +107,8 @@ func (c *IOCheck) Run() error {
 			return err
 		}
 		for inst, val := range vals {
-			if c.blacklist != nil && c.blacklist.MatchString(inst) {
-				log.Debugf("matched drive %s against blacklist; skipping", inst)
+			if c.isDeviceExcluded(inst) {
+				log.Debugf("drive %s is excluded; skipping", inst)
 				continue
 			}
 			tagbuff.Reset()
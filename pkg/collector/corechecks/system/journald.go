@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux,systemd
+
+package system
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/sdjournal"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	journaldCheckName = "journald"
+	// maximum number of messages listed in an event
+	journaldEventMessages = 10
+)
+
+// the syslog priorities, indexed by level
+var journaldPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// For testing purpose
+var openJournal = openSDJournal
+
+type journaldInstanceConfig struct {
+	Path             string   `yaml:"path"`
+	Priority         string   `yaml:"priority"`
+	IncludeUnits     []string `yaml:"include_units"`
+	FirstRunLookback int      `yaml:"first_run_lookback"`
+}
+
+// journalReader reads the entries of the journal
+type journalReader interface {
+	read(handle func(entry *sdjournal.JournalEntry)) error
+	close()
+}
+
+// JournaldCheck reports the entries of the journal at or above a priority,
+// crit by default, counted and bundled in an event by syslog identifier. It
+// gives visibility on the boot and service errors without the logs agent.
+type JournaldCheck struct {
+	core.CheckBase
+	instance    journaldInstanceConfig
+	maxPriority int
+	cursor      string    // cursor of the last entry read
+	since       time.Time // time to read from until an entry is read
+}
+
+// journaldGroup holds the entries logged by a syslog identifier during a run
+type journaldGroup struct {
+	counts    map[string]int // priority -> count
+	messages  []string
+	total     int
+	timestamp int64
+}
+
+// Run executes the check
+func (c *JournaldCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	// the journal is only opened during the run, it's read from the last
+	// entry read by the previous runs
+	start := time.Now()
+	if c.since.IsZero() {
+		c.since = start.Add(-time.Duration(c.instance.FirstRunLookback) * time.Second)
+	}
+	reader, err := openJournal(c.instance, c.maxPriority, c.cursor, c.since)
+	if err != nil {
+		return fmt.Errorf("could not open the journal: %s", err)
+	}
+	defer reader.close()
+
+	groups := make(map[string]*journaldGroup)
+	err = reader.read(func(entry *sdjournal.JournalEntry) {
+		c.cursor = entry.Cursor
+		identifier := entryIdentifier(entry)
+		group, found := groups[identifier]
+		if !found {
+			group = &journaldGroup{counts: make(map[string]int)}
+			groups[identifier] = group
+		}
+		priority := entryPriority(entry)
+		group.counts[priority]++
+		group.total++
+		group.timestamp = int64(entry.RealtimeTimestamp / 1e6)
+		if len(group.messages) < journaldEventMessages {
+			group.messages = append(group.messages, fmt.Sprintf("[%s] %s", priority, entry.Fields[sdjournal.SD_JOURNAL_FIELD_MESSAGE]))
+		}
+	})
+	if c.cursor == "" {
+		c.since = start
+	}
+
+	for identifier, group := range groups {
+		tags := []string{fmt.Sprintf("syslog_identifier:%s", identifier)}
+		for priority, count := range group.counts {
+			sender.Count("journald.entries", float64(count), "", append(tags, fmt.Sprintf("priority:%s", priority)))
+		}
+		sender.Event(group.event(identifier, tags))
+	}
+
+	sender.Commit()
+	return err
+}
+
+func (g *journaldGroup) event(identifier string, tags []string) metrics.Event {
+	text := strings.Join(g.messages, "\n")
+	if g.total > len(g.messages) {
+		text += fmt.Sprintf("\n... and %d more", g.total-len(g.messages))
+	}
+	return metrics.Event{
+		Title:          fmt.Sprintf("%d journal entries logged by %s", g.total, identifier),
+		Text:           text,
+		Ts:             g.timestamp,
+		Priority:       metrics.EventPriorityNormal,
+		AlertType:      metrics.EventAlertTypeError,
+		Tags:           tags,
+		AggregationKey: fmt.Sprintf("journald:%s", identifier),
+		SourceTypeName: journaldCheckName,
+		EventType:      journaldCheckName,
+	}
+}
+
+// entryIdentifier returns the syslog identifier of an entry, or the name of
+// its process when it's not set
+func entryIdentifier(entry *sdjournal.JournalEntry) string {
+	if identifier := entry.Fields[sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER]; identifier != "" {
+		return identifier
+	}
+	if comm := entry.Fields[sdjournal.SD_JOURNAL_FIELD_COMM]; comm != "" {
+		return comm
+	}
+	return "unknown"
+}
+
+func entryPriority(entry *sdjournal.JournalEntry) string {
+	level, err := strconv.Atoi(entry.Fields[sdjournal.SD_JOURNAL_FIELD_PRIORITY])
+	if err != nil || level < 0 || level >= len(journaldPriorities) {
+		return "unknown"
+	}
+	return journaldPriorities[level]
+}
+
+// sdJournal reads the journal with the systemd library
+type sdJournal struct {
+	journal *sdjournal.Journal
+}
+
+// openSDJournal opens the journal, filtered on the priorities and the units,
+// at the cursor or at the given time when there's no cursor yet
+func openSDJournal(instance journaldInstanceConfig, maxPriority int, cursor string, since time.Time) (journalReader, error) {
+	var journal *sdjournal.Journal
+	var err error
+	if instance.Path == "" {
+		journal, err = sdjournal.NewJournal()
+	} else {
+		journal, err = sdjournal.NewJournalFromDir(instance.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the matches of a field are ORed, the matches of different fields ANDed
+	for level := 0; level <= maxPriority; level++ {
+		if err := journal.AddMatch(fmt.Sprintf("%s=%d", sdjournal.SD_JOURNAL_FIELD_PRIORITY, level)); err != nil {
+			journal.Close()
+			return nil, err
+		}
+	}
+	for _, unit := range instance.IncludeUnits {
+		if err := journal.AddMatch(sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT + "=" + unit); err != nil {
+			journal.Close()
+			return nil, err
+		}
+	}
+
+	if cursor != "" {
+		err = journal.SeekCursor(cursor)
+		if err == nil {
+			// skip the entry of the cursor, it was already read
+			_, err = journal.NextSkip(1)
+		}
+	} else {
+		err = journal.SeekRealtimeUsec(uint64(since.UnixNano() / 1000))
+	}
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+	return &sdJournal{journal: journal}, nil
+}
+
+func (j *sdJournal) read(handle func(entry *sdjournal.JournalEntry)) error {
+	for {
+		n, err := j.journal.Next()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n < 1 {
+			return nil
+		}
+		entry, err := j.journal.GetEntry()
+		if err != nil {
+			log.Debugf("Could not read a journal entry: %s", err)
+			continue
+		}
+		handle(entry)
+	}
+}
+
+func (j *sdJournal) close() {
+	j.journal.Close()
+}
+
+// Configure parses the check configuration
+func (c *JournaldCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	instance := journaldInstanceConfig{
+		Priority:         "crit",
+		FirstRunLookback: 3600,
+	}
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+	maxPriority := -1
+	for level, name := range journaldPriorities {
+		if instance.Priority == name {
+			maxPriority = level
+		}
+	}
+	if maxPriority < 0 {
+		return fmt.Errorf("unknown priority %q, must be one of %s", instance.Priority, strings.Join(journaldPriorities, ", "))
+	}
+
+	c.instance = instance
+	c.maxPriority = maxPriority
+	return nil
+}
+
+func journaldFactory() check.Check {
+	return &JournaldCheck{
+		CheckBase: core.NewCheckBase(journaldCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(journaldCheckName, journaldFactory)
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux,systemd
+
+package system
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/sdjournal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// fakeJournal returns the entries after the cursor
+type fakeJournal struct {
+	entries []*sdjournal.JournalEntry
+	cursor  string
+}
+
+func (j *fakeJournal) read(handle func(entry *sdjournal.JournalEntry)) error {
+	found := j.cursor == ""
+	for _, entry := range j.entries {
+		if found {
+			handle(entry)
+		}
+		found = found || entry.Cursor == j.cursor
+	}
+	return nil
+}
+
+func (j *fakeJournal) close() {}
+
+func journalEntry(cursor, identifier string, priority int, message string) *sdjournal.JournalEntry {
+	return &sdjournal.JournalEntry{
+		Cursor:            cursor,
+		RealtimeTimestamp: 1560000000 * 1e6,
+		Fields: map[string]string{
+			sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER: identifier,
+			sdjournal.SD_JOURNAL_FIELD_PRIORITY:          fmt.Sprint(priority),
+			sdjournal.SD_JOURNAL_FIELD_MESSAGE:           message,
+		},
+	}
+}
+
+func TestJournaldCheck(t *testing.T) {
+	journal := &fakeJournal{}
+	var openedSince time.Time
+	openJournal = func(instance journaldInstanceConfig, maxPriority int, cursor string, since time.Time) (journalReader, error) {
+		assert.Equal(t, 2, maxPriority)
+		journal.cursor = cursor
+		openedSince = since
+		return journal, nil
+	}
+	defer func() { openJournal = openSDJournal }()
+
+	check := journaldFactory().(*JournaldCheck)
+	require.NoError(t, check.Configure(nil, nil))
+
+	// nothing logged yet, the lookback is read
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), openedSince, time.Minute)
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+
+	journal.entries = []*sdjournal.JournalEntry{
+		journalEntry("1", "kernel", 2, "Out of memory"),
+		journalEntry("2", "sshd", 0, "fatal error"),
+		journalEntry("3", "kernel", 2, "I/O error"),
+		journalEntry("4", "kernel", 1, "Machine check"),
+	}
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	kernel := []string{"syslog_identifier:kernel"}
+	sender.AssertMetric(t, "Count", "journald.entries", 2, "", append(kernel, "priority:crit"))
+	sender.AssertMetric(t, "Count", "journald.entries", 1, "", append(kernel, "priority:alert"))
+	sender.AssertMetric(t, "Count", "journald.entries", 1, "", []string{"syslog_identifier:sshd", "priority:emerg"})
+	sender.AssertCalled(t, "Event", metrics.Event{
+		Title:          "3 journal entries logged by kernel",
+		Text:           "[crit] Out of memory\n[crit] I/O error\n[alert] Machine check",
+		Ts:             1560000000,
+		Priority:       metrics.EventPriorityNormal,
+		AlertType:      metrics.EventAlertTypeError,
+		Tags:           kernel,
+		AggregationKey: "journald:kernel",
+		SourceTypeName: "journald",
+		EventType:      "journald",
+	})
+	sender.AssertNumberOfCalls(t, "Event", 2)
+
+	// the next run reads from the last entry
+	for i := 5; i < 20; i++ {
+		journal.entries = append(journal.entries, journalEntry(fmt.Sprint(i), "dockerd", 2, "failed"))
+	}
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	assert.Equal(t, "4", journal.cursor)
+	sender.AssertMetric(t, "Count", "journald.entries", 15, "", []string{"syslog_identifier:dockerd", "priority:crit"})
+	sender.AssertNumberOfCalls(t, "Event", 1)
+	sender.AssertCalled(t, "Event", mock.MatchedBy(func(e metrics.Event) bool {
+		return strings.HasSuffix(e.Text, "[crit] failed\n... and 5 more")
+	}))
+}
+
+func TestJournaldConfig(t *testing.T) {
+	check := journaldFactory().(*JournaldCheck)
+	require.NoError(t, check.Configure([]byte("priority: err"), nil))
+	assert.Equal(t, 3, check.maxPriority)
+
+	assert.Error(t, check.Configure([]byte("priority: error"), nil))
+}
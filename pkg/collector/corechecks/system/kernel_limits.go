@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kernelLimitsCheckName      = "kernel_limits"
+	conntrackUsageServiceCheck = "kernel_limits.conntrack.usage"
+)
+
+// kernelLimitsConfig holds the thresholds of the conntrack service check, as
+// percents of the size of the conntrack table like system.net.conntrack.saturation
+type kernelLimitsConfig struct {
+	ConntrackWarningThreshold  float64 `yaml:"conntrack_warning_threshold"`
+	ConntrackCriticalThreshold float64 `yaml:"conntrack_critical_threshold"`
+}
+
+// KernelLimitsCheck reports the usage of kernel resources that break the
+// host once exhausted: the netfilter connection tracking table, whose
+// saturation drops the new connections, and the entropy pool, whose
+// exhaustion blocks the reads of /dev/random.
+type KernelLimitsCheck struct {
+	core.CheckBase
+	instance kernelLimitsConfig
+}
+
+// Run executes the check
+func (c *KernelLimitsCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	c.collectConntrack(sender)
+	c.collectEntropy(sender)
+
+	sender.Commit()
+	return nil
+}
+
+// collectConntrack reports the saturation of the conntrack table with a
+// service check, it's only available when the nf_conntrack module is loaded.
+// The conntrack metrics are sent by the network check.
+func (c *KernelLimitsCheck) collectConntrack(sender aggregator.Sender) {
+	count, err := readProcValue("sys/net/netfilter/nf_conntrack_count")
+	if os.IsNotExist(err) {
+		log.Debugf("No conntrack table, the nf_conntrack module isn't loaded")
+		return
+	} else if err != nil {
+		log.Warnf("Could not read the conntrack entry count: %s", err)
+		return
+	}
+	max, err := readProcValue("sys/net/netfilter/nf_conntrack_max")
+	if err != nil {
+		log.Warnf("Could not read the conntrack table size: %s", err)
+		return
+	}
+
+	if max <= 0 {
+		return
+	}
+	saturation := 100 * count / max
+
+	status := metrics.ServiceCheckOK
+	message := ""
+	if saturation >= c.instance.ConntrackCriticalThreshold {
+		status = metrics.ServiceCheckCritical
+	} else if saturation >= c.instance.ConntrackWarningThreshold {
+		status = metrics.ServiceCheckWarning
+	}
+	if status != metrics.ServiceCheckOK {
+		message = fmt.Sprintf("%.0f of %.0f conntrack entries in use, new connections are dropped once the table is full", count, max)
+	}
+	sender.ServiceCheck(conntrackUsageServiceCheck, status, "", nil, message)
+}
+
+// collectEntropy reports the entropy available in the kernel pool
+func (c *KernelLimitsCheck) collectEntropy(sender aggregator.Sender) {
+	available, err := readProcValue("sys/kernel/random/entropy_avail")
+	if err != nil {
+		log.Warnf("Could not read the available entropy: %s", err)
+		return
+	}
+	sender.Gauge("system.entropy.available", available, "", nil)
+
+	if poolSize, err := readProcValue("sys/kernel/random/poolsize"); err == nil {
+		sender.Gauge("system.entropy.pool_size", poolSize, "", nil)
+	}
+}
+
+// readProcValue reads a file of procfs holding a single number
+func readProcValue(path string) (float64, error) {
+	content, err := ioutil.ReadFile(filepath.Join(procfsPath(), path))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(content)), 64)
+}
+
+// Configure parses the check configuration
+func (c *KernelLimitsCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+	c.instance = kernelLimitsConfig{
+		ConntrackWarningThreshold:  80,
+		ConntrackCriticalThreshold: 95,
+	}
+	if err := yaml.Unmarshal(data, &c.instance); err != nil {
+		return err
+	}
+	if c.instance.ConntrackWarningThreshold > c.instance.ConntrackCriticalThreshold {
+		return fmt.Errorf("conntrack_warning_threshold must be lower than conntrack_critical_threshold")
+	}
+	return nil
+}
+
+func kernelLimitsFactory() check.Check {
+	return &KernelLimitsCheck{
+		CheckBase: core.NewCheckBase(kernelLimitsCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(kernelLimitsCheckName, kernelLimitsFactory)
+}
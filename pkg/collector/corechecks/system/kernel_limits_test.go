@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux
+
+package system
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// writeProcFiles writes the files of a fake procfs, by path
+func writeProcFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content+"\n"), 0644))
+	}
+}
+
+func runKernelLimitsCheck(t *testing.T, config string) *mocksender.MockSender {
+	check := kernelLimitsFactory().(*KernelLimitsCheck)
+	require.NoError(t, check.Configure([]byte(config), nil))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return sender
+}
+
+func TestKernelLimitsCheck(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	procfsPath = func() string { return root }
+	defer func() { procfsPath = hostProc }()
+
+	writeProcFiles(t, root, map[string]string{
+		"sys/net/netfilter/nf_conntrack_count": "55000",
+		"sys/net/netfilter/nf_conntrack_max":   "65536",
+		"sys/kernel/random/entropy_avail":      "3021",
+		"sys/kernel/random/poolsize":           "4096",
+	})
+
+	sender := runKernelLimitsCheck(t, "")
+	// the conntrack metrics are sent by the network check
+	sender.AssertNotCalled(t, "Gauge", "system.net.conntrack.count", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertCalled(t, "ServiceCheck", conntrackUsageServiceCheck, metrics.ServiceCheckWarning, "", []string(nil), mock.Anything)
+	sender.AssertMetric(t, "Gauge", "system.entropy.available", 3021, "", nil)
+	sender.AssertMetric(t, "Gauge", "system.entropy.pool_size", 4096, "", nil)
+
+	sender = runKernelLimitsCheck(t, "conntrack_warning_threshold: 50\nconntrack_critical_threshold: 80")
+	sender.AssertCalled(t, "ServiceCheck", conntrackUsageServiceCheck, metrics.ServiceCheckCritical, "", []string(nil), mock.Anything)
+
+	sender = runKernelLimitsCheck(t, "conntrack_warning_threshold: 90\nconntrack_critical_threshold: 99")
+	sender.AssertServiceCheck(t, conntrackUsageServiceCheck, metrics.ServiceCheckOK, "", nil, "")
+
+	// without the nf_conntrack module
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "sys/net/netfilter")))
+	sender = runKernelLimitsCheck(t, "")
+	sender.AssertNotCalled(t, "ServiceCheck", conntrackUsageServiceCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertMetric(t, "Gauge", "system.entropy.available", 3021, "", nil)
+}
+
+func TestKernelLimitsConfig(t *testing.T) {
+	check := kernelLimitsFactory().(*KernelLimitsCheck)
+	assert.Error(t, check.Configure([]byte("conntrack_warning_threshold: 90\nconntrack_critical_threshold: 50"), nil))
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/shirou/gopsutil/load"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -23,10 +24,15 @@ const loadCheckName = "load"
 // For testing purpose
 var loadAvg = load.Avg
 
+type loadInstanceConfig struct {
+	TagByCPUCount bool `yaml:"tag_by_cpu_count"`
+}
+
 // LoadCheck doesn't need additional fields
 type LoadCheck struct {
 	core.CheckBase
 	nbCPU int32
+	tags  []string
 }
 
 // Run executes the check
@@ -42,13 +48,16 @@ func (c *LoadCheck) Run() error {
 		return err
 	}
 
-	sender.Gauge("system.load.1", avg.Load1, "", nil)
-	sender.Gauge("system.load.5", avg.Load5, "", nil)
-	sender.Gauge("system.load.15", avg.Load15, "", nil)
-	cpus := float64(c.nbCPU)
-	sender.Gauge("system.load.norm.1", avg.Load1/cpus, "", nil)
-	sender.Gauge("system.load.norm.5", avg.Load5/cpus, "", nil)
-	sender.Gauge("system.load.norm.15", avg.Load15/cpus, "", nil)
+	sender.Gauge("system.load.1", avg.Load1, "", c.tags)
+	sender.Gauge("system.load.5", avg.Load5, "", c.tags)
+	sender.Gauge("system.load.15", avg.Load15, "", c.tags)
+	// the load can't be normalized if the number of cores is unknown
+	if c.nbCPU > 0 {
+		cpus := float64(c.nbCPU)
+		sender.Gauge("system.load.norm.1", avg.Load1/cpus, "", c.tags)
+		sender.Gauge("system.load.norm.5", avg.Load5/cpus, "", c.tags)
+		sender.Gauge("system.load.norm.15", avg.Load15/cpus, "", c.tags)
+	}
 	sender.Commit()
 
 	return nil
@@ -75,6 +84,14 @@ func (c *LoadCheck) Configure(data integration.Data, initConfig integration.Data
 	for _, i := range info {
 		c.nbCPU += i.Cores
 	}
+
+	conf := loadInstanceConfig{}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+	if conf.TagByCPUCount {
+		c.tags = []string{fmt.Sprintf("cpu_count:%d", c.nbCPU)}
+	}
 	return nil
 }
 
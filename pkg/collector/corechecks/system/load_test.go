@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/shirou/gopsutil/load"
 )
 
@@ -52,3 +53,26 @@ func TestLoadCheckLinux(t *testing.T) {
 	mock.AssertNumberOfCalls(t, "Gauge", 6)
 	mock.AssertNumberOfCalls(t, "Commit", 1)
 }
+
+func TestLoadCheckTagByCPUCount(t *testing.T) {
+	loadAvg = Avg
+	cpuInfo = CPUInfo
+	loadCheck := new(LoadCheck)
+	loadCheck.Configure(integration.Data("tag_by_cpu_count: true"), nil)
+
+	mock := mocksender.NewMockSender(loadCheck.ID())
+
+	tags := []string{"cpu_count:1"}
+	mock.On("Gauge", "system.load.1", 0.83, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.load.5", 0.96, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.load.15", 1.15, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.load.norm.1", 0.83, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.load.norm.5", 0.96, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.load.norm.15", 1.15, "", tags).Return().Times(1)
+	mock.On("Commit").Return().Times(1)
+	loadCheck.Run()
+
+	mock.AssertExpectations(t)
+	mock.AssertNumberOfCalls(t, "Gauge", 6)
+	mock.AssertNumberOfCalls(t, "Commit", 1)
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux
+
+package system
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/ebpf/oomkill"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/sysprobe"
+)
+
+const oomKillCheckName = "oom_kill"
+
+// OOMKillCheck reports the processes killed by the OOM killer, as watched
+// by the system-probe
+type OOMKillCheck struct {
+	core.CheckBase
+	client   *http.Client
+	hostname string
+}
+
+// Run executes the check
+func (c *OOMKillCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	var kills []oomkill.OOMKill
+	if err := sysprobe.GetCheck(c.client, oomKillCheckName, &kills); err != nil {
+		return err
+	}
+
+	for _, kill := range kills {
+		tags := []string{fmt.Sprintf("process_name:%s", kill.Comm)}
+		if kill.ContainerID != "" {
+			entity := containerEntity(kill)
+			containerTags, err := tagger.Tag(entity, collectors.HighCardinality)
+			if err != nil {
+				log.Debugf("no tags for %s: %s", entity, err)
+			}
+			tags = append(tags, containerTags...)
+		}
+
+		sender.Count("oom_kill.oom_process.count", 1, "", tags)
+		sender.Event(toOOMKillEvent(kill, c.hostname, tags))
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// containerEntity returns the tagger entity of the container of a killed
+// process. The process is gone so its runtime is guessed from its cgroup,
+// docker is assumed when the cgroup doesn't name the runtime.
+func containerEntity(kill oomkill.OOMKill) string {
+	runtime := containers.RuntimeNameDocker
+	switch {
+	case strings.Contains(kill.Cgroup, "crio"):
+		runtime = containers.RuntimeNameCRIO
+	case strings.Contains(kill.Cgroup, "containerd"):
+		runtime = containers.RuntimeNameContainerd
+	}
+	return containers.BuildEntityName(runtime, kill.ContainerID)
+}
+
+func toOOMKillEvent(kill oomkill.OOMKill, hostname string, tags []string) metrics.Event {
+	var reason string
+	if kill.MemCgroupKill && kill.MemoryLimit > 0 {
+		reason = fmt.Sprintf("its cgroup reached its memory limit (%d kB used of %d kB)", kill.MemoryUsage, kill.MemoryLimit)
+	} else if kill.MemCgroupKill {
+		reason = "its cgroup reached its memory limit"
+	} else {
+		reason = "the host ran out of memory"
+	}
+
+	text := fmt.Sprintf("Process %s (pid %d) was killed by the OOM killer because %s.\n\n", kill.Comm, kill.Pid, reason)
+	if kill.Cgroup != "" {
+		text += fmt.Sprintf("Cgroup: %s\n", kill.Cgroup)
+	}
+	text += fmt.Sprintf("Memory of the process: total-vm %d kB, anon-rss %d kB, file-rss %d kB, shmem-rss %d kB",
+		kill.TotalVM, kill.AnonRSS, kill.FileRSS, kill.ShmemRSS)
+
+	return metrics.Event{
+		Title:          fmt.Sprintf("Process OOM killed: %s (pid %d) on %s", kill.Comm, kill.Pid, hostname),
+		Text:           fmt.Sprintf("%%%%%% \n%s\n %%%%%%", text),
+		Ts:             kill.Timestamp,
+		Priority:       metrics.EventPriorityNormal,
+		Host:           hostname,
+		Tags:           tags,
+		AlertType:      metrics.EventAlertTypeError,
+		AggregationKey: fmt.Sprintf("oom_kill:%s", kill.Comm),
+		SourceTypeName: oomKillCheckName,
+		EventType:      oomKillCheckName,
+	}
+}
+
+// Configure configures the oom_kill check
+func (c *OOMKillCheck) Configure(rawInstance integration.Data, rawInitConfig integration.Data) error {
+	if err := c.CommonConfigure(rawInstance); err != nil {
+		return err
+	}
+
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Can't get hostname from the oom_kill check: %s", err)
+	}
+	c.hostname = hostname
+	c.client = sysprobe.NewClient()
+	return nil
+}
+
+func oomKillFactory() check.Check {
+	return &OOMKillCheck{
+		CheckBase: core.NewCheckBase(oomKillCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(oomKillCheckName, oomKillFactory)
+}
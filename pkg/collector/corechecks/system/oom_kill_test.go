@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux
+
+package system
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/ebpf/oomkill"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestOOMKillCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sysprobe")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "sysprobe.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/check/oom_kill", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"timestamp": 1561000000, "pid": 4242, "comm": "stress", "cgroup": "/user.slice", "total_vm": 268692, "anon_rss": 261892},
+			{"timestamp": 1561000060, "pid": 31337, "comm": "python", "memcg_kill": true, "memory_usage": 102400, "memory_limit": 102400}
+		]`))
+	})
+	go http.Serve(listener, mux)
+	defer listener.Close()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("system_probe_config.sysprobe_socket", socketPath)
+	defer mockConfig.Set("system_probe_config.sysprobe_socket", "")
+
+	check := oomKillFactory().(*OOMKillCheck)
+	require.NoError(t, check.Configure(nil, nil))
+	check.hostname = "myhost"
+
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	mock.AssertMetric(t, "Count", "oom_kill.oom_process.count", 1, "", []string{"process_name:stress"})
+	mock.AssertMetric(t, "Count", "oom_kill.oom_process.count", 1, "", []string{"process_name:python"})
+	mock.AssertEvent(t, metrics.Event{
+		Ts:             1561000060,
+		Priority:       metrics.EventPriorityNormal,
+		Host:           "myhost",
+		Tags:           []string{"process_name:python"},
+		AggregationKey: "oom_kill:python",
+		SourceTypeName: "oom_kill",
+		EventType:      "oom_kill",
+	}, 0)
+	mock.AssertNumberOfCalls(t, "Event", 2)
+}
+
+func TestOOMKillEvent(t *testing.T) {
+	event := toOOMKillEvent(oomkill.OOMKill{
+		Timestamp:     1561000000,
+		Pid:           4242,
+		Comm:          "stress",
+		Cgroup:        "/docker/0f3c9eb3aa8f",
+		MemCgroupKill: true,
+		TotalVM:       268692,
+		AnonRSS:       261892,
+		FileRSS:       1012,
+		MemoryUsage:   262144,
+		MemoryLimit:   262144,
+	}, "myhost", []string{"process_name:stress"})
+
+	assert.Equal(t, "Process OOM killed: stress (pid 4242) on myhost", event.Title)
+	assert.Equal(t, metrics.EventAlertTypeError, event.AlertType)
+	assert.Contains(t, event.Text, "because its cgroup reached its memory limit (262144 kB used of 262144 kB)")
+	assert.Contains(t, event.Text, "Cgroup: /docker/0f3c9eb3aa8f")
+	assert.Contains(t, event.Text, "total-vm 268692 kB, anon-rss 261892 kB, file-rss 1012 kB, shmem-rss 0 kB")
+
+	event = toOOMKillEvent(oomkill.OOMKill{Pid: 1, Comm: "java"}, "myhost", nil)
+	assert.Contains(t, event.Text, "because the host ran out of memory")
+	assert.NotContains(t, event.Text, "Cgroup:")
+}
+
+func TestOOMKillContainerEntity(t *testing.T) {
+	id := "47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e"
+	assert.Equal(t, "docker://"+id, containerEntity(oomkill.OOMKill{Cgroup: "/docker/" + id, ContainerID: id}))
+	assert.Equal(t, "docker://"+id, containerEntity(oomkill.OOMKill{Cgroup: "/kubepods/besteffort/pod2baa3444/" + id, ContainerID: id}))
+	assert.Equal(t, "cri-o://"+id, containerEntity(oomkill.OOMKill{Cgroup: "/kubepods.slice/kubepods-pod2baa3444.slice/crio-" + id + ".scope", ContainerID: id}))
+	assert.Equal(t, "containerd://"+id, containerEntity(oomkill.OOMKill{Cgroup: "/kubepods.slice/kubepods-pod2baa3444.slice/cri-containerd-" + id + ".scope", ContainerID: id}))
+}
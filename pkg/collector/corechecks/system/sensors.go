@@ -0,0 +1,300 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	sensorsCheckName          = "sensors"
+	sensorsStatusServiceCheck = "system.sensors.status"
+)
+
+// For testing purpose
+var ipmiOutput = runIpmitool
+
+// sensorKind is a kind of sensor, with the prefix of its hwmon files, the
+// unit of its IPMI readings and the scale of its hwmon values
+type sensorKind struct {
+	metric   string
+	prefix   string
+	ipmiUnit string
+	scale    float64
+}
+
+var sensorKinds = []sensorKind{
+	{metric: "system.sensors.temperature", prefix: "temp", ipmiUnit: "degrees C", scale: 1e-3},
+	{metric: "system.sensors.fan_speed", prefix: "fan", ipmiUnit: "RPM", scale: 1},
+	{metric: "system.sensors.power", prefix: "power", ipmiUnit: "Watts", scale: 1e-6},
+	{metric: "system.sensors.voltage", prefix: "in", ipmiUnit: "Volts", scale: 1e-3},
+}
+
+// hwmonInputRe matches the files of the sensor readings, like temp1_input
+var hwmonInputRe = regexp.MustCompile(`^([a-z]+)(\d+)_input$`)
+
+// the status of the IPMI sensors, as computed by the BMC from their
+// thresholds; `ns` sensors have no reading and aren't reported
+var ipmiStatuses = map[string]metrics.ServiceCheckStatus{
+	"ok": metrics.ServiceCheckOK,
+	"nc": metrics.ServiceCheckWarning,
+	"cr": metrics.ServiceCheckCritical,
+	"nr": metrics.ServiceCheckCritical,
+}
+
+type sensorsInstanceConfig struct {
+	IPMI            bool   `yaml:"ipmi"`
+	IpmitoolPath    string `yaml:"ipmitool_path"`
+	IpmitoolTimeout int    `yaml:"ipmitool_timeout"`
+}
+
+// SensorsCheck reports the temperatures, fan speeds, power and voltages
+// of the hardware sensors exposed by the hwmon drivers and, optionally, by
+// the BMC through ipmitool. The status of every sensor with thresholds is
+// reported with a service check, against the thresholds set by the sensors
+// themselves.
+type SensorsCheck struct {
+	core.CheckBase
+	instance sensorsInstanceConfig
+}
+
+// Run executes the check
+func (c *SensorsCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	c.collectHwmon(sender)
+	if c.instance.IPMI {
+		if err = c.collectIPMI(sender); err != nil {
+			err = fmt.Errorf("could not read the IPMI sensors: %s", err)
+		}
+	}
+
+	sender.Commit()
+	return err
+}
+
+// collectHwmon reports the sensors of the hwmon devices, the thresholds of
+// a sensor are read from the files sharing the prefix of its input
+func (c *SensorsCheck) collectHwmon(sender aggregator.Sender) {
+	devices, err := filepath.Glob(filepath.Join(sysfsPath(), "class/hwmon/hwmon*"))
+	if err != nil || len(devices) == 0 {
+		log.Debugf("No hwmon device found")
+		return
+	}
+
+	for _, device := range devices {
+		hwmon := filepath.Base(device)
+		// the files are under the device directory with older kernels
+		files, err := ioutil.ReadDir(device)
+		if err == nil && !hasHwmonInputs(files) {
+			device = filepath.Join(device, "device")
+			files, err = ioutil.ReadDir(device)
+		}
+		if err != nil {
+			log.Debugf("Could not read the hwmon device %s: %s", device, err)
+			continue
+		}
+		chip := readHwmonString(filepath.Join(device, "name"))
+		if chip == "" {
+			chip = "unknown"
+		}
+
+		for _, file := range files {
+			m := hwmonInputRe.FindStringSubmatch(file.Name())
+			if m == nil {
+				continue
+			}
+			kind, found := hwmonKind(m[1])
+			if !found {
+				continue
+			}
+			prefix := filepath.Join(device, m[1]+m[2])
+			value, err := readHwmonValue(prefix+"_input", kind.scale)
+			if err != nil {
+				log.Debugf("Could not read the sensor %s: %s", prefix, err)
+				continue
+			}
+			label := readHwmonString(prefix + "_label")
+			if label == "" {
+				label = m[1] + m[2]
+			}
+
+			tags := []string{
+				"source:hwmon",
+				fmt.Sprintf("chip:%s", chip),
+				fmt.Sprintf("hwmon:%s", hwmon),
+				fmt.Sprintf("sensor:%s", label),
+			}
+			sender.Gauge(kind.metric, value, "", tags)
+			if status, message, found := hwmonStatus(prefix, kind.scale, value); found {
+				sender.ServiceCheck(sensorsStatusServiceCheck, status, "", tags, message)
+			}
+		}
+	}
+}
+
+func hasHwmonInputs(files []os.FileInfo) bool {
+	for _, file := range files {
+		if hwmonInputRe.MatchString(file.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+func hwmonKind(prefix string) (sensorKind, bool) {
+	for _, kind := range sensorKinds {
+		if kind.prefix == prefix {
+			return kind, true
+		}
+	}
+	return sensorKind{}, false
+}
+
+// hwmonStatus compares the value of a sensor with its thresholds, the
+// critical ones first, it returns false when the sensor has none
+func hwmonStatus(prefix string, scale float64, value float64) (metrics.ServiceCheckStatus, string, bool) {
+	found := false
+	thresholds := []struct {
+		suffix string
+		status metrics.ServiceCheckStatus
+		above  bool
+	}{
+		{"_crit", metrics.ServiceCheckCritical, true},
+		{"_lcrit", metrics.ServiceCheckCritical, false},
+		{"_max", metrics.ServiceCheckWarning, true},
+		{"_min", metrics.ServiceCheckWarning, false},
+	}
+	for _, t := range thresholds {
+		threshold, err := readHwmonValue(prefix+t.suffix, scale)
+		if err != nil {
+			continue
+		}
+		found = true
+		if t.above && value >= threshold {
+			return t.status, fmt.Sprintf("%g is above the threshold of %g", value, threshold), true
+		}
+		if !t.above && value <= threshold {
+			return t.status, fmt.Sprintf("%g is below the threshold of %g", value, threshold), true
+		}
+	}
+	return metrics.ServiceCheckOK, "", found
+}
+
+func readHwmonValue(path string, scale float64) (float64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(content)), 64)
+	return value * scale, err
+}
+
+func readHwmonString(path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// collectIPMI reports the sensors listed by `ipmitool -c sdr list`, whose
+// lines are `<name>,<value>,<unit>,<status>`. The discrete sensors, like
+// the status of the power supplies, are only reported with the service
+// check.
+func (c *SensorsCheck) collectIPMI(sender aggregator.Sender) error {
+	out, err := ipmiOutput(c.instance.IpmitoolPath, time.Duration(c.instance.IpmitoolTimeout)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(out))
+	reader.FieldsPerRecord = -1
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		name, reading, unit := strings.TrimSpace(fields[0]), fields[1], fields[2]
+		status, found := ipmiStatuses[fields[3]]
+		if !found {
+			continue
+		}
+
+		tags := []string{"source:ipmi", fmt.Sprintf("sensor:%s", name)}
+		for _, kind := range sensorKinds {
+			if kind.ipmiUnit != unit {
+				continue
+			}
+			if value, err := strconv.ParseFloat(reading, 64); err == nil {
+				sender.Gauge(kind.metric, value, "", tags)
+			}
+		}
+		message := ""
+		if status != metrics.ServiceCheckOK {
+			message = fmt.Sprintf("%s is in the %s state, reading %s %s", name, fields[3], reading, unit)
+		}
+		sender.ServiceCheck(sensorsStatusServiceCheck, status, "", tags, message)
+	}
+}
+
+func runIpmitool(path string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return exec.CommandContext(ctx, path, "-c", "sdr", "list").Output()
+}
+
+// Configure parses the check configuration
+func (c *SensorsCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+	c.instance = sensorsInstanceConfig{
+		IpmitoolPath:    "ipmitool",
+		IpmitoolTimeout: 10,
+	}
+	return yaml.Unmarshal(data, &c.instance)
+}
+
+func sensorsFactory() check.Check {
+	return &SensorsCheck{
+		CheckBase: core.NewCheckBase(sensorsCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(sensorsCheckName, sensorsFactory)
+}
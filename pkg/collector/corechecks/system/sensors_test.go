@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build linux
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+var ipmitoolSdrList = `CPU Temp,45,degrees C,ok
+System Fan 1,1200,RPM,nc
+PS1 Status,0x01,discrete,ok
+PS2 Status,0x00,discrete,cr
+PS2 Input Power,0,Watts,ns
+12V,12.10,Volts,ok
+`
+
+func TestSensorsHwmon(t *testing.T) {
+	root, err := ioutil.TempDir("", "sys")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	sysfsPath = func() string { return root }
+	defer func() { sysfsPath = hostSys }()
+
+	writeProcFiles(t, root, map[string]string{
+		"class/hwmon/hwmon0/name":                "coretemp",
+		"class/hwmon/hwmon0/temp1_label":         "Package id 0",
+		"class/hwmon/hwmon0/temp1_input":         "52000",
+		"class/hwmon/hwmon0/temp1_max":           "80000",
+		"class/hwmon/hwmon0/temp1_crit":          "100000",
+		"class/hwmon/hwmon0/temp2_input":         "85000",
+		"class/hwmon/hwmon0/temp2_max":           "80000",
+		"class/hwmon/hwmon0/temp2_crit":          "100000",
+		"class/hwmon/hwmon0/temp3_input":         "40000",
+		"class/hwmon/hwmon1/device/name":         "nct6775",
+		"class/hwmon/hwmon1/device/fan1_input":   "300",
+		"class/hwmon/hwmon1/device/fan1_min":     "600",
+		"class/hwmon/hwmon1/device/power1_input": "95500000",
+		"class/hwmon/hwmon1/device/in0_input":    "1024",
+	})
+
+	check := sensorsFactory().(*SensorsCheck)
+	require.NoError(t, check.Configure(nil, nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	coretemp := []string{"source:hwmon", "chip:coretemp", "hwmon:hwmon0"}
+	sender.AssertMetric(t, "Gauge", "system.sensors.temperature", 52, "", append(coretemp, "sensor:Package id 0"))
+	sender.AssertServiceCheck(t, sensorsStatusServiceCheck, metrics.ServiceCheckOK, "", append(coretemp, "sensor:Package id 0"), "")
+	sender.AssertMetric(t, "Gauge", "system.sensors.temperature", 85, "", append(coretemp, "sensor:temp2"))
+	sender.AssertCalled(t, "ServiceCheck", sensorsStatusServiceCheck, metrics.ServiceCheckWarning, "", []string{"source:hwmon", "chip:coretemp", "hwmon:hwmon0", "sensor:temp2"}, "85 is above the threshold of 80")
+	sender.AssertMetric(t, "Gauge", "system.sensors.temperature", 40, "", append(coretemp, "sensor:temp3"))
+	sender.AssertNumberOfCalls(t, "ServiceCheck", 3)
+
+	nct := []string{"source:hwmon", "chip:nct6775", "hwmon:hwmon1"}
+	sender.AssertMetric(t, "Gauge", "system.sensors.fan_speed", 300, "", append(nct, "sensor:fan1"))
+	sender.AssertCalled(t, "ServiceCheck", sensorsStatusServiceCheck, metrics.ServiceCheckWarning, "", []string{"source:hwmon", "chip:nct6775", "hwmon:hwmon1", "sensor:fan1"}, mock.Anything)
+	sender.AssertMetric(t, "Gauge", "system.sensors.power", 95.5, "", append(nct, "sensor:power1"))
+	sender.AssertMetric(t, "Gauge", "system.sensors.voltage", 1.024, "", append(nct, "sensor:in0"))
+}
+
+func TestSensorsIPMI(t *testing.T) {
+	sysfsPath = func() string { return "/nonexistent" }
+	defer func() { sysfsPath = hostSys }()
+	ipmiOutput = func(path string, timeout time.Duration) ([]byte, error) {
+		assert.Equal(t, "/usr/sbin/ipmitool", path)
+		assert.Equal(t, 10*time.Second, timeout)
+		return []byte(ipmitoolSdrList), nil
+	}
+	defer func() { ipmiOutput = runIpmitool }()
+
+	check := sensorsFactory().(*SensorsCheck)
+	require.NoError(t, check.Configure([]byte("ipmi: true\nipmitool_path: /usr/sbin/ipmitool"), nil))
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Gauge", "system.sensors.temperature", 45, "", []string{"source:ipmi", "sensor:CPU Temp"})
+	sender.AssertMetric(t, "Gauge", "system.sensors.fan_speed", 1200, "", []string{"sensor:System Fan 1"})
+	sender.AssertMetric(t, "Gauge", "system.sensors.voltage", 12.1, "", []string{"sensor:12V"})
+	sender.AssertNotCalled(t, "Gauge", "system.sensors.power", mock.Anything, mock.Anything, mock.Anything)
+
+	sender.AssertServiceCheck(t, sensorsStatusServiceCheck, metrics.ServiceCheckOK, "", []string{"source:ipmi", "sensor:PS1 Status"}, "")
+	sender.AssertCalled(t, "ServiceCheck", sensorsStatusServiceCheck, metrics.ServiceCheckWarning, "", []string{"source:ipmi", "sensor:System Fan 1"}, mock.Anything)
+	sender.AssertCalled(t, "ServiceCheck", sensorsStatusServiceCheck, metrics.ServiceCheckCritical, "", []string{"source:ipmi", "sensor:PS2 Status"}, "PS2 Status is in the cr state, reading 0x00 discrete")
+	sender.AssertNumberOfCalls(t, "ServiceCheck", 5)
+
+	// the failures of ipmitool are reported as the error of the run
+	ipmiOutput = func(path string, timeout time.Duration) ([]byte, error) {
+		return nil, fmt.Errorf("exit status 1")
+	}
+	assert.Error(t, check.Run())
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	// the source type of the events of the Python win32_event_log check
+	eventLogSourceTypeName = "event viewer"
+
+	levelCritical = 1
+	levelError    = 2
+	levelWarning  = 3
+
+	keywordAuditFailure = 0x10000000000000
+	keywordAuditSuccess = 0x20000000000000
+)
+
+// eventLogRecord is the System section of an event rendered as XML, see
+// https://docs.microsoft.com/en-us/windows/desktop/wes/eventschema-systempropertiestype-complextype
+type eventLogRecord struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID     int    `xml:"EventID"`
+		Level       int    `xml:"Level"`
+		Keywords    string `xml:"Keywords"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		EventRecordID uint64 `xml:"EventRecordID"`
+		Channel       string `xml:"Channel"`
+		Computer      string `xml:"Computer"`
+	} `xml:"System"`
+}
+
+func parseEventLogRecord(eventXML string) (eventLogRecord, error) {
+	var record eventLogRecord
+	err := xml.Unmarshal([]byte(eventXML), &record)
+	return record, err
+}
+
+// eventLogAlertType maps the level and the audit keywords of an event to an
+// alert type, like the Python win32_event_log check does
+func eventLogAlertType(record eventLogRecord) metrics.EventAlertType {
+	keywords, _ := strconv.ParseUint(strings.TrimPrefix(record.System.Keywords, "0x"), 16, 64)
+	switch {
+	case keywords&keywordAuditFailure != 0:
+		return metrics.EventAlertTypeError
+	case keywords&keywordAuditSuccess != 0:
+		return metrics.EventAlertTypeSuccess
+	case record.System.Level == levelCritical, record.System.Level == levelError:
+		return metrics.EventAlertTypeError
+	case record.System.Level == levelWarning:
+		return metrics.EventAlertTypeWarning
+	default:
+		return metrics.EventAlertTypeInfo
+	}
+}
+
+func toEventLogEvent(record eventLogRecord, message, hostname string) metrics.Event {
+	ts := time.Now().Unix()
+	if t, err := time.Parse(time.RFC3339Nano, record.System.TimeCreated.SystemTime); err == nil {
+		ts = t.Unix()
+	}
+
+	text := ""
+	if message != "" {
+		text = fmt.Sprintf("%%%%%%\n```\n%s\n```\n%%%%%%", message)
+	}
+
+	return metrics.Event{
+		Title:          fmt.Sprintf("%s/%s", record.System.Channel, record.System.Provider.Name),
+		Text:           text,
+		Ts:             ts,
+		Priority:       metrics.EventPriorityNormal,
+		Host:           hostname,
+		AlertType:      eventLogAlertType(record),
+		AggregationKey: record.System.Provider.Name,
+		SourceTypeName: eventLogSourceTypeName,
+		Tags: []string{
+			fmt.Sprintf("event_id:%d", record.System.EventID),
+			fmt.Sprintf("channel:%s", record.System.Channel),
+		},
+	}
+}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modWevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtSubscribe             = modWevtapi.NewProc("EvtSubscribe")
+	procEvtNext                  = modWevtapi.NewProc("EvtNext")
+	procEvtClose                 = modWevtapi.NewProc("EvtClose")
+	procEvtRender                = modWevtapi.NewProc("EvtRender")
+	procEvtCreateBookmark        = modWevtapi.NewProc("EvtCreateBookmark")
+	procEvtUpdateBookmark        = modWevtapi.NewProc("EvtUpdateBookmark")
+	procEvtOpenPublisherMetadata = modWevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtFormatMessage         = modWevtapi.NewProc("EvtFormatMessage")
+)
+
+// evtHandle is an EVT_HANDLE of the Windows Event Log API, see winevt.h
+type evtHandle uintptr
+
+const (
+	evtSubscribeToFutureEvents      = 1
+	evtSubscribeStartAtOldestRecord = 2
+	evtSubscribeStartAfterBookmark  = 3
+
+	evtRenderEventXML = 1
+	evtRenderBookmark = 2
+
+	evtFormatMessageEvent = 1
+
+	errorNoMoreItems syscall.Errno = 259
+	errorTimeout     syscall.Errno = 1460
+)
+
+// evtSubscribe subscribes to the events of a channel matching an XPath
+// query. The subscription is in pull mode: the events are read with evtNext.
+func evtSubscribe(signal windows.Handle, channel, query string, bookmark evtHandle, flags uint32) (evtHandle, error) {
+	channelPtr, err := windows.UTF16PtrFromString(channel)
+	if err != nil {
+		return 0, err
+	}
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return 0, err
+	}
+
+	h, _, err := procEvtSubscribe.Call(
+		0, // local session
+		uintptr(signal),
+		uintptr(unsafe.Pointer(channelPtr)),
+		uintptr(unsafe.Pointer(queryPtr)),
+		uintptr(bookmark),
+		0, // no context
+		0, // no callback, pull mode
+		uintptr(flags))
+	if h == 0 {
+		return 0, err
+	}
+	return evtHandle(h), nil
+}
+
+// evtNext returns the next events of a subscription, none once they were all
+// read. The events must be closed with evtClose.
+func evtNext(subscription evtHandle, max int) ([]evtHandle, error) {
+	events := make([]evtHandle, max)
+	var returned uint32
+	ret, _, err := procEvtNext.Call(
+		uintptr(subscription),
+		uintptr(max),
+		uintptr(unsafe.Pointer(&events[0])),
+		0, // don't wait for new events
+		0,
+		uintptr(unsafe.Pointer(&returned)))
+	if ret == 0 {
+		if err == errorNoMoreItems || err == errorTimeout {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return events[:returned], nil
+}
+
+func evtClose(h evtHandle) {
+	if h != 0 {
+		procEvtClose.Call(uintptr(h))
+	}
+}
+
+// evtRender renders an event or a bookmark as XML
+func evtRender(h evtHandle, flags uint32) (string, error) {
+	var used, properties uint32
+	ret, _, err := procEvtRender.Call(0, uintptr(h), uintptr(flags), 0, 0,
+		uintptr(unsafe.Pointer(&used)),
+		uintptr(unsafe.Pointer(&properties)))
+	if ret == 0 && err != windows.ERROR_INSUFFICIENT_BUFFER {
+		return "", err
+	}
+	if used == 0 {
+		return "", nil
+	}
+
+	// the sizes are in bytes
+	buf := make([]uint16, used/2)
+	ret, _, err = procEvtRender.Call(0, uintptr(h), uintptr(flags), uintptr(used),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&used)),
+		uintptr(unsafe.Pointer(&properties)))
+	if ret == 0 {
+		return "", err
+	}
+	return windows.UTF16ToString(buf), nil
+}
+
+// evtCreateBookmark creates a bookmark from its XML, an empty one without XML
+func evtCreateBookmark(bookmarkXML string) (evtHandle, error) {
+	var xmlPtr *uint16
+	if bookmarkXML != "" {
+		var err error
+		if xmlPtr, err = windows.UTF16PtrFromString(bookmarkXML); err != nil {
+			return 0, err
+		}
+	}
+	h, _, err := procEvtCreateBookmark.Call(uintptr(unsafe.Pointer(xmlPtr)))
+	if h == 0 {
+		return 0, err
+	}
+	return evtHandle(h), nil
+}
+
+func evtUpdateBookmark(bookmark, event evtHandle) error {
+	ret, _, err := procEvtUpdateBookmark.Call(uintptr(bookmark), uintptr(event))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// evtOpenPublisherMetadata opens the metadata of a provider, holding the
+// templates of the messages of its events
+func evtOpenPublisherMetadata(provider string) (evtHandle, error) {
+	providerPtr, err := windows.UTF16PtrFromString(provider)
+	if err != nil {
+		return 0, err
+	}
+	h, _, err := procEvtOpenPublisherMetadata.Call(0, uintptr(unsafe.Pointer(providerPtr)), 0, 0, 0)
+	if h == 0 {
+		return 0, err
+	}
+	return evtHandle(h), nil
+}
+
+// evtFormatMessage returns the message of an event, formatted with the
+// metadata of its provider
+func evtFormatMessage(metadata, event evtHandle) (string, error) {
+	var used uint32
+	ret, _, err := procEvtFormatMessage.Call(uintptr(metadata), uintptr(event), 0, 0, 0, evtFormatMessageEvent, 0, 0,
+		uintptr(unsafe.Pointer(&used)))
+	if ret == 0 && err != windows.ERROR_INSUFFICIENT_BUFFER {
+		return "", err
+	}
+	if used == 0 {
+		return "", nil
+	}
+
+	// the sizes are in characters
+	buf := make([]uint16, used)
+	ret, _, err = procEvtFormatMessage.Call(uintptr(metadata), uintptr(event), 0, 0, 0, evtFormatMessageEvent,
+		uintptr(used),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&used)))
+	if ret == 0 {
+		return "", err
+	}
+	return windows.UTF16ToString(buf), nil
+}
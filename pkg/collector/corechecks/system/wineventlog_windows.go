@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	wineventlogCheckName = "wineventlog"
+	eventLogBatchSize    = 100
+
+	eventLogStartNow    = "now"
+	eventLogStartOldest = "oldest"
+)
+
+type wineventlogInstanceConfig struct {
+	ChannelPath string `yaml:"channel_path"`
+	Query       string `yaml:"query"`
+	Start       string `yaml:"start"`
+}
+
+// wineventlogCheck forwards the records of a Windows Event Log channel
+// matching an XPath query as events. The position in the channel is kept
+// in a bookmark saved after every run, so that the records logged while
+// the agent is stopped are forwarded when it starts again.
+type wineventlogCheck struct {
+	core.CheckBase
+	config       wineventlogInstanceConfig
+	hostname     string
+	bookmarkPath string
+
+	signal       windows.Handle
+	subscription evtHandle
+	bookmark     evtHandle
+	// the metadata of the providers, to format the messages of their events
+	publishers map[string]evtHandle
+}
+
+// Run executes the check
+func (c *wineventlogCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	if c.subscription == 0 {
+		if err := c.subscribe(); err != nil {
+			return err
+		}
+	}
+
+	forwarded, err := c.forwardEvents(sender)
+	sender.Commit()
+	if forwarded > 0 {
+		c.saveBookmark()
+	}
+	if err != nil {
+		// subscribe again from the bookmark at the next run
+		c.unsubscribe()
+		return fmt.Errorf("could not read the events of the channel %s: %s", c.config.ChannelPath, err)
+	}
+	return nil
+}
+
+// forwardEvents forwards the events logged since the previous run and
+// returns their number
+func (c *wineventlogCheck) forwardEvents(sender aggregator.Sender) (int, error) {
+	forwarded := 0
+	for {
+		events, err := evtNext(c.subscription, eventLogBatchSize)
+		if err != nil || len(events) == 0 {
+			return forwarded, err
+		}
+		for _, event := range events {
+			c.forwardEvent(sender, event)
+			if err := evtUpdateBookmark(c.bookmark, event); err != nil {
+				log.Warnf("Could not update the bookmark of the channel %s: %s", c.config.ChannelPath, err)
+			}
+			evtClose(event)
+		}
+		forwarded += len(events)
+	}
+}
+
+func (c *wineventlogCheck) forwardEvent(sender aggregator.Sender, event evtHandle) {
+	eventXML, err := evtRender(event, evtRenderEventXML)
+	if err != nil {
+		log.Warnf("Could not render an event of the channel %s: %s", c.config.ChannelPath, err)
+		return
+	}
+	record, err := parseEventLogRecord(eventXML)
+	if err != nil {
+		log.Warnf("Could not parse an event of the channel %s: %s", c.config.ChannelPath, err)
+		return
+	}
+	sender.Event(toEventLogEvent(record, c.formatMessage(record.System.Provider.Name, event), c.hostname))
+}
+
+// formatMessage returns the message of an event, empty if its provider
+// isn't installed on the host
+func (c *wineventlogCheck) formatMessage(provider string, event evtHandle) string {
+	metadata, found := c.publishers[provider]
+	if !found {
+		var err error
+		if metadata, err = evtOpenPublisherMetadata(provider); err != nil {
+			log.Debugf("Could not open the metadata of the provider %s: %s", provider, err)
+		}
+		c.publishers[provider] = metadata
+	}
+	if metadata == 0 {
+		return ""
+	}
+
+	message, err := evtFormatMessage(metadata, event)
+	if err != nil {
+		log.Debugf("Could not format the message of an event of %s: %s", provider, err)
+	}
+	return message
+}
+
+// subscribe subscribes to the channel after the saved bookmark, or from
+// the configured start without bookmark
+func (c *wineventlogCheck) subscribe() error {
+	flags := uint32(evtSubscribeToFutureEvents)
+	if c.config.Start == eventLogStartOldest {
+		flags = evtSubscribeStartAtOldestRecord
+	}
+
+	bookmarkXML, err := ioutil.ReadFile(c.bookmarkPath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not read the bookmark of the channel %s: %s", c.config.ChannelPath, err)
+	}
+	if c.bookmark, err = evtCreateBookmark(string(bookmarkXML)); err != nil {
+		log.Warnf("Invalid bookmark %s, ignoring it: %s", c.bookmarkPath, err)
+		if c.bookmark, err = evtCreateBookmark(""); err != nil {
+			return fmt.Errorf("could not create a bookmark: %s", err)
+		}
+	} else if len(bookmarkXML) > 0 {
+		flags = evtSubscribeStartAfterBookmark
+	}
+
+	if c.signal, err = windows.CreateEvent(nil, 1, 0, nil); err != nil {
+		c.unsubscribe()
+		return err
+	}
+	if c.subscription, err = evtSubscribe(c.signal, c.config.ChannelPath, c.config.Query, c.bookmark, flags); err != nil {
+		c.unsubscribe()
+		return fmt.Errorf("could not subscribe to the channel %s with the query %s: %s", c.config.ChannelPath, c.config.Query, err)
+	}
+	return nil
+}
+
+func (c *wineventlogCheck) unsubscribe() {
+	evtClose(c.subscription)
+	evtClose(c.bookmark)
+	if c.signal != 0 {
+		windows.CloseHandle(c.signal)
+	}
+	c.subscription, c.bookmark, c.signal = 0, 0, 0
+}
+
+func (c *wineventlogCheck) saveBookmark() {
+	bookmarkXML, err := evtRender(c.bookmark, evtRenderBookmark)
+	if err == nil {
+		err = ioutil.WriteFile(c.bookmarkPath, []byte(bookmarkXML), 0600)
+	}
+	if err != nil {
+		log.Warnf("Could not save the bookmark of the channel %s: %s", c.config.ChannelPath, err)
+	}
+}
+
+// Stop closes the subscription
+func (c *wineventlogCheck) Stop() {
+	c.unsubscribe()
+	for provider, metadata := range c.publishers {
+		evtClose(metadata)
+		delete(c.publishers, provider)
+	}
+}
+
+// Configure configures the wineventlog check
+func (c *wineventlogCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	conf := wineventlogInstanceConfig{
+		Query: "*",
+		Start: eventLogStartNow,
+	}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+	if conf.ChannelPath == "" {
+		return fmt.Errorf("no channel to subscribe to, set `channel_path`")
+	}
+	if conf.Start != eventLogStartNow && conf.Start != eventLogStartOldest {
+		return fmt.Errorf("invalid start %s, must be %s or %s", conf.Start, eventLogStartNow, eventLogStartOldest)
+	}
+
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Can't get hostname from the wineventlog check: %s", err)
+	}
+
+	c.config = conf
+	c.hostname = hostname
+	c.publishers = make(map[string]evtHandle)
+	// the bookmarks are kept in the run directory, like the registry of the logs agent
+	c.bookmarkPath = filepath.Join(config.Datadog.GetString("logs_config.run_path"),
+		fmt.Sprintf("%s.xml", strings.Replace(string(c.ID()), ":", "_", -1)))
+	return nil
+}
+
+func wineventlogFactory() check.Check {
+	return &wineventlogCheck{
+		CheckBase: core.NewCheckBase(wineventlogCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(wineventlogCheckName, wineventlogFactory)
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const serviceStoppedEventXML = `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+  <System>
+    <Provider Name='Service Control Manager' Guid='{555908d1-a6d7-4695-8e1e-26931d2012f4}' EventSourceName='Service Control Manager'/>
+    <EventID Qualifiers='49152'>7034</EventID>
+    <Version>0</Version>
+    <Level>2</Level>
+    <Task>0</Task>
+    <Opcode>0</Opcode>
+    <Keywords>0x8080000000000000</Keywords>
+    <TimeCreated SystemTime='2019-06-20T13:31:40.123456700Z'/>
+    <EventRecordID>4242</EventRecordID>
+    <Correlation/>
+    <Execution ProcessID='620' ThreadID='5476'/>
+    <Channel>System</Channel>
+    <Computer>WIN-HOST</Computer>
+    <Security/>
+  </System>
+  <EventData>
+    <Data Name='param1'>Datadog Agent</Data>
+    <Data Name='param2'>1</Data>
+  </EventData>
+</Event>`
+
+func TestParseEventLogRecord(t *testing.T) {
+	record, err := parseEventLogRecord(serviceStoppedEventXML)
+	require.NoError(t, err)
+	assert.Equal(t, "Service Control Manager", record.System.Provider.Name)
+	assert.Equal(t, 7034, record.System.EventID)
+	assert.Equal(t, 2, record.System.Level)
+	assert.Equal(t, uint64(4242), record.System.EventRecordID)
+	assert.Equal(t, "System", record.System.Channel)
+
+	_, err = parseEventLogRecord("<Event>")
+	assert.Error(t, err)
+}
+
+func TestEventLogEvent(t *testing.T) {
+	record, err := parseEventLogRecord(serviceStoppedEventXML)
+	require.NoError(t, err)
+
+	event := toEventLogEvent(record, "The Datadog Agent service terminated unexpectedly.", "myhost")
+	assert.Equal(t, metrics.Event{
+		Title:          "System/Service Control Manager",
+		Text:           "%%%\n```\nThe Datadog Agent service terminated unexpectedly.\n```\n%%%",
+		Ts:             1561037500,
+		Priority:       metrics.EventPriorityNormal,
+		Host:           "myhost",
+		AlertType:      metrics.EventAlertTypeError,
+		AggregationKey: "Service Control Manager",
+		SourceTypeName: "event viewer",
+		Tags:           []string{"event_id:7034", "channel:System"},
+	}, event)
+
+	event = toEventLogEvent(record, "", "myhost")
+	assert.Empty(t, event.Text)
+}
+
+func TestEventLogAlertType(t *testing.T) {
+	for _, tc := range []struct {
+		level    int
+		keywords string
+		expected metrics.EventAlertType
+	}{
+		{1, "0x80000000000000", metrics.EventAlertTypeError},
+		{2, "0x80000000000000", metrics.EventAlertTypeError},
+		{3, "0x80000000000000", metrics.EventAlertTypeWarning},
+		{4, "0x80000000000000", metrics.EventAlertTypeInfo},
+		{0, "0x8010000000000000", metrics.EventAlertTypeError},
+		{0, "0x8020000000000000", metrics.EventAlertTypeSuccess},
+	} {
+		var record eventLogRecord
+		record.System.Level = tc.level
+		record.System.Keywords = tc.keywords
+		assert.Equal(t, tc.expected, eventLogAlertType(record), "level %d keywords %s", tc.level, tc.keywords)
+	}
+}
+
+func TestWineventlogConfigure(t *testing.T) {
+	check := wineventlogFactory().(*wineventlogCheck)
+	assert.Error(t, check.Configure([]byte("query: '*'"), nil))
+	assert.Error(t, check.Configure([]byte("channel_path: System\nstart: yesterday"), nil))
+
+	require.NoError(t, check.Configure([]byte("channel_path: System"), nil))
+	assert.Equal(t, "*", check.config.Query)
+	assert.Equal(t, "now", check.config.Start)
+	assert.Contains(t, check.bookmarkPath, "wineventlog_")
+}
@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	winservicesCheckName = "winservices"
+	winservicesStateName = "windows_service.state"
+)
+
+// For testing purpose
+var connectServiceManager = connectSCM
+
+type winservicesInstanceConfig struct {
+	Services []string `yaml:"services"`
+}
+
+// serviceStatus is the state and the start type of a service
+type serviceStatus struct {
+	state     svc.State
+	startType uint32
+}
+
+// serviceManager queries the services of the service control manager
+type serviceManager interface {
+	ListServices() ([]string, error)
+	QueryService(name string) (serviceStatus, error)
+	Disconnect() error
+}
+
+type scmManager struct {
+	m *mgr.Mgr
+}
+
+// connectSCM connects to the service control manager. mgr.Connect and
+// mgr.OpenService ask for all access rights, which the agent doesn't have
+// when running as a non-admin user, so only the query rights are requested.
+func connectSCM() (serviceManager, error) {
+	h, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT|windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil, err
+	}
+	return &scmManager{m: &mgr.Mgr{Handle: h}}, nil
+}
+
+func (s *scmManager) ListServices() ([]string, error) {
+	return s.m.ListServices()
+}
+
+func (s *scmManager) QueryService(name string) (serviceStatus, error) {
+	h, err := windows.OpenService(s.m.Handle, syscall.StringToUTF16Ptr(name), windows.SERVICE_QUERY_STATUS|windows.SERVICE_QUERY_CONFIG)
+	if err != nil {
+		return serviceStatus{}, err
+	}
+	service := &mgr.Service{Name: name, Handle: h}
+	defer service.Close()
+
+	status, err := service.Query()
+	if err != nil {
+		return serviceStatus{}, err
+	}
+	config, err := service.Config()
+	if err != nil {
+		return serviceStatus{}, err
+	}
+	return serviceStatus{state: status.State, startType: config.StartType}, nil
+}
+
+func (s *scmManager) Disconnect() error {
+	return s.m.Disconnect()
+}
+
+// winservicesCheck reports the state of the configured Windows services
+type winservicesCheck struct {
+	core.CheckBase
+	patterns []string
+}
+
+// Run executes the check
+func (c *winservicesCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	m, err := connectServiceManager()
+	if err != nil {
+		return fmt.Errorf("could not connect to the service control manager: %s", err)
+	}
+	defer m.Disconnect()
+
+	names, err := m.ListServices()
+	if err != nil {
+		return fmt.Errorf("could not list the services: %s", err)
+	}
+
+	matched := make(map[string]bool, len(c.patterns))
+	for _, name := range names {
+		pattern, found := c.match(name)
+		if !found {
+			continue
+		}
+		matched[pattern] = true
+
+		status, err := m.QueryService(name)
+		if err != nil {
+			log.Warnf("Could not query the service %s: %s", name, err)
+			sender.ServiceCheck(winservicesStateName, metrics.ServiceCheckUnknown, "", []string{fmt.Sprintf("windows_service:%s", name)}, err.Error())
+			continue
+		}
+		tags := []string{
+			fmt.Sprintf("windows_service:%s", name),
+			fmt.Sprintf("windows_service_start_type:%s", startTypeName(status.startType)),
+		}
+		sender.ServiceCheck(winservicesStateName, serviceCheckStatus(status.state), "", tags, "")
+	}
+
+	// the services that don't exist are reported too, they may have been uninstalled
+	for _, pattern := range c.patterns {
+		if !matched[pattern] {
+			sender.ServiceCheck(winservicesStateName, metrics.ServiceCheckUnknown, "", []string{fmt.Sprintf("windows_service:%s", pattern)}, "service not found")
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// match returns the first pattern matching the name of a service, the names
+// of the services aren't case sensitive.
+func (c *winservicesCheck) match(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, pattern := range c.patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), lower); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// serviceCheckStatus maps the state of a service to a service check status:
+// pending and paused services are reported as warnings
+func serviceCheckStatus(state svc.State) metrics.ServiceCheckStatus {
+	switch state {
+	case svc.Running:
+		return metrics.ServiceCheckOK
+	case svc.Stopped:
+		return metrics.ServiceCheckCritical
+	case svc.StartPending, svc.StopPending, svc.ContinuePending, svc.PausePending, svc.Paused:
+		return metrics.ServiceCheckWarning
+	default:
+		return metrics.ServiceCheckUnknown
+	}
+}
+
+func startTypeName(startType uint32) string {
+	switch startType {
+	case windows.SERVICE_BOOT_START:
+		return "boot"
+	case windows.SERVICE_SYSTEM_START:
+		return "system"
+	case mgr.StartAutomatic:
+		return "automatic"
+	case mgr.StartManual:
+		return "manual"
+	case mgr.StartDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
+// Configure the winservices check
+func (c *winservicesCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.CommonConfigure(data); err != nil {
+		return err
+	}
+
+	conf := winservicesInstanceConfig{}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+	if len(conf.Services) == 0 {
+		return fmt.Errorf("no services to monitor, set `services`")
+	}
+	for _, pattern := range conf.Services {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid service pattern %s: %s", pattern, err)
+		}
+	}
+	c.patterns = conf.Services
+	return nil
+}
+
+func winservicesFactory() check.Check {
+	return &winservicesCheck{
+		CheckBase: core.NewCheckBase(winservicesCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(winservicesCheckName, winservicesFactory)
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+type mockServiceManager struct {
+	services map[string]serviceStatus
+}
+
+func (m *mockServiceManager) ListServices() ([]string, error) {
+	names := []string{}
+	for name := range m.services {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m *mockServiceManager) QueryService(name string) (serviceStatus, error) {
+	status, found := m.services[name]
+	if !found {
+		return serviceStatus{}, fmt.Errorf("service %s not found", name)
+	}
+	return status, nil
+}
+
+func (m *mockServiceManager) Disconnect() error {
+	return nil
+}
+
+func TestWinservicesCheck(t *testing.T) {
+	connectServiceManager = func() (serviceManager, error) {
+		return &mockServiceManager{
+			services: map[string]serviceStatus{
+				"DatadogAgent": {state: svc.Running, startType: mgr.StartAutomatic},
+				"W3SVC":        {state: svc.Stopped, startType: mgr.StartManual},
+				"wuauserv":     {state: svc.StartPending, startType: mgr.StartDisabled},
+				"Spooler":      {state: svc.Running, startType: mgr.StartAutomatic},
+			},
+		}, nil
+	}
+	defer func() { connectServiceManager = connectSCM }()
+
+	check := new(winservicesCheck)
+	require.NoError(t, check.Configure(integration.Data("services:\n  - datadog*\n  - w3svc\n  - WuauServ\n  - missing"), nil))
+
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	mock.AssertServiceCheck(t, winservicesStateName, metrics.ServiceCheckOK, "", []string{"windows_service:DatadogAgent", "windows_service_start_type:automatic"}, "")
+	mock.AssertServiceCheck(t, winservicesStateName, metrics.ServiceCheckCritical, "", []string{"windows_service:W3SVC", "windows_service_start_type:manual"}, "")
+	mock.AssertServiceCheck(t, winservicesStateName, metrics.ServiceCheckWarning, "", []string{"windows_service:wuauserv", "windows_service_start_type:disabled"}, "")
+	mock.AssertServiceCheck(t, winservicesStateName, metrics.ServiceCheckUnknown, "", []string{"windows_service:missing"}, "service not found")
+	mock.AssertNumberOfCalls(t, "ServiceCheck", 4)
+}
+
+func TestWinservicesConfigure(t *testing.T) {
+	check := new(winservicesCheck)
+	assert.Error(t, check.Configure(integration.Data("tags: [foo:bar]"), nil))
+	assert.Error(t, check.Configure(integration.Data("services: ['[a-']"), nil))
+	assert.NoError(t, check.Configure(integration.Data("services: [dd*]"), nil))
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package profiling captures the CPU and heap profiles of check runs, to
+// attribute the resource usage of the agent to an integration.
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+// invalidFileChars are the characters of the check IDs replaced in the
+// names of the profiles
+var invalidFileChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// active is 1 while a check is profiled
+var active int32
+
+// Profiler profiles the runs of a check between Start and Stop
+type Profiler struct {
+	prefix  string
+	cpuFile *os.File
+}
+
+// Start starts the CPU profiler for a check, it must be called from the
+// goroutine running the check. The CPU profile covers the whole agent, the
+// samples of the check are labeled with its name and ID. Only one check can
+// be profiled at a time.
+func Start(c check.Check, dir string) (p *Profiler, err error) {
+	if !atomic.CompareAndSwapInt32(&active, 0, 1) {
+		return nil, fmt.Errorf("another check is being profiled")
+	}
+	defer func() {
+		if err != nil {
+			atomic.StoreInt32(&active, 0)
+		}
+	}()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	name := invalidFileChars.ReplaceAllString(string(c.ID()), "_")
+	p = &Profiler{
+		prefix: filepath.Join(dir, fmt.Sprintf("%s-%s", name, time.Now().Format("20060102-150405"))),
+	}
+
+	f, err := os.Create(p.prefix + ".cpu.pprof")
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("unable to start the CPU profiler: %s", err)
+	}
+	p.cpuFile = f
+
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("check", c.String(), "check_id", string(c.ID()))))
+	return p, nil
+}
+
+// Stop stops the CPU profiler, writes a heap profile and returns the paths
+// of the profiles
+func (p *Profiler) Stop() ([]string, error) {
+	pprof.StopCPUProfile()
+	pprof.SetGoroutineLabels(context.Background())
+	atomic.StoreInt32(&active, 0)
+	if err := p.cpuFile.Close(); err != nil {
+		return nil, err
+	}
+
+	heapPath := p.prefix + ".heap.pprof"
+	f, err := os.Create(heapPath)
+	if err != nil {
+		return []string{p.cpuFile.Name()}, err
+	}
+	defer f.Close()
+	// collect the garbage so that the profile shows the memory in use
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return []string{p.cpuFile.Name()}, err
+	}
+	return []string{p.cpuFile.Name(), heapPath}, nil
+}
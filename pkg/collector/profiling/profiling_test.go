@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package profiling
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+type testCheck struct{}
+
+func (c *testCheck) String() string                                     { return "redisdb" }
+func (c *testCheck) Version() string                                    { return "" }
+func (c *testCheck) Stop()                                              {}
+func (c *testCheck) Configure(integration.Data, integration.Data) error { return nil }
+func (c *testCheck) Interval() time.Duration                            { return 15 * time.Second }
+func (c *testCheck) Run() error                                         { return nil }
+func (c *testCheck) ID() check.ID                                       { return "redisdb:main/db:5f3e" }
+func (c *testCheck) GetWarnings() []error                               { return nil }
+func (c *testCheck) GetMetricStats() (map[string]int64, error)          { return nil, nil }
+
+func TestProfiler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "check_profiles")
+
+	p, err := Start(&testCheck{}, dir)
+	require.NoError(t, err)
+
+	// the CPU profiler is shared by the whole process
+	_, err = Start(&testCheck{}, dir)
+	assert.Error(t, err)
+
+	paths, err := p.Stop()
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.True(t, strings.HasPrefix(filepath.Base(paths[0]), "redisdb_main_db_5f3e-"))
+	assert.True(t, strings.HasSuffix(paths[0], ".cpu.pprof"))
+	assert.True(t, strings.HasSuffix(paths[1], ".heap.pprof"))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size())
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
@@ -24,7 +24,7 @@ var filter *containers.Filter
 
 // IsContainerExcluded returns whether a container should be excluded,
 // based on it's name and image name. Exclusion patterns are configured
-// via the global options (ac_include/ac_exclude/exclude_pause_container)
+// via the global options (container_include/container_exclude/exclude_pause_container)
 //export IsContainerExcluded
 func IsContainerExcluded(name, image *C.char) C.int {
 	// If init failed, fallback to False
@@ -35,7 +35,7 @@ func IsContainerExcluded(name, image *C.char) C.int {
 	goName := C.GoString(name)
 	goImg := C.GoString(image)
 
-	if filter.IsExcluded(goName, goImg) {
+	if filter.IsExcluded(goName, goImg, "") {
 		return 1
 	}
 	return 0
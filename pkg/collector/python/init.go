@@ -63,6 +63,7 @@ void Headers(char **);
 void GetConfig(char*, char **);
 void LogMessage(char *, int);
 void SetExternalTags(char *, char *, char **);
+void SetCheckMetadata(char *, char *, char *);
 
 void initDatadogAgentModule(six_t *six) {
 	set_get_version_cb(six, GetVersion);
@@ -72,6 +73,7 @@ void initDatadogAgentModule(six_t *six) {
 	set_log_cb(six, LogMessage);
 	set_get_config_cb(six, GetConfig);
 	set_set_external_tags_cb(six, SetExternalTags);
+	set_set_check_metadata_cb(six, SetCheckMetadata);
 }
 
 //
@@ -71,6 +71,13 @@ func init() {
 const agentCheckClassName = "AgentCheck"
 const agentCheckModuleName = "checks"
 
+// coreLoaderOptions holds the checks also implemented in Go, and the
+// option letting the core loader load them instead
+var coreLoaderOptions = map[string]string{
+	"disk":      "disk_check.use_core_loader",
+	"go_expvar": "go_expvar_check.use_core_loader",
+}
+
 // PythonCheckLoader is a specific loader for checks living in Python modules
 type PythonCheckLoader struct{}
 
@@ -94,6 +101,11 @@ func (cl *PythonCheckLoader) Load(config integration.Config) ([]check.Check, err
 		return nil, fmt.Errorf("python is not initialized")
 	}
 
+	// the check is also implemented in Go, let the core loader load it
+	if option, found := coreLoaderOptions[config.Name]; found && agentConfig.Datadog.GetBool(option) {
+		return nil, fmt.Errorf("the %s check is loaded by the core loader, see %s", config.Name, option)
+	}
+
 	checks := []check.Check{}
 	moduleName := config.Name
 
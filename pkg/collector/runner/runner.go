@@ -8,6 +8,7 @@ package runner
 import (
 	"expvar"
 	"fmt"
+	"sort"
 	"strings"
 
 	"strconv"
@@ -17,9 +18,11 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/profiling"
 	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -40,6 +43,10 @@ var (
 	maxNumWorkers     = 25
 	runnerStats       *expvar.Map
 	checkStats        *runnerCheckStats
+
+	tlmRuns          = telemetry.NewCounter("checks", "runs", []string{"check_name", "state"}, "Number of check runs, by check and state (ok, warning or error).")
+	tlmRunning       = telemetry.NewGauge("checks", "running", nil, "Number of checks currently running.")
+	tlmExecutionTime = telemetry.NewHistogram("checks", "execution_time_seconds", []string{"check_name"}, "Execution time of the check runs, by check.", nil)
 )
 
 func init() {
@@ -59,11 +66,16 @@ type runnerCheckStats struct {
 // Runner ...
 type Runner struct {
 	pending          chan check.Check         // The channel where checks come from
+	isolated         chan check.Check         // The channel of the isolated checks, run by their own workers
+	isolatedChecks   map[string]bool          // The names of the checks run by the isolated workers
+	isolatedQueued   map[check.ID]bool        // The isolated checks waiting for an isolated worker
+	profiledChecks   map[string]bool          // The names or IDs of the checks profiled on their next run
 	runningChecks    map[check.ID]check.Check // The list of checks running
 	scheduler        *scheduler.Scheduler     // Scheduler runner operates on
 	m                sync.Mutex               // To control races on runningChecks
 	running          uint32                   // Flag to see if the Runner is, well, running
 	staticNumWorkers bool                     // Flag indicating if numWorkers is dynamically updated
+	workersToStop    int32                    // Number of workers to stop once they're done with their check
 }
 
 // NewRunner takes the number of desired goroutines processing incoming checks.
@@ -77,6 +89,9 @@ func NewRunner() *Runner {
 	r := &Runner{
 		// initialize the channel
 		pending:          make(chan check.Check),
+		isolatedChecks:   make(map[string]bool),
+		isolatedQueued:   make(map[check.ID]bool),
+		profiledChecks:   make(map[string]bool),
 		runningChecks:    make(map[check.ID]check.Check),
 		running:          1,
 		staticNumWorkers: numWorkers != 0,
@@ -91,6 +106,25 @@ func NewRunner() *Runner {
 		r.AddWorker()
 	}
 
+	// start the workers of the isolated checks, so that slow checks don't
+	// hold the other workers
+	for _, name := range config.Datadog.GetStringSlice("isolated_checks") {
+		r.isolatedChecks[name] = true
+	}
+	if len(r.isolatedChecks) > 0 {
+		numIsolatedWorkers := config.Datadog.GetInt("isolated_check_runners")
+		if numIsolatedWorkers < 1 {
+			numIsolatedWorkers = 1
+		}
+		r.isolated = make(chan check.Check, maxNumWorkers)
+		for i := 0; i < numIsolatedWorkers; i++ {
+			runnerStats.Add("IsolatedWorkers", 1)
+			TestWg.Add(1)
+			go r.workIsolated()
+		}
+		log.Infof("Runner started %d workers for the isolated checks.", numIsolatedWorkers)
+	}
+
 	log.Infof("Runner started with %d workers.", numWorkers)
 	return r
 }
@@ -102,11 +136,59 @@ func (r *Runner) AddWorker() {
 	go r.work()
 }
 
+// SetNumWorkers sets the number of workers at runtime, workers are stopped
+// once they're done with their current check. 0 lets the runner update the
+// number of workers with the number of checks again, through
+// UpdateNumWorkers.
+func (r *Runner) SetNumWorkers(numWorkers int) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if numWorkers > maxNumWorkers {
+		log.Warnf("Requested number of checks workers (%v) is too high: %v will be used", numWorkers, maxNumWorkers)
+		numWorkers = maxNumWorkers
+	}
+	r.staticNumWorkers = numWorkers > 0
+	if !r.staticNumWorkers {
+		log.Infof("The number of workers is now updated with the number of checks")
+		return
+	}
+
+	current, _ := strconv.Atoi(runnerStats.Get("Workers").String())
+	current -= int(atomic.LoadInt32(&r.workersToStop))
+	for ; current < numWorkers; current++ {
+		// cancel the pending stops first
+		if !r.cancelWorkerStop() {
+			r.AddWorker()
+		}
+	}
+	if current > numWorkers {
+		atomic.AddInt32(&r.workersToStop, int32(current-numWorkers))
+	}
+	log.Infof("Runner set to %d workers", numWorkers)
+}
+
+// cancelWorkerStop cancels the stop of a worker, if any is pending
+func (r *Runner) cancelWorkerStop() bool {
+	for {
+		n := atomic.LoadInt32(&r.workersToStop)
+		if n <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&r.workersToStop, n, n-1) {
+			return true
+		}
+	}
+}
+
 // UpdateNumWorkers checks if the current number of workers is reasonable, and adds more if needed
 func (r *Runner) UpdateNumWorkers(numChecks int64) {
 	numWorkers, _ := strconv.Atoi(runnerStats.Get("Workers").String())
 
-	if r.staticNumWorkers {
+	r.m.Lock()
+	staticNumWorkers := r.staticNumWorkers
+	r.m.Unlock()
+	if staticNumWorkers {
 		return
 	}
 
@@ -155,6 +237,9 @@ func (r *Runner) Stop() {
 
 	// stop checks that are still running
 	r.m.Lock()
+	if r.isolated != nil {
+		close(r.isolated)
+	}
 	globalDone := make(chan struct{})
 	wg := sync.WaitGroup{}
 
@@ -238,6 +323,62 @@ func (r *Runner) StopCheck(id check.ID) error {
 	}
 }
 
+// ProfileChecks profiles the CPU and heap usage of the next run of the
+// given checks, replacing the ones still waiting for their run. A check
+// name profiles one of its instances, a check ID that instance.
+func (r *Runner) ProfileChecks(names []string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.profiledChecks = make(map[string]bool, len(names))
+	for _, name := range names {
+		r.profiledChecks[name] = true
+	}
+}
+
+// ProfiledChecks returns the sorted names and IDs of the checks waiting for
+// their profiled run
+func (r *Runner) ProfiledChecks() []string {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	names := make([]string, 0, len(r.profiledChecks))
+	for name := range r.profiledChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// startProfiling starts profiling a run of check if it was requested, it
+// returns nil otherwise
+func (r *Runner) startProfiling(check check.Check) *profiling.Profiler {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	name, id := check.String(), string(check.ID())
+	if !r.profiledChecks[name] && !r.profiledChecks[id] {
+		return nil
+	}
+	if check.Interval() == 0 {
+		log.Warnf("Not profiling %s, a long running check", id)
+		delete(r.profiledChecks, id)
+		return nil
+	}
+	profiler, err := profiling.Start(check, config.Datadog.GetString("check_profiles_dir"))
+	if err != nil {
+		// the profile is taken on a later run
+		log.Warnf("Unable to profile the run of %s: %s", id, err)
+		return nil
+	}
+	if r.profiledChecks[id] {
+		delete(r.profiledChecks, id)
+	} else {
+		delete(r.profiledChecks, name)
+	}
+	return profiler
+}
+
 // work waits for checks and run them as long as they arrive on the channel
 func (r *Runner) work() {
 	log.Debug("Ready to process checks...")
@@ -245,99 +386,175 @@ func (r *Runner) work() {
 	defer runnerStats.Add("Workers", -1)
 
 	for check := range r.pending {
-		// see if the check is already running
-		r.m.Lock()
-		if _, isRunning := r.runningChecks[check.ID()]; isRunning {
-			log.Debugf("Check %s is already running, skip execution...", check)
-			r.m.Unlock()
+		if r.isolate(check) {
 			continue
-		} else {
-			r.runningChecks[check.ID()] = check
-			runnerStats.Add("RunningChecks", 1)
 		}
-		r.m.Unlock()
 
-		doLog, lastLog := shouldLog(check.ID())
+		r.runCheck(check)
 
-		if doLog {
-			log.Infof("Running check %s", check)
-		} else {
-			log.Debugf("Running check %s", check)
+		if check.Interval() == 0 {
+			log.Infof("Check %v one-time's execution has finished", check)
+			return
 		}
+		if r.cancelWorkerStop() {
+			log.Debug("Stopping a worker to reduce the number of workers")
+			return
+		}
+	}
 
-		// run the check
-		var err error
-		t0 := time.Now()
-
-		err = check.Run()
-		longRunning := check.Interval() == 0
+	log.Debug("Finished processing checks.")
+}
 
-		warnings := check.GetWarnings()
+// workIsolated runs the isolated checks, apart from the other checks
+func (r *Runner) workIsolated() {
+	log.Debug("Ready to process isolated checks...")
+	defer TestWg.Done()
+	defer runnerStats.Add("IsolatedWorkers", -1)
 
-		// use the default sender for the service checks
-		sender, e := aggregator.GetDefaultSender()
-		if e != nil {
-			log.Errorf("Error getting default sender: %v. Not sending status check for %s", e, check)
-		}
-		serviceCheckTags := []string{fmt.Sprintf("check:%s", check.String())}
-		serviceCheckStatus := metrics.ServiceCheckOK
+	for check := range r.isolated {
+		r.m.Lock()
+		delete(r.isolatedQueued, check.ID())
+		r.m.Unlock()
 
-		hostname := getHostname()
+		r.runCheck(check)
+	}
 
-		if len(warnings) != 0 {
-			// len returns int, and this expect int64, so it has to be converted
-			runnerStats.Add("Warnings", int64(len(warnings)))
-			serviceCheckStatus = metrics.ServiceCheckWarning
-		}
+	log.Debug("Finished processing isolated checks.")
+}
 
-		if err != nil {
-			log.Errorf("Error running check %s: %s", check, err)
-			runnerStats.Add("Errors", 1)
-			serviceCheckStatus = metrics.ServiceCheckCritical
-		}
+// isolate hands an isolated check over to its workers and returns whether
+// it did. The run waits for a free isolated worker, and is skipped when the
+// check is still running or already waiting, so that a slow check doesn't
+// pile up its runs.
+func (r *Runner) isolate(check check.Check) bool {
+	if r.isolated == nil || check.Interval() == 0 || !r.isolatedChecks[check.String()] {
+		return false
+	}
 
-		if sender != nil && !longRunning {
-			sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, "")
-			sender.Commit()
-		}
+	r.m.Lock()
+	defer r.m.Unlock()
+	if atomic.LoadUint32(&r.running) == 0 {
+		return true
+	}
+	if _, isRunning := r.runningChecks[check.ID()]; isRunning || r.isolatedQueued[check.ID()] {
+		log.Debugf("Check %s is already running or waiting, skip execution...", check)
+		return true
+	}
+	select {
+	case r.isolated <- check:
+		r.isolatedQueued[check.ID()] = true
+	default:
+		log.Warnf("Too many isolated checks are waiting, skipping the execution of %s", check)
+	}
+	return true
+}
 
-		// remove the check from the running list
-		r.m.Lock()
-		delete(r.runningChecks, check.ID())
+// runCheck runs a check unless it's already running, and publishes the
+// statistics of the run
+func (r *Runner) runCheck(check check.Check) {
+	// see if the check is already running
+	r.m.Lock()
+	if _, isRunning := r.runningChecks[check.ID()]; isRunning {
+		log.Debugf("Check %s is already running, skip execution...", check)
 		r.m.Unlock()
+		return
+	}
+	r.runningChecks[check.ID()] = check
+	runnerStats.Add("RunningChecks", 1)
+	tlmRunning.Inc()
+	r.m.Unlock()
 
-		// publish statistics about this run
-		runnerStats.Add("RunningChecks", -1)
-		runnerStats.Add("Runs", 1)
+	doLog, lastLog := shouldLog(check.ID())
 
-		r.m.Lock()
-		if !longRunning || len(warnings) != 0 || err != nil {
-			// If the scheduler isn't assigned (it should), just add stats
-			// otherwise only do so if the check is in the scheduler
-			if r.scheduler == nil || r.scheduler.IsCheckScheduled(check.ID()) {
-				mStats, _ := check.GetMetricStats()
-				addWorkStats(check, time.Since(t0), err, warnings, mStats)
-			}
-		}
-		r.m.Unlock()
+	if doLog {
+		log.Infof("Running check %s", check)
+	} else {
+		log.Debugf("Running check %s", check)
+	}
 
-		l := "Done running check %s"
-		if doLog {
-			if lastLog {
-				l = l + fmt.Sprintf(", next runs will be logged every %v runs", config.Datadog.GetInt64("logging_frequency"))
-			}
-			log.Infof(l, check)
+	// run the check
+	var err error
+	t0 := time.Now()
+
+	profiler := r.startProfiling(check)
+	err = check.Run()
+	if profiler != nil {
+		if paths, err := profiler.Stop(); err != nil {
+			log.Errorf("Unable to write the profiles of %s: %s", check, err)
 		} else {
-			log.Debugf(l, check)
+			log.Infof("Profiles of the run of %s written to %s", check.ID(), strings.Join(paths, ", "))
 		}
+	}
+	longRunning := check.Interval() == 0
 
-		if check.Interval() == 0 {
-			log.Infof("Check %v one-time's execution has finished", check)
-			return
+	warnings := check.GetWarnings()
+
+	// use the default sender for the service checks
+	sender, e := aggregator.GetDefaultSender()
+	if e != nil {
+		log.Errorf("Error getting default sender: %v. Not sending status check for %s", e, check)
+	}
+	serviceCheckTags := []string{fmt.Sprintf("check:%s", check.String())}
+	serviceCheckStatus := metrics.ServiceCheckOK
+
+	hostname := getHostname()
+
+	if len(warnings) != 0 {
+		// len returns int, and this expect int64, so it has to be converted
+		runnerStats.Add("Warnings", int64(len(warnings)))
+		serviceCheckStatus = metrics.ServiceCheckWarning
+	}
+
+	if err != nil {
+		log.Errorf("Error running check %s: %s", check, err)
+		runnerStats.Add("Errors", 1)
+		serviceCheckStatus = metrics.ServiceCheckCritical
+	}
+
+	if sender != nil && !longRunning {
+		sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, "")
+		sender.Commit()
+	}
+
+	// remove the check from the running list
+	r.m.Lock()
+	delete(r.runningChecks, check.ID())
+	r.m.Unlock()
+
+	// publish statistics about this run
+	runnerStats.Add("RunningChecks", -1)
+	runnerStats.Add("Runs", 1)
+	tlmRunning.Dec()
+	tlmExecutionTime.Observe(time.Since(t0).Seconds(), check.String())
+	switch {
+	case err != nil:
+		tlmRuns.Inc(check.String(), "error")
+	case len(warnings) != 0:
+		tlmRuns.Inc(check.String(), "warning")
+	default:
+		tlmRuns.Inc(check.String(), "ok")
+	}
+
+	r.m.Lock()
+	if !longRunning || len(warnings) != 0 || err != nil {
+		// If the scheduler isn't assigned (it should), just add stats
+		// otherwise only do so if the check is in the scheduler
+		if r.scheduler == nil || r.scheduler.IsCheckScheduled(check.ID()) {
+			mStats, _ := check.GetMetricStats()
+			addWorkStats(check, time.Since(t0), err, warnings, mStats)
 		}
 	}
+	r.m.Unlock()
 
-	log.Debug("Finished processing checks.")
+	l := "Done running check %s"
+	if doLog {
+		if lastLog {
+			l = l + fmt.Sprintf(", next runs will be logged every %v runs", config.Datadog.GetInt64("logging_frequency"))
+		}
+		log.Infof(l, check)
+	} else {
+		log.Debugf(l, check)
+	}
 }
 
 func shouldLog(id check.ID) (doLog bool, lastLog bool) {
@@ -404,6 +621,23 @@ func GetCheckStats() map[string]map[check.ID]*check.Stats {
 	return checkStats.Stats
 }
 
+// GetFirstRunErrors returns the errors of the first run of the scheduled
+// checks, indexed by check ID
+func GetFirstRunErrors() map[check.ID]string {
+	checkStats.M.RLock()
+	defer checkStats.M.RUnlock()
+
+	errors := make(map[check.ID]string)
+	for _, stats := range checkStats.Stats {
+		for id, s := range stats {
+			if err := s.GetFirstRunError(); err != "" {
+				errors[id] = err
+			}
+		}
+	}
+	return errors
+}
+
 // RemoveCheckStats removes a check from the check stats map
 func RemoveCheckStats(checkID check.ID) {
 	checkStats.M.Lock()
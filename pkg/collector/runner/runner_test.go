@@ -8,8 +8,13 @@ package runner
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,6 +23,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 // FIXTURE
@@ -147,6 +153,44 @@ func TestLogging(t *testing.T) {
 	r.Stop()
 }
 
+func TestFirstRunErrors(t *testing.T) {
+	failing := newTestCheck(true, "failing")
+	recovered := newTestCheck(false, "recovered")
+	defer RemoveCheckStats(failing.ID())
+	defer RemoveCheckStats(recovered.ID())
+
+	// the errors are read while the checks run
+	stop := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				GetFirstRunErrors()
+			}
+		}
+	}()
+
+	// only the error of the first run is kept
+	addWorkStats(failing, time.Millisecond, errors.New("wrong password"), nil, nil)
+	addWorkStats(failing, time.Millisecond, nil, nil, nil)
+	addWorkStats(recovered, time.Millisecond, nil, nil, nil)
+	addWorkStats(recovered, time.Millisecond, errors.New("timeout"), nil, nil)
+	close(stop)
+	<-readerDone
+
+	errs := GetFirstRunErrors()
+	assert.Equal(t, "wrong password", errs[failing.ID()])
+	assert.NotContains(t, errs, recovered.ID())
+
+	// unscheduling the check forgets about it
+	RemoveCheckStats(failing.ID())
+	assert.NotContains(t, GetFirstRunErrors(), failing.ID())
+}
+
 type TimingoutCheck struct {
 	TestCheck
 }
@@ -173,3 +217,133 @@ func TestStopCheck(t *testing.T) {
 	err = r.StopCheck(c2.ID())
 	assert.Equal(t, "timeout during stop operation on check id TestCheck:2", err.Error())
 }
+
+func numWorkers() int {
+	n, _ := strconv.Atoi(runnerStats.Get("Workers").String())
+	return n
+}
+
+func TestSetNumWorkers(t *testing.T) {
+	// other tests leave workers behind
+	maxNumWorkers = 1000
+	defer func() { maxNumWorkers = 25 }()
+
+	r := NewRunner()
+	defer r.Stop()
+	before := numWorkers()
+
+	r.SetNumWorkers(before + 2)
+	assert.True(t, r.staticNumWorkers)
+	assert.Equal(t, before+2, numWorkers())
+
+	// the workers stop once they're done with their check
+	r.SetNumWorkers(before)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&r.workersToStop))
+	for i := 0; i < 2; i++ {
+		c := newTestCheck(false, strconv.Itoa(i))
+		r.pending <- c
+		<-c.done
+	}
+	for i := 0; i < 100 && numWorkers() != before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, before, numWorkers())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&r.workersToStop))
+
+	// pending stops are cancelled rather than adding workers
+	r.SetNumWorkers(before - 1)
+	r.SetNumWorkers(before)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&r.workersToStop))
+	assert.Equal(t, before, numWorkers())
+
+	r.SetNumWorkers(0)
+	assert.False(t, r.staticNumWorkers)
+}
+
+type slowCheck struct {
+	TestCheck
+	release chan struct{}
+}
+
+func (c *slowCheck) String() string { return "slow" }
+func (c *slowCheck) Run() error {
+	<-c.release
+	return nil
+}
+
+func TestIsolatedChecks(t *testing.T) {
+	config.Datadog.Set("isolated_checks", []string{"slow"})
+	defer config.Datadog.Set("isolated_checks", []string{})
+
+	r := NewRunner()
+	defer r.Stop()
+	require.NotNil(t, r.isolated)
+
+	release := make(chan struct{})
+	defer close(release)
+	slow1 := &slowCheck{TestCheck: *newTestCheck(false, "slow1"), release: release}
+	slow2 := &slowCheck{TestCheck: *newTestCheck(false, "slow2"), release: release}
+	isRunning := func(c check.Check) bool {
+		r.m.Lock()
+		defer r.m.Unlock()
+		_, running := r.runningChecks[c.ID()]
+		return running
+	}
+
+	r.pending <- slow1
+	for i := 0; i < 100 && !isRunning(slow1); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, isRunning(slow1))
+
+	// the other slow check waits for the isolated worker, the new runs of
+	// the running and waiting checks are skipped
+	r.pending <- slow2
+	r.pending <- slow1
+	r.pending <- slow2
+
+	// the slow checks hold the isolated worker only
+	for i := 0; i < 2*defaultNumWorkers; i++ {
+		c := newTestCheck(false, strconv.Itoa(i))
+		r.pending <- c
+		select {
+		case <-c.done:
+		case <-time.After(1 * time.Second):
+			require.Fail(t, "Check hasn't run 1 second after being scheduled")
+		}
+	}
+
+	assert.False(t, isRunning(slow2))
+	assert.Len(t, r.isolated, 1)
+	r.m.Lock()
+	assert.Equal(t, map[check.ID]bool{slow2.ID(): true}, r.isolatedQueued)
+	r.m.Unlock()
+}
+
+func TestProfileChecks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check_profiles")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer config.Datadog.Set("check_profiles_dir", config.Datadog.GetString("check_profiles_dir"))
+	config.Datadog.Set("check_profiles_dir", dir)
+
+	r := NewRunner()
+	defer r.Stop()
+	r.ProfileChecks([]string{"TestCheck:2", "TestCheck", "other"})
+	assert.Equal(t, []string{"TestCheck", "TestCheck:2", "other"}, r.ProfiledChecks())
+
+	// the name profiles the first instance, the ID its instance
+	r.runCheck(newTestCheck(false, "1"))
+	r.runCheck(newTestCheck(false, "2"))
+	r.runCheck(newTestCheck(false, "3"))
+	assert.Equal(t, []string{"other"}, r.ProfiledChecks())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	assert.True(t, strings.HasPrefix(files[0].Name(), "TestCheck_1-"))
+	assert.True(t, strings.HasPrefix(files[2].Name(), "TestCheck_2-"))
+
+	r.ProfileChecks(nil)
+	assert.Empty(t, r.ProfiledChecks())
+}
@@ -7,6 +7,8 @@ package scheduler
 
 import (
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,6 +17,19 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// maxJitter is the maximum delay of a check within its bucket, below the
+// one second between two buckets
+const maxJitter = 900 * time.Millisecond
+
+// jitter returns the delay of a check within its bucket. It only depends on
+// the check ID, so that a check keeps its place in the schedule across
+// restarts.
+func jitter(id check.ID) time.Duration {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return time.Duration(h.Sum32()%uint32(maxJitter/time.Millisecond)) * time.Millisecond
+}
+
 type jobBucket struct {
 	jobs []check.Check
 	mu   sync.RWMutex // to protect critical sections in struct's fields
@@ -47,14 +62,17 @@ type jobQueue struct {
 	currentBucketIdx    uint
 	schedulingBucketIdx uint
 	running             bool
+	jitter              bool
 	health              *health.Handle
 	mu                  sync.RWMutex // to protect critical sections in struct's fields
 }
 
-// newJobQueue creates a new jobQueue instance
-func newJobQueue(interval time.Duration) *jobQueue {
+// newJobQueue creates a new jobQueue instance, the checks of a bucket are
+// spread over its second with jitter
+func newJobQueue(interval time.Duration, jitter bool) *jobQueue {
 	jq := &jobQueue{
 		interval:     interval,
+		jitter:       jitter,
 		stop:         make(chan bool),
 		stopped:      make(chan bool),
 		health:       health.Register("collector-queue"),
@@ -181,11 +199,28 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 		log.Tracef("Jobs in bucket: %v", jobs)
 
+		if jq.jitter {
+			sort.SliceStable(jobs, func(i, j int) bool {
+				return jitter(jobs[i].ID()) < jitter(jobs[j].ID())
+			})
+		}
+
 		for _, check := range jobs {
 			if !s.IsCheckScheduled(check.ID()) {
 				continue
 			}
 
+			if jq.jitter {
+				if delay := time.Until(t.Add(jitter(check.ID()))); delay > 0 {
+					select {
+					case <-time.After(delay):
+					case <-jq.stop:
+						jq.health.Deregister()
+						return false
+					}
+				}
+			}
+
 			select {
 			// blocking, we'll be here as long as it takes
 			case s.checksPipe <- check:
@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
@@ -43,6 +44,7 @@ type Scheduler struct {
 	running       uint32                      // Flag to see if the scheduler is running
 	cancelOneTime chan bool                   // Used to internally communicate a cancel signal to one-time schedule goroutines
 	wgOneTime     sync.WaitGroup              // WaitGroup to track the exit of one-time schedule goroutines
+	jitter        bool                        // Whether to spread the checks of a bucket over its second
 }
 
 // NewScheduler create a Scheduler and returns a pointer to it.
@@ -57,6 +59,7 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 		running:       0,
 		cancelOneTime: make(chan bool),
 		wgOneTime:     sync.WaitGroup{},
+		jitter:        config.Datadog.GetBool("check_scheduler_jitter"),
 	}
 }
 
@@ -80,7 +83,7 @@ func (s *Scheduler) Enter(check check.Check) error {
 	defer s.mu.Unlock()
 
 	if _, ok := s.jobQueues[check.Interval()]; !ok {
-		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
+		s.jobQueues[check.Interval()] = newJobQueue(check.Interval(), s.jitter)
 		s.startQueue(s.jobQueues[check.Interval()])
 		schedulerQueuesCount.Add(1)
 	}
@@ -216,3 +216,45 @@ func TestStopOneTimeSchedule(t *testing.T) {
 	// sleep to make the runtime schedule the hanging goroutines, if there are any
 	time.Sleep(time.Millisecond)
 }
+
+func TestJitter(t *testing.T) {
+	for _, id := range []check.ID{"cpu", "disk:e5dffb8bef24336f", "http_check:4b2dc3c9a5b5a0b3"} {
+		j := jitter(id)
+		assert.Equal(t, j, jitter(id))
+		assert.True(t, j >= 0 && j < maxJitter)
+	}
+	assert.NotEqual(t, jitter("cpu"), jitter("disk:e5dffb8bef24336f"))
+}
+
+type idCheck struct {
+	TestCheck
+	id check.ID
+}
+
+func (c *idCheck) ID() check.ID { return c.id }
+
+func TestProcessJitter(t *testing.T) {
+	ch := make(chan check.Check, 2)
+	s := NewScheduler(ch)
+
+	q := newJobQueue(time.Second, true)
+	q.bucketTicker.Stop()
+	tick := make(chan time.Time, 1)
+	q.bucketTicker = &time.Ticker{C: tick}
+	for _, c := range []*idCheck{{id: "cpu"}, {id: "disk:e5dffb8bef24336f"}} {
+		q.addJob(c)
+		s.checkToQueue[c.ID()] = q
+	}
+
+	start := time.Now()
+	tick <- start
+	// the queue may handle a health check before the tick
+	for len(ch) < 2 {
+		assert.True(t, q.process(s))
+	}
+
+	// the checks are enqueued in the order of their jitter, once it elapsed
+	first, second := <-ch, <-ch
+	assert.True(t, jitter(first.ID()) <= jitter(second.ID()))
+	assert.True(t, time.Since(start) >= jitter(second.ID()))
+}
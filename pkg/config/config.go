@@ -8,10 +8,12 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	yaml "gopkg.in/yaml.v2"
@@ -32,12 +34,24 @@ const DefaultSite = "datadoghq.com"
 
 const infraURLPrefix = "https://app."
 
+// Data types that can be sent to additional endpoints of their own with
+// `additional_endpoints_per_type`
+const (
+	MetricsEndpointDataType       = "metrics"
+	EventsEndpointDataType        = "events"
+	ServiceChecksEndpointDataType = "service_checks"
+)
+
+// EndpointDataTypes lists the valid keys of `additional_endpoints_per_type`
+var EndpointDataTypes = []string{MetricsEndpointDataType, EventsEndpointDataType, ServiceChecksEndpointDataType}
+
 var overrideVars = map[string]interface{}{}
 
 // Datadog is the global configuration object
 var (
-	Datadog Config
-	proxies *Proxy
+	Datadog      Config
+	proxies      *Proxy
+	proxiesMutex sync.RWMutex
 )
 
 // MetadataProviders helps unmarshalling `metadata_providers` config param
@@ -73,6 +87,11 @@ type Proxy struct {
 	HTTP    string   `mapstructure:"http"`
 	HTTPS   string   `mapstructure:"https"`
 	NoProxy []string `mapstructure:"no_proxy"`
+	// Auth is the authentication scheme used with the proxies: "basic"
+	// (default), "ntlm" or "negotiate"
+	Auth     string `mapstructure:"auth"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
 }
 
 func init() {
@@ -114,21 +133,32 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("syslog_tls_verify", true)
 	config.BindEnvAndSetDefault("cmd_host", "localhost")
 	config.BindEnvAndSetDefault("cmd_port", 5001)
+	config.BindEnvAndSetDefault("cmd_grpc_port", 0)
+	config.BindEnvAndSetDefault("cmd_grpc_socket", "")
+	config.BindEnvAndSetDefault("ipc_cert_file_path", "")
 	config.BindEnvAndSetDefault("cluster_agent.cmd_port", 5005)
 	config.BindEnvAndSetDefault("default_integration_http_timeout", 9)
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
+	config.BindEnvAndSetDefault("inventories_enabled", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_scheduler_jitter", true)
+	config.BindEnvAndSetDefault("isolated_checks", []string{})
+	config.BindEnvAndSetDefault("isolated_check_runners", 1)
+	config.BindEnvAndSetDefault("check_profiles_dir", filepath.Join(defaultRunPath, "check_profiles"))
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnvAndSetDefault("bind_host", "localhost")
 	config.BindEnvAndSetDefault("health_port", int64(0))
 	config.BindEnvAndSetDefault("disable_py3_validation", false)
+	config.BindEnvAndSetDefault("disk_check.use_core_loader", false)
+	config.BindEnvAndSetDefault("go_expvar_check.use_core_loader", false)
 	config.BindEnvAndSetDefault("python_version", "2")
 
 	// if/when the default is changed to true, make the default platform
 	// dependent; default should remain false on Windows to maintain backward
 	// compatibility with Agent5 behavior/win
 	config.BindEnvAndSetDefault("hostname_fqdn", false)
+	config.BindEnvAndSetDefault("hostname_provider", "")
 	config.BindEnvAndSetDefault("cluster_name", "")
 
 	// secrets backend
@@ -136,6 +166,17 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("secret_backend_arguments", []string{})
 	config.BindEnvAndSetDefault("secret_backend_output_max_size", 1024)
 	config.BindEnvAndSetDefault("secret_backend_timeout", 5)
+	config.BindEnvAndSetDefault("secret_refresh_interval", 0)
+
+	// Remote configuration
+	config.BindEnvAndSetDefault("remote_configuration.enabled", false)
+	config.BindEnvAndSetDefault("remote_configuration.url", "")
+	config.BindEnvAndSetDefault("remote_configuration.refresh_interval", 60) // in seconds
+	config.BindEnvAndSetDefault("remote_configuration.public_key", "")
+	config.BindEnvAndSetDefault("remote_configuration.state_file", filepath.Join(defaultRunPath, "remote_configuration.json"))
+
+	// Reload of the configuration file
+	config.BindEnvAndSetDefault("config_watch_interval", 0) // in seconds
 
 	// Retry settings
 	config.BindEnvAndSetDefault("forwarder_backoff_factor", 2)
@@ -143,6 +184,8 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_backoff_max", 64)
 	config.BindEnvAndSetDefault("forwarder_recovery_interval", DefaultForwarderRecoveryInterval)
 	config.BindEnvAndSetDefault("forwarder_recovery_reset", false)
+	config.BindEnvAndSetDefault("forwarder_4xx_circuit_breaker_threshold", 0) // 0 disables the circuit breaker
+	config.BindEnvAndSetDefault("forwarder_4xx_circuit_breaker_pause", 300)   // in seconds
 
 	// Use to output logs in JSON format
 	config.BindEnvAndSetDefault("log_format_json", false)
@@ -152,6 +195,13 @@ func initConfig(config Config) {
 
 	// Use to force client side TLS version to 1.2
 	config.BindEnvAndSetDefault("force_tls_12", false)
+	// TLS settings of the connections to the intake and the proxies
+	config.BindEnvAndSetDefault("min_tls_version", "")
+	config.BindEnvAndSetDefault("tls_ca_bundle", "")
+	config.BindEnvAndSetDefault("tls_client_cert", "")
+	config.BindEnvAndSetDefault("tls_client_key", "")
+	// Refuse to start unless the agent is built with the FIPS validated crypto
+	config.BindEnvAndSetDefault("fips_mode", false)
 
 	// Defaults to safe YAML methods in base and custom checks.
 	config.BindEnvAndSetDefault("disable_unsafe_yaml", true)
@@ -182,9 +232,12 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("histogram_percentiles", []string{"0.95"})
 	// Serializer
 	config.BindEnvAndSetDefault("enable_stream_payload_serialization", true)
+	config.BindEnvAndSetDefault("compression_kind", "")
+	config.BindEnvAndSetDefault("compression_level", 0)
 	config.BindEnvAndSetDefault("use_v2_api.series", false)
 	config.BindEnvAndSetDefault("use_v2_api.events", false)
 	config.BindEnvAndSetDefault("use_v2_api.service_checks", false)
+	config.BindEnvAndSetDefault("v1_only_endpoints", []string{})
 	// Serializer: allow user to blacklist any kind of payload to be sent
 	config.BindEnvAndSetDefault("enable_payloads.events", true)
 	config.BindEnvAndSetDefault("enable_payloads.series", true)
@@ -196,6 +249,11 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_timeout", 20)
 	config.BindEnvAndSetDefault("forwarder_retry_queue_max_size", 30)
 	config.BindEnvAndSetDefault("forwarder_num_workers", 1)
+	config.BindEnvAndSetDefault("forwarder_failover_domain", "")
+	config.BindEnvAndSetDefault("forwarder_failover_threshold", 5)
+	config.BindEnvAndSetDefault("forwarder_failover_probe_interval", 30) // in seconds
+	config.BindEnvAndSetDefault("forwarder_max_bytes_per_sec", 0)        // 0 means unlimited
+	config.BindEnvAndSetDefault("forwarder_max_bytes_burst", 0)          // 0 means one second of traffic
 	// Dogstatsd
 	config.BindEnvAndSetDefault("use_dogstatsd", true)
 	config.BindEnvAndSetDefault("dogstatsd_port", 8125) // Notice: 0 means UDP port closed
@@ -224,12 +282,17 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
 	config.BindEnvAndSetDefault("statsd_metric_namespace_blacklist", StandardStatsdPrefixes)
+	config.BindEnvAndSetDefault("statsd_metric_blocklist", []string{})
 	// Autoconfig
 	config.BindEnvAndSetDefault("autoconf_template_dir", "/datadog/check_configs")
 	config.BindEnvAndSetDefault("exclude_pause_container", true)
 	config.BindEnvAndSetDefault("ac_include", []string{})
 	config.BindEnvAndSetDefault("ac_exclude", []string{})
-	config.BindEnvAndSetDefault("ad_config_poll_interval", int64(10)) // in seconds
+	config.BindEnvAndSetDefault("container_include", []string{})
+	config.BindEnvAndSetDefault("container_exclude", []string{})
+	config.BindEnvAndSetDefault("ad_identifier_aliases", map[string][]string{})
+	config.BindEnvAndSetDefault("ad_config_poll_interval", int64(10))    // in seconds
+	config.BindEnvAndSetDefault("ad_config_resync_interval", int64(300)) // in seconds
 	config.BindEnvAndSetDefault("extra_listeners", []string{})
 	config.BindEnvAndSetDefault("extra_config_providers", []string{})
 
@@ -239,6 +302,7 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("docker_env_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_pod_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_pod_annotations_as_tags", map[string]string{})
+	config.BindEnvAndSetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_node_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("container_cgroup_prefix", "")
 
@@ -277,13 +341,19 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
 	config.BindEnvAndSetDefault("leader_lease_duration", "60")
 	config.BindEnvAndSetDefault("leader_election", false)
+	config.BindEnvAndSetDefault("leader_election_resource", "auto") // auto, configmaps, leases or configmapsleases
 	config.BindEnvAndSetDefault("kube_resources_namespace", "")
 
 	// Datadog cluster agent
 	config.BindEnvAndSetDefault("cluster_agent.enabled", false)
 	config.BindEnvAndSetDefault("cluster_agent.auth_token", "")
+	config.BindEnvAndSetDefault("cluster_agent.auth_token_file_path", "")
+	config.BindEnvAndSetDefault("cluster_agent.auth_token_refresh_interval", 60) // value in seconds, 0 to disable the reloads
+	config.BindEnvAndSetDefault("cluster_agent.auth_token_grace_period", 600)    // value in seconds
 	config.BindEnvAndSetDefault("cluster_agent.url", "")
 	config.BindEnvAndSetDefault("cluster_agent.kubernetes_service_name", "datadog-cluster-agent")
+	config.BindEnvAndSetDefault("cluster_agent.collect_cluster_tags", false)
+	config.BindEnvAndSetDefault("cluster_tags.refresh_interval", 300) // in seconds
 	config.BindEnvAndSetDefault("metrics_port", "5000")
 
 	// Metadata endpoints
@@ -296,9 +366,25 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("ecs_agent_url", "") // Will be autodetected
 	config.BindEnvAndSetDefault("ecs_agent_container_name", "ecs-agent")
 	config.BindEnvAndSetDefault("collect_ec2_tags", false)
+	config.BindEnvAndSetDefault("ec2_prefer_imdsv2", true)
 
 	// GCE
 	config.BindEnvAndSetDefault("collect_gce_tags", true)
+	config.BindEnvAndSetDefault("collect_gce_labels", false)
+
+	// Azure
+	config.BindEnvAndSetDefault("collect_azure_tags", false)
+
+	// Alibaba, Oracle and IBM Cloud
+	config.BindEnvAndSetDefault("collect_alibaba_tags", false)
+	config.BindEnvAndSetDefault("collect_oracle_tags", false)
+	config.BindEnvAndSetDefault("collect_ibm_tags", false)
+
+	// Cloud providers whose metadata (host aliases and tags) is collected, in order of priority
+	config.BindEnvAndSetDefault("cloud_provider_metadata", []string{"aws", "gcp", "azure", "alibaba", "oracle", "ibm"})
+
+	// Cloud providers host tags are queried at most once per interval
+	config.BindEnvAndSetDefault("cloud_provider_tags_refresh_interval", 300) // in seconds
 
 	// Cloud Foundry
 	config.BindEnvAndSetDefault("cloud_foundry", false)
@@ -310,10 +396,11 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("jmx_use_cgroup_memory_limit", false)
 	config.BindEnvAndSetDefault("jmx_max_restarts", int64(3))
 	config.BindEnvAndSetDefault("jmx_restart_interval", int64(5))
+	config.BindEnvAndSetDefault("jmx_restart_max_backoff", 60)
 	config.BindEnvAndSetDefault("jmx_thread_pool_size", 3)
 	config.BindEnvAndSetDefault("jmx_reconnection_thread_pool_size", 3)
 	config.BindEnvAndSetDefault("jmx_collection_timeout", 60)
-	config.BindEnvAndSetDefault("jmx_check_period", defaults.DefaultCheckInterval/time.Millisecond)
+	config.BindEnvAndSetDefault("jmx_check_period", int(defaults.DefaultCheckInterval/time.Millisecond))
 	config.BindEnvAndSetDefault("jmx_reconnection_timeout", 10)
 
 	// Go_expvar server port
@@ -333,6 +420,8 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("log_enabled", false) // deprecated, use logs_enabled instead
 	// collect all logs from all containers:
 	config.BindEnvAndSetDefault("logs_config.container_collect_all", false)
+	// tail the json log files of the docker containers while their logs can't be streamed from the docker API:
+	config.BindEnvAndSetDefault("logs_config.docker_file_fallback", true)
 	// add a socks5 proxy:
 	config.BindEnvAndSetDefault("logs_config.socks5_proxy_address", "")
 	// send the logs to a proxy:
@@ -342,12 +431,35 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.logs_no_ssl", false)
 	// send the logs to the port 443 of the logs-backend via TCP:
 	config.BindEnvAndSetDefault("logs_config.use_port_443", false)
+	// attach the tags of the containers and the pods to their logs, at the logs_tag_cardinality:
+	config.BindEnvAndSetDefault("logs_config.tagger_enrichment", true)
+	// send batches of logs to the HTTP intake, with the proxy settings of the forwarder:
+	config.BindEnvAndSetDefault("logs_config.use_http", false)
+	config.BindEnvAndSetDefault("logs_config.use_compression", true)
+	config.BindEnvAndSetDefault("logs_config.compression_level", 6)
+	config.BindEnvAndSetDefault("logs_config.batch_wait", 5)
 	// increase the read buffer size of the UDP sockets:
 	config.BindEnvAndSetDefault("logs_config.frame_size", 9000)
 	// increase the number of files that can be tailed in parallel:
 	config.BindEnvAndSetDefault("logs_config.open_files_limit", 100)
 	// add global processing rules that are applied on all logs
 	config.BindEnv("logs_config.processing_rules")
+	// detect the multi-line pattern of the logs of the sources without multi_line rule
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_sample_size", 500)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_match_threshold", 0.48)
+	// split or truncate the lines longer than max_line_size bytes
+	config.BindEnvAndSetDefault("logs_config.max_line_size", 256*1000)
+	config.BindEnvAndSetDefault("logs_config.truncation_mode", "split")
+	// sync the registry to disk at most every registry_fsync_period seconds
+	config.BindEnvAndSetDefault("logs_config.registry_fsync_period", 60)
+	// spill the logs to disk while the intake is slow instead of blocking their collection
+	config.BindEnvAndSetDefault("logs_config.spill_to_disk", false)
+	config.BindEnvAndSetDefault("logs_config.spill_max_size", 100*1000*1000)
+	// limit the logs sent by all the sources, 0 means no limit
+	config.BindEnvAndSetDefault("logs_config.rate_limit_lines_per_second", 0)
+	config.BindEnvAndSetDefault("logs_config.rate_limit_bytes_per_second", 0)
+	config.BindEnvAndSetDefault("logs_config.rate_limit_burst_seconds", 1)
 
 	// Internal Use Only: avoid modifying those configuration parameters, this could lead to unexpected results.
 	config.BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
@@ -357,6 +469,12 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.dd_url_443", "agent-443-intake.logs.datadoghq.com")
 	config.BindEnvAndSetDefault("logs_config.stop_grace_period", 30)
 
+	// Serverless mode: the agent runs as a sidecar or an extension of a serverless runtime,
+	// it keeps no registry on disk, bounds the memory used by the logs and is flushed on demand
+	config.BindEnvAndSetDefault("serverless.enabled", false)
+	config.BindEnvAndSetDefault("serverless.logs_memory_limit", 16*1000*1000)
+	config.BindEnvAndSetDefault("serverless.flush_timeout", 5)
+
 	// The cardinality of tags to send for checks and dogstatsd respectively.
 	// Choices are: low, orchestrator, high.
 	// WARNING: sending orchestrator, or high tags for dogstatsd metrics may create more metrics
@@ -364,6 +482,13 @@ func initConfig(config Config) {
 	// Changing this setting may impact your custom metrics billing.
 	config.BindEnvAndSetDefault("checks_tag_cardinality", "low")
 	config.BindEnvAndSetDefault("dogstatsd_tag_cardinality", "low")
+	config.BindEnvAndSetDefault("logs_tag_cardinality", "high")
+	config.BindEnvAndSetDefault("process_tag_cardinality", "high")
+	// Tagger sources whose tags win, in this order, when several sources
+	// send different values for the same tag key.
+	config.BindEnvAndSetDefault("tag_source_precedence", []string{})
+	// Seconds the tags of a deleted container or pod are kept, to tag its last metrics.
+	config.BindEnvAndSetDefault("tagger_deletion_grace_period", 60)
 
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution", false)
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution_prefix", "")
@@ -381,7 +506,9 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("external_metrics.aggregator", "avg")                    // aggregator used for the external metrics. Choose from [avg,sum,max,min]
 	config.BindEnvAndSetDefault("external_metrics_provider.bucket_size", 60*5)           // Window to query to get the metric from Datadog.
 	config.BindEnvAndSetDefault("external_metrics_provider.rollup", 30)                  // Bucket size to circumvent time aggregation side effects.
+	config.BindEnvAndSetDefault("external_metrics_provider.max_metrics_per_query", 35)   // Maximum number of metrics queried to Datadog in a single request.
 	config.BindEnvAndSetDefault("kubernetes_event_collection_timeout", 100)              // timeout between two successful event collections in milliseconds.
+	config.BindEnvAndSetDefault("kubernetes_events_rollup_window", 300)                  // window in seconds of the rollups of the events, 0 to disable them.
 	config.BindEnvAndSetDefault("kubernetes_informers_resync_period", 60*5)              // value in seconds. Default to 5 minutes
 	config.BindEnvAndSetDefault("external_metrics_provider.local_copy_refresh_rate", 30) // value in seconds
 	// Cluster check Autodiscovery
@@ -390,6 +517,33 @@ func initConfig(config Config) {
 	config.BindEnvAndSetDefault("cluster_checks.warmup_duration", 30)         // value in seconds
 	config.BindEnvAndSetDefault("cluster_checks.cluster_tag_name", "cluster_name")
 	config.BindEnvAndSetDefault("cluster_checks.extra_tags", []string{})
+	config.BindEnvAndSetDefault("cluster_checks.shared_state_enabled", false)
+	config.BindEnvAndSetDefault("cluster_checks.shared_state_sync_interval", 10) // value in seconds
+	// Cluster check runner
+	config.BindEnvAndSetDefault("clc_runner_enabled", false)
+	config.BindEnvAndSetDefault("clc_runner_capacity", 0) // maximum number of cluster checks, 0 for no limit
+	// Orchestrator explorer
+	config.BindEnvAndSetDefault("orchestrator_explorer.enabled", false)
+	config.BindEnvAndSetDefault("orchestrator_explorer.orchestrator_dd_url", "")
+	config.BindEnvAndSetDefault("orchestrator_explorer.flush_interval", 10)  // value in seconds
+	config.BindEnvAndSetDefault("orchestrator_explorer.resync_period", 60*5) // value in seconds
+	config.BindEnvAndSetDefault("orchestrator_explorer.max_per_message", 100)
+	config.BindEnvAndSetDefault("orchestrator_explorer.custom_sensitive_words", []string{})
+	// Admission controller
+	config.BindEnvAndSetDefault("admission_controller.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.port", 8000)
+	config.BindEnvAndSetDefault("admission_controller.service_name", "datadog-admission-controller")
+	config.BindEnvAndSetDefault("admission_controller.webhook_name", "datadog-webhook")
+	config.BindEnvAndSetDefault("admission_controller.pod_selector", "admission.datadoghq.com/enabled=true")
+	config.BindEnvAndSetDefault("admission_controller.failure_policy", "Ignore")
+	config.BindEnvAndSetDefault("admission_controller.timeout_seconds", 5)
+	config.BindEnvAndSetDefault("admission_controller.certificate.secret_name", "webhook-certificate")
+	config.BindEnvAndSetDefault("admission_controller.certificate.validity_bound", 365*24)      // value in hours
+	config.BindEnvAndSetDefault("admission_controller.certificate.expiration_threshold", 30*24) // value in hours
+	config.BindEnvAndSetDefault("admission_controller.inject_config.enabled", true)
+	config.BindEnvAndSetDefault("admission_controller.inject_config.trace_agent_port", 8126)
+	config.BindEnvAndSetDefault("admission_controller.inject_tracer.enabled", true)
+	config.BindEnvAndSetDefault("admission_controller.inject_tracer.container_registry", "gcr.io/datadoghq")
 
 	// Declare other keys that don't have a default/env var.
 	// Mostly, keys we use IsSet() on, because IsSet always returns true if a key has a default.
@@ -398,11 +552,23 @@ func initConfig(config Config) {
 	config.SetKnown("clustername")
 	config.SetKnown("listeners")
 	config.SetKnown("additional_endpoints")
+	config.SetKnown("additional_endpoints_per_type")
 	config.SetKnown("proxy.http")
 	config.SetKnown("proxy.https")
 	config.SetKnown("proxy.no_proxy")
+	config.SetKnown("proxy.auth")
+	config.SetKnown("proxy.username")
+	config.SetKnown("proxy.password")
+	// Written by the import of the agent 5 kubernetes settings
+	config.SetKnown("kubernetes_collect_service_tags")
+	config.SetKnown("kubernetes_service_tag_update_freq")
+
+	// Logs
+	config.SetKnown("logs_config.additional_endpoints")
+	config.SetKnown("logs_config.dev_mode_no_ssl")
 
 	// Process
+	config.SetKnown("process_agent_enabled")
 	config.SetKnown("process_config.dd_agent_env")
 	config.SetKnown("process_config.enabled")
 	config.SetKnown("process_config.intervals.process_realtime")
@@ -422,10 +588,20 @@ func initConfig(config Config) {
 	config.SetKnown("network_tracer_config.enabled")
 	config.SetKnown("network_tracer_config.log_file")
 
+	// System probe, its settings are read by the system-probe process
+	config.SetKnown("system_probe_config.*")
+
 	// APM
 	config.SetKnown("apm_config.enabled")
 	config.SetKnown("apm_config.env")
 	config.SetKnown("apm_config.additional_endpoints")
+	config.SetKnown("apm_config.api_key")
+	config.SetKnown("apm_config.log_level")
+	config.SetKnown("apm_config.log_throttling")
+	config.SetKnown("apm_config.bucket_size_seconds")
+	config.SetKnown("apm_config.extra_aggregators")
+	config.SetKnown("apm_config.receiver_timeout")
+	config.SetKnown("apm_config.watchdog_check_delay")
 	config.SetKnown("apm_config.apm_non_local_traffic")
 	config.SetKnown("apm_config.max_traces_per_second")
 	config.SetKnown("apm_config.max_memory")
@@ -489,9 +665,27 @@ var (
 
 // GetProxies returns the proxy settings from the configuration
 func GetProxies() *Proxy {
+	proxiesMutex.RLock()
+	defer proxiesMutex.RUnlock()
 	return proxies
 }
 
+// ReloadProxies replaces the proxy settings of the configuration with p,
+// the ones of a configuration file that changed. The environment variables
+// still take precedence over them.
+func ReloadProxies(p *Proxy) {
+	if p == nil {
+		p = &Proxy{}
+	}
+	Datadog.Set("proxy.http", p.HTTP)
+	Datadog.Set("proxy.https", p.HTTPS)
+	Datadog.Set("proxy.no_proxy", p.NoProxy)
+	Datadog.Set("proxy.auth", p.Auth)
+	Datadog.Set("proxy.username", p.Username)
+	Datadog.Set("proxy.password", p.Password)
+	loadProxyFromEnv(Datadog)
+}
+
 // loadProxyFromEnv overrides the proxy settings with environment variables
 func loadProxyFromEnv(config Config) {
 	// Viper doesn't handle mixing nested variables from files and set
@@ -553,13 +747,33 @@ func loadProxyFromEnv(config Config) {
 		p.NoProxy = strings.Split(noProxy, ",") // comma-separated list, consistent with other tools that use the NO_PROXY env var
 	}
 
+	if auth, found := lookupEnv("DD_PROXY_AUTH"); found {
+		isSet = true
+		p.Auth = auth
+	}
+
+	if username, found := lookupEnv("DD_PROXY_USERNAME"); found {
+		isSet = true
+		p.Username = username
+	}
+
+	if password, found := lookupEnv("DD_PROXY_PASSWORD"); found {
+		isSet = true
+		p.Password = password
+	}
+
 	// We have to set each value individually so both config.Get("proxy")
 	// and config.Get("proxy.http") work
 	if isSet {
 		config.Set("proxy.http", p.HTTP)
 		config.Set("proxy.https", p.HTTPS)
 		config.Set("proxy.no_proxy", p.NoProxy)
+		config.Set("proxy.auth", p.Auth)
+		config.Set("proxy.username", p.Username)
+		config.Set("proxy.password", p.Password)
+		proxiesMutex.Lock()
 		proxies = p
+		proxiesMutex.Unlock()
 	}
 }
 
@@ -573,6 +787,20 @@ func LoadWithoutSecret() error {
 	return load(Datadog, "datadog.yaml", false)
 }
 
+// KnownKeysDefaults returns the known keys of the agent configuration with
+// their default value, nil for the keys without default
+func KnownKeysDefaults() map[string]interface{} {
+	defaults := NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+	initConfig(defaults)
+
+	knownKeys := defaults.GetKnownKeys()
+	keys := make(map[string]interface{}, len(knownKeys))
+	for key := range knownKeys {
+		keys[key] = defaults.Get(key)
+	}
+	return keys
+}
+
 func findUnknownKeys(config Config) []string {
 	var unknownKeys []string
 	knownKeys := config.GetKnownKeys()
@@ -658,6 +886,74 @@ func GetMainEndpoint(prefix string, ddURLKey string) string {
 	return GetMainEndpointWithConfig(Datadog, prefix, ddURLKey)
 }
 
+// apiKeysSettings are the settings holding api keys, refreshed by RefreshAPIKeys
+var apiKeysSettings = []string{"api_key", "additional_endpoints", "additional_endpoints_per_type"}
+
+// RefreshAPIKeys reads the api keys of the main and additional endpoints from
+// the configuration file again, fetching the secrets they reference from the
+// secrets backend again, so the keys rotated since the Agent started are used.
+func RefreshAPIKeys() error {
+	return refreshAPIKeysWithConfig(Datadog, ioutil.ReadFile, secrets.Refresh)
+}
+
+// ReloadAPIKeys reads the api keys of the main and additional endpoints from
+// the configuration file again, using the values of the secrets already
+// fetched, so the keys follow a refresh of the secrets.
+func ReloadAPIKeys() error {
+	return refreshAPIKeysWithConfig(Datadog, ioutil.ReadFile, secrets.Decrypt)
+}
+
+func refreshAPIKeysWithConfig(config Config, readFile func(string) ([]byte, error), decrypt func([]byte, string) ([]byte, error)) error {
+	path := config.ConfigFileUsed()
+	if path == "" {
+		return fmt.Errorf("no configuration file loaded")
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", path, err)
+	}
+
+	var conf map[string]interface{}
+	if err = yaml.Unmarshal(data, &conf); err != nil {
+		return fmt.Errorf("unable to parse %s: %s", path, err)
+	}
+
+	settings := map[string]interface{}{}
+	for _, key := range apiKeysSettings {
+		// the environment variables take precedence over the file
+		if _, found := os.LookupEnv("DD_" + strings.ToUpper(key)); found {
+			continue
+		}
+		if value, found := conf[key]; found {
+			settings[key] = value
+		}
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	if config.GetString("secret_backend_command") != "" {
+		yamlSettings, err := yaml.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("unable to marshal the api keys to YAML to decrypt secrets: %v", err)
+		}
+		yamlSettings, err = decrypt(yamlSettings, filepath.Base(path))
+		if err != nil {
+			return fmt.Errorf("unable to decrypt the api keys: %v", err)
+		}
+		settings = map[string]interface{}{}
+		if err = yaml.Unmarshal(yamlSettings, &settings); err != nil {
+			return fmt.Errorf("unable to parse the decrypted api keys: %v", err)
+		}
+	}
+
+	for key, value := range settings {
+		config.Set(key, value)
+	}
+	sanitizeAPIKey(config)
+	return nil
+}
+
 // GetMultipleEndpoints returns the api keys per domain specified in the main agent config
 func GetMultipleEndpoints() (map[string][]string, error) {
 	return getMultipleEndpointsWithConfig(Datadog)
@@ -747,7 +1043,56 @@ func getMultipleEndpointsWithConfig(config Config) (map[string][]string, error)
 		}
 	}
 
-	// dedupe api keys and remove domains with no api keys (or empty ones)
+	dedupeAPIKeys(keysPerDomain)
+	return keysPerDomain, nil
+}
+
+// GetAdditionalEndpointsPerType returns the api keys per domain configured
+// in `additional_endpoints_per_type`, for each data type
+func GetAdditionalEndpointsPerType() (map[string]map[string][]string, error) {
+	return getAdditionalEndpointsPerTypeWithConfig(Datadog)
+}
+
+// getAdditionalEndpointsPerTypeWithConfig implements the logic to extract the
+// api keys per domain, for each data type, from an agent config
+func getAdditionalEndpointsPerTypeWithConfig(config Config) (map[string]map[string][]string, error) {
+	var endpointsPerType map[string]map[string][]string
+	err := config.UnmarshalKey("additional_endpoints_per_type", &endpointsPerType)
+	if err != nil {
+		return nil, err
+	}
+
+	keysPerDomainPerType := make(map[string]map[string][]string)
+	for dataType, keysPerDomain := range endpointsPerType {
+		if !isValidEndpointDataType(dataType) {
+			log.Warnf("Unknown data type %q in 'additional_endpoints_per_type', expected one of %v: ignoring it", dataType, EndpointDataTypes)
+			continue
+		}
+		for domain := range keysPerDomain {
+			if _, err := url.Parse(domain); err != nil {
+				return nil, fmt.Errorf("could not parse url from 'additional_endpoints_per_type' %s: %s", domain, err)
+			}
+		}
+		dedupeAPIKeys(keysPerDomain)
+		if len(keysPerDomain) > 0 {
+			keysPerDomainPerType[dataType] = keysPerDomain
+		}
+	}
+
+	return keysPerDomainPerType, nil
+}
+
+func isValidEndpointDataType(dataType string) bool {
+	for _, t := range EndpointDataTypes {
+		if t == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeAPIKeys dedupes api keys and removes domains with no api keys (or empty ones)
+func dedupeAPIKeys(keysPerDomain map[string][]string) {
 	for domain, apiKeys := range keysPerDomain {
 		dedupedAPIKeys := make([]string, 0, len(apiKeys))
 		seen := make(map[string]bool)
@@ -766,8 +1111,6 @@ func getMultipleEndpointsWithConfig(config Config) (map[string][]string, error)
 			delete(keysPerDomain, domain)
 		}
 	}
-
-	return keysPerDomain, nil
 }
 
 // IsContainerized returns whether the Agent is running on a Docker container
@@ -14,6 +14,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/secrets"
 )
 
 func setupConf() Config {
@@ -484,6 +486,25 @@ func TestLoadProxyStdEnvOnly(t *testing.T) {
 	os.Unsetenv("http_proxy")
 }
 
+func TestReloadProxies(t *testing.T) {
+	Mock()
+
+	os.Setenv("DD_PROXY_HTTP", "dd_http_url")
+	defer os.Unsetenv("DD_PROXY_HTTP")
+
+	ReloadProxies(&Proxy{HTTP: "http_url", HTTPS: "https_url", NoProxy: []string{"a"}})
+	assert.Equal(t,
+		&Proxy{
+			HTTP:    "dd_http_url",
+			HTTPS:   "https_url",
+			NoProxy: []string{"a"}},
+		GetProxies())
+	assert.Equal(t, "https_url", Datadog.GetString("proxy.https"))
+
+	ReloadProxies(nil)
+	assert.Equal(t, &Proxy{HTTP: "dd_http_url", NoProxy: []string{}}, GetProxies())
+}
+
 func TestLoadProxyDDSpecificEnvOnly(t *testing.T) {
 	config := setupConf()
 
@@ -636,3 +657,105 @@ func TestSecretBackendWithMultipleEndpoints(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedKeysPerDomain, keysPerDomain)
 }
+
+func TestGetAdditionalEndpointsPerType(t *testing.T) {
+	datadogYaml := `
+api_key: fakeapikey
+
+additional_endpoints_per_type:
+  metrics:
+    "https://app.datadoghq.eu":
+    - euapikey
+    - euapikey
+    "https://foo.datadoghq.com":
+    - " "
+  events:
+    "https://app.datadoghq.eu":
+    - euapikey2
+  unknown:
+    "https://app.datadoghq.eu":
+    - euapikey3
+`
+
+	testConfig := setupConfFromYAML(datadogYaml)
+
+	endpoints, err := getAdditionalEndpointsPerTypeWithConfig(testConfig)
+
+	expectedEndpoints := map[string]map[string][]string{
+		"metrics": {
+			"https://app.datadoghq.eu": {"euapikey"},
+		},
+		"events": {
+			"https://app.datadoghq.eu": {"euapikey2"},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, expectedEndpoints, endpoints)
+}
+
+func TestGetAdditionalEndpointsPerTypeEmpty(t *testing.T) {
+	testConfig := setupConfFromYAML("api_key: fakeapikey")
+
+	endpoints, err := getAdditionalEndpointsPerTypeWithConfig(testConfig)
+
+	assert.Nil(t, err)
+	assert.Len(t, endpoints, 0)
+}
+
+func TestRefreshAPIKeys(t *testing.T) {
+	datadogYaml := `
+api_key: fakeapikey
+additional_endpoints:
+  "https://app.datadoghq.com":
+  - fakeapikey2
+additional_endpoints_per_type:
+  metrics:
+    "https://app.datadoghq.eu":
+    - euapikey
+`
+	testConfig := setupConfFromYAML(datadogYaml)
+	testConfig.SetConfigFile("/etc/datadog-agent/datadog.yaml")
+
+	rotatedYaml := `
+api_key: " rotatedapikey "
+additional_endpoints:
+  "https://app.datadoghq.com":
+  - rotatedapikey2
+additional_endpoints_per_type:
+  metrics:
+    "https://app.datadoghq.eu":
+    - roteuapikey
+`
+	err := refreshAPIKeysWithConfig(testConfig, func(path string) ([]byte, error) {
+		assert.Equal(t, "/etc/datadog-agent/datadog.yaml", path)
+		return []byte(rotatedYaml), nil
+	}, secrets.Refresh)
+	require.Nil(t, err)
+
+	keysPerDomain, err := getMultipleEndpointsWithConfig(testConfig)
+	require.Nil(t, err)
+	assert.EqualValues(t, map[string][]string{
+		"https://app.datadoghq.com": {"rotatedapikey", "rotatedapikey2"},
+	}, keysPerDomain)
+
+	keysPerDomainPerType, err := getAdditionalEndpointsPerTypeWithConfig(testConfig)
+	require.Nil(t, err)
+	assert.EqualValues(t, map[string]map[string][]string{
+		"metrics": {"https://app.datadoghq.eu": {"roteuapikey"}},
+	}, keysPerDomainPerType)
+}
+
+func TestRefreshAPIKeysEnvPrecedence(t *testing.T) {
+	os.Setenv("DD_API_KEY", "envapikey")
+	defer os.Unsetenv("DD_API_KEY")
+
+	testConfig := setupConfFromYAML("api_key: fakeapikey")
+	testConfig.SetConfigFile("/etc/datadog-agent/datadog.yaml")
+
+	err := refreshAPIKeysWithConfig(testConfig, func(string) ([]byte, error) {
+		return []byte("api_key: rotatedapikey"), nil
+	}, secrets.Refresh)
+	require.Nil(t, err)
+	assert.Equal(t, "envapikey", testConfig.GetString("api_key"))
+}
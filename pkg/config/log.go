@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/cihub/seelog"
@@ -27,6 +28,23 @@ const logDateFormat = "2006-01-02 15:04:05 MST" // see time.Format for format sy
 
 var syslogTLSConfig *tls.Config
 
+// loggerParams are the parameters of SetupLogger, kept to rebuild the
+// logger when its level changes at runtime
+type loggerParams struct {
+	loggerName   LoggerName
+	logLevel     string
+	logFile      string
+	syslogURI    string
+	syslogRFC    bool
+	logToConsole bool
+	jsonFormat   bool
+}
+
+var (
+	loggerSetup      *loggerParams
+	loggerSetupMutex sync.Mutex
+)
+
 // BuildCommonFormat returns the log common format seelog string
 func BuildCommonFormat(loggerName LoggerName) string {
 	return fmt.Sprintf("%%Date(%s) | %s | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n", logDateFormat, loggerName)
@@ -65,15 +83,83 @@ func getSyslogTLSKeyPair() (*tls.Certificate, error) {
 // a non empty syslogURI will enable syslog, and format them following RFC 5424 if specified
 // you can also specify to log to the console and in JSON format
 func SetupLogger(loggerName LoggerName, logLevel, logFile, syslogURI string, syslogRFC, logToConsole, jsonFormat bool) error {
+	params := loggerParams{
+		loggerName:   loggerName,
+		logLevel:     logLevel,
+		logFile:      logFile,
+		syslogURI:    syslogURI,
+		syslogRFC:    syslogRFC,
+		logToConsole: logToConsole,
+		jsonFormat:   jsonFormat,
+	}
+	logger, err := buildLogger(params)
+	if err != nil {
+		return err
+	}
+	seelog.ReplaceLogger(logger)
+
+	log.SetupDatadogLogger(logger, seelogLevel(logLevel))
+
+	loggerSetupMutex.Lock()
+	loggerSetup = &params
+	loggerSetupMutex.Unlock()
+	return nil
+}
+
+// ChangeLogLevel changes the level of the logger set up by SetupLogger at
+// runtime. The logger is rebuilt, as its outputs filter on the level too.
+func ChangeLogLevel(level string) error {
+	if _, ok := seelog.LogLevelFromString(seelogLevel(level)); !ok {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+
+	loggerSetupMutex.Lock()
+	defer loggerSetupMutex.Unlock()
+
+	if loggerSetup == nil {
+		return errors.New("the logger is not set up")
+	}
+	params := *loggerSetup
+	params.logLevel = level
+
+	logger, err := buildLogger(params)
+	if err != nil {
+		return err
+	}
+	// Same stack depth as set by log.SetupDatadogLogger
+	logger.SetAdditionalStackDepth(2)
+
+	if err := log.ChangeLogLevel(seelogLevel(level)); err != nil {
+		return err
+	}
+	log.ReplaceLogger(logger)
+	seelog.ReplaceLogger(logger)
+
+	loggerSetup = &params
+	Datadog.Set("log_level", level)
+	return nil
+}
+
+// seelogLevel returns the seelog name of a log level
+func seelogLevel(logLevel string) string {
+	level := strings.ToLower(logLevel)
+	if level == "warning" { // Common gotcha when used to agent5
+		level = "warn"
+	}
+	return level
+}
+
+// buildLogger builds the seelog logger described by the parameters of SetupLogger
+func buildLogger(p loggerParams) (seelog.LoggerInterface, error) {
 	var syslog bool
 	var useTLS bool
 
-	if syslogURI != "" { // non-blank uri enables syslog
+	if p.syslogURI != "" { // non-blank uri enables syslog
 		syslog = true
 
 		syslogTLSKeyPair, err := getSyslogTLSKeyPair()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if syslogTLSKeyPair != nil {
@@ -85,33 +171,30 @@ func SetupLogger(loggerName LoggerName, logLevel, logFile, syslogURI string, sys
 		}
 	}
 
-	seelogLogLevel := strings.ToLower(logLevel)
-	if seelogLogLevel == "warning" { // Common gotcha when used to agent5
-		seelogLogLevel = "warn"
-	}
+	seelogLogLevel := seelogLevel(p.logLevel)
 
 	configTemplate := fmt.Sprintf(`<seelog minlevel="%s">`, seelogLogLevel)
 
 	formatID := "common"
-	if jsonFormat {
+	if p.jsonFormat {
 		formatID = "json"
 	}
 
 	configTemplate += fmt.Sprintf(`<outputs formatid="%s">`, formatID)
 
-	if logToConsole {
+	if p.logToConsole {
 		configTemplate += `<console />`
 	}
-	if logFile != "" {
-		configTemplate += fmt.Sprintf(`<rollingfile type="size" filename="%s" maxsize="%d" maxrolls="%d" />`, logFile, Datadog.GetSizeInBytes("log_file_max_size"), Datadog.GetInt("log_file_max_rolls"))
+	if p.logFile != "" {
+		configTemplate += fmt.Sprintf(`<rollingfile type="size" filename="%s" maxsize="%d" maxrolls="%d" />`, p.logFile, Datadog.GetSizeInBytes("log_file_max_size"), Datadog.GetInt("log_file_max_rolls"))
 	}
 	if syslog {
 		var syslogTemplate string
-		if syslogURI != "" {
+		if p.syslogURI != "" {
 			syslogTemplate = fmt.Sprintf(
 				`<custom name="syslog" formatid="syslog-%s" data-uri="%s" data-tls="%v" />`,
 				formatID,
-				syslogURI,
+				p.syslogURI,
 				useTLS,
 			)
 		} else {
@@ -124,24 +207,17 @@ func SetupLogger(loggerName LoggerName, logLevel, logFile, syslogURI string, sys
 	<formats>
 		<format id="json" format="%s"/>
 		<format id="common" format="%s"/>
-		<format id="syslog-json" format="%%CustomSyslogHeader(20,`+strconv.FormatBool(syslogRFC)+`){&quot;agent&quot;:&quot;%s&quot;,&quot;level&quot;:&quot;%%LEVEL&quot;,&quot;relfile&quot;:&quot;%%ShortFilePath&quot;,&quot;line&quot;:&quot;%%Line&quot;,&quot;msg&quot;:&quot;%%Msg&quot;}%%n"/>
-		<format id="syslog-common" format="%%CustomSyslogHeader(20,`+strconv.FormatBool(syslogRFC)+`) %s | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n" />
+		<format id="syslog-json" format="%%CustomSyslogHeader(20,`+strconv.FormatBool(p.syslogRFC)+`){&quot;agent&quot;:&quot;%s&quot;,&quot;level&quot;:&quot;%%LEVEL&quot;,&quot;relfile&quot;:&quot;%%ShortFilePath&quot;,&quot;line&quot;:&quot;%%Line&quot;,&quot;msg&quot;:&quot;%%Msg&quot;}%%n"/>
+		<format id="syslog-common" format="%%CustomSyslogHeader(20,`+strconv.FormatBool(p.syslogRFC)+`) %s | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n" />
 	</formats>
 </seelog>`,
-		BuildJSONFormat(loggerName),
-		BuildCommonFormat(loggerName),
-		strings.ToLower(string(loggerName)),
-		loggerName,
+		BuildJSONFormat(p.loggerName),
+		BuildCommonFormat(p.loggerName),
+		strings.ToLower(string(p.loggerName)),
+		p.loggerName,
 	)
 
-	logger, err := seelog.LoggerFromConfigAsString(configTemplate)
-	if err != nil {
-		return err
-	}
-	seelog.ReplaceLogger(logger)
-
-	log.SetupDatadogLogger(logger, seelogLogLevel)
-	return nil
+	return seelog.LoggerFromConfigAsString(configTemplate)
 }
 
 // ErrorLogWriter is a Writer that logs all written messages with the global seelog logger
@@ -12,6 +12,7 @@ import (
 
 	"github.com/cihub/seelog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractShortPathFromFullPath(t *testing.T) {
@@ -45,3 +46,19 @@ func BenchmarkLogFormatFilename(b *testing.B) {
 func BenchmarkLogFormatShortFilePath(b *testing.B) {
 	benchmarkLogFormat("%Date(%s) | %LEVEL | (%ShortFilePath:%Line in %FuncShort) | %Msg", b)
 }
+
+func TestChangeLogLevel(t *testing.T) {
+	mockConfig := Mock()
+	mockConfig.Set("log_level", "info")
+	defer mockConfig.Set("log_level", "info")
+
+	require.NoError(t, SetupLogger("TEST", "info", "", "", false, true, false))
+
+	assert.Error(t, ChangeLogLevel("verbose"))
+	assert.Equal(t, "info", mockConfig.GetString("log_level"))
+
+	require.NoError(t, ChangeLogLevel("debug"))
+	assert.Equal(t, "debug", mockConfig.GetString("log_level"))
+	assert.Equal(t, "debug", loggerSetup.logLevel)
+	assert.True(t, loggerSetup.logToConsole)
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package settings
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// LogLevelRuntimeSetting changes the log level of the agent
+type LogLevelRuntimeSetting struct{}
+
+// Name returns the name of the setting
+func (l LogLevelRuntimeSetting) Name() string {
+	return "log_level"
+}
+
+// Description returns the description of the setting
+func (l LogLevelRuntimeSetting) Description() string {
+	return "Set the log level of the agent: trace, debug, info, warn, error, critical or off"
+}
+
+// ConfigKeys returns the configuration key of the log level
+func (l LogLevelRuntimeSetting) ConfigKeys() []string {
+	return []string{"log_level"}
+}
+
+// Get returns the current log level
+func (l LogLevelRuntimeSetting) Get() (interface{}, error) {
+	return config.Datadog.GetString("log_level"), nil
+}
+
+// Set changes the log level
+func (l LogLevelRuntimeSetting) Set(value string) error {
+	return config.ChangeLogLevel(value)
+}
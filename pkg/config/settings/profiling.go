@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package settings
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+const (
+	// blockProfileRate samples one blocking event per 10µs spent blocked
+	blockProfileRate = 10000
+	// mutexProfileFraction samples one mutex contention event out of 10
+	mutexProfileFraction = 10
+)
+
+// ProfilingRuntimeSetting enables the block and mutex profiles, served
+// with the other profiles by the expvar server under /debug/pprof. They
+// are disabled by default, as they slow the agent down.
+type ProfilingRuntimeSetting struct {
+	m       sync.Mutex
+	enabled bool
+}
+
+// Name returns the name of the setting
+func (p *ProfilingRuntimeSetting) Name() string {
+	return "profiling"
+}
+
+// Description returns the description of the setting
+func (p *ProfilingRuntimeSetting) Description() string {
+	return "Enable the block and mutex profiles of the agent: true or false"
+}
+
+// ConfigKeys returns nil, the profiles can't be enabled in the configuration
+func (p *ProfilingRuntimeSetting) ConfigKeys() []string {
+	return nil
+}
+
+// Get returns true if the profiles are enabled
+func (p *ProfilingRuntimeSetting) Get() (interface{}, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.enabled, nil
+}
+
+// Set enables or disables the profiles
+func (p *ProfilingRuntimeSetting) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	if enabled {
+		runtime.SetBlockProfileRate(blockProfileRate)
+		runtime.SetMutexProfileFraction(mutexProfileFraction)
+	} else {
+		runtime.SetBlockProfileRate(0)
+		runtime.SetMutexProfileFraction(0)
+	}
+	p.enabled = enabled
+	return nil
+}
@@ -0,0 +1,221 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package settings implements the settings of the agent that can be changed
+// at runtime, without restarting it.
+package settings
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RuntimeSetting is a setting of the agent that can be changed at runtime.
+// ConfigKeys returns the configuration keys the setting changes, nil when
+// it can't be set in the configuration.
+type RuntimeSetting interface {
+	Name() string
+	Description() string
+	ConfigKeys() []string
+	Get() (interface{}, error)
+	Set(value string) error
+}
+
+// Sources of the values of the runtime settings
+const (
+	// SourceDefault is the source of the settings never changed at runtime
+	SourceDefault = "default"
+	// SourceCLI is the source of the settings changed with `agent config set`
+	SourceCLI = "cli"
+	// SourceRemoteConfig is the source of the settings changed by the remote configuration
+	SourceRemoteConfig = "remote-config"
+	// SourceConfigFile is the source of the settings reloaded from the configuration file
+	SourceConfigFile = "config-file"
+)
+
+// SettingInfo describes a registered runtime setting
+type SettingInfo struct {
+	Description string      `json:"description"`
+	Value       interface{} `json:"value"`
+	Source      string      `json:"source"`
+	RevertAt    *time.Time  `json:"revert_at,omitempty"`
+}
+
+// SetRequest is the body of the API requests changing a runtime setting
+type SetRequest struct {
+	Value string `json:"value"`
+	// RevertAfter is a duration, like "10m", after which the setting is
+	// reverted to its previous value. Empty to keep the value.
+	RevertAfter string `json:"revert_after,omitempty"`
+}
+
+// pendingRevert restores the value of a setting when its timer fires
+type pendingRevert struct {
+	timer    *time.Timer
+	value    string
+	source   string
+	revertAt time.Time
+}
+
+var (
+	runtimeSettings = make(map[string]RuntimeSetting)
+	settingSources  = make(map[string]string)
+	pendingReverts  = make(map[string]*pendingRevert)
+	settingsMutex   sync.Mutex
+)
+
+// RegisterRuntimeSetting makes a setting changeable at runtime
+func RegisterRuntimeSetting(setting RuntimeSetting) error {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if _, found := runtimeSettings[setting.Name()]; found {
+		return fmt.Errorf("the runtime setting %s is already registered", setting.Name())
+	}
+	runtimeSettings[setting.Name()] = setting
+	return nil
+}
+
+// RuntimeSettings returns the registered runtime settings with their value
+func RuntimeSettings() map[string]SettingInfo {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	infos := make(map[string]SettingInfo, len(runtimeSettings))
+	for name, setting := range runtimeSettings {
+		info := SettingInfo{Description: setting.Description(), Source: sourceOf(name)}
+		if value, err := setting.Get(); err == nil {
+			info.Value = value
+		}
+		if revert, found := pendingReverts[name]; found {
+			revertAt := revert.revertAt
+			info.RevertAt = &revertAt
+		}
+		infos[name] = info
+	}
+	return infos
+}
+
+// GetRuntimeSetting returns the current value of a runtime setting
+func GetRuntimeSetting(name string) (interface{}, error) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	setting, found := runtimeSettings[name]
+	if !found {
+		return nil, fmt.Errorf("unknown runtime setting %s", name)
+	}
+	return setting.Get()
+}
+
+// RuntimeSettingConfigKeys returns the configuration keys a runtime setting changes
+func RuntimeSettingConfigKeys(name string) ([]string, error) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	setting, found := runtimeSettings[name]
+	if !found {
+		return nil, fmt.Errorf("unknown runtime setting %s", name)
+	}
+	return setting.ConfigKeys(), nil
+}
+
+// RuntimeSettingSource returns the source of the current value of a runtime setting
+func RuntimeSettingSource(name string) (string, error) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if _, found := runtimeSettings[name]; !found {
+		return "", fmt.Errorf("unknown runtime setting %s", name)
+	}
+	return sourceOf(name), nil
+}
+
+// sourceOf returns the source of a setting, callers must hold settingsMutex
+func sourceOf(name string) string {
+	if source, found := settingSources[name]; found {
+		return source
+	}
+	return SourceDefault
+}
+
+// SetRuntimeSetting changes the value of a runtime setting from the CLI. When
+// revertAfter is positive, the value the setting had before being changed is
+// restored after that delay. Changing the setting again before the revert
+// postpones it with the new delay, or cancels it when the new delay is 0.
+func SetRuntimeSetting(name string, value string, revertAfter time.Duration) error {
+	return SetRuntimeSettingFromSource(name, value, revertAfter, SourceCLI)
+}
+
+// SetRuntimeSettingFromSource changes the value of a runtime setting like
+// SetRuntimeSetting, recording where the change comes from.
+func SetRuntimeSettingFromSource(name string, value string, revertAfter time.Duration, source string) error {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	setting, found := runtimeSettings[name]
+	if !found {
+		return fmt.Errorf("unknown runtime setting %s", name)
+	}
+
+	// The value to revert to is the one before the first change
+	previous, pending := pendingReverts[name]
+	var revertValue, revertSource string
+	if pending {
+		revertValue = previous.value
+		revertSource = previous.source
+	} else if revertAfter > 0 {
+		current, err := setting.Get()
+		if err != nil {
+			return fmt.Errorf("unable to get the value of %s to revert it: %s", name, err)
+		}
+		revertValue = fmt.Sprint(current)
+		revertSource = sourceOf(name)
+	}
+
+	if err := setting.Set(value); err != nil {
+		return err
+	}
+	settingSources[name] = source
+	log.Infof("Runtime setting %s set to %s by %s", name, value, source)
+
+	if pending {
+		previous.timer.Stop()
+		delete(pendingReverts, name)
+	}
+	if revertAfter > 0 {
+		revert := &pendingRevert{
+			value:    revertValue,
+			source:   revertSource,
+			revertAt: time.Now().Add(revertAfter),
+		}
+		revert.timer = time.AfterFunc(revertAfter, func() { revertRuntimeSetting(name, revert) })
+		pendingReverts[name] = revert
+		log.Infof("Runtime setting %s will be reverted to %s at %s", name, revertValue, revert.revertAt)
+	}
+	return nil
+}
+
+// revertRuntimeSetting restores the value of a setting, unless the revert
+// was cancelled or rescheduled in the meantime
+func revertRuntimeSetting(name string, revert *pendingRevert) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if pendingReverts[name] != revert {
+		return
+	}
+	delete(pendingReverts, name)
+
+	if err := runtimeSettings[name].Set(revert.value); err != nil {
+		log.Errorf("Unable to revert the runtime setting %s to %s: %s", name, revert.value, err)
+		return
+	}
+	settingSources[name] = revert.source
+	log.Infof("Runtime setting %s reverted to %s", name, revert.value)
+}
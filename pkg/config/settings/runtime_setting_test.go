@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSetting struct {
+	m     sync.Mutex
+	name  string
+	value string
+}
+
+func (f *fakeSetting) Name() string         { return f.name }
+func (f *fakeSetting) Description() string  { return "fake setting" }
+func (f *fakeSetting) ConfigKeys() []string { return nil }
+
+func (f *fakeSetting) Get() (interface{}, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.value, nil
+}
+
+func (f *fakeSetting) Set(value string) error {
+	if value == "invalid" {
+		return fmt.Errorf("invalid value")
+	}
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.value = value
+	return nil
+}
+
+func TestRuntimeSetting(t *testing.T) {
+	setting := &fakeSetting{name: "fake", value: "a"}
+	require.NoError(t, RegisterRuntimeSetting(setting))
+	defer func() {
+		delete(runtimeSettings, "fake")
+		delete(settingSources, "fake")
+	}()
+	assert.Error(t, RegisterRuntimeSetting(setting))
+
+	require.NoError(t, SetRuntimeSetting("fake", "b", 0))
+	value, err := GetRuntimeSetting("fake")
+	require.NoError(t, err)
+	assert.Equal(t, "b", value)
+
+	assert.Error(t, SetRuntimeSetting("fake", "invalid", 0))
+	assert.Error(t, SetRuntimeSetting("unknown", "b", 0))
+	_, err = GetRuntimeSetting("unknown")
+	assert.Error(t, err)
+
+	info := RuntimeSettings()["fake"]
+	assert.Equal(t, "b", info.Value)
+	assert.Equal(t, SourceCLI, info.Source)
+	assert.Nil(t, info.RevertAt)
+}
+
+func TestRuntimeSettingSource(t *testing.T) {
+	setting := &fakeSetting{name: "source", value: "a"}
+	require.NoError(t, RegisterRuntimeSetting(setting))
+	defer func() {
+		delete(runtimeSettings, "source")
+		delete(settingSources, "source")
+	}()
+
+	source, err := RuntimeSettingSource("source")
+	require.NoError(t, err)
+	assert.Equal(t, SourceDefault, source)
+	_, err = RuntimeSettingSource("unknown")
+	assert.Error(t, err)
+
+	require.NoError(t, SetRuntimeSettingFromSource("source", "b", 50*time.Millisecond, SourceRemoteConfig))
+	source, _ = RuntimeSettingSource("source")
+	assert.Equal(t, SourceRemoteConfig, source)
+
+	// the revert restores the source of the previous value
+	time.Sleep(100 * time.Millisecond)
+	source, _ = RuntimeSettingSource("source")
+	assert.Equal(t, SourceDefault, source)
+}
+
+func TestRuntimeSettingRevert(t *testing.T) {
+	setting := &fakeSetting{name: "revert", value: "a"}
+	require.NoError(t, RegisterRuntimeSetting(setting))
+	defer delete(runtimeSettings, "revert")
+
+	// Changing the setting twice reverts it to its first value
+	require.NoError(t, SetRuntimeSetting("revert", "b", time.Hour))
+	require.NoError(t, SetRuntimeSetting("revert", "c", 50*time.Millisecond))
+	assert.NotNil(t, RuntimeSettings()["revert"].RevertAt)
+
+	time.Sleep(100 * time.Millisecond)
+	value, _ := setting.Get()
+	assert.Equal(t, "a", value)
+	assert.Nil(t, RuntimeSettings()["revert"].RevertAt)
+
+	// A delay of 0 cancels the revert
+	require.NoError(t, SetRuntimeSetting("revert", "b", 50*time.Millisecond))
+	require.NoError(t, SetRuntimeSetting("revert", "c", 0))
+	time.Sleep(100 * time.Millisecond)
+	value, _ = setting.Get()
+	assert.Equal(t, "c", value)
+}
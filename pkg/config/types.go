@@ -28,6 +28,7 @@ type Config interface {
 	SetDefault(key string, value interface{})
 	SetFs(fs afero.Fs)
 	IsSet(key string) bool
+	InConfig(key string) bool
 
 	Get(key string) interface{}
 	GetString(key string) string
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package validate
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// deprecatedAgentKeys maps the deprecated settings of datadog.yaml to
+// their replacement
+var deprecatedAgentKeys = map[string]string{
+	"log_enabled": "logs_enabled",
+}
+
+// agentSchema describes the settings of datadog.yaml: the known keys with
+// their default value, which gives their type
+type agentSchema struct {
+	defaults  map[string]interface{}
+	sections  map[string]bool // the parents of the known keys
+	wildcards map[string]bool // the sections accepting any sub-key, like apm_config
+	keys      []string
+}
+
+func newAgentSchema(defaults map[string]interface{}) *agentSchema {
+	s := &agentSchema{
+		defaults:  make(map[string]interface{}, len(defaults)),
+		sections:  make(map[string]bool),
+		wildcards: make(map[string]bool),
+	}
+	for key, value := range defaults {
+		if strings.HasSuffix(key, ".*") {
+			s.wildcards[strings.TrimSuffix(key, ".*")] = true
+			continue
+		}
+		s.defaults[key] = value
+		s.keys = append(s.keys, key)
+		parts := strings.Split(key, ".")
+		for i := 1; i < len(parts); i++ {
+			s.sections[strings.Join(parts[:i], ".")] = true
+		}
+	}
+	sort.Strings(s.keys)
+	return s
+}
+
+// inWildcard returns whether a key belongs to a section accepting any sub-key
+func (s *agentSchema) inWildcard(path string) bool {
+	for i := strings.LastIndex(path, "."); i >= 0; i = strings.LastIndex(path[:i], ".") {
+		if s.wildcards[path[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAgentConfig checks a datadog.yaml file against the settings of
+// the agent
+func ValidateAgentConfig(path string) ([]Issue, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validateAgentConfig(path, data, newAgentSchema(config.KnownKeysDefaults())), nil
+}
+
+func validateAgentConfig(path string, data []byte, schema *agentSchema) []Issue {
+	v := newValidator(path, data)
+	if doc, ok := v.parse(data); ok {
+		v.agentSection("", doc, schema)
+	}
+	return v.sortedIssues()
+}
+
+func (v *validator) agentSection(prefix string, section map[interface{}]interface{}, schema *agentSchema) {
+	for _, key := range sortedKeys(section) {
+		value := lookup(section, key)
+		path := joinKey(prefix, strings.ToLower(key))
+
+		if replacement, found := deprecatedAgentKeys[path]; found {
+			v.add(SeverityWarning, path, "deprecated option, use %s instead", replacement)
+			continue
+		}
+		expected, known := schema.defaults[path]
+		if schema.sections[path] {
+			switch sub := value.(type) {
+			case map[interface{}]interface{}:
+				// the sections bound as a whole, like process_config, are
+				// read by other processes and take any setting
+				if !known || expected != nil {
+					v.agentSection(path, sub, schema)
+				}
+			case nil:
+				v.add(SeverityWarning, path, "empty section, make sure its settings are indented under it")
+			default:
+				v.add(SeverityError, path, "expects a map of settings, got %s", describe(value))
+			}
+			continue
+		}
+		if known {
+			if err := checkType(value, expected); err != nil {
+				v.add(SeverityError, path, "%s", err)
+			}
+			continue
+		}
+		if schema.wildcards[path] || schema.inWildcard(path) {
+			continue
+		}
+		v.add(SeverityWarning, path, "unknown key, the agent ignores it%s", suggest(path, schema.keys))
+	}
+}
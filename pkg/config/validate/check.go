@@ -0,0 +1,272 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package validate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+// checkSections lists the top-level keys of the check configuration files
+var checkSections = map[string]bool{
+	"init_config":    true,
+	"instances":      true,
+	"logs":           true,
+	"ad_identifiers": true,
+	"cluster_check":  true,
+	"jmx_metrics":    true,
+}
+
+// deprecatedCheckKeys maps the deprecated top-level keys of the check
+// configuration files to their replacement
+var deprecatedCheckKeys = map[string]string{
+	"docker_images": "ad_identifiers",
+}
+
+// instanceSettings are the settings common to the instances of all the
+// checks, with a value of their type
+var instanceSettings = map[string]interface{}{
+	"min_collection_interval": 0,
+	"empty_default_hostname":  false,
+	"tags":                    []string{},
+	"name":                    "",
+	"namespace":               "",
+}
+
+// logsSettings are the settings of a logs configuration, with a value of
+// their type, read from the fields of LogsConfig
+var logsSettings = settingsOf(reflect.TypeOf(logsconfig.LogsConfig{}))
+
+// settingsOf returns the settings decoded in a struct, with a value of
+// their type
+func settingsOf(t reflect.Type) map[string]interface{} {
+	settings := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.ToLower(field.Name)
+		if tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; tag != "" {
+			name = tag
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Bool:
+			settings[name] = false
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			settings[name] = 0
+		case reflect.String:
+			settings[name] = ""
+		case reflect.Slice:
+			if fieldType.Elem().Kind() == reflect.String {
+				settings[name] = []string{}
+			} else {
+				settings[name] = []interface{}{}
+			}
+		case reflect.Struct, reflect.Map:
+			settings[name] = map[string]interface{}{}
+		default:
+			settings[name] = nil
+		}
+	}
+	return settings
+}
+
+// ValidateCheckConfig checks a configuration file of conf.d
+func ValidateCheckConfig(path string) ([]Issue, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validateCheckConfig(path, data), nil
+}
+
+// ValidateConfd checks the configuration files of a conf.d directory,
+// the way the agent finds them: the YAML files at its root and in its
+// <check>.d sub-directories
+func ValidateConfd(confd string) ([]Issue, error) {
+	var files []string
+	for _, pattern := range []string{"*", filepath.Join("*.d", "*")} {
+		matches, err := filepath.Glob(filepath.Join(confd, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if isCheckConfigFile(match) {
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	var issues []Issue
+	for _, file := range files {
+		fileIssues, err := ValidateCheckConfig(file)
+		if err != nil {
+			issues = append(issues, Issue{File: file, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		issues = append(issues, fileIssues...)
+	}
+	return issues, nil
+}
+
+// isCheckConfigFile returns whether a file is a check configuration, the
+// metrics.yaml files of the JMX checks have their own format
+func isCheckConfigFile(path string) bool {
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return false
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".default")
+	ext := filepath.Ext(name)
+	return (ext == ".yaml" || ext == ".yml") && name != "metrics.yaml" && name != "metrics.yml"
+}
+
+func validateCheckConfig(path string, data []byte) []Issue {
+	v := newValidator(path, data)
+	doc, ok := v.parse(data)
+	if !ok {
+		return v.sortedIssues()
+	}
+
+	for _, key := range sortedKeys(doc) {
+		value := lookup(doc, key)
+		switch {
+		case key == "instances":
+			v.instances(value)
+		case key == "logs":
+			v.logs(value)
+		case key == "ad_identifiers":
+			if err := checkType(value, []string{}); err != nil {
+				v.add(SeverityError, key, "%s", err)
+			}
+		case key == "cluster_check":
+			if err := checkType(value, false); err != nil {
+				v.add(SeverityError, key, "%s", err)
+			}
+		case checkSections[key]:
+		case deprecatedCheckKeys[key] != "":
+			v.add(SeverityWarning, key, "deprecated option, use %s instead", deprecatedCheckKeys[key])
+		case instanceSettings[key] != nil:
+			v.add(SeverityWarning, key, "unknown key, the agent ignores it, did you mean to set it in the instances? Check its indentation")
+		default:
+			v.add(SeverityWarning, key, "unknown key, the agent ignores it%s", suggest(key, sortedSections()))
+		}
+	}
+
+	if lookup(doc, "instances") == nil && lookup(doc, "logs") == nil && lookup(doc, "jmx_metrics") == nil {
+		v.add(SeverityError, "", "no instances nor logs configuration, the file is ignored")
+	}
+	return v.sortedIssues()
+}
+
+func sortedSections() []string {
+	sections := make([]string, 0, len(checkSections))
+	for section := range checkSections {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return sections
+}
+
+func (v *validator) instances(value interface{}) {
+	instances, ok := value.([]interface{})
+	if !ok {
+		if value != nil {
+			v.add(SeverityError, "instances", "expects a list of instances, got %s", describe(value))
+		}
+		return
+	}
+	for i, instance := range instances {
+		path := fmt.Sprintf("instances[%d]", i)
+		settings, ok := instance.(map[interface{}]interface{})
+		if !ok {
+			if instance != nil {
+				v.add(SeverityError, path, "expects a map of settings, got %s", describe(instance))
+			}
+			continue
+		}
+		for _, key := range sortedKeys(settings) {
+			if expected, found := instanceSettings[key]; found {
+				if err := checkType(lookup(settings, key), expected); err != nil {
+					v.add(SeverityError, joinKey(path, key), "%s", err)
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) logs(value interface{}) {
+	logs, ok := value.([]interface{})
+	if !ok {
+		if value != nil {
+			v.add(SeverityError, "logs", "expects a list of logs configurations, got %s", describe(value))
+		}
+		return
+	}
+	for i, item := range logs {
+		path := fmt.Sprintf("logs[%d]", i)
+		settings, ok := item.(map[interface{}]interface{})
+		if !ok {
+			v.add(SeverityError, path, "expects a map of settings, got %s", describe(item))
+			continue
+		}
+		valid := true
+		for _, key := range sortedKeys(settings) {
+			name := strings.ToLower(key)
+			expected, found := logsSettings[name]
+			if !found {
+				v.add(SeverityWarning, joinKey(path, key), "unknown key, the agent ignores it%s", suggest(name, sortedLogsSettings()))
+				continue
+			}
+			if err := checkType(lookup(settings, key), expected); err != nil {
+				v.add(SeverityError, joinKey(path, key), "%s", err)
+				valid = false
+			}
+		}
+		if valid {
+			v.logsConfig(path, settings)
+		}
+	}
+}
+
+// logsConfig decodes a logs configuration the way the logs agent does and
+// reports its validation error
+func (v *validator) logsConfig(path string, settings map[interface{}]interface{}) {
+	raw, err := yaml.Marshal(map[string]interface{}{"logs": []interface{}{settings}})
+	if err != nil {
+		return
+	}
+	configs, err := logsconfig.ParseYAML(raw)
+	if err != nil {
+		v.add(SeverityError, path, "%s", err)
+		return
+	}
+	for _, config := range configs {
+		if err := config.Validate(); err != nil {
+			v.add(SeverityError, path, "%s", err)
+		}
+	}
+}
+
+func sortedLogsSettings() []string {
+	settings := make([]string, 0, len(logsSettings))
+	for setting := range logsSettings {
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+	return settings
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keyLines maps the path of the keys of a YAML document, like
+// `logs_config.container_collect_all` or `instances[0].tags`, to the line
+// they're defined at. yaml.v2 doesn't expose the position of the nodes, so
+// the lines are indexed from the indentation of the block style used by
+// the configuration files; the keys of flow style maps aren't indexed.
+type keyLines map[string]int
+
+// line returns the line of a key, or of its closest indexed parent
+func (l keyLines) line(path string) int {
+	path = strings.ToLower(path)
+	for path != "" {
+		if line, found := l[path]; found {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return 0
+}
+
+var keyRegexp = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"\[{][^:#]*?)\s*:(?:\s+(.*))?$`)
+
+// indexEntry is a map key or a sequence item in the path of a line
+type indexEntry struct {
+	indent int
+	path   string
+	isItem bool
+	items  int
+}
+
+// indexKeys returns the lines of the keys of a YAML document
+func indexKeys(data []byte) keyLines {
+	lines := keyLines{}
+	stack := []*indexEntry{{indent: -1}}
+	top := func() *indexEntry { return stack[len(stack)-1] }
+	blockIndent := -1
+
+	for i, line := range strings.Split(string(data), "\n") {
+		content := strings.TrimRight(strings.TrimLeft(line, " "), " \t\r")
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		// skip the lines of the block scalars
+		if blockIndent >= 0 {
+			if content == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if content == "" || content[0] == '#' || content == "---" || content == "..." {
+			continue
+		}
+
+		// sequence items, possibly nested on a single line like `- - a`
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			for top().indent > indent || (top().indent == indent && top().isItem) {
+				stack = stack[:len(stack)-1]
+			}
+			parent := top()
+			item := &indexEntry{indent: indent, path: fmt.Sprintf("%s[%d]", parent.path, parent.items), isItem: true}
+			stack = append(stack, item)
+			parent.items++
+			lines[item.path] = i + 1
+
+			rest := strings.TrimLeft(content[1:], " ")
+			indent += len(content) - len(rest)
+			content = rest
+		}
+
+		m := keyRegexp.FindStringSubmatch(content)
+		if m == nil {
+			continue
+		}
+		for top().indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		path := joinKey(top().path, strings.ToLower(strings.Trim(m[1], `"'`)))
+		if _, found := lines[path]; !found {
+			lines[path] = i + 1
+		}
+		stack = append(stack, &indexEntry{indent: indent, path: path})
+
+		if value := m[2]; strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = indent
+		}
+	}
+	return lines
+}
@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package validate checks the configuration files of the agent, datadog.yaml
+and the files of conf.d, against the settings the agent knows. It reports
+the unknown keys, the values of the wrong type and the deprecated options
+with the line they're defined at, as a misplaced key, like one indented at
+the wrong level, is otherwise silently ignored by the agent.
+*/
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Severity of an Issue
+type Severity string
+
+// Severities of the issues: the errors prevent the agent from using the
+// configuration as intended, the warnings point at settings it ignores
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a configuration file
+type Issue struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Key      string   `json:"key,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	if i.Key != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", location, i.Severity, i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+}
+
+// CountErrors returns the number of issues of error severity
+func CountErrors(issues []Issue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+var yamlLineRegexp = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// validator collects the issues of a file
+type validator struct {
+	file   string
+	lines  keyLines
+	issues []Issue
+}
+
+func newValidator(file string, data []byte) *validator {
+	return &validator{file: file, lines: indexKeys(data)}
+}
+
+func (v *validator) add(severity Severity, key string, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{
+		File:     v.file,
+		Line:     v.lines.line(key),
+		Key:      key,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// parse unmarshals a YAML document, reporting the syntax errors
+func (v *validator) parse(data []byte) (map[interface{}]interface{}, bool) {
+	var doc interface{}
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		messages := []string{err.Error()}
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			messages = typeErr.Errors
+		}
+		for _, message := range messages {
+			issue := Issue{File: v.file, Severity: SeverityError, Message: message}
+			if m := yamlLineRegexp.FindStringSubmatch(message); m != nil {
+				issue.Line, _ = strconv.Atoi(m[1])
+				issue.Message = m[2]
+			}
+			v.issues = append(v.issues, issue)
+		}
+		return nil, false
+	}
+	if doc == nil {
+		return map[interface{}]interface{}{}, true
+	}
+	m, ok := doc.(map[interface{}]interface{})
+	if !ok {
+		v.add(SeverityError, "", "the file must be a map of settings, got %s", describe(doc))
+		return nil, false
+	}
+	return m, true
+}
+
+// sortedIssues returns the issues ordered by line
+func (v *validator) sortedIssues() []Issue {
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		return v.issues[i].Line < v.issues[j].Line
+	})
+	return v.issues
+}
+
+// sortedKeys returns the keys of a YAML map, in their string form
+func sortedKeys(m map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, fmt.Sprint(k))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookup returns the value of a key of a YAML map from its string form
+func lookup(m map[interface{}]interface{}, key string) interface{} {
+	for k, value := range m {
+		if fmt.Sprint(k) == key {
+			return value
+		}
+	}
+	return nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// checkType returns an error if the value can't be used as the type of
+// expected, the way the agent converts the settings
+func checkType(value, expected interface{}) error {
+	if value == nil {
+		return nil
+	}
+	var err error
+	switch expected.(type) {
+	case bool:
+		_, err = cast.ToBoolE(value)
+	case int, int32, int64, uint, uint32, uint64:
+		_, err = cast.ToInt64E(value)
+	case float32, float64:
+		_, err = cast.ToFloat64E(value)
+	case string:
+		_, err = cast.ToStringE(value)
+	case time.Duration:
+		_, err = cast.ToDurationE(value)
+	case []string, []interface{}:
+		_, err = cast.ToStringSliceE(value)
+	case map[string]string, map[string]interface{}, map[interface{}]interface{}:
+		_, err = cast.ToStringMapE(value)
+	}
+	if err != nil {
+		return fmt.Errorf("expects %s, got %s", describe(expected), describe(value))
+	}
+	return nil
+}
+
+// describe returns the name of the type of a setting, with its article
+func describe(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "a boolean"
+	case int, int32, int64, uint, uint32, uint64:
+		return "an integer"
+	case float32, float64:
+		return "a number"
+	case string:
+		return "a string"
+	case time.Duration:
+		return "a duration"
+	case []string, []interface{}:
+		return "a list"
+	case map[string]string, map[string]interface{}, map[interface{}]interface{}:
+		return "a map"
+	case nil:
+		return "a null value"
+	}
+	return fmt.Sprintf("a %T", value)
+}
+
+// closest returns the candidate the closest to key, if it's close enough
+// to be a typo
+func closest(key string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := distance(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance between two strings
+func distance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// suggest returns a hint about an unknown key: the known key it's likely
+// a typo of, or a known key of the same name at another level, which
+// points at an indentation mistake
+func suggest(path string, known []string) string {
+	name := path[strings.LastIndex(path, ".")+1:]
+	for _, candidate := range known {
+		if (candidate != path && strings.HasSuffix(candidate, "."+name)) || (candidate == name && path != name) {
+			return fmt.Sprintf(", did you mean %s? Check its indentation", candidate)
+		}
+	}
+	if candidate := closest(path, known); candidate != "" {
+		return fmt.Sprintf(", did you mean %s?", candidate)
+	}
+	return ""
+}
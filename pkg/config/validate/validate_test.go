@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package validate
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var testSchema = newAgentSchema(map[string]interface{}{
+	"api_key":                           "",
+	"logs_enabled":                      false,
+	"log_enabled":                       false,
+	"dogstatsd_port":                    8125,
+	"proxy":                             nil,
+	"proxy.http":                        "",
+	"tags":                              []string{},
+	"logs_config":                       map[string]interface{}{"container_collect_all": false, "batch_wait": 5},
+	"logs_config.container_collect_all": false,
+	"logs_config.batch_wait":            5,
+	"apm_config.*":                      nil,
+})
+
+func TestIndexKeys(t *testing.T) {
+	lines := indexKeys([]byte(`# comment
+api_key: abc
+logs_config:
+  container_collect_all: true
+  processing_rules:
+    - type: exclude_at_match
+      pattern: |
+        foo: bar
+      name: first
+    - type: mask
+tags:
+- "env:prod"
+- role: db
+`))
+	assert.Equal(t, keyLines{
+		"api_key":                                 2,
+		"logs_config":                             3,
+		"logs_config.container_collect_all":       4,
+		"logs_config.processing_rules":            5,
+		"logs_config.processing_rules[0]":         6,
+		"logs_config.processing_rules[0].type":    6,
+		"logs_config.processing_rules[0].pattern": 7,
+		"logs_config.processing_rules[0].name":    9,
+		"logs_config.processing_rules[1]":         10,
+		"logs_config.processing_rules[1].type":    10,
+		"tags":                                    11,
+		"tags[0]":                                 12,
+		"tags[1]":                                 13,
+		"tags[1].role":                            13,
+	}, lines)
+	assert.Equal(t, 10, lines.line("logs_config.processing_rules[1].name"))
+	assert.Equal(t, 0, lines.line("unknown"))
+}
+
+func TestValidateAgentConfig(t *testing.T) {
+	issues := validateAgentConfig("datadog.yaml", []byte(`api_key: abc
+log_enabled: true
+dogstatsd_port: not_a_port
+logs_config:
+container_collect_all: true
+apm_config:
+  enabled: true
+proxy:
+  http: http://proxy:3128
+tags: [a, b]
+dogstatsd_prot: 8126
+`), testSchema)
+
+	require.Len(t, issues, 5)
+	assert.Equal(t, Issue{File: "datadog.yaml", Line: 2, Key: "log_enabled", Severity: SeverityWarning, Message: "deprecated option, use logs_enabled instead"}, issues[0])
+	assert.Equal(t, Issue{File: "datadog.yaml", Line: 3, Key: "dogstatsd_port", Severity: SeverityError, Message: "expects an integer, got a string"}, issues[1])
+	assert.Equal(t, Issue{File: "datadog.yaml", Line: 4, Key: "logs_config", Severity: SeverityWarning, Message: "empty section, make sure its settings are indented under it"}, issues[2])
+	assert.Equal(t, Issue{File: "datadog.yaml", Line: 5, Key: "container_collect_all", Severity: SeverityWarning, Message: "unknown key, the agent ignores it, did you mean logs_config.container_collect_all? Check its indentation"}, issues[3])
+	assert.Equal(t, "unknown key, the agent ignores it, did you mean dogstatsd_port?", issues[4].Message)
+	assert.Equal(t, 0, CountErrors(issues[2:]))
+}
+
+func TestValidateAgentConfigSyntax(t *testing.T) {
+	issues := validateAgentConfig("datadog.yaml", []byte(`api_key: abc
+  site: datadoghq.eu
+`), testSchema)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+	assert.Equal(t, "datadog.yaml:2: error: mapping values are not allowed in this context", issues[0].String())
+
+	issues = validateAgentConfig("datadog.yaml", []byte(`logs_config: true`), testSchema)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "expects a map of settings, got a boolean", issues[0].Message)
+
+	issues = validateAgentConfig("datadog.yaml", []byte(`logs_config:
+  batch_wait: later
+  batch_size: 10
+proxy:
+  https: http://proxy:3128
+`), testSchema)
+	require.Len(t, issues, 2)
+	assert.Equal(t, Issue{File: "datadog.yaml", Line: 2, Key: "logs_config.batch_wait", Severity: SeverityError, Message: "expects an integer, got a string"}, issues[0])
+	assert.Equal(t, "logs_config.batch_size", issues[1].Key)
+}
+
+func TestValidateCheckConfig(t *testing.T) {
+	issues := validateCheckConfig("redis.d/conf.yaml", []byte(`init_config:
+instances:
+  - host: localhost
+    min_collection_interval: soon
+tags:
+  - env:prod
+docker_images:
+  - redis
+logs:
+  - type: file
+    service: redis
+  - type: tcp
+    srvice: redis
+`))
+	require.Len(t, issues, 6)
+	assert.Equal(t, Issue{File: "redis.d/conf.yaml", Line: 4, Key: "instances[0].min_collection_interval", Severity: SeverityError, Message: "expects an integer, got a string"}, issues[0])
+	assert.Equal(t, Issue{File: "redis.d/conf.yaml", Line: 5, Key: "tags", Severity: SeverityWarning, Message: "unknown key, the agent ignores it, did you mean to set it in the instances? Check its indentation"}, issues[1])
+	assert.Equal(t, Issue{File: "redis.d/conf.yaml", Line: 7, Key: "docker_images", Severity: SeverityWarning, Message: "deprecated option, use ad_identifiers instead"}, issues[2])
+	assert.Equal(t, Issue{File: "redis.d/conf.yaml", Line: 10, Key: "logs[0]", Severity: SeverityError, Message: "file source must have a path"}, issues[3])
+	assert.Equal(t, Issue{File: "redis.d/conf.yaml", Line: 12, Key: "logs[1]", Severity: SeverityError, Message: "tcp source must have a port"}, issues[4])
+	assert.Equal(t, Issue{File: "redis.d/conf.yaml", Line: 13, Key: "logs[1].srvice", Severity: SeverityWarning, Message: "unknown key, the agent ignores it, did you mean service?"}, issues[5])
+
+	issues = validateCheckConfig("empty.yaml", []byte("init_config:\n"))
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+}
+
+func TestValidateConfd(t *testing.T) {
+	confd, err := ioutil.TempDir("", "conf.d")
+	require.NoError(t, err)
+	defer os.RemoveAll(confd)
+
+	require.NoError(t, os.Mkdir(filepath.Join(confd, "jmx.d"), 0755))
+	files := map[string]string{
+		"valid.yaml":          "instances:\n  - {}\n",
+		"invalid.yaml":        "instances: 1\n",
+		"jmx.d/conf.yaml":     "instances:\n  - port: 7199\n",
+		"jmx.d/metrics.yaml":  "- include: {}\n",
+		"jmx.d/conf.yaml.bak": "- not: a check config\n",
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(confd, name), []byte(content), 0644))
+	}
+
+	issues, err := ValidateConfd(confd)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, filepath.Join(confd, "invalid.yaml"), issues[0].File)
+	assert.Equal(t, "expects a list of instances, got an integer", issues[0].Message)
+}
+
+// placeholderValue matches the settings set to a placeholder in the examples,
+// like `GUI_port: <GUI_PORT>`
+var placeholderValue = regexp.MustCompile(`: <[A-Z0-9_-]+>$`)
+
+// renderConfigTemplate renders the datadog.yaml template with all its
+// sections, and uncomments the settings of the examples
+func renderConfigTemplate(t *testing.T) []byte {
+	tpl, err := template.ParseFiles(filepath.Join("..", "config_template.yaml"))
+	require.NoError(t, err)
+	sections := map[string]bool{}
+	for _, section := range []string{"Common", "Agent", "Metadata", "Dogstatsd", "LogsAgent", "JMX", "Autoconfig",
+		"Logging", "Autodiscovery", "DockerTagging", "Kubelet", "KubernetesTagging", "ECS", "Containerd", "CRI",
+		"ProcessAgent", "SystemProbe", "KubeApiServer", "TraceAgent", "ClusterChecks"} {
+		sections[section] = true
+	}
+	var rendered bytes.Buffer
+	require.NoError(t, tpl.Execute(&rendered, sections))
+
+	var lines []string
+	for _, line := range strings.Split(rendered.String(), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "# ") {
+			line = line[:len(line)-len(trimmed)] + trimmed[2:]
+			line = placeholderValue.ReplaceAllString(line, ":")
+		}
+		lines = append(lines, line)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func TestValidateAgentConfigTemplate(t *testing.T) {
+	data := renderConfigTemplate(t)
+	issues := validateAgentConfig("datadog.yaml", data, newAgentSchema(config.KnownKeysDefaults()))
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+}
+
+func TestValidateAgentConfigKeysWithoutDefault(t *testing.T) {
+	issues := validateAgentConfig("datadog.yaml", []byte(`process_agent_enabled: true
+kubernetes_collect_service_tags: true
+logs_config:
+  dev_mode_no_ssl: true
+  additional_endpoints:
+    - api_key: abc
+      host: intake.example.com
+system_probe_config:
+  enabled: true
+  sysprobe_socket: /opt/datadog-agent/run/sysprobe.sock
+`), newAgentSchema(config.KnownKeysDefaults()))
+	assert.Empty(t, issues)
+}
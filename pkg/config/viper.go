@@ -71,6 +71,13 @@ func (c *safeConfig) IsSet(key string) bool {
 	return c.Viper.IsSet(key)
 }
 
+// InConfig wraps Viper for concurrent access
+func (c *safeConfig) InConfig(key string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.Viper.InConfig(key)
+}
+
 // Get wraps Viper for concurrent access
 func (c *safeConfig) Get(key string) interface{} {
 	c.RLock()
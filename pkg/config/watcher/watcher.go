@@ -0,0 +1,272 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package watcher watches the main configuration file of the agent and
+// reloads the settings that can change without a restart.
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ErrRestartRequired is returned by the reloaders when the new value of a
+// setting can only be applied by restarting the agent
+var ErrRestartRequired = errors.New("requires a restart")
+
+// Reloader applies the new value of a top-level setting of the
+// configuration file, nil when the setting was removed from the file
+type Reloader func(value interface{}) error
+
+// Reload describes how the changes of the configuration file were handled
+type Reload struct {
+	// Applied are the settings reloaded without restart
+	Applied []string
+	// RestartRequired are the settings the agent only reads when it starts
+	RestartRequired []string
+	// Skipped maps the settings not reloaded to the reason why
+	Skipped map[string]string
+	// Failed maps the settings that failed to reload to the error
+	Failed map[string]string
+}
+
+// Watcher polls a configuration file and calls the reloaders of the
+// settings that changed
+type Watcher struct {
+	path      string
+	reloaders map[string]Reloader
+	handler   func(metrics.Event)
+	decrypt   func(data []byte, origin string) ([]byte, error)
+
+	raw      []byte
+	settings map[string]interface{}
+}
+
+// NewWatcher returns a watcher of the configuration file at path, decrypt
+// resolves its secrets and can be nil. The file is read right away, the
+// watcher reports the changes made after that.
+func NewWatcher(path string, reloaders map[string]Reloader, decrypt func([]byte, string) ([]byte, error)) (*Watcher, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no configuration file loaded")
+	}
+	w := &Watcher{
+		path:      path,
+		reloaders: reloaders,
+		decrypt:   decrypt,
+	}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if w.settings, err = w.parse(raw); err != nil {
+		return nil, err
+	}
+	w.raw = raw
+	return w, nil
+}
+
+// SetEventHandler sets the function receiving the events describing the
+// reloads
+func (w *Watcher) SetEventHandler(handler func(metrics.Event)) {
+	w.handler = handler
+}
+
+// Run checks the configuration file every interval until ctx is done
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	log.Infof("Watching %s for changes every %v", w.path, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Check(); err != nil {
+				log.Warnf("Unable to reload %s: %s", w.path, err)
+			}
+		}
+	}
+}
+
+// Check reloads the settings that changed since the previous check, it
+// returns nil when the file didn't change
+func (w *Watcher) Check() (*Reload, error) {
+	raw, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(raw, w.raw) {
+		return nil, nil
+	}
+	// the new settings are compared to the last ones that could be read,
+	// so an invalid file doesn't count as a change
+	settings, err := w.parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	w.raw = raw
+
+	reload := &Reload{Skipped: map[string]string{}, Failed: map[string]string{}}
+	for _, name := range changedSettings(w.settings, settings) {
+		reloader, found := w.reloaders[name]
+		if !found {
+			reload.RestartRequired = append(reload.RestartRequired, name)
+			continue
+		}
+		if _, found := os.LookupEnv("DD_" + strings.ToUpper(name)); found {
+			reload.Skipped[name] = "set in the environment"
+			continue
+		}
+		switch err := reloader(settings[name]); err {
+		case nil:
+			reload.Applied = append(reload.Applied, name)
+		case ErrRestartRequired:
+			reload.RestartRequired = append(reload.RestartRequired, name)
+		default:
+			reload.Failed[name] = err.Error()
+		}
+	}
+	w.settings = settings
+
+	w.report(reload)
+	return reload, nil
+}
+
+// parse returns the top-level settings of a configuration file, with their
+// secrets decrypted
+func (w *Watcher) parse(raw []byte) (map[string]interface{}, error) {
+	if w.decrypt != nil {
+		decrypted, err := w.decrypt(raw, filepath.Base(w.path))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt the secrets: %s", err)
+		}
+		raw = decrypted
+	}
+	var conf map[string]interface{}
+	if err := yaml.Unmarshal(raw, &conf); err != nil {
+		return nil, fmt.Errorf("unable to parse the file: %s", err)
+	}
+	settings := make(map[string]interface{}, len(conf))
+	for key, value := range conf {
+		// the keys of the configuration are case insensitive
+		settings[strings.ToLower(key)] = normalize(value)
+	}
+	return settings, nil
+}
+
+// normalize converts the maps decoded by yaml to maps of strings, like the
+// ones of the configuration
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalize(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// changedSettings returns the sorted names of the settings added, removed
+// or changed between old and new
+func changedSettings(old, new map[string]interface{}) []string {
+	var names []string
+	for name, value := range new {
+		if previous, found := old[name]; !found || !reflect.DeepEqual(previous, value) {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, found := new[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// report logs a reload and sends its event, unless no setting changed, like
+// when only comments were edited
+func (w *Watcher) report(reload *Reload) {
+	if len(reload.Applied)+len(reload.RestartRequired)+len(reload.Skipped)+len(reload.Failed) == 0 {
+		return
+	}
+	if len(reload.Applied) > 0 {
+		log.Infof("Reloaded %s from %s", strings.Join(reload.Applied, ", "), w.path)
+	}
+	if len(reload.RestartRequired) > 0 {
+		log.Warnf("%s changed in %s, restart the agent to apply the change", strings.Join(reload.RestartRequired, ", "), w.path)
+	}
+	for _, name := range sortedKeys(reload.Skipped) {
+		log.Infof("Not reloading %s: %s", name, reload.Skipped[name])
+	}
+	for _, name := range sortedKeys(reload.Failed) {
+		log.Errorf("Unable to reload %s: %s", name, reload.Failed[name])
+	}
+
+	if w.handler != nil {
+		w.handler(reloadEvent(w.path, reload))
+	}
+}
+
+// reloadEvent returns the event describing a reload
+func reloadEvent(path string, reload *Reload) metrics.Event {
+	var lines []string
+	alertType := metrics.EventAlertTypeInfo
+	if len(reload.Applied) > 0 {
+		lines = append(lines, "Applied without restart: "+strings.Join(reload.Applied, ", "))
+	}
+	if len(reload.RestartRequired) > 0 {
+		lines = append(lines, "Requires a restart of the Agent: "+strings.Join(reload.RestartRequired, ", "))
+		alertType = metrics.EventAlertTypeWarning
+	}
+	for _, name := range sortedKeys(reload.Skipped) {
+		lines = append(lines, fmt.Sprintf("Not applied: %s, %s", name, reload.Skipped[name]))
+	}
+	for _, name := range sortedKeys(reload.Failed) {
+		lines = append(lines, fmt.Sprintf("Failed to apply: %s, %s", name, reload.Failed[name]))
+		alertType = metrics.EventAlertTypeError
+	}
+
+	return metrics.Event{
+		Title:          "Datadog Agent configuration changed",
+		Text:           fmt.Sprintf("%s changed.\n%s", path, strings.Join(lines, "\n")),
+		Ts:             time.Now().Unix(),
+		AlertType:      alertType,
+		SourceTypeName: "System",
+		EventType:      "Agent Configuration",
+		AggregationKey: "agent_configuration",
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
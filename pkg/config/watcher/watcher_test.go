@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watcher
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func TestWatcherCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datadog.yaml")
+	writeConfig(t, path, `api_key: abc
+log_level: info
+tags: [env:prod]
+proxy:
+  https: http://proxy:3128
+`)
+
+	reloaded := map[string]interface{}{}
+	reloaders := map[string]Reloader{
+		"log_level": func(value interface{}) error {
+			reloaded["log_level"] = value
+			return nil
+		},
+		"tags": func(value interface{}) error {
+			reloaded["tags"] = value
+			return nil
+		},
+		"proxy": func(value interface{}) error {
+			return ErrRestartRequired
+		},
+		"site": func(value interface{}) error {
+			return fmt.Errorf("invalid site")
+		},
+	}
+	w, err := NewWatcher(path, reloaders, nil)
+	require.NoError(t, err)
+	var events []metrics.Event
+	w.SetEventHandler(func(e metrics.Event) { events = append(events, e) })
+
+	reload, err := w.Check()
+	require.NoError(t, err)
+	assert.Nil(t, reload)
+
+	writeConfig(t, path, `api_key: def
+LOG_LEVEL: debug
+tags: [env:prod]
+proxy:
+  https: http://proxy:3128
+  auth: ntlm
+site: datadoghq.eu
+`)
+	reload, err = w.Check()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log_level"}, reload.Applied)
+	assert.Equal(t, []string{"api_key", "proxy"}, reload.RestartRequired)
+	assert.Equal(t, map[string]string{"site": "invalid site"}, reload.Failed)
+	assert.Equal(t, map[string]interface{}{"log_level": "debug"}, reloaded)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, metrics.EventAlertTypeError, events[0].AlertType)
+	assert.Equal(t, path+` changed.
+Applied without restart: log_level
+Requires a restart of the Agent: api_key, proxy
+Failed to apply: site, invalid site`, events[0].Text)
+
+	// the settings removed from the file are reloaded with a nil value
+	writeConfig(t, path, `api_key: def
+LOG_LEVEL: debug
+proxy:
+  https: http://proxy:3128
+  auth: ntlm
+site: datadoghq.eu
+`)
+	reload, err = w.Check()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags"}, reload.Applied)
+	assert.Contains(t, reloaded, "tags")
+	assert.Nil(t, reloaded["tags"])
+	assert.Equal(t, metrics.EventAlertTypeInfo, events[1].AlertType)
+}
+
+func TestWatcherCheckIgnoredChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datadog.yaml")
+	writeConfig(t, path, "log_level: info\n")
+
+	calls := 0
+	w, err := NewWatcher(path, map[string]Reloader{
+		"log_level": func(value interface{}) error {
+			calls++
+			return nil
+		},
+	}, nil)
+	require.NoError(t, err)
+	var events []metrics.Event
+	w.SetEventHandler(func(e metrics.Event) { events = append(events, e) })
+
+	// an invalid file is not a change
+	writeConfig(t, path, "log_level: [info\n")
+	_, err = w.Check()
+	assert.Error(t, err)
+
+	// neither are the comments
+	writeConfig(t, path, "# verbosity\nlog_level: info\n")
+	reload, err := w.Check()
+	require.NoError(t, err)
+	assert.Empty(t, reload.Applied)
+
+	// the environment variables take precedence over the file
+	os.Setenv("DD_LOG_LEVEL", "warn")
+	defer os.Unsetenv("DD_LOG_LEVEL")
+	writeConfig(t, path, "log_level: debug\n")
+	reload, err = w.Check()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"log_level": "set in the environment"}, reload.Skipped)
+
+	assert.Equal(t, 0, calls)
+	assert.Len(t, events, 1)
+}
+
+func TestWatcherDecrypt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datadog.yaml")
+	writeConfig(t, path, "additional_endpoints:\n  https://app.datadoghq.com:\n  - ENC[key1]\n")
+
+	var origins []string
+	decrypt := func(data []byte, origin string) ([]byte, error) {
+		origins = append(origins, origin)
+		return bytes.Replace(data, []byte("ENC[key2]"), []byte("abcdef"), -1), nil
+	}
+	var endpoints interface{}
+	w, err := NewWatcher(path, map[string]Reloader{
+		"additional_endpoints": func(value interface{}) error {
+			endpoints = value
+			return nil
+		},
+	}, decrypt)
+	require.NoError(t, err)
+
+	writeConfig(t, path, "additional_endpoints:\n  https://app.datadoghq.com:\n  - ENC[key2]\n")
+	_, err = w.Check()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"https://app.datadoghq.com": []interface{}{"abcdef"}}, endpoints)
+	assert.Equal(t, []string{"datadog.yaml", "datadog.yaml"}, origins)
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package diagnosis
+
+import "fmt"
+
+// Failure is the error of a failed diagnosis telling the user how to fix it
+type Failure struct {
+	Err         error
+	Remediation string
+}
+
+func (f *Failure) Error() string {
+	return f.Err.Error()
+}
+
+// Fail returns the error of a diagnosis with the way to fix it
+func Fail(err error, remediation string) error {
+	return &Failure{Err: err, Remediation: remediation}
+}
+
+// Skipped is the error of a diagnosis that doesn't apply to the host
+type Skipped struct {
+	Reason string
+}
+
+func (s *Skipped) Error() string {
+	return s.Reason
+}
+
+// Skip returns the error of a diagnosis that doesn't apply to the host
+func Skip(format string, args ...interface{}) error {
+	return &Skipped{Reason: fmt.Sprintf(format, args...)}
+}
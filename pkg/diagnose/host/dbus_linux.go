@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package host
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const defaultSystemBusSocket = "/var/run/dbus/system_bus_socket"
+
+func init() {
+	diagnosis.Register("DBus access", diagnoseDBus)
+}
+
+// diagnoseDBus checks that the agent can connect to the system bus, used by
+// the systemd check
+func diagnoseDBus() error {
+	return diagnoseSystemBus(os.Getenv("DBUS_SYSTEM_BUS_ADDRESS"))
+}
+
+func diagnoseSystemBus(address string) error {
+	path := defaultSystemBusSocket
+	if address != "" {
+		if !strings.HasPrefix(address, "unix:path=") {
+			return diagnosis.Skip("the system bus isn't reached through a unix socket: %s", address)
+		}
+		path = strings.SplitN(strings.TrimPrefix(address, "unix:path="), ",", 2)[0]
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return diagnosis.Skip("no system bus socket at %s", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		log.Errorf("unable to connect to the system bus socket %s: %s", path, err)
+		if opErr, ok := err.(*net.OpError); ok && os.IsPermission(opErr.Err) {
+			return diagnosis.Fail(
+				fmt.Errorf("permission denied on the system bus socket %s", path),
+				"allow the user running the agent to connect to the system bus, or mount /var/run/dbus in the agent container",
+			)
+		}
+		return err
+	}
+	conn.Close()
+	log.Infof("the agent can connect to the system bus socket %s", path)
+	return nil
+}
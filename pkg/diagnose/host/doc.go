@@ -0,0 +1,9 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package host registers the diagnosis of the host features the agent
+// relies on that aren't owned by another package, like the access to the
+// system bus or the kernel prerequisites of the system-probe.
+package host
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package host
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// the system-probe needs the perf events of kernel 4.4
+	minKernelMajor = 4
+	minKernelMinor = 4
+
+	tracingPath = "/sys/kernel/debug/tracing"
+)
+
+func init() {
+	diagnosis.Register("eBPF prerequisites", diagnoseEBPF)
+}
+
+// diagnoseEBPF checks that the kernel can run the eBPF programs of the
+// system-probe, when it is enabled
+func diagnoseEBPF() error {
+	if !config.Datadog.GetBool("system_probe_config.enabled") {
+		return diagnosis.Skip("the system-probe is not enabled")
+	}
+
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return err
+	}
+	release := string(bytes.TrimRight(uname.Release[:], "\x00"))
+	if err := checkKernelRelease(release); err != nil {
+		return err
+	}
+	log.Infof("kernel %s supports eBPF", release)
+
+	if _, err := os.Stat(tracingPath); err != nil {
+		log.Errorf("unable to access %s: %s", tracingPath, err)
+		return diagnosis.Fail(
+			fmt.Errorf("%s is not available", tracingPath),
+			"mount debugfs with `mount -t debugfs none /sys/kernel/debug`, and mount /sys/kernel/debug in the system-probe container",
+		)
+	}
+	log.Infof("the kprobes can be set up with %s", tracingPath)
+	return nil
+}
+
+// checkKernelRelease checks that a kernel release, like 4.15.0-1044-aws, is
+// recent enough for the system-probe
+func checkKernelRelease(release string) error {
+	var major, minor int
+	if _, err := fmt.Sscanf(release, "%d.%d", &major, &minor); err != nil {
+		return fmt.Errorf("unable to parse the kernel release %q: %s", release, err)
+	}
+	if major < minKernelMajor || (major == minKernelMajor && minor < minKernelMinor) {
+		return diagnosis.Fail(
+			fmt.Errorf("kernel %s is older than %d.%d", release, minKernelMajor, minKernelMinor),
+			fmt.Sprintf("upgrade the kernel to %d.%d or later to run the system-probe", minKernelMajor, minKernelMinor),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package host
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+)
+
+func TestCheckKernelRelease(t *testing.T) {
+	assert.NoError(t, checkKernelRelease("4.15.0-1044-aws"))
+	assert.NoError(t, checkKernelRelease("5.0.0"))
+	assert.IsType(t, &diagnosis.Failure{}, checkKernelRelease("3.10.0-957.el7.x86_64"))
+	assert.IsType(t, &diagnosis.Failure{}, checkKernelRelease("4.1.12"))
+	assert.Error(t, checkKernelRelease("unknown"))
+}
+
+func TestDiagnoseSystemBus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "system_bus_socket")
+
+	assert.IsType(t, &diagnosis.Skipped{}, diagnoseSystemBus("unix:path="+path))
+	assert.IsType(t, &diagnosis.Skipped{}, diagnoseSystemBus("tcp:host=localhost,port=1234"))
+
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer l.Close()
+	assert.NoError(t, diagnoseSystemBus("unix:path="+path+",guid=1234"))
+}
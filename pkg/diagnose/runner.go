@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -17,8 +18,47 @@ import (
 	"github.com/fatih/color"
 )
 
+// Status of a diagnosis in a Report
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Result is the outcome of a diagnosis
+type Result struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report is the machine-readable outcome of the registered diagnosis
+type Report struct {
+	Time    time.Time `json:"time"`
+	Results []Result  `json:"results"`
+}
+
+// Failed returns the number of failed diagnosis
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failed++
+		}
+	}
+	return failed
+}
+
 // RunAll runs all registered connectivity checks, output it in writer
 func RunAll(w io.Writer) error {
+	_, err := RunAllWithReport(w)
+	return err
+}
+
+// RunAllWithReport runs all registered connectivity checks, output it in
+// writer and returns their report
+func RunAllWithReport(w io.Writer) (*Report, error) {
 	if w != color.Output {
 		color.NoColor = true
 	}
@@ -26,7 +66,7 @@ func RunAll(w io.Writer) error {
 	// Use temporarily a custom logger to our Writer
 	customLogger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(w, seelog.DebugLvl, "[%LEVEL] %FuncShort: %Msg - %Ns%n")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.RegisterAdditionalLogger("diagnose", customLogger)
 	defer log.UnregisterAdditionalLogger("diagnose")
@@ -37,15 +77,44 @@ func RunAll(w io.Writer) error {
 	}
 	sort.Strings(sortedDiagnosis)
 
+	report := &Report{Time: time.Now().UTC()}
 	for _, name := range sortedDiagnosis {
 		fmt.Fprintln(w, fmt.Sprintf("=== Running %s diagnosis ===", color.BlueString(name)))
-		err := diagnosis.DefaultCatalog[name]()
-		statusString := color.GreenString("PASS")
-		if err != nil {
-			statusString = color.RedString("FAIL")
+		result := newResult(name, diagnosis.DefaultCatalog[name]())
+		report.Results = append(report.Results, result)
+
+		switch result.Status {
+		case StatusPass:
+			fmt.Fprintln(w, fmt.Sprintf("===> %s\n", color.GreenString("PASS")))
+		case StatusSkip:
+			fmt.Fprintln(w, fmt.Sprintf("===> %s: %s\n", color.YellowString("SKIP"), result.Error))
+		default:
+			fmt.Fprintln(w, fmt.Sprintf("===> %s", color.RedString("FAIL")))
+			if result.Remediation != "" {
+				fmt.Fprintln(w, fmt.Sprintf("Remediation: %s", result.Remediation))
+			}
+			fmt.Fprintln(w)
 		}
-		fmt.Fprintln(w, fmt.Sprintf("===> %s\n", statusString))
 	}
 
-	return nil
+	return report, nil
+}
+
+// newResult returns the result of a diagnosis from its error
+func newResult(name string, err error) Result {
+	result := Result{Name: name, Status: StatusPass}
+	switch e := err.(type) {
+	case nil:
+	case *diagnosis.Skipped:
+		result.Status = StatusSkip
+		result.Error = e.Reason
+	case *diagnosis.Failure:
+		result.Status = StatusFail
+		result.Error = e.Error()
+		result.Remediation = e.Remediation
+	default:
+		result.Status = StatusFail
+		result.Error = e.Error()
+	}
+	return result
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRunAll(t *testing.T) {
@@ -27,3 +28,27 @@ func TestRunAll(t *testing.T) {
 	assert.Contains(t, result, "=== Running failing diagnosis ===\n===> FAIL")
 	assert.Contains(t, result, "=== Running succeeding diagnosis ===\n===> PASS")
 }
+
+func TestRunAllWithReport(t *testing.T) {
+	diagnosis.DefaultCatalog = make(diagnosis.Catalog)
+	diagnosis.Register("remediated", func() error {
+		return diagnosis.Fail(errors.New("permission denied"), "add the user to the group")
+	})
+	diagnosis.Register("skipped", func() error { return diagnosis.Skip("not running in %s", "docker") })
+	diagnosis.Register("succeeding", func() error { return nil })
+
+	w := &bytes.Buffer{}
+	report, err := RunAllWithReport(w)
+	require.NoError(t, err)
+
+	result := w.String()
+	assert.Contains(t, result, "=== Running remediated diagnosis ===\n===> FAIL\nRemediation: add the user to the group\n")
+	assert.Contains(t, result, "=== Running skipped diagnosis ===\n===> SKIP: not running in docker\n")
+
+	assert.Equal(t, []Result{
+		{Name: "remediated", Status: StatusFail, Error: "permission denied", Remediation: "add the user to the group"},
+		{Name: "skipped", Status: StatusSkip, Error: "not running in docker"},
+		{Name: "succeeding", Status: StatusPass},
+	}, report.Results)
+	assert.Equal(t, 1, report.Failed())
+}
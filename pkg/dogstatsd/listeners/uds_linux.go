@@ -8,18 +8,10 @@ package listeners
 import (
 	"fmt"
 	"net"
-	"strconv"
-	"time"
 
 	"golang.org/x/sys/unix"
 
-	"github.com/DataDog/datadog-agent/pkg/util/cache"
-	"github.com/DataDog/datadog-agent/pkg/util/containers"
-)
-
-const (
-	pidToEntityCacheKeyPrefix = "pid_to_entity"
-	pidToEntityCacheDuration  = time.Minute
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/origin"
 )
 
 // getUDSAncillarySize gets the needed buffer size to retrieve the ancillary data
@@ -64,34 +56,9 @@ func processUDSOrigin(ancillary []byte) (string, error) {
 			"probably to another namespace. Is the agent in host PID mode?")
 	}
 
-	entity, err := getEntityForPID(cred.Pid)
+	entity, err := origin.EntityForPID(cred.Pid)
 	if err != nil {
 		return NoOrigin, err
 	}
 	return entity, nil
 }
-
-// getEntityForPID returns the container entity name and caches the value for future lookups
-// As the result is cached and the lookup is really fast (parsing local files), it can be
-// called from the intake goroutine.
-func getEntityForPID(pid int32) (string, error) {
-	key := cache.BuildAgentKey(pidToEntityCacheKeyPrefix, strconv.Itoa(int(pid)))
-	if x, found := cache.Cache.Get(key); found {
-		return x.(string), nil
-	}
-
-	entity, err := containers.EntityForPID(pid)
-	switch err {
-	case nil:
-		// No error, yay!
-		cache.Cache.Set(key, entity, pidToEntityCacheDuration)
-		return entity, nil
-	case containers.ErrNoRuntimeMatch, containers.ErrNoContainerMatch:
-		// No runtime detected, cache the `NoOrigin` result
-		cache.Cache.Set(key, NoOrigin, pidToEntityCacheDuration)
-		return NoOrigin, nil
-	default:
-		// Other lookup error, retry next time
-		return NoOrigin, err
-	}
-}
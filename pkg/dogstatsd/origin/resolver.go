@@ -0,0 +1,230 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package origin maps the origin of the dogstatsd packets to tagger entities:
+the PID of the clients sending them over UDS, or the container ID they send in
+the `c:` field.
+
+The mappings are cached until the container exits, which the resolver learns
+by following the entities of the tagger, so that a PID reused by another
+container doesn't get the tags of the previous one.
+*/
+package origin
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// NoOrigin is returned when the origin of a packet couldn't be mapped to an
+// entity
+const NoOrigin = ""
+
+// pidToEntityCacheDuration is how long a PID is mapped to an entity, unless
+// the container exits before
+const pidToEntityCacheDuration = time.Minute
+
+// For testing purpose
+var (
+	subscribe   = tagger.Subscribe
+	unsubscribe = tagger.Unsubscribe
+	timeNow     = time.Now
+)
+
+// containerRuntimes are the entity prefixes of the containers in the tagger
+var containerRuntimes = map[string]struct{}{
+	containers.RuntimeNameDocker:     {},
+	containers.RuntimeNameContainerd: {},
+	containers.RuntimeNameCRIO:       {},
+}
+
+type pidCacheEntry struct {
+	entity         string
+	expirationDate time.Time
+}
+
+// Resolver maps the origins of the packets to tagger entities
+type Resolver struct {
+	m sync.RWMutex
+	// pids maps the PIDs of the clients to their entity, NoOrigin if they
+	// don't run in a container
+	pids map[int32]pidCacheEntry
+	// containerEntities maps the container IDs to their entity
+	containerEntities map[string]string
+
+	running bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+var defaultResolver = newResolver()
+
+func newResolver() *Resolver {
+	return &Resolver{
+		pids:              make(map[int32]pidCacheEntry),
+		containerEntities: make(map[string]string),
+	}
+}
+
+// Start follows the entities of the tagger to map the container IDs and to
+// invalidate the mappings of the containers that exit
+func Start() {
+	defaultResolver.start()
+}
+
+// Stop stops following the entities of the tagger
+func Stop() {
+	defaultResolver.stopFollowing()
+}
+
+// EntityForPID returns the entity of the container running a PID, or
+// NoOrigin if it doesn't run in a container
+func EntityForPID(pid int32) (string, error) {
+	return defaultResolver.entityForPID(pid)
+}
+
+// EntityForContainerID returns the entity of a container, or NoOrigin if the
+// tagger doesn't know it
+func EntityForContainerID(containerID string) string {
+	return defaultResolver.entityForContainerID(containerID)
+}
+
+func (r *Resolver) start() {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.running {
+		return
+	}
+	r.running = true
+	r.stop = make(chan struct{})
+	r.stopped = make(chan struct{})
+	go r.run(r.stop, r.stopped)
+}
+
+func (r *Resolver) stopFollowing() {
+	r.m.Lock()
+	if !r.running {
+		r.m.Unlock()
+		return
+	}
+	r.running = false
+	close(r.stop)
+	stopped := r.stopped
+	r.m.Unlock()
+
+	<-stopped
+}
+
+// run follows the entities of the tagger, subscribing again when it falls
+// behind, until stopped
+func (r *Resolver) run(stop, stopped chan struct{}) {
+	defer close(stopped)
+
+	for {
+		ch := subscribe(collectors.LowCardinality)
+		// the events missed while falling behind are replaced by the
+		// snapshot sent to the new subscription
+		r.reset()
+		if !r.follow(ch, stop) {
+			unsubscribe(ch)
+			return
+		}
+		log.Debugf("dogstatsd origin resolver fell behind the tagger, subscribing again")
+	}
+}
+
+// follow processes the events of a subscription, it returns false when the
+// resolver is stopped and true when the subscription was dropped
+func (r *Resolver) follow(ch chan []tagger.EntityEvent, stop chan struct{}) bool {
+	for {
+		select {
+		case events, ok := <-ch:
+			if !ok {
+				return true
+			}
+			r.processEvents(events)
+		case <-stop:
+			return false
+		}
+	}
+}
+
+func (r *Resolver) reset() {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.pids = make(map[int32]pidCacheEntry)
+	r.containerEntities = make(map[string]string)
+}
+
+func (r *Resolver) processEvents(events []tagger.EntityEvent) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	for _, event := range events {
+		runtime, containerID := containers.SplitEntityName(event.Entity)
+		if _, isContainer := containerRuntimes[runtime]; !isContainer {
+			continue
+		}
+
+		switch event.EventType {
+		case tagger.EventTypeAdded, tagger.EventTypeModified:
+			r.containerEntities[containerID] = event.Entity
+		case tagger.EventTypeDeleted:
+			delete(r.containerEntities, containerID)
+			for pid, entry := range r.pids {
+				if entry.entity == event.Entity {
+					delete(r.pids, pid)
+				}
+			}
+		}
+	}
+}
+
+func (r *Resolver) entityForContainerID(containerID string) string {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return r.containerEntities[containerID]
+}
+
+// entityForPID returns the cached entity of a PID, or looks it up. As the
+// result is cached and the lookup is really fast (parsing local files), it
+// can be called from the intake goroutine.
+func (r *Resolver) entityForPID(pid int32) (string, error) {
+	r.m.RLock()
+	entry, found := r.pids[pid]
+	r.m.RUnlock()
+	if found && timeNow().Before(entry.expirationDate) {
+		return entry.entity, nil
+	}
+
+	entity, err := entityForPID(pid)
+	switch err {
+	case nil:
+	case containers.ErrNoRuntimeMatch, containers.ErrNoContainerMatch:
+		// No runtime detected, cache the `NoOrigin` result
+		entity = NoOrigin
+	default:
+		// Other lookup error, retry next time
+		return NoOrigin, err
+	}
+
+	r.m.Lock()
+	r.pids[pid] = pidCacheEntry{
+		entity:         entity,
+		expirationDate: timeNow().Add(pidToEntityCacheDuration),
+	}
+	r.m.Unlock()
+
+	return entity, nil
+}
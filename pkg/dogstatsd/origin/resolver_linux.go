@@ -0,0 +1,13 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build linux
+
+package origin
+
+import "github.com/DataDog/datadog-agent/pkg/util/containers"
+
+// For testing purpose
+var entityForPID = containers.EntityForPID
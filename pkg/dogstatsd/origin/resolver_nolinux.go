@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build !linux
+
+package origin
+
+import "errors"
+
+// For testing purpose
+var entityForPID = func(pid int32) (string, error) {
+	return "", errors.New("PID origin detection is only supported on Linux")
+}
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package origin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func TestEntityForPID(t *testing.T) {
+	lookups := 0
+	entityForPID = func(pid int32) (string, error) {
+		lookups++
+		switch pid {
+		case 1:
+			return "docker://abc123", nil
+		case 2:
+			return "", containers.ErrNoContainerMatch
+		}
+		return "", errors.New("lookup error")
+	}
+	defer func() { timeNow = time.Now }()
+	r := newResolver()
+
+	entity, err := r.entityForPID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "docker://abc123", entity)
+	entity, err = r.entityForPID(2)
+	assert.NoError(t, err)
+	assert.Equal(t, NoOrigin, entity)
+	assert.Equal(t, 2, lookups)
+
+	// the results are cached, including the PIDs not in a container
+	r.entityForPID(1)
+	r.entityForPID(2)
+	assert.Equal(t, 2, lookups)
+
+	// the errors are not cached
+	_, err = r.entityForPID(3)
+	assert.Error(t, err)
+	_, err = r.entityForPID(3)
+	assert.Error(t, err)
+	assert.Equal(t, 4, lookups)
+
+	// the results expire
+	timeNow = func() time.Time { return time.Now().Add(2 * pidToEntityCacheDuration) }
+	r.entityForPID(1)
+	assert.Equal(t, 5, lookups)
+}
+
+func TestProcessEvents(t *testing.T) {
+	lookups := 0
+	entityForPID = func(pid int32) (string, error) {
+		lookups++
+		return "docker://abc123", nil
+	}
+	r := newResolver()
+
+	r.processEvents([]tagger.EntityEvent{
+		{EventType: tagger.EventTypeAdded, Entity: "docker://abc123"},
+		{EventType: tagger.EventTypeAdded, Entity: "containerd://def456"},
+		{EventType: tagger.EventTypeAdded, Entity: "kubernetes_pod://e2ee6d87-7b81-11e9-9bee-42010a840045"},
+	})
+	assert.Equal(t, "docker://abc123", r.entityForContainerID("abc123"))
+	assert.Equal(t, "containerd://def456", r.entityForContainerID("def456"))
+	assert.Equal(t, NoOrigin, r.entityForContainerID("e2ee6d87-7b81-11e9-9bee-42010a840045"))
+
+	r.entityForPID(1)
+	r.entityForPID(1)
+	assert.Equal(t, 1, lookups)
+
+	// the mappings of the containers that exit are invalidated
+	r.processEvents([]tagger.EntityEvent{{EventType: tagger.EventTypeDeleted, Entity: "docker://abc123"}})
+	assert.Equal(t, NoOrigin, r.entityForContainerID("abc123"))
+	assert.Equal(t, "containerd://def456", r.entityForContainerID("def456"))
+	r.entityForPID(1)
+	assert.Equal(t, 2, lookups)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for i := 0; i < 1000; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the condition")
+}
+
+func TestFollowTagger(t *testing.T) {
+	subscriptions := make(chan chan []tagger.EntityEvent, 2)
+	unsubscribed := make(chan bool, 1)
+	subscribe = func(cardinality collectors.TagCardinality) chan []tagger.EntityEvent {
+		ch := make(chan []tagger.EntityEvent, 1)
+		subscriptions <- ch
+		return ch
+	}
+	unsubscribe = func(ch chan []tagger.EntityEvent) {
+		unsubscribed <- true
+	}
+	defer func() {
+		subscribe = tagger.Subscribe
+		unsubscribe = tagger.Unsubscribe
+	}()
+	r := newResolver()
+	r.start()
+
+	ch := <-subscriptions
+	ch <- []tagger.EntityEvent{{EventType: tagger.EventTypeAdded, Entity: "docker://abc123"}}
+	waitFor(t, func() bool { return r.entityForContainerID("abc123") != NoOrigin })
+
+	// the resolver subscribes again when it falls behind, and starts from
+	// the new snapshot
+	close(ch)
+	ch = <-subscriptions
+	ch <- []tagger.EntityEvent{{EventType: tagger.EventTypeAdded, Entity: "docker://def456"}}
+	waitFor(t, func() bool { return r.entityForContainerID("def456") != NoOrigin })
+	assert.Equal(t, NoOrigin, r.entityForContainerID("abc123"))
+
+	r.stopFollowing()
+	assert.True(t, <-unsubscribed)
+}
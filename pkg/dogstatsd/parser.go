@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/origin"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
@@ -33,14 +34,17 @@ var (
 		"ms": metrics.HistogramType,
 		"d":  metrics.DistributionType,
 	}
-	tagSeparator                      = []byte(",")
-	fieldSeparator                    = []byte("|")
-	valueSeparator                    = []byte(":")
-	hostTagPrefix                     = []byte("host:")
-	entityIDTagPrefix                 = []byte("dd.internal.entity_id:")
-	lenHostTagPrefix                  = len(hostTagPrefix)
-	lenEntityIDTagPrefix              = len(entityIDTagPrefix)
-	getTags              tagRetriever = tagger.Tag
+	tagSeparator                           = []byte(",")
+	fieldSeparator                         = []byte("|")
+	valueSeparator                         = []byte(":")
+	hostTagPrefix                          = []byte("host:")
+	entityIDTagPrefix                      = []byte("dd.internal.entity_id:")
+	containerIDFieldPrefix                 = []byte("c:")
+	lenHostTagPrefix                       = len(hostTagPrefix)
+	lenEntityIDTagPrefix                   = len(entityIDTagPrefix)
+	lenContainerIDFieldPrefix              = len(containerIDFieldPrefix)
+	getTags                   tagRetriever = tagger.Tag
+	entityForContainerID                   = origin.EntityForContainerID
 )
 
 func nextMessage(packet *[]byte) (message []byte) {
@@ -105,6 +109,22 @@ func parseTags(rawTags []byte, defaultHostname string) ([]string, string) {
 	return tagsList, host
 }
 
+// parseContainerIDField returns the tags of the container whose ID is sent
+// in the `c:` field, to identify the origin of the clients that can't use UDS
+func parseContainerIDField(rawContainerID []byte) []string {
+	entity := entityForContainerID(string(rawContainerID))
+	if entity == origin.NoOrigin {
+		log.Tracef("Unknown container ID %s", rawContainerID)
+		return nil
+	}
+	containerTags, err := getTags(entity, tagger.DogstatsdCardinality)
+	if err != nil {
+		log.Tracef("Cannot get tags for entity %s: %s", entity, err)
+		return nil
+	}
+	return containerTags
+}
+
 func parseServiceCheckMessage(message []byte, defaultHostname string) (*metrics.ServiceCheck, error) {
 	// _sc|name|status|[metadata|...]
 
@@ -135,6 +155,7 @@ func parseServiceCheckMessage(message []byte, defaultHostname string) (*metrics.
 	// tag and finally the defaultHostname value
 	var hostFromField string
 	hostFromTags := defaultHostname
+	var containerTags []string
 
 	// Metadata
 	for {
@@ -157,6 +178,8 @@ func parseServiceCheckMessage(message []byte, defaultHostname string) (*metrics.
 			service.Tags, hostFromTags = parseTags(rawMetadataField[1:], defaultHostname)
 		} else if bytes.HasPrefix(rawMetadataField, []byte("m:")) {
 			service.Message = string(rawMetadataField[2:])
+		} else if bytes.HasPrefix(rawMetadataField, containerIDFieldPrefix) {
+			containerTags = parseContainerIDField(rawMetadataField[lenContainerIDFieldPrefix:])
 		} else {
 			log.Warnf("unknown metadata type: '%s'", rawMetadataField)
 		}
@@ -167,6 +190,7 @@ func parseServiceCheckMessage(message []byte, defaultHostname string) (*metrics.
 	} else {
 		service.Host = hostFromTags
 	}
+	service.Tags = append(service.Tags, containerTags...)
 	return &service, nil
 }
 
@@ -225,6 +249,7 @@ func parseEventMessage(message []byte, defaultHostname string) (*metrics.Event,
 	// tag and finally the defaultHostname value
 	var hostFromField string
 	hostFromTags := defaultHostname
+	var containerTags []string
 
 	// Metadata
 	if len(message) > 1 {
@@ -260,6 +285,8 @@ func parseEventMessage(message []byte, defaultHostname string) (*metrics.Event,
 				event.SourceTypeName = string(rawMetadataFields[i][2:])
 			} else if bytes.HasPrefix(rawMetadataFields[i], []byte("#")) {
 				event.Tags, hostFromTags = parseTags(rawMetadataFields[i][1:], defaultHostname)
+			} else if bytes.HasPrefix(rawMetadataFields[i], containerIDFieldPrefix) {
+				containerTags = parseContainerIDField(rawMetadataFields[i][lenContainerIDFieldPrefix:])
 			} else {
 				log.Warnf("unknown metadata type: '%s'", rawMetadataFields[i])
 			}
@@ -271,15 +298,17 @@ func parseEventMessage(message []byte, defaultHostname string) (*metrics.Event,
 	} else {
 		event.Host = hostFromTags
 	}
+	event.Tags = append(event.Tags, containerTags...)
 	return &event, nil
 }
 
 func parseMetricMessage(message []byte, namespace string, namespaceBlacklist []string, defaultHostname string) (*metrics.MetricSample, error) {
 	// daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2
 	// daemon:666|g|@0.1|#sometag:somevalue"
+	// daemon:666|g|#sometag:somevalue|c:container_id
 
 	separatorCount := bytes.Count(message, fieldSeparator)
-	if separatorCount < 1 || separatorCount > 3 {
+	if separatorCount < 1 || separatorCount > 4 {
 		return nil, fmt.Errorf("invalid field number for %q", message)
 	}
 
@@ -299,6 +328,7 @@ func parseMetricMessage(message []byte, namespace string, namespaceBlacklist []s
 	var metricTags []string
 	host := defaultHostname
 	var rawMetadataField []byte
+	var containerTags []string
 	sampleRate := 1.0
 
 	for {
@@ -313,6 +343,8 @@ func parseMetricMessage(message []byte, namespace string, namespaceBlacklist []s
 			if err != nil {
 				return nil, fmt.Errorf("invalid sample value for %q", message)
 			}
+		} else if bytes.HasPrefix(rawMetadataField, containerIDFieldPrefix) {
+			containerTags = parseContainerIDField(rawMetadataField[lenContainerIDFieldPrefix:])
 		}
 
 		if remainder == nil {
@@ -333,6 +365,8 @@ func parseMetricMessage(message []byte, namespace string, namespaceBlacklist []s
 		}
 	}
 
+	metricTags = append(metricTags, containerTags...)
+
 	metricType, ok := metricTypes[string(rawType)]
 	if !ok {
 		return nil, fmt.Errorf("invalid metric type for %q", message)
@@ -12,7 +12,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/origin"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
@@ -803,3 +805,39 @@ func TestEntityOriginDetectionTagsError(t *testing.T) {
 	assert.Equal(t, "my-hostname", parsed.Host)
 	assert.InEpsilon(t, 1.0, parsed.SampleRate, epsilon)
 }
+
+func TestContainerIDField(t *testing.T) {
+	getTags = func(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+		if entity == "docker://abc123" {
+			return []string{"image_name:redis"}, nil
+		}
+		return []string{}, nil
+	}
+	entityForContainerID = func(containerID string) string {
+		if containerID == "abc123" {
+			return "docker://abc123"
+		}
+		return ""
+	}
+	defer func() {
+		getTags = tagger.Tag
+		entityForContainerID = origin.EntityForContainerID
+	}()
+
+	parsed, err := parseMetricMessage([]byte("daemon:666|g|@0.5|c:abc123|#sometag1:somevalue1"), "", nil, "default-hostname")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sometag1:somevalue1", "image_name:redis"}, parsed.Tags)
+	assert.InEpsilon(t, 0.5, parsed.SampleRate, epsilon)
+
+	parsed, err = parseMetricMessage([]byte("daemon:666|g|c:unknown"), "", nil, "default-hostname")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Tags)
+
+	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|c:abc123|#tag1"), "default-hostname")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tag1", "image_name:redis"}, sc.Tags)
+
+	e, err := parseEventMessage([]byte("_e{10,9}:test title|test text|c:abc123"), "default-hostname")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image_name:redis"}, e.Tags)
+}
@@ -15,15 +15,18 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/origin"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
 )
 
@@ -35,7 +38,19 @@ var (
 	dogstatsdEventPackets            = expvar.Int{}
 	dogstatsdMetricParseErrors       = expvar.Int{}
 	dogstatsdMetricPackets           = expvar.Int{}
+	dogstatsdMetricBlocked           = expvar.Int{}
 	dogstatsdPacketsLastSec          = expvar.Int{}
+
+	tlmProcessed = telemetry.NewCounter("dogstatsd", "processed", []string{"message_type", "state"}, "Number of messages processed by dogstatsd, by type and state (ok, error or blocked).")
+	// the counters of the messages are resolved once, they're incremented
+	// for every message
+	tlmServiceChecksOK    = tlmProcessed.WithValues("service_checks", "ok")
+	tlmServiceChecksError = tlmProcessed.WithValues("service_checks", "error")
+	tlmEventsOK           = tlmProcessed.WithValues("events", "ok")
+	tlmEventsError        = tlmProcessed.WithValues("events", "error")
+	tlmMetricsOK          = tlmProcessed.WithValues("metrics", "ok")
+	tlmMetricsError       = tlmProcessed.WithValues("metrics", "error")
+	tlmMetricsBlocked     = tlmProcessed.WithValues("metrics", "blocked")
 )
 
 func init() {
@@ -45,6 +60,7 @@ func init() {
 	dogstatsdExpvars.Set("EventPackets", &dogstatsdEventPackets)
 	dogstatsdExpvars.Set("MetricParseErrors", &dogstatsdMetricParseErrors)
 	dogstatsdExpvars.Set("MetricPackets", &dogstatsdMetricPackets)
+	dogstatsdExpvars.Set("MetricBlocked", &dogstatsdMetricBlocked)
 }
 
 // Server represent a Dogstatsd server
@@ -58,11 +74,12 @@ type Server struct {
 	health                *health.Handle
 	metricPrefix          string
 	metricPrefixBlacklist []string
+	metricBlocklist       atomic.Value // map[string]struct{} of the metric names to drop
 	defaultHostname       string
 	histToDist            bool
 	histToDistPrefix      string
 	extraTags             []string
-	debugMetricsStats     bool
+	debugMetricsStats     uint64 // accessed atomically, 1 when the metrics stats are stored
 	metricsStats          map[string]metricStat
 	statsLock             sync.Mutex
 }
@@ -87,10 +104,10 @@ func NewServer(metricOut chan<- []*metrics.MetricSample, eventOut chan<- []*metr
 		dogstatsdExpvars.Set("PacketsLastSecond", &dogstatsdPacketsLastSec)
 	}
 
-	var metricsStats bool
+	var metricsStats uint64
 	if config.Datadog.GetBool("dogstatsd_metrics_stats_enable") == true {
 		log.Info("Dogstatsd: metrics statistics will be stored.")
-		metricsStats = true
+		metricsStats = 1
 	}
 
 	packetsChannel := make(chan listeners.Packets, config.Datadog.GetInt("dogstatsd_queue_size"))
@@ -154,6 +171,8 @@ func NewServer(metricOut chan<- []*metrics.MetricSample, eventOut chan<- []*metr
 		metricsStats:          make(map[string]metricStat),
 	}
 
+	s.SetMetricBlocklist(config.Datadog.GetStringSlice("statsd_metric_blocklist"))
+
 	forwardHost := config.Datadog.GetString("statsd_forward_host")
 	forwardPort := config.Datadog.GetInt("statsd_forward_port")
 
@@ -171,6 +190,9 @@ func NewServer(metricOut chan<- []*metrics.MetricSample, eventOut chan<- []*metr
 		}
 	}
 
+	// map the origin of the packets to the containers known by the tagger
+	origin.Start()
+
 	s.handleMessages(metricOut, eventOut, serviceCheckOut)
 
 	return s, nil
@@ -272,39 +294,50 @@ func (s *Server) parsePacket(packet *listeners.Packet, metricSamples []*metrics.
 			if err != nil {
 				log.Errorf("Dogstatsd: error parsing service check: %s", err)
 				dogstatsdServiceCheckParseErrors.Add(1)
+				tlmServiceChecksError.Inc()
 				continue
 			}
 			if len(extraTags) > 0 {
 				serviceCheck.Tags = append(serviceCheck.Tags, extraTags...)
 			}
 			dogstatsdServiceCheckPackets.Add(1)
+			tlmServiceChecksOK.Inc()
 			serviceChecks = append(serviceChecks, serviceCheck)
 		} else if bytes.HasPrefix(message, []byte("_e")) {
 			event, err := parseEventMessage(message, s.defaultHostname)
 			if err != nil {
 				log.Errorf("Dogstatsd: error parsing event: %s", err)
 				dogstatsdEventParseErrors.Add(1)
+				tlmEventsError.Inc()
 				continue
 			}
 			if len(extraTags) > 0 {
 				event.Tags = append(event.Tags, extraTags...)
 			}
 			dogstatsdEventPackets.Add(1)
+			tlmEventsOK.Inc()
 			events = append(events, event)
 		} else {
 			sample, err := parseMetricMessage(message, s.metricPrefix, s.metricPrefixBlacklist, s.defaultHostname)
 			if err != nil {
 				log.Errorf("Dogstatsd: error parsing metrics: %s", err)
 				dogstatsdMetricParseErrors.Add(1)
+				tlmMetricsError.Inc()
 				continue
 			}
-			if s.debugMetricsStats {
+			if s.isBlocked(sample.Name) {
+				dogstatsdMetricBlocked.Add(1)
+				tlmMetricsBlocked.Inc()
+				continue
+			}
+			if atomic.LoadUint64(&s.debugMetricsStats) == 1 {
 				s.storeMetricStats(sample.Name)
 			}
 			if len(extraTags) > 0 {
 				sample.Tags = append(sample.Tags, extraTags...)
 			}
 			dogstatsdMetricPackets.Add(1)
+			tlmMetricsOK.Inc()
 			metricSamples = append(metricSamples, sample)
 			if s.histToDist && sample.Mtype == metrics.HistogramType {
 				distSample := sample.Copy()
@@ -323,6 +356,7 @@ func (s *Server) Stop() {
 	for _, l := range s.listeners {
 		l.Stop()
 	}
+	origin.Stop()
 	if s.Statistics != nil {
 		s.Statistics.Stop()
 	}
@@ -340,6 +374,53 @@ func (s *Server) storeMetricStats(name string) {
 	s.metricsStats[name] = ms
 }
 
+// EnableMetricsStats enables or disables the storage of the metrics
+// statistics at runtime. They are cleared when disabled.
+func (s *Server) EnableMetricsStats(enabled bool) {
+	if enabled {
+		atomic.StoreUint64(&s.debugMetricsStats, 1)
+		log.Info("Dogstatsd: metrics statistics will be stored.")
+		return
+	}
+
+	atomic.StoreUint64(&s.debugMetricsStats, 0)
+	s.statsLock.Lock()
+	s.metricsStats = make(map[string]metricStat)
+	s.statsLock.Unlock()
+	log.Info("Dogstatsd: metrics statistics are no longer stored.")
+}
+
+// MetricsStatsEnabled returns true if the metrics statistics are stored
+func (s *Server) MetricsStatsEnabled() bool {
+	return atomic.LoadUint64(&s.debugMetricsStats) == 1
+}
+
+// SetMetricBlocklist replaces the names of the metrics dropped by the server
+func (s *Server) SetMetricBlocklist(names []string) {
+	blocklist := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		blocklist[name] = struct{}{}
+	}
+	s.metricBlocklist.Store(blocklist)
+}
+
+// MetricBlocklist returns the sorted names of the metrics dropped by the server
+func (s *Server) MetricBlocklist() []string {
+	blocklist, _ := s.metricBlocklist.Load().(map[string]struct{})
+	names := make([]string, 0, len(blocklist))
+	for name := range blocklist {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Server) isBlocked(name string) bool {
+	blocklist, _ := s.metricBlocklist.Load().(map[string]struct{})
+	_, blocked := blocklist[name]
+	return blocked
+}
+
 // GetJSONDebugStats returns jsonified debug statistics.
 func (s *Server) GetJSONDebugStats() ([]byte, error) {
 	s.statsLock.Lock()
@@ -390,3 +390,44 @@ func TestDebugStats(t *testing.T) {
 	require.Equal(t, metric2.Count, uint64(1))
 	require.Equal(t, metric3.Count, uint64(1))
 }
+
+func TestMetricBlocklist(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	config.Datadog.SetDefault("statsd_metric_blocklist", []string{"blocked"})
+	defer config.Datadog.SetDefault("statsd_metric_blocklist", []string{})
+
+	metricOut := make(chan []*metrics.MetricSample)
+	eventOut := make(chan []*metrics.Event)
+	serviceOut := make(chan []*metrics.ServiceCheck)
+	s, err := NewServer(metricOut, eventOut, serviceOut)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+	assert.Equal(t, []string{"blocked"}, s.MetricBlocklist())
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	conn.Write([]byte("blocked:666|g\ndaemon:666|g"))
+	select {
+	case res := <-metricOut:
+		require.Equal(t, 1, len(res))
+		assert.Equal(t, "daemon", res[0].Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+
+	s.SetMetricBlocklist([]string{"daemon", "other"})
+	assert.Equal(t, []string{"daemon", "other"}, s.MetricBlocklist())
+	conn.Write([]byte("blocked:666|g\ndaemon:666|g"))
+	select {
+	case res := <-metricOut:
+		require.Equal(t, 1, len(res))
+		assert.Equal(t, "blocked", res[0].Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
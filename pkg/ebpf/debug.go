@@ -0,0 +1,88 @@
+package ebpf
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxTracerErrors is the number of errors of the tracer kept for debugging
+const maxTracerErrors = 50
+
+// EBPFInfo lists the eBPF programs and maps loaded by the tracer
+type EBPFInfo struct {
+	KProbes []KProbeInfo `json:"kprobes"`
+	Maps    []MapInfo    `json:"maps"`
+}
+
+// KProbeInfo describes a kernel probe of the eBPF module
+type KProbeInfo struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// MapInfo describes a map of the eBPF module
+type MapInfo struct {
+	Name string `json:"name"`
+	FD   int    `json:"fd"`
+}
+
+// GuessedOffsets holds the offsets of the fields of the kernel structs,
+// found by the offset guessing when the tracer started
+type GuessedOffsets struct {
+	Ready       bool   `json:"ready"`
+	SAddr       uint64 `json:"saddr"`
+	DAddr       uint64 `json:"daddr"`
+	SPort       uint64 `json:"sport"`
+	DPort       uint64 `json:"dport"`
+	Netns       uint64 `json:"netns"`
+	Ino         uint64 `json:"ino"`
+	Family      uint64 `json:"family"`
+	DAddrIPv6   uint64 `json:"daddr_ipv6"`
+	IPv6Enabled bool   `json:"ipv6_enabled"`
+}
+
+// TracerError is an error encountered by the tracer
+type TracerError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// errorBuffer keeps the last errors encountered by the tracer
+type errorBuffer struct {
+	sync.Mutex
+	errors []TracerError
+	size   int
+}
+
+func newErrorBuffer(size int) *errorBuffer {
+	return &errorBuffer{
+		errors: make([]TracerError, 0, size),
+		size:   size,
+	}
+}
+
+// add records an error, dropping the oldest one if the buffer is full
+func (b *errorBuffer) add(format string, args ...interface{}) {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.errors) == b.size {
+		copy(b.errors, b.errors[1:])
+		b.errors = b.errors[:b.size-1]
+	}
+	b.errors = append(b.errors, TracerError{
+		Time:    time.Now(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// get returns a copy of the errors, the oldest first
+func (b *errorBuffer) get() []TracerError {
+	b.Lock()
+	defer b.Unlock()
+
+	errors := make([]TracerError, len(b.errors))
+	copy(errors, b.errors)
+	return errors
+}
@@ -0,0 +1,27 @@
+package ebpf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorBuffer(t *testing.T) {
+	b := newErrorBuffer(3)
+	assert.Empty(t, b.get())
+
+	for i := 0; i < 5; i++ {
+		b.add("error %d", i)
+	}
+
+	errors := b.get()
+	assert.Len(t, errors, 3)
+	for i, err := range errors {
+		assert.Equal(t, fmt.Sprintf("error %d", i+2), err.Message)
+	}
+
+	// the returned errors are a copy
+	errors[0].Message = "changed"
+	assert.Equal(t, "error 2", b.get()[0].Message)
+}
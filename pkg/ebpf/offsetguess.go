@@ -438,3 +438,25 @@ func guess(m *elf.Module, cfg *Config) error {
 	}
 	return nil
 }
+
+// readGuessedOffsets returns the offsets stored in the tracer_status map by the guessing
+func readGuessedOffsets(m *elf.Module) (GuessedOffsets, error) {
+	mp := m.Map(string(tracerStatusMap))
+	status := &tracerStatus{}
+	if err := m.LookupElement(mp, unsafe.Pointer(&zero), unsafe.Pointer(status)); err != nil {
+		return GuessedOffsets{}, fmt.Errorf("error reading tracer_status map: %v", err)
+	}
+
+	return GuessedOffsets{
+		Ready:       status.state == stateReady,
+		SAddr:       uint64(status.offset_saddr),
+		DAddr:       uint64(status.offset_daddr),
+		SPort:       uint64(status.offset_sport),
+		DPort:       uint64(status.offset_dport),
+		Netns:       uint64(status.offset_netns),
+		Ino:         uint64(status.offset_ino),
+		Family:      uint64(status.offset_family),
+		DAddrIPv6:   uint64(status.offset_daddr_ipv6),
+		IPv6Enabled: status.ipv6_enabled == enableV6,
+	}, nil
+}
@@ -0,0 +1,4 @@
+// Package oomkill detects the processes killed by the OOM killer from the
+// kernel log. The system-probe serves the kills to the oom_kill check of the
+// agent.
+package oomkill
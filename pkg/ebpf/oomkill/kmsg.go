@@ -0,0 +1,94 @@
+// +build linux
+
+package oomkill
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kmsgPath = "/dev/kmsg"
+	// a record of /dev/kmsg can't be larger
+	kmsgRecordMaxLen = 8192
+	// the oldest kills are dropped when the agent doesn't collect them
+	maxPendingKills = 100
+)
+
+// Watcher reads the kills of the OOM killer from /dev/kmsg as they're logged
+type Watcher struct {
+	file *os.File
+
+	m     sync.Mutex
+	kills []OOMKill
+}
+
+// NewWatcher starts watching the kills logged from now on. Reading
+// /dev/kmsg requires CAP_SYSLOG when kernel.dmesg_restrict is set.
+func NewWatcher() (*Watcher, error) {
+	f, err := os.Open(kmsgPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	w := &Watcher{file: f}
+	go w.run()
+	return w, nil
+}
+
+func (w *Watcher) run() {
+	p := &parser{}
+	buf := make([]byte, kmsgRecordMaxLen)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			// EPIPE means that records were overwritten before they were read
+			if pe, ok := err.(*os.PathError); ok && pe.Err == syscall.EPIPE {
+				continue
+			}
+			log.Debugf("stopped reading %s: %s", kmsgPath, err)
+			return
+		}
+
+		kill, ok := p.parseRecord(string(buf[:n]))
+		if !ok {
+			continue
+		}
+		kill.Timestamp = time.Now().Unix()
+		log.Debugf("process %d (%s) was killed by the OOM killer", kill.Pid, kill.Comm)
+
+		w.m.Lock()
+		if len(w.kills) >= maxPendingKills {
+			w.kills = w.kills[1:]
+		}
+		w.kills = append(w.kills, kill)
+		w.m.Unlock()
+	}
+}
+
+// GetOOMKills returns the kills since the previous call
+func (w *Watcher) GetOOMKills() []OOMKill {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	kills := w.kills
+	w.kills = nil
+	if kills == nil {
+		kills = []OOMKill{}
+	}
+	return kills
+}
+
+// Close stops watching the kills
+func (w *Watcher) Close() {
+	w.file.Close()
+}
@@ -0,0 +1,21 @@
+// +build !linux
+
+package oomkill
+
+import "errors"
+
+// Watcher is only implemented on linux
+type Watcher struct{}
+
+// NewWatcher is only implemented on linux
+func NewWatcher() (*Watcher, error) {
+	return nil, errors.New("the OOM kills are only watched on linux")
+}
+
+// GetOOMKills is only implemented on linux
+func (w *Watcher) GetOOMKills() []OOMKill {
+	return nil
+}
+
+// Close is only implemented on linux
+func (w *Watcher) Close() {}
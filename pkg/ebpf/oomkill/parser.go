@@ -0,0 +1,104 @@
+package oomkill
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The OOM killer logs a report of a few dozen lines per kill, the lines
+// below hold the process and its cgroup. Their format depends on the kernel
+// version: the oom-kill summary line appeared in 4.19, and the memory cgroup
+// of the process was only logged for the cgroup kills before.
+var (
+	invokedRe     = regexp.MustCompile(`^\S+ invoked oom-killer:`)
+	taskInRe      = regexp.MustCompile(`^Task in (\S+) killed as a result of limit of \S+`)
+	memoryRe      = regexp.MustCompile(`^memory: usage (\d+)kB, limit (\d+)kB`)
+	killedRe      = regexp.MustCompile(`Killed process (\d+) \((.*)\) total-vm:(\d+)kB, anon-rss:(\d+)kB, file-rss:(\d+)kB(?:, shmem-rss:(\d+)kB)?`)
+	containerIDRe = regexp.MustCompile(`[0-9a-f]{64}`)
+)
+
+const (
+	oomKillPrefix       = "oom-kill:"
+	memCgroupPrefix     = "Memory cgroup out of memory:"
+	memCgroupConstraint = "CONSTRAINT_MEMCG"
+)
+
+// parser assembles the kills from the lines of the reports of the OOM killer
+type parser struct {
+	pending OOMKill
+}
+
+// parseRecord parses a record of /dev/kmsg, formatted as
+// `<priority>,<sequence>,<timestamp>,<flags>;<message>` and followed by
+// continuation lines starting with a space. It returns the kill whose
+// report ends with the record, if any.
+func (p *parser) parseRecord(record string) (OOMKill, bool) {
+	i := strings.IndexByte(record, ';')
+	if i < 0 {
+		return OOMKill{}, false
+	}
+	message := record[i+1:]
+	if j := strings.IndexByte(message, '\n'); j >= 0 {
+		message = message[:j]
+	}
+	return p.parseLine(message)
+}
+
+func (p *parser) parseLine(line string) (OOMKill, bool) {
+	switch {
+	case invokedRe.MatchString(line):
+		p.pending = OOMKill{}
+	case strings.HasPrefix(line, oomKillPrefix):
+		p.parseSummary(strings.TrimPrefix(line, oomKillPrefix))
+	default:
+		if m := taskInRe.FindStringSubmatch(line); m != nil {
+			p.pending.Cgroup = m[1]
+			p.pending.MemCgroupKill = true
+		} else if m := memoryRe.FindStringSubmatch(line); m != nil {
+			p.pending.MemoryUsage = parseUint(m[1])
+			p.pending.MemoryLimit = parseUint(m[2])
+			p.pending.MemCgroupKill = true
+		} else if m := killedRe.FindStringSubmatch(line); m != nil {
+			kill := p.pending
+			p.pending = OOMKill{}
+
+			kill.Pid, _ = strconv.Atoi(m[1])
+			kill.Comm = m[2]
+			kill.TotalVM = parseUint(m[3])
+			kill.AnonRSS = parseUint(m[4])
+			kill.FileRSS = parseUint(m[5])
+			kill.ShmemRSS = parseUint(m[6])
+			if strings.HasPrefix(line, memCgroupPrefix) {
+				kill.MemCgroupKill = true
+			}
+			if ids := containerIDRe.FindAllString(kill.Cgroup, -1); ids != nil {
+				kill.ContainerID = ids[len(ids)-1]
+			}
+			return kill, true
+		}
+	}
+	return OOMKill{}, false
+}
+
+// parseSummary parses the comma-separated key=value pairs of the oom-kill line:
+// constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=...,mems_allowed=0,oom_memcg=...,task_memcg=...,task=...,pid=...,uid=...
+func (p *parser) parseSummary(summary string) {
+	for _, field := range strings.Split(summary, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "constraint":
+			p.pending.MemCgroupKill = kv[1] == memCgroupConstraint
+		case "task_memcg":
+			p.pending.Cgroup = kv[1]
+		}
+	}
+}
+
+func parseUint(s string) uint64 {
+	v, _ := strconv.ParseUint(s, 10, 64)
+	return v
+}
@@ -0,0 +1,87 @@
+package oomkill
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseRecords(records []string) []OOMKill {
+	p := &parser{}
+	var kills []OOMKill
+	for _, r := range records {
+		if kill, ok := p.parseRecord(r); ok {
+			kills = append(kills, kill)
+		}
+	}
+	return kills
+}
+
+func TestParseCgroupKill(t *testing.T) {
+	// kernel 4.19+
+	kills := parseRecords([]string{
+		"4,1771,3577437451,-;stress invoked oom-killer: gfp_mask=0x6000c0(GFP_KERNEL), nodemask=(null), order=0, oom_score_adj=0\n SUBSYSTEM=memory",
+		"6,1772,3577437460,-;stress cpuset=0f3c9eb3aa8f6a6d1a89d4c1d4f0e2bb66a5e1ba1f1b4fb1b0c0a1e2b3c4d5e6 mems_allowed=0",
+		"6,1780,3577437510,-;memory: usage 262144kB, limit 262144kB, failcnt 49",
+		"6,1781,3577437511,-;memory+swap: usage 524288kB, limit 9007199254740988kB, failcnt 0",
+		"6,1790,3577437600,-;oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=0f3c9eb3aa8f,mems_allowed=0,oom_memcg=/docker/0f3c9eb3aa8f6a6d1a89d4c1d4f0e2bb66a5e1ba1f1b4fb1b0c0a1e2b3c4d5e6,task_memcg=/docker/0f3c9eb3aa8f6a6d1a89d4c1d4f0e2bb66a5e1ba1f1b4fb1b0c0a1e2b3c4d5e6,task=stress,pid=4242,uid=0",
+		"3,1791,3577437610,-;Memory cgroup out of memory: Killed process 4242 (stress) total-vm:268692kB, anon-rss:261892kB, file-rss:1012kB, shmem-rss:0kB",
+		"6,1792,3577437700,-;oom_reaper: reaped process 4242 (stress), now anon-rss:0kB, file-rss:0kB, shmem-rss:0kB",
+	})
+
+	assert.Equal(t, []OOMKill{{
+		Pid:           4242,
+		Comm:          "stress",
+		Cgroup:        "/docker/0f3c9eb3aa8f6a6d1a89d4c1d4f0e2bb66a5e1ba1f1b4fb1b0c0a1e2b3c4d5e6",
+		ContainerID:   "0f3c9eb3aa8f6a6d1a89d4c1d4f0e2bb66a5e1ba1f1b4fb1b0c0a1e2b3c4d5e6",
+		MemCgroupKill: true,
+		TotalVM:       268692,
+		AnonRSS:       261892,
+		FileRSS:       1012,
+		MemoryUsage:   262144,
+		MemoryLimit:   262144,
+	}}, kills)
+}
+
+func TestParseLegacyCgroupKill(t *testing.T) {
+	// kernels older than 4.19
+	kills := parseRecords([]string{
+		"4,500,1000,-;python invoked oom-killer: gfp_mask=0x24000c0, order=0, oom_score_adj=994",
+		"6,501,1001,-;Task in /kubepods/burstable/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e killed as a result of limit of /kubepods/burstable/pod2baa3444-4d37-11e7-bd2f-080027d2bf10",
+		"6,502,1002,-;memory: usage 102400kB, limit 102400kB, failcnt 12",
+		"3,503,1003,-;Memory cgroup out of memory: Kill process 31337 (python) score 1994 or sacrifice child",
+		"3,504,1004,-;Killed process 31337 (python) total-vm:180000kB, anon-rss:100000kB, file-rss:2000kB",
+	})
+
+	assert.Equal(t, []OOMKill{{
+		Pid:           31337,
+		Comm:          "python",
+		Cgroup:        "/kubepods/burstable/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e",
+		ContainerID:   "47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e",
+		MemCgroupKill: true,
+		TotalVM:       180000,
+		AnonRSS:       100000,
+		FileRSS:       2000,
+		MemoryUsage:   102400,
+		MemoryLimit:   102400,
+	}}, kills)
+}
+
+func TestParseHostKill(t *testing.T) {
+	kills := parseRecords([]string{
+		"4,10,1000,-;java invoked oom-killer: gfp_mask=0x100cca(GFP_HIGHUSER_MOVABLE), order=0, oom_score_adj=0",
+		"6,11,1001,-;oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/user.slice/user-1000.slice,task=java,pid=1234,uid=1000",
+		"3,12,1002,-;Out of memory: Killed process 1234 (java) total-vm:8000000kB, anon-rss:7000000kB, file-rss:0kB, shmem-rss:12kB, UID:1000 pgtables:14000kB oom_score_adj:0",
+		"6,13,1003,-;not a kill",
+		"malformed record",
+	})
+
+	assert.Equal(t, []OOMKill{{
+		Pid:      1234,
+		Comm:     "java",
+		Cgroup:   "/user.slice/user-1000.slice",
+		TotalVM:  8000000,
+		AnonRSS:  7000000,
+		ShmemRSS: 12,
+	}}, kills)
+}
@@ -0,0 +1,27 @@
+package oomkill
+
+// OOMKill is a process killed by the OOM killer. The memory sizes are in kB.
+type OOMKill struct {
+	// Timestamp is the time the kill was logged, in seconds since the epoch
+	Timestamp int64 `json:"timestamp"`
+	Pid       int   `json:"pid"`
+	// Comm is the command name of the process, its command line isn't
+	// logged by the kernel and the process is gone when the kill is logged
+	Comm string `json:"comm"`
+	// Cgroup is the memory cgroup of the process, empty on kernels
+	// that don't log it for the kills outside of a memory cgroup
+	Cgroup string `json:"cgroup"`
+	// ContainerID is the id of the container of the process, read from its cgroup
+	ContainerID string `json:"container_id"`
+	// MemCgroupKill is true if the kill was triggered by the memory limit of
+	// a cgroup, rather than by the memory of the host
+	MemCgroupKill bool   `json:"memcg_kill"`
+	TotalVM       uint64 `json:"total_vm"`
+	AnonRSS       uint64 `json:"anon_rss"`
+	FileRSS       uint64 `json:"file_rss"`
+	ShmemRSS      uint64 `json:"shmem_rss"`
+	// MemoryUsage and MemoryLimit are the usage and the limit of the memory
+	// cgroup for the cgroup kills
+	MemoryUsage uint64 `json:"memory_usage"`
+	MemoryLimit uint64 `json:"memory_limit"`
+}
@@ -0,0 +1,4 @@
+// Package tcpqueuelength measures the fill of the TCP buffers of the sockets
+// of every network namespace. The system-probe serves the measures to the
+// tcp_queue_length check of the agent.
+package tcpqueuelength
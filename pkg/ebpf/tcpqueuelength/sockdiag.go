@@ -0,0 +1,211 @@
+// +build linux
+
+package tcpqueuelength
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// See linux/sock_diag.h and linux/inet_diag.h
+const (
+	sockDiagByFamily = 20
+
+	inetDiagReqV2Len  = 56
+	inetDiagMsgLen    = 72
+	inetDiagSkMemInfo = 7
+
+	skMemInfoRmemAlloc  = 0
+	skMemInfoRcvBuf     = 1
+	skMemInfoSndBuf     = 3
+	skMemInfoWmemQueued = 5
+	// the number of fields grew with the kernel versions
+	skMemInfoMinLen = skMemInfoWmemQueued + 1
+
+	tcpListen = 10
+)
+
+// GetStats returns the fill of the TCP buffers of the sockets
+// of every network namespace with a process. The buffers are queried with
+// sock_diag in each namespace, which requires CAP_SYS_ADMIN.
+func GetStats(procRoot string) ([]Stats, error) {
+	namespaces, err := listNetNamespaces(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]Stats, 0, len(namespaces))
+	for ns, pid := range namespaces {
+		readFills, writeFills, err := getNetNSBufferFills(filepath.Join(procRoot, strconv.Itoa(pid), "ns/net"))
+		if err != nil {
+			// the processes may exit in the meantime
+			log.Debugf("could not query the TCP sockets of the network namespace %d: %s", ns, err)
+			continue
+		}
+		stats = append(stats, Stats{
+			NetNS:       ns,
+			Pid:         pid,
+			Sockets:     len(readFills),
+			ReadBuffer:  bufferUsage(readFills),
+			WriteBuffer: bufferUsage(writeFills),
+		})
+	}
+	return stats, nil
+}
+
+// listNetNamespaces returns the network namespaces with a process, with the
+// lowest pid of each namespace
+func listNetNamespaces(procRoot string) (map[uint64]int, error) {
+	entries, err := ioutil.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make(map[uint64]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		link, err := os.Readlink(filepath.Join(procRoot, entry.Name(), "ns/net"))
+		if err != nil {
+			continue
+		}
+		ns, err := parseNetNSLink(link)
+		if err != nil {
+			log.Debugf("could not get the network namespace of %d: %s", pid, err)
+			continue
+		}
+		if current, found := namespaces[ns]; !found || pid < current {
+			namespaces[ns] = pid
+		}
+	}
+	return namespaces, nil
+}
+
+// parseNetNSLink parses the `net:[4026531993]` links of /proc/<pid>/ns/net
+func parseNetNSLink(link string) (uint64, error) {
+	if !strings.HasPrefix(link, "net:[") || !strings.HasSuffix(link, "]") {
+		return 0, fmt.Errorf("unexpected network namespace link %s", link)
+	}
+	return strconv.ParseUint(link[len("net:["):len(link)-1], 10, 64)
+}
+
+// getNetNSBufferFills returns the fill of the read and write buffers of the
+// TCP sockets of a network namespace, listening sockets excluded
+func getNetNSBufferFills(nsPath string) ([]float64, []float64, error) {
+	nsFile, err := os.Open(nsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer nsFile.Close()
+
+	conn, err := netlink.Dial(unix.NETLINK_SOCK_DIAG, &netlink.Config{NetNS: int(nsFile.Fd())})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+
+	var readFills, writeFills []float64
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		msgs, err := conn.Execute(netlink.Message{
+			Header: netlink.Header{
+				Type:  sockDiagByFamily,
+				Flags: netlink.Request | netlink.Dump,
+			},
+			Data: newInetDiagRequest(family),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, msg := range msgs {
+			readFill, writeFill, ok := parseInetDiagMessage(msg.Data)
+			if !ok {
+				continue
+			}
+			readFills = append(readFills, readFill)
+			writeFills = append(writeFills, writeFill)
+		}
+	}
+	return readFills, writeFills, nil
+}
+
+// newInetDiagRequest builds an inet_diag_req_v2 asking for the memory usage
+// of the TCP sockets of a family
+func newInetDiagRequest(family uint8) []byte {
+	req := make([]byte, inetDiagReqV2Len)
+	req[0] = family
+	req[1] = unix.IPPROTO_TCP
+	req[2] = 1 << (inetDiagSkMemInfo - 1)
+	// all the states but LISTEN, whose read queue is the accept queue
+	nlenc.PutUint32(req[4:8], ^uint32(1<<tcpListen))
+	return req
+}
+
+// parseInetDiagMessage returns the fill of the read and write buffers of a
+// socket from an inet_diag_msg and its INET_DIAG_SKMEMINFO attribute, in
+// percent of the buffer sizes
+func parseInetDiagMessage(data []byte) (float64, float64, bool) {
+	if len(data) < inetDiagMsgLen {
+		return 0, 0, false
+	}
+	attrs, err := netlink.UnmarshalAttributes(data[inetDiagMsgLen:])
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, attr := range attrs {
+		if attr.Type != inetDiagSkMemInfo || len(attr.Data) < skMemInfoMinLen*4 {
+			continue
+		}
+		memInfo := func(i int) float64 {
+			return float64(nlenc.Uint32(attr.Data[i*4 : i*4+4]))
+		}
+		return fillPercent(memInfo(skMemInfoRmemAlloc), memInfo(skMemInfoRcvBuf)),
+			fillPercent(memInfo(skMemInfoWmemQueued), memInfo(skMemInfoSndBuf)),
+			true
+	}
+	return 0, 0, false
+}
+
+func fillPercent(used, size float64) float64 {
+	if size <= 0 {
+		return 0
+	}
+	return 100 * used / size
+}
+
+func bufferUsage(fills []float64) BufferUsage {
+	if len(fills) == 0 {
+		return BufferUsage{}
+	}
+	sorted := append([]float64(nil), fills...)
+	sort.Float64s(sorted)
+	return BufferUsage{
+		P50: percentile(sorted, 0.50),
+		P95: percentile(sorted, 0.95),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,73 @@
+// +build linux
+
+package tcpqueuelength
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInetDiagMessage(t *testing.T) {
+	memInfo := make([]byte, 9*4)
+	putMemInfo := func(i int, value uint32) {
+		nlenc.PutUint32(memInfo[i*4:i*4+4], value)
+	}
+	putMemInfo(skMemInfoRmemAlloc, 1000)
+	putMemInfo(skMemInfoRcvBuf, 4000)
+	putMemInfo(skMemInfoSndBuf, 2000)
+	putMemInfo(skMemInfoWmemQueued, 2000)
+	attrs, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: []byte{0, 0, 0, 0}},
+		{Type: inetDiagSkMemInfo, Data: memInfo},
+	})
+	require.NoError(t, err)
+
+	readFill, writeFill, ok := parseInetDiagMessage(append(make([]byte, inetDiagMsgLen), attrs...))
+	assert.True(t, ok)
+	assert.Equal(t, 25.0, readFill)
+	assert.Equal(t, 100.0, writeFill)
+
+	_, _, ok = parseInetDiagMessage(make([]byte, inetDiagMsgLen))
+	assert.False(t, ok)
+	_, _, ok = parseInetDiagMessage([]byte{1, 2})
+	assert.False(t, ok)
+}
+
+func TestBufferUsage(t *testing.T) {
+	assert.Equal(t, BufferUsage{}, bufferUsage(nil))
+
+	fills := []float64{}
+	for i := 100; i > 0; i-- {
+		fills = append(fills, float64(i))
+	}
+	assert.Equal(t, BufferUsage{P50: 50, P95: 95, Max: 100}, bufferUsage(fills))
+	assert.Equal(t, BufferUsage{P50: 3, P95: 3, Max: 3}, bufferUsage([]float64{3}))
+}
+
+func TestListNetNamespaces(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+
+	for pid, link := range map[string]string{
+		"1":    "net:[4026531993]",
+		"42":   "net:[4026532281]",
+		"7":    "net:[4026532281]",
+		"9":    "mnt:[4026531840]",
+		"self": "net:[4026531993]",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(procRoot, pid, "ns"), 0755))
+		require.NoError(t, os.Symlink(link, filepath.Join(procRoot, pid, "ns/net")))
+	}
+
+	namespaces, err := listNetNamespaces(procRoot)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]int{4026531993: 1, 4026532281: 7}, namespaces)
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package tcpqueuelength
+
+import "errors"
+
+// GetStats is only implemented on linux
+func GetStats(procRoot string) ([]Stats, error) {
+	return nil, errors.New("the tcp queue length is only measured on linux")
+}
@@ -0,0 +1,20 @@
+package tcpqueuelength
+
+// Stats holds the fill of the TCP buffers of the sockets of a
+// network namespace, as percentages of the buffer limits of the sockets
+type Stats struct {
+	// NetNS is the inode of the network namespace
+	NetNS uint64 `json:"netns"`
+	// Pid is a process of the network namespace, to find its container
+	Pid         int         `json:"pid"`
+	Sockets     int         `json:"sockets"`
+	ReadBuffer  BufferUsage `json:"read_buffer"`
+	WriteBuffer BufferUsage `json:"write_buffer"`
+}
+
+// BufferUsage holds percentiles of the fill of a kind of TCP buffer
+type BufferUsage struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
@@ -46,6 +46,10 @@ type Tracer struct {
 	skippedConns    int64
 	expiredTCPConns int64
 
+	// Debugging information
+	offsets GuessedOffsets
+	errors  *errorBuffer
+
 	buffer     []ConnectionStats
 	bufferLock sync.Mutex
 
@@ -91,6 +95,11 @@ func NewTracer(config *Config) (*Tracer, error) {
 		return nil, fmt.Errorf("failed to init module: error guessing offsets: %v", err)
 	}
 
+	offsets, err := readGuessedOffsets(m)
+	if err != nil {
+		log.Warnf("could not read the guessed offsets: %s", err)
+	}
+
 	portMapping := NewPortMapping(config.ProcRoot, config)
 	if err := portMapping.ReadInitialState(); err != nil {
 		return nil, fmt.Errorf("failed to read initial pid->port mapping: %s", err)
@@ -116,6 +125,8 @@ func NewTracer(config *Config) (*Tracer, error) {
 		buffer:         make([]ConnectionStats, 0, 512),
 		buf:            &bytes.Buffer{},
 		conntracker:    conntracker,
+		offsets:        offsets,
+		errors:         newErrorBuffer(maxTracerErrors),
 	}
 
 	tr.perfMap, err = tr.initPerfPolling()
@@ -225,6 +236,7 @@ func (t *Tracer) initPerfPolling() (*bpflib.PerfMap, error) {
 				tcpExpired := atomic.SwapInt64(&t.expiredTCPConns, 0)
 				if lost > 0 {
 					log.Warnf("closed connection polling: %d received, %d lost, %d skipped, %d expired TCP", recv, lost, skip, tcpExpired)
+					t.errors.add("closed connection polling: %d received, %d lost", recv, lost)
 				}
 			}
 		}
@@ -252,6 +264,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*Connections, error) {
 
 	latestConns, latestTime, err := t.getConnections(t.buffer[:0])
 	if err != nil {
+		t.errors.add("error retrieving connections: %s", err)
 		return nil, fmt.Errorf("error retrieving connections: %s", err)
 	}
 
@@ -365,6 +378,7 @@ func (t *Tracer) removeEntries(mp, tcpMp *bpflib.Map, entries []*ConnTuple) {
 		bk, err := connStats(entries[i], statsWithTs, tcpStats).ByteKey(t.buf)
 		if err != nil {
 			log.Warnf("failed to create connection byte_key: %s", err)
+			t.errors.add("failed to create connection byte_key: %s", err)
 		} else {
 			keys = append(keys, string(bk))
 		}
@@ -488,6 +502,31 @@ func (t *Tracer) DebugNetworkMaps() (*Connections, error) {
 	return &Connections{Conns: latestConns}, nil
 }
 
+// DebugEBPF returns the kernel probes and maps of the eBPF module
+func (t *Tracer) DebugEBPF() (*EBPFInfo, error) {
+	info := &EBPFInfo{}
+	enabledProbes := t.config.EnabledKProbes()
+	for k := range t.m.IterKprobes() {
+		_, enabled := enabledProbes[KProbeName(k.Name)]
+		info.KProbes = append(info.KProbes, KProbeInfo{Name: k.Name, Enabled: enabled})
+	}
+	for mp := range t.m.IterMaps() {
+		info.Maps = append(info.Maps, MapInfo{Name: mp.Name, FD: mp.Fd()})
+	}
+	return info, nil
+}
+
+// DebugOffsets returns the offsets found by the offset guessing
+func (t *Tracer) DebugOffsets() (*GuessedOffsets, error) {
+	offsets := t.offsets
+	return &offsets, nil
+}
+
+// DebugErrors returns the last errors encountered by the tracer
+func (t *Tracer) DebugErrors() ([]TracerError, error) {
+	return t.errors.get(), nil
+}
+
 // populatePortMapping reads the entire portBinding bpf map and populates the local port/address map.  A list of
 // closed ports will be returned
 func (t *Tracer) populatePortMapping(mp *bpflib.Map) ([]uint16, error) {
@@ -37,3 +37,18 @@ func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, err
 func (t *Tracer) DebugNetworkMaps() (*Connections, error) {
 	return nil, ErrNotImplemented
 }
+
+// DebugEBPF is not implemented on non-linux systems
+func (t *Tracer) DebugEBPF() (*EBPFInfo, error) {
+	return nil, ErrNotImplemented
+}
+
+// DebugOffsets is not implemented on non-linux systems
+func (t *Tracer) DebugOffsets() (*GuessedOffsets, error) {
+	return nil, ErrNotImplemented
+}
+
+// DebugErrors is not implemented on non-linux systems
+func (t *Tracer) DebugErrors() ([]TracerError, error) {
+	return nil, ErrNotImplemented
+}
@@ -199,6 +199,11 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		log.Errorf("Could not collect go routine stack traces: %s", err)
 	}
 
+	err = zipCheckProfiles(tempDir, hostname)
+	if err != nil {
+		log.Errorf("Could not zip the check profiles: %s", err)
+	}
+
 	if config.IsContainerized() {
 		err = zipDockerSelfInspect(tempDir, hostname)
 		if err != nil {
@@ -211,6 +216,11 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		log.Errorf("Could not zip docker ps: %s", err)
 	}
 
+	err = zipSystemProbe(tempDir, hostname)
+	if err != nil {
+		log.Errorf("Could not zip system-probe: %s", err)
+	}
+
 	err = zipTypeperfData(tempDir, hostname)
 	if err != nil {
 		log.Errorf("Could not write typeperf data: %s", err)
@@ -422,10 +432,26 @@ func zipDiagnose(tempDir, hostname string) error {
 	var b bytes.Buffer
 
 	writer := bufio.NewWriter(&b)
-	diagnose.RunAll(writer)
+	report, err := diagnose.RunAllWithReport(writer)
+	if err != nil {
+		return err
+	}
 	writer.Flush()
 
-	f := filepath.Join(tempDir, hostname, "diagnose.log")
+	err = writeScrubbedFile(filepath.Join(tempDir, hostname, "diagnose.log"), b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// machine-readable report of the same diagnosis
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeScrubbedFile(filepath.Join(tempDir, hostname, "diagnose.json"), data)
+}
+
+func writeScrubbedFile(f string, data []byte) error {
 	err := ensureParentDirsExist(f)
 	if err != nil {
 		return err
@@ -437,7 +463,7 @@ func zipDiagnose(tempDir, hostname string) error {
 	}
 	defer w.Close()
 
-	_, err = w.Write(b.Bytes())
+	_, err = w.Write(data)
 	return err
 }
 
@@ -527,6 +553,22 @@ func zipStackTraces(tempDir, hostname string) error {
 	return err
 }
 
+// zipCheckProfiles copies the profiles of the check runs, written by
+// `agent check --profile` and the check_profiling runtime setting
+func zipCheckProfiles(tempDir, hostname string) error {
+	profiles, err := filepath.Glob(filepath.Join(config.Datadog.GetString("check_profiles_dir"), "*.pprof"))
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		dst := filepath.Join(tempDir, hostname, "check_profiles", filepath.Base(profile))
+		if err := util.CopyFileAll(profile, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func walkConfigFilePaths(tempDir, hostname string, confSearchPaths SearchPaths, permsInfos permissionsInfos) error {
 	for prefix, filePath := range confSearchPaths {
 		err := filepath.Walk(filePath, func(src string, f os.FileInfo, err error) error {
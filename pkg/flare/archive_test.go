@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
@@ -122,6 +123,8 @@ func TestZipConfigCheck(t *testing.T) {
 		Instances: []integration.Data{[]byte("username: User\npassword: MySecurePass")},
 		Provider:  "FooProvider",
 	})
+	id := string(check.BuildID("TestCheck", cr.Configs[0].Instances[0], nil))
+	cr.FirstRunErrors = map[string]string{id: "authentication failed"}
 
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		out, _ := json.Marshal(cr)
@@ -136,6 +139,10 @@ func TestZipConfigCheck(t *testing.T) {
 	}
 	defer os.RemoveAll(dir)
 
+	// the auth token is generated on the first query, keep it out of the package directory
+	mockConfig := config.Mock()
+	mockConfig.Set("auth_token_file_path", filepath.Join(dir, "auth_token"))
+
 	zipConfigCheck(dir, "")
 	content, err := ioutil.ReadFile(filepath.Join(dir, "config-check.log"))
 	if err != nil {
@@ -143,6 +150,29 @@ func TestZipConfigCheck(t *testing.T) {
 	}
 
 	assert.NotContains(t, string(content), "MySecurePass")
+	assert.Contains(t, string(content), "First run error: authentication failed")
+}
+
+func TestZipCheckProfiles(t *testing.T) {
+	mockConfig := config.Mock()
+
+	profilesDir, err := ioutil.TempDir("", "TestZipCheckProfiles")
+	assert.NoError(t, err)
+	defer os.RemoveAll(profilesDir)
+	mockConfig.Set("check_profiles_dir", profilesDir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(profilesDir, "cpu-20191015-103136.cpu.pprof"), []byte("profile"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(profilesDir, "notes.txt"), []byte("notes"), 0644))
+
+	dir, err := ioutil.TempDir("", "TestZipCheckProfiles")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, zipCheckProfiles(dir, "host"))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "host", "check_profiles", "cpu-20191015-103136.cpu.pprof"))
+	assert.NoError(t, err)
+	assert.Equal(t, "profile", string(content))
+	_, err = os.Stat(filepath.Join(dir, "host", "check_profiles", "notes.txt"))
+	assert.True(t, os.IsNotExist(err))
 }
 
 func TestIncludeConfigFiles(t *testing.T) {
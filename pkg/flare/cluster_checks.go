@@ -87,9 +87,13 @@ func GetClusterChecks(w io.Writer) error {
 	fmt.Fprintln(w, fmt.Sprintf("=== %d node-agents reporting ===", len(cr.Nodes)))
 	sort.Slice(cr.Nodes, func(i, j int) bool { return cr.Nodes[i].Name < cr.Nodes[j].Name })
 	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(table, "\nName\tRunning checks")
+	fmt.Fprintln(table, "\nName\tRunning checks\tCluster check runner\tCapacity")
 	for _, n := range cr.Nodes {
-		fmt.Fprintf(table, "%s\t%d\n", n.Name, len(n.Configs))
+		capacity := "unlimited"
+		if n.Capacity > 0 {
+			capacity = fmt.Sprintf("%d", n.Capacity)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%t\t%s\n", n.Name, len(n.Configs), n.ClcRunner, capacity)
 	}
 	table.Flush()
 
@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
 
@@ -61,7 +63,7 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 	}
 
 	for _, c := range cr.Configs {
-		PrintConfig(w, c)
+		printConfig(w, c, cr.FirstRunErrors)
 	}
 
 	if withDebug {
@@ -84,11 +86,48 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 				}
 			}
 		}
+		if len(cr.Resolutions) > 0 {
+			fmt.Fprintln(w, fmt.Sprintf("\n=== Auto-discovery %s ===", color.YellowString("resolutions")))
+			entities := make([]string, 0, len(cr.Resolutions))
+			for entity := range cr.Resolutions {
+				entities = append(entities, entity)
+			}
+			sort.Strings(entities)
+			for _, entity := range entities {
+				PrintResolution(w, cr.Resolutions[entity])
+			}
+		}
 	}
 
 	return nil
 }
 
+// PrintResolution prints a human-readable representation of the templates
+// resolution for a service
+func PrintResolution(w io.Writer, r integration.ServiceResolution) {
+	fmt.Fprintln(w, fmt.Sprintf("\n%s: %s", color.BlueString("Service"), color.CyanString(r.Entity)))
+	fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Auto-discovery IDs"), strings.Join(r.ADIdentifiers, ", ")))
+	if len(r.Templates) == 0 {
+		fmt.Fprintln(w, color.YellowString("No template matched this service"))
+		return
+	}
+	for _, tpl := range r.Templates {
+		status := color.GreenString("resolved")
+		if tpl.Error != "" {
+			status = fmt.Sprintf("%s: %s", color.RedString("rejected"), tpl.Error)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("* %s template from %s matched on %s, %s", color.GreenString(tpl.Name), color.CyanString(tpl.Provider), color.CyanString(tpl.ADIdentifier), status))
+		variables := make([]string, 0, len(tpl.Variables))
+		for name := range tpl.Variables {
+			variables = append(variables, name)
+		}
+		sort.Strings(variables)
+		for _, name := range variables {
+			fmt.Fprintln(w, fmt.Sprintf("    %s -> %s", name, tpl.Variables[name]))
+		}
+	}
+}
+
 // GetClusterAgentConfigCheck proxies GetConfigCheck overidding the URL
 func GetClusterAgentConfigCheck(w io.Writer, withDebug bool) error {
 	configCheckURL = fmt.Sprintf("https://localhost:%v/config-check", config.Datadog.GetInt("cluster_agent.cmd_port"))
@@ -97,6 +136,12 @@ func GetClusterAgentConfigCheck(w io.Writer, withDebug bool) error {
 
 // PrintConfig prints a human-readable representation of a configuration
 func PrintConfig(w io.Writer, c integration.Config) {
+	printConfig(w, c, nil)
+}
+
+// printConfig prints a configuration along with the error of the first run
+// of its instances, if any
+func printConfig(w io.Writer, c integration.Config, firstRunErrors map[string]string) {
 	if !c.ClusterCheck {
 		fmt.Fprintln(w, fmt.Sprintf("\n=== %s check ===", color.GreenString(c.Name)))
 	} else {
@@ -111,6 +156,9 @@ func PrintConfig(w io.Writer, c integration.Config) {
 	for _, inst := range c.Instances {
 		ID := string(check.BuildID(c.Name, inst, c.InitConfig))
 		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Instance ID"), color.CyanString(ID)))
+		if err, found := firstRunErrors[ID]; found {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.RedString("First run error"), err))
+		}
 		fmt.Fprint(w, fmt.Sprintf("%s", inst))
 		fmt.Fprintln(w, "~")
 	}
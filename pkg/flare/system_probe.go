@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package flare
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/sysprobe"
+)
+
+// systemProbeSections are the debugging endpoints of the system-probe
+// included in the flare: its status, the conntrack and tracer telemetry,
+// the loaded eBPF programs and maps, the guessed offsets and the last
+// errors of the tracer.
+var systemProbeSections = []string{"status", "stats", "ebpf", "offsets", "errors"}
+
+// zipSystemProbe adds the debugging information of the system-probe to the
+// flare, when it is running on the host
+func zipSystemProbe(tempDir, hostname string) error {
+	if _, err := os.Stat(sysprobe.SocketPath()); os.IsNotExist(err) {
+		log.Debugf("The system-probe socket %s doesn't exist, skipping", sysprobe.SocketPath())
+		return nil
+	}
+
+	client := sysprobe.NewClient()
+	var failed []string
+	for _, section := range systemProbeSections {
+		if err := zipSystemProbeSection(client, tempDir, hostname, section); err != nil {
+			log.Warnf("Could not zip the system-probe %s: %s", section, err)
+			failed = append(failed, section)
+		}
+	}
+
+	if len(failed) == len(systemProbeSections) {
+		return fmt.Errorf("could not query the system-probe")
+	}
+	return nil
+}
+
+func zipSystemProbeSection(client *http.Client, tempDir, hostname, section string) error {
+	data, err := sysprobe.GetDebug(client, section)
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(tempDir, hostname, "system-probe", section+".json")
+	err = ensureParentDirsExist(f)
+	if err != nil {
+		return err
+	}
+
+	w, err := newRedactingWriter(f, os.ModePerm, true)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	bandwidthThrottled     = expvar.Int{}
+	bandwidthThrottledTime = expvar.Int{}
+	bandwidthExpvars       = expvar.Map{}
+)
+
+func initBandwidthExpvars() {
+	bandwidthExpvars.Init()
+	forwarderExpvars.Set("Bandwidth", &bandwidthExpvars)
+	bandwidthExpvars.Set("Throttled", &bandwidthThrottled)
+	bandwidthExpvars.Set("ThrottledTimeMs", &bandwidthThrottledTime)
+}
+
+// bandwidthLimiter limits the rate at which the payloads are sent, it's
+// shared by the workers of every domain so the limit applies to the whole
+// egress traffic of the forwarder.
+type bandwidthLimiter struct {
+	limiter *rate.Limiter
+}
+
+// newBandwidthLimiter returns a limiter allowing bytesPerSec bytes per second
+// on average and bursts of burst bytes, or nil if bytesPerSec is 0 or less.
+// The burst defaults to one second of traffic.
+func newBandwidthLimiter(bytesPerSec, burst int) *bandwidthLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = bytesPerSec
+	}
+	return &bandwidthLimiter{limiter: rate.NewLimiter(rate.Limit(bytesPerSec), burst)}
+}
+
+// newBandwidthLimiterFromConfig returns the limiter configured with
+// `forwarder_max_bytes_per_sec` and `forwarder_max_bytes_burst`, or nil if the
+// bandwidth isn't limited.
+func newBandwidthLimiterFromConfig() *bandwidthLimiter {
+	bytesPerSec := config.Datadog.GetInt("forwarder_max_bytes_per_sec")
+	burst := config.Datadog.GetInt("forwarder_max_bytes_burst")
+	l := newBandwidthLimiter(bytesPerSec, burst)
+	if l != nil {
+		log.Infof("Forwarder bandwidth limited to %d bytes/s with bursts of %d bytes", bytesPerSec, l.limiter.Burst())
+	}
+	return l
+}
+
+// wait blocks until size bytes can be sent, or the context is done. Payloads
+// bigger than the burst are accounted for in burst-sized chunks.
+func (l *bandwidthLimiter) wait(ctx context.Context, size int) error {
+	if size <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	burst := l.limiter.Burst()
+	reservations := []*rate.Reservation{}
+	var delay time.Duration
+	for remaining := size; remaining > 0; remaining -= burst {
+		chunk := remaining
+		if chunk > burst {
+			chunk = burst
+		}
+		r := l.limiter.ReserveN(now, chunk)
+		reservations = append(reservations, r)
+		delay = r.DelayFrom(now)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	bandwidthThrottled.Add(1)
+	bandwidthThrottledTime.Add(int64(delay / time.Millisecond))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give back the bytes that weren't sent
+		for i := len(reservations) - 1; i >= 0; i-- {
+			reservations[i].Cancel()
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	assert.Nil(t, newBandwidthLimiter(0, 100))
+	assert.Nil(t, newBandwidthLimiter(-1, 100))
+
+	l := newBandwidthLimiter(1000, 0)
+	require.NotNil(t, l)
+	assert.Equal(t, 1000, l.limiter.Burst())
+
+	l = newBandwidthLimiter(1000, 200)
+	require.NotNil(t, l)
+	assert.Equal(t, 200, l.limiter.Burst())
+}
+
+func TestNewBandwidthLimiterFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	assert.Nil(t, newBandwidthLimiterFromConfig())
+
+	mockConfig.Set("forwarder_max_bytes_per_sec", 5000)
+	mockConfig.Set("forwarder_max_bytes_burst", 500)
+	defer mockConfig.Set("forwarder_max_bytes_per_sec", 0)
+	defer mockConfig.Set("forwarder_max_bytes_burst", 0)
+
+	l := newBandwidthLimiterFromConfig()
+	require.NotNil(t, l)
+	assert.Equal(t, 500, l.limiter.Burst())
+}
+
+func TestBandwidthLimiterWait(t *testing.T) {
+	l := newBandwidthLimiter(1000, 100)
+	throttled := bandwidthThrottled.Value()
+
+	// the burst is sent right away
+	start := time.Now()
+	require.NoError(t, l.wait(context.Background(), 100))
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+	assert.Equal(t, throttled, bandwidthThrottled.Value())
+
+	// then 1000 bytes per second, in burst-sized chunks for bigger payloads
+	start = time.Now()
+	require.NoError(t, l.wait(context.Background(), 150))
+	assert.True(t, time.Since(start) >= 140*time.Millisecond)
+	assert.Equal(t, throttled+1, bandwidthThrottled.Value())
+}
+
+func TestBandwidthLimiterWaitCanceled(t *testing.T) {
+	l := newBandwidthLimiter(10, 10)
+	require.NoError(t, l.wait(context.Background(), 10))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, l.wait(ctx, 10))
+
+	// the canceled bytes are given back
+	assert.True(t, l.limiter.ReserveN(time.Now(), 10).DelayFrom(time.Now()) <= time.Second)
+}
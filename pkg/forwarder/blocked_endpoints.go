@@ -6,6 +6,7 @@
 package forwarder
 
 import (
+	"expvar"
 	"math"
 	"math/rand"
 	"sync"
@@ -17,13 +18,16 @@ import (
 
 const secondsFloat = float64(time.Second)
 
+var transactionsEndpointsPaused = expvar.Int{}
+
 func randomBetween(min, max float64) float64 {
 	return rand.Float64()*(max-min) + min
 }
 
 type block struct {
-	nbError int
-	until   time.Time
+	nbError      int
+	clientErrors int // consecutive 4xx responses
+	until        time.Time
 }
 
 type blockedEndpoints struct {
@@ -50,6 +54,13 @@ type blockedEndpoints struct {
 
 	// This derived value is the number of errors it will take to reach the maxBackoffTime.
 	maxErrors int
+
+	// This is the number of consecutive 4xx responses after which an endpoint is
+	// paused for clientErrorPause, 0 disables the circuit breaker. 4xx responses
+	// aren't retried so they don't trigger the backoff, but sustained 4xx usually
+	// mean a misconfiguration (like an invalid API key) that retrying won't fix.
+	clientErrorThreshold int
+	clientErrorPause     time.Duration
 }
 
 func newBlockedEndpoints() *blockedEndpoints {
@@ -84,13 +95,27 @@ func newBlockedEndpoints() *blockedEndpoints {
 		recInterval = errorsMax
 	}
 
+	clientErrorThreshold := config.Datadog.GetInt("forwarder_4xx_circuit_breaker_threshold")
+	if clientErrorThreshold < 0 {
+		log.Warnf("Configured forwarder_4xx_circuit_breaker_threshold (%v) is negative; the circuit breaker will be disabled", clientErrorThreshold)
+		clientErrorThreshold = 0
+	}
+
+	clientErrorPause := config.Datadog.GetInt("forwarder_4xx_circuit_breaker_pause")
+	if clientErrorPause <= 0 {
+		log.Warnf("Configured forwarder_4xx_circuit_breaker_pause (%v) is not positive; 300 seconds will be used", clientErrorPause)
+		clientErrorPause = 300
+	}
+
 	return &blockedEndpoints{
-		errorPerEndpoint: make(map[string]*block),
-		minBackoffFactor: backoffFactor,
-		baseBackoffTime:  backoffBase,
-		maxBackoffTime:   backoffMax,
-		recoveryInterval: recInterval,
-		maxErrors:        errorsMax,
+		errorPerEndpoint:     make(map[string]*block),
+		minBackoffFactor:     backoffFactor,
+		baseBackoffTime:      backoffBase,
+		maxBackoffTime:       backoffMax,
+		recoveryInterval:     recInterval,
+		maxErrors:            errorsMax,
+		clientErrorThreshold: clientErrorThreshold,
+		clientErrorPause:     time.Duration(clientErrorPause) * time.Second,
 	}
 }
 
@@ -129,11 +154,40 @@ func (e *blockedEndpoints) recover(endpoint string) {
 	if b.nbError < 0 {
 		b.nbError = 0
 	}
+	b.clientErrors = 0
 	b.until = time.Now().Add(e.getBackoffDuration(b.nbError))
 
 	e.errorPerEndpoint[endpoint] = b
 }
 
+// clientError records a 4xx response from an endpoint, pausing it once the
+// circuit breaker threshold is reached.
+func (e *blockedEndpoints) clientError(endpoint string) {
+	if e.clientErrorThreshold == 0 {
+		return
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	var b *block
+	if knownBlock, ok := e.errorPerEndpoint[endpoint]; ok {
+		b = knownBlock
+	} else {
+		b = &block{}
+	}
+
+	b.clientErrors++
+	if b.clientErrors >= e.clientErrorThreshold {
+		log.Errorf("%d consecutive client errors from endpoint '%s': pausing it for %s", b.clientErrors, endpoint, e.clientErrorPause)
+		b.clientErrors = 0
+		b.until = time.Now().Add(e.clientErrorPause)
+		transactionsEndpointsPaused.Add(1)
+	}
+
+	e.errorPerEndpoint[endpoint] = b
+}
+
 func (e *blockedEndpoints) isBlock(endpoint string) bool {
 	e.m.RLock()
 	defer e.m.RUnlock()
@@ -280,3 +280,49 @@ func TestIsblockUnknown(t *testing.T) {
 
 	assert.False(t, e.isBlock("test"))
 }
+
+func TestClientErrorCircuitBreaker(t *testing.T) {
+	mockConfig := config.Mock()
+	e := newBlockedEndpoints()
+	assert.Equal(t, 0, e.clientErrorThreshold)
+	assert.Equal(t, 300*time.Second, e.clientErrorPause)
+
+	// disabled by default
+	for i := 0; i < 10; i++ {
+		e.clientError("test")
+	}
+	assert.False(t, e.isBlock("test"))
+
+	mockConfig.Set("forwarder_4xx_circuit_breaker_threshold", 3)
+	mockConfig.Set("forwarder_4xx_circuit_breaker_pause", 60)
+	defer mockConfig.Set("forwarder_4xx_circuit_breaker_threshold", 0)
+	defer mockConfig.Set("forwarder_4xx_circuit_breaker_pause", 300)
+	e = newBlockedEndpoints()
+	paused := transactionsEndpointsPaused.Value()
+
+	e.clientError("test")
+	e.clientError("test")
+	assert.False(t, e.isBlock("test"))
+	// a success resets the count
+	e.recover("test")
+	e.clientError("test")
+	e.clientError("test")
+	assert.False(t, e.isBlock("test"))
+
+	e.clientError("test")
+	assert.True(t, e.isBlock("test"))
+	assert.True(t, e.errorPerEndpoint["test"].until.After(time.Now().Add(59*time.Second)))
+	assert.Equal(t, paused+1, transactionsEndpointsPaused.Value())
+}
+
+func TestClientErrorCircuitBreakerInvalidConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("forwarder_4xx_circuit_breaker_threshold", -1)
+	mockConfig.Set("forwarder_4xx_circuit_breaker_pause", 0)
+	defer mockConfig.Set("forwarder_4xx_circuit_breaker_threshold", 0)
+	defer mockConfig.Set("forwarder_4xx_circuit_breaker_pause", 300)
+
+	e := newBlockedEndpoints()
+	assert.Equal(t, 0, e.clientErrorThreshold)
+	assert.Equal(t, 300*time.Second, e.clientErrorPause)
+}
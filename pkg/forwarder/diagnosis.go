@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func init() {
+	diagnosis.Register("Intake reachability", diagnose)
+}
+
+// diagnose validates the api keys against every configured intake endpoint
+func diagnose() error {
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return diagnosis.Fail(err, "fix the dd_url, api_key and additional_endpoints settings")
+	}
+	return diagnoseEndpoints(keysPerDomain)
+}
+
+func diagnoseEndpoints(keysPerDomain map[string][]string) error {
+	domains := make([]string, 0, len(keysPerDomain))
+	for domain := range keysPerDomain {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	fh := &forwarderHealth{timeout: validateAPIKeyTimeout}
+	var unreachable, invalid []string
+	for _, domain := range domains {
+		for _, apiKey := range keysPerDomain[domain] {
+			obfuscatedKey := apiKey
+			if len(obfuscatedKey) > 5 {
+				obfuscatedKey = obfuscatedKey[len(obfuscatedKey)-5:]
+			}
+
+			valid, err := fh.validateAPIKey(apiKey, domain)
+			if err != nil {
+				log.Errorf("unable to reach %s: %s", domain, err)
+				unreachable = append(unreachable, domain)
+			} else if !valid {
+				log.Errorf("the API key ending with %s is invalid for %s", obfuscatedKey, domain)
+				invalid = append(invalid, domain)
+			} else {
+				log.Infof("the API key ending with %s is valid for %s", obfuscatedKey, domain)
+			}
+		}
+	}
+
+	if len(unreachable) > 0 {
+		return diagnosis.Fail(
+			fmt.Errorf("unable to reach %v", unreachable),
+			"allow the outgoing HTTPS traffic to these endpoints, or set the proxy settings if the host has no direct access to the internet",
+		)
+	}
+	if len(invalid) > 0 {
+		return diagnosis.Fail(
+			fmt.Errorf("invalid API keys for %v", invalid),
+			"set an API key of your organization, listed at https://app.datadoghq.com/account/settings#api, and make sure the site matches your organization",
+		)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+)
+
+func TestDiagnoseEndpoints(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") == "invalid" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	assert.NoError(t, diagnoseEndpoints(map[string][]string{ts.URL: {"valid"}}))
+
+	err := diagnoseEndpoints(map[string][]string{ts.URL: {"valid", "invalid"}})
+	require.IsType(t, &diagnosis.Failure{}, err)
+	assert.Contains(t, err.Error(), "invalid API keys")
+
+	ts.Close()
+	err = diagnoseEndpoints(map[string][]string{ts.URL: {"valid"}})
+	require.IsType(t, &diagnosis.Failure{}, err)
+	assert.Contains(t, err.Error(), "unable to reach")
+}
@@ -23,12 +23,19 @@ var (
 	transactionsRetried  = expvar.Int{}
 	transactionsDropped  = expvar.Int{}
 	transactionsRequeued = expvar.Int{}
+	transactionsResigned = expvar.Int{}
+
+	retryQueueSizePerDomain = expvar.Map{}
 )
 
 func initDomainForwarderExpvars() {
+	retryQueueSizePerDomain.Init()
+	transactionsExpvars.Set("RetryQueueSizePerDomain", &retryQueueSizePerDomain)
 	transactionsExpvars.Set("Retried", &transactionsRetried)
 	transactionsExpvars.Set("Dropped", &transactionsDropped)
 	transactionsExpvars.Set("Requeued", &transactionsRequeued)
+	transactionsExpvars.Set("Resigned", &transactionsResigned)
+	transactionsExpvars.Set("EndpointsPaused", &transactionsEndpointsPaused)
 }
 
 // domainForwarder is in charge of sending Transactions to Datadog backend over
@@ -48,6 +55,11 @@ type domainForwarder struct {
 	m                   sync.Mutex // To control Start/Stop races
 	isRetrying          int32
 	blockedList         *blockedEndpoints
+	failover            *failover
+	telemetry           *endpointTelemetry
+	bandwidth           *bandwidthLimiter
+	apiKeyReplacements  chan map[string]string // use to receive rotated api keys
+	replacedAPIKeys     map[string]string      // old api key -> new api key
 }
 
 func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int) *domainForwarder {
@@ -57,14 +69,22 @@ func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int)
 		retryQueueLimit: retryQueueLimit,
 		internalState:   Stopped,
 		blockedList:     newBlockedEndpoints(),
+		telemetry:       getEndpointTelemetry(domain),
 	}
 }
 
-type byCreatedTime []Transaction
+// pendingTransaction is a transaction of the retry queue along with its
+// creation time, so that it's only read once per retry attempt.
+type pendingTransaction struct {
+	Transaction
+	createdAt time.Time
+}
+
+type byCreatedTime []pendingTransaction
 
 func (v byCreatedTime) Len() int           { return len(v) }
 func (v byCreatedTime) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
-func (v byCreatedTime) Less(i, j int) bool { return v[i].GetCreatedAt().After(v[j].GetCreatedAt()) }
+func (v byCreatedTime) Less(i, j int) bool { return v[i].createdAt.After(v[j].createdAt) }
 
 func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	// In case it takes more that flushInterval to sort and retry
@@ -78,10 +98,16 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	newQueue := []Transaction{}
 	droppedRetryQueueFull := 0
 	droppedWorkerBusy := 0
+	var oldestPending time.Time
 
-	sort.Sort(byCreatedTime(f.retryQueue))
-
+	queue := make([]pendingTransaction, 0, len(f.retryQueue))
 	for _, t := range f.retryQueue {
+		queue = append(queue, pendingTransaction{Transaction: t, createdAt: t.GetCreatedAt()})
+	}
+	sort.Sort(byCreatedTime(queue))
+
+	for _, pending := range queue {
+		t := pending.Transaction
 		if !f.blockedList.isBlock(t.GetTarget()) {
 			select {
 			case f.lowPrio <- t:
@@ -89,18 +115,22 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 			default:
 				droppedWorkerBusy++
 				transactionsDropped.Add(1)
+				f.telemetry.recordTransaction(t, transactionDropped)
 			}
 		} else if len(newQueue) < f.retryQueueLimit {
 			newQueue = append(newQueue, t)
+			oldestPending = pending.createdAt // the queue is sorted from the newest to the oldest transaction
 			transactionsRequeued.Add(1)
 		} else {
 			droppedRetryQueueFull++
 			transactionsDropped.Add(1)
+			f.telemetry.recordTransaction(t, transactionDropped)
 		}
 	}
 
 	f.retryQueue = newQueue
-	transactionsRetryQueueSize.Set(int64(len(f.retryQueue)))
+	f.updateRetryQueueSize()
+	f.telemetry.setOldestPending(oldestPending)
 
 	if droppedRetryQueueFull+droppedWorkerBusy > 0 {
 		log.Errorf("Dropped %d transactions in this retry attempt: %d for exceeding the retry queue size limit of %d, %d because the workers are too busy",
@@ -109,9 +139,59 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 }
 
 func (f *domainForwarder) requeueTransaction(t Transaction) {
+	f.signWithNewAPIKey(t)
 	f.retryQueue = append(f.retryQueue, t)
 	transactionsRequeued.Add(1)
-	transactionsRetryQueueSize.Set(int64(len(f.retryQueue)))
+	f.updateRetryQueueSize()
+}
+
+func (f *domainForwarder) updateRetryQueueSize() {
+	size := int64(len(f.retryQueue))
+	transactionsRetryQueueSize.Set(size)
+
+	domainSize := &expvar.Int{}
+	domainSize.Set(size)
+	retryQueueSizePerDomain.Set(f.domain, domainSize)
+	f.telemetry.setRetryQueueSize(len(f.retryQueue))
+}
+
+// replaceAPIKeys sends rotated api keys to the goroutine handling the
+// retry queue.
+func (f *domainForwarder) replaceAPIKeys(replacements map[string]string) {
+	// Lock so we can't stop the domainForwarder while sending
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState == Stopped {
+		return
+	}
+	f.apiKeyReplacements <- replacements
+}
+
+// rotateAPIKeys signs the transactions waiting in the retry queue with the
+// new api keys. The replacements are kept to sign the transactions that are
+// being sent with the old keys if they are requeued.
+func (f *domainForwarder) rotateAPIKeys(replacements map[string]string) {
+	for oldKey, newKey := range f.replacedAPIKeys {
+		if key, found := replacements[newKey]; found {
+			f.replacedAPIKeys[oldKey] = key
+		}
+	}
+	for oldKey, newKey := range replacements {
+		f.replacedAPIKeys[oldKey] = newKey
+	}
+	for _, t := range f.retryQueue {
+		f.signWithNewAPIKey(t)
+	}
+}
+
+func (f *domainForwarder) signWithNewAPIKey(t Transaction) {
+	if len(f.replacedAPIKeys) == 0 {
+		return
+	}
+	if httpTransaction, ok := t.(*HTTPTransaction); ok && httpTransaction.replaceAPIKey(f.replacedAPIKeys) {
+		transactionsResigned.Add(1)
+	}
 }
 
 func (f *domainForwarder) handleFailedTransactions() {
@@ -122,6 +202,8 @@ func (f *domainForwarder) handleFailedTransactions() {
 			f.retryTransactions(tickTime)
 		case t := <-f.requeuedTransaction:
 			f.requeueTransaction(t)
+		case replacements := <-f.apiKeyReplacements:
+			f.rotateAPIKeys(replacements)
 		case <-f.stopRetry:
 			ticker.Stop()
 			return
@@ -134,8 +216,10 @@ func (f *domainForwarder) init() {
 	f.lowPrio = make(chan Transaction, chanBufferSize)
 	f.requeuedTransaction = make(chan Transaction, chanBufferSize)
 	f.stopRetry = make(chan bool)
+	f.apiKeyReplacements = make(chan map[string]string)
 	f.workers = []*Worker{}
 	f.retryQueue = []Transaction{}
+	f.replacedAPIKeys = map[string]string{}
 }
 
 // Start starts a domainForwarder.
@@ -153,6 +237,9 @@ func (f *domainForwarder) Start() error {
 
 	for i := 0; i < f.numberOfWorkers; i++ {
 		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
+		w.failover = f.failover
+		w.bandwidth = f.bandwidth
+		w.telemetry = f.telemetry
 		w.Start()
 		f.workers = append(f.workers, w)
 	}
@@ -200,6 +287,7 @@ func (f *domainForwarder) sendHTTPTransactions(transaction Transaction) error {
 	case f.highPrio <- transaction:
 	default:
 		transactionsDroppedOnInput.Add(1)
+		f.telemetry.recordTransaction(transaction, transactionDropped)
 		return fmt.Errorf("the forwarder input queue for %s is full: dropping transaction", f.domain)
 	}
 	return nil
@@ -220,3 +220,41 @@ func TestForwarderRetryLimitQueue(t *testing.T) {
 	// assert that the oldest transaction was dropped
 	assert.Equal(t, transaction2, forwarder.retryQueue[0])
 }
+
+func TestRotateAPIKeys(t *testing.T) {
+	forwarder := newDomainForwarder("test", 1, 10)
+	forwarder.init()
+
+	t1 := NewHTTPTransaction()
+	t1.Headers.Set("DD-Api-Key", "old-key")
+	t2 := NewHTTPTransaction()
+	t2.Endpoint = "/api/v1/check_run?api_key=old-key"
+	t2.Headers.Set("DD-Api-Key", "old-key")
+	t3 := NewHTTPTransaction()
+	t3.Headers.Set("DD-Api-Key", "other-key")
+	forwarder.requeueTransaction(t1)
+	forwarder.requeueTransaction(t2)
+	forwarder.requeueTransaction(t3)
+
+	forwarder.rotateAPIKeys(map[string]string{"old-key": "new-key"})
+	assert.Equal(t, "new-key", t1.Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "new-key", t2.Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "/api/v1/check_run?api_key=new-key", t2.Endpoint)
+	assert.Equal(t, "other-key", t3.Headers.Get("DD-Api-Key"))
+
+	// transactions sent with the old key are signed with the latest key when requeued
+	forwarder.rotateAPIKeys(map[string]string{"new-key": "newer-key"})
+	t4 := NewHTTPTransaction()
+	t4.Headers.Set("DD-Api-Key", "old-key")
+	forwarder.requeueTransaction(t4)
+	assert.Equal(t, "newer-key", t1.Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "newer-key", t4.Headers.Get("DD-Api-Key"))
+}
+
+func TestReplaceAPIKeysIfStopped(t *testing.T) {
+	forwarder := newDomainForwarder("test", 1, 10)
+	forwarder.init()
+
+	// shouldn't block
+	forwarder.replaceAPIKeys(map[string]string{"old-key": "new-key"})
+}
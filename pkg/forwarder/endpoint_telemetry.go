@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"expvar"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+// Outcomes of a transaction, as reported per endpoint and payload type
+const (
+	transactionSuccess = "Success"
+	transactionError   = "Errors" // retryable errors
+	transactionDropped = "Dropped"
+)
+
+var (
+	endpointsExpvars   = expvar.Map{}
+	endpointsTelemetry = map[string]*endpointTelemetry{}
+	endpointsMutex     sync.Mutex
+
+	// payloadTypes names the payloads sent to each endpoint, like the
+	// transactions counters of the forwarder
+	payloadTypes = map[string]string{
+		v1SeriesEndpoint:       "TimeseriesV1",
+		v1CheckRunsEndpoint:    "CheckRunsV1",
+		v1IntakeEndpoint:       "IntakeV1",
+		v1SketchSeriesEndpoint: "SketchSeriesV1",
+		seriesEndpoint:         "Series",
+		eventsEndpoint:         "Events",
+		serviceChecksEndpoint:  "ServiceChecks",
+		sketchSeriesEndpoint:   "SketchSeries",
+		hostMetadataEndpoint:   "HostMetadata",
+		metadataEndpoint:       "Metadata",
+	}
+
+	tlmTransactions   = telemetry.NewCounter("forwarder", "transactions", []string{"domain", "payload_type", "outcome"}, "Number of transactions, by domain, payload type and outcome.")
+	tlmHTTPStatus     = telemetry.NewCounter("forwarder", "http_requests", []string{"domain", "payload_type", "status_code"}, "Number of HTTP responses, by domain, payload type and status code.")
+	tlmRetryQueueSize = telemetry.NewGauge("forwarder", "retry_queue_size", []string{"domain"}, "Number of transactions in the retry queue, by domain.")
+)
+
+func initEndpointsExpvars() {
+	endpointsExpvars.Init()
+	forwarderExpvars.Set("Endpoints", &endpointsExpvars)
+}
+
+// payloadType returns the name of the payloads sent to an endpoint
+func payloadType(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	if name, found := payloadTypes[endpoint]; found {
+		return name
+	}
+	return "Other"
+}
+
+// endpointTelemetry holds the counters of the transactions sent to a domain,
+// per payload type, and the state of the retry queue of the domain. It's
+// exposed under `forwarder/Endpoints/<host>`.
+type endpointTelemetry struct {
+	m              sync.Mutex
+	host           string
+	expvars        expvar.Map
+	payloadTypes   expvar.Map
+	retryQueue     expvar.Map
+	retryQueueSize expvar.Int
+	oldestPending  int64 // creation time of the oldest transaction in the retry queue, in unix nanoseconds
+}
+
+// getEndpointTelemetry returns the telemetry of a domain, creating it if needed
+func getEndpointTelemetry(domain string) *endpointTelemetry {
+	key := domain
+	if u, err := url.Parse(domain); err == nil && u.Host != "" {
+		key = u.Host
+	}
+
+	endpointsMutex.Lock()
+	defer endpointsMutex.Unlock()
+
+	if e, found := endpointsTelemetry[key]; found {
+		return e
+	}
+	e := &endpointTelemetry{host: key}
+	e.expvars.Init()
+	e.payloadTypes.Init()
+	e.retryQueue.Init()
+	e.expvars.Set("PayloadTypes", &e.payloadTypes)
+	e.expvars.Set("RetryQueue", &e.retryQueue)
+	e.retryQueue.Set("Size", &e.retryQueueSize)
+	e.retryQueue.Set("OldestTransactionAge", expvar.Func(e.oldestPendingAge))
+	endpointsTelemetry[key] = e
+	endpointsExpvars.Set(key, &e.expvars)
+	return e
+}
+
+// oldestPendingAge returns for how long the oldest transaction of the retry
+// queue has been pending, in seconds.
+func (e *endpointTelemetry) oldestPendingAge() interface{} {
+	oldest := atomic.LoadInt64(&e.oldestPending)
+	if oldest == 0 {
+		return 0
+	}
+	return int64(time.Since(time.Unix(0, oldest)) / time.Second)
+}
+
+// payloadTypeExpvars returns the counters of a payload type
+func (e *endpointTelemetry) payloadTypeExpvars(name string) *expvar.Map {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if stats := e.payloadTypes.Get(name); stats != nil {
+		return stats.(*expvar.Map)
+	}
+	stats := &expvar.Map{}
+	stats.Init()
+	stats.Set(transactionSuccess, &expvar.Int{})
+	stats.Set(transactionError, &expvar.Int{})
+	stats.Set(transactionDropped, &expvar.Int{})
+	statusCodes := &expvar.Map{}
+	statusCodes.Init()
+	stats.Set("HTTPStatusByCode", statusCodes)
+	e.payloadTypes.Set(name, stats)
+	return stats
+}
+
+// record records the outcome of a transaction of a payload type
+func (e *endpointTelemetry) record(payloadType, outcome string) {
+	e.payloadTypeExpvars(payloadType).Add(outcome, 1)
+	tlmTransactions.Inc(e.host, payloadType, outcome)
+}
+
+// recordTransaction records the outcome of a transaction
+func (e *endpointTelemetry) recordTransaction(t Transaction, outcome string) {
+	if t == nil {
+		return
+	}
+	e.record(t.GetEndpointName(), outcome)
+}
+
+// recordStatusCode records the HTTP status code of the response to a transaction
+func (e *endpointTelemetry) recordStatusCode(payloadType string, statusCode int) {
+	e.payloadTypeExpvars(payloadType).Get("HTTPStatusByCode").(*expvar.Map).Add(strconv.Itoa(statusCode), 1)
+	tlmHTTPStatus.Inc(e.host, payloadType, strconv.Itoa(statusCode))
+}
+
+// setRetryQueueSize updates the size of the retry queue of the domain
+func (e *endpointTelemetry) setRetryQueueSize(size int) {
+	e.retryQueueSize.Set(int64(size))
+	tlmRetryQueueSize.Set(float64(size), e.host)
+}
+
+// setOldestPending updates the creation time of the oldest transaction of
+// the retry queue, the zero time if the queue is empty.
+func (e *endpointTelemetry) setOldestPending(createdAt time.Time) {
+	var oldest int64
+	if !createdAt.IsZero() {
+		oldest = createdAt.UnixNano()
+	}
+	atomic.StoreInt64(&e.oldestPending, oldest)
+}
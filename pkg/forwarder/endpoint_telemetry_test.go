@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadType(t *testing.T) {
+	assert.Equal(t, "Series", payloadType(seriesEndpoint))
+	assert.Equal(t, "TimeseriesV1", payloadType(v1SeriesEndpoint))
+	assert.Equal(t, "CheckRunsV1", payloadType(v1CheckRunsEndpoint+"?api_key=foo"))
+	assert.Equal(t, "Other", payloadType("/api/v42/unknown"))
+}
+
+func TestGetEndpointTelemetry(t *testing.T) {
+	e := getEndpointTelemetry("https://6-12-0-app.agent.datadoghq.com")
+	assert.True(t, e == getEndpointTelemetry("https://6-12-0-app.agent.datadoghq.com"))
+	assert.NotNil(t, endpointsExpvars.Get("6-12-0-app.agent.datadoghq.com"))
+
+	e.record("Series", transactionSuccess)
+	e.record("Series", transactionSuccess)
+	e.record("Series", transactionDropped)
+	e.recordStatusCode("Series", 200)
+
+	var stats map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(e.payloadTypes.String()), &stats))
+	assert.Equal(t, float64(2), stats["Series"]["Success"])
+	assert.Equal(t, float64(0), stats["Series"]["Errors"])
+	assert.Equal(t, float64(1), stats["Series"]["Dropped"])
+	assert.Equal(t, map[string]interface{}{"200": float64(1)}, stats["Series"]["HTTPStatusByCode"])
+}
+
+func TestEndpointTelemetryOldestPending(t *testing.T) {
+	e := getEndpointTelemetry("https://oldest.pending")
+	assert.Equal(t, 0, e.oldestPendingAge())
+
+	e.setOldestPending(time.Now().Add(-1 * time.Minute))
+	assert.Equal(t, int64(60), e.oldestPendingAge())
+
+	e.setOldestPending(time.Time{})
+	assert.Equal(t, 0, e.oldestPendingAge())
+}
+
+func TestProcessTelemetry(t *testing.T) {
+	statusCode := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	transaction := NewHTTPTransaction()
+	transaction.Domain = ts.URL
+	transaction.Endpoint = eventsEndpoint
+	payload := []byte("test payload")
+	transaction.Payload = &payload
+	client := &http.Client{}
+
+	transaction.Process(context.Background(), client)
+	statusCode = http.StatusServiceUnavailable
+	transaction.Process(context.Background(), client)
+	statusCode = http.StatusRequestEntityTooLarge
+	transaction.Process(context.Background(), client)
+
+	u, _ := url.Parse(ts.URL)
+	stats := endpointsExpvars.Get(u.Host).(*expvar.Map).Get("PayloadTypes").(*expvar.Map).Get("Events").(*expvar.Map)
+	assert.Equal(t, "1", stats.Get("Success").String())
+	assert.Equal(t, "1", stats.Get("Errors").String())
+	assert.Equal(t, "1", stats.Get("Dropped").String())
+	statusCodes := stats.Get("HTTPStatusByCode").(*expvar.Map)
+	assert.Equal(t, "1", statusCodes.Get("200").String())
+	assert.Equal(t, "1", statusCodes.Get("503").String())
+	assert.Equal(t, "1", statusCodes.Get("413").String())
+}
+
+func TestRetryTransactionsTelemetry(t *testing.T) {
+	forwarder := newDomainForwarder("https://retry.telemetry", 1, 10)
+	forwarder.init()
+	forwarder.retryQueueLimit = 1
+
+	t1 := NewHTTPTransaction()
+	t1.Domain = "https://retry.telemetry"
+	t1.Endpoint = seriesEndpoint
+	t2 := NewHTTPTransaction()
+	t2.Domain = "https://retry.telemetry"
+	t2.Endpoint = seriesEndpoint
+	t2.createdAt = time.Now().Add(-1 * time.Minute)
+	forwarder.blockedList.close(t1.GetTarget())
+	forwarder.blockedList.errorPerEndpoint[t1.GetTarget()].until = time.Now().Add(1 * time.Hour)
+
+	forwarder.requeueTransaction(t1)
+	forwarder.requeueTransaction(t2)
+	assert.Equal(t, int64(2), forwarder.telemetry.retryQueueSize.Value())
+
+	// the oldest transaction is dropped
+	forwarder.retryTransactions(time.Now())
+	assert.Equal(t, int64(1), forwarder.telemetry.retryQueueSize.Value())
+	assert.Equal(t, int64(0), forwarder.telemetry.oldestPendingAge())
+	assert.Equal(t, "1", forwarder.telemetry.payloadTypeExpvars("Series").Get("Dropped").String())
+}
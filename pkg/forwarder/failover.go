@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	failoverActive       = expvar.Int{}
+	failoverSwitches     = expvar.Int{}
+	failoverExpvars      = expvar.Map{}
+	failoverProbeTimeout = 10 * time.Second
+)
+
+func initFailoverExpvars() {
+	failoverExpvars.Init()
+	forwarderExpvars.Set("Failover", &failoverExpvars)
+	failoverExpvars.Set("Active", &failoverActive)
+	failoverExpvars.Set("Switches", &failoverSwitches)
+}
+
+// FailoverEventHandler receives the events emitted when the forwarder fails
+// over to its secondary domain, or fails back to its primary domain.
+type FailoverEventHandler func(e metrics.Event)
+
+// failover redirects the transactions of a primary domain to a secondary
+// domain after a number of consecutive transactions to the primary domain
+// failed, provided the secondary domain is healthy. While failed over, the
+// primary domain is probed and the transactions are switched back to it as
+// soon as it is healthy again.
+type failover struct {
+	primary       string
+	secondary     string
+	threshold     int32
+	probeInterval time.Duration
+
+	consecutiveFailures int32
+	active              int32
+	secondaryHealthy    int32
+
+	// probe checks whether a domain is healthy, it's replaced in tests
+	probe func(domain string) bool
+
+	handlerMutex sync.RWMutex
+	handler      FailoverEventHandler
+
+	apiKeyMutex sync.RWMutex
+	apiKey      string
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newFailover(primary, secondary, apiKey string, threshold int, probeInterval time.Duration) *failover {
+	if threshold < 1 {
+		threshold = 1
+	}
+	f := &failover{
+		primary:       primary,
+		secondary:     secondary,
+		apiKey:        apiKey,
+		threshold:     int32(threshold),
+		probeInterval: probeInterval,
+	}
+	f.probe = f.probeDomain
+	return f
+}
+
+// Start starts probing the domains
+func (f *failover) Start() {
+	f.stop = make(chan struct{})
+	f.stopped = make(chan struct{})
+	go f.probeLoop()
+}
+
+// Stop stops probing the domains
+func (f *failover) Stop() {
+	close(f.stop)
+	<-f.stopped
+}
+
+// setEventHandler sets the handler receiving the failover and failback events
+func (f *failover) setEventHandler(handler FailoverEventHandler) {
+	f.handlerMutex.Lock()
+	defer f.handlerMutex.Unlock()
+	f.handler = handler
+}
+
+// setAPIKey sets the api key used to probe the domains, after it was rotated
+func (f *failover) setAPIKey(apiKey string) {
+	f.apiKeyMutex.Lock()
+	defer f.apiKeyMutex.Unlock()
+	f.apiKey = apiKey
+}
+
+// isActive returns whether the transactions are redirected to the secondary domain
+func (f *failover) isActive() bool {
+	return atomic.LoadInt32(&f.active) == 1
+}
+
+// domain returns the domain a new transaction for the given domain should target
+func (f *failover) domain(domain string) string {
+	if domain == f.primary && f.isActive() {
+		return f.secondary
+	}
+	return domain
+}
+
+// report records the outcome of a transaction, failing over to the secondary
+// domain if too many consecutive transactions to the primary domain failed.
+func (f *failover) report(target string, success bool) {
+	if !strings.HasPrefix(target, f.primary) {
+		return
+	}
+	if success {
+		atomic.StoreInt32(&f.consecutiveFailures, 0)
+		return
+	}
+
+	failures := atomic.AddInt32(&f.consecutiveFailures, 1)
+	if failures < f.threshold || f.isActive() {
+		return
+	}
+	if atomic.LoadInt32(&f.secondaryHealthy) != 1 {
+		log.Warnf("%d consecutive transactions to %s failed but %s is not healthy, not failing over", failures, f.primary, f.secondary)
+		return
+	}
+	if atomic.CompareAndSwapInt32(&f.active, 0, 1) {
+		atomic.StoreInt32(&f.consecutiveFailures, 0)
+		failoverActive.Set(1)
+		failoverSwitches.Add(1)
+		log.Warnf("%d consecutive transactions to %s failed, failing over to %s", failures, f.primary, f.secondary)
+		f.sendEvent(metrics.Event{
+			Title:     fmt.Sprintf("Datadog Agent failed over to %s", util.SanitizeURL(f.secondary)),
+			Text:      fmt.Sprintf("%d consecutive transactions to %s failed, the Agent now sends its data to %s.", failures, util.SanitizeURL(f.primary), util.SanitizeURL(f.secondary)),
+			AlertType: metrics.EventAlertTypeWarning,
+		})
+	}
+}
+
+// failback switches the transactions back to the primary domain
+func (f *failover) failback() {
+	if atomic.CompareAndSwapInt32(&f.active, 1, 0) {
+		atomic.StoreInt32(&f.consecutiveFailures, 0)
+		failoverActive.Set(0)
+		failoverSwitches.Add(1)
+		log.Infof("%s is healthy again, failing back from %s", f.primary, f.secondary)
+		f.sendEvent(metrics.Event{
+			Title:     fmt.Sprintf("Datadog Agent failed back to %s", util.SanitizeURL(f.primary)),
+			Text:      fmt.Sprintf("%s is healthy again, the Agent stopped sending its data to %s.", util.SanitizeURL(f.primary), util.SanitizeURL(f.secondary)),
+			AlertType: metrics.EventAlertTypeSuccess,
+		})
+	}
+}
+
+func (f *failover) sendEvent(e metrics.Event) {
+	f.handlerMutex.RLock()
+	defer f.handlerMutex.RUnlock()
+
+	if f.handler == nil {
+		return
+	}
+	e.Ts = time.Now().Unix()
+	e.SourceTypeName = "System"
+	e.EventType = "Agent Failover"
+	e.AggregationKey = "agent_failover"
+	f.handler(e)
+}
+
+// probeLoop probes the secondary domain while not failed over, so that we
+// only fail over to a healthy domain, and the primary domain while failed over.
+func (f *failover) probeLoop() {
+	defer close(f.stopped)
+
+	ticker := time.NewTicker(f.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		if f.isActive() {
+			if f.probe(f.primary) {
+				f.failback()
+			}
+		} else {
+			healthy := int32(0)
+			if f.probe(f.secondary) {
+				healthy = 1
+			}
+			atomic.StoreInt32(&f.secondaryHealthy, healthy)
+		}
+
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeDomain queries the API key validation endpoint of a domain. The domain
+// is considered healthy if it answers with anything but a server error.
+func (f *failover) probeDomain(domain string) bool {
+	client := &http.Client{
+		Transport: util.CreateHTTPTransport(),
+		Timeout:   failoverProbeTimeout,
+	}
+
+	f.apiKeyMutex.RLock()
+	apiKey := f.apiKey
+	f.apiKeyMutex.RUnlock()
+
+	resp, err := client.Get(fmt.Sprintf("%s%s?api_key=%s", domain, v1ValidateEndpoint, apiKey))
+	if err != nil {
+		log.Debugf("Failover probe to %s failed: %s", util.SanitizeURL(domain), util.SanitizeURL(err.Error()))
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		log.Debugf("Failover probe to %s failed with status code %d", util.SanitizeURL(domain), resp.StatusCode)
+		return false
+	}
+	return true
+}
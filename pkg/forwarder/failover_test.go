@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestFailoverSwitch(t *testing.T) {
+	f := newFailover("https://primary", "https://secondary", "key", 3, time.Hour)
+	events := []metrics.Event{}
+	f.setEventHandler(func(e metrics.Event) { events = append(events, e) })
+
+	// the secondary domain is not known to be healthy yet
+	for i := 0; i < 5; i++ {
+		f.report("https://primary/api/v1/series", false)
+	}
+	assert.False(t, f.isActive())
+	assert.Equal(t, "https://primary", f.domain("https://primary"))
+
+	atomic.StoreInt32(&f.secondaryHealthy, 1)
+	atomic.StoreInt32(&f.consecutiveFailures, 0)
+
+	// a success resets the failure count, other domains are ignored
+	f.report("https://primary/api/v1/series", false)
+	f.report("https://primary/api/v1/series", false)
+	f.report("https://primary/api/v1/series", true)
+	f.report("https://primary/api/v1/series", false)
+	f.report("https://other/api/v1/series", false)
+	f.report("https://primary/api/v1/series", false)
+	assert.False(t, f.isActive())
+
+	f.report("https://primary/api/v1/series", false)
+	assert.True(t, f.isActive())
+	assert.Equal(t, "https://secondary", f.domain("https://primary"))
+	assert.Equal(t, "https://other", f.domain("https://other"))
+	require.Len(t, events, 1)
+	assert.Equal(t, metrics.EventAlertTypeWarning, events[0].AlertType)
+	assert.Equal(t, "agent_failover", events[0].AggregationKey)
+
+	f.failback()
+	assert.False(t, f.isActive())
+	assert.Equal(t, "https://primary", f.domain("https://primary"))
+	require.Len(t, events, 2)
+	assert.Equal(t, metrics.EventAlertTypeSuccess, events[1].AlertType)
+
+	// failing back twice is a no-op
+	f.failback()
+	assert.Len(t, events, 2)
+}
+
+func TestFailoverProbeLoop(t *testing.T) {
+	f := newFailover("https://primary", "https://secondary", "key", 1, 10*time.Millisecond)
+	var primaryHealthy int32
+	f.probe = func(domain string) bool {
+		if domain == "https://primary" {
+			return atomic.LoadInt32(&primaryHealthy) == 1
+		}
+		return true
+	}
+	f.Start()
+	defer f.Stop()
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&f.secondaryHealthy) == 1 })
+	f.report("https://primary/api/v1/series", false)
+	require.True(t, f.isActive())
+
+	// still failed over while the primary domain is unhealthy
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, f.isActive())
+
+	atomic.StoreInt32(&primaryHealthy, 1)
+	waitFor(t, func() bool { return !f.isActive() })
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.FailNow(t, "timeout waiting for condition")
+}
+
+func TestFailoverProbeDomain(t *testing.T) {
+	var status int32 = http.StatusForbidden
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, v1ValidateEndpoint, r.URL.Path)
+		assert.Equal(t, "key", r.URL.Query().Get("api_key"))
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer ts.Close()
+
+	f := newFailover("https://primary", ts.URL, "key", 1, time.Hour)
+	assert.True(t, f.probeDomain(ts.URL))
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	assert.False(t, f.probeDomain(ts.URL))
+}
+
+func TestNewDefaultForwarderWithFailover(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("dd_url", testDomain)
+	mockConfig.Set("forwarder_failover_domain", "https://secondary.bar")
+	defer mockConfig.Set("dd_url", "")
+	defer mockConfig.Set("forwarder_failover_domain", "")
+
+	forwarder := NewDefaultForwarder(keysPerDomains)
+	require.NotNil(t, forwarder.failover)
+	assert.Equal(t, testVersionDomain, forwarder.failover.primary)
+	assert.Equal(t, "https://secondary.bar", forwarder.failover.secondary)
+	assert.Equal(t, "api-key-1", forwarder.failover.apiKey)
+	require.Len(t, forwarder.domainForwarders, 2)
+	for _, df := range forwarder.domainForwarders {
+		assert.Equal(t, forwarder.failover, df.failover)
+	}
+
+	// new transactions target the secondary domain once failed over
+	atomic.StoreInt32(&forwarder.failover.active, 1)
+	p := []byte("A payload")
+	transactions := forwarder.createHTTPTransactions(seriesEndpoint, config.MetricsEndpointDataType, Payloads{&p}, false, make(http.Header))
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "https://secondary.bar", transactions[0].Domain)
+}
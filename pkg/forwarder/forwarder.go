@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -31,6 +32,7 @@ var (
 	transactionsTimeseriesV1  = expvar.Int{}
 	transactionsCheckRunsV1   = expvar.Int{}
 	transactionsIntakeV1      = expvar.Int{}
+	apiKeysUpdates            = expvar.Int{}
 )
 
 func init() {
@@ -48,6 +50,10 @@ func init() {
 	initDomainForwarderExpvars()
 	initTransactionExpvars()
 	initForwarderHealthExpvars()
+	initFailoverExpvars()
+	initBandwidthExpvars()
+	initEndpointsExpvars()
+	forwarderExpvars.Set("APIKeysUpdates", &apiKeysUpdates)
 }
 
 const (
@@ -76,6 +82,16 @@ const (
 	useragentHTTPHeaderKey = "User-Agent"
 )
 
+// v2Endpoints are the endpoints of the v2 intake, receiving protobuf payloads
+var v2Endpoints = map[string]bool{
+	seriesEndpoint:        true,
+	eventsEndpoint:        true,
+	serviceChecksEndpoint: true,
+	sketchSeriesEndpoint:  true,
+	hostMetadataEndpoint:  true,
+	metadataEndpoint:      true,
+}
+
 // Payloads is a slice of pointers to byte arrays, an alias for the slices of
 // payloads we pass into the forwarder
 type Payloads []*[]byte
@@ -85,7 +101,7 @@ type Forwarder interface {
 	Start() error
 	Stop()
 	SubmitV1Series(payload Payloads, extra http.Header) error
-	SubmitV1Intake(payload Payloads, extra http.Header) error
+	SubmitV1Intake(payload Payloads, dataType string, extra http.Header) error
 	SubmitV1CheckRuns(payload Payloads, extra http.Header) error
 	SubmitSeries(payload Payloads, extra http.Header) error
 	SubmitEvents(payload Payloads, extra http.Header) error
@@ -100,36 +116,249 @@ type DefaultForwarder struct {
 	// NumberOfWorkers Number of concurrent HTTP request made by the DefaultForwarder (default 4).
 	NumberOfWorkers int
 
-	domainForwarders map[string]*domainForwarder
-	keysPerDomains   map[string][]string
-	healthChecker    *forwarderHealth
-	internalState    uint32
-	m                sync.Mutex // To control Start/Stop races
+	domainForwarders      map[string]*domainForwarder
+	keysPerDomains        map[string][]string
+	keysPerDomainsPerType map[string]map[string][]string // data type -> domain -> api keys
+	keysMutex             sync.RWMutex                   // To update the api keys at runtime
+	v1OnlyDomains         map[string]bool                // the domains not supporting the v2 intake
+	useV2Series           bool                           // whether the series are sent to the v2 intake
+	failover              *failover
+	healthChecker         *forwarderHealth
+	internalState         uint32
+	m                     sync.Mutex // To control Start/Stop races
 }
 
 // NewDefaultForwarder returns a new DefaultForwarder.
 func NewDefaultForwarder(keysPerDomains map[string][]string) *DefaultForwarder {
+	return NewDefaultForwarderWithEndpointsPerType(keysPerDomains, nil)
+}
+
+// NewDefaultForwarderWithEndpointsPerType returns a new DefaultForwarder
+// also sending some data types (see config.EndpointDataTypes) to additional
+// endpoints with their own API keys.
+func NewDefaultForwarderWithEndpointsPerType(keysPerDomains map[string][]string, keysPerDomainsPerType map[string]map[string][]string) *DefaultForwarder {
 	f := &DefaultForwarder{
-		NumberOfWorkers:  config.Datadog.GetInt("forwarder_num_workers"),
-		domainForwarders: map[string]*domainForwarder{},
-		keysPerDomains:   map[string][]string{},
-		internalState:    Stopped,
-		healthChecker:    &forwarderHealth{keysPerDomains: keysPerDomains},
+		NumberOfWorkers:       config.Datadog.GetInt("forwarder_num_workers"),
+		domainForwarders:      map[string]*domainForwarder{},
+		keysPerDomains:        map[string][]string{},
+		keysPerDomainsPerType: map[string]map[string][]string{},
+		v1OnlyDomains:         map[string]bool{},
+		useV2Series:           config.Datadog.GetBool("use_v2_api.series"),
+		internalState:         Stopped,
+	}
+	for _, domain := range config.Datadog.GetStringSlice("v1_only_endpoints") {
+		domain, _ := config.AddAgentVersionToDomain(domain, "app")
+		f.v1OnlyDomains[domain] = true
+		log.Infof("%s only supports the v1 intake: it won't receive the sketches, nor the events, service checks and host metadata sent to the v2 intake", domain)
 	}
 	numWorkers := config.Datadog.GetInt("forwarder_num_workers")
 	retryQueueMaxSize := config.Datadog.GetInt("forwarder_retry_queue_max_size")
+	// the bandwidth limit applies to every domain at once
+	bandwidth := newBandwidthLimiterFromConfig()
+
+	// every domain has its own domainForwarder, and thus its own retry queue
+	addDomain := func(domain string) {
+		if _, found := f.domainForwarders[domain]; !found {
+			df := newDomainForwarder(domain, numWorkers, retryQueueMaxSize)
+			df.bandwidth = bandwidth
+			f.domainForwarders[domain] = df
+		}
+	}
 
+	f.keysPerDomains, f.keysPerDomainsPerType = versionedKeysPerDomains(keysPerDomains, keysPerDomainsPerType)
+	for domain := range f.allKeysPerDomains() {
+		addDomain(domain)
+	}
+
+	if secondary := config.Datadog.GetString("forwarder_failover_domain"); secondary != "" {
+		primary, _ := config.AddAgentVersionToDomain(config.GetMainInfraEndpoint(), "app")
+		secondary, _ = config.AddAgentVersionToDomain(secondary, "app")
+		if keys := f.keysPerDomains[primary]; len(keys) > 0 {
+			f.failover = newFailover(primary, secondary, keys[0],
+				config.Datadog.GetInt("forwarder_failover_threshold"),
+				config.Datadog.GetDuration("forwarder_failover_probe_interval")*time.Second)
+			addDomain(secondary)
+			for _, df := range f.domainForwarders {
+				df.failover = f.failover
+			}
+		} else {
+			log.Errorf("No API keys for the main domain '%s', disabling failover to '%s'", primary, secondary)
+		}
+	}
+
+	f.healthChecker = &forwarderHealth{keysPerDomains: f.allKeysPerDomains()}
+	return f
+}
+
+// versionedKeysPerDomains prefixes the domains with the agent version and
+// drops the domains with no api keys.
+func versionedKeysPerDomains(keysPerDomains map[string][]string, keysPerDomainsPerType map[string]map[string][]string) (map[string][]string, map[string]map[string][]string) {
+	versionedKeysPerDomains := map[string][]string{}
 	for domain, keys := range keysPerDomains {
 		domain, _ := config.AddAgentVersionToDomain(domain, "app")
 		if keys == nil || len(keys) == 0 {
 			log.Errorf("No API keys for domain '%s', dropping domain ", domain)
 		} else {
-			f.keysPerDomains[domain] = keys
-			f.domainForwarders[domain] = newDomainForwarder(domain, numWorkers, retryQueueMaxSize)
+			versionedKeysPerDomains[domain] = keys
 		}
 	}
 
-	return f
+	versionedKeysPerDomainsPerType := map[string]map[string][]string{}
+	for dataType, typeKeysPerDomains := range keysPerDomainsPerType {
+		for domain, keys := range typeKeysPerDomains {
+			domain, _ := config.AddAgentVersionToDomain(domain, "app")
+			if keys == nil || len(keys) == 0 {
+				log.Errorf("No API keys for domain '%s' (%s), dropping domain ", domain, dataType)
+				continue
+			}
+			if versionedKeysPerDomainsPerType[dataType] == nil {
+				versionedKeysPerDomainsPerType[dataType] = map[string][]string{}
+			}
+			versionedKeysPerDomainsPerType[dataType][domain] = keys
+		}
+	}
+	return versionedKeysPerDomains, versionedKeysPerDomainsPerType
+}
+
+// UpdateAPIKeys replaces the api keys of the endpoints, to rotate them
+// without restarting the agent. The transactions created from now on use the
+// new keys, the ones already handed to the workers are drained with the old
+// keys and the ones waiting to be retried are signed with the new keys. An old
+// key is replaced by the key at the same position for the same domain. The
+// domains themselves can't change at runtime.
+func (f *DefaultForwarder) UpdateAPIKeys(keysPerDomains map[string][]string, keysPerDomainsPerType map[string]map[string][]string) error {
+	newKeysPerDomains, newKeysPerDomainsPerType := versionedKeysPerDomains(keysPerDomains, keysPerDomainsPerType)
+	newKeys := mergeKeysPerDomains(newKeysPerDomains, newKeysPerDomainsPerType)
+	if len(newKeys) == 0 {
+		return fmt.Errorf("no API keys")
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for domain := range newKeys {
+		if _, found := f.domainForwarders[domain]; !found {
+			return fmt.Errorf("domain '%s' isn't configured, the domains can't change at runtime", domain)
+		}
+	}
+
+	f.keysMutex.Lock()
+	oldKeys := f.allKeysPerDomains()
+	f.keysPerDomains, f.keysPerDomainsPerType = newKeysPerDomains, newKeysPerDomainsPerType
+	f.keysMutex.Unlock()
+
+	replacements := apiKeyReplacements(oldKeys, newKeys)
+	if f.healthChecker != nil {
+		f.healthChecker.setKeysPerDomains(newKeys)
+	}
+	if f.failover != nil {
+		if keys := newKeys[f.failover.primary]; len(keys) > 0 {
+			f.failover.setAPIKey(keys[0])
+		}
+	}
+	if len(replacements) > 0 {
+		for _, df := range f.domainForwarders {
+			df.replaceAPIKeys(replacements)
+		}
+	}
+	apiKeysUpdates.Add(1)
+	log.Infof("Forwarder API keys updated, %d key(s) replaced", len(replacements))
+	return nil
+}
+
+// apiKeyReplacements maps the api keys no longer in use to the key replacing
+// them, the one at the same position for the same domain.
+func apiKeyReplacements(oldKeysPerDomains, newKeysPerDomains map[string][]string) map[string]string {
+	replacements := map[string]string{}
+	for domain, oldKeys := range oldKeysPerDomains {
+		newKeys := newKeysPerDomains[domain]
+		for i, oldKey := range oldKeys {
+			if i >= len(newKeys) || containsKey(newKeys, oldKey) {
+				continue
+			}
+			replacements[oldKey] = newKeys[i]
+		}
+	}
+	return replacements
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SetFailoverEventHandler sets the handler receiving the events emitted when
+// the forwarder fails over to `forwarder_failover_domain` and back.
+func (f *DefaultForwarder) SetFailoverEventHandler(handler FailoverEventHandler) {
+	if f.failover != nil {
+		f.failover.setEventHandler(handler)
+	}
+}
+
+// allKeysPerDomains returns every api key in use per domain, whatever the data type
+func (f *DefaultForwarder) allKeysPerDomains() map[string][]string {
+	return mergeKeysPerDomains(f.keysPerDomains, f.keysPerDomainsPerType)
+}
+
+func mergeKeysPerDomains(keysPerDomains map[string][]string, keysPerDomainsPerType map[string]map[string][]string) map[string][]string {
+	if len(keysPerDomainsPerType) == 0 {
+		return keysPerDomains
+	}
+	allKeysPerDomains := map[string][]string{}
+	for domain, keys := range keysPerDomains {
+		allKeysPerDomains[domain] = keys
+	}
+	for _, typeKeysPerDomains := range keysPerDomainsPerType {
+		for domain, keys := range typeKeysPerDomains {
+			allKeysPerDomains[domain] = appendMissingKeys(allKeysPerDomains[domain], keys)
+		}
+	}
+	return allKeysPerDomains
+}
+
+// keysPerDomainsForType returns the api keys per domain a data type is sent to.
+// Payloads with no data type (metadata) are sent to every endpoint, as any
+// org receiving data from this host needs its metadata.
+func (f *DefaultForwarder) keysPerDomainsForType(dataType string) map[string][]string {
+	if dataType == "" {
+		return f.allKeysPerDomains()
+	}
+	typeKeysPerDomains, found := f.keysPerDomainsPerType[dataType]
+	if !found {
+		return f.keysPerDomains
+	}
+
+	keysPerDomains := map[string][]string{}
+	for domain, keys := range f.keysPerDomains {
+		keysPerDomains[domain] = keys
+	}
+	for domain, keys := range typeKeysPerDomains {
+		keysPerDomains[domain] = appendMissingKeys(keysPerDomains[domain], keys)
+	}
+	return keysPerDomains
+}
+
+// appendMissingKeys appends to a list of api keys the ones it doesn't contain yet
+func appendMissingKeys(apiKeys []string, newKeys []string) []string {
+	result := make([]string, len(apiKeys), len(apiKeys)+len(newKeys))
+	copy(result, apiKeys)
+	for _, key := range newKeys {
+		found := false
+		for _, k := range result {
+			if k == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, key)
+		}
+	}
+	return result
 }
 
 // Start initialize and runs the forwarder.
@@ -145,6 +374,10 @@ func (f *DefaultForwarder) Start() error {
 	for _, df := range f.domainForwarders {
 		df.Start()
 	}
+	if f.failover != nil {
+		f.failover.Start()
+		log.Infof("Forwarder failover enabled from %s to %s", f.failover.primary, f.failover.secondary)
+	}
 
 	// log endpoints configuration
 	endpointLogs := make([]string, 0, len(f.keysPerDomains))
@@ -152,6 +385,12 @@ func (f *DefaultForwarder) Start() error {
 		endpointLogs = append(endpointLogs, fmt.Sprintf("\"%s\" (%v api key(s))",
 			domain, len(apiKeys)))
 	}
+	for dataType, typeKeysPerDomains := range f.keysPerDomainsPerType {
+		for domain, apiKeys := range typeKeysPerDomains {
+			endpointLogs = append(endpointLogs, fmt.Sprintf("\"%s\" (%v api key(s), %s only)",
+				domain, len(apiKeys), dataType))
+		}
+	}
 	log.Infof("Forwarder started, sending to %v endpoint(s) with %v worker(s) each: %s",
 		len(endpointLogs), f.NumberOfWorkers, strings.Join(endpointLogs, " ; "))
 
@@ -176,6 +415,9 @@ func (f *DefaultForwarder) Stop() {
 	for _, df := range f.domainForwarders {
 		df.Stop()
 	}
+	if f.failover != nil {
+		f.failover.Stop()
+	}
 
 	f.healthChecker.Stop()
 	f.healthChecker = nil
@@ -191,10 +433,29 @@ func (f *DefaultForwarder) State() uint32 {
 	return f.internalState
 }
 
-func (f *DefaultForwarder) createHTTPTransactions(endpoint string, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
+// acceptsEndpoint returns whether a domain receives the payloads of an
+// endpoint. The domains listed in v1_only_endpoints don't receive the
+// payloads of the v2 intake, and receive the series in JSON on the v1 endpoint
+// instead when they're sent in protobuf to the other domains.
+func (f *DefaultForwarder) acceptsEndpoint(domain string, endpoint string) bool {
+	if f.v1OnlyDomains[domain] {
+		return !v2Endpoints[endpoint]
+	}
+	return endpoint != v1SeriesEndpoint || !f.useV2Series
+}
+
+// createHTTPTransactions creates the transactions sending the payloads to every
+// domain the data type is sent to.
+func (f *DefaultForwarder) createHTTPTransactions(endpoint string, dataType string, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
 	transactions := []*HTTPTransaction{}
+	f.keysMutex.RLock()
+	keysPerDomains := f.keysPerDomainsForType(dataType)
+	f.keysMutex.RUnlock()
 	for _, payload := range payloads {
-		for domain, apiKeys := range f.keysPerDomains {
+		for domain, apiKeys := range keysPerDomains {
+			if !f.acceptsEndpoint(domain, endpoint) {
+				continue
+			}
 			for _, apiKey := range apiKeys {
 				transactionEndpoint := endpoint
 				if apiKeyInQueryString {
@@ -202,6 +463,9 @@ func (f *DefaultForwarder) createHTTPTransactions(endpoint string, payloads Payl
 				}
 				t := NewHTTPTransaction()
 				t.Domain = domain
+				if f.failover != nil {
+					t.Domain = f.failover.domain(domain)
+				}
 				t.Endpoint = transactionEndpoint
 				t.Payload = payload
 				t.Headers.Set(apiHTTPHeaderKey, apiKey)
@@ -233,42 +497,42 @@ func (f *DefaultForwarder) sendHTTPTransactions(transactions []*HTTPTransaction)
 
 // SubmitSeries will send a series type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitSeries(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(seriesEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactions(seriesEndpoint, config.MetricsEndpointDataType, payload, false, extra)
 	transactionsSeries.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitEvents will send an event type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitEvents(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(eventsEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactions(eventsEndpoint, config.EventsEndpointDataType, payload, false, extra)
 	transactionsEvents.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitServiceChecks will send a service check type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitServiceChecks(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(serviceChecksEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactions(serviceChecksEndpoint, config.ServiceChecksEndpointDataType, payload, false, extra)
 	transactionsServiceChecks.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitSketchSeries will send payloads to Datadog backend - PROTOTYPE FOR PERCENTILE
 func (f *DefaultForwarder) SubmitSketchSeries(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(sketchSeriesEndpoint, payload, true, extra)
+	transactions := f.createHTTPTransactions(sketchSeriesEndpoint, config.MetricsEndpointDataType, payload, true, extra)
 	transactionsSketchSeries.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitHostMetadata will send a host_metadata tag type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitHostMetadata(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(hostMetadataEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactions(hostMetadataEndpoint, "", payload, false, extra)
 	transactionsHostMetadata.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
 
 // SubmitMetadata will send a metadata type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitMetadata(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(metadataEndpoint, payload, false, extra)
+	transactions := f.createHTTPTransactions(metadataEndpoint, "", payload, false, extra)
 	transactionsMetadata.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
@@ -276,7 +540,7 @@ func (f *DefaultForwarder) SubmitMetadata(payload Payloads, extra http.Header) e
 // SubmitV1Series will send timeserie to v1 endpoint (this will be remove once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(v1SeriesEndpoint, payload, true, extra)
+	transactions := f.createHTTPTransactions(v1SeriesEndpoint, config.MetricsEndpointDataType, payload, true, extra)
 	transactionsTimeseriesV1.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
@@ -284,14 +548,16 @@ func (f *DefaultForwarder) SubmitV1Series(payload Payloads, extra http.Header) e
 // SubmitV1CheckRuns will send service checks to v1 endpoint (this will be removed once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1CheckRuns(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(v1CheckRunsEndpoint, payload, true, extra)
+	transactions := f.createHTTPTransactions(v1CheckRunsEndpoint, config.ServiceChecksEndpointDataType, payload, true, extra)
 	transactionsCheckRunsV1.Add(1)
 	return f.sendHTTPTransactions(transactions)
 }
 
-// SubmitV1Intake will send payloads to the universal `/intake/` endpoint used by Agent v.5
-func (f *DefaultForwarder) SubmitV1Intake(payload Payloads, extra http.Header) error {
-	transactions := f.createHTTPTransactions(v1IntakeEndpoint, payload, true, extra)
+// SubmitV1Intake will send payloads to the universal `/intake/` endpoint used by Agent v.5.
+// The data type selects the additional endpoints receiving the payloads, the
+// metadata (with no data type) is sent to all of them.
+func (f *DefaultForwarder) SubmitV1Intake(payload Payloads, dataType string, extra http.Header) error {
+	transactions := f.createHTTPTransactions(v1IntakeEndpoint, dataType, payload, true, extra)
 
 	// the intake endpoint requires the Content-Type header to be set
 	for _, t := range transactions {
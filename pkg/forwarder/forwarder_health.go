@@ -9,6 +9,7 @@ import (
 	"expvar"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -46,6 +47,14 @@ type forwarderHealth struct {
 	stopped        chan struct{}
 	timeout        time.Duration
 	keysPerDomains map[string][]string
+	keysMutex      sync.RWMutex
+}
+
+// setKeysPerDomains sets the api keys to validate, after they were rotated
+func (fh *forwarderHealth) setKeysPerDomains(keysPerDomains map[string][]string) {
+	fh.keysMutex.Lock()
+	defer fh.keysMutex.Unlock()
+	fh.keysPerDomains = keysPerDomains
 }
 
 func (fh *forwarderHealth) init() {
@@ -152,7 +161,11 @@ func (fh *forwarderHealth) hasValidAPIKey() bool {
 	validKey := false
 	apiError := false
 
-	for domain, apiKeys := range fh.keysPerDomains {
+	fh.keysMutex.RLock()
+	keysPerDomains := fh.keysPerDomains
+	fh.keysMutex.RUnlock()
+
+	for domain, apiKeys := range keysPerDomains {
 		for _, apiKey := range apiKeys {
 			v, err := fh.validateAPIKey(apiKey, domain)
 			if err != nil {
@@ -47,6 +47,81 @@ func TestNewDefaultForwarder(t *testing.T) {
 	assert.Equal(t, forwarder.State(), forwarder.internalState)
 }
 
+func TestNewDefaultForwarderWithEndpointsPerType(t *testing.T) {
+	forwarder := NewDefaultForwarderWithEndpointsPerType(keysPerDomains, map[string]map[string][]string{
+		config.MetricsEndpointDataType: {
+			testDomain:            {"api-key-2", "api-key-3"},
+			"https://staging.bar": {"staging-key"},
+		},
+		config.EventsEndpointDataType: {
+			"https://empty.bar": {},
+		},
+	})
+
+	// one retry queue per domain
+	require.Len(t, forwarder.domainForwarders, 2)
+	assert.Contains(t, forwarder.domainForwarders, testVersionDomain)
+	assert.Contains(t, forwarder.domainForwarders, "https://staging.bar")
+	assert.Equal(t, validKeysPerDomain, forwarder.keysPerDomains)
+	assert.Len(t, forwarder.keysPerDomainsPerType, 1)
+
+	metricsKeys := forwarder.keysPerDomainsForType(config.MetricsEndpointDataType)
+	assert.Equal(t, map[string][]string{
+		testVersionDomain:     {"api-key-1", "api-key-2", "api-key-3"},
+		"https://staging.bar": {"staging-key"},
+	}, metricsKeys)
+	assert.Equal(t, validKeysPerDomain, forwarder.keysPerDomainsForType(config.EventsEndpointDataType))
+	// metadata is sent everywhere
+	assert.Equal(t, metricsKeys, forwarder.keysPerDomainsForType(""))
+	assert.Equal(t, metricsKeys, forwarder.healthChecker.keysPerDomains)
+	// the main keys are left untouched
+	assert.Equal(t, []string{"api-key-1", "api-key-2"}, forwarder.keysPerDomains[testVersionDomain])
+}
+
+func TestCreateHTTPTransactionsPerType(t *testing.T) {
+	forwarder := NewDefaultForwarderWithEndpointsPerType(monoKeysDomains, map[string]map[string][]string{
+		config.EventsEndpointDataType: {
+			"https://staging.bar": {"staging-key"},
+		},
+	})
+	p1 := []byte("A payload")
+	payloads := Payloads{&p1}
+
+	transactions := forwarder.createHTTPTransactions(seriesEndpoint, config.MetricsEndpointDataType, payloads, false, make(http.Header))
+	require.Len(t, transactions, 1)
+	assert.Equal(t, testVersionDomain, transactions[0].Domain)
+
+	transactions = forwarder.createHTTPTransactions(eventsEndpoint, config.EventsEndpointDataType, payloads, false, make(http.Header))
+	require.Len(t, transactions, 2)
+	domains := []string{transactions[0].Domain, transactions[1].Domain}
+	assert.ElementsMatch(t, []string{testVersionDomain, "https://staging.bar"}, domains)
+	for _, tr := range transactions {
+		if tr.Domain == "https://staging.bar" {
+			assert.Equal(t, "staging-key", tr.Headers.Get("DD-Api-Key"))
+		}
+	}
+}
+
+func TestCreateHTTPTransactionsV1IntakePerType(t *testing.T) {
+	forwarder := NewDefaultForwarderWithEndpointsPerType(monoKeysDomains, map[string]map[string][]string{
+		config.MetricsEndpointDataType: {
+			"https://metrics.bar": {"metrics-key"},
+		},
+	})
+	p1 := []byte("A payload")
+	payloads := Payloads{&p1}
+
+	// the events aren't sent to the metrics only endpoints
+	transactions := forwarder.createHTTPTransactions(v1IntakeEndpoint, config.EventsEndpointDataType, payloads, true, make(http.Header))
+	require.Len(t, transactions, 1)
+	assert.Equal(t, testVersionDomain, transactions[0].Domain)
+
+	// the metadata is
+	transactions = forwarder.createHTTPTransactions(v1IntakeEndpoint, "", payloads, true, make(http.Header))
+	require.Len(t, transactions, 2)
+	assert.ElementsMatch(t, []string{testVersionDomain, "https://metrics.bar"}, []string{transactions[0].Domain, transactions[1].Domain})
+}
+
 func TestStart(t *testing.T) {
 	forwarder := NewDefaultForwarder(monoKeysDomains)
 	err := forwarder.Start()
@@ -82,7 +157,7 @@ func TestSubmitIfStopped(t *testing.T) {
 	assert.NotNil(t, forwarder.SubmitHostMetadata(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitMetadata(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitV1Series(nil, make(http.Header)))
-	assert.NotNil(t, forwarder.SubmitV1Intake(nil, make(http.Header)))
+	assert.NotNil(t, forwarder.SubmitV1Intake(nil, config.EventsEndpointDataType, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitV1CheckRuns(nil, make(http.Header)))
 }
 
@@ -95,7 +170,7 @@ func TestCreateHTTPTransactions(t *testing.T) {
 	headers := make(http.Header)
 	headers.Set("HTTP-MAGIC", "foo")
 
-	transactions := forwarder.createHTTPTransactions(endpoint, payloads, false, headers)
+	transactions := forwarder.createHTTPTransactions(endpoint, config.MetricsEndpointDataType, payloads, false, headers)
 	require.Len(t, transactions, 4)
 	assert.Equal(t, testVersionDomain, transactions[0].Domain)
 	assert.Equal(t, testVersionDomain, transactions[1].Domain)
@@ -115,7 +190,7 @@ func TestCreateHTTPTransactions(t *testing.T) {
 	assert.Equal(t, p2, *(transactions[2].Payload))
 	assert.Equal(t, p2, *(transactions[3].Payload))
 
-	transactions = forwarder.createHTTPTransactions(endpoint, payloads, true, headers)
+	transactions = forwarder.createHTTPTransactions(endpoint, config.MetricsEndpointDataType, payloads, true, headers)
 	require.Len(t, transactions, 4)
 	assert.Contains(t, transactions[0].Endpoint, "api_key=api-key-1")
 	assert.Contains(t, transactions[1].Endpoint, "api_key=api-key-2")
@@ -123,13 +198,46 @@ func TestCreateHTTPTransactions(t *testing.T) {
 	assert.Contains(t, transactions[3].Endpoint, "api_key=api-key-2")
 }
 
+func TestCreateHTTPTransactionsV1OnlyEndpoints(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("v1_only_endpoints", []string{"https://custom.intake"})
+	defer mockConfig.Set("v1_only_endpoints", []string{})
+
+	domains := func(transactions []*HTTPTransaction) []string {
+		var result []string
+		for _, t := range transactions {
+			result = append(result, t.Domain)
+		}
+		return result
+	}
+	p := []byte("A payload")
+	payloads := Payloads{&p}
+	keys := map[string][]string{
+		testDomain:              {"api-key-1"},
+		"https://custom.intake": {"api-key-2"},
+	}
+
+	forwarder := NewDefaultForwarder(keys)
+	assert.Equal(t, []string{testVersionDomain}, domains(forwarder.createHTTPTransactions(sketchSeriesEndpoint, config.MetricsEndpointDataType, payloads, true, nil)))
+	assert.ElementsMatch(t, []string{testVersionDomain, "https://custom.intake"}, domains(forwarder.createHTTPTransactions(v1SeriesEndpoint, config.MetricsEndpointDataType, payloads, true, nil)))
+	assert.ElementsMatch(t, []string{testVersionDomain, "https://custom.intake"}, domains(forwarder.createHTTPTransactions(v1CheckRunsEndpoint, config.ServiceChecksEndpointDataType, payloads, true, nil)))
+
+	// the series are sent in JSON to the v1 only endpoints, in protobuf to the others
+	mockConfig.Set("use_v2_api.series", true)
+	defer mockConfig.Set("use_v2_api.series", false)
+	forwarder = NewDefaultForwarder(keys)
+	assert.Equal(t, []string{testVersionDomain}, domains(forwarder.createHTTPTransactions(seriesEndpoint, config.MetricsEndpointDataType, payloads, false, nil)))
+	assert.Equal(t, []string{"https://custom.intake"}, domains(forwarder.createHTTPTransactions(v1SeriesEndpoint, config.MetricsEndpointDataType, payloads, true, nil)))
+	assert.Equal(t, []string{testVersionDomain}, domains(forwarder.createHTTPTransactions(sketchSeriesEndpoint, config.MetricsEndpointDataType, payloads, true, nil)))
+}
+
 func TestSendHTTPTransactions(t *testing.T) {
 	forwarder := NewDefaultForwarder(keysPerDomains)
 	endpoint := "/api/foo"
 	p1 := []byte("A payload")
 	payloads := Payloads{&p1}
 	headers := make(http.Header)
-	tr := forwarder.createHTTPTransactions(endpoint, payloads, false, headers)
+	tr := forwarder.createHTTPTransactions(endpoint, config.MetricsEndpointDataType, payloads, false, headers)
 
 	// fw is stopped, we should get an error
 	err := forwarder.sendHTTPTransactions(tr)
@@ -156,7 +264,7 @@ func TestSubmitV1Intake(t *testing.T) {
 	defer func() { df.highPrio = bk }()
 
 	p := []byte("test")
-	assert.Nil(t, forwarder.SubmitV1Intake(Payloads{&p}, make(http.Header)))
+	assert.Nil(t, forwarder.SubmitV1Intake(Payloads{&p}, config.EventsEndpointDataType, make(http.Header)))
 
 	select {
 	case tr := <-df.highPrio:
@@ -201,7 +309,7 @@ func TestForwarderEndtoEnd(t *testing.T) {
 	headers.Set("key", "value")
 
 	assert.Nil(t, f.SubmitV1Series(payload, headers))
-	assert.Nil(t, f.SubmitV1Intake(payload, headers))
+	assert.Nil(t, f.SubmitV1Intake(payload, config.EventsEndpointDataType, headers))
 	assert.Nil(t, f.SubmitV1CheckRuns(payload, headers))
 	assert.Nil(t, f.SubmitSeries(payload, headers))
 	assert.Nil(t, f.SubmitEvents(payload, headers))
@@ -219,3 +327,58 @@ func TestForwarderEndtoEnd(t *testing.T) {
 	ts.Close()
 	assert.Equal(t, int64(38), requests)
 }
+
+func TestUpdateAPIKeys(t *testing.T) {
+	forwarder := NewDefaultForwarder(keysPerDomains)
+	p1 := []byte("A payload")
+	payloads := Payloads{&p1}
+
+	err := forwarder.UpdateAPIKeys(map[string][]string{
+		testDomain: {"api-key-1", "api-key-3"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{testVersionDomain: {"api-key-1", "api-key-3"}}, forwarder.keysPerDomains)
+	assert.Equal(t, forwarder.keysPerDomains, forwarder.healthChecker.keysPerDomains)
+
+	transactions := forwarder.createHTTPTransactions("/api/foo", config.MetricsEndpointDataType, payloads, true, make(http.Header))
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "api-key-1", transactions[0].Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "/api/foo?api_key=api-key-1", transactions[0].Endpoint)
+	assert.Equal(t, "api-key-3", transactions[1].Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "/api/foo?api_key=api-key-3", transactions[1].Endpoint)
+}
+
+func TestUpdateAPIKeysErrors(t *testing.T) {
+	forwarder := NewDefaultForwarder(keysPerDomains)
+
+	// no keys
+	assert.NotNil(t, forwarder.UpdateAPIKeys(map[string][]string{testDomain: {}}, nil))
+	// the domains can't change
+	assert.NotNil(t, forwarder.UpdateAPIKeys(map[string][]string{"https://other.bar": {"api-key-3"}}, nil))
+	assert.Equal(t, validKeysPerDomain, forwarder.keysPerDomains)
+}
+
+func TestAPIKeyReplacements(t *testing.T) {
+	replacements := apiKeyReplacements(map[string][]string{
+		"domain1": {"key-1", "key-2", "key-3"},
+		"domain2": {"key-4"},
+	}, map[string][]string{
+		"domain1": {"key-2", "key-5"},
+		"domain2": {"key-6"},
+	})
+
+	// key-2 is still in use and key-3 has no replacement
+	assert.Equal(t, map[string]string{
+		"key-1": "key-2",
+		"key-4": "key-6",
+	}, replacements)
+}
+
+func TestUpdateAPIKeysStarted(t *testing.T) {
+	forwarder := NewDefaultForwarder(monoKeysDomains)
+	require.NoError(t, forwarder.Start())
+	defer forwarder.Stop()
+
+	require.NoError(t, forwarder.UpdateAPIKeys(map[string][]string{testDomain: {"newkey"}}, nil))
+	assert.Equal(t, map[string][]string{testVersionDomain: {"newkey"}}, forwarder.keysPerDomains)
+}
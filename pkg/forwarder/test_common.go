@@ -37,6 +37,14 @@ func (t *testTransaction) GetTarget() string {
 	return t.Called().Get(0).(string)
 }
 
+func (t *testTransaction) GetEndpointName() string {
+	return "" // not mocked, it's only used for the telemetry
+}
+
+func (t *testTransaction) GetPayloadSize() int {
+	return t.Called().Get(0).(int)
+}
+
 // MockedForwarder a mocked forwarder to be use in other module to test their dependencies with the forwarder
 type MockedForwarder struct {
 	mock.Mock
@@ -58,8 +66,8 @@ func (tf *MockedForwarder) SubmitV1Series(payload Payloads, extra http.Header) e
 }
 
 // SubmitV1Intake updates the internal mock struct
-func (tf *MockedForwarder) SubmitV1Intake(payload Payloads, extra http.Header) error {
-	return tf.Called(payload, extra).Error(0)
+func (tf *MockedForwarder) SubmitV1Intake(payload Payloads, dataType string, extra http.Header) error {
+	return tf.Called(payload, dataType, extra).Error(0)
 }
 
 // SubmitV1CheckRuns updates the internal mock struct
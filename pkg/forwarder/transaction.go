@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"net/http/httptrace"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -94,7 +95,8 @@ type HTTPTransaction struct {
 	// ErrorCount is the number of times this HTTPTransaction failed to be processed.
 	ErrorCount int
 
-	createdAt time.Time
+	createdAt  time.Time
+	statusCode int // status code of the last response, 0 if none
 }
 
 // Transaction represents the task to process for a Worker.
@@ -102,6 +104,8 @@ type Transaction interface {
 	Process(ctx context.Context, client *http.Client) error
 	GetCreatedAt() time.Time
 	GetTarget() string
+	GetPayloadSize() int
+	GetEndpointName() string
 }
 
 // NewHTTPTransaction returns a new HTTPTransaction.
@@ -124,21 +128,57 @@ func (t *HTTPTransaction) GetTarget() string {
 	return util.SanitizeURL(url) // sanitized url that can be logged
 }
 
+// GetPayloadSize returns the size of the payload of the transaction, in bytes
+func (t *HTTPTransaction) GetPayloadSize() int {
+	if t.Payload == nil {
+		return 0
+	}
+	return len(*t.Payload)
+}
+
+// isClientError returns whether the last response to the transaction was a
+// 4xx, the transaction is then dropped rather than retried.
+func (t *HTTPTransaction) isClientError() bool {
+	return t.statusCode >= 400 && t.statusCode < 500
+}
+
+// GetEndpointName returns the name of the payloads sent by the transaction
+func (t *HTTPTransaction) GetEndpointName() string {
+	return payloadType(t.Endpoint)
+}
+
+// replaceAPIKey signs the transaction with a new api key if its api key was
+// replaced, returning whether it was.
+func (t *HTTPTransaction) replaceAPIKey(replacements map[string]string) bool {
+	apiKey := t.Headers.Get(apiHTTPHeaderKey)
+	newAPIKey, found := replacements[apiKey]
+	if !found {
+		return false
+	}
+	t.Headers.Set(apiHTTPHeaderKey, newAPIKey)
+	t.Endpoint = strings.Replace(t.Endpoint, "api_key="+apiKey, "api_key="+newAPIKey, 1)
+	return true
+}
+
 // Process sends the Payload of the transaction to the right Endpoint and Domain.
 func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) error {
 	reader := bytes.NewReader(*t.Payload)
 	url := t.Domain + t.Endpoint
 	logURL := util.SanitizeURL(url) // sanitized url that can be logged
+	telemetry := getEndpointTelemetry(t.Domain)
+	payloadType := t.GetEndpointName()
 
 	req, err := http.NewRequest("POST", url, reader)
 	if err != nil {
 		log.Errorf("Could not create request for transaction to invalid URL %q (dropping transaction): %s", logURL, err)
 		transactionsErrors.Add(1)
 		transactionsSentRequestErrors.Add(1)
+		telemetry.record(payloadType, transactionDropped)
 		return nil
 	}
 	req = req.WithContext(ctx)
 	req.Header = t.Headers
+	t.statusCode = 0
 	resp, err := client.Do(req)
 
 	if err != nil {
@@ -148,6 +188,7 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 		}
 		t.ErrorCount++
 		transactionsErrors.Add(1)
+		telemetry.record(payloadType, transactionError)
 		return fmt.Errorf("error while sending transaction, rescheduling it: %s", util.SanitizeURL(err.Error()))
 	}
 	defer resp.Body.Close()
@@ -158,6 +199,8 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 		return err
 	}
 
+	t.statusCode = resp.StatusCode
+	telemetry.recordStatusCode(payloadType, resp.StatusCode)
 	if resp.StatusCode >= 400 {
 		statusCode := strconv.Itoa(resp.StatusCode)
 		var codeCount *expvar.Int
@@ -174,18 +217,22 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 	if resp.StatusCode == 400 || resp.StatusCode == 404 || resp.StatusCode == 413 {
 		log.Errorf("Error code %q received while sending transaction to %q: %s, dropping it", resp.Status, logURL, string(body))
 		transactionsDropped.Add(1)
+		telemetry.record(payloadType, transactionDropped)
 		return nil
 	} else if resp.StatusCode == 403 {
 		log.Errorf("API Key invalid, dropping transaction for %s", logURL)
 		transactionsDropped.Add(1)
+		telemetry.record(payloadType, transactionDropped)
 		return nil
 	} else if resp.StatusCode > 400 {
 		t.ErrorCount++
 		transactionsErrors.Add(1)
+		telemetry.record(payloadType, transactionError)
 		return fmt.Errorf("error %q while sending transaction to %q, rescheduling it", resp.Status, logURL)
 	}
 
 	transactionsSuccessful.Add(1)
+	telemetry.record(payloadType, transactionSuccess)
 
 	loggingFrequency := config.Datadog.GetInt64("logging_frequency")
 
@@ -34,6 +34,9 @@ type Worker struct {
 	stopChan    chan bool
 	stopped     chan struct{}
 	blockedList *blockedEndpoints
+	failover    *failover
+	bandwidth   *bandwidthLimiter
+	telemetry   *endpointTelemetry
 }
 
 // NewWorker returns a new worker to consume Transaction from inputChan
@@ -128,6 +131,9 @@ func (w *Worker) process(ctx context.Context, t Transaction) {
 		case w.RequeueChan <- t:
 		default:
 			log.Errorf("dropping transaction because the retry goroutine is too busy to handle another one")
+			if w.telemetry != nil {
+				w.telemetry.recordTransaction(t, transactionDropped)
+			}
 		}
 	}
 
@@ -136,11 +142,41 @@ func (w *Worker) process(ctx context.Context, t Transaction) {
 	if w.blockedList.isBlock(target) {
 		requeue()
 		log.Errorf("Too many errors for endpoint '%s': retrying later", target)
+	} else if err := w.waitForBandwidth(ctx, t); err != nil {
+		requeue()
+		log.Debugf("Transaction to %s canceled while waiting for bandwidth: %s", target, err)
 	} else if err := t.Process(ctx, w.Client); err != nil {
 		w.blockedList.close(target)
+		w.reportToFailover(target, false)
 		requeue()
 		log.Errorf("Error while processing transaction: %v", err)
+	} else if isClientError(t) {
+		w.blockedList.clientError(target)
+		// the endpoint is reachable, failing over won't help
+		w.reportToFailover(target, true)
 	} else {
 		w.blockedList.recover(target)
+		w.reportToFailover(target, true)
+	}
+}
+
+// isClientError returns whether the last response to the transaction was a 4xx
+func isClientError(t Transaction) bool {
+	httpTransaction, ok := t.(*HTTPTransaction)
+	return ok && httpTransaction.isClientError()
+}
+
+// waitForBandwidth blocks until the payload of the transaction can be sent
+// without exceeding the bandwidth limit, if any.
+func (w *Worker) waitForBandwidth(ctx context.Context, t Transaction) error {
+	if w.bandwidth == nil {
+		return nil
+	}
+	return w.bandwidth.wait(ctx, t.GetPayloadSize())
+}
+
+func (w *Worker) reportToFailover(target string, success bool) {
+	if w.failover != nil {
+		w.failover.report(target, success)
 	}
 }
@@ -6,8 +6,10 @@
 package forwarder
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -109,3 +111,58 @@ func TestWorkerRetryBlockedTransaction(t *testing.T) {
 	assert.Equal(t, mock, retryTransaction)
 	assert.True(t, w.blockedList.isBlock("error_url"))
 }
+
+func TestWorkerBandwidthLimit(t *testing.T) {
+	highPrio := make(chan Transaction)
+	lowPrio := make(chan Transaction)
+	requeue := make(chan Transaction, 1)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+	w.bandwidth = newBandwidthLimiter(1000, 100)
+
+	mock := newTestTransaction()
+	mock.On("Process", w.Client).Return(nil).Times(2)
+	mock.On("GetTarget").Return("").Times(2)
+	mock.On("GetPayloadSize").Return(100).Times(2)
+
+	w.Start()
+	defer w.Stop()
+
+	start := time.Now()
+	highPrio <- mock
+	<-mock.processed
+	highPrio <- mock
+	<-mock.processed
+
+	// the second payload had to wait for the first one to be "sent"
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+	mock.AssertExpectations(t)
+}
+
+func TestWorkerClientErrorCircuitBreaker(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("forwarder_4xx_circuit_breaker_threshold", 2)
+	defer mockConfig.Set("forwarder_4xx_circuit_breaker_threshold", 0)
+
+	highPrio := make(chan Transaction)
+	lowPrio := make(chan Transaction)
+	requeue := make(chan Transaction, 1)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+
+	transaction := NewHTTPTransaction()
+	transaction.Domain = ts.URL
+	transaction.Endpoint = "/endpoint/test"
+	payload := []byte("test payload")
+	transaction.Payload = &payload
+
+	w.process(context.Background(), transaction)
+	assert.False(t, w.blockedList.isBlock(transaction.GetTarget()))
+	w.process(context.Background(), transaction)
+	assert.True(t, w.blockedList.isBlock(transaction.GetTarget()))
+	// 4xx aren't retried
+	assert.Len(t, requeue, 0)
+}
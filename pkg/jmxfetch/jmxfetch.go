@@ -15,13 +15,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	api "github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
-	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/executable"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -71,7 +71,9 @@ type JMXFetch struct {
 	IPCHost            string
 	defaultJmxCommand  string
 	cmd                *exec.Cmd
+	cmdMutex           sync.Mutex // guards cmd, replaced by the monitor on restarts
 	exitFilePath       string
+	startTime          time.Time
 	managed            bool
 	shutdown           chan struct{}
 	stopped            chan struct{}
@@ -95,9 +97,26 @@ func (j *JMXFetch) setDefaults() {
 	}
 }
 
-// Start starts the JMXFetch process
+// Start starts the JMXFetch process, when managed the process is supervised
+// by the agent until Stop is called
 func (j *JMXFetch) Start(manage bool) error {
+	err := j.start()
+
+	// start syncrhonization channels
+	if err == nil && manage {
+		j.managed = true
+		j.shutdown = make(chan struct{})
+		j.stopped = make(chan struct{})
+
+		go j.Monitor()
+	}
+
+	return err
+}
+
+func (j *JMXFetch) start() error {
 	j.setDefaults()
+	j.startTime = time.Now()
 
 	here, _ := executable.Folder()
 	classpath := filepath.Join(common.GetDistPath(), "jmx", jmxJarName)
@@ -192,10 +211,10 @@ func (j *JMXFetch) Start(manage bool) error {
 		subprocessArgs = append(subprocessArgs, "--exit_file_location", j.exitFilePath)
 	}
 
-	j.cmd = exec.Command(j.JavaBinPath, subprocessArgs...)
+	cmd := exec.Command(j.JavaBinPath, subprocessArgs...)
 
 	// set environment + token
-	j.cmd.Env = append(
+	cmd.Env = append(
 		os.Environ(),
 		fmt.Sprintf("SESSION_TOKEN=%s", api.GetAuthToken()),
 	)
@@ -206,7 +225,7 @@ func (j *JMXFetch) Start(manage bool) error {
 	}
 
 	// forward the standard output to the Agent logger
-	stdout, err := j.cmd.StdoutPipe()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
 	}
@@ -218,7 +237,7 @@ func (j *JMXFetch) Start(manage bool) error {
 	}()
 
 	// forward the standard error to the Agent logger
-	stderr, err := j.cmd.StderrPipe()
+	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return err
 	}
@@ -231,24 +250,35 @@ func (j *JMXFetch) Start(manage bool) error {
 
 	log.Debugf("Args: %v", subprocessArgs)
 
-	err = j.cmd.Start()
-
-	// start syncrhonization channels
-	if err == nil && manage {
-		j.managed = true
-		j.shutdown = make(chan struct{})
-		j.stopped = make(chan struct{})
-
-		go j.Monitor()
+	if err := cmd.Start(); err != nil {
+		return err
 	}
 
-	return err
+	j.cmdMutex.Lock()
+	j.cmd = cmd
+	j.cmdMutex.Unlock()
+	return nil
 }
 
 // Stop stops the JMXFetch process
 func (j *JMXFetch) Stop() error {
-	var stopChan chan struct{}
+	if j.managed {
+		// the monitor owns the process, it stops it instead of restarting it
+		close(j.shutdown)
+		<-j.stopped
+		return nil
+	}
 
+	exited := make(chan error, 1)
+	go func() {
+		exited <- j.Wait()
+	}()
+	return j.terminate(exited)
+}
+
+// terminate asks the JMXFetch process to exit, and kills it if it's still
+// running after its grace period. exited receives the exit of the process.
+func (j *JMXFetch) terminate(exited <-chan error) error {
 	if j.JmxExitFile == "" {
 		// Unix
 		err := j.cmd.Process.Signal(syscall.SIGTERM)
@@ -256,18 +286,6 @@ func (j *JMXFetch) Stop() error {
 			return err
 		}
 
-		if j.managed {
-			stopChan = j.stopped
-			close(j.shutdown)
-		} else {
-			stopChan = make(chan struct{})
-
-			go func() {
-				j.Wait()
-				close(stopChan)
-			}()
-		}
-
 		select {
 		case <-time.After(time.Millisecond * 500):
 			log.Warnf("Jmxfetch did not exit during it's grace period, killing it")
@@ -275,7 +293,7 @@ func (j *JMXFetch) Stop() error {
 			if err != nil {
 				log.Warnf("Could not kill jmxfetch: %v", err)
 			}
-		case <-stopChan:
+		case <-exited:
 		}
 
 	} else {
@@ -293,23 +311,17 @@ func (j *JMXFetch) Wait() error {
 	return j.cmd.Wait()
 }
 
-func (j *JMXFetch) heartbeat(beat *time.Ticker) {
-	health := health.Register("jmxfetch")
-	defer health.Deregister()
-
-	for range beat.C {
-		select {
-		case <-health.C:
-		case <-j.shutdown:
-			return
-		}
-	}
-}
-
 // Up returns if JMXFetch is up - used by healthcheck
 func (j *JMXFetch) Up() (bool, error) {
+	j.cmdMutex.Lock()
+	cmd := j.cmd
+	j.cmdMutex.Unlock()
+	if cmd == nil {
+		return false, fmt.Errorf("JMXFetch is not started")
+	}
+
 	// TODO: write windows implementation
-	process, err := os.FindProcess(j.cmd.Process.Pid)
+	process, err := os.FindProcess(cmd.Process.Pid)
 	if err != nil {
 		return false, fmt.Errorf("Failed to find process: %s\n", err)
 	}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build jmx
+
+package jmxfetch
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	monitorPeriod  = 500 * time.Millisecond
+	initialBackoff = time.Second
+)
+
+// Monitor supervises the JMXFetch process until Stop is called: it restarts
+// the process with an exponential backoff when it exits, and kills it when it
+// stops sending its status to the agent, which it does at the end of every
+// collection. It gives up after jmx_max_restarts consecutive restarts of a
+// process that ran less than jmx_restart_interval.
+func (j *JMXFetch) Monitor() {
+	defer close(j.stopped)
+
+	maxRestarts := config.Datadog.GetInt("jmx_max_restarts")
+	restartInterval := time.Duration(config.Datadog.GetInt("jmx_restart_interval")) * time.Second
+	maxBackoff := time.Duration(config.Datadog.GetInt("jmx_restart_max_backoff")) * time.Second
+	timeout := statusTimeout()
+
+	health := health.Register("jmxfetch")
+	defer health.Deregister()
+
+	ticker := time.NewTicker(monitorPeriod)
+	defer ticker.Stop()
+
+	telemetry := status.JMXProcessStatus{}
+	j.setRunning(&telemetry)
+	exited := j.waitExit()
+	failures := 0
+	killed := false
+
+	for {
+		select {
+		case <-j.shutdown:
+			if err := j.terminate(exited); err != nil {
+				log.Warnf("Could not stop JMXFetch: %v", err)
+			}
+			setStopped(&telemetry)
+			return
+
+		case <-ticker.C:
+			if killed {
+				continue
+			}
+			if last := j.lastSignOfLife(); time.Since(last) > timeout {
+				log.Errorf("JMXFetch sent no status since %s, killing it", last.Format(time.RFC3339))
+				if err := j.cmd.Process.Kill(); err != nil {
+					log.Warnf("Could not kill jmxfetch: %v", err)
+				}
+				killed = true
+				continue
+			}
+			// the component is only healthy while JMXFetch is responsive
+			select {
+			case <-health.C:
+			default:
+			}
+
+		case err := <-exited:
+			now := time.Now()
+			telemetry.LastExitTime = now.Unix()
+			telemetry.LastExitError = ""
+			if err == nil {
+				log.Infof("JMXFetch stopped and exited sanely.")
+				setStopped(&telemetry)
+				<-j.shutdown
+				return
+			}
+			telemetry.LastExitError = err.Error()
+
+			if now.Sub(j.startTime) < restartInterval && !killed {
+				failures++
+			} else {
+				failures = 0
+			}
+			if failures >= maxRestarts {
+				log.Errorf("Too many JMXFetch restarts (%v) of a process running less than %v - giving up", maxRestarts, restartInterval)
+				telemetry.State = status.JMXProcessFailed
+				telemetry.Pid = 0
+				status.SetJMXProcessStatus(telemetry)
+				// the component stays registered as unhealthy
+				<-j.shutdown
+				return
+			}
+
+			backoff := restartBackoff(failures, maxBackoff)
+			log.Warnf("JMXFetch exited: %v, restarting it in %v", err, backoff)
+			telemetry.State = status.JMXProcessRestarting
+			telemetry.Pid = 0
+			status.SetJMXProcessStatus(telemetry)
+
+			select {
+			case <-j.shutdown:
+				setStopped(&telemetry)
+				return
+			case <-time.After(backoff):
+			}
+
+			telemetry.Restarts++
+			killed = false
+			if err := j.start(); err != nil {
+				// an exit is reported for the restart to be retried
+				log.Errorf("Could not restart JMXFetch: %v", err)
+				failed := make(chan error, 1)
+				failed <- err
+				exited = failed
+				continue
+			}
+			j.setRunning(&telemetry)
+			exited = j.waitExit()
+		}
+	}
+}
+
+// waitExit returns a channel receiving the exit of the JMXFetch process
+func (j *JMXFetch) waitExit() <-chan error {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- j.Wait()
+	}()
+	return exited
+}
+
+// lastSignOfLife returns when the JMXFetch process last sent its status, or
+// when it started if it didn't send any yet
+func (j *JMXFetch) lastSignOfLife() time.Time {
+	last := status.GetJMXStatusUpdateTime()
+	if last.Before(j.startTime) {
+		return j.startTime
+	}
+	return last
+}
+
+func (j *JMXFetch) setRunning(telemetry *status.JMXProcessStatus) {
+	telemetry.State = status.JMXProcessRunning
+	telemetry.Pid = j.cmd.Process.Pid
+	telemetry.StartTime = j.startTime.Unix()
+	status.SetJMXProcessStatus(*telemetry)
+}
+
+func setStopped(telemetry *status.JMXProcessStatus) {
+	telemetry.State = status.JMXProcessStopped
+	telemetry.Pid = 0
+	status.SetJMXProcessStatus(*telemetry)
+}
+
+// statusTimeout returns how long JMXFetch may go without sending its status
+// before it's considered hung: twice the longest collection it can run, a
+// collection waits for the reconnection of the instances then for their
+// metrics.
+func statusTimeout() time.Duration {
+	period := time.Duration(config.Datadog.GetInt("jmx_check_period")) * time.Millisecond
+	collection := time.Duration(config.Datadog.GetInt("jmx_collection_timeout")) * time.Second
+	reconnection := time.Duration(config.Datadog.GetInt("jmx_reconnection_timeout")) * time.Second
+	return 2 * (period + collection + reconnection)
+}
+
+// restartBackoff returns the delay before restarting a process that exited
+// after failures consecutive short runs: it doubles at each failure, up to max
+func restartBackoff(failures int, max time.Duration) time.Duration {
+	backoff := initialBackoff
+	for i := 0; i < failures && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build jmx
+// +build !windows
+
+package jmxfetch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status"
+)
+
+// fakeJava writes a script standing for the java binary, ignoring the
+// arguments of JMXFetch
+func fakeJava(t *testing.T, dir, script string) string {
+	path := filepath.Join(dir, "java")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return path
+}
+
+// waitForProcessStatus polls the status of the JMXFetch process until cond is
+// true or the timeout expires
+func waitForProcessStatus(t *testing.T, cond func(status.JMXProcessStatus) bool) {
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond(status.GetJMXProcessStatus()) {
+		if time.Now().After(deadline) {
+			require.FailNow(t, "timeout", "unexpected process status: %+v", status.GetJMXProcessStatus())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestRestartBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, restartBackoff(0, time.Minute))
+	assert.Equal(t, 2*time.Second, restartBackoff(1, time.Minute))
+	assert.Equal(t, 8*time.Second, restartBackoff(3, time.Minute))
+	assert.Equal(t, time.Minute, restartBackoff(6, time.Minute))
+	assert.Equal(t, time.Minute, restartBackoff(100, time.Minute))
+}
+
+func TestStatusTimeout(t *testing.T) {
+	assert.Equal(t, 2*(15*time.Second+60*time.Second+10*time.Second), statusTimeout())
+}
+
+func TestMonitorGivesUp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jmxfetch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config.Datadog.Set("jmx_max_restarts", 2)
+	config.Datadog.Set("jmx_restart_max_backoff", 1)
+	defer config.Datadog.Set("jmx_max_restarts", 3)
+	defer config.Datadog.Set("jmx_restart_max_backoff", 60)
+
+	j := &JMXFetch{JavaBinPath: fakeJava(t, dir, "exit 1")}
+	require.NoError(t, j.Start(true))
+
+	waitForProcessStatus(t, func(s status.JMXProcessStatus) bool {
+		return s.State == status.JMXProcessFailed
+	})
+
+	s := status.GetJMXProcessStatus()
+	assert.Equal(t, 1, s.Restarts)
+	assert.Equal(t, "exit status 1", s.LastExitError)
+	assert.Zero(t, s.Pid)
+
+	require.NoError(t, j.Stop())
+}
+
+func TestMonitorStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jmxfetch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	j := &JMXFetch{JavaBinPath: fakeJava(t, dir, "exec sleep 60")}
+	require.NoError(t, j.Start(true))
+
+	waitForProcessStatus(t, func(s status.JMXProcessStatus) bool {
+		return s.State == status.JMXProcessRunning
+	})
+	s := status.GetJMXProcessStatus()
+	assert.Equal(t, j.cmd.Process.Pid, s.Pid)
+
+	require.NoError(t, j.Stop())
+	s = status.GetJMXProcessStatus()
+	assert.Equal(t, status.JMXProcessStopped, s.State)
+	assert.Zero(t, s.Restarts)
+}
+
+func TestMonitorKillsUnresponsiveProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jmxfetch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the status timeout is 2s
+	config.Datadog.Set("jmx_check_period", 1000)
+	config.Datadog.Set("jmx_collection_timeout", 0)
+	config.Datadog.Set("jmx_reconnection_timeout", 0)
+	defer config.Datadog.Set("jmx_check_period", 15000)
+	defer config.Datadog.Set("jmx_collection_timeout", 60)
+	defer config.Datadog.Set("jmx_reconnection_timeout", 10)
+
+	j := &JMXFetch{JavaBinPath: fakeJava(t, dir, "exec sleep 60")}
+	require.NoError(t, j.Start(true))
+	defer j.Stop()
+
+	waitForProcessStatus(t, func(s status.JMXProcessStatus) bool {
+		return s.Restarts == 1
+	})
+	assert.Equal(t, "signal: killed", status.GetJMXProcessStatus().LastExitError)
+}
+
+func TestUpDuringRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jmxfetch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config.Datadog.Set("jmx_restart_max_backoff", 1)
+	defer config.Datadog.Set("jmx_restart_max_backoff", 60)
+
+	j := &JMXFetch{}
+	up, err := j.Up()
+	assert.False(t, up)
+	assert.Error(t, err)
+
+	j.JavaBinPath = fakeJava(t, dir, "exit 1")
+	require.NoError(t, j.Start(true))
+
+	// the health check runs concurrently with the restarts of the monitor
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		j.Up()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.NoError(t, j.Stop())
+}
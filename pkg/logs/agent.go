@@ -6,6 +6,7 @@
 package logs
 
 import (
+	"context"
 	"time"
 
 	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
@@ -15,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/container"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/file"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/journald"
@@ -23,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 )
 
 // Agent represents the data pipeline that collects, decodes,
@@ -38,6 +41,9 @@ type Agent struct {
 	pipelineProvider pipeline.Provider
 	inputs           []restart.Restartable
 	health           *health.Handle
+
+	// diagnosticMessageReceiver streams the processed messages to the clients of stream-logs
+	diagnosticMessageReceiver *diagnostic.BufferedMessageReceiver
 }
 
 // NewAgent returns a new Agent
@@ -47,11 +53,28 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 	// setup the auditor
 	// We pass the health handle to the auditor because it's the end of the pipeline and the most
 	// critical part. Arguably it could also be plugged to the destination.
-	auditor := auditor.New(coreConfig.Datadog.GetString("logs_config.run_path"), health)
+	runPath := coreConfig.Datadog.GetString("logs_config.run_path")
+	numberOfPipelines := config.NumberOfPipelines
+	if config.IsServerless() {
+		// keep the registry in memory and the memory footprint low
+		runPath = ""
+		numberOfPipelines = 1
+	}
+	auditor := auditor.New(runPath, health)
 	destinationsCtx := client.NewDestinationsContext()
 
+	// attach the tags of the containers and the pods to their logs, and the
+	// tags of the cluster to all the logs
+	enricher := tag.NoopEnricher
+	entityTags := coreConfig.Datadog.GetBool("logs_config.tagger_enrichment")
+	clusterTags := coreConfig.Datadog.GetBool("cluster_agent.enabled") && coreConfig.Datadog.GetBool("cluster_agent.collect_cluster_tags")
+	if entityTags || clusterTags {
+		enricher = tag.NewEnricher(entityTags, clusterTags)
+	}
+
 	// setup the pipeline provider that provides pairs of processor and sender
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, processingRules, endpoints, destinationsCtx)
+	diagnosticMessageReceiver := diagnostic.NewBufferedMessageReceiver()
+	pipelineProvider := pipeline.NewProvider(numberOfPipelines, auditor, processingRules, config.GlobalRateLimiter(), config.GlobalMemoryLimiter(), enricher, endpoints, destinationsCtx, diagnosticMessageReceiver)
 
 	// setup the inputs
 	inputs := []restart.Restartable{
@@ -59,15 +82,16 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 		container.NewLauncher(coreConfig.Datadog.GetBool("logs_config.container_collect_all"), sources, services, pipelineProvider, auditor),
 		listener.NewLauncher(sources, coreConfig.Datadog.GetInt("logs_config.frame_size"), pipelineProvider),
 		journald.NewLauncher(sources, pipelineProvider, auditor),
-		windowsevent.NewLauncher(sources, pipelineProvider),
+		windowsevent.NewLauncher(sources, pipelineProvider, auditor),
 	}
 
 	return &Agent{
-		auditor:          auditor,
-		destinationsCtx:  destinationsCtx,
-		pipelineProvider: pipelineProvider,
-		inputs:           inputs,
-		health:           health,
+		auditor:                   auditor,
+		destinationsCtx:           destinationsCtx,
+		pipelineProvider:          pipelineProvider,
+		inputs:                    inputs,
+		health:                    health,
+		diagnosticMessageReceiver: diagnosticMessageReceiver,
 	}
 }
 
@@ -117,3 +141,9 @@ func (a *Agent) Stop() {
 		<-c
 	}
 }
+
+// Flush sends the logs collected so far, it returns once they are sent or
+// when ctx is done.
+func (a *Agent) Flush(ctx context.Context) error {
+	return a.pipelineProvider.Flush(ctx)
+}
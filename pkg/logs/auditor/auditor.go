@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -31,6 +32,7 @@ const registryAPIVersion = 2
 // Registry holds a list of offsets.
 type Registry interface {
 	GetOffset(identifier string) string
+	GetFingerprint(identifier string) string
 }
 
 // A RegistryEntry represents an entry in the registry where we keep track
@@ -38,6 +40,9 @@ type Registry interface {
 type RegistryEntry struct {
 	LastUpdated time.Time
 	Offset      string
+	// Fingerprint identifies the file the offset belongs to, to detect
+	// the files replaced while they were not tailed
+	Fingerprint string `json:",omitempty"`
 }
 
 // JSONRegistry represents the registry that will be written on disk
@@ -54,15 +59,23 @@ type Auditor struct {
 	registryPath string
 	mu           sync.Mutex
 	entryTTL     time.Duration
+	fsyncPeriod  time.Duration
+	lastFsync    time.Time
 	done         chan struct{}
 }
 
-// New returns an initialized Auditor
+// New returns an initialized Auditor, the registry is kept in memory only
+// when runPath is empty.
 func New(runPath string, health *health.Handle) *Auditor {
+	registryPath := ""
+	if runPath != "" {
+		registryPath = filepath.Join(runPath, "registry.json")
+	}
 	return &Auditor{
 		health:       health,
-		registryPath: filepath.Join(runPath, "registry.json"),
+		registryPath: registryPath,
 		entryTTL:     defaultTTL,
+		fsyncPeriod:  time.Duration(coreConfig.Datadog.GetInt("logs_config.registry_fsync_period")) * time.Second,
 	}
 }
 
@@ -111,6 +124,17 @@ func (a *Auditor) GetOffset(identifier string) string {
 	return entry.Offset
 }
 
+// GetFingerprint returns the fingerprint of the file of the last committed
+// offset for a given identifier, returns an empty string if it is unknown.
+func (a *Auditor) GetFingerprint(identifier string) string {
+	r := a.readOnlyRegistryCopy()
+	entry, exists := r[identifier]
+	if !exists {
+		return ""
+	}
+	return entry.Fingerprint
+}
+
 // run keeps up to date the registry depending on different events
 func (a *Auditor) run() {
 	cleanUpTicker := time.NewTicker(defaultCleanupPeriod)
@@ -132,7 +156,7 @@ func (a *Auditor) run() {
 				return
 			}
 			// update the registry with new entry
-			a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset)
+			a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.Fingerprint)
 		case <-cleanUpTicker.C:
 			// remove expired offsets from registry
 			a.cleanupRegistry()
@@ -154,6 +178,9 @@ func (a *Auditor) run() {
 
 // recoverRegistry rebuilds the registry from the state file found at path
 func (a *Auditor) recoverRegistry() map[string]*RegistryEntry {
+	if a.registryPath == "" {
+		return make(map[string]*RegistryEntry)
+	}
 	mr, err := ioutil.ReadFile(a.registryPath)
 	if err != nil {
 		log.Error(err)
@@ -179,8 +206,8 @@ func (a *Auditor) cleanupRegistry() {
 	}
 }
 
-// updateRegistry updates the registry entry matching identifier with new the offset and timestamp
-func (a *Auditor) updateRegistry(identifier string, offset string) {
+// updateRegistry updates the registry entry matching identifier with new the offset, fingerprint and timestamp
+func (a *Auditor) updateRegistry(identifier string, offset string, fingerprint string) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if identifier == "" {
@@ -192,6 +219,7 @@ func (a *Auditor) updateRegistry(identifier string, offset string) {
 	a.registry[identifier] = &RegistryEntry{
 		LastUpdated: time.Now().UTC(),
 		Offset:      offset,
+		Fingerprint: fingerprint,
 	}
 }
 
@@ -206,14 +234,37 @@ func (a *Auditor) readOnlyRegistryCopy() map[string]RegistryEntry {
 	return r
 }
 
-// flushRegistry writes on disk the registry at the given path
+// flushRegistry writes on disk the registry at the given path. The registry
+// is written to a temporary file renamed over the previous one, so that it
+// is never left partially written, and synced every fsyncPeriod.
 func (a *Auditor) flushRegistry() error {
+	if a.registryPath == "" {
+		return nil
+	}
 	r := a.readOnlyRegistryCopy()
 	mr, err := a.marshalRegistry(r)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(a.registryPath, mr, 0644)
+	tmpPath := a.registryPath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(mr)
+	if err == nil && a.fsyncPeriod > 0 && time.Since(a.lastFsync) >= a.fsyncPeriod {
+		if err = f.Sync(); err == nil {
+			a.lastFsync = time.Now()
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, a.registryPath)
 }
 
 // marshalRegistry marshals a registry
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
 var testpath = "testpath"
@@ -63,12 +64,14 @@ func (suite *AuditorTestSuite) TestAuditorStartStop() {
 func (suite *AuditorTestSuite) TestAuditorUpdatesRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.Equal(0, len(suite.a.registry))
-	suite.a.updateRegistry(suite.source.Config.Path, "42")
+	suite.a.updateRegistry(suite.source.Config.Path, "42", "")
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("42", suite.a.registry[suite.source.Config.Path].Offset)
-	suite.a.updateRegistry(suite.source.Config.Path, "43")
+	suite.a.updateRegistry(suite.source.Config.Path, "43", "2049:1234")
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("43", suite.a.registry[suite.source.Config.Path].Offset)
+	suite.Equal("2049:1234", suite.a.GetFingerprint(suite.source.Config.Path))
+	suite.Equal("", suite.a.GetFingerprint("anotherpath"))
 }
 
 func (suite *AuditorTestSuite) TestAuditorFlushesAndRecoversRegistry() {
@@ -85,6 +88,20 @@ func (suite *AuditorTestSuite) TestAuditorFlushesAndRecoversRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry = suite.a.recoverRegistry()
 	suite.Equal("42", suite.a.registry[suite.source.Config.Path].Offset)
+
+	// the fingerprint is flushed when known, the registry is replaced atomically
+	suite.a.fsyncPeriod = time.Minute
+	suite.a.registry[suite.source.Config.Path].Fingerprint = "2049:1234"
+	suite.Nil(suite.a.flushRegistry())
+	suite.False(suite.a.lastFsync.IsZero())
+	r, err = ioutil.ReadFile(suite.testPath)
+	suite.Nil(err)
+	suite.Equal("{\"Version\":2,\"Registry\":{\"testpath\":{\"LastUpdated\":\"2006-01-12T01:01:01.000000001Z\",\"Offset\":\"42\",\"Fingerprint\":\"2049:1234\"}}}", string(r))
+	_, err = os.Stat(suite.testPath + ".tmp")
+	suite.True(os.IsNotExist(err))
+
+	suite.a.registry = suite.a.recoverRegistry()
+	suite.Equal("2049:1234", suite.a.GetFingerprint(suite.source.Config.Path))
 }
 
 func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForOffset() {
@@ -121,6 +138,17 @@ func (suite *AuditorTestSuite) TestAuditorCleansupRegistry() {
 	suite.Equal("43", suite.a.registry[otherpath].Offset)
 }
 
+func (suite *AuditorTestSuite) TestAuditorKeepsRegistryInMemoryWithoutRunPath() {
+	a := New("", health.Register("fake"))
+	suite.Equal("", a.registryPath)
+	a.Start()
+	a.Channel() <- message.NewMessage(nil, &message.Origin{Identifier: testpath, Offset: "42"}, "")
+	a.Stop()
+	suite.Equal("42", a.GetOffset(testpath))
+
+	suite.Equal(testpath+"/registry.json", New(testpath, health.Register("fake")).registryPath)
+}
+
 func TestScannerTestSuite(t *testing.T) {
 	suite.Run(t, new(AuditorTestSuite))
 }
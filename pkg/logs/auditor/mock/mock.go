@@ -7,7 +7,8 @@ package mock
 
 // Registry does nothing
 type Registry struct {
-	offset string
+	offset      string
+	fingerprint string
 }
 
 // NewRegistry returns a new registry.
@@ -24,3 +25,13 @@ func (r *Registry) GetOffset(identifier string) string {
 func (r *Registry) SetOffset(offset string) {
 	r.offset = offset
 }
+
+// GetFingerprint returns the fingerprint.
+func (r *Registry) GetFingerprint(identifier string) string {
+	return r.fingerprint
+}
+
+// SetFingerprint sets the fingerprint.
+func (r *Registry) SetFingerprint(fingerprint string) {
+	r.fingerprint = fingerprint
+}
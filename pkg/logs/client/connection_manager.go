@@ -64,7 +64,7 @@ func (cm *ConnectionManager) NewConnection(ctx context.Context) (net.Conn, error
 		}
 		if retries > 0 {
 			log.Debugf("Connect attempt #%d", retries)
-			cm.backoff(ctx, retries)
+			backoff(ctx, retries)
 		}
 		retries++
 
@@ -152,7 +152,7 @@ func (cm *ConnectionManager) handleServerClose(conn net.Conn) {
 // backoff implements a randomized exponential backoff in case of connection failure
 // each invocation will trigger a sleep between [2^(retries-1), 2^retries) second
 // the exponent is capped at 7, which translates to max sleep between ~1min and ~2min
-func (cm *ConnectionManager) backoff(ctx context.Context, retries uint) {
+func backoff(ctx context.Context, retries uint) {
 	if retries > maxExpBackoffCount {
 		retries = maxExpBackoffCount
 	}
@@ -38,32 +38,41 @@ func (e *FramingError) Error() string {
 	return e.err.Error()
 }
 
-// Destination is responsible for shipping logs to a remote server over TCP.
-type Destination struct {
+// Destination sends payloads to the logs intake.
+type Destination interface {
+	// Send sends a payload, it blocks until the payload is sent and returns an
+	// error if it failed.
+	Send(payload []byte) error
+	// SendAsync queues a payload to send it without blocking, the payload is
+	// dropped if the queue is full.
+	SendAsync(payload []byte)
+}
+
+// TCPDestination is responsible for shipping logs to a remote server over TCP.
+type TCPDestination struct {
 	prefixer            *prefixer
 	delimiter           Delimiter
 	connManager         *ConnectionManager
 	destinationsContext *DestinationsContext
 	conn                net.Conn
-	inputChan           chan []byte
-	once                sync.Once
-	warningCounter      int
+	async               asyncSender
 }
 
-// NewDestination returns a new destination.
-func NewDestination(endpoint Endpoint, destinationsContext *DestinationsContext) *Destination {
+// NewDestination returns a new TCP destination.
+func NewDestination(endpoint Endpoint, destinationsContext *DestinationsContext) *TCPDestination {
 	prefix := endpoint.APIKey + string(' ')
-	return &Destination{
+	return &TCPDestination{
 		prefixer:            newPrefixer(prefix),
 		delimiter:           NewDelimiter(endpoint.UseProto),
 		connManager:         NewConnectionManager(endpoint),
 		destinationsContext: destinationsContext,
+		async:               asyncSender{host: endpoint.Host},
 	}
 }
 
 // Send transforms a message into a frame and sends it to a remote server,
 // returns an error if the operation failed.
-func (d *Destination) Send(payload []byte) error {
+func (d *TCPDestination) Send(payload []byte) error {
 	if d.conn == nil {
 		var err error
 
@@ -92,33 +101,44 @@ func (d *Destination) Send(payload []byte) error {
 
 // SendAsync sends a message to the destination without blocking. If the channel is full, the incoming messages will be
 // dropped
-func (d *Destination) SendAsync(payload []byte) {
-	host := d.connManager.endpoint.Host
-	d.once.Do(func() {
+func (d *TCPDestination) SendAsync(payload []byte) {
+	d.async.sendAsync(payload, d.destinationsContext, d.Send)
+}
+
+// asyncSender sends the payloads queued for a destination in the background.
+type asyncSender struct {
+	host      string
+	once      sync.Once
+	inputChan chan []byte
+}
+
+// sendAsync queues the payload, the payload is dropped if the queue is full.
+func (a *asyncSender) sendAsync(payload []byte, destinationsContext *DestinationsContext, send func([]byte) error) {
+	a.once.Do(func() {
 		inputChan := make(chan []byte, chanSize)
-		d.inputChan = inputChan
-		metrics.DestinationLogsDropped.Set(host, &expvar.Int{})
-		go d.runAsync()
+		a.inputChan = inputChan
+		metrics.DestinationLogsDropped.Set(a.host, &expvar.Int{})
+		go a.run(destinationsContext, send)
 	})
 
 	select {
-	case d.inputChan <- payload:
+	case a.inputChan <- payload:
 	default:
 		// TODO: Display the warning in the status
-		if metrics.DestinationLogsDropped.Get(host).(*expvar.Int).Value()%warningPeriod == 0 {
-			log.Warnf("Some logs sent to additional destination %v were dropped", host)
+		if metrics.DestinationLogsDropped.Get(a.host).(*expvar.Int).Value()%warningPeriod == 0 {
+			log.Warnf("Some logs sent to additional destination %v were dropped", a.host)
 		}
-		metrics.DestinationLogsDropped.Add(host, 1)
+		metrics.DestinationLogsDropped.Add(a.host, 1)
 	}
 }
 
-// runAsync read the messages from the channel and send them
-func (d *Destination) runAsync() {
-	ctx := d.destinationsContext.Context()
+// run read the messages from the channel and send them
+func (a *asyncSender) run(destinationsContext *DestinationsContext, send func([]byte) error) {
+	ctx := destinationsContext.Context()
 	for {
 		select {
-		case payload := <-d.inputChan:
-			d.Send(payload)
+		case payload := <-a.inputChan:
+			send(payload)
 		case <-ctx.Done():
 			return
 		}
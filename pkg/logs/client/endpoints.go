@@ -5,6 +5,8 @@
 
 package client
 
+import "time"
+
 // Endpoint holds all the organization and network parameters to send logs to Datadog.
 type Endpoint struct {
 	APIKey       string `mapstructure:"api_key"`
@@ -13,12 +15,17 @@ type Endpoint struct {
 	UseSSL       bool
 	UseProto     bool
 	ProxyAddress string
+
+	UseCompression   bool
+	CompressionLevel int
 }
 
 // Endpoints holds the main endpoint and additional ones to dualship logs.
 type Endpoints struct {
 	Main        Endpoint
 	Additionals []Endpoint
+	UseHTTP     bool
+	BatchWait   time.Duration
 }
 
 // NewEndpoints returns a new endpoints composite.
@@ -28,3 +35,14 @@ func NewEndpoints(main Endpoint, additionals []Endpoint) *Endpoints {
 		Additionals: additionals,
 	}
 }
+
+// NewHTTPEndpoints returns a new endpoints composite sending batches of logs
+// over HTTP every batchWait.
+func NewHTTPEndpoints(main Endpoint, additionals []Endpoint, batchWait time.Duration) *Endpoints {
+	return &Endpoints{
+		Main:        main,
+		Additionals: additionals,
+		UseHTTP:     true,
+		BatchWait:   batchWait,
+	}
+}
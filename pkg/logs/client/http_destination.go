@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const httpTimeout = 20 * time.Second
+
+// HTTPError represents an error returned by the intake that must not be
+// retried, the payload is dropped.
+type HTTPError struct {
+	StatusCode int
+}
+
+// Error returns the message of the error.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("the logs intake rejected the payload with status code %d", e.StatusCode)
+}
+
+// HTTPDestination sends batches of logs to the logs intake over HTTP(S),
+// with the proxy settings of the forwarder.
+type HTTPDestination struct {
+	url                 string
+	apiKey              string
+	compressionLevel    int
+	client              *http.Client
+	destinationsContext *DestinationsContext
+	retries             uint
+	async               asyncSender
+}
+
+// NewHTTPDestination returns a new HTTP destination, the payloads are gzip
+// compressed when the endpoint uses compression.
+func NewHTTPDestination(endpoint Endpoint, destinationsContext *DestinationsContext) *HTTPDestination {
+	compressionLevel := gzip.NoCompression
+	if endpoint.UseCompression {
+		compressionLevel = endpoint.CompressionLevel
+	}
+	return &HTTPDestination{
+		url:                 buildURL(endpoint),
+		apiKey:              endpoint.APIKey,
+		compressionLevel:    compressionLevel,
+		client:              &http.Client{Timeout: httpTimeout, Transport: util.CreateHTTPTransport()},
+		destinationsContext: destinationsContext,
+		async:               asyncSender{host: endpoint.Host},
+	}
+}
+
+// buildURL returns the url of the intake of the endpoint.
+func buildURL(endpoint Endpoint) string {
+	scheme := "http"
+	if endpoint.UseSSL {
+		scheme = "https"
+	}
+	address := endpoint.Host
+	if endpoint.Port != 0 {
+		address = fmt.Sprintf("%s:%d", endpoint.Host, endpoint.Port)
+	}
+	return fmt.Sprintf("%s://%s/v1/input", scheme, address)
+}
+
+// Send sends a payload to the intake, the payloads failing with a network
+// error or a server error are delayed with an exponential backoff before
+// they are retried by the caller.
+func (d *HTTPDestination) Send(payload []byte) error {
+	ctx := d.destinationsContext.Context()
+	if d.retries > 0 {
+		backoff(ctx, d.retries)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	err := d.send(ctx, payload)
+	switch err.(type) {
+	case nil, *HTTPError:
+		d.retries = 0
+	default:
+		if d.retries < maxExpBackoffCount {
+			d.retries++
+		}
+		log.Debugf("Could not send the payload to %s, retrying: %v", d.url, err)
+	}
+	return err
+}
+
+// send posts the payload to the intake.
+func (d *HTTPDestination) send(ctx context.Context, payload []byte) error {
+	body, err := d.compress(payload)
+	if err != nil {
+		return NewFramingError(err)
+	}
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(body))
+	if err != nil {
+		return NewFramingError(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", d.apiKey)
+	if d.compressionLevel != gzip.NoCompression {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return context.Canceled
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusRequestTimeout:
+		return fmt.Errorf("server error, status code %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return &HTTPError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// compress gzips the payload with the compression level of the destination.
+func (d *HTTPDestination) compress(payload []byte) ([]byte, error) {
+	if d.compressionLevel == gzip.NoCompression {
+		return payload, nil
+	}
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, d.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SendAsync sends a payload to the destination without blocking, the
+// payload is dropped if the queue is full.
+func (d *HTTPDestination) SendAsync(payload []byte) {
+	d.async.sendAsync(payload, d.destinationsContext, d.Send)
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package client
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+func newTestServer(statusCode int) (*httptest.Server, chan request) {
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			body, _ = gzip.NewReader(r.Body)
+		}
+		content, _ := ioutil.ReadAll(body)
+		requests <- request{header: r.Header, body: content}
+		w.WriteHeader(statusCode)
+	}))
+	return server, requests
+}
+
+func newTestHTTPDestination(t *testing.T, server *httptest.Server, useCompression bool, ctx *DestinationsContext) *HTTPDestination {
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+	endpoint := Endpoint{
+		APIKey:           "foo",
+		Host:             u.Hostname(),
+		Port:             port,
+		UseCompression:   useCompression,
+		CompressionLevel: 6,
+	}
+	return NewHTTPDestination(endpoint, ctx)
+}
+
+func TestBuildURL(t *testing.T) {
+	assert.Equal(t, "https://foo:443/v1/input", buildURL(Endpoint{Host: "foo", Port: 443, UseSSL: true}))
+	assert.Equal(t, "http://foo:1234/v1/input", buildURL(Endpoint{Host: "foo", Port: 1234}))
+	assert.Equal(t, "https://foo/v1/input", buildURL(Endpoint{Host: "foo", UseSSL: true}))
+}
+
+func TestHTTPDestinationSendsCompressedPayload(t *testing.T) {
+	server, requests := newTestServer(http.StatusOK)
+	defer server.Close()
+	ctx := NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	destination := newTestHTTPDestination(t, server, true, ctx)
+	assert.Nil(t, destination.Send([]byte(`[{"message":"foo"}]`)))
+
+	r := <-requests
+	assert.Equal(t, "gzip", r.header.Get("Content-Encoding"))
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	assert.Equal(t, "foo", r.header.Get("DD-API-KEY"))
+	assert.Equal(t, `[{"message":"foo"}]`, string(r.body))
+}
+
+func TestHTTPDestinationSendsUncompressedPayload(t *testing.T) {
+	server, requests := newTestServer(http.StatusOK)
+	defer server.Close()
+	ctx := NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	destination := newTestHTTPDestination(t, server, false, ctx)
+	assert.Nil(t, destination.Send([]byte(`[{"message":"foo"}]`)))
+
+	r := <-requests
+	assert.Equal(t, "", r.header.Get("Content-Encoding"))
+	assert.Equal(t, `[{"message":"foo"}]`, string(r.body))
+}
+
+func TestHTTPDestinationServerErrorIsRetryable(t *testing.T) {
+	server, _ := newTestServer(http.StatusInternalServerError)
+	defer server.Close()
+	ctx := NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	destination := newTestHTTPDestination(t, server, true, ctx)
+	err := destination.Send([]byte("[]"))
+	assert.NotNil(t, err)
+	_, isHTTPError := err.(*HTTPError)
+	assert.False(t, isHTTPError)
+	assert.Equal(t, uint(1), destination.retries)
+}
+
+func TestHTTPDestinationClientErrorIsDropped(t *testing.T) {
+	server, _ := newTestServer(http.StatusBadRequest)
+	defer server.Close()
+	ctx := NewDestinationsContext()
+	ctx.Start()
+	defer ctx.Stop()
+
+	destination := newTestHTTPDestination(t, server, true, ctx)
+	err := destination.Send([]byte("[]"))
+	assert.Equal(t, &HTTPError{StatusCode: http.StatusBadRequest}, err)
+	assert.Equal(t, uint(0), destination.retries)
+}
+
+func TestHTTPDestinationStopsRetryingWhenContextIsCancelled(t *testing.T) {
+	server, _ := newTestServer(http.StatusOK)
+	defer server.Close()
+	ctx := NewDestinationsContext()
+	ctx.Start()
+
+	destination := newTestHTTPDestination(t, server, true, ctx)
+	destination.retries = maxExpBackoffCount
+	ctx.Stop()
+	assert.NotNil(t, destination.Send([]byte("[]")))
+}
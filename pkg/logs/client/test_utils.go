@@ -27,6 +27,6 @@ func AddrToEndPoint(addr net.Addr) Endpoint {
 }
 
 // AddrToDestination creates a Destination from an Addr
-func AddrToDestination(addr net.Addr, ctx *DestinationsContext) *Destination {
+func AddrToDestination(addr net.Addr, ctx *DestinationsContext) *TCPDestination {
 	return NewDestination(AddrToEndPoint(addr), ctx)
 }
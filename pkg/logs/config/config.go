@@ -7,6 +7,7 @@ package config
 
 import (
 	"encoding/json"
+	"path/filepath"
 
 	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 )
@@ -54,3 +55,92 @@ func GlobalProcessingRules() ([]*ProcessingRule, error) {
 	}
 	return rules, nil
 }
+
+// AutoMultiLineEnabled returns whether the multi-line pattern of the logs of
+// the source should be detected, the source setting overrides the global one.
+func AutoMultiLineEnabled(c *LogsConfig) bool {
+	if c.AutoMultiLine != nil {
+		return *c.AutoMultiLine
+	}
+	return coreConfig.Datadog.GetBool("logs_config.auto_multi_line_detection")
+}
+
+// AutoMultiLineSampleSize returns the number of lines sampled to detect a
+// multi-line pattern.
+func AutoMultiLineSampleSize() int {
+	return coreConfig.Datadog.GetInt("logs_config.auto_multi_line_sample_size")
+}
+
+// AutoMultiLineMatchThreshold returns the ratio of the sampled lines a
+// multi-line pattern must match to be detected.
+func AutoMultiLineMatchThreshold() float64 {
+	return coreConfig.Datadog.GetFloat64("logs_config.auto_multi_line_match_threshold")
+}
+
+// MaxLineSize returns the size limit of the lines of the source, the source
+// setting overrides the global one.
+func MaxLineSize(c *LogsConfig) int {
+	if c.MaxLineSize > 0 {
+		return c.MaxLineSize
+	}
+	return coreConfig.Datadog.GetInt("logs_config.max_line_size")
+}
+
+// ShouldTruncate returns whether the remainder of the lines of the source
+// exceeding the size limit is dropped instead of being sent in following logs,
+// the source setting overrides the global one.
+func ShouldTruncate(c *LogsConfig) bool {
+	if c.TruncationMode != "" {
+		return c.TruncationMode == TruncationModeTruncate
+	}
+	return coreConfig.Datadog.GetString("logs_config.truncation_mode") == TruncationModeTruncate
+}
+
+// GlobalRateLimiter returns a rate limiter shared by all the sources.
+func GlobalRateLimiter() *RateLimiter {
+	return NewRateLimiter(
+		coreConfig.Datadog.GetInt("logs_config.rate_limit_lines_per_second"),
+		coreConfig.Datadog.GetInt("logs_config.rate_limit_bytes_per_second"),
+		RateLimitBurstSeconds(),
+	)
+}
+
+// RateLimitBurstSeconds returns the number of seconds of traffic allowed in a
+// burst above the rate limits.
+func RateLimitBurstSeconds() int {
+	return coreConfig.Datadog.GetInt("logs_config.rate_limit_burst_seconds")
+}
+
+// IsServerless returns whether the agent runs next to a serverless runtime,
+// where it can be frozen at any time and has a tight memory budget.
+func IsServerless() bool {
+	return coreConfig.Datadog.GetBool("serverless.enabled")
+}
+
+// GlobalMemoryLimiter returns a memory limiter shared by all the pipelines in
+// serverless mode, nil otherwise.
+func GlobalMemoryLimiter() *MemoryLimiter {
+	if !IsServerless() {
+		return nil
+	}
+	limit := coreConfig.Datadog.GetInt("serverless.logs_memory_limit")
+	if limit <= 0 {
+		return nil
+	}
+	return NewMemoryLimiter(limit)
+}
+
+// SpillDirectory returns the directory where the pipelines spill the logs
+// while their sender is blocked, an empty string when it is disabled.
+func SpillDirectory() string {
+	if !coreConfig.Datadog.GetBool("logs_config.spill_to_disk") || IsServerless() {
+		return ""
+	}
+	return filepath.Join(coreConfig.Datadog.GetString("logs_config.run_path"), "spill")
+}
+
+// SpillMaxSize returns the maximum number of bytes of logs spilled to disk
+// per pipeline.
+func SpillMaxSize() int64 {
+	return coreConfig.Datadog.GetInt64("logs_config.spill_max_size")
+}
@@ -123,6 +123,19 @@ func (suite *ConfigTestSuite) TestGlobalProcessingRulesShouldReturnRulesWithVali
 	suite.NotNil(rule.Regex)
 }
 
+func (suite *ConfigTestSuite) TestLineSizeLimitSourceSettingsOverrideGlobalOnes() {
+	suite.Equal(256000, MaxLineSize(&LogsConfig{}))
+	suite.False(ShouldTruncate(&LogsConfig{}))
+
+	suite.config.Set("logs_config.max_line_size", 1000)
+	suite.config.Set("logs_config.truncation_mode", TruncationModeTruncate)
+	suite.Equal(1000, MaxLineSize(&LogsConfig{}))
+	suite.True(ShouldTruncate(&LogsConfig{}))
+
+	suite.Equal(500, MaxLineSize(&LogsConfig{MaxLineSize: 500}))
+	suite.False(ShouldTruncate(&LogsConfig{TruncationMode: TruncationModeSplit}))
+}
+
 func TestConfigTestSuite(t *testing.T) {
 	suite.Run(t, new(ConfigTestSuite))
 }
@@ -17,6 +17,15 @@ const (
 	DockerType       = "docker"
 	JournaldType     = "journald"
 	WindowsEventType = "windows_event"
+	SyslogType       = "syslog"
+)
+
+// Truncation modes of the lines longer than the line size limit
+const (
+	// TruncationModeSplit sends the remainder of the line in following logs
+	TruncationModeSplit = "split"
+	// TruncationModeTruncate drops the remainder of the line
+	TruncationModeTruncate = "truncate"
 )
 
 // LogsConfig represents a log source config, which can be for instance
@@ -24,8 +33,11 @@ const (
 type LogsConfig struct {
 	Type string
 
-	Port int    // Network
-	Path string // File, Journald
+	Port     int    // Network
+	Path     string // File, Journald
+	Protocol string // Syslog
+	TLSCert  string `mapstructure:"tls_cert" json:"tls_cert"` // Syslog
+	TLSKey   string `mapstructure:"tls_key" json:"tls_key"`   // Syslog
 
 	IncludeUnits []string `mapstructure:"include_units" json:"include_units"` // Journald
 	ExcludeUnits []string `mapstructure:"exclude_units" json:"exclude_units"` // Journald
@@ -43,6 +55,13 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
+	AutoMultiLine   *bool             `mapstructure:"auto_multi_line_detection" json:"auto_multi_line_detection"`
+	JSONParsing     *JSONParsing      `mapstructure:"json_parsing" json:"json_parsing"`
+	MaxLineSize     int               `mapstructure:"max_line_size" json:"max_line_size"`
+	TruncationMode  string            `mapstructure:"truncation_mode" json:"truncation_mode"`
+
+	RateLimitLinesPerSecond int `mapstructure:"rate_limit_lines_per_second" json:"rate_limit_lines_per_second"`
+	RateLimitBytesPerSecond int `mapstructure:"rate_limit_bytes_per_second" json:"rate_limit_bytes_per_second"`
 }
 
 // Validate returns an error if the config is misconfigured
@@ -59,6 +78,23 @@ func (c *LogsConfig) Validate() error {
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == SyslogType && c.Port == 0:
+		return fmt.Errorf("syslog source must have a port")
+	case c.Type == SyslogType && c.Protocol != "" && c.Protocol != TCPType && c.Protocol != UDPType:
+		return fmt.Errorf("syslog source protocol must be tcp or udp")
+	case c.Type == SyslogType && (c.TLSCert == "") != (c.TLSKey == ""):
+		return fmt.Errorf("syslog source must have both a tls_cert and a tls_key")
+	case c.Type == SyslogType && c.TLSCert != "" && c.Protocol == UDPType:
+		return fmt.Errorf("syslog source can only use tls over tcp")
+	case c.MaxLineSize < 0:
+		return fmt.Errorf("max_line_size must be positive")
+	case c.TruncationMode != "" && c.TruncationMode != TruncationModeSplit && c.TruncationMode != TruncationModeTruncate:
+		return fmt.Errorf("truncation_mode must be %s or %s", TruncationModeSplit, TruncationModeTruncate)
+	}
+	if c.JSONParsing != nil {
+		if err := c.JSONParsing.Validate(); err != nil {
+			return err
+		}
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
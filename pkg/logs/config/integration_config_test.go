@@ -18,8 +18,14 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: FileType, Path: "/var/log/foo.log"},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
+		{Type: SyslogType, Port: 514},
+		{Type: SyslogType, Port: 514, Protocol: UDPType},
+		{Type: SyslogType, Port: 6514, TLSCert: "/etc/certs/syslog.crt", TLSKey: "/etc/certs/syslog.key"},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
+		{Type: DockerType, JSONParsing: &JSONParsing{}},
+		{Type: DockerType, MaxLineSize: 1000, TruncationMode: TruncationModeTruncate},
+		{Type: DockerType, JSONParsing: &JSONParsing{RenameAttributes: map[string]string{"lvl": "level"}, RemoveAttributes: []string{"pid"}}},
 	}
 
 	for _, config := range validConfigs {
@@ -34,6 +40,10 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: FileType},
 		{Type: TCPType},
 		{Type: UDPType},
+		{Type: SyslogType},
+		{Type: SyslogType, Port: 514, Protocol: "http"},
+		{Type: SyslogType, Port: 6514, TLSCert: "/etc/certs/syslog.crt"},
+		{Type: SyslogType, Port: 6514, Protocol: UDPType, TLSCert: "/etc/certs/syslog.crt", TLSKey: "/etc/certs/syslog.key"},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: "bar"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch}}},
@@ -41,6 +51,9 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: DockerType, JSONParsing: &JSONParsing{RenameAttributes: map[string]string{"lvl": ""}}},
+		{Type: DockerType, MaxLineSize: -1},
+		{Type: DockerType, TruncationMode: "drop"},
 	}
 
 	for _, config := range invalidConfigs {
@@ -48,3 +61,11 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		assert.NotNil(t, err)
 	}
 }
+
+func TestValidateSetsDefaultJSONParsingAttributes(t *testing.T) {
+	config := &LogsConfig{Type: DockerType, JSONParsing: &JSONParsing{StatusAttributes: []string{"lvl"}}}
+	assert.Nil(t, config.Validate())
+	assert.Equal(t, []string{"service"}, config.JSONParsing.ServiceAttributes)
+	assert.Equal(t, []string{"lvl"}, config.JSONParsing.StatusAttributes)
+	assert.Equal(t, []string{"timestamp", "@timestamp", "time"}, config.JSONParsing.TimestampAttributes)
+}
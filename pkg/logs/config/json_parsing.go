@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+)
+
+// Default attributes promoted to the reserved attributes of the json logs.
+var (
+	defaultServiceAttributes   = []string{"service"}
+	defaultStatusAttributes    = []string{"status", "level", "severity"}
+	defaultTimestampAttributes = []string{"timestamp", "@timestamp", "time"}
+)
+
+// JSONParsing defines how the json log lines of a source are parsed: the
+// top-level attributes are renamed then removed, then the service, the status
+// and the timestamp of the logs are taken from the first attribute found
+// in their list.
+type JSONParsing struct {
+	ServiceAttributes   []string          `mapstructure:"service_attributes" json:"service_attributes"`
+	StatusAttributes    []string          `mapstructure:"status_attributes" json:"status_attributes"`
+	TimestampAttributes []string          `mapstructure:"timestamp_attributes" json:"timestamp_attributes"`
+	RenameAttributes    map[string]string `mapstructure:"rename_attributes" json:"rename_attributes"`
+	RemoveAttributes    []string          `mapstructure:"remove_attributes" json:"remove_attributes"`
+}
+
+// Validate returns an error if an attribute is renamed to an empty name,
+// and sets the default attributes of the lists left empty.
+func (p *JSONParsing) Validate() error {
+	for from, to := range p.RenameAttributes {
+		if from == "" || to == "" {
+			return fmt.Errorf("json_parsing cannot rename attribute %q to %q", from, to)
+		}
+	}
+	if len(p.ServiceAttributes) == 0 {
+		p.ServiceAttributes = defaultServiceAttributes
+	}
+	if len(p.StatusAttributes) == 0 {
+		p.StatusAttributes = defaultStatusAttributes
+	}
+	if len(p.TimestampAttributes) == 0 {
+		p.TimestampAttributes = defaultTimestampAttributes
+	}
+	return nil
+}
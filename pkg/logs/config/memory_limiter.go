@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package config
+
+import (
+	"sync"
+)
+
+// MemoryLimiter bounds the number of bytes of logs held in memory by the
+// pipelines, the messages wait for the memory to be released by the ones
+// sent before them.
+type MemoryLimiter struct {
+	limit int
+	used  int
+	mu    sync.Mutex
+	cond  *sync.Cond
+}
+
+// NewMemoryLimiter returns a memory limiter allowing limit bytes of logs.
+func NewMemoryLimiter(limit int) *MemoryLimiter {
+	l := &MemoryLimiter{limit: limit}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// Acquire blocks until size bytes are available, a message bigger than the
+// limit waits for all the memory to be released. No-op on a nil limiter.
+func (l *MemoryLimiter) Acquire(size int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.used > 0 && l.used+size > l.limit {
+		l.cond.Wait()
+	}
+	l.used += size
+}
+
+// Release releases size bytes. No-op on a nil limiter.
+func (l *MemoryLimiter) Release(size int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= size
+	l.cond.Broadcast()
+}
+
+// Used returns the number of bytes currently acquired.
+func (l *MemoryLimiter) Used() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
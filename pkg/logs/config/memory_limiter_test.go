@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestMemoryLimiterBlocksUntilReleased(t *testing.T) {
+	l := NewMemoryLimiter(10)
+	l.Acquire(6)
+	assert.Equal(t, 6, l.Used())
+
+	acquired := make(chan struct{})
+	go func() {
+		l.Acquire(6)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		assert.Fail(t, "the memory should not be acquired above the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	l.Release(6)
+	<-acquired
+	assert.Equal(t, 6, l.Used())
+}
+
+func TestMemoryLimiterAllowsMessagesBiggerThanTheLimit(t *testing.T) {
+	l := NewMemoryLimiter(10)
+	l.Acquire(20)
+	assert.Equal(t, 20, l.Used())
+	l.Release(20)
+	assert.Equal(t, 0, l.Used())
+}
+
+func TestNilMemoryLimiter(t *testing.T) {
+	var l *MemoryLimiter
+	l.Acquire(20)
+	l.Release(20)
+	assert.Equal(t, 0, l.Used())
+}
+
+func TestGlobalMemoryLimiter(t *testing.T) {
+	assert.Nil(t, GlobalMemoryLimiter())
+
+	coreConfig.Datadog.Set("serverless.enabled", true)
+	defer coreConfig.Datadog.Set("serverless.enabled", false)
+	assert.True(t, IsServerless())
+	assert.NotNil(t, GlobalMemoryLimiter())
+}
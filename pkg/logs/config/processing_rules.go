@@ -25,6 +25,12 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// MatchMultiLine makes a mask_sequences rule match the aggregated
+	// multi-line logs with their line breaks
+	MatchMultiLine bool `mapstructure:"multi_line" json:"multi_line"`
+	// ExpandPlaceholder makes a mask_sequences rule expand the references
+	// to the capture groups of its pattern in its placeholder
+	ExpandPlaceholder bool `mapstructure:"expand_placeholder" json:"expand_placeholder"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
@@ -50,6 +56,13 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 			return fmt.Errorf("type %s is not supported for processing rule `%s`", rule.Type, rule.Name)
 		}
 
+		if rule.MatchMultiLine && rule.Type != MaskSequences {
+			return fmt.Errorf("multi_line is only supported by %s rules, processing rule: %s", MaskSequences, rule.Name)
+		}
+		if rule.ExpandPlaceholder && rule.Type != MaskSequences {
+			return fmt.Errorf("expand_placeholder is only supported by %s rules, processing rule: %s", MaskSequences, rule.Name)
+		}
+
 		if rule.Pattern == "" {
 			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
 		}
@@ -33,3 +33,19 @@ func TestCompileShouldFailWithInvalidRules(t *testing.T) {
 		assert.Nil(t, rule.Regex)
 	}
 }
+
+func TestValidateMultiLineMatching(t *testing.T) {
+	rules := []*ProcessingRule{{Name: "mask", Type: MaskSequences, Pattern: "(?s)BEGIN.*END", MatchMultiLine: true}}
+	assert.Nil(t, ValidateProcessingRules(rules))
+
+	rules = []*ProcessingRule{{Name: "exclude", Type: ExcludeAtMatch, Pattern: "debug", MatchMultiLine: true}}
+	assert.NotNil(t, ValidateProcessingRules(rules))
+}
+
+func TestValidateExpandPlaceholder(t *testing.T) {
+	rules := []*ProcessingRule{{Name: "mask", Type: MaskSequences, Pattern: "(?P<key>password)=\\S+", ReplacePlaceholder: "${key}=[masked]", ExpandPlaceholder: true}}
+	assert.Nil(t, ValidateProcessingRules(rules))
+
+	rules = []*ProcessingRule{{Name: "include", Type: IncludeAtMatch, Pattern: "debug", ExpandPlaceholder: true}}
+	assert.NotNil(t, ValidateProcessingRules(rules))
+}
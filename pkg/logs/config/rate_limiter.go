@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter limits the number of lines and of bytes of logs per second,
+// allowing bursts of a few seconds of traffic. It keeps track of the lines
+// dropped since they were last reported.
+type RateLimiter struct {
+	lines      *rate.Limiter
+	bytes      *rate.Limiter
+	dropped    int64
+	mu         sync.Mutex
+	lastReport time.Time
+}
+
+// NewRateLimiter returns a rate limiter allowing linesPerSecond lines and
+// bytesPerSecond bytes per second on average, and bursts of burstSeconds of
+// traffic. A limit of 0 or less is not enforced.
+func NewRateLimiter(linesPerSecond, bytesPerSecond, burstSeconds int) *RateLimiter {
+	if burstSeconds <= 0 {
+		burstSeconds = 1
+	}
+	l := &RateLimiter{lastReport: time.Now()}
+	if linesPerSecond > 0 {
+		l.lines = rate.NewLimiter(rate.Limit(linesPerSecond), linesPerSecond*burstSeconds)
+	}
+	if bytesPerSecond > 0 {
+		l.bytes = rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond*burstSeconds)
+	}
+	return l
+}
+
+// IsLimited returns whether the lines or the bytes are limited.
+func (l *RateLimiter) IsLimited() bool {
+	return l.lines != nil || l.bytes != nil
+}
+
+// reserve reserves a line of size bytes at now, it returns false when the
+// line exceeds the limits and must be dropped.
+func (l *RateLimiter) reserve(now time.Time, size int) ([]*rate.Reservation, bool) {
+	var reservations []*rate.Reservation
+	for _, r := range []struct {
+		limiter *rate.Limiter
+		n       int
+	}{{l.lines, 1}, {l.bytes, size}} {
+		if r.limiter == nil {
+			continue
+		}
+		if r.n > r.limiter.Burst() {
+			// a line bigger than the burst is accounted for as a whole burst
+			r.n = r.limiter.Burst()
+		}
+		reservation := r.limiter.ReserveN(now, r.n)
+		reservations = append(reservations, reservation)
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			return reservations, false
+		}
+	}
+	return reservations, true
+}
+
+// AddDropped adds n lines to the lines dropped since the last report.
+func (l *RateLimiter) AddDropped(n int64) {
+	atomic.AddInt64(&l.dropped, n)
+}
+
+// HasDropped returns whether lines were dropped since the last report.
+func (l *RateLimiter) HasDropped() bool {
+	return atomic.LoadInt64(&l.dropped) > 0
+}
+
+// FlushDropped returns the number of lines dropped since the last report and
+// resets it, if at least period elapsed since then, 0 otherwise.
+func (l *RateLimiter) FlushDropped(period time.Duration) int64 {
+	if atomic.LoadInt64(&l.dropped) == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Sub(l.lastReport) < period {
+		return 0
+	}
+	l.lastReport = now
+	return atomic.SwapInt64(&l.dropped, 0)
+}
+
+// AllowAll returns whether a line of size bytes is within the limits of all
+// the limiters, nil limiters are ignored. When it is not, none of the
+// limiters account for it.
+func AllowAll(size int, limiters ...*RateLimiter) bool {
+	now := time.Now()
+	var reservations []*rate.Reservation
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		r, ok := l.reserve(now, size)
+		reservations = append(reservations, r...)
+		if !ok {
+			for _, reservation := range reservations {
+				reservation.CancelAt(now)
+			}
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterWithoutLimits(t *testing.T) {
+	l := NewRateLimiter(0, 0, 1)
+	assert.False(t, l.IsLimited())
+	for i := 0; i < 1000; i++ {
+		assert.True(t, AllowAll(100, l, nil))
+	}
+}
+
+func TestRateLimiterLimitsLines(t *testing.T) {
+	l := NewRateLimiter(10, 0, 2)
+	assert.True(t, l.IsLimited())
+	// the burst allows 2 seconds of lines at once
+	for i := 0; i < 20; i++ {
+		assert.True(t, AllowAll(100, l))
+	}
+	assert.False(t, AllowAll(100, l))
+}
+
+func TestRateLimiterLimitsBytes(t *testing.T) {
+	l := NewRateLimiter(0, 100, 1)
+	assert.True(t, AllowAll(60, l))
+	assert.False(t, AllowAll(60, l))
+	assert.True(t, AllowAll(40, l))
+}
+
+func TestAllowAllDoesNotAccountForDroppedLines(t *testing.T) {
+	source := NewRateLimiter(10, 0, 1)
+	global := NewRateLimiter(1, 0, 1)
+	assert.True(t, AllowAll(10, source, global))
+	for i := 0; i < 5; i++ {
+		// dropped by the global limiter, the source limiter is not charged
+		assert.False(t, AllowAll(10, source, global))
+	}
+	for i := 0; i < 9; i++ {
+		assert.True(t, AllowAll(10, source))
+	}
+	assert.False(t, AllowAll(10, source))
+}
+
+func TestRateLimiterFlushDropped(t *testing.T) {
+	l := NewRateLimiter(1, 0, 1)
+	assert.Equal(t, int64(0), l.FlushDropped(0))
+	l.AddDropped(3)
+	assert.Equal(t, int64(0), l.FlushDropped(time.Hour))
+	assert.Equal(t, int64(3), l.FlushDropped(0))
+	assert.Equal(t, int64(0), l.FlushDropped(0))
+}
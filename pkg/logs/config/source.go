@@ -32,6 +32,9 @@ type LogSource struct {
 	// that reads log lines for this source. E.g, a sourceType == containerd and Config.Type == file means that
 	// the agent is tailing a file to read logs of a containerd container
 	sourceType SourceType
+
+	rateLimiter     *RateLimiter
+	rateLimiterOnce sync.Once
 }
 
 // NewLogSource creates a new log source.
@@ -71,6 +74,14 @@ func (s *LogSource) GetInputs() []string {
 	return inputs
 }
 
+// RateLimiter returns the rate limiter of the source, built from its config the first time.
+func (s *LogSource) RateLimiter() *RateLimiter {
+	s.rateLimiterOnce.Do(func() {
+		s.rateLimiter = NewRateLimiter(s.Config.RateLimitLinesPerSecond, s.Config.RateLimitBytesPerSecond, RateLimitBurstSeconds())
+	})
+	return s.rateLimiter
+}
+
 // SetSourceType sets a format that give information on how the source lines should be parsed
 func (s *LogSource) SetSourceType(sourceType SourceType) {
 	s.lock.Lock()
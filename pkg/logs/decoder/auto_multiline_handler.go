@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package decoder
+
+import (
+	"bytes"
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/parser"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// timestampPattern is a format of timestamp starting a new log
+type timestampPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// timestampPatterns are the formats of timestamp detected at the beginning
+// of the lines, the continuation lines (stack traces, ...) do not start with
+// a timestamp
+var timestampPatterns = []timestampPattern{
+	// 2019-04-20T10:12:34.567Z, 2019-04-20 10:12:34,567
+	{"iso8601", regexp.MustCompile(`^\[?\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`)},
+	// 2019/04/20 10:12:34
+	{"slashed_date", regexp.MustCompile(`^\[?\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`)},
+	// 20/Apr/2019:10:12:34 +0000
+	{"common_log", regexp.MustCompile(`^\[?\d{2}/[A-Za-z]{3}/\d{4}:\d{2}:\d{2}:\d{2}`)},
+	// Sat Apr 20 10:12:34 2019
+	{"ansic", regexp.MustCompile(`^\[?[A-Za-z]{3} [A-Za-z]{3} +\d{1,2} \d{2}:\d{2}:\d{2}`)},
+	// Apr 20 10:12:34
+	{"syslog", regexp.MustCompile(`^\[?[A-Za-z]{3} +\d{1,2} \d{2}:\d{2}:\d{2}`)},
+}
+
+// AutoMultiLineHandler samples the first lines of a source to detect whether
+// they start with a timestamp. The lines of the sample are sent as single
+// lines, then the following ones are aggregated by the detected pattern, or
+// stay single lines if no pattern matches enough lines.
+type AutoMultiLineHandler struct {
+	lineChan       chan []byte
+	outputChan     chan *Output
+	singleLine     *SingleLineHandler
+	parser         parser.Parser
+	lineLimit      int
+	truncate       bool
+	flushTimeout   time.Duration
+	sampleSize     int
+	matchThreshold float64
+	sampled        int
+	matches        []int // by timestamp pattern
+}
+
+// NewAutoMultiLineHandler returns a new AutoMultiLineHandler, a pattern is
+// detected when it matches at least matchThreshold of the sampleSize lines
+func NewAutoMultiLineHandler(outputChan chan *Output, parser parser.Parser, lineLimit int, truncate bool, flushTimeout time.Duration, sampleSize int, matchThreshold float64) *AutoMultiLineHandler {
+	singleLine := NewSingleLineHandler(outputChan, parser, lineLimit, truncate)
+	return &AutoMultiLineHandler{
+		lineChan:       singleLine.lineChan,
+		outputChan:     outputChan,
+		singleLine:     singleLine,
+		parser:         parser,
+		lineLimit:      lineLimit,
+		truncate:       truncate,
+		flushTimeout:   flushTimeout,
+		sampleSize:     sampleSize,
+		matchThreshold: matchThreshold,
+		matches:        make([]int, len(timestampPatterns)),
+	}
+}
+
+// Handle forwards lines to lineChan to process them
+func (h *AutoMultiLineHandler) Handle(content []byte) {
+	h.lineChan <- content
+}
+
+// Stop stops the handler from processing new lines
+func (h *AutoMultiLineHandler) Stop() {
+	close(h.lineChan)
+}
+
+// Start starts the handler
+func (h *AutoMultiLineHandler) Start() {
+	go h.run()
+}
+
+// run samples the lines until a decision is made, then hands the remaining
+// lines over to a MultiLineHandler if a pattern was detected
+func (h *AutoMultiLineHandler) run() {
+	for line := range h.lineChan {
+		h.sample(line)
+		h.singleLine.process(line)
+		if h.sampled < h.sampleSize {
+			continue
+		}
+		pattern := h.detectedPattern()
+		if pattern == nil {
+			log.Debugf("No multi-line pattern detected in %d lines, the lines will not be aggregated", h.sampled)
+			metrics.AutoMultiLinePatterns.Add("none", 1)
+			break
+		}
+		log.Debugf("Detected the %s multi-line pattern, the lines will be aggregated", pattern.name)
+		metrics.AutoMultiLinePatterns.Add(pattern.name, 1)
+		multiLine := NewMultiLineHandler(h.outputChan, pattern.re, h.flushTimeout, h.parser, h.lineLimit, h.truncate)
+		multiLine.lineChan = h.lineChan
+		multiLine.run()
+		return
+	}
+	h.singleLine.run()
+}
+
+// sample counts the timestamp patterns matching the beginning of the line
+func (h *AutoMultiLineHandler) sample(line []byte) {
+	content, _, _, _ := h.parser.Parse(line)
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return
+	}
+	h.sampled++
+	for i, pattern := range timestampPatterns {
+		if pattern.re.Match(content) {
+			h.matches[i]++
+			return
+		}
+	}
+}
+
+// detectedPattern returns the timestamp pattern matching the most sampled
+// lines, or nil if it matches too few of them
+func (h *AutoMultiLineHandler) detectedPattern() *timestampPattern {
+	best := -1
+	for i, count := range h.matches {
+		if best < 0 || count > h.matches[best] {
+			best = i
+		}
+	}
+	if best < 0 || h.sampled == 0 {
+		return nil
+	}
+	ratio := float64(h.matches[best]) / float64(h.sampled)
+	if ratio < h.matchThreshold {
+		return nil
+	}
+	return &timestampPatterns[best]
+}
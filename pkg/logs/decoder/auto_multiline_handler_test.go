@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package decoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/parser"
+)
+
+func TestAutoMultiLineHandlerDetectsPattern(t *testing.T) {
+	outputChan := make(chan *Output, 10)
+	h := NewAutoMultiLineHandler(outputChan, parser.NoopParser, 100, false, 10*time.Millisecond, 3, 0.5)
+	h.Start()
+
+	// the sampled lines are sent as single lines
+	h.Handle([]byte("2019-04-20 10:12:34 ERROR boom"))
+	h.Handle([]byte("  at foo.bar(Foo.java:42)"))
+	h.Handle([]byte("2019-04-20 10:12:35 INFO ok"))
+	assert.Equal(t, "2019-04-20 10:12:34 ERROR boom", string((<-outputChan).Content))
+	assert.Equal(t, "at foo.bar(Foo.java:42)", string((<-outputChan).Content))
+	assert.Equal(t, "2019-04-20 10:12:35 INFO ok", string((<-outputChan).Content))
+
+	// the next ones are aggregated by timestamp
+	h.Handle([]byte("2019-04-20 10:12:36 ERROR boom"))
+	h.Handle([]byte("  at foo.bar(Foo.java:42)"))
+	h.Handle([]byte("2019-04-20 10:12:37 INFO ok"))
+	assert.Equal(t, "2019-04-20 10:12:36 ERROR boom\\n  at foo.bar(Foo.java:42)", string((<-outputChan).Content))
+	assert.Equal(t, "2019-04-20 10:12:37 INFO ok", string((<-outputChan).Content))
+
+	h.Stop()
+	_, open := <-outputChan
+	assert.False(t, open)
+}
+
+func TestAutoMultiLineHandlerWithoutPattern(t *testing.T) {
+	outputChan := make(chan *Output, 10)
+	h := NewAutoMultiLineHandler(outputChan, parser.NoopParser, 100, false, 10*time.Millisecond, 2, 0.5)
+	h.Start()
+
+	h.Handle([]byte("first"))
+	h.Handle([]byte("second"))
+	h.Handle([]byte("2019-04-20 10:12:36 third"))
+	h.Handle([]byte("fourth"))
+	for _, line := range []string{"first", "second", "2019-04-20 10:12:36 third", "fourth"} {
+		assert.Equal(t, line, string((<-outputChan).Content))
+	}
+
+	h.Stop()
+	_, open := <-outputChan
+	assert.False(t, open)
+}
+
+func TestDetectedPattern(t *testing.T) {
+	h := NewAutoMultiLineHandler(nil, parser.NoopParser, 100, false, time.Second, 4, 0.5)
+	for _, line := range []string{"Apr 20 10:12:34 host app: started", "  continued", "", "Apr 20 10:12:35 host app: done", "Sat Apr 20 10:12:34 2019 other"} {
+		h.sample([]byte(line))
+	}
+	assert.Equal(t, 4, h.sampled)
+	assert.Equal(t, "syslog", h.detectedPattern().name)
+
+	h.matchThreshold = 0.6
+	assert.Nil(t, h.detectedPattern())
+}
@@ -9,13 +9,10 @@ import (
 	"bytes"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 	"github.com/DataDog/datadog-agent/pkg/logs/parser"
 )
 
-// defaultContentLenLimit represents the length limit above which we want to
-// truncate the output content
-const defaultContentLenLimit = 256 * 1000
-
 // Input represents a list of bytes consumed by the Decoder
 type Input struct {
 	content []byte
@@ -32,6 +29,8 @@ type Output struct {
 	Status     string
 	RawDataLen int
 	Timestamp  string
+	// Truncated is true when the content is a part of a line longer than the line size limit
+	Truncated bool
 }
 
 // NewOutput returns a new output.
@@ -44,6 +43,16 @@ func NewOutput(content []byte, status string, rawDataLen int, timestamp string)
 	}
 }
 
+// newTruncatedOutput returns a new output, the truncated outputs are counted.
+func newTruncatedOutput(content []byte, status string, rawDataLen int, timestamp string, truncated bool) *Output {
+	output := NewOutput(content, status, rawDataLen, timestamp)
+	if truncated {
+		output.Truncated = true
+		metrics.LogsTruncated.Add(1)
+	}
+	return output
+}
+
 // Decoder splits raw data into lines and passes them to a lineHandler that emits outputs
 type Decoder struct {
 	InputChan  chan *Input
@@ -58,15 +67,19 @@ type Decoder struct {
 func InitializeDecoder(source *config.LogSource, parser parser.Parser) *Decoder {
 	inputChan := make(chan *Input)
 	outputChan := make(chan *Output)
-	lineLimit := defaultContentLenLimit
+	lineLimit := config.MaxLineSize(source.Config)
+	truncate := config.ShouldTruncate(source.Config)
 	var lineHandler LineHandler
 	for _, rule := range source.Config.ProcessingRules {
 		if rule.Type == config.MultiLine {
-			lineHandler = NewMultiLineHandler(outputChan, rule.Regex, defaultFlushTimeout, parser, lineLimit)
+			lineHandler = NewMultiLineHandler(outputChan, rule.Regex, defaultFlushTimeout, parser, lineLimit, truncate)
 		}
 	}
+	if lineHandler == nil && config.AutoMultiLineEnabled(source.Config) {
+		lineHandler = NewAutoMultiLineHandler(outputChan, parser, lineLimit, truncate, defaultFlushTimeout, config.AutoMultiLineSampleSize(), config.AutoMultiLineMatchThreshold())
+	}
 	if lineHandler == nil {
-		lineHandler = NewSingleLineHandler(outputChan, parser, lineLimit)
+		lineHandler = NewSingleLineHandler(outputChan, parser, lineLimit, truncate)
 	}
 
 	return New(inputChan, outputChan, lineHandler, lineLimit)
@@ -31,15 +31,20 @@ type SingleLineHandler struct {
 	shouldTruncate bool
 	parser         parser.Parser
 	lineLimit      int
+	truncate       bool
+	droppedLen     int // length of the data dropped since the last output
 }
 
-// NewSingleLineHandler returns a new SingleLineHandler
-func NewSingleLineHandler(outputChan chan *Output, parser parser.Parser, lineLimit int) *SingleLineHandler {
+// NewSingleLineHandler returns a new SingleLineHandler, the remainder of the
+// lines longer than lineLimit is dropped when truncate is true, it is sent in
+// following outputs otherwise
+func NewSingleLineHandler(outputChan chan *Output, parser parser.Parser, lineLimit int, truncate bool) *SingleLineHandler {
 	return &SingleLineHandler{
 		lineChan:   make(chan []byte),
 		outputChan: outputChan,
 		parser:     parser,
 		lineLimit:  lineLimit,
+		truncate:   truncate,
 	}
 }
 
@@ -71,12 +76,23 @@ func (h *SingleLineHandler) run() {
 // When lines are too long, they are truncated
 func (h *SingleLineHandler) process(line []byte) {
 	lineLen := len(line)
+	if h.truncate && h.shouldTruncate {
+		// drop the remainder of the truncated line, its length is accounted
+		// for with the next output
+		h.droppedLen += lineLen
+		if lineLen < h.lineLimit {
+			h.droppedLen++ // '\n'
+			h.shouldTruncate = false
+		}
+		return
+	}
 	line = bytes.TrimSpace(line)
 	if len(line) == 0 {
 		return
 	}
 
 	var content []byte
+	truncated := h.shouldTruncate
 	if h.shouldTruncate {
 		// add TRUNCATED at the beginning of content
 		content = append(TRUNCATED, line...)
@@ -94,7 +110,8 @@ func (h *SingleLineHandler) process(line []byte) {
 			log.Debug(err)
 		}
 		if len(output) > 0 {
-			h.outputChan <- NewOutput(output, status, lineLen+1, timestamp)
+			h.outputChan <- newTruncatedOutput(output, status, h.droppedLen+lineLen+1, timestamp, truncated)
+			h.droppedLen = 0
 		}
 	} else {
 		// add TRUNCATED at the end of content and send it
@@ -104,7 +121,8 @@ func (h *SingleLineHandler) process(line []byte) {
 			log.Debug(err)
 		}
 		if len(output) > 0 {
-			h.outputChan <- NewOutput(output, status, lineLen, timestamp)
+			h.outputChan <- newTruncatedOutput(output, status, h.droppedLen+lineLen, timestamp, true)
+			h.droppedLen = 0
 			h.shouldTruncate = true
 		}
 	}
@@ -125,10 +143,16 @@ type MultiLineHandler struct {
 	flushTimeout      time.Duration
 	parser            parser.Parser
 	lineLimit         int
+	truncate          bool
+	truncated         bool // the content of lineBuffer is truncated
+	dropping          bool // the lines are dropped until a new content starts
+	droppedLen        int  // length of the data dropped since the last output
 }
 
-// NewMultiLineHandler returns a new MultiLineHandler
-func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, flushTimeout time.Duration, parser parser.Parser, lineLimit int) *MultiLineHandler {
+// NewMultiLineHandler returns a new MultiLineHandler, the remainder of the
+// contents longer than lineLimit is dropped when truncate is true, it is sent
+// in following outputs otherwise
+func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, flushTimeout time.Duration, parser parser.Parser, lineLimit int, truncate bool) *MultiLineHandler {
 	return &MultiLineHandler{
 		lineChan:     make(chan []byte),
 		outputChan:   outputChan,
@@ -137,6 +161,7 @@ func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, f
 		flushTimeout: flushTimeout,
 		parser:       parser,
 		lineLimit:    lineLimit,
+		truncate:     truncate,
 	}
 }
 
@@ -202,6 +227,12 @@ func (h *MultiLineHandler) process(line []byte) {
 	if h.newContentRe.Match(unwrappedLine) {
 		// send content from lineBuffer
 		h.sendContent()
+		h.dropping = false
+	} else if h.dropping {
+		// drop the remainder of the truncated content, its length is
+		// accounted for with the next output
+		h.droppedLen += len(line) + 1
+		return
 	}
 	if !h.lineBuffer.IsEmpty() {
 		// unwrap all the following lines
@@ -219,16 +250,26 @@ func (h *MultiLineHandler) process(line []byte) {
 		// add line and truncate and flush content in lineBuffer
 		h.lineBuffer.AddIncompleteLine(line)
 		h.lineBuffer.AddTruncate(line)
+		h.truncated = true
 		// send content from lineBuffer
 		h.sendContent()
+		if h.truncate {
+			// drop the next lines until a new content starts
+			h.dropping = true
+			return
+		}
 		// truncate next content
 		h.lineBuffer.AddTruncate(line)
+		h.truncated = true
 	}
 }
 
 // sendContent forwards the content from lineBuffer to outputChan
 func (h *MultiLineHandler) sendContent() {
-	defer h.lineBuffer.Reset()
+	defer func() {
+		h.lineBuffer.Reset()
+		h.truncated = false
+	}()
 	content, rawDataLen := h.lineBuffer.Content()
 	content = bytes.TrimSpace(content)
 	if len(content) > 0 {
@@ -241,7 +282,8 @@ func (h *MultiLineHandler) sendContent() {
 			// log line, in order to be useful to setLastSince function, we need to replace
 			// it with the ts of the last log line. Note: this timestamp is NOT used for stamp
 			// the log record, it's ONLY used to recover well when tailing back the container.
-			h.outputChan <- NewOutput(output, status, rawDataLen, h.lastSeenTimestamp)
+			h.outputChan <- newTruncatedOutput(output, status, h.droppedLen+rawDataLen, h.lastSeenTimestamp, h.truncated)
+			h.droppedLen = 0
 		}
 	}
 }
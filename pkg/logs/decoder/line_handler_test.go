@@ -68,7 +68,7 @@ func (u *MockFailingParser) Parse(msg []byte) ([]byte, string, string, error) {
 
 func TestSingleLineHandler(t *testing.T) {
 	outputChan := make(chan *Output, 10)
-	h := NewSingleLineHandler(outputChan, parser.NoopParser, 100)
+	h := NewSingleLineHandler(outputChan, parser.NoopParser, 100, false)
 	h.Start()
 
 	var output *Output
@@ -103,13 +103,41 @@ func TestSingleLineHandler(t *testing.T) {
 	output = <-outputChan
 	assert.Equal(t, string(TRUNCATED)+line, string(output.Content))
 	assert.Equal(t, len(line)+1, output.RawDataLen)
+	assert.True(t, output.Truncated)
+
+	h.Stop()
+}
+
+func TestSingleLineHandlerTruncateMode(t *testing.T) {
+	outputChan := make(chan *Output, 10)
+	h := NewSingleLineHandler(outputChan, parser.NoopParser, 100, true)
+	h.Start()
+
+	var output *Output
+
+	// the first part of a too long line is sent
+	line := strings.Repeat("a", contentLenLimit)
+	h.Handle([]byte(line))
+	output = <-outputChan
+	assert.Equal(t, line+string(TRUNCATED), string(output.Content))
+	assert.Equal(t, len(line), output.RawDataLen)
+	assert.True(t, output.Truncated)
+
+	// the remainder is dropped and accounted for with the next line
+	h.Handle([]byte(line))
+	h.Handle([]byte("bbb"))
+	h.Handle([]byte("hello world"))
+	output = <-outputChan
+	assert.Equal(t, "hello world", string(output.Content))
+	assert.Equal(t, len(line)+len("bbb")+1+len("hello world")+1, output.RawDataLen)
+	assert.False(t, output.Truncated)
 
 	h.Stop()
 }
 
 func TestTrimSingleLine(t *testing.T) {
 	outputChan := make(chan *Output, 10)
-	h := NewSingleLineHandler(outputChan, parser.NoopParser, 100)
+	h := NewSingleLineHandler(outputChan, parser.NoopParser, 100, false)
 	h.Start()
 
 	var output *Output
@@ -128,7 +156,7 @@ func TestTrimSingleLine(t *testing.T) {
 func TestMultiLineHandler(t *testing.T) {
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputChan := make(chan *Output, 10)
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, parser.NoopParser, 20)
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, parser.NoopParser, 20, false)
 	h.Start()
 
 	var output *Output
@@ -160,6 +188,7 @@ func TestMultiLineHandler(t *testing.T) {
 	output = <-outputChan
 	assert.Equal(t, "...TRUNCATED...con", string(output.Content))
 	assert.Equal(t, 4, output.RawDataLen)
+	assert.True(t, output.Truncated)
 
 	// second line + TRUNCATED too long
 	h.Handle([]byte("4. stringssssssize20"))
@@ -204,10 +233,37 @@ func TestMultiLineHandler(t *testing.T) {
 	h.Stop()
 }
 
+func TestMultiLineHandlerTruncateMode(t *testing.T) {
+	re := regexp.MustCompile("[0-9]+\\.")
+	outputChan := make(chan *Output, 10)
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, parser.NoopParser, 20, true)
+	h.Start()
+
+	var output *Output
+
+	// the lines following a truncated content are dropped until a new content starts
+	h.Handle([]byte("1. stringssssssize20"))
+	h.Handle([]byte("continue"))
+	h.Handle([]byte("end"))
+	h.Handle([]byte("2. next"))
+
+	output = <-outputChan
+	assert.Equal(t, "1. stringssssssize20...TRUNCATED...", string(output.Content))
+	assert.Equal(t, len("1. stringssssssize20"), output.RawDataLen)
+	assert.True(t, output.Truncated)
+
+	output = <-outputChan
+	assert.Equal(t, "2. next", string(output.Content))
+	assert.Equal(t, len("continue\n")+len("end\n")+len("2. next\n"), output.RawDataLen)
+	assert.False(t, output.Truncated)
+
+	h.Stop()
+}
+
 func TestTrimMultiLine(t *testing.T) {
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputChan := make(chan *Output, 10)
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, parser.NoopParser, 100)
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, parser.NoopParser, 100, false)
 	h.Start()
 
 	var output *Output
@@ -231,7 +287,7 @@ func TestTrimMultiLine(t *testing.T) {
 func TestSingleLineHandlerDropsEmptyMessages(t *testing.T) {
 	const header = "HEADER"
 	outputChan := make(chan *Output, 10)
-	h := NewSingleLineHandler(outputChan, NewMockParser(header), 100)
+	h := NewSingleLineHandler(outputChan, NewMockParser(header), 100, false)
 	h.Start()
 
 	line := header
@@ -248,7 +304,7 @@ func TestMultiLineHandlerDropsEmptyMessages(t *testing.T) {
 	const header = "HEADER"
 	outputChan := make(chan *Output, 10)
 	re := regexp.MustCompile("[0-9]+\\.")
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewMockParser(header), 100)
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewMockParser(header), 100, false)
 	h.Start()
 
 	h.Handle([]byte(header))
@@ -265,7 +321,7 @@ func TestMultiLineHandlerDropsEmptyMessages(t *testing.T) {
 func TestSingleLineHandlerSendsRawInvalidMessages(t *testing.T) {
 	const header = "HEADER"
 	outputChan := make(chan *Output, 10)
-	h := NewSingleLineHandler(outputChan, NewMockFailingParser(header), 100)
+	h := NewSingleLineHandler(outputChan, NewMockFailingParser(header), 100, false)
 	h.Start()
 
 	h.Handle([]byte("one message"))
@@ -280,7 +336,7 @@ func TestMultiLineHandlerSendsRawInvalidMessages(t *testing.T) {
 	const header = "HEADER"
 	outputChan := make(chan *Output, 10)
 	re := regexp.MustCompile("[0-9]+\\.")
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewMockFailingParser(header), 100)
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewMockFailingParser(header), 100, false)
 	h.Start()
 
 	h.Handle([]byte("1.third line"))
@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package diagnostic streams the messages processed by the logs agent, to
+// troubleshoot the processing rules and the tags of the logs without waiting
+// for them to reach the intake.
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// streamBufferSize is the number of formatted messages buffered per stream,
+// the messages are dropped when a stream is not read fast enough.
+const streamBufferSize = 100
+
+// MessageReceiver receives the messages once they are processed, right
+// before they are encoded to be sent.
+type MessageReceiver interface {
+	HandleMessage(msg *message.Message, content []byte)
+}
+
+// Filters select the messages of a stream, an empty filter matches every
+// message.
+type Filters struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Source    string `json:"source"`
+	Service   string `json:"service"`
+	Container string `json:"container"`
+}
+
+// stream is a client of the BufferedMessageReceiver
+type stream struct {
+	filters Filters
+	lines   chan string
+}
+
+// BufferedMessageReceiver formats the messages it receives and buffers them
+// for the streams matching them. It does nothing while there is no stream, so
+// it doesn't slow the pipelines down, and never blocks them.
+type BufferedMessageReceiver struct {
+	m        sync.RWMutex
+	streams  map[*stream]struct{}
+	nStreams int32
+	dropped  int64
+}
+
+// NewBufferedMessageReceiver returns a new BufferedMessageReceiver
+func NewBufferedMessageReceiver() *BufferedMessageReceiver {
+	return &BufferedMessageReceiver{
+		streams: make(map[*stream]struct{}),
+	}
+}
+
+// Subscribe opens a stream of the messages matching filters. The function
+// returned must be called to close it.
+func (b *BufferedMessageReceiver) Subscribe(filters Filters) (<-chan string, func()) {
+	s := &stream{
+		filters: filters,
+		lines:   make(chan string, streamBufferSize),
+	}
+
+	b.m.Lock()
+	b.streams[s] = struct{}{}
+	atomic.StoreInt32(&b.nStreams, int32(len(b.streams)))
+	b.m.Unlock()
+
+	unsubscribe := func() {
+		b.m.Lock()
+		defer b.m.Unlock()
+		delete(b.streams, s)
+		atomic.StoreInt32(&b.nStreams, int32(len(b.streams)))
+	}
+	return s.lines, unsubscribe
+}
+
+// Dropped returns the number of messages dropped because a stream was full
+func (b *BufferedMessageReceiver) Dropped() int64 {
+	return atomic.LoadInt64(&b.dropped)
+}
+
+// HandleMessage sends the message to the streams matching it, content is the
+// message once the processing rules are applied.
+func (b *BufferedMessageReceiver) HandleMessage(msg *message.Message, content []byte) {
+	if atomic.LoadInt32(&b.nStreams) == 0 {
+		return
+	}
+
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	var line string
+	for s := range b.streams {
+		if !s.filters.match(msg) {
+			continue
+		}
+		if line == "" {
+			line = formatMessage(msg, content)
+		}
+		select {
+		case s.lines <- line:
+		default:
+			atomic.AddInt64(&b.dropped, 1)
+		}
+	}
+}
+
+// match returns whether the message matches all the filters
+func (f Filters) match(msg *message.Message) bool {
+	origin := msg.Origin
+	if f.Name != "" && f.Name != origin.LogSource.Name {
+		return false
+	}
+	if f.Type != "" && f.Type != origin.LogSource.Config.Type {
+		return false
+	}
+	if f.Source != "" && f.Source != origin.Source() {
+		return false
+	}
+	if f.Service != "" && f.Service != origin.Service() {
+		return false
+	}
+	if f.Container != "" && !matchContainer(f.Container, origin) {
+		return false
+	}
+	return true
+}
+
+// matchContainer returns whether the message comes from the container with
+// the given name or id, ids can be shortened.
+func matchContainer(container string, origin *message.Origin) bool {
+	entityID := origin.EntityID()
+	if idx := strings.Index(entityID, "://"); idx >= 0 && strings.HasPrefix(entityID[idx+3:], container) {
+		return true
+	}
+	for _, tag := range origin.Tags() {
+		if tag == "container_name:"+container || tag == "container_id:"+container {
+			return true
+		}
+	}
+	return false
+}
+
+// formatMessage renders a message with its metadata on a single line
+func formatMessage(msg *message.Message, content []byte) string {
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	origin := msg.Origin
+	return fmt.Sprintf("Integration Name: %s | Type: %s | Status: %s | Timestamp: %s | Service: %s | Source: %s | Tags: %s | Message: %s\n",
+		origin.LogSource.Name,
+		origin.LogSource.Config.Type,
+		msg.GetStatus(),
+		timestamp.Format(time.RFC3339Nano),
+		origin.Service(),
+		origin.Source(),
+		strings.Join(origin.Tags(), ","),
+		content,
+	)
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newMessage(content string, source *config.LogSource, entityID string, tags ...string) *message.Message {
+	origin := message.NewOrigin(source)
+	origin.SetEntityID(entityID)
+	origin.SetTags(tags)
+	return message.NewMessage([]byte(content), origin, message.StatusInfo)
+}
+
+func TestHandleMessageWithoutStream(t *testing.T) {
+	b := NewBufferedMessageReceiver()
+	source := config.NewLogSource("nginx", &config.LogsConfig{Type: config.FileType})
+
+	// nothing is buffered while nobody streams the messages
+	b.HandleMessage(newMessage("hello", source, ""), []byte("hello"))
+	assert.Equal(t, int64(0), b.Dropped())
+
+	lines, unsubscribe := b.Subscribe(Filters{})
+	unsubscribe()
+	b.HandleMessage(newMessage("hello", source, ""), []byte("hello"))
+	assert.Len(t, lines, 0)
+}
+
+func TestHandleMessageFilters(t *testing.T) {
+	b := NewBufferedMessageReceiver()
+	nginx := config.NewLogSource("nginx", &config.LogsConfig{Type: config.DockerType, Source: "nginx", Service: "web"})
+	redis := config.NewLogSource("redis", &config.LogsConfig{Type: config.DockerType, Source: "redis"})
+
+	all, unsubscribeAll := b.Subscribe(Filters{})
+	defer unsubscribeAll()
+	web, unsubscribeWeb := b.Subscribe(Filters{Service: "web"})
+	defer unsubscribeWeb()
+	container, unsubscribeContainer := b.Subscribe(Filters{Container: "a5901276aed1"})
+	defer unsubscribeContainer()
+	named, unsubscribeNamed := b.Subscribe(Filters{Source: "redis", Container: "cache"})
+	defer unsubscribeNamed()
+
+	b.HandleMessage(newMessage("GET /", nginx, "docker://a5901276aed16ae9ea11660a41fecd674da47e8f", "env:prod"), []byte("GET / [masked]"))
+	b.HandleMessage(newMessage("PING", redis, "docker://fecd674da47e8f", "container_name:cache"), []byte("PING"))
+
+	assert.Len(t, all, 2)
+	assert.Len(t, web, 1)
+	assert.Len(t, container, 1)
+	assert.Len(t, named, 1)
+
+	line := <-web
+	assert.Contains(t, line, "Integration Name: nginx | Type: docker | Status: info |")
+	assert.Contains(t, line, "| Service: web | Source: nginx | Tags: env:prod | Message: GET / [masked]\n")
+	assert.Contains(t, <-named, "Message: PING")
+}
+
+func TestHandleMessageDropsWhenFull(t *testing.T) {
+	b := NewBufferedMessageReceiver()
+	source := config.NewLogSource("nginx", &config.LogsConfig{})
+
+	lines, unsubscribe := b.Subscribe(Filters{})
+	defer unsubscribe()
+	for i := 0; i < streamBufferSize+5; i++ {
+		b.HandleMessage(newMessage("hello", source, ""), []byte("hello"))
+	}
+	assert.Len(t, lines, streamBufferSize)
+	assert.Equal(t, int64(5), b.Dropped())
+}
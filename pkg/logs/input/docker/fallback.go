@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build docker
+
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/input/file"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	dockerutil "github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// defaultContainersPath is the directory where the json-file logging driver
+// writes the logs of the containers by default.
+const defaultContainersPath = "/var/lib/docker/containers"
+
+// lastLineWindow is the number of bytes read backward to find the last line
+// read in a json log file.
+const lastLineWindow = 256 * 1000
+
+// jsonLogLine represents a line written by the json-file logging driver.
+type jsonLogLine struct {
+	Time time.Time `json:"time"`
+}
+
+// recovery keeps track of a container whose logs can't be streamed from the
+// docker API anymore, its json log file is tailed in the meantime.
+type recovery struct {
+	containerID string
+	source      *config.LogSource
+	fileTailer  *file.Tailer
+	stop        chan struct{}
+}
+
+// newRecovery returns a new recovery for the container.
+func newRecovery(containerID string, source *config.LogSource) *recovery {
+	return &recovery{
+		containerID: containerID,
+		source:      source,
+		stop:        make(chan struct{}),
+	}
+}
+
+// defaultLogPath returns the path of the json log file of the container when
+// it can't be inspected.
+func defaultLogPath(containerID string) string {
+	return filepath.Join(defaultContainersPath, containerID, containerID+"-json.log")
+}
+
+// startFileTailer starts tailing the json log file of the container from the
+// first line logged at since or later.
+func (r *recovery) startFileTailer(path string, since time.Time, outputChan chan *message.Message) error {
+	offset, err := findOffset(path, since)
+	if err != nil {
+		return err
+	}
+	tailer := file.NewTailer(outputChan, newFileSource(r.source, path, r.containerID), path, file.DefaultSleepDuration, false)
+	if err := tailer.Start(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.fileTailer = tailer
+	return nil
+}
+
+// stopFileTailer stops tailing the json log file of the container and
+// returns the date following the last line read, since otherwise.
+func (r *recovery) stopFileTailer(path string, since time.Time) time.Time {
+	if r.fileTailer == nil {
+		return since
+	}
+	r.fileTailer.Stop()
+	offset := r.fileTailer.GetReadOffset()
+	r.fileTailer = nil
+	if timestamp, err := lastTimestamp(path, offset); err == nil && !timestamp.Before(since) {
+		return timestamp.Add(time.Nanosecond)
+	}
+	return since
+}
+
+// newFileSource returns a copy of the source of the container to tail its json
+// log file, the lines are parsed and tagged as the ones of the docker API.
+func newFileSource(source *config.LogSource, path string, containerID string) *config.LogSource {
+	logsConfig := *source.Config
+	logsConfig.Type = config.FileType
+	logsConfig.Path = path
+	logsConfig.Identifier = dockerutil.ContainerIDToEntityName(containerID)
+	fileSource := config.NewLogSource(source.Name, &logsConfig)
+	// the kubernetes parser handles the lines of the json-file logging driver
+	fileSource.SetSourceType(config.KubernetesSourceType)
+	fileSource.Status = source.Status
+	return fileSource
+}
+
+// findOffset returns the offset of the first line of the json log file logged
+// at since or later, or the size of the file when there is none.
+func findOffset(path string, since time.Time) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var offset int64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// an incomplete line is read later on by the tailer
+			return offset, nil
+		}
+		if timestamp, ok := parseLineTimestamp(line); ok && !timestamp.Before(since) {
+			return offset, nil
+		}
+		offset += int64(len(line))
+	}
+}
+
+// lastTimestamp returns the timestamp of the last complete line of the json log
+// file ending before offset.
+func lastTimestamp(path string, offset int64) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	start := offset - lastLineWindow
+	if start < 0 {
+		start = 0
+	}
+	buf := make([]byte, offset-start)
+	n, err := f.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return time.Time{}, err
+	}
+	buf = buf[:n]
+	// skip the incomplete line at the end of the window
+	buf = buf[:bytes.LastIndexByte(buf, '\n')+1]
+	for len(buf) > 0 {
+		end := bytes.LastIndexByte(buf[:len(buf)-1], '\n') + 1
+		if timestamp, ok := parseLineTimestamp(buf[end:]); ok {
+			return timestamp, nil
+		}
+		buf = buf[:end]
+	}
+	return time.Time{}, io.EOF
+}
+
+// parseLineTimestamp returns the timestamp of a line of a json log file.
+func parseLineTimestamp(line []byte) (time.Time, bool) {
+	var logLine jsonLogLine
+	if err := json.Unmarshal(line, &logLine); err != nil || logLine.Time.IsZero() {
+		return time.Time{}, false
+	}
+	return logLine.Time, true
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build docker
+
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+const (
+	line1 = `{"log":"first\n","stream":"stdout","time":"2019-04-20T10:12:34.000000001Z"}` + "\n"
+	line2 = `{"log":"second\n","stream":"stderr","time":"2019-04-20T10:12:35.000000002Z"}` + "\n"
+	line3 = `{"log":"third\n","stream":"stdout","time":"2019-04-20T10:12:36.000000003Z"}` + "\n"
+)
+
+func writeLogFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "docker-fallback")
+	require.Nil(t, err)
+	path := filepath.Join(dir, "container-json.log")
+	require.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func parseTime(t *testing.T, value string) time.Time {
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	require.Nil(t, err)
+	return timestamp
+}
+
+func TestFindOffset(t *testing.T) {
+	path, cleanup := writeLogFile(t, line1+line2+line3+`{"log":"incompl`)
+	defer cleanup()
+
+	offset, err := findOffset(path, time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	offset, err = findOffset(path, parseTime(t, "2019-04-20T10:12:35.000000002Z"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(line1)), offset)
+
+	offset, err = findOffset(path, parseTime(t, "2019-04-20T10:12:35.000000003Z"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(line1+line2)), offset)
+
+	offset, err = findOffset(path, parseTime(t, "2019-04-21T00:00:00Z"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(line1+line2+line3)), offset)
+
+	_, err = findOffset(path+".missing", time.Time{})
+	assert.NotNil(t, err)
+}
+
+func TestLastTimestamp(t *testing.T) {
+	path, cleanup := writeLogFile(t, line1+line2+line3)
+	defer cleanup()
+
+	timestamp, err := lastTimestamp(path, int64(len(line1+line2+line3)))
+	assert.Nil(t, err)
+	assert.Equal(t, parseTime(t, "2019-04-20T10:12:36.000000003Z"), timestamp)
+
+	// the incomplete line ending at the offset is ignored
+	timestamp, err = lastTimestamp(path, int64(len(line1+line2)+10))
+	assert.Nil(t, err)
+	assert.Equal(t, parseTime(t, "2019-04-20T10:12:35.000000002Z"), timestamp)
+
+	timestamp, err = lastTimestamp(path, int64(len(line1)))
+	assert.Nil(t, err)
+	assert.Equal(t, parseTime(t, "2019-04-20T10:12:34.000000001Z"), timestamp)
+
+	_, err = lastTimestamp(path, 10)
+	assert.NotNil(t, err)
+}
+
+func TestNewFileSource(t *testing.T) {
+	source := config.NewLogSource("foo", &config.LogsConfig{Type: config.DockerType, Service: "bar", Source: "baz"})
+	fileSource := newFileSource(source, "/var/lib/docker/containers/123/123-json.log", "123")
+
+	assert.Equal(t, "foo", fileSource.Name)
+	assert.Equal(t, config.FileType, fileSource.Config.Type)
+	assert.Equal(t, "/var/lib/docker/containers/123/123-json.log", fileSource.Config.Path)
+	assert.Equal(t, "docker://123", fileSource.Config.Identifier)
+	assert.Equal(t, "bar", fileSource.Config.Service)
+	assert.Equal(t, "baz", fileSource.Config.Source)
+	assert.Equal(t, config.KubernetesSourceType, fileSource.GetSourceType())
+	assert.Equal(t, source.Status, fileSource.Status)
+
+	// the source of the container is left untouched
+	assert.Equal(t, config.DockerType, source.Config.Type)
+	assert.Equal(t, "", source.Config.Path)
+}
+
+func TestDefaultLogPath(t *testing.T) {
+	assert.Equal(t, "/var/lib/docker/containers/123/123-json.log", defaultLogPath("123"))
+}
@@ -8,11 +8,13 @@
 package docker
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/docker/docker/client"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
@@ -37,12 +39,14 @@ type Launcher struct {
 	activeSources      []*config.LogSource
 	pendingContainers  map[string]*Container
 	tailers            map[string]*Tailer
+	recoveries         map[string]*recovery
 	cli                *client.Client
 	registry           auditor.Registry
 	stop               chan struct{}
 	erroredContainerID chan string
 	lock               *sync.Mutex
 	collectAllSource   *config.LogSource
+	fileFallback       bool
 }
 
 // NewLauncher returns a new launcher
@@ -50,11 +54,13 @@ func NewLauncher(sources *config.LogSources, services *service.Services, pipelin
 	launcher := &Launcher{
 		pipelineProvider:   pipelineProvider,
 		tailers:            make(map[string]*Tailer),
+		recoveries:         make(map[string]*recovery),
 		pendingContainers:  make(map[string]*Container),
 		registry:           registry,
 		stop:               make(chan struct{}),
 		erroredContainerID: make(chan string),
 		lock:               &sync.Mutex{},
+		fileFallback:       coreConfig.Datadog.GetBool("logs_config.docker_file_fallback"),
 	}
 	err := launcher.setup()
 	if err != nil {
@@ -95,10 +101,16 @@ func (l *Launcher) Start() {
 func (l *Launcher) Stop() {
 	l.stop <- struct{}{}
 	stopper := restart.NewParallelStopper()
-	for _, tailer := range l.tailers {
+	l.lock.Lock()
+	for containerID, tailer := range l.tailers {
 		stopper.Add(tailer)
-		l.removeTailer(tailer.ContainerID)
+		delete(l.tailers, containerID)
+	}
+	for containerID, recovery := range l.recoveries {
+		close(recovery.stop)
+		delete(l.recoveries, containerID)
 	}
+	l.lock.Unlock()
 	stopper.Stop()
 }
 
@@ -223,58 +235,107 @@ func (l *Launcher) startTailer(container *Container, source *config.LogSource) {
 
 // stopTailer stops the tailer matching the containerID.
 func (l *Launcher) stopTailer(containerID string) {
-	if tailer, isTailed := l.tailers[containerID]; isTailed {
+	l.lock.Lock()
+	tailer, isTailed := l.tailers[containerID]
+	recovery, isRecovering := l.recoveries[containerID]
+	delete(l.tailers, containerID)
+	delete(l.recoveries, containerID)
+	l.lock.Unlock()
+
+	if isTailed || isRecovering {
 		// No-op if the tailer source came from AD
 		if l.collectAllSource != nil {
 			l.collectAllSource.RemoveInput(containerID)
 		}
+	}
+	if isTailed {
 		go tailer.Stop()
-		l.removeTailer(containerID)
+	}
+	if isRecovering {
+		close(recovery.stop)
 	}
 }
 
+// restartTailer stops the tailer of a container whose logs can't be streamed
+// from the docker API anymore and recovers it.
 func (l *Launcher) restartTailer(containerID string) {
-	backoffDuration := backoffInitialDuration
-	cumulatedBackoff := 0 * time.Second
-	var source *config.LogSource
-
+	l.lock.Lock()
 	oldTailer, exists := l.tailers[containerID]
-	if exists {
-		source = oldTailer.source
-		if l.collectAllSource != nil {
-			l.collectAllSource.RemoveInput(containerID)
-		}
-		oldTailer.Stop()
-		l.removeTailer(containerID)
+	if !exists {
+		l.lock.Unlock()
+		return
 	}
+	recovery := newRecovery(containerID, oldTailer.source)
+	delete(l.tailers, containerID)
+	l.recoveries[containerID] = recovery
+	l.lock.Unlock()
 
-	tailer := NewTailer(l.cli, containerID, source, l.pipelineProvider.NextPipelineChan(), l.erroredContainerID)
+	oldTailer.Stop()
 
-	// compute the offset to prevent from missing or duplicating logs
-	since, err := Since(l.registry, tailer.Identifier(), service.Before)
+	// resume from the last line processed to prevent from missing or duplicating logs
+	since, err := time.Parse(config.DateFormat, oldTailer.getLastSince())
 	if err != nil {
-		log.Warnf("Could not recover last committed offset for container %v: %v", ShortContainerID(containerID), err)
+		since, err = Since(l.registry, oldTailer.Identifier(), service.Before)
+		if err != nil {
+			log.Warnf("Could not recover last committed offset for container %v: %v", ShortContainerID(containerID), err)
+		}
 	}
+	l.recover(recovery, since)
+}
 
+// recover restarts streaming the logs of the container from the docker API
+// with an exponential backoff, the json log file of the container is tailed
+// until it succeeds so that no logs are missed.
+func (l *Launcher) recover(recovery *recovery, since time.Time) {
+	containerID := recovery.containerID
+	backoffDuration := backoffInitialDuration
+	logPath := ""
 	for {
-		if backoffDuration > backoffMaxDuration {
-			log.Warnf("Could not resume tailing container %v", ShortContainerID(containerID))
-			return
+		inspect, err := l.cli.ContainerInspect(context.Background(), containerID)
+		if err == nil {
+			logPath = inspect.LogPath
+			since = recovery.stopFileTailer(logPath, since)
+			tailer := NewTailer(l.cli, containerID, recovery.source, l.pipelineProvider.NextPipelineChan(), l.erroredContainerID)
+			err = tailer.Start(since)
+			if err == nil {
+				l.lock.Lock()
+				if _, isRecovering := l.recoveries[containerID]; !isRecovering {
+					// the container was stopped in the meantime
+					l.lock.Unlock()
+					go tailer.Stop()
+					return
+				}
+				delete(l.recoveries, containerID)
+				l.tailers[containerID] = tailer
+				l.lock.Unlock()
+				recovery.source.AddInput(containerID)
+				log.Infof("Resumed tailing container %v from the docker API", ShortContainerID(containerID))
+				return
+			}
 		}
+		log.Warnf("Could not start tailer for container %v: %v", ShortContainerID(containerID), err)
 
-		// start the tailer
-		err = tailer.Start(since)
-		if err != nil {
-			log.Warnf("Could not start tailer for container %v: %v", ShortContainerID(containerID), err)
-			time.Sleep(backoffDuration)
-			cumulatedBackoff += backoffDuration
-			backoffDuration *= 2
-			continue
+		if l.fileFallback && recovery.fileTailer == nil {
+			if logPath == "" {
+				logPath = defaultLogPath(containerID)
+			}
+			if err := recovery.startFileTailer(logPath, since, l.pipelineProvider.NextPipelineChan()); err != nil {
+				log.Debugf("Could not tail the log file of container %v: %v", ShortContainerID(containerID), err)
+			} else {
+				log.Infof("Tailing container %v from its log file %v until the docker API is available", ShortContainerID(containerID), logPath)
+			}
+		}
+
+		select {
+		case <-recovery.stop:
+			recovery.stopFileTailer(logPath, since)
+			return
+		case <-time.After(backoffDuration):
+		}
+		backoffDuration *= 2
+		if backoffDuration > backoffMaxDuration {
+			backoffDuration = backoffMaxDuration
 		}
-		// keep the tailer in track to stop it later on
-		l.addTailer(containerID, tailer)
-		source.AddInput(containerID)
-		return
 	}
 }
 
@@ -283,9 +344,3 @@ func (l *Launcher) addTailer(containerID string, tailer *Tailer) {
 	l.tailers[containerID] = tailer
 	l.lock.Unlock()
 }
-
-func (l *Launcher) removeTailer(containerID string) {
-	l.lock.Lock()
-	delete(l.tailers, containerID)
-	l.lock.Unlock()
-}
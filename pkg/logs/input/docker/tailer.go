@@ -244,7 +244,10 @@ func (t *Tailer) forwardMessages() {
 			t.setLastSince(output.Timestamp)
 			origin.Identifier = t.Identifier()
 			origin.SetTags(t.tagProvider.GetTags())
-			t.outputChan <- message.NewMessage(output.Content, origin, output.Status)
+			origin.SetEntityID(dockerutil.ContainerIDToEntityName(t.ContainerID))
+			msg := message.NewMessage(output.Content, origin, output.Status)
+			msg.Truncated = output.Truncated
+			t.outputChan <- msg
 		}
 	}
 }
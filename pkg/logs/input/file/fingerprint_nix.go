@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build !windows
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fingerprint returns the device and inode of a file, a file replaced by
+// another one at the same path has a different fingerprint
+func fingerprint(fi os.FileInfo) string {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino)
+}
@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build windows
+
+package file
+
+import (
+	"os"
+)
+
+// fingerprint is not supported on Windows, the file index is not exposed by
+// os.FileInfo, only the truncated files are detected
+func fingerprint(fi os.FileInfo) string {
+	return ""
+}
@@ -35,3 +35,24 @@ func DidRotate(file *os.File, lastReadOffset int64) (bool, error) {
 
 	return recreated || truncated, nil
 }
+
+// DidChange returns true if the file at path is not the one whose offset was
+// registered, when it was replaced or truncated while it was not tailed.
+func DidChange(path string, offset int64, registeredFingerprint string) bool {
+	f, err := openFile(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	currentFingerprint := fingerprint(fi)
+	replaced := registeredFingerprint != "" && currentFingerprint != "" && registeredFingerprint != currentFingerprint
+	truncated := fi.Size() < offset
+
+	return replaced || truncated
+}
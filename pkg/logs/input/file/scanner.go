@@ -6,6 +6,7 @@
 package file
 
 import (
+	"io"
 	"sync/atomic"
 	"time"
 
@@ -132,7 +133,7 @@ func (s *Scanner) scan() {
 		if err != nil {
 			continue
 		}
-		if didRotate {
+		if didRotate || tailer.DidTruncate() {
 			// restart tailer because of file-rotation on file
 			succeeded := s.restartTailerAfterFileRotation(tailer, file)
 			if !succeeded {
@@ -205,6 +206,11 @@ func (s *Scanner) startNewTailer(file *File, tailFromBeginning bool) bool {
 	if err != nil {
 		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
 	}
+	if whence == io.SeekStart && offset > 0 && DidChange(file.Path, offset, s.registry.GetFingerprint(tailer.Identifier())) {
+		// the file was rotated since the offset was committed, none of its lines were collected
+		log.Infof("File %v changed since its offset was committed, tailing it from the beginning", file.Path)
+		offset = 0
+	}
 
 	err = tailer.Start(offset, whence)
 	if err != nil {
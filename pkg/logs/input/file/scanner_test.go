@@ -285,3 +285,38 @@ func TestScannerScanWithTooManyFiles(t *testing.T) {
 	scanner.scan()
 	assert.Equal(t, 2, len(scanner.tailers))
 }
+
+func TestScannerStartNewTailerWithChangedFile(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := fmt.Sprintf("%s/test.log", testDir)
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	_, err = file.WriteString("hello\nworld\n")
+	assert.Nil(t, err)
+
+	// the registry holds the offset of another file, rotated since then
+	registry := auditor.NewRegistry()
+	registry.SetOffset("100")
+	registry.SetFingerprint("0:0")
+
+	sleepDuration := 20 * time.Millisecond
+	scanner := NewScanner(config.NewLogSources(), 2, mock.NewMockProvider(), registry, sleepDuration)
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	scanner.activeSources = append(scanner.activeSources, source)
+	status.Clear()
+	status.CreateSources([]*config.LogSource{source})
+	defer status.Clear()
+
+	scanner.scan()
+	assert.Equal(t, 1, len(scanner.tailers))
+	tailer := scanner.tailers[path]
+	msg := <-tailer.outputChan
+	assert.Equal(t, "hello", string(msg.Content))
+	assert.NotEmpty(t, msg.Origin.Fingerprint)
+	msg = <-tailer.outputChan
+	assert.Equal(t, "world", string(msg.Content))
+	scanner.cleanup()
+}
@@ -50,6 +50,8 @@ type Tailer struct {
 	closeTimeout  time.Duration
 	shouldStop    int32
 	didFileRotate int32
+	didTruncate   int32
+	fingerprint   string
 	stop          chan struct{}
 	done          chan struct{}
 }
@@ -124,6 +126,9 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	}
 
 	t.file = f
+	if fi, err := f.Stat(); err == nil {
+		t.fingerprint = fingerprint(fi)
+	}
 	ret, _ := f.Seek(offset, whence)
 	t.readOffset = ret
 	t.decodedOffset = ret
@@ -150,6 +155,13 @@ func (t *Tailer) readForever() {
 			// stop reading data from file
 			return
 		default:
+			if atomic.LoadInt32(&t.didTruncate) != 0 {
+				// the file was truncated in place, reading it from the current
+				// offset would skip or duplicate lines, the scanner restarts
+				// a tailer from the beginning of the file
+				t.wait()
+				continue
+			}
 			// keep reading data from file
 			inBuf := make([]byte, 4096)
 			n, err := t.file.Read(inBuf)
@@ -161,11 +173,14 @@ func (t *Tailer) readForever() {
 			}
 			if n == 0 {
 				// wait for new data to come
+				t.checkTruncation()
 				t.wait()
 				continue
 			}
-			t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+			// the offset is updated first, the rotation checks compare it
+			// with the file size as soon as the data is decoded
 			t.incrementReadOffset(n)
+			t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
 		}
 	}
 }
@@ -226,8 +241,14 @@ func (t *Tailer) forwardMessages() {
 		origin := message.NewOrigin(t.source)
 		origin.Identifier = identifier
 		origin.Offset = strconv.FormatInt(offset, 10)
+		if identifier != "" {
+			origin.Fingerprint = t.fingerprint
+		}
 		origin.SetTags(append(t.tags, t.tagProvider.GetTags()...))
-		t.outputChan <- message.NewMessage(output.Content, origin, output.Status)
+		origin.SetEntityID(t.source.Config.Identifier)
+		msg := message.NewMessage(output.Content, origin, output.Status)
+		msg.Truncated = output.Truncated
+		t.outputChan <- msg
 	}
 }
 
@@ -240,6 +261,23 @@ func (t *Tailer) GetReadOffset() int64 {
 	return atomic.LoadInt64(&t.readOffset)
 }
 
+// checkTruncation flags the tailer when its file is shorter than the read
+// offset, as it happens to the files rotated with copytruncate
+func (t *Tailer) checkTruncation() {
+	fi, err := t.file.Stat()
+	if err != nil || fi.Size() >= t.GetReadOffset() {
+		return
+	}
+	if atomic.CompareAndSwapInt32(&t.didTruncate, 0, 1) {
+		log.Info("File truncated: ", t.path)
+	}
+}
+
+// DidTruncate returns whether the file of the tailer was truncated in place
+func (t *Tailer) DidTruncate() bool {
+	return atomic.LoadInt32(&t.didTruncate) != 0
+}
+
 // shouldTrackOffset returns whether the tailer should track the file offset or not
 func (t *Tailer) shouldTrackOffset() bool {
 	if atomic.LoadInt32(&t.didFileRotate) != 0 {
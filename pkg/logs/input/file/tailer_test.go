@@ -158,6 +158,24 @@ func (suite *TailerTestSuite) TestRecoverTailing() {
 	suite.Equal(len(lines[0])+len(lines[1])+len(lines[2]), int(suite.tl.decodedOffset))
 }
 
+func (suite *TailerTestSuite) TestTailerDetectsTruncation() {
+	var err error
+
+	_, err = suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+
+	suite.tl.StartFromBeginning()
+	<-suite.outputChan
+	suite.False(suite.tl.DidTruncate())
+
+	err = suite.testFile.Truncate(0)
+	suite.Nil(err)
+	for i := 0; i < 100 && !suite.tl.DidTruncate(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	suite.True(suite.tl.DidTruncate())
+}
+
 func (suite *TailerTestSuite) TestTailerIdentifier() {
 	suite.tl.StartFromBeginning()
 	suite.Equal(fmt.Sprintf("file:%s/tailer.log", suite.testDir), suite.tl.Identifier())
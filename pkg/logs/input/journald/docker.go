@@ -11,7 +11,6 @@ import (
 	"github.com/coreos/go-systemd/sdjournal"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger"
-	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	dockerutil "github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -31,9 +30,14 @@ func (t *Tailer) getContainerID(entry *sdjournal.JournalEntry) string {
 	return containerID
 }
 
+// getContainerEntityID returns the tagger entity of a given container.
+func (t *Tailer) getContainerEntityID(containerID string) string {
+	return dockerutil.ContainerIDToEntityName(containerID)
+}
+
 // getContainerTags returns all the tags of a given container.
 func (t *Tailer) getContainerTags(containerID string) []string {
-	tags, err := tagger.Tag(dockerutil.ContainerIDToEntityName(containerID), collectors.HighCardinality)
+	tags, err := tagger.Tag(t.getContainerEntityID(containerID), tagger.LogsCardinality)
 	if err != nil {
 		log.Warn(err)
 	}
@@ -225,6 +225,9 @@ func (t *Tailer) getOrigin(entry *sdjournal.JournalEntry) *message.Origin {
 	origin.SetSource(applicationName)
 	origin.SetService(applicationName)
 	origin.SetTags(t.getTags(entry))
+	if t.isContainerEntry(entry) {
+		origin.SetEntityID(t.getContainerEntityID(t.getContainerID(entry)))
+	}
 	return origin
 }
 
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// dockerJSONLine is a line of a log file written by the docker json-file
+// logging driver, the files of /var/log/pods link to them when docker is
+// the container runtime.
+// Example:
+// {"log":"This is my message\n","stream":"stdout","time":"2018-09-20T11:54:11.753589172Z"}
+type dockerJSONLine struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// isDockerJSONLine returns whether the line was written by the docker
+// json-file logging driver, CRI lines start with a timestamp
+func isDockerJSONLine(msg []byte) bool {
+	return len(msg) > 0 && msg[0] == '{'
+}
+
+// parseDockerJSON parses a docker json-file log line, the trailing newline
+// of the log is removed.
+func parseDockerJSON(msg []byte) ([]byte, string, string, error) {
+	var line dockerJSONLine
+	if err := json.Unmarshal(msg, &line); err != nil {
+		return msg, message.StatusInfo, "", fmt.Errorf("cannot parse the docker log line: %v", err)
+	}
+	content := bytes.TrimSuffix([]byte(line.Log), []byte{'\n'})
+	return content, getStatus([]byte(line.Stream)), line.Time, nil
+}
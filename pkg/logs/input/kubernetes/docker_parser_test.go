@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestParserShouldDetectDockerJSONLines(t *testing.T) {
+	content, status, timestamp, err := Parser.Parse([]byte(`{"log":"This is my message\n","stream":"stdout","time":"2018-09-20T11:54:11.753589172Z"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("This is my message"), content)
+	assert.Equal(t, message.StatusInfo, status)
+	assert.Equal(t, "2018-09-20T11:54:11.753589172Z", timestamp)
+
+	content, status, _, err = Parser.Parse([]byte(`{"log":"{\"error\":\"foo\"}\n","stream":"stderr","time":"2018-09-20T11:54:11.753589172Z"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(`{"error":"foo"}`), content)
+	assert.Equal(t, message.StatusError, status)
+
+	// the CRI lines are still parsed
+	content, _, _, err = Parser.Parse([]byte(containerdHeaderOut + " {\"foo\":\"bar\"}"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(`{"foo":"bar"}`), content)
+}
+
+func TestParserShouldFailWithInvalidDockerJSONLine(t *testing.T) {
+	log := []byte(`{"log":"truncated`)
+	content, status, timestamp, err := Parser.Parse(log)
+	assert.NotNil(t, err)
+	assert.Equal(t, log, content)
+	assert.Equal(t, message.StatusInfo, status)
+	assert.Equal(t, "", timestamp)
+}
@@ -133,14 +133,9 @@ func (l *Launcher) addSource(svc *service.Service) {
 		return
 	}
 
-	switch svc.Type {
-	case config.DockerType:
-		// docker uses the NJSON format as opposed to other container runtimes
-		// to write log lines to files so there is no need for the custom kubernetes parser.
-		break
-	default:
-		source.SetSourceType(config.KubernetesSourceType)
-	}
+	// the kubernetes parser detects whether the lines are written by docker
+	// or by another container runtime
+	source.SetSourceType(config.KubernetesSourceType)
 
 	l.sourcesByContainer[svc.GetEntityID()] = source
 	l.sources.AddSource(source)
@@ -31,12 +31,18 @@ type parser struct {
 	lineParser.Parser
 }
 
-// Parse parses a Kubernetes log line.
-// Kubernetes log lines follow this pattern '<timestamp> <stream> <flag> <content>',
+// Parse parses a Kubernetes log line, the format of the line is detected
+// from its first character as the files of /var/log/pods are written by
+// the container runtime: docker writes json lines, the other runtimes
+// write CRI lines.
+// CRI log lines follow this pattern '<timestamp> <stream> <flag> <content>',
 // see https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/kuberuntime/logs/logs.go
 // Example:
 // 2018-09-20T11:54:11.753589172Z stdout F This is my message
 func (p *parser) Parse(msg []byte) ([]byte, string, string, error) {
+	if isDockerJSONLine(msg) {
+		return parseDockerJSON(msg)
+	}
 	content, status, timestamp, _, err := parse(msg)
 	return content, status, timestamp, err
 }
@@ -17,6 +17,7 @@ type Launcher struct {
 	frameSize        int
 	tcpSources       chan *config.LogSource
 	udpSources       chan *config.LogSource
+	syslogSources    chan *config.LogSource
 	listeners        []restart.Restartable
 	stop             chan struct{}
 }
@@ -28,6 +29,7 @@ func NewLauncher(sources *config.LogSources, frameSize int, pipelineProvider pip
 		frameSize:        frameSize,
 		tcpSources:       sources.GetAddedForType(config.TCPType),
 		udpSources:       sources.GetAddedForType(config.UDPType),
+		syslogSources:    sources.GetAddedForType(config.SyslogType),
 		stop:             make(chan struct{}),
 	}
 }
@@ -49,6 +51,16 @@ func (l *Launcher) run() {
 			listener := NewUDPListener(l.pipelineProvider, source, l.frameSize)
 			listener.Start()
 			l.listeners = append(l.listeners, listener)
+		case source := <-l.syslogSources:
+			// syslog is received over tcp unless specified otherwise
+			var listener restart.Restartable
+			if source.Config.Protocol == config.UDPType {
+				listener = NewUDPListener(l.pipelineProvider, source, l.frameSize)
+			} else {
+				listener = NewTCPListener(l.pipelineProvider, source, l.frameSize)
+			}
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
 		case <-l.stop:
 			return
 		}
@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package listener
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/parser"
+)
+
+// severityStatusMapping maps the syslog severities (0 to 7) to statuses.
+var severityStatusMapping = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// utf8BOM may start the message of an RFC5424 message.
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// rfc3164Header matches the timestamp, the hostname and the tag of an RFC3164 message,
+// ex: "Apr 20 10:12:34 myhost sshd[1234]: "
+var rfc3164Header = regexp.MustCompile(`^([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[([^\]]*)\])?: ?`)
+
+// syslogAttributes represents the attributes parsed from the header of a syslog message.
+type syslogAttributes struct {
+	Facility       int    `json:"facility"`
+	Severity       int    `json:"severity"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	Hostname       string `json:"hostname,omitempty"`
+	AppName        string `json:"appname,omitempty"`
+	ProcID         string `json:"procid,omitempty"`
+	MsgID          string `json:"msgid,omitempty"`
+	StructuredData string `json:"structured_data,omitempty"`
+}
+
+// syslogPayload represents the content of a syslog message sent to the intake.
+type syslogPayload struct {
+	Message string           `json:"message"`
+	Syslog  syslogAttributes `json:"syslog"`
+}
+
+// syslogParser parses RFC3164 and RFC5424 syslog messages, the priority and the
+// header fields are bundled in a "syslog" attribute of a json content:
+// * syslog-message:
+//  <34>1 2019-04-20T10:12:34Z myhost sshd 1234 - - login failed
+// * message-content:
+//  {
+//    "message": "login failed",
+//    "syslog": {
+//      "facility": 4,
+//      "severity": 2,
+//      ...
+//    }
+//  }
+type syslogParser struct {
+	parser.Parser
+}
+
+// Parse parses a syslog message, the message is returned as is with an error
+// when it does not start with a priority.
+func (p *syslogParser) Parse(msg []byte) ([]byte, string, string, error) {
+	priority, rest, err := parsePriority(msg)
+	if err != nil {
+		return msg, message.StatusInfo, "", err
+	}
+	payload := syslogPayload{
+		Syslog: syslogAttributes{
+			Facility: priority / 8,
+			Severity: priority % 8,
+		},
+	}
+	if len(rest) > 2 && rest[0] == '1' && rest[1] == ' ' {
+		payload.Message = parseRFC5424(rest[2:], &payload.Syslog)
+	} else {
+		payload.Message = parseRFC3164(rest, &payload.Syslog)
+	}
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return msg, message.StatusInfo, "", err
+	}
+	return content, severityStatusMapping[payload.Syslog.Severity], "", nil
+}
+
+// parsePriority returns the priority of the message and the remaining bytes.
+func parsePriority(msg []byte) (int, []byte, error) {
+	end := bytes.IndexByte(msg, '>')
+	if len(msg) == 0 || msg[0] != '<' || end < 2 || end > 4 {
+		return 0, msg, fmt.Errorf("cannot parse the syslog priority of the message")
+	}
+	priority, err := strconv.Atoi(string(msg[1:end]))
+	if err != nil || priority > 191 {
+		return 0, msg, fmt.Errorf("invalid syslog priority: %s", msg[1:end])
+	}
+	return priority, msg[end+1:], nil
+}
+
+// parseRFC5424 fills the attributes with the header fields following the
+// version of an RFC5424 message and returns its message.
+func parseRFC5424(msg []byte, attributes *syslogAttributes) string {
+	fields := []*string{&attributes.Timestamp, &attributes.Hostname, &attributes.AppName, &attributes.ProcID, &attributes.MsgID}
+	for _, field := range fields {
+		var value []byte
+		value, msg = nextField(msg)
+		if string(value) != "-" {
+			*field = string(value)
+		}
+	}
+	attributes.StructuredData, msg = parseStructuredData(msg)
+	return string(bytes.TrimPrefix(msg, utf8BOM))
+}
+
+// parseStructuredData returns the structured data starting the message, if any,
+// and the remaining bytes.
+func parseStructuredData(msg []byte) (string, []byte) {
+	if len(msg) > 0 && msg[0] == '-' {
+		_, msg = nextField(msg)
+		return "", msg
+	}
+	end := 0
+	for end < len(msg) && msg[end] == '[' {
+		// skip the escaped closing brackets of the param values
+		i := end + 1
+		for i < len(msg) && msg[i] != ']' {
+			if msg[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(msg) {
+			break
+		}
+		end = i + 1
+	}
+	return string(msg[:end]), bytes.TrimPrefix(msg[end:], []byte{' '})
+}
+
+// parseRFC3164 fills the attributes with the header of an RFC3164 message and
+// returns its message, the whole content is the message when there is no header.
+func parseRFC3164(msg []byte, attributes *syslogAttributes) string {
+	match := rfc3164Header.FindSubmatch(msg)
+	if match == nil {
+		return string(msg)
+	}
+	attributes.Timestamp = string(match[1])
+	attributes.Hostname = string(match[2])
+	attributes.AppName = string(match[3])
+	attributes.ProcID = string(match[4])
+	return string(msg[len(match[0]):])
+}
+
+// nextField returns the bytes before the next space and the bytes following it.
+func nextField(msg []byte) ([]byte, []byte) {
+	i := bytes.IndexByte(msg, ' ')
+	if i < 0 {
+		return msg, nil
+	}
+	return msg[:i], msg[i+1:]
+}
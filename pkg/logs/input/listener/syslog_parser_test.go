@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package listener
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func parseSyslog(t *testing.T, msg string) (syslogPayload, string) {
+	content, status, _, err := (&syslogParser{}).Parse([]byte(msg))
+	assert.Nil(t, err)
+	var payload syslogPayload
+	assert.Nil(t, json.Unmarshal(content, &payload))
+	return payload, status
+}
+
+func TestSyslogParserRFC5424(t *testing.T) {
+	payload, status := parseSyslog(t, `<34>1 2019-04-20T10:12:34.567Z myhost sshd 1234 ID47 [origin ip="10.0.0.1"][meta x="a\]b"] login failed`)
+	assert.Equal(t, message.StatusCritical, status)
+	assert.Equal(t, "login failed", payload.Message)
+	assert.Equal(t, syslogAttributes{
+		Facility:       4,
+		Severity:       2,
+		Timestamp:      "2019-04-20T10:12:34.567Z",
+		Hostname:       "myhost",
+		AppName:        "sshd",
+		ProcID:         "1234",
+		MsgID:          "ID47",
+		StructuredData: `[origin ip="10.0.0.1"][meta x="a\]b"]`,
+	}, payload.Syslog)
+
+	payload, status = parseSyslog(t, "<165>1 - - - - - - \xEF\xBB\xBFhello world")
+	assert.Equal(t, message.StatusNotice, status)
+	assert.Equal(t, "hello world", payload.Message)
+	assert.Equal(t, syslogAttributes{Facility: 20, Severity: 5}, payload.Syslog)
+}
+
+func TestSyslogParserRFC3164(t *testing.T) {
+	payload, status := parseSyslog(t, "<13>Apr  2 10:12:34 myhost sshd[1234]: login failed")
+	assert.Equal(t, message.StatusNotice, status)
+	assert.Equal(t, "login failed", payload.Message)
+	assert.Equal(t, syslogAttributes{
+		Facility:  1,
+		Severity:  5,
+		Timestamp: "Apr  2 10:12:34",
+		Hostname:  "myhost",
+		AppName:   "sshd",
+		ProcID:    "1234",
+	}, payload.Syslog)
+
+	// appliances often send messages without header
+	payload, status = parseSyslog(t, "<11>link down on port 3")
+	assert.Equal(t, message.StatusError, status)
+	assert.Equal(t, "link down on port 3", payload.Message)
+	assert.Equal(t, syslogAttributes{Facility: 1, Severity: 3}, payload.Syslog)
+}
+
+func TestSyslogParserWithInvalidPriority(t *testing.T) {
+	for _, msg := range []string{"hello world", "<>hello", "<abc>hello", "<192>hello", "<13hello"} {
+		content, status, _, err := (&syslogParser{}).Parse([]byte(msg))
+		assert.NotNil(t, err)
+		assert.Equal(t, msg, string(content))
+		assert.Equal(t, message.StatusInfo, status)
+	}
+}
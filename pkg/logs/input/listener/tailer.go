@@ -6,6 +6,7 @@
 package listener
 
 import (
+	"fmt"
 	"io"
 	"net"
 
@@ -24,18 +25,27 @@ type Tailer struct {
 	outputChan chan *message.Message
 	read       func(*Tailer) ([]byte, error)
 	decoder    *decoder.Decoder
+	tags       []string
 	stop       chan struct{}
 	done       chan struct{}
 }
 
 // NewTailer returns a new Tailer
 func NewTailer(source *config.LogSource, conn net.Conn, outputChan chan *message.Message, read func(*Tailer) ([]byte, error)) *Tailer {
+	var p parser.Parser = parser.NoopParser
+	var tags []string
+	if source.Config.Type == config.SyslogType {
+		// the syslog messages are tagged by listening port to tell the appliances apart
+		p = &syslogParser{}
+		tags = []string{fmt.Sprintf("syslog_port:%d", source.Config.Port)}
+	}
 	return &Tailer{
 		source:     source,
 		conn:       conn,
 		outputChan: outputChan,
 		read:       read,
-		decoder:    decoder.InitializeDecoder(source, parser.NoopParser),
+		decoder:    decoder.InitializeDecoder(source, p),
+		tags:       tags,
 		stop:       make(chan struct{}, 1),
 		done:       make(chan struct{}, 1),
 	}
@@ -62,10 +72,11 @@ func (t *Tailer) forwardMessages() {
 		t.done <- struct{}{}
 	}()
 	for output := range t.decoder.OutputChan {
-		t.outputChan <- message.NewMessageWithSource(
-			output.Content,
-			message.StatusInfo,
-			t.source)
+		origin := message.NewOrigin(t.source)
+		origin.SetTags(t.tags)
+		msg := message.NewMessage(output.Content, origin, output.Status)
+		msg.Truncated = output.Truncated
+		t.outputChan <- msg
 	}
 }
 
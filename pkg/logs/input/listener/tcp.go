@@ -6,6 +6,7 @@
 package listener
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -62,7 +63,9 @@ func (l *TCPListener) Stop() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.stop <- struct{}{}
-	l.listener.Close()
+	if l.listener != nil {
+		l.listener.Close()
+	}
 	stopper := restart.NewParallelStopper()
 	for _, tailer := range l.tailers {
 		stopper.Add(tailer)
@@ -104,11 +107,19 @@ func (l *TCPListener) run() {
 }
 
 // startListener starts a new listener, returns an error if it failed.
+// The listener terminates the TLS connections when the source has a certificate.
 func (l *TCPListener) startListener() error {
+	tlsConfig, err := buildTLSConfig(l.source.Config)
+	if err != nil {
+		return fmt.Errorf("invalid tls configuration: %v", err)
+	}
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", l.source.Config.Port))
 	if err != nil {
 		return err
 	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
 	l.listener = listener
 	return nil
 }
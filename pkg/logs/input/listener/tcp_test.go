@@ -6,10 +6,18 @@
 package listener
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -65,3 +73,50 @@ func TestTCPDoesNotTruncateMessagesThatAreBiggerThanTheReadBufferSize(t *testing
 
 	listener.Stop()
 }
+
+func TestTCPShouldReceiveSyslogMessagesOverTLS(t *testing.T) {
+	certPEM, keyPEM := generateCertificate(t)
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Port: tcpTestPort, TLSCert: certPEM, TLSKey: keyPEM})
+	listener := NewTCPListener(pp, source, 9000)
+	listener.Start()
+
+	conn, err := tls.Dial("tcp", fmt.Sprintf("%s", listener.listener.Addr()), &tls.Config{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+
+	fmt.Fprintf(conn, "<11>1 2019-04-20T10:12:34Z myhost app - - - link down\n")
+	msg := <-msgChan
+	assert.Contains(t, string(msg.Content), `"message":"link down"`)
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, []string{"syslog_port:0"}, msg.Origin.Tags())
+
+	conn.Close()
+	listener.Stop()
+}
+
+func TestTCPShouldFailWithInvalidTLSConfig(t *testing.T) {
+	pp := mock.NewMockProvider()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Port: tcpTestPort, TLSCert: "/does/not/exist.crt", TLSKey: "/does/not/exist.key"})
+	listener := NewTCPListener(pp, source, 9000)
+	listener.Start()
+	assert.True(t, source.Status.IsError())
+	listener.Stop()
+}
+
+// generateCertificate returns a PEM encoded self-signed certificate and its key.
+func generateCertificate(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(certPEM), string(keyPEM)
+}
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package listener
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io/ioutil"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+// pemPrefix starts the PEM encoded certificates and keys.
+var pemPrefix = []byte("-----BEGIN")
+
+// buildTLSConfig returns the TLS config of the source, or nil if the source
+// does not terminate TLS connections.
+func buildTLSConfig(c *config.LogsConfig) (*tls.Config, error) {
+	if c.TLSCert == "" {
+		return nil, nil
+	}
+	certPEM, err := readPEM(c.TLSCert)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := readPEM(c.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// readPEM returns the PEM content of value, value is either the path of a
+// PEM file or the PEM content itself, as resolved from the secrets backend.
+func readPEM(value string) ([]byte, error) {
+	content := []byte(value)
+	if bytes.HasPrefix(bytes.TrimSpace(content), pemPrefix) {
+		return content, nil
+	}
+	return ioutil.ReadFile(value)
+}
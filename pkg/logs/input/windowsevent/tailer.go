@@ -23,6 +23,7 @@ const (
 	binaryPath   = "Event.EventData.Binary"
 	dataPath     = "Event.EventData.Data"
 	taskPath     = "Event.System.Task"
+	levelPath    = "Event.System.Level"
 	fabricPrefix = "Microsoft-ServiceFabric/"
 )
 
@@ -45,7 +46,9 @@ type Tailer struct {
 	stop       chan struct{}
 	done       chan struct{}
 
-	context *eventContext
+	context      *eventContext
+	subscription evtSubscriptionHandle
+	bookmark     evtBookmarkHandle
 }
 
 // NewTailer returns a new tailer.
@@ -119,7 +122,35 @@ func (t *Tailer) toMessage(event string) (*message.Message, error) {
 	}
 	jsonEvent = replaceTextKeyToValue(jsonEvent)
 	log.Debug("Sending JSON:", string(jsonEvent))
-	return message.NewMessageWithSource(jsonEvent, message.StatusInfo, t.source), nil
+	return message.NewMessageWithSource(jsonEvent, extractStatus(mv), t.source), nil
+}
+
+// levelStatusMapping maps the levels of the events to statuses, the levels are listed at:
+// https://docs.microsoft.com/en-us/windows/desktop/wes/eventschema-levels
+var levelStatusMapping = map[string]string{
+	"1": message.StatusCritical,
+	"2": message.StatusError,
+	"3": message.StatusWarning,
+	"4": message.StatusInfo,
+	"5": message.StatusDebug,
+}
+
+// extractStatus returns the status matching the level in {"Event": {"System": {"Level": <LEVEL> }}},
+// StatusInfo when the level is missing or unknown
+func extractStatus(mv mxj.Map) string {
+	values, err := mv.ValuesForPath(levelPath)
+	if err != nil || len(values) == 0 {
+		return message.StatusInfo
+	}
+	level, ok := values[0].(string)
+	if !ok {
+		return message.StatusInfo
+	}
+	status, exists := levelStatusMapping[level]
+	if !exists {
+		return message.StatusInfo
+	}
+	return status
 }
 
 // extractTaskName looks for the TASK_ID in {"Event": {"System": {"Task": <TASK_ID> }}}
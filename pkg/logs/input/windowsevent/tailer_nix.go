@@ -11,8 +11,14 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// evtSubscriptionHandle is not used on this system
+type evtSubscriptionHandle uintptr
+
+// evtBookmarkHandle is not used on this system
+type evtBookmarkHandle uintptr
+
 // Start does not do much
-func (t *Tailer) Start() {
+func (t *Tailer) Start(bookmark string) {
 	log.Warn("windows event log not supported on this system")
 	go t.tail()
 }
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
 func TestToMessage(t *testing.T) {
@@ -76,3 +78,26 @@ func TestToMessageWithFabric(t *testing.T) {
 	actual, _ = tailer.toMessage(evt5)
 	assert.Equal(t, expected5, string(actual.Content))
 }
+
+func TestToMessageStatus(t *testing.T) {
+	tailer := NewTailer(nil, &Config{ChannelPath: "System"}, nil)
+	for level, status := range map[string]string{
+		"1": message.StatusCritical,
+		"2": message.StatusError,
+		"3": message.StatusWarning,
+		"4": message.StatusInfo,
+		"5": message.StatusDebug,
+		"0": message.StatusInfo,
+	} {
+		evt := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Service Control Manager'/><EventID>7036</EventID><Level>` + level + `</Level><Channel>System</Channel></System></Event>`
+		actual, err := tailer.toMessage(evt)
+		assert.Nil(t, err)
+		assert.Equal(t, status, actual.GetStatus())
+	}
+
+	// Without <Level></Level>
+	evt := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><EventID>7036</EventID></System></Event>`
+	actual, err := tailer.toMessage(evt)
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusInfo, actual.GetStatus())
+}
@@ -14,6 +14,7 @@ package windowsevent
 import "C"
 
 import (
+	"fmt"
 	"syscall"
 	"unsafe"
 
@@ -21,10 +22,16 @@ import (
 	"golang.org/x/sys/windows"
 )
 
-// Start starts tailing the event log.
-func (t *Tailer) Start() {
+// evtSubscriptionHandle is the handle of a subscription to a channel
+type evtSubscriptionHandle uintptr
+
+// evtBookmarkHandle is the handle of the bookmark of the last event sent
+type evtBookmarkHandle uintptr
+
+// Start starts tailing the event log, after the bookmark when it is set.
+func (t *Tailer) Start(bookmark string) {
 	log.Infof("Starting windows event log tailing for channel %s query %s", t.config.ChannelPath, t.config.Query)
-	go t.tail()
+	go t.tail(bookmark)
 }
 
 // Stop stops the tailer
@@ -35,25 +42,61 @@ func (t *Tailer) Stop() {
 }
 
 // tail subscribes to the channel for the windows events
-func (t *Tailer) tail() {
+func (t *Tailer) tail(bookmark string) {
 	t.context = &eventContext{
 		id: indexForTailer(t),
 	}
-	C.startEventSubscribe(
+	flags := EvtSubscribeToFutureEvents
+	handle, err := evtCreateBookmark(bookmark)
+	if err != nil && bookmark != "" {
+		// the bookmark can't be restored, only the new events are collected
+		log.Warnf("Could not restore the bookmark of channel %s: %v", t.config.ChannelPath, err)
+		handle, err = evtCreateBookmark("")
+	}
+	if err != nil {
+		log.Warnf("Could not create a bookmark for channel %s, the events will not be tracked: %v", t.config.ChannelPath, err)
+	} else if bookmark != "" {
+		flags = EvtSubscribeStartAfterBookmark
+	}
+	t.bookmark = handle
+	t.subscription = evtSubscriptionHandle(C.startEventSubscribe(
 		C.CString(t.config.ChannelPath),
 		C.CString(t.config.Query),
-		C.ULONGLONG(0),
-		C.int(EvtSubscribeToFutureEvents),
+		C.ULONGLONG(t.bookmark),
+		C.int(flags),
 		C.PVOID(uintptr(unsafe.Pointer(t.context))),
-	)
-	t.source.Status.Success()
+	))
+	if t.subscription == 0 {
+		t.source.Status.Error(fmt.Errorf("could not subscribe to channel %s with query %s", t.config.ChannelPath, t.config.Query))
+	} else {
+		t.source.Status.Success()
+	}
 
 	// wait for stop signal
 	<-t.stop
+	if t.subscription != 0 {
+		procEvtClose.Call(uintptr(t.subscription))
+	}
+	if t.bookmark != 0 {
+		procEvtClose.Call(uintptr(t.bookmark))
+	}
 	t.done <- struct{}{}
 	return
 }
 
+// updateBookmark moves the bookmark to the event and returns its XML rendering,
+// the bookmark is committed to the registry as the offset of the event.
+func (t *Tailer) updateBookmark(event C.ULONGLONG) (string, error) {
+	if t.bookmark == 0 {
+		return "", fmt.Errorf("no bookmark")
+	}
+	ret, _, err := procEvtUpdateBookmark.Call(uintptr(t.bookmark), uintptr(event))
+	if ret == 0 {
+		return "", err
+	}
+	return evtRender(C.ULONGLONG(t.bookmark), EvtRenderBookmark)
+}
+
 /*
 	Windows related methods
 */
@@ -91,6 +134,13 @@ func goNotificationCallback(handle C.ULONGLONG, ctx C.PVOID) {
 		log.Warnf("Couldn't convert xml to json: %s for event %s", err, xml)
 		return
 	}
+	bookmark, err := t.updateBookmark(handle)
+	if err != nil {
+		log.Debugf("Could not update the bookmark of channel %s: %v", t.config.ChannelPath, err)
+	} else {
+		msg.Origin.Identifier = t.Identifier()
+		msg.Origin.Offset = bookmark
+	}
 
 	t.outputChan <- msg
 }
@@ -104,16 +154,41 @@ var (
 	procEvtOpenChannelEnum = modWinEvtAPI.NewProc("EvtOpenChannelEnum")
 	procEvtNextChannelPath = modWinEvtAPI.NewProc("EvtNextChannelPath")
 	procEvtNext            = modWinEvtAPI.NewProc("EvtNext")
+	procEvtCreateBookmark  = modWinEvtAPI.NewProc("EvtCreateBookmark")
+	procEvtUpdateBookmark  = modWinEvtAPI.NewProc("EvtUpdateBookmark")
 )
 
+// evtCreateBookmark creates a bookmark from its XML rendering,
+// or a new bookmark when the rendering is empty.
+func evtCreateBookmark(xml string) (evtBookmarkHandle, error) {
+	var ptr *uint16
+	if xml != "" {
+		var err error
+		ptr, err = windows.UTF16PtrFromString(xml)
+		if err != nil {
+			return 0, err
+		}
+	}
+	ret, _, err := procEvtCreateBookmark.Call(uintptr(unsafe.Pointer(ptr)))
+	if ret == 0 {
+		return 0, err
+	}
+	return evtBookmarkHandle(ret), nil
+}
+
 // EvtRender takes an event handle and reders it to XML
 func EvtRender(h C.ULONGLONG) (xml string, err error) {
+	return evtRender(h, EvtRenderEventXml)
+}
+
+// evtRender renders an event or a bookmark handle to XML according to the flag
+func evtRender(h C.ULONGLONG, flag int) (xml string, err error) {
 	var bufSize uint32
 	var bufUsed uint32
 
 	_, _, err = procEvtRender.Call(uintptr(0), // this handle is always null for XML renders
-		uintptr(h),                 // handle of event we're rendering
-		uintptr(EvtRenderEventXml), // for now, always render in xml
+		uintptr(h),    // handle of the event or the bookmark we're rendering
+		uintptr(flag), // render the event or the bookmark in xml
 		uintptr(bufSize),
 		uintptr(0),                        // no buffer for now, just getting necessary size
 		uintptr(unsafe.Pointer(&bufUsed)), // filled in with necessary buffer size
@@ -125,8 +200,8 @@ func EvtRender(h C.ULONGLONG) (xml string, err error) {
 	bufSize = bufUsed
 	buf := make([]uint8, bufSize)
 	ret, _, err := procEvtRender.Call(uintptr(0), // this handle is always null for XML renders
-		uintptr(h),                 // handle of event we're rendering
-		uintptr(EvtRenderEventXml), // for now, always render in xml
+		uintptr(h),    // handle of the event or the bookmark we're rendering
+		uintptr(flag), // render the event or the bookmark in xml
 		uintptr(bufSize),
 		uintptr(unsafe.Pointer(&buf[0])),  // actual buffer used
 		uintptr(unsafe.Pointer(&bufUsed)), // filled in with necessary buffer size
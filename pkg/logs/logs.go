@@ -6,6 +6,7 @@
 package logs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
@@ -15,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
 	"github.com/DataDog/datadog-agent/pkg/logs/status"
@@ -102,6 +104,15 @@ func Stop() {
 	log.Info("logs-agent stopped")
 }
 
+// Flush sends the logs collected so far, it returns once they are sent or
+// when ctx is done. No-op when logs-agent is not running.
+func Flush(ctx context.Context) error {
+	if !IsAgentRunning() || agent == nil {
+		return nil
+	}
+	return agent.Flush(ctx)
+}
+
 // IsAgentRunning returns true if the logs-agent is running.
 func IsAgentRunning() bool {
 	return status.Get().IsRunning
@@ -112,6 +123,15 @@ func GetStatus() status.Status {
 	return status.Get()
 }
 
+// GetMessageReceiver returns the receiver streaming the messages processed by
+// logs-agent, nil when logs-agent is not running.
+func GetMessageReceiver() *diagnostic.BufferedMessageReceiver {
+	if !IsAgentRunning() || agent == nil {
+		return nil
+	}
+	return agent.diagnosticMessageReceiver
+}
+
 // GetScheduler returns the logs-config scheduler if set.
 func GetScheduler() *scheduler.Scheduler {
 	return adScheduler
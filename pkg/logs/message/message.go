@@ -5,13 +5,23 @@
 
 package message
 
-import "github.com/DataDog/datadog-agent/pkg/logs/config"
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
 
 // Message represents a log line sent to datadog, with its metadata
 type Message struct {
 	Content []byte
 	Origin  *Origin
 	status  string
+	// Timestamp is the time of the log when it is parsed from its content,
+	// the logs are timestamped when they are encoded otherwise.
+	Timestamp time.Time
+	// Truncated is true when the content is a part of a line longer than the
+	// line size limit of the source
+	Truncated bool
 }
 
 // NewMessageWithSource constructs message with content, status and log source.
@@ -36,3 +46,8 @@ func (m *Message) GetStatus() string {
 	}
 	return m.status
 }
+
+// SetStatus sets the status of the message.
+func (m *Message) SetStatus(status string) {
+	m.status = status
+}
@@ -13,12 +13,14 @@ import (
 
 // Origin represents the Origin of a message
 type Origin struct {
-	Identifier string
-	LogSource  *config.LogSource
-	Offset     string
-	service    string
-	source     string
-	tags       []string
+	Identifier  string
+	LogSource   *config.LogSource
+	Offset      string
+	Fingerprint string // identifies the file of the offset, if any
+	service     string
+	source      string
+	tags        []string
+	entityID    string
 }
 
 // NewOrigin returns a new Origin
@@ -72,6 +74,34 @@ func (o *Origin) SetTags(tags []string) {
 	o.tags = tags
 }
 
+// AddTags adds the tags missing from the tags of the origin.
+func (o *Origin) AddTags(tags []string) {
+	existing := make(map[string]struct{})
+	for _, tag := range o.Tags() {
+		existing[tag] = struct{}{}
+	}
+	// copy the tags of the origin, they can be shared with other origins
+	merged := o.tags[:len(o.tags):len(o.tags)]
+	for _, tag := range tags {
+		if _, found := existing[tag]; !found {
+			existing[tag] = struct{}{}
+			merged = append(merged, tag)
+		}
+	}
+	o.tags = merged
+}
+
+// SetEntityID sets the tagger entity of the container or the pod the origin
+// belongs to, e.g. docker://<container_id>.
+func (o *Origin) SetEntityID(entityID string) {
+	o.entityID = entityID
+}
+
+// EntityID returns the tagger entity of the origin, if any.
+func (o *Origin) EntityID() string {
+	return o.entityID
+}
+
 // SetSource sets the source of the origin.
 func (o *Origin) SetSource(source string) {
 	o.source = source
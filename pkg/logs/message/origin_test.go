@@ -101,3 +101,23 @@ func TestDefaultServiceValueIsServiceFromConfig(t *testing.T) {
 	origin.SetService("bar")
 	assert.Equal(t, "bar", origin.Service())
 }
+
+func TestAddTagsSkipsExistingTags(t *testing.T) {
+	cfg := &config.LogsConfig{
+		Tags: []string{"c:d"},
+	}
+	source := config.NewLogSource("", cfg)
+	shared := make([]string, 0, 10)
+	shared = append(shared, "foo:bar")
+
+	origin := NewOrigin(source)
+	origin.SetTags(shared)
+	origin.AddTags([]string{"foo:bar", "c:d", "env:prod", "env:prod"})
+	assert.Equal(t, []string{"foo:bar", "env:prod", "c:d"}, origin.Tags())
+
+	other := NewOrigin(source)
+	other.SetTags(shared)
+	other.AddTags([]string{"env:staging"})
+	assert.Equal(t, []string{"foo:bar", "env:staging", "c:d"}, other.Tags())
+	assert.Equal(t, []string{"foo:bar", "env:prod", "c:d"}, origin.Tags())
+}
@@ -7,6 +7,8 @@ package metrics
 
 import (
 	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 )
 
 var (
@@ -22,7 +24,35 @@ var (
 	DestinationErrors = expvar.Int{}
 	// DestinationLogsDropped is the total number of logs dropped per Destination
 	DestinationLogsDropped = expvar.Map{}
+	// AutoMultiLinePatterns is the number of sources by multi-line pattern detected, "none" if no pattern was detected
+	AutoMultiLinePatterns = expvar.Map{}
+	// LogsRateLimited is the total number of logs dropped by the rate limits
+	LogsRateLimited = expvar.Int{}
+	// LogsTruncated is the total number of logs truncated because they exceed the line size limit
+	LogsTruncated = expvar.Int{}
+	// BytesSent is the total number of bytes of logs sent
+	BytesSent = expvar.Int{}
+	// BytesDropped is the total number of bytes of logs dropped by the senders
+	BytesDropped = expvar.Int{}
+	// BytesSpilled is the total number of bytes of logs spilled to disk while the senders were blocked
+	BytesSpilled = expvar.Int{}
+	// PipelinesBlocked is the number of times a pipeline found its sender blocked
+	PipelinesBlocked = expvar.Int{}
 	// TODO: Add LogsCollected for the total number of collected logs.
+
+	// The telemetry counters have no tags, they're resolved once as they're
+	// incremented for every log.
+
+	// TlmLogsDecoded is the total number of decoded logs
+	TlmLogsDecoded = telemetry.NewCounter("logs", "decoded", nil, "Total number of decoded logs.").WithValues()
+	// TlmLogsProcessed is the total number of processed logs.
+	TlmLogsProcessed = telemetry.NewCounter("logs", "processed", nil, "Total number of processed logs.").WithValues()
+	// TlmLogsSent is the total number of sent logs.
+	TlmLogsSent = telemetry.NewCounter("logs", "sent", nil, "Total number of sent logs.").WithValues()
+	// TlmDestinationErrors is the total number of network errors.
+	TlmDestinationErrors = telemetry.NewCounter("logs", "destination_errors", nil, "Total number of network errors.").WithValues()
+	// TlmBytesSent is the total number of bytes of logs sent
+	TlmBytesSent = telemetry.NewCounter("logs", "bytes_sent", nil, "Total number of bytes of logs sent.").WithValues()
 )
 
 func init() {
@@ -32,4 +62,11 @@ func init() {
 	LogsExpvars.Set("LogsSent", &LogsSent)
 	LogsExpvars.Set("DestinationErrors", &DestinationErrors)
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
+	LogsExpvars.Set("AutoMultiLinePatterns", &AutoMultiLinePatterns)
+	LogsExpvars.Set("LogsRateLimited", &LogsRateLimited)
+	LogsExpvars.Set("LogsTruncated", &LogsTruncated)
+	LogsExpvars.Set("BytesSent", &BytesSent)
+	LogsExpvars.Set("BytesDropped", &BytesDropped)
+	LogsExpvars.Set("BytesSpilled", &BytesSpilled)
+	LogsExpvars.Set("PipelinesBlocked", &PipelinesBlocked)
 }
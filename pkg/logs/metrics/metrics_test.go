@@ -12,5 +12,5 @@ import (
 )
 
 func TestMetrics(t *testing.T) {
-	assert.Equal(t, LogsExpvars.String(), `{"DestinationErrors": 0, "DestinationLogsDropped": {}, "LogsDecoded": 0, "LogsProcessed": 0, "LogsSent": 0}`)
+	assert.Equal(t, LogsExpvars.String(), `{"AutoMultiLinePatterns": {}, "BytesDropped": 0, "BytesSent": 0, "BytesSpilled": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "LogsDecoded": 0, "LogsProcessed": 0, "LogsRateLimited": 0, "LogsSent": 0, "LogsTruncated": 0, "PipelinesBlocked": 0}`)
 }
@@ -6,6 +6,8 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 )
@@ -32,3 +34,8 @@ func (p *mockProvider) Stop() {}
 func (p *mockProvider) NextPipelineChan() chan *message.Message {
 	return p.msgChan
 }
+
+// Flush does nothing
+func (p *mockProvider) Flush(ctx context.Context) error {
+	return nil
+}
@@ -6,58 +6,137 @@
 package pipeline
 
 import (
+	"context"
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 )
 
 // Pipeline processes and sends messages to the backend
 type Pipeline struct {
-	InputChan chan *message.Message
-	processor *processor.Processor
-	sender    *sender.Sender
+	InputChan   chan *message.Message
+	spillerChan chan *message.Message
+	senderChan  chan *message.Message
+	processor   *processor.Processor
+	spiller     *spiller
+	sender      *sender.Sender
 }
 
-// NewPipeline returns a new Pipeline
-func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *client.Endpoints, destinationsContext *client.DestinationsContext) *Pipeline {
-	// initialize the main destination
-	main := client.NewDestination(endpoints.Main, destinationsContext)
-
-	// initialize the additional destinations
-	var additionals []*client.Destination
-	for _, endpoint := range endpoints.Additionals {
-		additionals = append(additionals, client.NewDestination(endpoint, destinationsContext))
+// NewPipeline returns a new Pipeline, the logs are spilled to the file at
+// spillPath while the sender is blocked unless spillPath is empty.
+func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, rateLimiter *config.RateLimiter, memoryLimiter *config.MemoryLimiter, enricher tag.Enricher, endpoints *client.Endpoints, destinationsContext *client.DestinationsContext, spillPath string, diagnosticMessageReceiver diagnostic.MessageReceiver) *Pipeline {
+	var mainDestination client.Destination
+	var additionals []client.Destination
+	var strategy sender.Strategy
+	var encoder processor.Encoder
+	if endpoints.UseHTTP {
+		// send batches of json logs over HTTP
+		mainDestination = client.NewHTTPDestination(endpoints.Main, destinationsContext)
+		for _, endpoint := range endpoints.Additionals {
+			additionals = append(additionals, client.NewHTTPDestination(endpoint, destinationsContext))
+		}
+		strategy = sender.NewBatchStrategy(endpoints.BatchWait)
+		encoder = processor.JSONEncoder
+	} else {
+		// stream the logs over TCP
+		mainDestination = client.NewDestination(endpoints.Main, destinationsContext)
+		for _, endpoint := range endpoints.Additionals {
+			additionals = append(additionals, client.NewDestination(endpoint, destinationsContext))
+		}
+		strategy = sender.StreamStrategy
+		encoder = processor.NewEncoder(endpoints.Main.UseProto)
 	}
 
 	// initialize the sender
-	destinations := client.NewDestinations(main, additionals)
+	destinations := client.NewDestinations(mainDestination, additionals)
 	senderChan := make(chan *message.Message, config.ChanSize)
-	sender := sender.NewSender(senderChan, outputChan, destinations)
+	sender := sender.NewSender(senderChan, outputChan, destinations, strategy)
+
+	// initialize the spiller between the processor and the sender
+	var spiller *spiller
+	var spillerChan chan *message.Message
+	processorOutputChan := senderChan
+	if spillPath != "" {
+		spillerChan = make(chan *message.Message, config.ChanSize)
+		spiller = newSpiller(spillerChan, senderChan, spillPath, config.SpillMaxSize())
+		processorOutputChan = spillerChan
+	}
 
 	// initialize the input chan
 	inputChan := make(chan *message.Message, config.ChanSize)
 
 	// initialize the processor
-	encoder := processor.NewEncoder(endpoints.Main.UseProto)
-	processor := processor.New(inputChan, senderChan, processingRules, encoder)
+	processor := processor.New(inputChan, processorOutputChan, processingRules, rateLimiter, memoryLimiter, enricher, encoder, diagnosticMessageReceiver)
 
 	return &Pipeline{
-		InputChan: inputChan,
-		processor: processor,
-		sender:    sender,
+		InputChan:   inputChan,
+		spillerChan: spillerChan,
+		senderChan:  senderChan,
+		processor:   processor,
+		spiller:     spiller,
+		sender:      sender,
 	}
 }
 
 // Start launches the pipeline
 func (p *Pipeline) Start() {
 	p.sender.Start()
+	if p.spiller != nil {
+		p.spiller.Start()
+	}
 	p.processor.Start()
 }
 
 // Stop stops the pipeline
 func (p *Pipeline) Stop() {
 	p.processor.Stop()
+	if p.spiller != nil {
+		p.spiller.Stop()
+	}
 	p.sender.Stop()
 }
+
+// flushPollPeriod is the period the channels of the pipeline are polled
+// with while they are drained by a flush.
+const flushPollPeriod = 10 * time.Millisecond
+
+// Flush sends the messages received by the pipeline so far, it returns once
+// they are sent or when ctx is done.
+func (p *Pipeline) Flush(ctx context.Context) error {
+	if err := waitForEmpty(ctx, p.InputChan); err != nil {
+		return err
+	}
+	if err := p.processor.Flush(ctx); err != nil {
+		return err
+	}
+	if p.spiller != nil {
+		if err := waitForEmpty(ctx, p.spillerChan); err != nil {
+			return err
+		}
+		if err := p.spiller.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	if err := waitForEmpty(ctx, p.senderChan); err != nil {
+		return err
+	}
+	return p.sender.Flush(ctx)
+}
+
+// waitForEmpty returns once the channel is empty or when ctx is done.
+func waitForEmpty(ctx context.Context, c chan *message.Message) error {
+	for len(c) > 0 {
+		select {
+		case <-time.After(flushPollPeriod):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -6,13 +6,18 @@
 package pipeline
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
 	"sync/atomic"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 )
 
 // Provider provides message channels
@@ -20,6 +25,7 @@ type Provider interface {
 	Start()
 	Stop()
 	NextPipelineChan() chan *message.Message
+	Flush(ctx context.Context) error
 }
 
 // provider implements providing logic
@@ -28,22 +34,33 @@ type provider struct {
 	auditor           *auditor.Auditor
 	outputChan        chan *message.Message
 	processingRules   []*config.ProcessingRule
+	rateLimiter       *config.RateLimiter
+	memoryLimiter     *config.MemoryLimiter
+	enricher          tag.Enricher
 	endpoints         *client.Endpoints
 
-	pipelines            []*Pipeline
-	currentPipelineIndex int32
-	destinationsContext  *client.DestinationsContext
+	diagnosticMessageReceiver diagnostic.MessageReceiver
+	pipelines                 []*Pipeline
+	currentPipelineIndex      int32
+	destinationsContext       *client.DestinationsContext
+	releaseDone               chan struct{}
+	spillDirectory            string
 }
 
 // NewProvider returns a new Provider
-func NewProvider(numberOfPipelines int, auditor *auditor.Auditor, processingRules []*config.ProcessingRule, endpoints *client.Endpoints, destinationsContext *client.DestinationsContext) Provider {
+func NewProvider(numberOfPipelines int, auditor *auditor.Auditor, processingRules []*config.ProcessingRule, rateLimiter *config.RateLimiter, memoryLimiter *config.MemoryLimiter, enricher tag.Enricher, endpoints *client.Endpoints, destinationsContext *client.DestinationsContext, diagnosticMessageReceiver diagnostic.MessageReceiver) Provider {
 	return &provider{
-		numberOfPipelines:   numberOfPipelines,
-		auditor:             auditor,
-		processingRules:     processingRules,
-		endpoints:           endpoints,
-		pipelines:           []*Pipeline{},
-		destinationsContext: destinationsContext,
+		numberOfPipelines:         numberOfPipelines,
+		auditor:                   auditor,
+		processingRules:           processingRules,
+		rateLimiter:               rateLimiter,
+		memoryLimiter:             memoryLimiter,
+		enricher:                  enricher,
+		endpoints:                 endpoints,
+		diagnosticMessageReceiver: diagnosticMessageReceiver,
+		pipelines:                 []*Pipeline{},
+		destinationsContext:       destinationsContext,
+		spillDirectory:            config.SpillDirectory(),
 	}
 }
 
@@ -51,9 +68,20 @@ func NewProvider(numberOfPipelines int, auditor *auditor.Auditor, processingRule
 func (p *provider) Start() {
 	// This requires the auditor to be started before.
 	p.outputChan = p.auditor.Channel()
+	if p.memoryLimiter != nil {
+		// release the memory of the messages sent before they reach the auditor
+		auditorChan := p.outputChan
+		p.outputChan = make(chan *message.Message, config.ChanSize)
+		p.releaseDone = make(chan struct{})
+		go p.release(p.outputChan, auditorChan)
+	}
 
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext)
+		spillPath := ""
+		if p.spillDirectory != "" {
+			spillPath = filepath.Join(p.spillDirectory, fmt.Sprintf("pipeline-%d.spill", i))
+		}
+		pipeline := NewPipeline(p.outputChan, p.processingRules, p.rateLimiter, p.memoryLimiter, p.enricher, p.endpoints, p.destinationsContext, spillPath, p.diagnosticMessageReceiver)
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
@@ -67,10 +95,36 @@ func (p *provider) Stop() {
 		stopper.Add(pipeline)
 	}
 	stopper.Stop()
+	if p.releaseDone != nil {
+		close(p.outputChan)
+		<-p.releaseDone
+		p.releaseDone = nil
+	}
 	p.pipelines = p.pipelines[:0]
 	p.outputChan = nil
 }
 
+// release releases the memory of the messages of inputChan and forwards them
+// to outputChan, until inputChan is closed.
+func (p *provider) release(inputChan, outputChan chan *message.Message) {
+	defer close(p.releaseDone)
+	for msg := range inputChan {
+		p.memoryLimiter.Release(len(msg.Content))
+		outputChan <- msg
+	}
+}
+
+// Flush flushes all the pipelines, it returns once the messages they received
+// so far are sent or when ctx is done.
+func (p *provider) Flush(ctx context.Context) error {
+	for _, pipeline := range p.pipelines {
+		if err := pipeline.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NextPipelineChan returns the next pipeline input channel
 func (p *provider) NextPipelineChan() chan *message.Message {
 	pipelinesLen := len(p.pipelines)
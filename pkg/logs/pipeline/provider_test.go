@@ -14,6 +14,8 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
 type ProviderTestSuite struct {
@@ -54,6 +56,23 @@ func (suite *ProviderTestSuite) TestProvider() {
 	suite.Nil(suite.p.NextPipelineChan())
 }
 
+func (suite *ProviderTestSuite) TestProviderReleasesMemory() {
+	suite.p.memoryLimiter = config.NewMemoryLimiter(100)
+	suite.p.releaseDone = make(chan struct{})
+	inputChan := make(chan *message.Message)
+	outputChan := make(chan *message.Message, 1)
+	go suite.p.release(inputChan, outputChan)
+
+	msg := message.NewMessageWithSource([]byte("hello"), "", config.NewLogSource("", &config.LogsConfig{}))
+	suite.p.memoryLimiter.Acquire(len(msg.Content))
+	inputChan <- msg
+	suite.Equal(msg, <-outputChan)
+	suite.Equal(0, suite.p.memoryLimiter.Used())
+
+	close(inputChan)
+	<-suite.p.releaseDone
+}
+
 func TestProviderTestSuite(t *testing.T) {
 	suite.Run(t, new(ProviderTestSuite))
 }
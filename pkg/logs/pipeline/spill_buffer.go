@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+// spilledMessage is a message of a spill buffer, its content is either in
+// memory or at offset in the file of the buffer.
+type spilledMessage struct {
+	msg    *message.Message
+	offset int64
+	size   int
+	onDisk bool
+}
+
+// spillBuffer is a FIFO queue of messages whose content is written to a
+// file, up to maxSize bytes. The content of the messages exceeding maxSize
+// is kept in memory and the buffer is full until it is emptied.
+type spillBuffer struct {
+	path     string
+	maxSize  int64
+	file     *os.File
+	messages []spilledMessage
+	size     int64
+	full     bool
+}
+
+// newSpillBuffer returns a new spill buffer writing to path.
+func newSpillBuffer(path string, maxSize int64) *spillBuffer {
+	return &spillBuffer{
+		path:    path,
+		maxSize: maxSize,
+	}
+}
+
+// push appends the message to the buffer, an error is returned when its
+// content can't be written to disk.
+func (b *spillBuffer) push(msg *message.Message) error {
+	entry := spilledMessage{msg: msg, offset: b.size, size: len(msg.Content)}
+	var err error
+	if b.full || b.size+int64(entry.size) > b.maxSize {
+		err = fmt.Errorf("the spill buffer %s is full", b.path)
+	} else {
+		err = b.write(msg.Content)
+	}
+	if err != nil {
+		// keep the content in memory until the buffer is emptied
+		b.full = true
+	} else {
+		metrics.BytesSpilled.Add(int64(entry.size))
+		b.size += int64(entry.size)
+		entry.onDisk = true
+		msg.Content = nil
+	}
+	b.messages = append(b.messages, entry)
+	return err
+}
+
+// write writes the content at the end of the file, the file is created on
+// the first write.
+func (b *spillBuffer) write(content []byte) error {
+	if b.file == nil {
+		if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(b.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		b.file = file
+	}
+	_, err := b.file.WriteAt(content, b.size)
+	return err
+}
+
+// peek returns the first message of the buffer with its content, nil if the
+// buffer is empty.
+func (b *spillBuffer) peek() (*message.Message, error) {
+	if len(b.messages) == 0 {
+		return nil, nil
+	}
+	entry := &b.messages[0]
+	if entry.onDisk {
+		content := make([]byte, entry.size)
+		if _, err := b.file.ReadAt(content, entry.offset); err != nil {
+			return nil, err
+		}
+		entry.msg.Content = content
+		entry.onDisk = false
+	}
+	return entry.msg, nil
+}
+
+// pop removes the first message of the buffer, the file is truncated once
+// the buffer is empty.
+func (b *spillBuffer) pop() {
+	if len(b.messages) == 0 {
+		return
+	}
+	b.messages[0] = spilledMessage{}
+	b.messages = b.messages[1:]
+	if len(b.messages) > 0 {
+		return
+	}
+	b.messages = nil
+	b.size = 0
+	b.full = false
+	if b.file != nil {
+		b.file.Truncate(0)
+	}
+}
+
+// len returns the number of messages in the buffer.
+func (b *spillBuffer) len() int {
+	return len(b.messages)
+}
+
+// isFull returns whether no message can be appended to the buffer anymore
+// until it is emptied.
+func (b *spillBuffer) isFull() bool {
+	return b.full
+}
+
+// close closes and removes the file of the buffer.
+func (b *spillBuffer) close() {
+	if b.file == nil {
+		return
+	}
+	b.file.Close()
+	os.Remove(b.path)
+	b.file = nil
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package pipeline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newTestSpillBuffer(t *testing.T, maxSize int64) (*spillBuffer, func()) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.Nil(t, err)
+	buffer := newSpillBuffer(filepath.Join(dir, "pipeline-0.spill"), maxSize)
+	return buffer, func() {
+		buffer.close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSpillBufferPushAndPop(t *testing.T) {
+	buffer, cleanup := newTestSpillBuffer(t, 100)
+	defer cleanup()
+
+	foo := message.NewMessage([]byte("foo"), nil, "")
+	bar := message.NewMessage([]byte("bar"), nil, "")
+	assert.Nil(t, buffer.push(foo))
+	assert.Nil(t, buffer.push(bar))
+	assert.Equal(t, 2, buffer.len())
+	assert.False(t, buffer.isFull())
+
+	// the content of the messages is on disk
+	assert.Nil(t, foo.Content)
+	assert.Nil(t, bar.Content)
+
+	msg, err := buffer.peek()
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", string(msg.Content))
+	buffer.pop()
+
+	msg, err = buffer.peek()
+	assert.Nil(t, err)
+	assert.Equal(t, "bar", string(msg.Content))
+	buffer.pop()
+
+	assert.Equal(t, 0, buffer.len())
+	msg, err = buffer.peek()
+	assert.Nil(t, err)
+	assert.Nil(t, msg)
+}
+
+func TestSpillBufferFull(t *testing.T) {
+	buffer, cleanup := newTestSpillBuffer(t, 5)
+	defer cleanup()
+
+	assert.Nil(t, buffer.push(message.NewMessage([]byte("foo"), nil, "")))
+	assert.NotNil(t, buffer.push(message.NewMessage([]byte("bar"), nil, "")))
+	assert.True(t, buffer.isFull())
+	assert.Equal(t, 2, buffer.len())
+
+	// the content of the message exceeding the limit is kept in memory
+	msg, _ := buffer.peek()
+	assert.Equal(t, "foo", string(msg.Content))
+	buffer.pop()
+	msg, _ = buffer.peek()
+	assert.Equal(t, "bar", string(msg.Content))
+	buffer.pop()
+
+	// the buffer accepts messages again once emptied
+	assert.False(t, buffer.isFull())
+	assert.Nil(t, buffer.push(message.NewMessage([]byte("baz"), nil, "")))
+}
+
+func TestSpillBufferCloseRemovesFile(t *testing.T) {
+	buffer, cleanup := newTestSpillBuffer(t, 100)
+	defer cleanup()
+
+	assert.Nil(t, buffer.push(message.NewMessage([]byte("foo"), nil, "")))
+	_, err := os.Stat(buffer.path)
+	assert.Nil(t, err)
+
+	buffer.close()
+	_, err = os.Stat(buffer.path)
+	assert.True(t, os.IsNotExist(err))
+}
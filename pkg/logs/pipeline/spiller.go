@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package pipeline
+
+import (
+	"context"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+// A spiller forwards the messages of inputChan to outputChan. While outputChan
+// is full, the messages are queued in a spill buffer so that a blocked sender
+// does not block the processor, and are forwarded in order once the sender
+// catches up. When the spill buffer is full, the spiller blocks.
+type spiller struct {
+	inputChan     chan *message.Message
+	outputChan    chan *message.Message
+	buffer        *spillBuffer
+	flushChan     chan chan struct{}
+	flushRequests []chan struct{}
+	done          chan struct{}
+}
+
+// newSpiller returns a new spiller spilling up to maxSize bytes to the file at path.
+func newSpiller(inputChan, outputChan chan *message.Message, path string, maxSize int64) *spiller {
+	return &spiller{
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		buffer:     newSpillBuffer(path, maxSize),
+		flushChan:  make(chan chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start starts the spiller.
+func (s *spiller) Start() {
+	go s.run()
+}
+
+// Stop stops the spiller, this call blocks until the messages spilled are
+// forwarded.
+func (s *spiller) Stop() {
+	close(s.inputChan)
+	<-s.done
+}
+
+// Flush returns once the messages received so far are forwarded to the
+// outputChan or when ctx is done.
+func (s *spiller) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case s.flushChan <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run forwards the messages until inputChan is closed.
+func (s *spiller) run() {
+	defer func() {
+		s.buffer.close()
+		close(s.done)
+	}()
+	for {
+		if s.buffer.len() == 0 {
+			s.releaseFlushRequests()
+			select {
+			case msg, isOpen := <-s.inputChan:
+				if !isOpen {
+					return
+				}
+				select {
+				case s.outputChan <- msg:
+				default:
+					metrics.PipelinesBlocked.Add(1)
+					log.Debug("The sender of a pipeline is blocked, spilling the logs to disk")
+					s.spill(msg)
+				}
+			case done := <-s.flushChan:
+				close(done)
+			}
+			continue
+		}
+
+		next, err := s.buffer.peek()
+		if err != nil {
+			log.Warnf("Could not read a log spilled to disk, dropping it: %v", err)
+			s.buffer.pop()
+			continue
+		}
+		inputChan := s.inputChan
+		if s.buffer.isFull() {
+			// block the processor until the sender catches up
+			inputChan = nil
+		}
+		select {
+		case msg, isOpen := <-inputChan:
+			if !isOpen {
+				s.drain()
+				return
+			}
+			s.spill(msg)
+		case s.outputChan <- next:
+			s.buffer.pop()
+		case done := <-s.flushChan:
+			s.flushRequests = append(s.flushRequests, done)
+		}
+	}
+}
+
+// spill appends the message to the spill buffer.
+func (s *spiller) spill(msg *message.Message) {
+	if err := s.buffer.push(msg); err != nil {
+		log.Warnf("Could not spill the logs to disk, waiting for the sender: %v", err)
+	}
+}
+
+// drain forwards all the messages of the spill buffer.
+func (s *spiller) drain() {
+	for s.buffer.len() > 0 {
+		next, err := s.buffer.peek()
+		if err != nil {
+			log.Warnf("Could not read a log spilled to disk, dropping it: %v", err)
+		} else {
+			s.outputChan <- next
+		}
+		s.buffer.pop()
+	}
+	s.releaseFlushRequests()
+}
+
+// releaseFlushRequests notifies the flush requests received while the spill
+// buffer was not empty.
+func (s *spiller) releaseFlushRequests() {
+	for _, done := range s.flushRequests {
+		close(done)
+	}
+	s.flushRequests = nil
+}
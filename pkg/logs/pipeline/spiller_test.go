@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package pipeline
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestSpillerForwardsMessagesInOrderWhileBlocked(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	inputChan := make(chan *message.Message, 10)
+	// the sender is blocked until the output chan is read
+	outputChan := make(chan *message.Message)
+	spiller := newSpiller(inputChan, outputChan, filepath.Join(dir, "pipeline-0.spill"), 1000)
+	spiller.Start()
+
+	contents := []string{"foo", "bar", "baz"}
+	for _, content := range contents {
+		inputChan <- message.NewMessage([]byte(content), nil, "")
+	}
+
+	flushed := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		flushed <- spiller.Flush(ctx)
+	}()
+
+	for _, content := range contents {
+		msg := <-outputChan
+		assert.Equal(t, content, string(msg.Content))
+	}
+	assert.Nil(t, <-flushed)
+
+	spiller.Stop()
+	_, err = os.Stat(filepath.Join(dir, "pipeline-0.spill"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSpillerStopForwardsSpilledMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	inputChan := make(chan *message.Message, 10)
+	outputChan := make(chan *message.Message)
+	spiller := newSpiller(inputChan, outputChan, filepath.Join(dir, "pipeline-0.spill"), 1000)
+	spiller.Start()
+
+	inputChan <- message.NewMessage([]byte("foo"), nil, "")
+	inputChan <- message.NewMessage([]byte("bar"), nil, "")
+
+	stopped := make(chan struct{})
+	go func() {
+		spiller.Stop()
+		close(stopped)
+	}()
+
+	assert.Equal(t, "foo", string((<-outputChan).Content))
+	assert.Equal(t, "bar", string((<-outputChan).Content))
+	<-stopped
+}
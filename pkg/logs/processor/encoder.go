@@ -6,7 +6,9 @@
 package processor
 
 import (
+	"encoding/json"
 	"regexp"
+	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -28,6 +30,10 @@ var rawEncoder raw
 // Proto is an encoder implementation that writes messages as protocol buffers.
 var protoEncoder proto
 
+// JSONEncoder is an encoder implementation that writes messages as json,
+// it is used to send batches of logs over HTTP.
+var JSONEncoder Encoder = &jsonEncoder{}
+
 // NewEncoder returns an encoder.
 func NewEncoder(useProto bool) Encoder {
 	if useProto {
@@ -57,7 +63,7 @@ func (r *raw) encode(msg *message.Message, redactedMsg []byte) ([]byte, error) {
 		extraContent = append(extraContent, ' ')
 
 		// Timestamp
-		extraContent = time.Now().UTC().AppendFormat(extraContent, config.DateFormat)
+		extraContent = timestamp(msg).AppendFormat(extraContent, config.DateFormat)
 		extraContent = append(extraContent, ' ')
 
 		extraContent = append(extraContent, []byte(getHostname())...)
@@ -107,7 +113,7 @@ func (p *proto) encode(msg *message.Message, redactedMsg []byte) ([]byte, error)
 	return (&pb.Log{
 		Message:   p.toValidUtf8(redactedMsg),
 		Status:    msg.GetStatus(),
-		Timestamp: time.Now().UTC().UnixNano(),
+		Timestamp: timestamp(msg).UnixNano(),
 		Hostname:  getHostname(),
 		Service:   msg.Origin.Service(),
 		Source:    msg.Origin.Source(),
@@ -131,6 +137,40 @@ func (p *proto) toValidUtf8(msg []byte) string {
 	return string(str)
 }
 
+// jsonPayload represents a log sent to the HTTP intake.
+type jsonPayload struct {
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Service   string `json:"service"`
+	Source    string `json:"ddsource"`
+	Tags      string `json:"ddtags"`
+}
+
+type jsonEncoder struct{}
+
+func (j *jsonEncoder) encode(msg *message.Message, redactedMsg []byte) ([]byte, error) {
+	return json.Marshal(jsonPayload{
+		Message:   protoEncoder.toValidUtf8(redactedMsg),
+		Status:    msg.GetStatus(),
+		Timestamp: timestamp(msg).UnixNano() / int64(time.Millisecond),
+		Hostname:  getHostname(),
+		Service:   msg.Origin.Service(),
+		Source:    msg.Origin.Source(),
+		Tags:      strings.Join(msg.Origin.Tags(), ","),
+	})
+}
+
+// timestamp returns the timestamp of the message if it was parsed from its
+// content, the current time otherwise.
+func timestamp(msg *message.Message) time.Time {
+	if !msg.Timestamp.IsZero() {
+		return msg.Timestamp.UTC()
+	}
+	return time.Now().UTC()
+}
+
 // getHostname returns the hostname for the agent.
 func getHostname() string {
 	// Compute the hostname
@@ -6,6 +6,7 @@
 package processor
 
 import (
+	"encoding/json"
 	"testing"
 
 	"strings"
@@ -196,3 +197,49 @@ func TestProtoEncoderToValidUTF8(t *testing.T) {
 	assert.Equal(t, "a���z", protoEncoder.toValidUtf8([]byte("a\xed\xa0\x80z")))
 	assert.Equal(t, "a����z", protoEncoder.toValidUtf8([]byte("a\xf0\x8f\xbf\xbfz")))
 }
+
+func TestJSONEncoder(t *testing.T) {
+	logsConfig := &config.LogsConfig{
+		Service:        "Service",
+		Source:         "Source",
+		SourceCategory: "SourceCategory",
+		Tags:           []string{"foo:bar", "baz"},
+	}
+
+	source := config.NewLogSource("", logsConfig)
+
+	msg := newMessage([]byte("message"), source, message.StatusError)
+	msg.Origin.LogSource = source
+	msg.Origin.SetTags([]string{"a", "b:c"})
+
+	content, err := JSONEncoder.encode(msg, []byte("redacted"))
+	assert.Nil(t, err)
+
+	log := &jsonPayload{}
+	err = json.Unmarshal(content, log)
+	assert.Nil(t, err)
+
+	assert.NotEmpty(t, log.Hostname)
+	assert.NotEmpty(t, log.Timestamp)
+	assert.Equal(t, "redacted", log.Message)
+	assert.Equal(t, message.StatusError, log.Status)
+	assert.Equal(t, logsConfig.Service, log.Service)
+	assert.Equal(t, logsConfig.Source, log.Source)
+	assert.Equal(t, "a,b:c,sourcecategory:SourceCategory,foo:bar,baz", log.Tags)
+}
+
+func TestEncodersUseParsedTimestamp(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("message"), source, message.StatusInfo)
+	msg.Timestamp = time.Date(2019, 4, 20, 10, 12, 34, 0, time.UTC)
+
+	raw, err := rawEncoder.encode(msg, []byte("message"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(raw), " 2019-04-20T10:12:34")
+
+	proto, err := protoEncoder.encode(msg, []byte("message"))
+	assert.Nil(t, err)
+	log := &pb.Log{}
+	assert.Nil(t, log.Unmarshal(proto))
+	assert.Equal(t, msg.Timestamp.UnixNano(), log.Timestamp)
+}
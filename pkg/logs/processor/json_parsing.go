@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// statusMapping maps the usual values of the status attributes of json logs
+// to statuses, the numeric values are syslog severities.
+var statusMapping = map[string]string{
+	"0":             message.StatusEmergency,
+	"emerg":         message.StatusEmergency,
+	"emergency":     message.StatusEmergency,
+	"1":             message.StatusAlert,
+	"alert":         message.StatusAlert,
+	"2":             message.StatusCritical,
+	"crit":          message.StatusCritical,
+	"critical":      message.StatusCritical,
+	"fatal":         message.StatusCritical,
+	"3":             message.StatusError,
+	"err":           message.StatusError,
+	"error":         message.StatusError,
+	"4":             message.StatusWarning,
+	"warn":          message.StatusWarning,
+	"warning":       message.StatusWarning,
+	"5":             message.StatusNotice,
+	"notice":        message.StatusNotice,
+	"6":             message.StatusInfo,
+	"info":          message.StatusInfo,
+	"information":   message.StatusInfo,
+	"informational": message.StatusInfo,
+	"7":             message.StatusDebug,
+	"debug":         message.StatusDebug,
+	"trace":         message.StatusDebug,
+}
+
+// applyJSONParsing parses the content of the message as a json object, renames
+// and removes its attributes, then promotes its service, status and timestamp
+// attributes to the message. The content is left untouched when it is not a
+// json object.
+func applyJSONParsing(parsing *config.JSONParsing, msg *message.Message) {
+	attributes, ok := parseJSONObject(msg.Content)
+	if !ok {
+		return
+	}
+
+	modified := false
+	for from, to := range parsing.RenameAttributes {
+		if value, found := attributes[from]; found {
+			delete(attributes, from)
+			attributes[to] = value
+			modified = true
+		}
+	}
+	for _, name := range parsing.RemoveAttributes {
+		if _, found := attributes[name]; found {
+			delete(attributes, name)
+			modified = true
+		}
+	}
+
+	if value, found := firstAttribute(attributes, parsing.ServiceAttributes); found {
+		if service, isString := value.(string); isString && service != "" {
+			msg.Origin.SetService(service)
+		}
+	}
+	if value, found := firstAttribute(attributes, parsing.StatusAttributes); found {
+		if status, found := statusMapping[strings.ToLower(toString(value))]; found {
+			msg.SetStatus(status)
+		}
+	}
+	if value, found := firstAttribute(attributes, parsing.TimestampAttributes); found {
+		if timestamp, ok := parseTimestamp(value); ok {
+			msg.Timestamp = timestamp
+		}
+	}
+
+	if modified {
+		if content, err := marshalJSONObject(attributes); err == nil {
+			msg.Content = content
+		}
+	}
+}
+
+// parseJSONObject returns the attributes of the json object of the content,
+// the numbers are kept as they are.
+func parseJSONObject(content []byte) (map[string]interface{}, bool) {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 || content[0] != '{' {
+		return nil, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var attributes map[string]interface{}
+	if err := decoder.Decode(&attributes); err != nil {
+		return nil, false
+	}
+	// the content must hold a single json object
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return attributes, true
+}
+
+// marshalJSONObject serializes the attributes without escaping the html characters.
+func marshalJSONObject(attributes map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(attributes); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// firstAttribute returns the value of the first attribute found among names.
+func firstAttribute(attributes map[string]interface{}, names []string) (interface{}, bool) {
+	for _, name := range names {
+		if value, found := attributes[name]; found {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// toString returns the string or the number value, an empty string otherwise.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// parseTimestamp parses an RFC3339 date or a unix epoch in seconds or
+// milliseconds.
+func parseTimestamp(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		timestamp, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return timestamp.UTC(), true
+	case json.Number:
+		epoch, err := v.Float64()
+		if err != nil || epoch <= 0 {
+			return time.Time{}, false
+		}
+		// epochs above 1e11 seconds, in year 5138, are in milliseconds
+		if epoch > 1e11 {
+			epoch /= 1000
+		}
+		seconds := int64(epoch)
+		nanoseconds := int64((epoch - float64(seconds)) * 1e9)
+		return time.Unix(seconds, nanoseconds).UTC(), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newJSONParsing(parsing *config.JSONParsing) *config.JSONParsing {
+	parsing.Validate()
+	return parsing
+}
+
+func TestJSONParsingPromotesReservedAttributes(t *testing.T) {
+	parsing := newJSONParsing(&config.JSONParsing{})
+	source := config.NewLogSource("", &config.LogsConfig{})
+	content := `{"service":"web","level":"ERROR","timestamp":"2019-04-20T10:12:34.5Z","message":"<b>failed</b>"}`
+	msg := newMessage([]byte(content), source, "")
+
+	applyJSONParsing(parsing, msg)
+	assert.Equal(t, "web", msg.Origin.Service())
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, time.Date(2019, 4, 20, 10, 12, 34, 500000000, time.UTC), msg.Timestamp)
+	// the content is untouched when no attribute is renamed or removed
+	assert.Equal(t, content, string(msg.Content))
+}
+
+func TestJSONParsingRenamesAndRemovesAttributes(t *testing.T) {
+	parsing := newJSONParsing(&config.JSONParsing{
+		StatusAttributes:    []string{"severity"},
+		TimestampAttributes: []string{"ts"},
+		RenameAttributes:    map[string]string{"lvl": "severity", "msg": "message"},
+		RemoveAttributes:    []string{"pid"},
+	})
+	source := config.NewLogSource("", &config.LogsConfig{Service: "api"})
+	msg := newMessage([]byte(`{"lvl":4,"ts":1555755154500,"msg":"a < b","pid":12,"count":1.50}`), source, "")
+
+	applyJSONParsing(parsing, msg)
+	assert.Equal(t, `{"count":1.50,"message":"a < b","severity":4,"ts":1555755154500}`, string(msg.Content))
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, time.Date(2019, 4, 20, 10, 12, 34, 500000000, time.UTC), msg.Timestamp.Round(time.Millisecond))
+	// the service of the configuration takes precedence
+	assert.Equal(t, "api", msg.Origin.Service())
+}
+
+func TestJSONParsingIgnoresOtherContents(t *testing.T) {
+	parsing := newJSONParsing(&config.JSONParsing{RemoveAttributes: []string{"status"}})
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	for _, content := range []string{
+		"plain text",
+		`["status"]`,
+		`{"status":"error"`,
+		`{"status":"error"} trailing`,
+		"null",
+	} {
+		msg := newMessage([]byte(content), source, message.StatusInfo)
+		applyJSONParsing(parsing, msg)
+		assert.Equal(t, content, string(msg.Content))
+		assert.Equal(t, message.StatusInfo, msg.GetStatus())
+		assert.True(t, msg.Timestamp.IsZero())
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Date(2019, 4, 20, 10, 12, 34, 0, time.UTC)
+	for _, value := range []interface{}{"2019-04-20T10:12:34Z", "2019-04-20T12:12:34+02:00", json.Number("1555755154"), json.Number("1555755154000")} {
+		timestamp, ok := parseTimestamp(value)
+		assert.True(t, ok)
+		assert.Equal(t, expected, timestamp)
+	}
+	for _, value := range []interface{}{"20/Apr/2019", json.Number("-1"), true, nil} {
+		_, ok := parseTimestamp(value)
+		assert.False(t, ok)
+	}
+}
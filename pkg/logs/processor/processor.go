@@ -6,11 +6,18 @@
 package processor
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
 )
 
 // A Processor updates messages from an inputChan and pushes
@@ -19,18 +26,34 @@ type Processor struct {
 	inputChan       chan *message.Message
 	outputChan      chan *message.Message
 	processingRules []*config.ProcessingRule
+	rateLimiter     *config.RateLimiter
+	memoryLimiter   *config.MemoryLimiter
+	enricher        tag.Enricher
 	encoder         Encoder
-	done            chan struct{}
+	// diagnosticMessageReceiver streams the processed messages, can be nil
+	diagnosticMessageReceiver diagnostic.MessageReceiver
+	// limitedSources are the sources with lines dropped by the rate limits
+	// and not reported yet
+	limitedSources map[*config.LogSource]bool
+	flushChan      chan chan struct{}
+	done           chan struct{}
 }
 
-// New returns an initialized Processor.
-func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder) *Processor {
+// New returns an initialized Processor, the memory of the messages forwarded
+// is acquired from the memory limiter when there is one.
+func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, rateLimiter *config.RateLimiter, memoryLimiter *config.MemoryLimiter, enricher tag.Enricher, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver) *Processor {
 	return &Processor{
-		inputChan:       inputChan,
-		outputChan:      outputChan,
-		processingRules: processingRules,
-		encoder:         encoder,
-		done:            make(chan struct{}),
+		inputChan:                 inputChan,
+		outputChan:                outputChan,
+		processingRules:           processingRules,
+		rateLimiter:               rateLimiter,
+		memoryLimiter:             memoryLimiter,
+		enricher:                  enricher,
+		encoder:                   encoder,
+		diagnosticMessageReceiver: diagnosticMessageReceiver,
+		limitedSources:            make(map[*config.LogSource]bool),
+		flushChan:                 make(chan chan struct{}),
+		done:                      make(chan struct{}),
 	}
 }
 
@@ -46,28 +69,138 @@ func (p *Processor) Stop() {
 	<-p.done
 }
 
+// Flush returns once the message being processed, if any, is forwarded to
+// the outputChan or when ctx is done.
+func (p *Processor) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case p.flushChan <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // run starts the processing of the inputChan
 func (p *Processor) run() {
+	ticker := time.NewTicker(droppedReportPeriod)
 	defer func() {
+		ticker.Stop()
 		p.done <- struct{}{}
 	}()
-	for msg := range p.inputChan {
-		metrics.LogsDecoded.Add(1)
-		if shouldProcess, redactedMsg := p.applyRedactingRules(msg); shouldProcess {
-			metrics.LogsProcessed.Add(1)
-
-			// Encode the message to its final format
-			content, err := p.encoder.encode(msg, redactedMsg)
-			if err != nil {
-				log.Error("unable to encode msg ", err)
-				continue
+	for {
+		select {
+		case msg, isOpen := <-p.inputChan:
+			if !isOpen {
+				return
 			}
-			msg.Content = content
-			p.outputChan <- msg
+			p.process(msg)
+		case <-ticker.C:
+			p.reportLimitedSources()
+		case done := <-p.flushChan:
+			close(done)
 		}
 	}
 }
 
+// process processes a message and forwards it to the outputChan.
+func (p *Processor) process(msg *message.Message) {
+	metrics.LogsDecoded.Add(1)
+	metrics.TlmLogsDecoded.Inc()
+	if parsing := msg.Origin.LogSource.Config.JSONParsing; parsing != nil {
+		applyJSONParsing(parsing, msg)
+	}
+	shouldProcess, redactedMsg := p.applyRedactingRules(msg)
+	if !shouldProcess || p.isRateLimited(msg, redactedMsg) {
+		return
+	}
+	metrics.LogsProcessed.Add(1)
+	metrics.TlmLogsProcessed.Inc()
+
+	// Attach the tags of the container or the pod the message originates from
+	p.enricher.Enrich(msg.Origin)
+	if msg.Truncated {
+		msg.Origin.AddTags(truncatedTags)
+	}
+	if p.diagnosticMessageReceiver != nil {
+		p.diagnosticMessageReceiver.HandleMessage(msg, redactedMsg)
+	}
+
+	// Encode the message to its final format
+	content, err := p.encoder.encode(msg, redactedMsg)
+	if err != nil {
+		log.Error("unable to encode msg ", err)
+		return
+	}
+	msg.Content = content
+
+	// the memory is released once the message is sent
+	p.memoryLimiter.Acquire(len(msg.Content))
+	p.outputChan <- msg
+}
+
+// truncatedTags mark the messages truncated by the line size limit
+var truncatedTags = []string{"truncated:true"}
+
+// droppedReportPeriod is the minimum period between two reports of the lines
+// of a source dropped by the rate limits
+var droppedReportPeriod = 10 * time.Second
+
+// isRateLimited returns whether the message exceeds the rate limits of its
+// source or the global ones and must be dropped. The lines dropped are
+// reported at most every droppedReportPeriod with a log of the source, with
+// the next line allowed or by reportLimitedSources when the source is quiet.
+func (p *Processor) isRateLimited(msg *message.Message, content []byte) bool {
+	sourceLimiter := msg.Origin.LogSource.RateLimiter()
+	if !config.AllowAll(len(content), sourceLimiter, p.rateLimiter) {
+		sourceLimiter.AddDropped(1)
+		metrics.LogsRateLimited.Add(1)
+		if p.limitedSources == nil {
+			p.limitedSources = make(map[*config.LogSource]bool)
+		}
+		p.limitedSources[msg.Origin.LogSource] = true
+		return true
+	}
+	if dropped := sourceLimiter.FlushDropped(droppedReportPeriod); dropped > 0 {
+		p.reportDropped(msg.Origin.LogSource, dropped)
+	}
+	return false
+}
+
+// reportLimitedSources reports the lines dropped by the rate limits that
+// weren't reported with a line allowed since.
+func (p *Processor) reportLimitedSources() {
+	for source := range p.limitedSources {
+		sourceLimiter := source.RateLimiter()
+		if dropped := sourceLimiter.FlushDropped(droppedReportPeriod); dropped > 0 {
+			p.reportDropped(source, dropped)
+		}
+		if !sourceLimiter.HasDropped() {
+			delete(p.limitedSources, source)
+		}
+	}
+}
+
+// reportDropped sends a log of the source with the number of lines dropped by the rate limits.
+func (p *Processor) reportDropped(source *config.LogSource, dropped int64) {
+	log.Warnf("%d log lines of source %s were dropped by the rate limits", dropped, source.Name)
+	content := []byte(fmt.Sprintf("%d lines dropped by the rate limits of the datadog agent", dropped))
+	msg := message.NewMessage(content, message.NewOrigin(source), message.StatusWarning)
+	encoded, err := p.encoder.encode(msg, content)
+	if err != nil {
+		log.Error("unable to encode msg ", err)
+		return
+	}
+	msg.Content = encoded
+	p.memoryLimiter.Acquire(len(msg.Content))
+	p.outputChan <- msg
+}
+
 // applyRedactingRules returns given a message if we should process it or not,
 // and a copy of the message with some fields redacted, depending on config
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
@@ -84,8 +217,37 @@ func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 				return false, nil
 			}
 		case config.MaskSequences:
-			content = rule.Regex.ReplaceAllLiteral(content, rule.Placeholder)
+			content = maskSequences(rule, content)
 		}
 	}
 	return true, content
 }
+
+var (
+	// the decoder joins the lines of a multi-line event with escaped line breaks
+	escapedLineBreak = []byte(`\n`)
+	lineBreak        = []byte("\n")
+)
+
+// maskSequences replaces the sequences matching the rule. With the
+// `multi_line` option, the lines of a multi-line event are joined with line
+// breaks while they are matched so that a sequence can span several lines,
+// e.g. with a `(?s)` pattern. It's opt-in as it changes what the patterns
+// match, like `.*` stopping at the end of the first line. The placeholder is
+// literal, unless the `expand_placeholder` option lets it reference the
+// capture groups of the pattern with `${name}` or `${1}`.
+func maskSequences(rule *config.ProcessingRule, content []byte) []byte {
+	multiLine := rule.MatchMultiLine && bytes.Contains(content, escapedLineBreak) && !bytes.Contains(content, lineBreak)
+	if multiLine {
+		content = bytes.Replace(content, escapedLineBreak, lineBreak, -1)
+	}
+	if rule.ExpandPlaceholder {
+		content = rule.Regex.ReplaceAll(content, rule.Placeholder)
+	} else {
+		content = rule.Regex.ReplaceAllLiteral(content, rule.Placeholder)
+	}
+	if multiLine {
+		content = bytes.Replace(content, lineBreak, escapedLineBreak, -1)
+	}
+	return content
+}
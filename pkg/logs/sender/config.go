@@ -6,16 +6,24 @@
 package sender
 
 import (
+	"compress/gzip"
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-const endpointPrefix = "agent-intake.logs."
+const (
+	endpointPrefix     = "agent-intake.logs."
+	httpEndpointPrefix = "agent-http-intake.logs."
+
+	defaultBatchWait        = 5 * time.Second
+	defaultCompressionLevel = 6
+)
 
 var logsEndpoints = map[string]int{
 	"agent-intake.logs.datadoghq.com": 10516,
@@ -29,6 +37,9 @@ func BuildEndpoints() (*client.Endpoints, error) {
 	if config.Datadog.GetBool("logs_config.dev_mode_no_ssl") {
 		log.Warnf("Use of illegal configuration parameter, if you need to send your logs to a proxy, please use 'logs_config.logs_dd_url' and 'logs_config.logs_no_ssl' instead")
 	}
+	if config.Datadog.GetBool("logs_config.use_http") {
+		return buildHTTPEndpoints()
+	}
 
 	var useSSL bool
 	useProto := config.Datadog.GetBool("logs_config.dev_mode_use_proto")
@@ -85,6 +96,70 @@ func BuildEndpoints() (*client.Endpoints, error) {
 	return client.NewEndpoints(main, additionals), nil
 }
 
+// buildHTTPEndpoints returns the endpoints to send batches of logs to over HTTP.
+func buildHTTPEndpoints() (*client.Endpoints, error) {
+	main := client.Endpoint{
+		APIKey:           getLogsAPIKey(config.Datadog),
+		UseCompression:   config.Datadog.GetBool("logs_config.use_compression"),
+		CompressionLevel: compressionLevel(config.Datadog),
+	}
+	if isSetAndNotEmpty(config.Datadog, "logs_config.logs_dd_url") {
+		// Proxy settings, expect 'logs_config.logs_dd_url' to respect the format '<HOST>:<PORT>'
+		// and '<PORT>' to be an integer.
+		// By default ssl is enabled ; to disable ssl set 'logs_config.logs_no_ssl' to true.
+		host, portString, err := net.SplitHostPort(config.Datadog.GetString("logs_config.logs_dd_url"))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse logs_dd_url: %v", err)
+		}
+		port, err := strconv.Atoi(portString)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse logs_dd_url port: %v", err)
+		}
+		main.Host = host
+		main.Port = port
+		main.UseSSL = !config.Datadog.GetBool("logs_config.logs_no_ssl")
+	} else {
+		main.Host = config.GetMainEndpoint(httpEndpointPrefix, "logs_config.dd_url")
+		main.Port = 443
+		main.UseSSL = true
+	}
+
+	var additionals []client.Endpoint
+	err := config.Datadog.UnmarshalKey("logs_config.additional_endpoints", &additionals)
+	if err != nil {
+		log.Warnf("Could not parse additional_endpoints for logs: %v", err)
+	}
+	for i := 0; i < len(additionals); i++ {
+		additionals[i].UseSSL = main.UseSSL
+		additionals[i].UseCompression = main.UseCompression
+		additionals[i].CompressionLevel = main.CompressionLevel
+	}
+
+	return client.NewHTTPEndpoints(main, additionals, batchWait(config.Datadog)), nil
+}
+
+// batchWait returns the maximum time the logs wait in a batch, the default
+// when the setting is invalid
+func batchWait(config config.Config) time.Duration {
+	batchWait := config.GetInt("logs_config.batch_wait")
+	if batchWait <= 0 {
+		log.Warnf("Invalid logs_config.batch_wait %d, it must be positive, using %v", batchWait, defaultBatchWait)
+		return defaultBatchWait
+	}
+	return time.Duration(batchWait) * time.Second
+}
+
+// compressionLevel returns the gzip compression level of the batches, the
+// default when the setting is invalid
+func compressionLevel(config config.Config) int {
+	level := config.GetInt("logs_config.compression_level")
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		log.Warnf("Invalid logs_config.compression_level %d, it must be between %d and %d, using %d", level, gzip.BestSpeed, gzip.BestCompression, defaultCompressionLevel)
+		return defaultCompressionLevel
+	}
+	return level
+}
+
 func isSetAndNotEmpty(config config.Config, key string) bool {
 	return config.IsSet(key) && len(config.GetString(key)) > 0
 }
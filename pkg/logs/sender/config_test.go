@@ -7,6 +7,7 @@ package sender
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -60,6 +61,56 @@ func (suite *ConfigTestSuite) TestLogsEndpointConfig() {
 	suite.Equal(1234, endpoints.Main.Port)
 }
 
+func (suite *ConfigTestSuite) TestBuildHTTPEndpoints() {
+	suite.config.Set("api_key", "azerty")
+	suite.config.Set("logs_config.use_http", true)
+	suite.config.Set("logs_config.batch_wait", 10)
+	suite.config.Set("logs_config.additional_endpoints", []map[string]interface{}{{"api_key": "foo", "host": "bar"}})
+
+	endpoints, err := BuildEndpoints()
+	suite.Nil(err)
+	suite.True(endpoints.UseHTTP)
+	suite.Equal(10*time.Second, endpoints.BatchWait)
+	suite.Equal("azerty", endpoints.Main.APIKey)
+	suite.Equal("agent-http-intake.logs.datadoghq.com", endpoints.Main.Host)
+	suite.Equal(443, endpoints.Main.Port)
+	suite.True(endpoints.Main.UseSSL)
+	suite.True(endpoints.Main.UseCompression)
+	suite.Equal(6, endpoints.Main.CompressionLevel)
+	suite.Len(endpoints.Additionals, 1)
+	suite.Equal("bar", endpoints.Additionals[0].Host)
+	suite.True(endpoints.Additionals[0].UseSSL)
+	suite.True(endpoints.Additionals[0].UseCompression)
+
+	// invalid settings fall back to the defaults
+	defer suite.config.Set("logs_config.batch_wait", 5)
+	defer suite.config.Set("logs_config.compression_level", 6)
+	suite.config.Set("logs_config.batch_wait", 0)
+	suite.config.Set("logs_config.compression_level", 12)
+	endpoints, err = BuildEndpoints()
+	suite.Nil(err)
+	suite.Equal(5*time.Second, endpoints.BatchWait)
+	suite.Equal(6, endpoints.Main.CompressionLevel)
+	suite.Equal(6, endpoints.Additionals[0].CompressionLevel)
+
+	suite.config.Set("logs_config.batch_wait", -1)
+	suite.config.Set("logs_config.compression_level", 1)
+	endpoints, err = BuildEndpoints()
+	suite.Nil(err)
+	suite.Equal(5*time.Second, endpoints.BatchWait)
+	suite.Equal(1, endpoints.Main.CompressionLevel)
+
+	suite.config.Set("logs_config.logs_dd_url", "proxy.local:8080")
+	suite.config.Set("logs_config.logs_no_ssl", true)
+	suite.config.Set("logs_config.use_compression", false)
+	endpoints, err = BuildEndpoints()
+	suite.Nil(err)
+	suite.Equal("proxy.local", endpoints.Main.Host)
+	suite.Equal(8080, endpoints.Main.Port)
+	suite.False(endpoints.Main.UseSSL)
+	suite.False(endpoints.Main.UseCompression)
+}
+
 func (suite *ConfigTestSuite) TestBuildEndpointsShouldSucceedWithDefaultAndValidOverride() {
 	var endpoints *client.Endpoints
 
@@ -18,15 +18,19 @@ type Sender struct {
 	inputChan    chan *message.Message
 	outputChan   chan *message.Message
 	destinations *client.Destinations
+	strategy     Strategy
+	flushChan    chan chan struct{}
 	done         chan struct{}
 }
 
 // NewSender returns an new sender.
-func NewSender(inputChan, outputChan chan *message.Message, destinations *client.Destinations) *Sender {
+func NewSender(inputChan, outputChan chan *message.Message, destinations *client.Destinations, strategy Strategy) *Sender {
 	return &Sender{
 		inputChan:    inputChan,
 		outputChan:   outputChan,
 		destinations: destinations,
+		strategy:     strategy,
+		flushChan:    make(chan chan struct{}),
 		done:         make(chan struct{}),
 	}
 }
@@ -48,44 +52,60 @@ func (s *Sender) run() {
 	defer func() {
 		s.done <- struct{}{}
 	}()
-	for payload := range s.inputChan {
-		s.send(payload)
+	s.strategy.Send(s.inputChan, s.outputChan, s.flushChan, s.send)
+}
+
+// Flush sends the messages received so far, it returns once they are sent
+// or when ctx is done.
+func (s *Sender) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case s.flushChan <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// send keeps trying to send the message to the main destination until it succeeds
-// and try to send the message to the additional destinations only once.
-func (s *Sender) send(payload *message.Message) {
+// send keeps trying to send the payload to the main destination until it succeeds
+// and try to send the payload to the additional destinations only once,
+// returns an error if the payload was dropped.
+func (s *Sender) send(payload []byte) error {
 	for {
 		// this call is blocking until payload is sent (or the connection destination context cancelled)
-		err := s.destinations.Main.Send(payload.Content)
+		err := s.destinations.Main.Send(payload)
 		if err != nil {
+			metrics.DestinationErrors.Add(1)
+			metrics.TlmDestinationErrors.Inc()
 			if err == context.Canceled {
-				metrics.DestinationErrors.Add(1)
 				// the context was cancelled, agent is stopping non-gracefully.
-				// drop the message
-				break
+				// drop the payload
+				metrics.BytesDropped.Add(int64(len(payload)))
+				return err
 			}
 			switch err.(type) {
-			case *client.FramingError:
-				metrics.DestinationErrors.Add(1)
-				// the message can not be framed properly,
-				// drop the message
-				break
+			case *client.FramingError, *client.HTTPError:
+				// the payload can not be framed properly or was rejected by the intake,
+				// drop the payload
+				metrics.BytesDropped.Add(int64(len(payload)))
+				return err
 			default:
-				metrics.DestinationErrors.Add(1)
 				// retry as the error can be related to network issues
 				continue
 			}
 		}
+		metrics.BytesSent.Add(int64(len(payload)))
+		metrics.TlmBytesSent.Add(float64(len(payload)))
 		for _, destination := range s.destinations.Additionals {
 			// send to a queue then send asynchronously for additional endpoints,
-			// it will drop messages if the queue is full
-			destination.SendAsync(payload.Content)
+			// it will drop payloads if the queue is full
+			destination.SendAsync(payload)
 		}
-
-		metrics.LogsSent.Add(1)
-		break
+		return nil
 	}
-	s.outputChan <- payload
 }
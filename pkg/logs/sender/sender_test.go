@@ -5,7 +5,9 @@
 package sender
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 
@@ -35,7 +37,7 @@ func TestSender(t *testing.T) {
 	destination := client.AddrToDestination(l.Addr(), destinationsCtx)
 	destinations := client.NewDestinations(destination, nil)
 
-	sender := NewSender(input, output, destinations)
+	sender := NewSender(input, output, destinations, StreamStrategy)
 	sender.Start()
 
 	expectedMessage := newMessage([]byte("fake line"), source, "")
@@ -66,9 +68,9 @@ func TestSenderNotBlockedByAdditional(t *testing.T) {
 	mainDestination := client.AddrToDestination(l.Addr(), destinationsCtx)
 	// This destination doesn't exists
 	additionalDestination := client.NewDestination(client.Endpoint{Host: "dont.exist.local", Port: 0}, destinationsCtx)
-	destinations := client.NewDestinations(mainDestination, []*client.Destination{additionalDestination})
+	destinations := client.NewDestinations(mainDestination, []client.Destination{additionalDestination})
 
-	sender := NewSender(input, output, destinations)
+	sender := NewSender(input, output, destinations, StreamStrategy)
 	sender.Start()
 
 	expectedMessage1 := newMessage([]byte("fake line"), source, "")
@@ -86,3 +88,34 @@ func TestSenderNotBlockedByAdditional(t *testing.T) {
 	sender.Stop()
 	destinationsCtx.Stop()
 }
+
+func TestSenderFlush(t *testing.T) {
+	l := mock.NewMockLogsIntake(t)
+	defer l.Close()
+
+	input := make(chan *message.Message, 1)
+	output := make(chan *message.Message, 1)
+
+	destinationsCtx := client.NewDestinationsContext()
+	destinationsCtx.Start()
+	destinations := client.NewDestinations(client.AddrToDestination(l.Addr(), destinationsCtx), nil)
+
+	sender := NewSender(input, output, destinations, NewBatchStrategy(time.Hour))
+
+	// the flush waits for the sender to be running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, sender.Flush(ctx))
+
+	sender.Start()
+	expectedMessage := newMessage([]byte("fake line"), config.NewLogSource("", &config.LogsConfig{}), "")
+	input <- expectedMessage
+	for len(input) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, sender.Flush(context.Background()))
+	assert.Equal(t, expectedMessage, <-output)
+
+	sender.Stop()
+	destinationsCtx.Stop()
+}
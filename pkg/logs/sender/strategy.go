@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package sender
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+const (
+	// maxBatchSize is the maximum number of messages in a batch.
+	maxBatchSize = 200
+	// maxContentSize is the maximum size of the payload of a batch, in bytes.
+	maxContentSize = 1000000
+)
+
+// Strategy sends the messages of inputChan with send and forwards them to
+// outputChan once they have been sent, until inputChan is closed. The
+// channels received from flushChan are closed once the messages received
+// before are sent.
+type Strategy interface {
+	Send(inputChan, outputChan chan *message.Message, flushChan chan chan struct{}, send func([]byte) error)
+}
+
+// StreamStrategy sends the messages one by one.
+var StreamStrategy Strategy = &streamStrategy{}
+
+type streamStrategy struct{}
+
+// Send sends the messages one by one.
+func (s *streamStrategy) Send(inputChan, outputChan chan *message.Message, flushChan chan chan struct{}, send func([]byte) error) {
+	for {
+		select {
+		case msg, isOpen := <-inputChan:
+			if !isOpen {
+				return
+			}
+			if err := send(msg.Content); err == nil {
+				metrics.LogsSent.Add(1)
+				metrics.TlmLogsSent.Inc()
+			}
+			outputChan <- msg
+		case done := <-flushChan:
+			// the messages are sent as soon as they are received
+			close(done)
+		}
+	}
+}
+
+// batchStrategy sends the messages by batches, serialized as a json array.
+type batchStrategy struct {
+	batchWait time.Duration
+	buffer    []*message.Message
+	size      int
+}
+
+// NewBatchStrategy returns a strategy sending the messages by batches of at
+// most maxBatchSize messages and maxContentSize bytes, a batch is sent when
+// it is full or batchWait after the previous one.
+func NewBatchStrategy(batchWait time.Duration) Strategy {
+	return &batchStrategy{
+		batchWait: batchWait,
+	}
+}
+
+// Send accumulates the messages and sends them by batches.
+func (s *batchStrategy) Send(inputChan, outputChan chan *message.Message, flushChan chan chan struct{}, send func([]byte) error) {
+	ticker := time.NewTicker(s.batchWait)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, isOpen := <-inputChan:
+			if !isOpen {
+				// the input channel is closed, flush the remaining messages
+				s.flush(outputChan, send)
+				return
+			}
+			if len(s.buffer) > 0 && s.size+len(msg.Content)+1 > maxContentSize {
+				s.flush(outputChan, send)
+			}
+			s.buffer = append(s.buffer, msg)
+			s.size += len(msg.Content) + 1
+			if len(s.buffer) >= maxBatchSize {
+				s.flush(outputChan, send)
+			}
+		case <-ticker.C:
+			s.flush(outputChan, send)
+		case done := <-flushChan:
+			s.flush(outputChan, send)
+			close(done)
+		}
+	}
+}
+
+// flush sends the messages of the buffer as one payload and forwards them to outputChan.
+func (s *batchStrategy) flush(outputChan chan *message.Message, send func([]byte) error) {
+	if len(s.buffer) == 0 {
+		return
+	}
+	if err := send(s.serialize()); err == nil {
+		metrics.LogsSent.Add(int64(len(s.buffer)))
+		metrics.TlmLogsSent.Add(float64(len(s.buffer)))
+	}
+	for _, msg := range s.buffer {
+		outputChan <- msg
+	}
+	s.buffer = s.buffer[:0]
+	s.size = 0
+}
+
+// serialize returns the contents of the messages of the buffer as a json array.
+func (s *batchStrategy) serialize() []byte {
+	var payload bytes.Buffer
+	payload.Grow(s.size + 1)
+	payload.WriteByte('[')
+	for i, msg := range s.buffer {
+		if i > 0 {
+			payload.WriteByte(',')
+		}
+		payload.Write(msg.Content)
+	}
+	payload.WriteByte(']')
+	return payload.Bytes()
+}
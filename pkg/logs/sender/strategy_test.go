@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package sender
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestStreamStrategy(t *testing.T) {
+	input := make(chan *message.Message)
+	output := make(chan *message.Message)
+	var payloads []string
+
+	go StreamStrategy.Send(input, output, nil, func(payload []byte) error {
+		payloads = append(payloads, string(payload))
+		return nil
+	})
+
+	msg := newMessage([]byte("a"), config.NewLogSource("", &config.LogsConfig{}), "")
+	input <- msg
+	assert.Equal(t, msg, <-output)
+	assert.Equal(t, []string{"a"}, payloads)
+	close(input)
+}
+
+func TestBatchStrategySendsFullBatches(t *testing.T) {
+	input := make(chan *message.Message)
+	output := make(chan *message.Message, maxBatchSize)
+	payloads := make(chan string, 1)
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	go NewBatchStrategy(time.Hour).Send(input, output, nil, func(payload []byte) error {
+		payloads <- string(payload)
+		return nil
+	})
+
+	for i := 0; i < maxBatchSize; i++ {
+		input <- newMessage([]byte(fmt.Sprintf(`{"i":%d}`, i)), source, "")
+	}
+	payload := <-payloads
+	assert.Contains(t, payload, `[{"i":0},{"i":1},`)
+	assert.Contains(t, payload, `,{"i":199}]`)
+	assert.Len(t, output, maxBatchSize)
+	close(input)
+}
+
+func TestBatchStrategyFlushesOnBatchWait(t *testing.T) {
+	input := make(chan *message.Message)
+	output := make(chan *message.Message, 2)
+	payloads := make(chan string, 1)
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	go NewBatchStrategy(10*time.Millisecond).Send(input, output, nil, func(payload []byte) error {
+		payloads <- string(payload)
+		return nil
+	})
+
+	input <- newMessage([]byte(`"a"`), source, "")
+	input <- newMessage([]byte(`"b"`), source, "")
+	assert.Equal(t, `["a","b"]`, <-payloads)
+	assert.Len(t, output, 2)
+	close(input)
+}
+
+func TestBatchStrategyFlushesWhenInputIsClosed(t *testing.T) {
+	input := make(chan *message.Message, 1)
+	output := make(chan *message.Message, 1)
+	var payloads []string
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	input <- newMessage([]byte(`"a"`), source, "")
+	close(input)
+	NewBatchStrategy(time.Hour).Send(input, output, nil, func(payload []byte) error {
+		payloads = append(payloads, string(payload))
+		return nil
+	})
+	assert.Equal(t, []string{`["a"]`}, payloads)
+	assert.Len(t, output, 1)
+}
+
+func TestBatchStrategyFlushesOnDemand(t *testing.T) {
+	input := make(chan *message.Message)
+	output := make(chan *message.Message, 1)
+	flush := make(chan chan struct{})
+	payloads := make(chan string, 1)
+	source := config.NewLogSource("", &config.LogsConfig{})
+
+	go NewBatchStrategy(time.Hour).Send(input, output, flush, func(payload []byte) error {
+		payloads <- string(payload)
+		return nil
+	})
+
+	input <- newMessage([]byte(`"a"`), source, "")
+	done := make(chan struct{})
+	flush <- done
+	<-done
+	assert.Equal(t, `["a"]`, <-payloads)
+	assert.Len(t, output, 1)
+	close(input)
+}
@@ -92,13 +92,13 @@ func TestStatusDeduplicateErrorsAndWarnings(t *testing.T) {
 func TestMetrics(t *testing.T) {
 	defer Clear()
 	Clear()
-	var expected = `{"DestinationErrors": 0, "DestinationLogsDropped": {}, "Errors": "", "IsRunning": false, "LogsDecoded": 0, "LogsProcessed": 0, "LogsSent": 0, "Warnings": ""}`
+	var expected = `{"AutoMultiLinePatterns": {}, "BytesDropped": 0, "BytesSent": 0, "BytesSpilled": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "Errors": "", "IsRunning": false, "LogsDecoded": 0, "LogsProcessed": 0, "LogsRateLimited": 0, "LogsSent": 0, "LogsTruncated": 0, "PipelinesBlocked": 0, "Warnings": ""}`
 	assert.Equal(t, expected, metrics.LogsExpvars.String())
 
 	createSources()
 	AddGlobalWarning("bar", "Unique Warning")
 	AddGlobalError("bar", "I am an error")
-	expected = `{"DestinationErrors": 0, "DestinationLogsDropped": {}, "Errors": "I am an error", "IsRunning": true, "LogsDecoded": 0, "LogsProcessed": 0, "LogsSent": 0, "Warnings": "Unique Warning"}`
+	expected = `{"AutoMultiLinePatterns": {}, "BytesDropped": 0, "BytesSent": 0, "BytesSpilled": 0, "DestinationErrors": 0, "DestinationLogsDropped": {}, "Errors": "I am an error", "IsRunning": true, "LogsDecoded": 0, "LogsProcessed": 0, "LogsRateLimited": 0, "LogsSent": 0, "LogsTruncated": 0, "PipelinesBlocked": 0, "Warnings": "Unique Warning"}`
 	assert.Equal(t, expected, metrics.LogsExpvars.String())
 }
 
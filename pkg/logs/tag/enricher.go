@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package tag
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// serviceTagPrefix prefixes the tag holding the service of an entity.
+const serviceTagPrefix = "service:"
+
+// Enricher attaches to the messages the tags of the container or the pod
+// they originate from, and the tags of the cluster.
+type Enricher interface {
+	Enrich(origin *message.Origin)
+}
+
+// NoopEnricher does not enrich the messages.
+var NoopEnricher Enricher = &noopEnricher{}
+
+type noopEnricher struct{}
+
+func (e *noopEnricher) Enrich(origin *message.Origin) {}
+
+// enricher resolves the tags of the entity of the origins with the tagger,
+// at the logs cardinality, and gets the cluster tags from the Cluster Agent.
+// A nil getter disables the corresponding tags.
+type enricher struct {
+	getTags        func(entityID string) ([]string, error)
+	getClusterTags func() ([]string, error)
+
+	m                sync.Mutex
+	clusterTags      []string
+	clusterTagsFetch time.Time
+}
+
+// NewEnricher returns an enricher resolving the tags of the entities with the
+// tagger when entityTags is set, and attaching the cluster tags served by the
+// Cluster Agent to all the messages when clusterTags is set.
+func NewEnricher(entityTags, clusterTags bool) Enricher {
+	e := &enricher{}
+	if entityTags {
+		e.getTags = func(entityID string) ([]string, error) {
+			return tagger.Tag(entityID, tagger.LogsCardinality)
+		}
+	}
+	if clusterTags {
+		e.getClusterTags = hostinfo.GetClusterTags
+	}
+	return e
+}
+
+// Enrich adds the tags of the entity of the origin and the cluster tags
+// missing from its tags, the service of the origin defaults to the service
+// tag of the entity.
+func (e *enricher) Enrich(origin *message.Origin) {
+	if e.getTags != nil {
+		e.addEntityTags(origin)
+	}
+	if e.getClusterTags != nil {
+		origin.AddTags(e.getCachedClusterTags())
+	}
+}
+
+func (e *enricher) addEntityTags(origin *message.Origin) {
+	entityID := origin.EntityID()
+	if entityID == "" {
+		return
+	}
+	tags, err := e.getTags(entityID)
+	if err != nil {
+		log.Debugf("Could not resolve the tags of %s: %v", entityID, err)
+		return
+	}
+	origin.AddTags(tags)
+	if origin.Service() != "" {
+		return
+	}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, serviceTagPrefix) {
+			origin.SetService(strings.TrimPrefix(tag, serviceTagPrefix))
+			return
+		}
+	}
+}
+
+// getCachedClusterTags returns the cluster tags, they are refreshed at most
+// once per refreshPeriod and the last ones are kept on errors.
+func (e *enricher) getCachedClusterTags() []string {
+	e.m.Lock()
+	defer e.m.Unlock()
+	if time.Since(e.clusterTagsFetch) < refreshPeriod {
+		return e.clusterTags
+	}
+	e.clusterTagsFetch = time.Now()
+	tags, err := e.getClusterTags()
+	if err != nil {
+		log.Debugf("Could not get the cluster tags: %v", err)
+		return e.clusterTags
+	}
+	e.clusterTags = tags
+	return e.clusterTags
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package tag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newTestEnricher(tags map[string][]string) *enricher {
+	return &enricher{
+		getTags: func(entityID string) ([]string, error) {
+			if tags, found := tags[entityID]; found {
+				return tags, nil
+			}
+			return nil, fmt.Errorf("unknown entity %s", entityID)
+		},
+	}
+}
+
+func TestEnricherAddsEntityTags(t *testing.T) {
+	e := newTestEnricher(map[string][]string{
+		"docker://foo": {"service:web", "env:prod", "version:1.2", "container_name:foo"},
+	})
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{}))
+	origin.SetTags([]string{"container_name:foo"})
+	origin.SetEntityID("docker://foo")
+
+	e.Enrich(origin)
+	assert.Equal(t, []string{"container_name:foo", "service:web", "env:prod", "version:1.2"}, origin.Tags())
+	assert.Equal(t, "web", origin.Service())
+}
+
+func TestEnricherKeepsConfiguredService(t *testing.T) {
+	e := newTestEnricher(map[string][]string{
+		"docker://foo": {"service:web"},
+	})
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{Service: "api"}))
+	origin.SetEntityID("docker://foo")
+
+	e.Enrich(origin)
+	assert.Equal(t, "api", origin.Service())
+}
+
+func TestEnricherIgnoresOriginsWithoutEntity(t *testing.T) {
+	e := newTestEnricher(map[string][]string{})
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{}))
+
+	e.Enrich(origin)
+	assert.Empty(t, origin.Tags())
+
+	origin.SetEntityID("docker://bar")
+	e.Enrich(origin)
+	assert.Empty(t, origin.Tags())
+}
+
+func TestEnricherAddsClusterTags(t *testing.T) {
+	calls := 0
+	e := &enricher{
+		getClusterTags: func() ([]string, error) {
+			calls++
+			return []string{"kube_cluster_name:foo", "project:bar"}, nil
+		},
+	}
+
+	// the cluster tags are attached to the origins without entity too
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{Tags: []string{"project:bar"}}))
+	e.Enrich(origin)
+	assert.Equal(t, []string{"kube_cluster_name:foo", "project:bar"}, origin.Tags())
+
+	other := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{}))
+	e.Enrich(other)
+	assert.Equal(t, []string{"kube_cluster_name:foo", "project:bar"}, other.Tags())
+	assert.Equal(t, 1, calls)
+}
+
+func TestEnricherKeepsClusterTagsOnError(t *testing.T) {
+	e := &enricher{
+		getClusterTags: func() ([]string, error) {
+			return nil, fmt.Errorf("cluster agent unreachable")
+		},
+		clusterTags: []string{"kube_cluster_name:foo"},
+	}
+
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{}))
+	e.Enrich(origin)
+	assert.Equal(t, []string{"kube_cluster_name:foo"}, origin.Tags())
+}
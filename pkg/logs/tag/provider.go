@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger"
-	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
 const refreshPeriod = 10 * time.Second
@@ -76,7 +75,7 @@ func (p *provider) Stop() {
 func (p *provider) updateTags() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	tags, err := tagger.Tag(p.entityID, collectors.HighCardinality)
+	tags, err := tagger.Tag(p.entityID, tagger.LogsCardinality)
 	if err == nil && !reflect.DeepEqual(tags, p.tags) {
 		p.tags = tags
 	}
@@ -8,9 +8,11 @@ package host
 import (
 	"os"
 	"path"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metadata/common"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/alibaba"
@@ -22,7 +24,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/cloudfoundry"
 	"github.com/DataDog/datadog-agent/pkg/util/ec2"
 	"github.com/DataDog/datadog-agent/pkg/util/gce"
+	"github.com/DataDog/datadog-agent/pkg/util/ibm"
 	k8s "github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
+	"github.com/DataDog/datadog-agent/pkg/util/oracle"
 )
 
 const packageCachePrefix = "host"
@@ -80,30 +84,44 @@ func GetPythonVersion() string {
 	return "n/a"
 }
 
-// getHostAliases returns the hostname aliases from different provider
-// This should include GCE, Azure, Cloud foundry, kubernetes
-func getHostAliases() []string {
-	aliases := []string{}
+// cloudProviderAliases maps the names used in cloud_provider_metadata to the
+// functions returning the host alias of each cloud provider
+var cloudProviderAliases = map[string]func() (string, error){
+	"alibaba": alibaba.GetHostAlias,
+	"azure":   azure.GetHostAlias,
+	"gcp":     gce.GetHostAlias,
+	"oracle":  oracle.GetHostAlias,
+	"ibm":     ibm.GetHostAlias,
+}
 
-	alibabaAlias, err := alibaba.GetHostAlias()
-	if err != nil {
-		log.Debugf("no Alibaba Host Alias: %s", err)
-	} else if alibabaAlias != "" {
-		aliases = append(aliases, alibabaAlias)
+// cloudProviderEnabled returns whether the metadata of a cloud provider is
+// collected, according to cloud_provider_metadata
+func cloudProviderEnabled(name string) bool {
+	for _, provider := range config.Datadog.GetStringSlice("cloud_provider_metadata") {
+		if strings.ToLower(provider) == name {
+			return true
+		}
 	}
+	return false
+}
 
-	azureAlias, err := azure.GetHostAlias()
-	if err != nil {
-		log.Debugf("no Azure Host Alias: %s", err)
-	} else if azureAlias != "" {
-		aliases = append(aliases, azureAlias)
-	}
+// getHostAliases returns the hostname aliases from different provider
+// This should include the cloud providers enabled in cloud_provider_metadata
+// (in the order they're listed), Cloud foundry and kubernetes
+func getHostAliases() []string {
+	aliases := []string{}
 
-	gceAlias, err := gce.GetHostAlias()
-	if err != nil {
-		log.Debugf("no GCE Host Alias: %s", err)
-	} else {
-		aliases = append(aliases, gceAlias)
+	for _, provider := range config.Datadog.GetStringSlice("cloud_provider_metadata") {
+		getAlias, found := cloudProviderAliases[strings.ToLower(provider)]
+		if !found {
+			continue
+		}
+		alias, err := getAlias()
+		if err != nil {
+			log.Debugf("no %s Host Alias: %s", provider, err)
+		} else if alias != "" {
+			aliases = append(aliases, alias)
+		}
 	}
 
 	cfAliases, err := cloudfoundry.GetHostAliases()
@@ -126,8 +144,11 @@ func getHostAliases() []string {
 func getMeta() *Meta {
 	hostname, _ := os.Hostname()
 	tzname, _ := time.Now().Zone()
-	ec2Hostname, _ := ec2.GetHostname()
-	instanceID, _ := ec2.GetInstanceID()
+	var ec2Hostname, instanceID string
+	if cloudProviderEnabled("aws") {
+		ec2Hostname, _ = ec2.GetHostname()
+		instanceID, _ = ec2.GetInstanceID()
+	}
 
 	m := &Meta{
 		SocketHostname: hostname,
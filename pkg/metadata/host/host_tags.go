@@ -3,13 +3,19 @@ package host
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/alibaba"
+	"github.com/DataDog/datadog-agent/pkg/util/azure"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/ec2"
 	"github.com/DataDog/datadog-agent/pkg/util/gce"
+	"github.com/DataDog/datadog-agent/pkg/util/ibm"
 	k8s "github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/oracle"
 )
 
 // this is a "low-tech" version of tagger/utils/taglist.go
@@ -52,13 +58,24 @@ func getHostTags() *tags {
 	hostTags := make([]string, 0, len(rawHostTags))
 	hostTags = appendToHostTags(hostTags, rawHostTags)
 
-	if config.Datadog.GetBool("collect_ec2_tags") {
-		ec2Tags, err := ec2.GetTags()
-		if err != nil {
-			log.Debugf("No EC2 host tags %v", err)
-		} else {
-			hostTags = appendToHostTags(hostTags, ec2Tags)
-		}
+	if config.Datadog.GetBool("collect_ec2_tags") && cloudProviderEnabled("aws") {
+		hostTags = appendToHostTags(hostTags, getCloudProviderTags("EC2", ec2.GetTags))
+	}
+
+	if config.Datadog.GetBool("collect_azure_tags") && cloudProviderEnabled("azure") {
+		hostTags = appendToHostTags(hostTags, getCloudProviderTags("Azure", azure.GetTags))
+	}
+
+	if config.Datadog.GetBool("collect_alibaba_tags") && cloudProviderEnabled("alibaba") {
+		hostTags = appendToHostTags(hostTags, getCloudProviderTags("Alibaba", alibaba.GetTags))
+	}
+
+	if config.Datadog.GetBool("collect_oracle_tags") && cloudProviderEnabled("oracle") {
+		hostTags = appendToHostTags(hostTags, getCloudProviderTags("Oracle", oracle.GetTags))
+	}
+
+	if config.Datadog.GetBool("collect_ibm_tags") && cloudProviderEnabled("ibm") {
+		hostTags = appendToHostTags(hostTags, getCloudProviderTags("IBM", ibm.GetTags))
 	}
 
 	k8sTags, err := k8s.GetTags()
@@ -68,6 +85,13 @@ func getHostTags() *tags {
 		hostTags = appendToHostTags(hostTags, k8sTags)
 	}
 
+	clusterTags, err := k8s.GetClusterTags()
+	if err != nil {
+		log.Debugf("No cluster tags from the Cluster Agent %v", err)
+	} else {
+		hostTags = appendToHostTags(hostTags, clusterTags)
+	}
+
 	dockerTags, err := docker.GetTags()
 	if err != nil {
 		log.Debugf("No Docker host tags %v", err)
@@ -76,13 +100,8 @@ func getHostTags() *tags {
 	}
 
 	gceTags := []string{}
-	if config.Datadog.GetBool("collect_gce_tags") {
-		rawGceTags, err := gce.GetTags()
-		if err != nil {
-			log.Debugf("No GCE host tags %v", err)
-		} else {
-			gceTags = appendToHostTags(gceTags, rawGceTags)
-		}
+	if config.Datadog.GetBool("collect_gce_tags") && cloudProviderEnabled("gcp") {
+		gceTags = appendToHostTags(gceTags, getCloudProviderTags("GCE", gce.GetTags))
 	}
 
 	return &tags{
@@ -90,3 +109,32 @@ func getHostTags() *tags {
 		GoogleCloudPlatform: gceTags,
 	}
 }
+
+// getCloudProviderTags returns the host tags of a cloud provider. They are
+// only queried once per cloud_provider_tags_refresh_interval, and the last
+// tags collected are kept while the provider API can't be reached.
+func getCloudProviderTags(provider string, getTags func() ([]string, error)) []string {
+	key := buildKey(provider + "Tags")
+	freshnessKey := buildKey(provider + "TagsFreshness")
+
+	cached, found := cache.Cache.Get(key)
+	if _, fresh := cache.Cache.Get(freshnessKey); found && fresh {
+		return cached.([]string)
+	}
+
+	tags, err := getTags()
+	if err != nil {
+		log.Debugf("No %s host tags %v", provider, err)
+		if found {
+			return cached.([]string)
+		}
+		return nil
+	}
+
+	refreshInterval := time.Duration(config.Datadog.GetInt("cloud_provider_tags_refresh_interval")) * time.Second
+	cache.Cache.Set(key, tags, cache.NoExpiration)
+	if refreshInterval > 0 {
+		cache.Cache.Set(freshnessKey, true, refreshInterval)
+	}
+	return tags
+}
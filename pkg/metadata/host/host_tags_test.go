@@ -1,9 +1,11 @@
 package host
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,3 +47,39 @@ func TestGetHostTagsWithoutSplits(t *testing.T) {
 	assert.NotNil(t, hostTags.System)
 	assert.Equal(t, []string{"tag1:value1", "tag2", "tag3", "kafka_partition:0,1,2"}, hostTags.System)
 }
+
+func TestGetCloudProviderTags(t *testing.T) {
+	calls := 0
+	var err error
+	getTags := func() ([]string, error) {
+		calls++
+		return []string{fmt.Sprintf("call:%d", calls)}, err
+	}
+
+	// tags are cached for the refresh interval
+	assert.Equal(t, []string{"call:1"}, getCloudProviderTags("test", getTags))
+	assert.Equal(t, []string{"call:1"}, getCloudProviderTags("test", getTags))
+	assert.Equal(t, 1, calls)
+
+	// the last tags collected are kept on errors
+	cache.Cache.Delete(buildKey("testTagsFreshness"))
+	err = fmt.Errorf("unreachable")
+	assert.Equal(t, []string{"call:1"}, getCloudProviderTags("test", getTags))
+	assert.Equal(t, 2, calls)
+
+	cache.Cache.Delete(buildKey("testTags"))
+	assert.Nil(t, getCloudProviderTags("test", getTags))
+}
+
+func TestCloudProviderEnabled(t *testing.T) {
+	mockConfig := config.Mock()
+	assert.True(t, cloudProviderEnabled("aws"))
+	assert.True(t, cloudProviderEnabled("ibm"))
+
+	mockConfig.Set("cloud_provider_metadata", []string{"GCP", "oracle"})
+	defer mockConfig.Set("cloud_provider_metadata", []string{"aws", "gcp", "azure", "alibaba", "oracle", "ibm"})
+	assert.True(t, cloudProviderEnabled("gcp"))
+	assert.True(t, cloudProviderEnabled("oracle"))
+	assert.False(t, cloudProviderEnabled("aws"))
+	assert.False(t, cloudProviderEnabled("alibaba"))
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package metadata
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+type inventoriesCollector struct {
+	coll inventories.CollectorInterface
+}
+
+// Send collects the data needed and submits the payload
+func (c inventoriesCollector) Send(s *serializer.Serializer) error {
+	hostname, _ := util.GetHostname()
+
+	payload := inventories.GetPayload(hostname, c.coll)
+	if err := s.SendMetadata(payload); err != nil {
+		return fmt.Errorf("unable to submit inventories metadata payload, %s", err)
+	}
+	return nil
+}
+
+// SetupInventories registers the inventories metadata collector, which needs
+// the collector to list the check instances, and schedules it
+func SetupInventories(scheduler *Scheduler, coll inventories.CollectorInterface, interval time.Duration) error {
+	RegisterCollector("inventories", inventoriesCollector{coll: coll})
+
+	return scheduler.AddCollector("inventories", interval)
+}
@@ -0,0 +1,19 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package inventories implements the inventories metadata provider.
+
+The payload describes the agent (its scrubbed configuration, its version and
+the features enabled) and every check instance scheduled by the collector
+(its version and the metadata it sets), to audit a fleet of agents.
+
+Like the external host tags provider, it keeps a cache that other packages can
+fill: the agent metadata with `SetAgentMetadata`, and the metadata of check
+instances with `SetCheckMetadata`, which Python checks call through
+`datadog_agent.set_check_metadata`. The metadata of the check instances that
+are no longer scheduled is removed from the cache at every collection.
+*/
+package inventories
@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package inventories
+
+import (
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// CollectorInterface is the subset of the collector used to list the check
+// instances, so that this package doesn't depend on the collector
+type CollectorInterface interface {
+	GetAllChecks() []check.Check
+}
+
+type checkMetadataCacheEntry struct {
+	LastUpdated           time.Time
+	CheckInstanceMetadata CheckInstanceMetadata
+}
+
+var (
+	// checkMetadata maps check ID -> metadata set by the check instance
+	checkMetadata      = make(map[string]*checkMetadataCacheEntry)
+	checkMetadataMutex = &sync.Mutex{}
+
+	agentMetadata      = make(AgentMetadata)
+	agentMetadataMutex = &sync.Mutex{}
+
+	// For testing purpose
+	timeNow = time.Now
+)
+
+// SetAgentMetadata updates a metadata value of the agent in the cache
+func SetAgentMetadata(name string, value interface{}) {
+	agentMetadataMutex.Lock()
+	defer agentMetadataMutex.Unlock()
+
+	agentMetadata[name] = value
+}
+
+// SetCheckMetadata updates a metadata value of a check instance in the cache
+func SetCheckMetadata(checkID, name string, value interface{}) {
+	checkMetadataMutex.Lock()
+	defer checkMetadataMutex.Unlock()
+
+	entry, found := checkMetadata[checkID]
+	if !found {
+		entry = &checkMetadataCacheEntry{
+			CheckInstanceMetadata: make(CheckInstanceMetadata),
+		}
+		checkMetadata[checkID] = entry
+	}
+	entry.LastUpdated = timeNow()
+	entry.CheckInstanceMetadata[name] = value
+}
+
+// GetPayload fills and returns the inventories metadata payload
+func GetPayload(hostname string, coll CollectorInterface) *Payload {
+	return &Payload{
+		Hostname:      hostname,
+		Timestamp:     timeNow().Unix(),
+		CheckMetadata: getCheckMetadata(coll),
+		AgentMetadata: getAgentMetadata(),
+	}
+}
+
+func getCheckMetadata(coll CollectorInterface) *CheckMetadata {
+	checkMetadataMutex.Lock()
+	defer checkMetadataMutex.Unlock()
+
+	payloadCheckMeta := make(CheckMetadata)
+	scheduled := make(map[string]struct{})
+
+	for _, c := range coll.GetAllChecks() {
+		checkID := string(c.ID())
+		scheduled[checkID] = struct{}{}
+
+		instance := CheckInstanceMetadata{
+			"config.hash": checkID,
+			"version":     c.Version(),
+		}
+		if entry, found := checkMetadata[checkID]; found {
+			for name, value := range entry.CheckInstanceMetadata {
+				instance[name] = value
+			}
+			instance["last_updated"] = entry.LastUpdated.Unix()
+		}
+		payloadCheckMeta[c.String()] = append(payloadCheckMeta[c.String()], &instance)
+	}
+
+	// the metadata of the check instances that were unscheduled is dropped
+	for checkID := range checkMetadata {
+		if _, found := scheduled[checkID]; !found {
+			delete(checkMetadata, checkID)
+		}
+	}
+
+	return &payloadCheckMeta
+}
+
+func getAgentMetadata() *AgentMetadata {
+	agentMetadataMutex.Lock()
+	defer agentMetadataMutex.Unlock()
+
+	payloadAgentMeta := AgentMetadata{
+		"agent_version": version.AgentVersion,
+		"features": map[string]interface{}{
+			"apm_enabled":           config.Datadog.GetBool("apm_config.enabled"),
+			"logs_enabled":          config.Datadog.GetBool("logs_enabled"),
+			"process_enabled":       config.Datadog.GetString("process_config.enabled"),
+			"dogstatsd_enabled":     config.Datadog.GetBool("use_dogstatsd"),
+			"cluster_agent_enabled": config.Datadog.GetBool("cluster_agent.enabled"),
+		},
+	}
+
+	if runtimeConfig, err := getScrubbedConfig(); err != nil {
+		log.Debugf("Unable to add the configuration to the inventories payload: %s", err)
+	} else {
+		payloadAgentMeta["config"] = runtimeConfig
+	}
+
+	for name, value := range agentMetadata {
+		payloadAgentMeta[name] = value
+	}
+
+	return &payloadAgentMeta
+}
+
+// getScrubbedConfig returns the runtime configuration of the agent as YAML,
+// with the credentials scrubbed
+func getScrubbedConfig() (string, error) {
+	runtimeConfig, err := yaml.Marshal(config.Datadog.AllSettings())
+	if err != nil {
+		return "", err
+	}
+	scrubbed, err := log.CredentialsCleanerBytes(runtimeConfig)
+	if err != nil {
+		return "", err
+	}
+	return string(scrubbed), nil
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// FIXTURE
+type testCheck struct {
+	name, id string
+}
+
+func (c *testCheck) String() string                                     { return c.name }
+func (c *testCheck) Version() string                                    { return "1.0.0" }
+func (c *testCheck) Stop()                                              {}
+func (c *testCheck) Configure(integration.Data, integration.Data) error { return nil }
+func (c *testCheck) Interval() time.Duration                            { return 1 }
+func (c *testCheck) Run() error                                         { return nil }
+func (c *testCheck) ID() check.ID                                       { return check.ID(c.id) }
+func (c *testCheck) GetWarnings() []error                               { return []error{} }
+func (c *testCheck) GetMetricStats() (map[string]int64, error)          { return make(map[string]int64), nil }
+
+type fakeCollector struct {
+	checks []check.Check
+}
+
+func (c *fakeCollector) GetAllChecks() []check.Check {
+	return c.checks
+}
+
+func clearMetadata() {
+	checkMetadata = make(map[string]*checkMetadataCacheEntry)
+	agentMetadata = make(AgentMetadata)
+}
+
+func TestGetPayload(t *testing.T) {
+	defer clearMetadata()
+	now := time.Unix(1557844364, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	SetAgentMetadata("test", "value")
+	SetCheckMetadata("redis:1", "version.raw", "5.0.4")
+	SetCheckMetadata("nginx:1", "version.raw", "1.15.0")
+
+	coll := &fakeCollector{checks: []check.Check{
+		&testCheck{name: "redis", id: "redis:1"},
+		&testCheck{name: "redis", id: "redis:2"},
+	}}
+	payload := GetPayload("test-host", coll)
+
+	assert.Equal(t, "test-host", payload.Hostname)
+	assert.Equal(t, now.Unix(), payload.Timestamp)
+
+	require.Len(t, (*payload.CheckMetadata)["redis"], 2)
+	assert.ElementsMatch(t, []*CheckInstanceMetadata{
+		{"config.hash": "redis:1", "version": "1.0.0", "version.raw": "5.0.4", "last_updated": now.Unix()},
+		{"config.hash": "redis:2", "version": "1.0.0"},
+	}, (*payload.CheckMetadata)["redis"])
+
+	// the metadata of the unscheduled nginx instance is dropped
+	assert.NotContains(t, *payload.CheckMetadata, "nginx")
+	assert.NotContains(t, checkMetadata, "nginx:1")
+
+	agentMeta := *payload.AgentMetadata
+	assert.Equal(t, "value", agentMeta["test"])
+	assert.Contains(t, agentMeta, "agent_version")
+	assert.Contains(t, agentMeta, "features")
+
+	_, err := json.Marshal(payload)
+	assert.NoError(t, err)
+}
+
+func TestGetAgentMetadataScrubbed(t *testing.T) {
+	defer clearMetadata()
+	apiKey := config.Datadog.GetString("api_key")
+	config.Datadog.Set("api_key", "aaaaaaaaaaaaaaaaaaaaaaaaaaaabbbb")
+	defer config.Datadog.Set("api_key", apiKey)
+
+	agentMeta := *getAgentMetadata()
+	require.Contains(t, agentMeta, "config")
+	assert.NotContains(t, agentMeta["config"], "aaaaaaaaaaaaaaaaaaaaaaaaaaaabbbb")
+	assert.Contains(t, agentMeta["config"], "***************************abbbb")
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+)
+
+/*
+The payload looks like this:
+
+{
+	"hostname": "my-host",
+	"timestamp": 1557844364,
+	"check_metadata": {
+		"redis": [
+			{"config.hash": "redis:6e4f1c4a37e2c8a1", "version": "1.10.0", "last_updated": 1557844364, "version.raw": "5.0.4"}
+		]
+	},
+	"agent_metadata": {
+		"agent_version": "6.12.0",
+		"features": {"apm_enabled": true, "logs_enabled": false, ...},
+		"config": "<scrubbed runtime configuration>"
+	}
+}
+*/
+
+// CheckInstanceMetadata contains the metadata of a check instance
+type CheckInstanceMetadata map[string]interface{}
+
+// CheckMetadata contains the metadata of the check instances, by check name
+type CheckMetadata map[string][]*CheckInstanceMetadata
+
+// AgentMetadata contains the metadata of the agent
+type AgentMetadata map[string]interface{}
+
+// Payload handles the JSON unmarshalling of the inventories metadata payload
+type Payload struct {
+	Hostname      string         `json:"hostname"`
+	Timestamp     int64          `json:"timestamp"`
+	CheckMetadata *CheckMetadata `json:"check_metadata"`
+	AgentMetadata *AgentMetadata `json:"agent_metadata"`
+}
+
+// MarshalJSON serialization a Payload to JSON
+func (p *Payload) MarshalJSON() ([]byte, error) {
+	// use an alias to avoid infinite recursion while serializing
+	type PayloadAlias Payload
+
+	return json.Marshal((*PayloadAlias)(p))
+}
+
+// Marshal not implemented
+func (p *Payload) Marshal() ([]byte, error) {
+	return nil, fmt.Errorf("Inventories Payload serialization is not implemented")
+}
+
+// SplitPayload breaks the payload into times number of pieces
+func (p *Payload) SplitPayload(times int) ([]marshaler.Marshaler, error) {
+	// Metadata payloads are analyzed as a whole, so they cannot be split
+	return nil, fmt.Errorf("Inventories Payload splitting is not implemented")
+}
@@ -66,34 +66,36 @@ type Serie struct {
 type Series []*Serie
 
 func marshalPoints(points []Point) []*agentpayload.MetricsPayload_Sample_Point {
-	pointsPayload := []*agentpayload.MetricsPayload_Sample_Point{}
-
-	for _, p := range points {
-		pointsPayload = append(pointsPayload, &agentpayload.MetricsPayload_Sample_Point{
-			Ts:    int64(p.Ts),
-			Value: p.Value,
-		})
+	// allocate every point at once, series payloads can hold a lot of points
+	values := make([]agentpayload.MetricsPayload_Sample_Point, len(points))
+	pointsPayload := make([]*agentpayload.MetricsPayload_Sample_Point, len(points))
+
+	for i, p := range points {
+		values[i].Ts = int64(p.Ts)
+		values[i].Value = p.Value
+		pointsPayload[i] = &values[i]
 	}
 	return pointsPayload
 }
 
 // Marshal serialize timeseries using agent-payload definition
 func (series Series) Marshal() ([]byte, error) {
+	samples := make([]agentpayload.MetricsPayload_Sample, len(series))
 	payload := &agentpayload.MetricsPayload{
-		Samples:  []*agentpayload.MetricsPayload_Sample{},
+		Samples:  make([]*agentpayload.MetricsPayload_Sample, len(series)),
 		Metadata: &agentpayload.CommonMetadata{},
 	}
 
-	for _, serie := range series {
-		payload.Samples = append(payload.Samples,
-			&agentpayload.MetricsPayload_Sample{
-				Metric:         serie.Name,
-				Type:           serie.MType.String(),
-				Host:           serie.Host,
-				Points:         marshalPoints(serie.Points),
-				Tags:           serie.Tags,
-				SourceTypeName: serie.SourceTypeName,
-			})
+	for i, serie := range series {
+		samples[i] = agentpayload.MetricsPayload_Sample{
+			Metric:         serie.Name,
+			Type:           serie.MType.String(),
+			Host:           serie.Host,
+			Points:         marshalPoints(serie.Points),
+			Tags:           serie.Tags,
+			SourceTypeName: serie.SourceTypeName,
+		}
+		payload.Samples[i] = &samples[i]
 	}
 
 	return proto.Marshal(payload)
@@ -163,10 +165,15 @@ func (series Series) SplitPayload(times int) ([]marshaler.Marshaler, error) {
 		}
 	}
 
-	// if we only have one metric name we cannot split further
+	// if we only have one metric name we split its series, unless there
+	// is a single serie which cannot be split further
 	if len(metricsPerName) == 1 {
-		seriesExpvar.Add("SplitMetricsTooBig", 1)
-		return nil, fmt.Errorf("Cannot split metric '%s' into %d payload (it contains %d series)", series[0].Name, times, len(series))
+		if len(series) < 2 {
+			seriesExpvar.Add("SplitMetricsTooBig", 1)
+			return nil, fmt.Errorf("Cannot split metric '%s' into %d payload (it contains %d series)", series[0].Name, times, len(series))
+		}
+		seriesExpvar.Add("SplitMetricSeries", 1)
+		return series.splitSeries(times), nil
 	}
 
 	nbSeriesPerPayload := len(series) / times
@@ -199,6 +206,25 @@ func (series Series) SplitPayload(times int) ([]marshaler.Marshaler, error) {
 	return payloads, nil
 }
 
+// splitSeries breaks the series into "times" payloads of the same number of
+// series, regardless of their metric name
+func (series Series) splitSeries(times int) []marshaler.Marshaler {
+	if len(series) < times {
+		times = len(series)
+	}
+	payloads := make([]marshaler.Marshaler, 0, times)
+	batchSize := len(series) / times
+	for i := 0; i < times; i++ {
+		end := (i + 1) * batchSize
+		// the last payload gets the remaining series
+		if i == times-1 {
+			end = len(series)
+		}
+		payloads = append(payloads, series[i*batchSize:end])
+	}
+	return payloads
+}
+
 // UnmarshalJSON is a custom unmarshaller for Point (used for testing)
 func (p *Point) UnmarshalJSON(buf []byte) error {
 	tmp := []interface{}{&p.Ts, &p.Value}
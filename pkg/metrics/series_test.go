@@ -51,6 +51,30 @@ func TestMarshalSeries(t *testing.T) {
 	assert.Equal(t, newPayload.Samples[0].Points[1].Value, float64(12.12))
 }
 
+func TestMarshalMultipleSeries(t *testing.T) {
+	series := Series{
+		{Name: "test.metrics1", MType: APIGaugeType, Points: []Point{{Ts: 12345.0, Value: 1}}},
+		{Name: "test.metrics2", MType: APIRateType},
+		{Name: "test.metrics3", MType: APICountType, Points: []Point{{Ts: 12345.0, Value: 2}, {Ts: 67890.0, Value: 3}}},
+	}
+
+	payload, err := series.Marshal()
+	require.Nil(t, err)
+
+	newPayload := &agentpayload.MetricsPayload{}
+	require.Nil(t, proto.Unmarshal(payload, newPayload))
+
+	require.Len(t, newPayload.Samples, 3)
+	assert.Equal(t, "test.metrics1", newPayload.Samples[0].Metric)
+	assert.Len(t, newPayload.Samples[0].Points, 1)
+	assert.Equal(t, "test.metrics2", newPayload.Samples[1].Metric)
+	assert.Equal(t, "rate", newPayload.Samples[1].Type)
+	assert.Len(t, newPayload.Samples[1].Points, 0)
+	assert.Equal(t, "test.metrics3", newPayload.Samples[2].Metric)
+	require.Len(t, newPayload.Samples[2].Points, 2)
+	assert.Equal(t, float64(3), newPayload.Samples[2].Points[1].Value)
+}
+
 func TestPopulateDeviceField(t *testing.T) {
 	for _, tc := range []struct {
 		Tags           []string
@@ -131,8 +155,15 @@ func TestSplitSerieasOneMetric(t *testing.T) {
 		},
 	}
 
-	// One metric should not be splitable
+	// One metric is split on its series
 	res, err := s.SplitPayload(2)
+	assert.Nil(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, Series{s[0]}, res[0])
+	assert.Equal(t, Series{s[1]}, res[1])
+
+	// One serie should not be splitable
+	res, err = s[:1].SplitPayload(2)
 	assert.Nil(t, res)
 	assert.NotNil(t, err)
 }
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/DataDog/agent-payload/gogen"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
@@ -70,6 +71,9 @@ func (sl SketchSeriesList) Marshal() ([]byte, error) {
 
 // SplitPayload breaks the payload into times number of pieces
 func (sl SketchSeriesList) SplitPayload(times int) ([]marshaler.Marshaler, error) {
+	if len(sl) < 2 {
+		return nil, fmt.Errorf("cannot split a payload of %d sketch series", len(sl))
+	}
 	// Only break it down as much as possible
 	if len(sl) < times {
 		times = len(sl)
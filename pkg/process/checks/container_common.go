@@ -1,6 +1,23 @@
 package checks
 
-import "time"
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/remote"
+)
+
+// containerTags returns the tags of a container, they come from the tagger
+// of the core agent when the process-agent follows it
+var containerTags = func(entityID string) ([]string, error) {
+	return tagger.Tag(entityID, tagger.ProcessCardinality)
+}
+
+// UseRemoteTagger makes the container checks tag the containers with the
+// entities streamed by the tagger of the core agent
+func UseRemoteTagger(t *remote.Tagger) {
+	containerTags = t.Tag
+}
 
 func calculateCtrPct(cur, prev, sys2, sys1 uint64, numCPU int, before time.Time) float32 {
 	now := time.Now()
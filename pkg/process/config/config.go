@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/api/ipc"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	ecsutil "github.com/DataDog/datadog-agent/pkg/util/ecs"
@@ -89,6 +90,8 @@ type AgentConfig struct {
 	SystemProbeDebugPort         int
 	MaxClosedConnectionsBuffered int
 	MaxConnectionsStateBuffered  int
+	EnableTCPQueueLength         bool
+	EnableOOMKill                bool
 
 	// Check config
 	EnabledChecks  []string
@@ -395,9 +398,18 @@ func isAffirmative(value string) (bool, error) {
 	return v == "true" || v == "yes" || v == "1", nil
 }
 
-// getHostname shells out to obtain the hostname used by the infra agent
-// falling back to os.Hostname() if it is unavailable
+// getHostname asks the hostname used by the infra agent over its gRPC api,
+// else shells out to obtain it, falling back to os.Hostname() if it is
+// unavailable
 func getHostname(ddAgentBin string) (string, error) {
+	if socketPath := config.Datadog.GetString("cmd_grpc_socket"); socketPath != "" {
+		hostname, err := ipc.GetHostname(socketPath)
+		if err == nil {
+			return hostname, nil
+		}
+		log.Infof("error retrieving dd-agent hostname over gRPC, falling back to the agent binary: %v", err)
+	}
+
 	cmd := exec.Command(ddAgentBin, "hostname")
 
 	// Copying all environment variables to child process
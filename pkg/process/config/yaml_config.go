@@ -60,6 +60,12 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 		a.ConntrackShortTermBufferSize = s
 	}
 
+	// Whether the system probe should measure the fill of the TCP buffers for the tcp_queue_length check
+	a.EnableTCPQueueLength = config.Datadog.GetBool(key(spNS, "enable_tcp_queue_length"))
+
+	// Whether the system probe should watch the OOM kills for the oom_kill check
+	a.EnableOOMKill = config.Datadog.GetBool(key(spNS, "enable_oom_kill"))
+
 	if logFile := config.Datadog.GetString(key(spNS, "log_file")); logFile != "" {
 		a.LogFile = logFile
 	}
@@ -245,7 +251,7 @@ func (a *AgentConfig) loadProcessYamlConfig(path string) error {
 	}
 
 	// Used to override container source auto-detection.
-	// "docker", "ecs_fargate", "kubelet", etc
+	// "docker", "ecs_fargate", "kubelet", "cri", etc
 	if containerSource := config.Datadog.GetString(key(ns, "container_source")); containerSource != "" {
 		util.SetContainerSource(containerSource)
 	}
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"reflect"
 
-	zstd "github.com/DataDog/zstd.v0.5"
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
 )
@@ -40,6 +39,25 @@ const (
 
 const headerLength = 1 + 1 + 1 + 1 + 4
 
+// ZstdFunc compresses or decompresses src into dst
+type ZstdFunc func(dst, src []byte) ([]byte, error)
+
+// zstdCompress and zstdDecompress implement MessageEncodingZstdPB. They are
+// registered by the process agent: the zstd version the intake expects can't
+// be linked in the agent along with the one it already uses.
+var (
+	zstdCompress   ZstdFunc
+	zstdDecompress ZstdFunc
+)
+
+// RegisterZstd registers the zstd functions used by MessageEncodingZstdPB
+func RegisterZstd(compress, decompress ZstdFunc) {
+	zstdCompress = compress
+	zstdDecompress = decompress
+}
+
+var errZstdNotRegistered = fmt.Errorf("zstd encoding not registered")
+
 // MessageHeader is attached to all messages at the head of the message. Some
 // fields are added in later versions so make sure you're only using fields that
 // are available in the defined Version.
@@ -59,7 +77,10 @@ func unmarshal(enc MessageEncoding, body []byte, m proto.Message) error {
 	case MessageEncodingJSON:
 		return jsonpb.Unmarshal(bytes.NewReader(body), m)
 	case MessageEncodingZstdPB:
-		d, err := zstd.Decompress(nil, body)
+		if zstdDecompress == nil {
+			return errZstdNotRegistered
+		}
+		d, err := zstdDecompress(nil, body)
 		if err != nil {
 			return err
 		}
@@ -81,6 +102,7 @@ const (
 	TypeCollectorRealTime          = 27
 	TypeCollectorContainer         = 39
 	TypeCollectorContainerRealTime = 40
+	TypeCollectorManifest          = 56
 )
 
 // Message is a generic type for all messages with a Header and Body.
@@ -119,6 +141,8 @@ func DecodeMessage(data []byte) (Message, error) {
 		m = &CollectorContainer{}
 	case TypeCollectorContainerRealTime:
 		m = &CollectorContainerRealTime{}
+	case TypeCollectorManifest:
+		m = &CollectorManifest{}
 	default:
 		return Message{}, fmt.Errorf("unhandled message type: %d", header.Type)
 	}
@@ -144,6 +168,8 @@ func DetectMessageType(b MessageBody) (MessageType, error) {
 		t = TypeCollectorContainer
 	case *CollectorContainerRealTime:
 		t = TypeCollectorContainerRealTime
+	case *CollectorManifest:
+		t = TypeCollectorManifest
 	default:
 		return 0, fmt.Errorf("unknown message body type: %s", reflect.TypeOf(b))
 	}
@@ -182,7 +208,10 @@ func EncodeMessage(m Message) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		p, err = zstd.Compress(nil, pb)
+		if zstdCompress == nil {
+			return nil, errZstdNotRegistered
+		}
+		p, err = zstdCompress(nil, pb)
 		if err != nil {
 			return nil, err
 		}
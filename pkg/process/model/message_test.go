@@ -4,9 +4,14 @@ import (
 	"io/ioutil"
 	"testing"
 
+	zstd "github.com/DataDog/zstd.v0.5"
 	"github.com/stretchr/testify/assert"
 )
 
+func init() {
+	RegisterZstd(zstd.Compress, zstd.Decompress)
+}
+
 // TestDecodeZstd05Payload ensures backward compatibility with our intake
 func TestDecodeZstd05Payload(t *testing.T) {
 	file := "./testdata/test_zstd.0.5.dump"
@@ -0,0 +1,923 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: orchestrator.proto
+
+/*
+Package model is a generated protocol buffer package.
+
+It is generated from these files:
+
+	orchestrator.proto
+
+It has these top-level messages:
+
+	CollectorManifest
+	Manifest
+*/
+package model
+
+import proto "github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
+
+type ManifestType int32
+
+const (
+	ManifestType_pod        ManifestType = 0
+	ManifestType_deployment ManifestType = 1
+	ManifestType_replicaSet ManifestType = 2
+	ManifestType_node       ManifestType = 3
+)
+
+var ManifestType_name = map[int32]string{
+	0: "pod",
+	1: "deployment",
+	2: "replicaSet",
+	3: "node",
+}
+var ManifestType_value = map[string]int32{
+	"pod":        0,
+	"deployment": 1,
+	"replicaSet": 2,
+	"node":       3,
+}
+
+func (x ManifestType) String() string {
+	return proto.EnumName(ManifestType_name, int32(x))
+}
+func (ManifestType) EnumDescriptor() ([]byte, []int) { return fileDescriptorOrchestrator, []int{0} }
+
+type CollectorManifest struct {
+	ClusterName string      `protobuf:"bytes,1,opt,name=clusterName,proto3" json:"clusterName,omitempty"`
+	ClusterId   string      `protobuf:"bytes,2,opt,name=clusterId,proto3" json:"clusterId,omitempty"`
+	Manifests   []*Manifest `protobuf:"bytes,3,rep,name=manifests" json:"manifests,omitempty"`
+	GroupId     int32       `protobuf:"varint,4,opt,name=groupId,proto3" json:"groupId,omitempty"`
+	GroupSize   int32       `protobuf:"varint,5,opt,name=groupSize,proto3" json:"groupSize,omitempty"`
+}
+
+func (m *CollectorManifest) Reset()                    { *m = CollectorManifest{} }
+func (m *CollectorManifest) String() string            { return proto.CompactTextString(m) }
+func (*CollectorManifest) ProtoMessage()               {}
+func (*CollectorManifest) Descriptor() ([]byte, []int) { return fileDescriptorOrchestrator, []int{0} }
+
+func (m *CollectorManifest) GetClusterName() string {
+	if m != nil {
+		return m.ClusterName
+	}
+	return ""
+}
+
+func (m *CollectorManifest) GetClusterId() string {
+	if m != nil {
+		return m.ClusterId
+	}
+	return ""
+}
+
+func (m *CollectorManifest) GetManifests() []*Manifest {
+	if m != nil {
+		return m.Manifests
+	}
+	return nil
+}
+
+func (m *CollectorManifest) GetGroupId() int32 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *CollectorManifest) GetGroupSize() int32 {
+	if m != nil {
+		return m.GroupSize
+	}
+	return 0
+}
+
+type Manifest struct {
+	Type            ManifestType `protobuf:"varint,1,opt,name=type,proto3,enum=datadog.process_agent.ManifestType" json:"type,omitempty"`
+	Uid             string       `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	ResourceVersion string       `protobuf:"bytes,3,opt,name=resourceVersion,proto3" json:"resourceVersion,omitempty"`
+	Namespace       string       `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name            string       `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	// JSON encoded resource, the sensitive values are scrubbed
+	Content []byte `protobuf:"bytes,6,opt,name=content,proto3" json:"content,omitempty"`
+	// The resource was deleted, content holds its last known state
+	Deleted bool `protobuf:"varint,7,opt,name=deleted,proto3" json:"deleted,omitempty"`
+}
+
+func (m *Manifest) Reset()                    { *m = Manifest{} }
+func (m *Manifest) String() string            { return proto.CompactTextString(m) }
+func (*Manifest) ProtoMessage()               {}
+func (*Manifest) Descriptor() ([]byte, []int) { return fileDescriptorOrchestrator, []int{1} }
+
+func (m *Manifest) GetType() ManifestType {
+	if m != nil {
+		return m.Type
+	}
+	return ManifestType_pod
+}
+
+func (m *Manifest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *Manifest) GetResourceVersion() string {
+	if m != nil {
+		return m.ResourceVersion
+	}
+	return ""
+}
+
+func (m *Manifest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Manifest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Manifest) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (m *Manifest) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*CollectorManifest)(nil), "datadog.process_agent.CollectorManifest")
+	proto.RegisterType((*Manifest)(nil), "datadog.process_agent.Manifest")
+	proto.RegisterEnum("datadog.process_agent.ManifestType", ManifestType_name, ManifestType_value)
+}
+func (m *CollectorManifest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CollectorManifest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ClusterName) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.ClusterName)))
+		i += copy(dAtA[i:], m.ClusterName)
+	}
+	if len(m.ClusterId) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.ClusterId)))
+		i += copy(dAtA[i:], m.ClusterId)
+	}
+	if len(m.Manifests) > 0 {
+		for _, msg := range m.Manifests {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintOrchestrator(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.GroupId != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(m.GroupId))
+	}
+	if m.GroupSize != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(m.GroupSize))
+	}
+	return i, nil
+}
+
+func (m *Manifest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Manifest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Type != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(m.Type))
+	}
+	if len(m.Uid) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.Uid)))
+		i += copy(dAtA[i:], m.Uid)
+	}
+	if len(m.ResourceVersion) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.ResourceVersion)))
+		i += copy(dAtA[i:], m.ResourceVersion)
+	}
+	if len(m.Namespace) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.Namespace)))
+		i += copy(dAtA[i:], m.Namespace)
+	}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.Content) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintOrchestrator(dAtA, i, uint64(len(m.Content)))
+		i += copy(dAtA[i:], m.Content)
+	}
+	if m.Deleted {
+		dAtA[i] = 0x38
+		i++
+		if m.Deleted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func encodeVarintOrchestrator(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *CollectorManifest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ClusterName)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	l = len(m.ClusterId)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	if len(m.Manifests) > 0 {
+		for _, e := range m.Manifests {
+			l = e.Size()
+			n += 1 + l + sovOrchestrator(uint64(l))
+		}
+	}
+	if m.GroupId != 0 {
+		n += 1 + sovOrchestrator(uint64(m.GroupId))
+	}
+	if m.GroupSize != 0 {
+		n += 1 + sovOrchestrator(uint64(m.GroupSize))
+	}
+	return n
+}
+
+func (m *Manifest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovOrchestrator(uint64(m.Type))
+	}
+	l = len(m.Uid)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	l = len(m.ResourceVersion)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	l = len(m.Content)
+	if l > 0 {
+		n += 1 + l + sovOrchestrator(uint64(l))
+	}
+	if m.Deleted {
+		n += 2
+	}
+	return n
+}
+
+func sovOrchestrator(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozOrchestrator(x uint64) (n int) {
+	return sovOrchestrator(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *CollectorManifest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOrchestrator
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CollectorManifest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CollectorManifest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClusterName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClusterName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClusterId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClusterId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Manifests", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Manifests = append(m.Manifests, &Manifest{})
+			if err := m.Manifests[len(m.Manifests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupId |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupSize", wireType)
+			}
+			m.GroupSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupSize |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOrchestrator(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Manifest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOrchestrator
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Manifest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Manifest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= (ManifestType(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Uid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResourceVersion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ResourceVersion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Content", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Content = append(m.Content[:0], dAtA[iNdEx:postIndex]...)
+			if m.Content == nil {
+				m.Content = []byte{}
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deleted", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Deleted = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOrchestrator(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthOrchestrator
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipOrchestrator(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowOrchestrator
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowOrchestrator
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthOrchestrator
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowOrchestrator
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipOrchestrator(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthOrchestrator = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowOrchestrator   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("orchestrator.proto", fileDescriptorOrchestrator) }
+
+var fileDescriptorOrchestrator = []byte{
+	// 397 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0xb1, 0x8e, 0xd4, 0x30,
+	0x10, 0x86, 0xcf, 0x97, 0xbd, 0xdb, 0x8d, 0xef, 0x74, 0x04, 0x4b, 0x48, 0x2e, 0xd0, 0x12, 0x1d,
+	0x4d, 0x84, 0xc4, 0x46, 0x5a, 0x0a, 0x2a, 0x0a, 0x60, 0x9b, 0x2b, 0xa0, 0xc8, 0x21, 0x0a, 0x1a,
+	0xe4, 0xb3, 0x87, 0x5c, 0x44, 0x92, 0xb1, 0xec, 0x49, 0xb1, 0x3c, 0x05, 0x8f, 0x85, 0xa8, 0x78,
+	0x04, 0xb4, 0x88, 0xf7, 0x40, 0x36, 0x1b, 0xb2, 0x42, 0x48, 0x74, 0xf3, 0xfd, 0xbf, 0xc6, 0xf3,
+	0xcf, 0xc8, 0x5c, 0xa0, 0xd3, 0xb7, 0xe0, 0xc9, 0x29, 0x42, 0xb7, 0xb2, 0x0e, 0x09, 0xc5, 0x3d,
+	0xa3, 0x48, 0x19, 0xac, 0x03, 0x6a, 0xf0, 0xfe, 0xbd, 0xaa, 0xa1, 0xa7, 0xcb, 0xaf, 0x8c, 0xdf,
+	0x7d, 0x89, 0x6d, 0x0b, 0x9a, 0xd0, 0xbd, 0x52, 0x7d, 0xf3, 0x01, 0x3c, 0x89, 0x9c, 0x9f, 0xe9,
+	0x76, 0xf0, 0x04, 0xee, 0xb5, 0xea, 0x40, 0xb2, 0x9c, 0x15, 0x69, 0x75, 0x28, 0x89, 0xfb, 0x3c,
+	0xdd, 0xe3, 0x95, 0x91, 0xc7, 0xd1, 0x9f, 0x04, 0xf1, 0x8c, 0xa7, 0xdd, 0xfe, 0x2d, 0x2f, 0x93,
+	0x3c, 0x29, 0xce, 0xd6, 0x0f, 0x56, 0xff, 0x0c, 0xb0, 0x1a, 0x67, 0x56, 0x53, 0x87, 0x90, 0x7c,
+	0x5e, 0x3b, 0x1c, 0xec, 0x95, 0x91, 0xb3, 0x9c, 0x15, 0x27, 0xd5, 0x88, 0x61, 0x6c, 0x2c, 0xaf,
+	0x9b, 0x4f, 0x20, 0x4f, 0xa2, 0x37, 0x09, 0x97, 0x3f, 0x19, 0x5f, 0xfc, 0xd9, 0xe1, 0x29, 0x9f,
+	0xd1, 0xd6, 0xfe, 0x0e, 0x7f, 0xb1, 0x7e, 0xf8, 0x9f, 0xf1, 0x6f, 0xb6, 0x16, 0xaa, 0xd8, 0x20,
+	0x32, 0x9e, 0x0c, 0xcd, 0xb8, 0x54, 0x28, 0x45, 0xc1, 0xef, 0x38, 0xf0, 0x38, 0x38, 0x0d, 0x6f,
+	0xc1, 0xf9, 0x06, 0x7b, 0x99, 0x44, 0xf7, 0x6f, 0x39, 0xe4, 0xeb, 0x55, 0x07, 0xde, 0x2a, 0x0d,
+	0x31, 0x7b, 0x5a, 0x4d, 0x82, 0x10, 0x7c, 0x16, 0x20, 0x06, 0x4f, 0xab, 0x58, 0x87, 0x5d, 0x35,
+	0xf6, 0x04, 0x3d, 0xc9, 0xd3, 0x9c, 0x15, 0xe7, 0xd5, 0x88, 0xc1, 0x31, 0xd0, 0x02, 0x81, 0x91,
+	0xf3, 0x9c, 0x15, 0x8b, 0x6a, 0xc4, 0x47, 0xcf, 0xf9, 0xf9, 0x61, 0x6e, 0x31, 0xe7, 0x89, 0x45,
+	0x93, 0x1d, 0x89, 0x0b, 0xce, 0x0d, 0xd8, 0x16, 0xb7, 0x1d, 0xf4, 0x94, 0xb1, 0xc0, 0x0e, 0x6c,
+	0xdb, 0x68, 0x75, 0x0d, 0x94, 0x1d, 0x8b, 0x05, 0x9f, 0xf5, 0x68, 0x20, 0x4b, 0x5e, 0x6c, 0xbe,
+	0xec, 0x96, 0xec, 0xdb, 0x6e, 0xc9, 0xbe, 0xef, 0x96, 0xec, 0xf3, 0x8f, 0xe5, 0xd1, 0xbb, 0x75,
+	0xdd, 0xd0, 0xed, 0x70, 0xb3, 0xd2, 0xd8, 0x95, 0x1b, 0x45, 0x6a, 0x83, 0x75, 0xb9, 0xbf, 0xd9,
+	0xe3, 0x78, 0xab, 0xd2, 0x7e, 0xac, 0xcb, 0xfd, 0xf5, 0xca, 0x0e, 0x0d, 0xb4, 0x37, 0xa7, 0xf1,
+	0x6f, 0x3d, 0xf9, 0x35, 0x00, 0xd2, 0x4f, 0x37, 0xf2, 0x71, 0x02, 0x00, 0x00,
+}
@@ -0,0 +1,421 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package remoteconfig polls a remote configuration service for signed
+// values of the runtime settings, and applies them unless they are
+// overridden locally.
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	httpTimeout = 10 * time.Second
+	// maxPayloadSize is the maximum size in bytes of a payload
+	maxPayloadSize = 1024 * 1024
+	// maxHistory is the number of changes kept in the history of a client
+	maxHistory = 100
+)
+
+// Actions recorded in the history of the changes
+const (
+	ActionApplied  = "applied"
+	ActionReverted = "reverted"
+	ActionSkipped  = "skipped"
+	ActionFailed   = "failed"
+	ActionRejected = "rejected"
+)
+
+var (
+	remoteConfigExpvars = expvar.NewMap("remoteconfig")
+	remoteConfigVersion = expvar.Int{}
+	remoteConfigErrors  = expvar.Int{}
+	remoteConfigApplied = expvar.Int{}
+)
+
+func init() {
+	remoteConfigExpvars.Set("Version", &remoteConfigVersion)
+	remoteConfigExpvars.Set("Errors", &remoteConfigErrors)
+	remoteConfigExpvars.Set("Applied", &remoteConfigApplied)
+}
+
+// Change is an entry of the audit trail of the remote configuration
+type Change struct {
+	Time     time.Time `json:"time"`
+	Version  int64     `json:"version"`
+	Action   string    `json:"action"`
+	Setting  string    `json:"setting,omitempty"`
+	Value    string    `json:"value,omitempty"`
+	Previous string    `json:"previous,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// Client polls the remote configuration service and applies the settings it
+// returns with the runtime settings. The settings it applied are reverted to
+// their previous values once they are removed from the remote configuration.
+type Client struct {
+	url        string
+	apiKey     string
+	publicKey  *ecdsa.PublicKey
+	interval   time.Duration
+	httpClient *http.Client
+	stop       chan struct{}
+	done       chan struct{}
+
+	// stateFile persists the last applied version, so that older versions
+	// are still rejected after a restart. Empty to keep it in memory.
+	stateFile string
+	// hostname decides whether the agent is part of the sampled agents
+	hostname string
+
+	m        sync.Mutex
+	version  int64
+	applied  bool              // whether version was applied since the agent started
+	original map[string]string // values of the settings before they were applied
+	history  []Change
+}
+
+// state is the content of the state file of a client
+type state struct {
+	Version int64 `json:"version"`
+}
+
+// NewClient returns a client polling url every interval, the payloads must
+// be signed with the private key of publicKeyPEM
+func NewClient(url, apiKey, publicKeyPEM string, interval time.Duration) (*Client, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no remote configuration url")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid refresh interval %v", interval)
+	}
+	publicKey, err := parsePublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote configuration public key: %s", err)
+	}
+
+	return &Client{
+		url:        url,
+		apiKey:     apiKey,
+		publicKey:  publicKey,
+		interval:   interval,
+		httpClient: &http.Client{Timeout: httpTimeout, Transport: util.CreateHTTPTransport()},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		original:   make(map[string]string),
+	}, nil
+}
+
+// NewClientFromConfig returns a client set up with the `remote_configuration`
+// section of the configuration, starting from the last version it applied
+func NewClientFromConfig() (*Client, error) {
+	c, err := NewClient(
+		config.Datadog.GetString("remote_configuration.url"),
+		config.Datadog.GetString("api_key"),
+		config.Datadog.GetString("remote_configuration.public_key"),
+		time.Duration(config.Datadog.GetInt("remote_configuration.refresh_interval"))*time.Second,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.hostname, err = util.GetHostname()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the hostname to sample the remote configuration: %s", err)
+	}
+	c.stateFile = config.Datadog.GetString("remote_configuration.state_file")
+	if err := c.loadState(); err != nil {
+		return nil, fmt.Errorf("unable to read the last applied version from %s: %s", c.stateFile, err)
+	}
+	return c, nil
+}
+
+// Start polls the remote configuration service until Stop is called
+func (c *Client) Start() {
+	log.Infof("Polling the remote configuration from %s every %v", util.SanitizeURL(c.url), c.interval)
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			if err := c.poll(); err != nil {
+				remoteConfigErrors.Add(1)
+				log.Warnf("Unable to update the remote configuration: %s", err)
+			}
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling the remote configuration service
+func (c *Client) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+// History returns the audit trail of the remote configuration, oldest first
+func (c *Client) History() []Change {
+	c.m.Lock()
+	defer c.m.Unlock()
+	history := make([]Change, len(c.history))
+	copy(history, c.history)
+	return history
+}
+
+// poll fetches, verifies and applies the remote configuration
+func (c *Client) poll() error {
+	req, err := http.NewRequest("GET", c.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("DD-Api-Key", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	raw, err := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxPayloadSize + 1})
+	if err != nil {
+		return err
+	}
+	if len(raw) > maxPayloadSize {
+		return fmt.Errorf("payload larger than %d bytes", maxPayloadSize)
+	}
+
+	return c.update(raw, time.Now())
+}
+
+// update applies a raw payload received at now
+func (c *Client) update(raw []byte, now time.Time) error {
+	signed, err := verifyPayload(raw, c.publicKey)
+	if err != nil {
+		c.audit(Change{Action: ActionRejected, Reason: err.Error()})
+		return err
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if signed.Version == c.version && c.applied {
+		return nil
+	}
+	if signed.Version < c.version {
+		err = fmt.Errorf("version %d is older than the applied version %d", signed.Version, c.version)
+	} else if signed.Expires.IsZero() || !now.Before(signed.Expires) {
+		err = fmt.Errorf("version %d expired at %s", signed.Version, signed.Expires.Format(time.RFC3339))
+	} else if signed.SampleRate != nil && (*signed.SampleRate < 0 || *signed.SampleRate > 1) {
+		err = fmt.Errorf("version %d has an invalid sample rate %v", signed.Version, *signed.SampleRate)
+	}
+	if err != nil {
+		c.record(Change{Version: signed.Version, Action: ActionRejected, Reason: err.Error()})
+		return err
+	}
+
+	if signed.Version != c.version {
+		if err := c.saveState(signed.Version); err != nil {
+			// applying a version that can't be persisted would let a
+			// restart roll it back
+			err = fmt.Errorf("unable to persist version %d: %s", signed.Version, err)
+			c.record(Change{Version: signed.Version, Action: ActionRejected, Reason: err.Error()})
+			return err
+		}
+	}
+	c.version = signed.Version
+	c.applied = true
+	remoteConfigVersion.Set(signed.Version)
+
+	remote := signed.Settings
+	if signed.SampleRate != nil && !sampled(c.hostname, *signed.SampleRate) {
+		// the settings applied by the previous versions are reverted
+		c.record(Change{Version: signed.Version, Action: ActionSkipped, Reason: fmt.Sprintf("not sampled at rate %v", *signed.SampleRate)})
+		remote = nil
+	}
+	c.apply(signed.Version, remote)
+	return nil
+}
+
+// sampled returns whether the agent with the given hostname is part of the
+// rate of the agents applying a version. The agents sampled at a rate are
+// still sampled when it grows.
+func sampled(hostname string, rate float64) bool {
+	sum := sha256.Sum256([]byte(hostname))
+	return float64(binary.BigEndian.Uint32(sum[:4]))/(1<<32) < rate
+}
+
+// loadState reads the last applied version from the state file, if any
+func (c *Client) loadState() error {
+	if c.stateFile == "" {
+		return nil
+	}
+	raw, err := ioutil.ReadFile(c.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var s state
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	c.version = s.Version
+	remoteConfigVersion.Set(s.Version)
+	return nil
+}
+
+// saveState writes the applied version to the state file, through a
+// temporary file so that it's never left truncated
+func (c *Client) saveState(version int64) error {
+	if c.stateFile == "" {
+		return nil
+	}
+	raw, err := json.Marshal(state{Version: version})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(c.stateFile), filepath.Base(c.stateFile))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.stateFile)
+}
+
+// apply changes the runtime settings to the remote values, and reverts the
+// ones no longer part of the remote configuration. Callers must hold c.m.
+func (c *Client) apply(version int64, remote map[string]string) {
+	names := make([]string, 0, len(remote))
+	for name := range remote {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := remote[name]
+		current, err := settings.GetRuntimeSetting(name)
+		if err != nil {
+			c.record(Change{Version: version, Action: ActionSkipped, Setting: name, Value: value, Reason: "unsupported setting"})
+			continue
+		}
+		previous := fmt.Sprint(current)
+		if reason := localOverride(name); reason != "" {
+			c.record(Change{Version: version, Action: ActionSkipped, Setting: name, Value: value, Reason: reason})
+			continue
+		}
+		if _, applied := c.original[name]; applied && previous == value {
+			continue
+		}
+
+		if err := settings.SetRuntimeSettingFromSource(name, value, 0, settings.SourceRemoteConfig); err != nil {
+			c.record(Change{Version: version, Action: ActionFailed, Setting: name, Value: value, Previous: previous, Reason: err.Error()})
+			continue
+		}
+		if _, applied := c.original[name]; !applied {
+			c.original[name] = previous
+		}
+		remoteConfigApplied.Add(1)
+		c.record(Change{Version: version, Action: ActionApplied, Setting: name, Value: value, Previous: previous})
+	}
+
+	for name, original := range c.original {
+		if _, found := remote[name]; found {
+			continue
+		}
+		delete(c.original, name)
+		if source, _ := settings.RuntimeSettingSource(name); source != settings.SourceRemoteConfig {
+			// changed locally since it was applied, keep the local value
+			continue
+		}
+		current, _ := settings.GetRuntimeSetting(name)
+		if err := settings.SetRuntimeSettingFromSource(name, original, 0, settings.SourceDefault); err != nil {
+			c.record(Change{Version: version, Action: ActionFailed, Setting: name, Value: original, Previous: fmt.Sprint(current), Reason: err.Error()})
+			continue
+		}
+		c.record(Change{Version: version, Action: ActionReverted, Setting: name, Value: original, Previous: fmt.Sprint(current)})
+	}
+}
+
+// localOverride returns why a setting is overridden locally, or an empty
+// string when the remote configuration can change it. The configuration
+// keys the setting changes are checked, rather than its name.
+func localOverride(name string) string {
+	keys, _ := settings.RuntimeSettingConfigKeys(name)
+	for _, key := range keys {
+		if config.Datadog.InConfig(key) {
+			return fmt.Sprintf("%s set in the configuration file", key)
+		}
+		if env := "DD_" + strings.ToUpper(strings.Replace(key, ".", "_", -1)); os.Getenv(env) != "" {
+			return fmt.Sprintf("%s set in the environment", env)
+		}
+	}
+	if source, _ := settings.RuntimeSettingSource(name); source == settings.SourceCLI {
+		return "set with the config set command"
+	}
+	return ""
+}
+
+func (c *Client) audit(change Change) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.record(change)
+}
+
+// record logs a change and adds it to the history, callers must hold c.m
+func (c *Client) record(change Change) {
+	change.Time = time.Now()
+	switch change.Action {
+	case ActionApplied, ActionReverted:
+		log.Infof("Remote configuration version %d: %s %s to %q (was %q)", change.Version, change.Action, change.Setting, change.Value, change.Previous)
+	case ActionSkipped:
+		if change.Setting == "" {
+			log.Infof("Remote configuration version %d: skipped: %s", change.Version, change.Reason)
+			break
+		}
+		log.Infof("Remote configuration version %d: skipped %s: %s", change.Version, change.Setting, change.Reason)
+	case ActionFailed:
+		log.Warnf("Remote configuration version %d: unable to set %s to %q: %s", change.Version, change.Setting, change.Value, change.Reason)
+	default:
+		log.Warnf("Remote configuration rejected: %s", change.Reason)
+	}
+
+	c.history = append(c.history, change)
+	if len(c.history) > maxHistory {
+		c.history = c.history[len(c.history)-maxHistory:]
+	}
+}
@@ -0,0 +1,288 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+)
+
+type fakeSetting struct {
+	m     sync.Mutex
+	name  string
+	keys  []string
+	value string
+}
+
+func (f *fakeSetting) Name() string         { return f.name }
+func (f *fakeSetting) Description() string  { return "fake setting" }
+func (f *fakeSetting) ConfigKeys() []string { return f.keys }
+
+func (f *fakeSetting) Get() (interface{}, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.value, nil
+}
+
+func (f *fakeSetting) Set(value string) error {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.value = value
+	return nil
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key, publicKeyPEM(t, key)
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, config signedConfig) []byte {
+	signed, err := json.Marshal(config)
+	require.NoError(t, err)
+	digest := sha256.Sum256(signed)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	der, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	require.NoError(t, err)
+	raw, err := json.Marshal(payload{Signed: signed, Signature: base64.StdEncoding.EncodeToString(der)})
+	require.NoError(t, err)
+	return raw
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func newTestClient(t *testing.T, url string) (*Client, *ecdsa.PrivateKey) {
+	key, publicKeyPEM := newKey(t)
+	c, err := NewClient(url, "abcdef", publicKeyPEM, time.Minute)
+	require.NoError(t, err)
+	return c, key
+}
+
+func TestNewClient(t *testing.T) {
+	_, publicKeyPEM := newKey(t)
+	_, err := NewClient("", "abcdef", publicKeyPEM, time.Minute)
+	assert.Error(t, err)
+	_, err = NewClient("https://config.example.com", "abcdef", publicKeyPEM, 0)
+	assert.Error(t, err)
+	_, err = NewClient("https://config.example.com", "abcdef", "not a key", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestVerifyPayload(t *testing.T) {
+	key, publicKeyPEM := newKey(t)
+	publicKey, err := parsePublicKey(publicKeyPEM)
+	require.NoError(t, err)
+	config := signedConfig{Version: 3, Expires: time.Now().Add(time.Hour).UTC(), Settings: map[string]string{"log_level": "debug"}}
+
+	signed, err := verifyPayload(sign(t, key, config), publicKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), signed.Version)
+	assert.Equal(t, "debug", signed.Settings["log_level"])
+
+	// signed with another key
+	otherKey, _ := newKey(t)
+	_, err = verifyPayload(sign(t, otherKey, config), publicKey)
+	assert.Error(t, err)
+
+	// tampered with after being signed
+	var p payload
+	require.NoError(t, json.Unmarshal(sign(t, key, config), &p))
+	p.Signed = json.RawMessage(`{"version":3,"settings":{"log_level":"trace"}}`)
+	raw, _ := json.Marshal(p)
+	_, err = verifyPayload(raw, publicKey)
+	assert.Error(t, err)
+
+	_, err = verifyPayload([]byte(`{"signed":{"version":3}}`), publicKey)
+	assert.Error(t, err)
+}
+
+func TestUpdate(t *testing.T) {
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_update", value: "a"}))
+	c, key := newTestClient(t, "https://config.example.com")
+	now := time.Now()
+	expires := now.Add(time.Hour)
+
+	err := c.update(sign(t, key, signedConfig{Version: 2, Expires: expires, Settings: map[string]string{"remote_update": "b", "unknown": "c"}}), now)
+	require.NoError(t, err)
+	value, _ := settings.GetRuntimeSetting("remote_update")
+	assert.Equal(t, "b", value)
+	source, _ := settings.RuntimeSettingSource("remote_update")
+	assert.Equal(t, settings.SourceRemoteConfig, source)
+
+	history := c.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, ActionApplied, history[0].Action)
+	assert.Equal(t, "remote_update", history[0].Setting)
+	assert.Equal(t, "a", history[0].Previous)
+	assert.Equal(t, ActionSkipped, history[1].Action)
+	assert.Equal(t, "unknown", history[1].Setting)
+
+	// older and expired versions are rejected
+	assert.Error(t, c.update(sign(t, key, signedConfig{Version: 1, Expires: expires}), now))
+	assert.Error(t, c.update(sign(t, key, signedConfig{Version: 3, Expires: now.Add(-time.Second)}), now))
+	assert.Error(t, c.update(sign(t, key, signedConfig{Version: 3}), now))
+	value, _ = settings.GetRuntimeSetting("remote_update")
+	assert.Equal(t, "b", value)
+	assert.Equal(t, ActionRejected, c.History()[4].Action)
+
+	// removing the setting from the remote configuration reverts it
+	require.NoError(t, c.update(sign(t, key, signedConfig{Version: 3, Expires: expires}), now))
+	value, _ = settings.GetRuntimeSetting("remote_update")
+	assert.Equal(t, "a", value)
+	source, _ = settings.RuntimeSettingSource("remote_update")
+	assert.Equal(t, settings.SourceDefault, source)
+	history = c.History()
+	assert.Equal(t, ActionReverted, history[len(history)-1].Action)
+}
+
+func TestUpdateLocalOverride(t *testing.T) {
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_cli", value: "a"}))
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_env", keys: []string{"remote.env_key"}, value: "a"}))
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_name", keys: []string{"remote_name_key"}, value: "a"}))
+	require.NoError(t, settings.SetRuntimeSetting("remote_cli", "local", 0))
+	os.Setenv("DD_REMOTE_ENV_KEY", "a")
+	defer os.Unsetenv("DD_REMOTE_ENV_KEY")
+	// the name of the setting isn't a configuration key
+	os.Setenv("DD_REMOTE_NAME", "a")
+	defer os.Unsetenv("DD_REMOTE_NAME")
+
+	c, key := newTestClient(t, "https://config.example.com")
+	now := time.Now()
+	err := c.update(sign(t, key, signedConfig{Version: 1, Expires: now.Add(time.Hour), Settings: map[string]string{"remote_cli": "b", "remote_env": "b", "remote_name": "b"}}), now)
+	require.NoError(t, err)
+
+	value, _ := settings.GetRuntimeSetting("remote_cli")
+	assert.Equal(t, "local", value)
+	value, _ = settings.GetRuntimeSetting("remote_env")
+	assert.Equal(t, "a", value)
+	value, _ = settings.GetRuntimeSetting("remote_name")
+	assert.Equal(t, "b", value)
+
+	history := c.History()
+	require.Len(t, history, 3)
+	assert.Equal(t, ActionSkipped, history[0].Action)
+	assert.Equal(t, ActionSkipped, history[1].Action)
+	assert.Equal(t, "DD_REMOTE_ENV_KEY set in the environment", history[1].Reason)
+	assert.Equal(t, ActionApplied, history[2].Action)
+}
+
+func TestUpdateState(t *testing.T) {
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_state", value: "a"}))
+	dir, err := ioutil.TempDir("", "remoteconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	expires := now.Add(time.Hour)
+
+	c, key := newTestClient(t, "https://config.example.com")
+	c.stateFile = filepath.Join(dir, "remote_configuration.json")
+	require.NoError(t, c.loadState())
+	require.NoError(t, c.update(sign(t, key, signedConfig{Version: 2, Expires: expires, Settings: map[string]string{"remote_state": "b"}}), now))
+
+	// after a restart, the older versions are still rejected and the
+	// applied version is applied again
+	require.NoError(t, settings.SetRuntimeSettingFromSource("remote_state", "a", 0, settings.SourceDefault))
+	restarted, err := NewClient("https://config.example.com", "abcdef", publicKeyPEM(t, key), time.Minute)
+	require.NoError(t, err)
+	restarted.stateFile = c.stateFile
+	require.NoError(t, restarted.loadState())
+	assert.Equal(t, int64(2), restarted.version)
+
+	assert.Error(t, restarted.update(sign(t, key, signedConfig{Version: 1, Expires: expires, Settings: map[string]string{"remote_state": "c"}}), now))
+	value, _ := settings.GetRuntimeSetting("remote_state")
+	assert.Equal(t, "a", value)
+	require.NoError(t, restarted.update(sign(t, key, signedConfig{Version: 2, Expires: expires, Settings: map[string]string{"remote_state": "b"}}), now))
+	value, _ = settings.GetRuntimeSetting("remote_state")
+	assert.Equal(t, "b", value)
+
+	// a corrupted state file is an error
+	require.NoError(t, ioutil.WriteFile(c.stateFile, []byte("{"), 0600))
+	assert.Error(t, restarted.loadState())
+}
+
+func TestUpdateSampleRate(t *testing.T) {
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_sampled", value: "a"}))
+	c, key := newTestClient(t, "https://config.example.com")
+	c.hostname = "my-host"
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	all, none, invalid := 1.0, 0.0, 1.5
+
+	require.NoError(t, c.update(sign(t, key, signedConfig{Version: 1, Expires: expires, SampleRate: &all, Settings: map[string]string{"remote_sampled": "b"}}), now))
+	value, _ := settings.GetRuntimeSetting("remote_sampled")
+	assert.Equal(t, "b", value)
+
+	// the settings of the agents no longer sampled are reverted
+	require.NoError(t, c.update(sign(t, key, signedConfig{Version: 2, Expires: expires, SampleRate: &none, Settings: map[string]string{"remote_sampled": "b"}}), now))
+	value, _ = settings.GetRuntimeSetting("remote_sampled")
+	assert.Equal(t, "a", value)
+
+	assert.Error(t, c.update(sign(t, key, signedConfig{Version: 3, Expires: expires, SampleRate: &invalid}), now))
+	assert.Equal(t, int64(2), c.version)
+}
+
+func TestSampled(t *testing.T) {
+	sampledCount := 0
+	for i := 0; i < 1000; i++ {
+		hostname := fmt.Sprintf("host-%d", i)
+		assert.False(t, sampled(hostname, 0))
+		assert.True(t, sampled(hostname, 1))
+		if sampled(hostname, 0.1) {
+			sampledCount++
+			// growing the rate keeps the sampled agents
+			assert.True(t, sampled(hostname, 0.5))
+		}
+	}
+	assert.InDelta(t, 100, sampledCount, 50)
+}
+
+func TestPoll(t *testing.T) {
+	require.NoError(t, settings.RegisterRuntimeSetting(&fakeSetting{name: "remote_poll", value: "a"}))
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-Api-Key") != "abcdef" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	c, key := newTestClient(t, server.URL)
+	body = sign(t, key, signedConfig{Version: 1, Expires: time.Now().Add(time.Hour), Settings: map[string]string{"remote_poll": "b"}})
+	require.NoError(t, c.poll())
+	value, _ := settings.GetRuntimeSetting("remote_poll")
+	assert.Equal(t, "b", value)
+
+	c.apiKey = "invalid"
+	assert.Error(t, c.poll())
+}
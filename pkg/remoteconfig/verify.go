@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// payload is the response of the remote configuration service, signature
+// is the base64 encoded ASN.1 ECDSA signature of the SHA-256 of signed.
+type payload struct {
+	Signed    json.RawMessage `json:"signed"`
+	Signature string          `json:"signature"`
+}
+
+// signedConfig is the signed part of a payload, settings maps the names of
+// the runtime settings to their values. When set, sample_rate is the
+// fraction of the agents, between 0 and 1, applying the settings.
+type signedConfig struct {
+	Version    int64             `json:"version"`
+	Expires    time.Time         `json:"expires"`
+	SampleRate *float64          `json:"sample_rate,omitempty"`
+	Settings   map[string]string `json:"settings"`
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// parsePublicKey parses a PEM encoded ECDSA public key
+func parsePublicKey(publicKeyPEM string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the public key is not an ECDSA key")
+	}
+	return publicKey, nil
+}
+
+// verifyPayload checks the signature of a raw payload and returns the
+// configuration it holds
+func verifyPayload(raw []byte, publicKey *ecdsa.PublicKey) (*signedConfig, error) {
+	var p payload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %s", err)
+	}
+	if len(p.Signed) == 0 || p.Signature == "" {
+		return nil, fmt.Errorf("unsigned payload")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(p.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %s", err)
+	}
+	var sig ecdsaSignature
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 || sig.R == nil || sig.S == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	digest := sha256.Sum256(p.Signed)
+	if !ecdsa.Verify(publicKey, digest[:], sig.R, sig.S) {
+		return nil, fmt.Errorf("signature verification failed")
+	}
+
+	var config signedConfig
+	if err := json.Unmarshal(p.Signed, &config); err != nil {
+		return nil, fmt.Errorf("invalid signed configuration: %s", err)
+	}
+	return &config, nil
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // SecretInfo export troubleshooting information about the decrypted secrets
@@ -20,6 +21,21 @@ type SecretInfo struct {
 	UnixOwner      string
 	UnixGroup      string
 	SecretsHandles map[string][]string
+	// SecretsPaths lists the configuration paths using each handle, as
+	// "origin: path"
+	SecretsPaths map[string][]string
+	LastRefresh  time.Time
+	AuditTrail   []AuditRecord
+}
+
+// AuditRecord is an entry of the audit trail of the secrets: a handle was
+// decrypted for a configuration path, or its value changed during a refresh
+type AuditRecord struct {
+	Time   time.Time
+	Action string
+	Handle string
+	Origin string `json:",omitempty"`
+	Path   string `json:",omitempty"`
 }
 
 // Print output a SecretInfo to a io.Writer
@@ -42,5 +58,22 @@ func (si *SecretInfo) Print(w io.Writer) {
 	fmt.Fprintf(w, "Secrets handle decrypted:\n")
 	for handle, origins := range si.SecretsHandles {
 		fmt.Fprintf(w, "- %s: from %s\n", handle, strings.Join(origins, ", "))
+		for _, path := range si.SecretsPaths[handle] {
+			fmt.Fprintf(w, "    used by %s\n", path)
+		}
+	}
+	if si.LastRefresh.IsZero() {
+		fmt.Fprintf(w, "Last refresh: never\n")
+	} else {
+		fmt.Fprintf(w, "Last refresh: %s\n", si.LastRefresh.Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(w, "\n=== Secrets audit trail ===\n")
+	for _, record := range si.AuditTrail {
+		fmt.Fprintf(w, "%s %s %s", record.Time.Format(time.RFC3339), record.Action, record.Handle)
+		if record.Origin != "" {
+			fmt.Fprintf(w, " for %s: %s", record.Origin, record.Path)
+		}
+		fmt.Fprintf(w, "\n")
 	}
 }
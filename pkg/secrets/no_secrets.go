@@ -21,6 +21,22 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 	return data, nil
 }
 
+// Refresh encrypted secrets are not available on windows
+func Refresh(data []byte, origin string) ([]byte, error) {
+	return data, nil
+}
+
+// RefreshCallback is notified of the origins using a secret whose value changed
+type RefreshCallback func(origins []string)
+
+// RegisterRefreshCallback placeholder when compiled without the 'secrets' build tag
+func RegisterRefreshCallback(callback RefreshCallback) {}
+
+// RefreshAll encrypted secrets are not available on windows
+func RefreshAll() ([]string, error) {
+	return nil, fmt.Errorf("Secret feature is not available in this version of the agent")
+}
+
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
 	return nil, fmt.Errorf("Secret feature is not available in this version of the agent")
@@ -9,7 +9,10 @@ package secrets
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -17,10 +20,29 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// maxAuditRecords is the number of records kept in the audit trail
+const maxAuditRecords = 100
+
+// Actions recorded in the audit trail
+const (
+	auditDecrypted = "decrypted"
+	auditRotated   = "rotated"
+)
+
 var (
 	secretCache map[string]string
 	// list of handles and where they were found
 	secretOrigin map[string]common.StringSet
+	// list of handles and the configuration paths using them
+	secretPaths map[string]common.StringSet
+	// last uses and rotations of the handles, oldest first
+	auditTrail  []AuditRecord
+	lastRefresh time.Time
+	// secretLock protects the cache and the audit trail from concurrent
+	// decryptions and refreshes
+	secretLock sync.Mutex
+
+	refreshCallbacks []RefreshCallback
 
 	secretBackendCommand       string
 	secretBackendArguments     []string
@@ -31,6 +53,7 @@ var (
 func init() {
 	secretCache = make(map[string]string)
 	secretOrigin = make(map[string]common.StringSet)
+	secretPaths = make(map[string]common.StringSet)
 }
 
 // Init initializes the command and other options of the secrets package. Since
@@ -43,23 +66,26 @@ func Init(command string, arguments []string, timeout int, maxSize int) {
 	secretBackendOutputMaxSize = maxSize
 }
 
-type walkerCallback func(string) (string, error)
+// walkerCallback is called with the path of the string in the yaml, as
+// returned by formatPath, and the string itself
+type walkerCallback func(path []string, value string) (string, error)
 
-func walkSlice(data []interface{}, callback walkerCallback) error {
+func walkSlice(data []interface{}, path []string, callback walkerCallback) error {
 	for idx, k := range data {
+		itemPath := append(path[:len(path):len(path)], fmt.Sprintf("[%d]", idx))
 		switch v := k.(type) {
 		case string:
-			newValue, err := callback(v)
+			newValue, err := callback(itemPath, v)
 			if err != nil {
 				return err
 			}
 			data[idx] = newValue
 		case map[interface{}]interface{}:
-			if err := walkHash(v, callback); err != nil {
+			if err := walkHash(v, itemPath, callback); err != nil {
 				return err
 			}
 		case []interface{}:
-			if err := walkSlice(v, callback); err != nil {
+			if err := walkSlice(v, itemPath, callback); err != nil {
 				return err
 			}
 		}
@@ -67,21 +93,22 @@ func walkSlice(data []interface{}, callback walkerCallback) error {
 	return nil
 }
 
-func walkHash(data map[interface{}]interface{}, callback walkerCallback) error {
+func walkHash(data map[interface{}]interface{}, path []string, callback walkerCallback) error {
 	for k := range data {
+		itemPath := append(path[:len(path):len(path)], fmt.Sprint(k))
 		switch v := data[k].(type) {
 		case string:
-			newValue, err := callback(v)
+			newValue, err := callback(itemPath, v)
 			if err != nil {
 				return err
 			}
 			data[k] = newValue
 		case map[interface{}]interface{}:
-			if err := walkHash(v, callback); err != nil {
+			if err := walkHash(v, itemPath, callback); err != nil {
 				return err
 			}
 		case []interface{}:
-			if err := walkSlice(v, callback); err != nil {
+			if err := walkSlice(v, itemPath, callback); err != nil {
 				return err
 			}
 		}
@@ -94,19 +121,32 @@ func walkHash(data map[interface{}]interface{}, callback walkerCallback) error {
 func walk(data *interface{}, callback walkerCallback) error {
 	switch v := (*data).(type) {
 	case string:
-		newValue, err := callback(v)
+		newValue, err := callback(nil, v)
 		if err != nil {
 			return err
 		}
 		*data = newValue
 	case map[interface{}]interface{}:
-		return walkHash(v, callback)
+		return walkHash(v, nil, callback)
 	case []interface{}:
-		return walkSlice(v, callback)
+		return walkSlice(v, nil, callback)
 	}
 	return nil
 }
 
+// formatPath renders the path of a string found by walk, like
+// "instances[0].password"
+func formatPath(path []string) string {
+	var b strings.Builder
+	for idx, p := range path {
+		if idx > 0 && !strings.HasPrefix(p, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
 func isEnc(str string) (bool, string) {
 	// trimming space and tabs
 	str = strings.Trim(str, " 	")
@@ -122,10 +162,25 @@ var secretFetcher = fetchSecret
 // Decrypt replaces all encrypted secrets in data by executing
 // "secret_backend_command" once if all secrets aren't present in the cache.
 func Decrypt(data []byte, origin string) ([]byte, error) {
+	return decrypt(data, origin, true)
+}
+
+// Refresh replaces all encrypted secrets in data by executing
+// "secret_backend_command", ignoring the cache, so secrets rotated since they
+// were first decrypted are fetched again. The cache is updated with the new
+// values.
+func Refresh(data []byte, origin string) ([]byte, error) {
+	return decrypt(data, origin, false)
+}
+
+func decrypt(data []byte, origin string, useCache bool) ([]byte, error) {
 	if data == nil || secretBackendCommand == "" {
 		return data, nil
 	}
 
+	secretLock.Lock()
+	defer secretLock.Unlock()
+
 	var config interface{}
 	err := yaml.Unmarshal(data, &config)
 	if err != nil {
@@ -134,12 +189,14 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 
 	// First we collect all new handles in the config
 	newHandles := []string{}
+	usedPaths := map[string][]string{}
 	haveSecret := false
-	err = walk(&config, func(str string) (string, error) {
+	err = walk(&config, func(path []string, str string) (string, error) {
 		if ok, handle := isEnc(str); ok {
 			haveSecret = true
+			usedPaths[handle] = append(usedPaths[handle], formatPath(path))
 			// Check if we already know this secret
-			if secret, ok := secretCache[handle]; ok {
+			if secret, ok := secretCache[handle]; ok && useCache {
 				log.Debugf("Secret '%s' was retrieved from cache", handle)
 				// keep track of place where a handle was found
 				secretOrigin[handle].Add(origin)
@@ -166,7 +223,7 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 		}
 
 		// Replace all new encrypted secrets in the config
-		err = walk(&config, func(str string) (string, error) {
+		err = walk(&config, func(path []string, str string) (string, error) {
 			if ok, handle := isEnc(str); ok {
 				if secret, ok := secrets[handle]; ok {
 					log.Debugf("Secret '%s' was retrieved from executable", handle)
@@ -187,9 +244,46 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not Marshal config after replacing encrypted secrets: %s", err)
 	}
+	recordUsedPaths(usedPaths, origin)
 	return finalConfig, nil
 }
 
+// recordUsedPaths keeps track of the configuration paths where the handles
+// were found, and adds them to the audit trail. Callers must hold secretLock.
+func recordUsedPaths(usedPaths map[string][]string, origin string) {
+	now := time.Now()
+	handles := make([]string, 0, len(usedPaths))
+	for handle := range usedPaths {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+
+	for _, handle := range handles {
+		if _, found := secretPaths[handle]; !found {
+			secretPaths[handle] = common.NewStringSet()
+		}
+		for _, path := range usedPaths[handle] {
+			secretPaths[handle].Add(fmt.Sprintf("%s: %s", origin, path))
+			recordAudit(AuditRecord{
+				Time:   now,
+				Action: auditDecrypted,
+				Handle: handle,
+				Origin: origin,
+				Path:   path,
+			})
+		}
+	}
+}
+
+// recordAudit appends a record to the audit trail, dropping the oldest one
+// when it is full. Callers must hold secretLock.
+func recordAudit(record AuditRecord) {
+	if len(auditTrail) >= maxAuditRecords {
+		auditTrail = auditTrail[1:]
+	}
+	auditTrail = append(auditTrail, record)
+}
+
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
 	if secretBackendCommand == "" {
@@ -198,9 +292,90 @@ func GetDebugInfo() (*SecretInfo, error) {
 	info := &SecretInfo{ExecutablePath: secretBackendCommand}
 	info.populateRights()
 
+	secretLock.Lock()
+	defer secretLock.Unlock()
+
 	info.SecretsHandles = map[string][]string{}
 	for handle, originNames := range secretOrigin {
 		info.SecretsHandles[handle] = originNames.GetAll()
 	}
+	info.SecretsPaths = map[string][]string{}
+	for handle, paths := range secretPaths {
+		info.SecretsPaths[handle] = paths.GetAll()
+		sort.Strings(info.SecretsPaths[handle])
+	}
+	info.LastRefresh = lastRefresh
+	info.AuditTrail = append([]AuditRecord{}, auditTrail...)
 	return info, nil
 }
+
+// RefreshCallback is notified of the origins, the names of the
+// configurations, using a secret whose value changed during a refresh
+type RefreshCallback func(origins []string)
+
+// RegisterRefreshCallback registers a callback notified by RefreshAll when
+// secrets change, so the components using them can reload their
+// configuration.
+func RegisterRefreshCallback(callback RefreshCallback) {
+	secretLock.Lock()
+	defer secretLock.Unlock()
+	refreshCallbacks = append(refreshCallbacks, callback)
+}
+
+// RefreshAll fetches again all the secrets decrypted so far, with a single
+// execution of "secret_backend_command", and updates the cache with their
+// new values. The registered callbacks are notified of the origins using the
+// secrets that changed. It returns the handles of those secrets.
+func RefreshAll() ([]string, error) {
+	if secretBackendCommand == "" {
+		return nil, fmt.Errorf("No secret_backend_command set: secrets feature is not enabled")
+	}
+
+	secretLock.Lock()
+	handles := make([]string, 0, len(secretCache))
+	for handle := range secretCache {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+
+	values := map[string]string{}
+	if len(handles) > 0 {
+		var err error
+		values, err = fetchSecretValues(handles)
+		if err != nil {
+			secretLock.Unlock()
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	changed := []string{}
+	origins := common.NewStringSet()
+	for _, handle := range handles {
+		if values[handle] == secretCache[handle] {
+			continue
+		}
+		secretCache[handle] = values[handle]
+		changed = append(changed, handle)
+		for origin := range secretOrigin[handle] {
+			origins.Add(origin)
+		}
+		recordAudit(AuditRecord{Time: now, Action: auditRotated, Handle: handle})
+	}
+	lastRefresh = now
+	callbacks := append([]RefreshCallback{}, refreshCallbacks...)
+	secretLock.Unlock()
+
+	if len(changed) == 0 {
+		log.Debugf("Refreshed %d secrets, none changed", len(handles))
+		return changed, nil
+	}
+
+	log.Infof("Refreshed %d secrets, %d changed: %s", len(handles), len(changed), strings.Join(changed, ", "))
+	changedOrigins := origins.GetAll()
+	sort.Strings(changedOrigins)
+	for _, callback := range callbacks {
+		callback(changedOrigins)
+	}
+	return changed, nil
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/common"
 	"github.com/stretchr/testify/assert"
@@ -102,7 +103,7 @@ func TestWalkerError(t *testing.T) {
 	err := yaml.Unmarshal(testYamlHash, &config)
 	require.Nil(t, err)
 
-	err = walk(&config, func(str string) (string, error) {
+	err = walk(&config, func(path []string, str string) (string, error) {
 		return "", fmt.Errorf("some error")
 	})
 	assert.NotNil(t, err)
@@ -114,7 +115,7 @@ func TestWalkerSimple(t *testing.T) {
 	require.Nil(t, err)
 
 	stringsCollected := []string{}
-	err = walk(&config, func(str string) (string, error) {
+	err = walk(&config, func(path []string, str string) (string, error) {
 		stringsCollected = append(stringsCollected, str)
 		return str + "_verified", nil
 	})
@@ -133,7 +134,7 @@ func TestWalkerComplex(t *testing.T) {
 	require.Nil(t, err)
 
 	stringsCollected := []string{}
-	err = walk(&config, func(str string) (string, error) {
+	err = walk(&config, func(path []string, str string) (string, error) {
 		stringsCollected = append(stringsCollected, str)
 		return str + "_verified", nil
 	})
@@ -261,13 +262,61 @@ func TestDecryptSecretFullCache(t *testing.T) {
 	assert.Equal(t, testConfDecrypted, newConf)
 }
 
+func TestRefreshSecretFullCache(t *testing.T) {
+	secretBackendCommand = "some_command"
+	defer func() { secretBackendCommand = "" }()
+
+	secretCache["pass1"] = "password1"
+	secretCache["pass2"] = "password2"
+	secretOrigin["pass1"] = common.NewStringSet("previous_test")
+	secretOrigin["pass2"] = common.NewStringSet("previous_test")
+	defer func() {
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+		secretFetcher = fetchSecret
+	}()
+
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		sort.Strings(secrets)
+		assert.Equal(t, []string{
+			"pass1",
+			"pass2",
+		}, secrets)
+
+		secretCache["pass1"] = "rotated1"
+		secretCache["pass2"] = "rotated2"
+		return map[string]string{
+			"pass1": "rotated1",
+			"pass2": "rotated2",
+		}, nil
+	}
+
+	newConf, err := Refresh(testConf, "test")
+	require.Nil(t, err)
+	assert.Contains(t, string(newConf), "password: rotated1")
+	assert.Contains(t, string(newConf), "password: rotated2")
+
+	// the cache now holds the new values
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		require.Fail(t, "Secret Cache was not used properly")
+		return nil, nil
+	}
+	newConf, err = Decrypt(testConf, "test")
+	require.Nil(t, err)
+	assert.Contains(t, string(newConf), "password: rotated1")
+}
+
 func TestDebugInfo(t *testing.T) {
 	secretBackendCommand = "some_command"
+	secretPaths = map[string]common.StringSet{}
+	auditTrail = nil
 
 	defer func() {
 		secretBackendCommand = ""
 		secretCache = map[string]string{}
 		secretOrigin = map[string]common.StringSet{}
+		secretPaths = map[string]common.StringSet{}
+		auditTrail = nil
 		runCommand = execCommand
 	}()
 
@@ -299,4 +348,103 @@ func TestDebugInfo(t *testing.T) {
 		"pass2": {"test", "test2"},
 		"pass3": {"test2"},
 	}, handles)
+
+	assert.Equal(t, map[string][]string{
+		"pass1": {"test: instances[0].password"},
+		"pass2": {"test2: instances[1].password", "test: instances[1].password"},
+		"pass3": {"test2: instances[0].password"},
+	}, info.SecretsPaths)
+
+	require.Len(t, info.AuditTrail, 4)
+	assert.Equal(t, AuditRecord{Time: info.AuditTrail[0].Time, Action: auditDecrypted, Handle: "pass1", Origin: "test", Path: "instances[0].password"}, info.AuditTrail[0])
+	assert.Equal(t, "pass3", info.AuditTrail[3].Handle)
+	assert.Equal(t, "test2", info.AuditTrail[3].Origin)
+	assert.True(t, info.LastRefresh.IsZero())
+}
+
+func TestWalkerPaths(t *testing.T) {
+	var config interface{}
+	err := yaml.Unmarshal(testYamlHash, &config)
+	require.Nil(t, err)
+
+	paths := []string{}
+	err = walk(&config, func(path []string, str string) (string, error) {
+		paths = append(paths, formatPath(path))
+		return str, nil
+	})
+	require.Nil(t, err)
+
+	sort.Strings(paths)
+	assert.Equal(t, []string{
+		"hash.a",
+		"hash.b",
+		"hash.slice[0]",
+		"hash.slice[1]",
+		"slice[0]",
+		"slice[1][0]",
+		"slice[1][1]",
+	}, paths)
+}
+
+func TestAuditTrailIsBounded(t *testing.T) {
+	defer func() { auditTrail = nil }()
+
+	for i := 0; i < maxAuditRecords+10; i++ {
+		recordAudit(AuditRecord{Handle: fmt.Sprintf("pass%d", i)})
+	}
+	require.Len(t, auditTrail, maxAuditRecords)
+	assert.Equal(t, "pass10", auditTrail[0].Handle)
+	assert.Equal(t, fmt.Sprintf("pass%d", maxAuditRecords+9), auditTrail[maxAuditRecords-1].Handle)
+}
+
+func TestRefreshAll(t *testing.T) {
+	secretBackendCommand = "some_command"
+	defer func() {
+		secretBackendCommand = ""
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+		auditTrail = nil
+		lastRefresh = time.Time{}
+		refreshCallbacks = nil
+		runCommand = execCommand
+	}()
+
+	secretCache["pass1"] = "password1"
+	secretCache["pass2"] = "password2"
+	secretOrigin["pass1"] = common.NewStringSet("test")
+	secretOrigin["pass2"] = common.NewStringSet("test", "test2")
+
+	runCommand = func(payload string) ([]byte, error) {
+		assert.Equal(t, `{"secrets":["pass1","pass2"],"version":"1.0"}`, payload)
+		return []byte(`{"pass1":{"value":"password1"},"pass2":{"value":"rotated2"}}`), nil
+	}
+
+	var notified [][]string
+	RegisterRefreshCallback(func(origins []string) {
+		notified = append(notified, origins)
+	})
+
+	changed, err := RefreshAll()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"pass2"}, changed)
+	assert.Equal(t, "rotated2", secretCache["pass2"])
+	assert.Equal(t, [][]string{{"test", "test2"}}, notified)
+	assert.False(t, lastRefresh.IsZero())
+	require.Len(t, auditTrail, 1)
+	assert.Equal(t, auditRotated, auditTrail[0].Action)
+	assert.Equal(t, "pass2", auditTrail[0].Handle)
+
+	// nothing changed: the callbacks are not notified
+	changed, err = RefreshAll()
+	require.Nil(t, err)
+	assert.Empty(t, changed)
+	assert.Len(t, notified, 1)
+
+	// the values are kept when the backend fails
+	runCommand = func(string) ([]byte, error) {
+		return nil, fmt.Errorf("some error")
+	}
+	_, err = RefreshAll()
+	assert.NotNil(t, err)
+	assert.Equal(t, "rotated2", secretCache["pass2"])
 }
@@ -98,10 +98,20 @@ type Serializer struct {
 	enableSketches       bool
 	enableJSONToV1Intake bool
 	enableJSONStream     bool
+
+	// some endpoints only support the v1 intake, the series are sent to them
+	// in JSON when the others receive them in protobuf
+	hasV1OnlyEndpoints bool
 }
 
 // NewSerializer returns a new Serializer initialized
 func NewSerializer(forwarder forwarder.Forwarder) *Serializer {
+	err := compression.Configure(config.Datadog.GetString("compression_kind"), config.Datadog.GetInt("compression_level"))
+	if err != nil {
+		log.Errorf("Invalid compression configuration, using %s: %s", compression.Kind(), err)
+	}
+	initExtraHeaders()
+
 	s := &Serializer{
 		Forwarder:            forwarder,
 		seriesPayloadBuilder: jsonstream.NewPayloadBuilder(),
@@ -111,6 +121,14 @@ func NewSerializer(forwarder forwarder.Forwarder) *Serializer {
 		enableSketches:       config.Datadog.GetBool("enable_payloads.sketches"),
 		enableJSONToV1Intake: config.Datadog.GetBool("enable_payloads.json_to_v1_intake"),
 		enableJSONStream:     jsonstream.Available && config.Datadog.GetBool("enable_stream_payload_serialization"),
+		hasV1OnlyEndpoints:   len(config.Datadog.GetStringSlice("v1_only_endpoints")) > 0,
+	}
+
+	// the stream serialization compresses the payloads with zlib itself, it's
+	// disabled for the other compression kinds
+	if s.enableJSONStream && compression.Kind() != compression.ZlibKind {
+		log.Infof("The stream payload serialization is disabled when the payloads are compressed with %s", compression.Kind())
+		s.enableJSONStream = false
 	}
 
 	if !s.enableEvents {
@@ -182,7 +200,7 @@ func (s *Serializer) SendEvents(e marshaler.Marshaler) error {
 	}
 
 	if useV1API {
-		return s.Forwarder.SubmitV1Intake(eventPayloads, extraHeaders)
+		return s.Forwarder.SubmitV1Intake(eventPayloads, config.EventsEndpointDataType, extraHeaders)
 	}
 	return s.Forwarder.SubmitEvents(eventPayloads, extraHeaders)
 }
@@ -217,16 +235,7 @@ func (s *Serializer) SendSeries(series marshaler.StreamJSONMarshaler) error {
 
 	useV1API := !config.Datadog.GetBool("use_v2_api.series")
 
-	var seriesPayloads forwarder.Payloads
-	var extraHeaders http.Header
-	var err error
-
-	if useV1API && s.enableJSONStream {
-		seriesPayloads, extraHeaders, err = s.serializeStreamablePayload(series)
-	} else {
-		seriesPayloads, extraHeaders, err = s.serializePayload(series, true, useV1API)
-	}
-
+	seriesPayloads, extraHeaders, err := s.serializeSeries(series, useV1API)
 	if err != nil {
 		return fmt.Errorf("dropping series payload: %s", err)
 	}
@@ -234,7 +243,27 @@ func (s *Serializer) SendSeries(series marshaler.StreamJSONMarshaler) error {
 	if useV1API {
 		return s.Forwarder.SubmitV1Series(seriesPayloads, extraHeaders)
 	}
-	return s.Forwarder.SubmitSeries(seriesPayloads, extraHeaders)
+	if err := s.Forwarder.SubmitSeries(seriesPayloads, extraHeaders); err != nil {
+		return err
+	}
+	if !s.hasV1OnlyEndpoints {
+		return nil
+	}
+
+	// the forwarder only sends the JSON series to the endpoints not
+	// supporting the v2 intake
+	seriesPayloads, extraHeaders, err = s.serializeSeries(series, true)
+	if err != nil {
+		return fmt.Errorf("dropping JSON series payload: %s", err)
+	}
+	return s.Forwarder.SubmitV1Series(seriesPayloads, extraHeaders)
+}
+
+func (s *Serializer) serializeSeries(series marshaler.StreamJSONMarshaler, useV1API bool) (forwarder.Payloads, http.Header, error) {
+	if useV1API && s.enableJSONStream {
+		return s.serializeStreamablePayload(series)
+	}
+	return s.serializePayload(series, true, useV1API)
 }
 
 // SendSketch serializes a list of SketSeriesList and sends the payload to the forwarder
@@ -245,7 +274,7 @@ func (s *Serializer) SendSketch(sketches marshaler.Marshaler) error {
 	}
 
 	compress := false // TODO: enable compression once the backend supports it on this endpoint
-	useV1API := false // Sketches only have a v2 endpoint
+	useV1API := false // Sketches only have a v2 endpoint, the forwarder skips the v1 only endpoints
 	splitSketches, extraHeaders, err := s.serializePayload(sketches, compress, useV1API)
 	if err != nil {
 		return fmt.Errorf("dropping sketch payload: %s", err)
@@ -267,7 +296,7 @@ func (s *Serializer) SendMetadata(m marshaler.Marshaler) error {
 		return fmt.Errorf("metadata payload was too big to send (%d bytes compressed), metadata payloads cannot be split", len(compressedPayload))
 	}
 
-	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&compressedPayload}, jsonExtraHeadersWithCompression); err != nil {
+	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&compressedPayload}, "", jsonExtraHeadersWithCompression); err != nil {
 		return err
 	}
 
@@ -287,7 +316,7 @@ func (s *Serializer) SendJSONToV1Intake(data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("could not serialize v1 payload: %s", err)
 	}
-	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&payload}, jsonExtraHeaders); err != nil {
+	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&payload}, "", jsonExtraHeaders); err != nil {
 		return err
 	}
 
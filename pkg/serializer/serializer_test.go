@@ -80,6 +80,29 @@ func TestInitExtraHeadersWithCompression(t *testing.T) {
 	assert.Equal(t, expected, protobufExtraHeadersWithCompression)
 }
 
+func TestNewSerializerCompressionKind(t *testing.T) {
+	initialKind := compression.Kind()
+	defer func() {
+		compression.Configure(initialKind, compression.DefaultLevel)
+		resetContentEncoding()
+	}()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("compression_kind", compression.NoneKind)
+	defer mockConfig.Set("compression_kind", "")
+
+	s := NewSerializer(nil)
+	assert.Equal(t, compression.NoneKind, compression.Kind())
+	assert.False(t, s.enableJSONStream)
+	assert.Empty(t, jsonExtraHeadersWithCompression.Get("Content-Encoding"))
+	assert.Empty(t, protobufExtraHeadersWithCompression.Get("Content-Encoding"))
+
+	// unavailable kinds keep the current one
+	mockConfig.Set("compression_kind", "lzma")
+	NewSerializer(nil)
+	assert.Equal(t, compression.NoneKind, compression.Kind())
+}
+
 func TestAgentPayloadVersion(t *testing.T) {
 	assert.NotEmpty(t, AgentPayloadVersion, "AgentPayloadVersion is empty, indicates that the package was not built correctly")
 }
@@ -140,7 +163,7 @@ func mkPayloads(payload []byte, compress bool) (forwarder.Payloads, error) {
 
 func TestSendV1Events(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
-	f.On("SubmitV1Intake", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitV1Intake", jsonPayloads, config.EventsEndpointDataType, jsonExtraHeadersWithCompression).Return(nil).Times(1)
 
 	s := NewSerializer(f)
 
@@ -248,6 +271,27 @@ func TestSendSeries(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestSendSeriesV1OnlyEndpoints(t *testing.T) {
+	mockConfig := config.Mock()
+
+	f := &forwarder.MockedForwarder{}
+	f.On("SubmitSeries", protobufPayloads, protobufExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitV1Series", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	mockConfig.Set("use_v2_api.series", true)
+	defer mockConfig.Set("use_v2_api.series", nil)
+	mockConfig.Set("v1_only_endpoints", []string{"https://custom.intake"})
+	defer mockConfig.Set("v1_only_endpoints", nil)
+	mockConfig.Set("enable_stream_payload_serialization", false)
+	defer mockConfig.Set("enable_stream_payload_serialization", nil)
+
+	s := NewSerializer(f)
+
+	payload := &testPayload{}
+	err := s.SendSeries(payload)
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+}
+
 func TestSendSketch(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	payloads, _ := mkPayloads(protobufString, false)
@@ -267,7 +311,7 @@ func TestSendSketch(t *testing.T) {
 
 func TestSendMetadata(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
-	f.On("SubmitV1Intake", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitV1Intake", jsonPayloads, "", jsonExtraHeadersWithCompression).Return(nil).Times(1)
 
 	s := NewSerializer(f)
 
@@ -276,7 +320,7 @@ func TestSendMetadata(t *testing.T) {
 	require.Nil(t, err)
 	f.AssertExpectations(t)
 
-	f.On("SubmitV1Intake", jsonPayloads, jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
+	f.On("SubmitV1Intake", jsonPayloads, "", jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
 	err = s.SendMetadata(payload)
 	require.NotNil(t, err)
 	f.AssertExpectations(t)
@@ -290,7 +334,7 @@ func TestSendJSONToV1Intake(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	payload := []byte("\"test\"")
 	payloads, _ := mkPayloads(payload, false)
-	f.On("SubmitV1Intake", payloads, jsonExtraHeaders).Return(nil).Times(1)
+	f.On("SubmitV1Intake", payloads, "", jsonExtraHeaders).Return(nil).Times(1)
 
 	s := NewSerializer(f)
 
@@ -298,7 +342,7 @@ func TestSendJSONToV1Intake(t *testing.T) {
 	require.Nil(t, err)
 	f.AssertExpectations(t)
 
-	f.On("SubmitV1Intake", payloads, jsonExtraHeaders).Return(fmt.Errorf("some error")).Times(1)
+	f.On("SubmitV1Intake", payloads, "", jsonExtraHeaders).Return(fmt.Errorf("some error")).Times(1)
 	err = s.SendJSONToV1Intake("test")
 	require.NotNil(t, err)
 	f.AssertExpectations(t)
@@ -346,7 +390,7 @@ func TestSendWithDisabledKind(t *testing.T) {
 	f.AssertNotCalled(t, "SubmitSketchSeries")
 
 	// We never disable metadata
-	f.On("SubmitV1Intake", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitV1Intake", jsonPayloads, "", jsonExtraHeadersWithCompression).Return(nil).Times(1)
 	s.SendMetadata(payload)
 	f.AssertNumberOfCalls(t, "SubmitV1Intake", 1) // called once for the metadata
 }
@@ -6,6 +6,7 @@
 package split
 
 import (
+	"errors"
 	"expvar"
 
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
@@ -15,8 +16,12 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-// the backend accepts payloads up to 3MB, but being conservative is okay
-var maxPayloadSize = 2 * 1024 * 1024
+// the backend accepts payloads up to 3MB compressed and 50MB uncompressed,
+// but being conservative is okay
+var (
+	maxPayloadSize             = 2 * 1024 * 1024
+	maxUncompressedPayloadSize = 45 * 1024 * 1024
+)
 
 // MarshalType is the type of marshaler to use
 type MarshalType int
@@ -33,6 +38,8 @@ var (
 	splitterTooBig       = expvar.Int{}
 	splitterTotalLoops   = expvar.Int{}
 	splitterPayloadDrops = expvar.Int{}
+	splitterSplits       = expvar.Int{}
+	splitterChunks       = expvar.Int{}
 )
 
 func init() {
@@ -40,7 +47,8 @@ func init() {
 	splitterExpvars.Set("TooBig", &splitterTooBig)
 	splitterExpvars.Set("TotalLoops", &splitterTotalLoops)
 	splitterExpvars.Set("PayloadDrops", &splitterPayloadDrops)
-
+	splitterExpvars.Set("Splits", &splitterSplits)
+	splitterExpvars.Set("Chunks", &splitterChunks)
 }
 
 // CheckSizeAndSerialize Check the size of a payload and marshall it (optionally compress it)
@@ -50,7 +58,7 @@ func CheckSizeAndSerialize(m marshaler.Marshaler, compress bool, mType MarshalTy
 	if err != nil {
 		return false, nil, nil, err
 	}
-	return checkSize(compressedPayload), compressedPayload, payload, nil
+	return checkSize(compressedPayload, payload), compressedPayload, payload, nil
 }
 
 // Payloads serializes a metadata payload and sends it to the forwarder
@@ -82,24 +90,22 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 		for _, toSplit := range tempSlice {
 			var e error
 			// we have to do this every time to get the proper payload
-			payload, compressedPayload, e := serializeMarshaller(toSplit, compress, mType)
+			compressedPayload, payload, e := serializeMarshaller(toSplit, compress, mType)
 			if e != nil {
 				return smallEnoughPayloads, e
 			}
-			payloadSize := len(payload)
-			compressedSize := len(compressedPayload)
-			// Attempt to account for the compression when estimating the number of chunks that will be needed
-			// This is the same function used in dd-agent
-			compressionRatio := float64(payloadSize) / float64(compressedSize)
-			numChunks := compressedSize/maxPayloadSize + 1 + int(compressionRatio/2)
+			numChunks := chunksCount(len(compressedPayload), len(payload))
 			log.Debugf("split the payload into into %d chunks", numChunks)
 			chunks, err := toSplit.SplitPayload(numChunks)
-			log.Debugf("payload was split into %d chunks", len(chunks))
 			if err != nil {
-				log.Warnf("Some payloads could not be split, dropping them")
+				// the payload can't be split on item boundaries, only
+				// drop this part and keep the others
+				log.Warnf("A payload could not be split, dropping it: %s", err)
 				splitterPayloadDrops.Add(1)
-				return smallEnoughPayloads, err
+				continue
 			}
+			log.Debugf("payload was split into %d chunks", len(chunks))
+			splitterSplits.Add(1)
 			// after the payload has been split, loop through the chunks
 			for _, chunk := range chunks {
 				// serialize the payload
@@ -111,6 +117,7 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 				if smallEnough {
 					// if the payload is small enough, return it straight away
 					smallEnoughPayloads = append(smallEnoughPayloads, &payload)
+					splitterChunks.Add(1)
 					log.Debugf("chunk was small enough: %v, smallEnoughPayloads are of length: %v", len(payload), len(smallEnoughPayloads))
 				} else {
 					// if it is not, append it to the list of payloads
@@ -129,12 +136,29 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 	}
 	if len(marshallers) != 0 {
 		log.Warnf("Some payloads could not be split, dropping them")
-		splitterPayloadDrops.Add(1)
+		splitterPayloadDrops.Add(int64(len(marshallers)))
+	}
+	if len(smallEnoughPayloads) == 0 {
+		return smallEnoughPayloads, errors.New("the payload could not be split into small enough chunks")
 	}
 
 	return smallEnoughPayloads, nil
 }
 
+// chunksCount estimates the number of chunks a payload should be split into
+// to fit in the size limits
+func chunksCount(compressedSize, payloadSize int) int {
+	// Attempt to account for the compression when estimating the number of chunks that will be needed
+	// This is the same function used in dd-agent
+	compressionRatio := float64(payloadSize) / float64(compressedSize)
+	numChunks := compressedSize/maxPayloadSize + 1 + int(compressionRatio/2)
+	// the uncompressed size may be the limiting one for very repetitive payloads
+	if uncompressedChunks := payloadSize/maxUncompressedPayloadSize + 1; uncompressedChunks > numChunks {
+		numChunks = uncompressedChunks
+	}
+	return numChunks
+}
+
 // serializeMarshaller serializes the marshaller and returns both the compressed and uncompressed payloads
 func serializeMarshaller(m marshaler.Marshaler, compress bool, mType MarshalType) ([]byte, []byte, error) {
 	var payload []byte
@@ -154,8 +178,8 @@ func serializeMarshaller(m marshaler.Marshaler, compress bool, mType MarshalType
 	return compressedPayload, payload, nil
 }
 
-func checkSize(payload []byte) bool {
-	if len(payload) >= maxPayloadSize {
+func checkSize(compressedPayload, payload []byte) bool {
+	if len(compressedPayload) >= maxPayloadSize || len(payload) >= maxUncompressedPayloadSize {
 		return false
 	}
 	return true
@@ -176,3 +200,8 @@ func marshal(m marshaler.Marshaler, mType MarshalType) ([]byte, error) {
 func GetPayloadDrops() int64 {
 	return splitterPayloadDrops.Value()
 }
+
+// GetPayloadSplits returns the number of times we had to split a payload because it was too big.
+func GetPayloadSplits() int64 {
+	return splitterSplits.Value()
+}
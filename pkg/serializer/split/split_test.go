@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestSplitPayloadsSeries(t *testing.T) {
@@ -173,3 +175,119 @@ func TestSplitPayloadsServiceChecks(t *testing.T) {
 	newLength := len(testServiceChecks)
 	require.Equal(t, originalLength, newLength)
 }
+
+func TestSplitPayloadsSingleMetric(t *testing.T) {
+	testSeries := metrics.Series{}
+	for i := 0; i < 30000; i++ {
+		point := metrics.Serie{
+			Points: []metrics.Point{
+				{Ts: 12345.0, Value: float64(21.21)},
+				{Ts: 67890.0, Value: float64(12.12)},
+				{Ts: 2222.0, Value: float64(22.12)},
+				{Ts: 333.0, Value: float64(32.12)},
+				{Ts: 444444.0, Value: float64(42.12)},
+				{Ts: 882787.0, Value: float64(52.12)},
+				{Ts: 99990.0, Value: float64(62.12)},
+				{Ts: 121212.0, Value: float64(72.12)},
+				{Ts: 222227.0, Value: float64(82.12)},
+				{Ts: 808080.0, Value: float64(92.12)},
+				{Ts: 9090.0, Value: float64(13.12)},
+			},
+			MType:    metrics.APIGaugeType,
+			Name:     "test.metrics",
+			Interval: 1,
+			Host:     "localHost",
+			Tags:     []string{"tag1", fmt.Sprintf("tag2:%d", i)},
+		}
+		testSeries = append(testSeries, &point)
+	}
+
+	splits := GetPayloadSplits()
+	payloads, err := Payloads(testSeries, false, MarshalJSON)
+	require.Nil(t, err)
+	require.True(t, len(payloads) > 1)
+	assert.True(t, GetPayloadSplits() > splits)
+
+	unrolledSeries := metrics.Series{}
+	for _, payload := range payloads {
+		assert.True(t, len(*payload) < maxPayloadSize)
+		var s = map[string]metrics.Series{}
+		err = json.Unmarshal(*payload, &s)
+		require.Nil(t, err)
+		unrolledSeries = append(unrolledSeries, s["series"]...)
+	}
+	require.Equal(t, len(testSeries), len(unrolledSeries))
+}
+
+func TestSplitPayloadsUncompressedSize(t *testing.T) {
+	defer func(size int) { maxUncompressedPayloadSize = size }(maxUncompressedPayloadSize)
+	maxUncompressedPayloadSize = 100 * 1024
+
+	testSeries := metrics.Series{}
+	for i := 0; i < 10000; i++ {
+		testSeries = append(testSeries, &metrics.Serie{
+			Points: []metrics.Point{{Ts: 12345.0, Value: float64(21.21)}},
+			MType:  metrics.APIGaugeType,
+			Name:   fmt.Sprintf("test.metrics%d", i),
+			Host:   "localHost",
+			Tags:   []string{"tag1", "tag2:yes"},
+		})
+	}
+
+	// the compressed payload is small enough but not the uncompressed one
+	payloads, err := Payloads(testSeries, true, MarshalJSON)
+	require.Nil(t, err)
+	require.True(t, len(payloads) > 1)
+
+	seriesCount := 0
+	for _, payload := range payloads {
+		decompressed, err := compression.Decompress(nil, *payload)
+		require.Nil(t, err)
+		assert.True(t, len(decompressed) < maxUncompressedPayloadSize)
+		var s = map[string]metrics.Series{}
+		err = json.Unmarshal(decompressed, &s)
+		require.Nil(t, err)
+		seriesCount += len(s["series"])
+	}
+	assert.Equal(t, len(testSeries), seriesCount)
+}
+
+func TestSplitPayloadsDropsOnlyUnsplittableItems(t *testing.T) {
+	defer func(size int) { maxPayloadSize = size }(maxPayloadSize)
+	maxPayloadSize = 2048
+
+	bigSerie := &metrics.Serie{
+		MType: metrics.APIGaugeType,
+		Name:  "test.big",
+		Host:  "localHost",
+	}
+	for i := 0; i < 200; i++ {
+		bigSerie.Points = append(bigSerie.Points, metrics.Point{Ts: float64(i), Value: 21.21})
+	}
+	testSeries := metrics.Series{bigSerie}
+	for i := 0; i < 50; i++ {
+		testSeries = append(testSeries, &metrics.Serie{
+			Points: []metrics.Point{{Ts: 12345.0, Value: float64(21.21)}},
+			MType:  metrics.APIGaugeType,
+			Name:   fmt.Sprintf("test.metrics%d", i),
+			Host:   "localHost",
+		})
+	}
+
+	drops := GetPayloadDrops()
+	payloads, err := Payloads(testSeries, false, MarshalJSON)
+	require.Nil(t, err)
+	assert.Equal(t, drops+1, GetPayloadDrops())
+
+	names := map[string]bool{}
+	for _, payload := range payloads {
+		var s = map[string]metrics.Series{}
+		err = json.Unmarshal(*payload, &s)
+		require.Nil(t, err)
+		for _, serie := range s["series"] {
+			names[serie.Name] = true
+		}
+	}
+	assert.Len(t, names, 50)
+	assert.False(t, names["test.big"])
+}
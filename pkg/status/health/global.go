@@ -12,28 +12,74 @@ import (
 
 var globalCatalog = newCatalog()
 
-// Register a component with the default 30 seconds timeout, returns a token
-func Register(name string) *Handle {
+// RegisterLiveness registers a component for the liveness and readiness
+// checks with the default 30 seconds timeout, returns a token. A component
+// unhealthy for the liveness check is expected to be fixed by restarting
+// the agent.
+func RegisterLiveness(name string) *Handle {
 	return globalCatalog.register(name)
 }
 
+// RegisterReadiness registers a component only for the readiness check with
+// the default 30 seconds timeout, returns a token. It is meant for the
+// components depending on external systems, that restarting the agent
+// wouldn't fix.
+func RegisterReadiness(name string) *Handle {
+	return globalCatalog.registerReadiness(name)
+}
+
+// Register a component with the default 30 seconds timeout, returns a token.
+// It is the same as RegisterLiveness.
+func Register(name string) *Handle {
+	return RegisterLiveness(name)
+}
+
 // Deregister a component from the healthcheck
 func Deregister(handle *Handle) error {
 	return globalCatalog.deregister(handle)
 }
 
-// GetStatus allows to query the health status of the agent
+// GetStatus allows to query the health status of all the components of the
+// agent. It is the same as GetReady.
 func GetStatus() Status {
 	return globalCatalog.getStatus()
 }
 
+// GetReady allows to query the readiness of the agent, all its components
+// have to be healthy
+func GetReady() Status {
+	return globalCatalog.getStatus()
+}
+
+// GetLive allows to query the liveness of the agent, the components only
+// registered for the readiness are not taken into account
+func GetLive() Status {
+	return globalCatalog.getLiveStatus()
+}
+
 // GetStatusNonBlocking allows to query the health status of the agent
 // and is guaranteed to return under 500ms.
 func GetStatusNonBlocking() (Status, error) {
+	return getStatusNonBlocking(GetStatus)
+}
+
+// GetReadyNonBlocking allows to query the readiness of the agent
+// and is guaranteed to return under 500ms.
+func GetReadyNonBlocking() (Status, error) {
+	return getStatusNonBlocking(GetReady)
+}
+
+// GetLiveNonBlocking allows to query the liveness of the agent
+// and is guaranteed to return under 500ms.
+func GetLiveNonBlocking() (Status, error) {
+	return getStatusNonBlocking(GetLive)
+}
+
+func getStatusNonBlocking(getStatus func() Status) (Status, error) {
 	// Run the health status in a goroutine
 	ch := make(chan Status, 1)
 	go func() {
-		ch <- GetStatus()
+		ch <- getStatus()
 	}()
 
 	// Only wait 500ms before returning
@@ -28,6 +28,8 @@ type component struct {
 	name       string
 	healthChan chan struct{}
 	healthy    bool
+	// readinessOnly components are not taken into account for the liveness
+	readinessOnly bool
 }
 
 type catalog struct {
@@ -45,6 +47,16 @@ func newCatalog() *catalog {
 
 // register a component with the default 30 seconds timeout, returns a token
 func (c *catalog) register(name string) *Handle {
+	return c.registerComponent(name, false)
+}
+
+// registerReadiness registers a component only taken into account for the
+// readiness, returns a token
+func (c *catalog) registerReadiness(name string) *Handle {
+	return c.registerComponent(name, true)
+}
+
+func (c *catalog) registerComponent(name string, readinessOnly bool) *Handle {
 	c.Lock()
 	defer c.Unlock()
 
@@ -53,9 +65,10 @@ func (c *catalog) register(name string) *Handle {
 	}
 
 	component := &component{
-		name:       name,
-		healthChan: make(chan struct{}, bufferSize),
-		healthy:    false,
+		name:          name,
+		healthChan:    make(chan struct{}, bufferSize),
+		healthy:       false,
+		readinessOnly: readinessOnly,
 	}
 	h := &Handle{
 		C: component.healthChan,
@@ -122,8 +135,19 @@ type Status struct {
 	Unhealthy []string
 }
 
-// getStatus allows to query the health status of the agent
+// getStatus allows to query the health status of all the components,
+// which is the readiness of the agent
 func (c *catalog) getStatus() Status {
+	return c.getComponentsStatus(false)
+}
+
+// getLiveStatus allows to query the health status of the components taken
+// into account for the liveness of the agent
+func (c *catalog) getLiveStatus() Status {
+	return c.getComponentsStatus(true)
+}
+
+func (c *catalog) getComponentsStatus(liveness bool) Status {
 	status := Status{}
 	c.RLock()
 	defer c.RUnlock()
@@ -137,6 +161,9 @@ func (c *catalog) getStatus() Status {
 
 	// Check components
 	for _, component := range c.components {
+		if liveness && component.readinessOnly {
+			continue
+		}
 		if component.healthy {
 			status.Healthy = append(status.Healthy, component.name)
 		} else {
@@ -108,3 +108,18 @@ func TestGetHealthy(t *testing.T) {
 	assert.Len(t, status.Healthy, 2)
 	assert.Len(t, status.Unhealthy, 0)
 }
+
+func TestReadinessOnly(t *testing.T) {
+	cat := newCatalog()
+	cat.register("live")
+	cat.registerReadiness("ready")
+
+	status := cat.getStatus()
+	assert.Contains(t, status.Unhealthy, "live")
+	assert.Contains(t, status.Unhealthy, "ready")
+
+	status = cat.getLiveStatus()
+	assert.Contains(t, status.Unhealthy, "live")
+	assert.NotContains(t, status.Unhealthy, "ready")
+	assert.NotContains(t, status.Healthy, "ready")
+}
@@ -7,6 +7,7 @@ package status
 
 import (
 	"sync"
+	"time"
 )
 
 type jmxCheckStatus struct {
@@ -20,9 +21,29 @@ type JMXStatus struct {
 	Timestamp    int64          `json:"timestamp"`
 }
 
+// JMXProcessStatus holds the state of the JMXFetch process run by the agent
+type JMXProcessStatus struct {
+	State         string `json:"state"`
+	Pid           int    `json:"pid,omitempty"`
+	StartTime     int64  `json:"start_time,omitempty"`
+	Restarts      int    `json:"restarts"`
+	LastExitTime  int64  `json:"last_exit_time,omitempty"`
+	LastExitError string `json:"last_exit_error,omitempty"`
+}
+
+// States of the JMXFetch process
+const (
+	JMXProcessRunning    = "running"
+	JMXProcessRestarting = "restarting"
+	JMXProcessStopped    = "stopped"
+	JMXProcessFailed     = "failed"
+)
+
 var (
-	lastJMXStatus JMXStatus
-	m             sync.RWMutex
+	lastJMXStatus       JMXStatus
+	lastJMXStatusUpdate time.Time
+	jmxProcessStatus    JMXProcessStatus
+	m                   sync.RWMutex
 )
 
 // SetJMXStatus sets the last JMX Status
@@ -31,6 +52,7 @@ func SetJMXStatus(s JMXStatus) {
 	defer m.Unlock()
 
 	lastJMXStatus = s
+	lastJMXStatusUpdate = time.Now()
 }
 
 // GetJMXStatus retrieves latest JMX Status
@@ -40,3 +62,28 @@ func GetJMXStatus() JMXStatus {
 
 	return lastJMXStatus
 }
+
+// GetJMXStatusUpdateTime returns when JMXFetch last sent its status, JMXFetch
+// sends it at the end of every collection so it tells whether it's still alive
+func GetJMXStatusUpdateTime() time.Time {
+	m.RLock()
+	defer m.RUnlock()
+
+	return lastJMXStatusUpdate
+}
+
+// SetJMXProcessStatus sets the state of the JMXFetch process
+func SetJMXProcessStatus(s JMXProcessStatus) {
+	m.Lock()
+	defer m.Unlock()
+
+	jmxProcessStatus = s
+}
+
+// GetJMXProcessStatus retrieves the state of the JMXFetch process
+func GetJMXProcessStatus() JMXProcessStatus {
+	m.RLock()
+	defer m.RUnlock()
+
+	return jmxProcessStatus
+}
@@ -0,0 +1,311 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package status
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	logsStatus "github.com/DataDog/datadog-agent/pkg/logs/status"
+)
+
+// JSONSchemaVersion is the version of the schema of JSONStatus. Fields can
+// be added within a version, renaming or removing one requires a new version.
+const JSONSchemaVersion = 1
+
+// Statuses of the checks in the JSON status
+const (
+	CheckStatusOK      = "ok"
+	CheckStatusWarning = "warning"
+	CheckStatusError   = "error"
+)
+
+// JSONStatus is the machine-readable rendering of the agent status. Unlike
+// the raw status served by the agent, which mirrors the internal expvars,
+// its schema is versioned and stays stable across agent versions.
+type JSONStatus struct {
+	SchemaVersion int              `json:"schema_version"`
+	Agent         AgentInfo        `json:"agent"`
+	Collector     CollectorStatus  `json:"collector"`
+	Aggregator    AggregatorStatus `json:"aggregator"`
+	Forwarder     ForwarderStatus  `json:"forwarder"`
+	Logs          LogsStatus       `json:"logs"`
+	Tagger        TaggerStatus     `json:"tagger"`
+}
+
+// AgentInfo identifies the agent reporting the status
+type AgentInfo struct {
+	Version       string `json:"version"`
+	Hostname      string `json:"hostname"`
+	PID           int    `json:"pid"`
+	PythonVersion string `json:"python_version"`
+	FIPSMode      bool   `json:"fips_mode"`
+	ConfFile      string `json:"conf_file"`
+	StartTime     string `json:"start_time"`
+	Time          string `json:"time"`
+}
+
+// CollectorStatus holds the status of the checks
+type CollectorStatus struct {
+	Checks           []CheckStatus       `json:"checks"`
+	ConfigErrors     map[string]string   `json:"config_errors"`
+	LoadingErrors    map[string][]string `json:"loading_errors"`
+	PythonInitErrors []string            `json:"python_init_errors"`
+}
+
+// CheckStatus holds the status of a check instance
+type CheckStatus struct {
+	Name                   string   `json:"name"`
+	ID                     string   `json:"id"`
+	Version                string   `json:"version"`
+	Status                 string   `json:"status"`
+	TotalRuns              uint64   `json:"total_runs"`
+	TotalErrors            uint64   `json:"total_errors"`
+	TotalWarnings          uint64   `json:"total_warnings"`
+	MetricSamples          int64    `json:"metric_samples"`
+	TotalMetricSamples     int64    `json:"total_metric_samples"`
+	Events                 int64    `json:"events"`
+	TotalEvents            int64    `json:"total_events"`
+	ServiceChecks          int64    `json:"service_checks"`
+	TotalServiceChecks     int64    `json:"total_service_checks"`
+	AverageExecutionTimeMs int64    `json:"average_execution_time_ms"`
+	LastExecutionTimeMs    int64    `json:"last_execution_time_ms"`
+	LastError              string   `json:"last_error"`
+	LastWarnings           []string `json:"last_warnings"`
+	UpdateTimestamp        int64    `json:"update_timestamp"`
+}
+
+// AggregatorStatus holds the counters of the aggregator
+type AggregatorStatus struct {
+	ChecksMetricSample      int64 `json:"checks_metric_sample"`
+	DogstatsdMetricSample   int64 `json:"dogstatsd_metric_sample"`
+	Event                   int64 `json:"event"`
+	ServiceCheck            int64 `json:"service_check"`
+	NumberOfFlush           int64 `json:"number_of_flush"`
+	SeriesFlushed           int64 `json:"series_flushed"`
+	SeriesFlushErrors       int64 `json:"series_flush_errors"`
+	SketchesFlushed         int64 `json:"sketches_flushed"`
+	SketchesFlushErrors     int64 `json:"sketches_flush_errors"`
+	ServiceCheckFlushed     int64 `json:"service_check_flushed"`
+	ServiceCheckFlushErrors int64 `json:"service_check_flush_errors"`
+	EventsFlushed           int64 `json:"events_flushed"`
+	EventsFlushErrors       int64 `json:"events_flush_errors"`
+	HostnameUpdate          int64 `json:"hostname_update"`
+}
+
+// ForwarderStatus holds the counters of the forwarder
+type ForwarderStatus struct {
+	Transactions ForwarderTransactions `json:"transactions"`
+	APIKeyStatus map[string]string     `json:"api_key_status"`
+}
+
+// ForwarderTransactions holds the counters of the transactions of the forwarder
+type ForwarderTransactions struct {
+	Success          int64            `json:"success"`
+	Errors           int64            `json:"errors"`
+	ErrorsByType     map[string]int64 `json:"errors_by_type"`
+	HTTPErrors       int64            `json:"http_errors"`
+	HTTPErrorsByCode map[string]int64 `json:"http_errors_by_code"`
+	Dropped          int64            `json:"dropped"`
+	DroppedOnInput   int64            `json:"dropped_on_input"`
+	Retried          int64            `json:"retried"`
+	Requeued         int64            `json:"requeued"`
+	RetryQueueSize   int64            `json:"retry_queue_size"`
+}
+
+// LogsStatus holds the status of the logs agent
+type LogsStatus struct {
+	Running  bool             `json:"running"`
+	Metrics  map[string]int64 `json:"metrics"`
+	Sources  []LogsSource     `json:"sources"`
+	Errors   []string         `json:"errors"`
+	Warnings []string         `json:"warnings"`
+}
+
+// LogsSource holds the status of a logs source
+type LogsSource struct {
+	Integration string   `json:"integration"`
+	Type        string   `json:"type"`
+	Status      string   `json:"status"`
+	Inputs      []string `json:"inputs"`
+	Messages    []string `json:"messages"`
+}
+
+// TaggerStatus holds the counters of the tagger
+type TaggerStatus struct {
+	Queries        map[string]int64 `json:"queries"`
+	CacheHits      int64            `json:"cache_hits"`
+	CacheMisses    int64            `json:"cache_misses"`
+	PrunedEntities int64            `json:"pruned_entities"`
+	FetchErrors    map[string]int64 `json:"fetch_errors"`
+	PullErrors     map[string]int64 `json:"pull_errors"`
+}
+
+// rawStatus is the subset of the status returned by GetStatus used to
+// build the JSONStatus. The aggregator, forwarder and tagger fields have
+// the names of their expvars, so they are converted to the schema types.
+type rawStatus struct {
+	Version       string `json:"version"`
+	PID           int    `json:"pid"`
+	PythonVersion string `json:"python_version"`
+	FIPSMode      bool   `json:"fips_mode"`
+	ConfFile      string `json:"conf_file"`
+	AgentStart    string `json:"agent_start"`
+	Time          string `json:"time"`
+	Metadata      struct {
+		Meta struct {
+			Hostname string `json:"hostname"`
+		} `json:"meta"`
+	} `json:"metadata"`
+	RunnerStats struct {
+		Checks map[string]map[string]*check.Stats
+	} `json:"runnerStats"`
+	AutoConfigStats struct {
+		ConfigErrors map[string]string
+	} `json:"autoConfigStats"`
+	PyLoaderStats struct {
+		ConfigureErrors map[string][]string
+	} `json:"pyLoaderStats"`
+	PythonInit struct {
+		Errors []string
+	} `json:"pythonInit"`
+	AggregatorStats struct {
+		ChecksMetricSample      int64
+		DogstatsdMetricSample   int64
+		Event                   int64
+		ServiceCheck            int64
+		NumberOfFlush           int64
+		SeriesFlushed           int64
+		SeriesFlushErrors       int64
+		SketchesFlushed         int64
+		SketchesFlushErrors     int64
+		ServiceCheckFlushed     int64
+		ServiceCheckFlushErrors int64
+		EventsFlushed           int64
+		EventsFlushErrors       int64
+		HostnameUpdate          int64
+	} `json:"aggregatorStats"`
+	ForwarderStats struct {
+		Transactions struct {
+			Success          int64
+			Errors           int64
+			ErrorsByType     map[string]int64
+			HTTPErrors       int64
+			HTTPErrorsByCode map[string]int64
+			Dropped          int64
+			DroppedOnInput   int64
+			Retried          int64
+			Requeued         int64
+			RetryQueueSize   int64
+		}
+		APIKeyStatus map[string]string
+	} `json:"forwarderStats"`
+	LogsStats   logsStatus.Status `json:"logsStats"`
+	TaggerStats struct {
+		Queries        map[string]int64
+		CacheHits      int64
+		CacheMisses    int64
+		PrunedEntities int64
+		FetchErrors    map[string]int64
+		PullErrors     map[string]int64
+	} `json:"taggerStats"`
+}
+
+// FormatJSONStatus takes the json bytestring of the status and renders it
+// with the versioned schema of JSONStatus
+func FormatJSONStatus(data []byte) (*JSONStatus, error) {
+	var raw rawStatus
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	s := &JSONStatus{
+		SchemaVersion: JSONSchemaVersion,
+		Agent: AgentInfo{
+			Version:       raw.Version,
+			Hostname:      raw.Metadata.Meta.Hostname,
+			PID:           raw.PID,
+			PythonVersion: raw.PythonVersion,
+			FIPSMode:      raw.FIPSMode,
+			ConfFile:      raw.ConfFile,
+			StartTime:     raw.AgentStart,
+			Time:          raw.Time,
+		},
+		Collector: CollectorStatus{
+			Checks:           []CheckStatus{},
+			ConfigErrors:     raw.AutoConfigStats.ConfigErrors,
+			LoadingErrors:    raw.PyLoaderStats.ConfigureErrors,
+			PythonInitErrors: raw.PythonInit.Errors,
+		},
+		Aggregator: AggregatorStatus(raw.AggregatorStats),
+		Forwarder: ForwarderStatus{
+			Transactions: ForwarderTransactions(raw.ForwarderStats.Transactions),
+			APIKeyStatus: raw.ForwarderStats.APIKeyStatus,
+		},
+		Logs: LogsStatus{
+			Running:  raw.LogsStats.IsRunning,
+			Metrics:  raw.LogsStats.StatusMetrics,
+			Sources:  []LogsSource{},
+			Errors:   raw.LogsStats.Errors,
+			Warnings: raw.LogsStats.Warnings,
+		},
+		Tagger: TaggerStatus(raw.TaggerStats),
+	}
+
+	for _, instances := range raw.RunnerStats.Checks {
+		for _, stats := range instances {
+			s.Collector.Checks = append(s.Collector.Checks, jsonCheckStatus(stats))
+		}
+	}
+	sort.Slice(s.Collector.Checks, func(i, j int) bool {
+		return s.Collector.Checks[i].ID < s.Collector.Checks[j].ID
+	})
+
+	for _, integration := range raw.LogsStats.Integrations {
+		for _, source := range integration.Sources {
+			s.Logs.Sources = append(s.Logs.Sources, LogsSource{
+				Integration: integration.Name,
+				Type:        source.Type,
+				Status:      source.Status,
+				Inputs:      source.Inputs,
+				Messages:    source.Messages,
+			})
+		}
+	}
+
+	return s, nil
+}
+
+func jsonCheckStatus(stats *check.Stats) CheckStatus {
+	status := CheckStatusOK
+	if stats.LastError != "" {
+		status = CheckStatusError
+	} else if len(stats.LastWarnings) > 0 {
+		status = CheckStatusWarning
+	}
+
+	return CheckStatus{
+		Name:                   stats.CheckName,
+		ID:                     string(stats.CheckID),
+		Version:                stats.CheckVersion,
+		Status:                 status,
+		TotalRuns:              stats.TotalRuns,
+		TotalErrors:            stats.TotalErrors,
+		TotalWarnings:          stats.TotalWarnings,
+		MetricSamples:          stats.MetricSamples,
+		TotalMetricSamples:     stats.TotalMetricSamples,
+		Events:                 stats.Events,
+		TotalEvents:            stats.TotalEvents,
+		ServiceChecks:          stats.ServiceChecks,
+		TotalServiceChecks:     stats.TotalServiceChecks,
+		AverageExecutionTimeMs: stats.AverageExecutionTime,
+		LastExecutionTimeMs:    stats.LastExecutionTime,
+		LastError:              string(lastErrorMessage(stats.LastError)),
+		LastWarnings:           stats.LastWarnings,
+		UpdateTimestamp:        stats.UpdateTimestamp,
+	}
+}
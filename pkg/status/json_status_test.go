@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatJSONStatus(t *testing.T) {
+	raw := []byte(`{
+		"version": "6.12.0",
+		"pid": 42,
+		"metadata": {"meta": {"hostname": "myhost"}},
+		"runnerStats": {
+			"Checks": {
+				"redis": {
+					"redis:b": {"CheckName": "redis", "CheckID": "redis:b", "TotalRuns": 3, "LastError": "[{\"message\": \"connection refused\", \"traceback\": \"...\"}]", "LastWarnings": []},
+					"redis:a": {"CheckName": "redis", "CheckID": "redis:a", "TotalRuns": 3, "LastWarnings": ["slow"]}
+				},
+				"load": {
+					"load": {"CheckName": "load", "CheckID": "load", "TotalRuns": 3, "AverageExecutionTime": 6, "LastWarnings": []}
+				}
+			}
+		},
+		"autoConfigStats": {"ConfigErrors": {"nginx": "yaml: invalid"}},
+		"pyLoaderStats": null,
+		"aggregatorStats": {"ChecksMetricSample": 10, "SeriesFlushed": 2, "Flush": {"MainFlushTime": {}}},
+		"forwarderStats": {
+			"Transactions": {"Success": 5, "Errors": 1, "ErrorsByType": {"DNSErrors": 1}, "HTTPErrorsByCode": {}},
+			"APIKeyStatus": {"API key ending with abcde": "API Key valid"}
+		},
+		"logsStats": {
+			"is_running": true,
+			"integrations": [{"name": "nginx", "sources": [{"type": "file", "status": "OK", "inputs": ["/var/log/nginx.log"]}]}]
+		},
+		"taggerStats": {"Queries": {"container_id": 4}, "CacheHits": 3, "CacheMisses": 1}
+	}`)
+
+	s, err := FormatJSONStatus(raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, JSONSchemaVersion, s.SchemaVersion)
+	assert.Equal(t, "6.12.0", s.Agent.Version)
+	assert.Equal(t, "myhost", s.Agent.Hostname)
+	assert.Equal(t, 42, s.Agent.PID)
+
+	require.Len(t, s.Collector.Checks, 3)
+	assert.Equal(t, "load", s.Collector.Checks[0].ID)
+	assert.Equal(t, CheckStatusOK, s.Collector.Checks[0].Status)
+	assert.Equal(t, int64(6), s.Collector.Checks[0].AverageExecutionTimeMs)
+	assert.Equal(t, "redis:a", s.Collector.Checks[1].ID)
+	assert.Equal(t, CheckStatusWarning, s.Collector.Checks[1].Status)
+	assert.Equal(t, "redis:b", s.Collector.Checks[2].ID)
+	assert.Equal(t, CheckStatusError, s.Collector.Checks[2].Status)
+	assert.Equal(t, "connection refused", s.Collector.Checks[2].LastError)
+	assert.Equal(t, map[string]string{"nginx": "yaml: invalid"}, s.Collector.ConfigErrors)
+
+	assert.Equal(t, int64(10), s.Aggregator.ChecksMetricSample)
+	assert.Equal(t, int64(2), s.Aggregator.SeriesFlushed)
+
+	assert.Equal(t, int64(5), s.Forwarder.Transactions.Success)
+	assert.Equal(t, map[string]int64{"DNSErrors": 1}, s.Forwarder.Transactions.ErrorsByType)
+	assert.Equal(t, "API Key valid", s.Forwarder.APIKeyStatus["API key ending with abcde"])
+
+	assert.True(t, s.Logs.Running)
+	assert.Equal(t, []LogsSource{{Integration: "nginx", Type: "file", Status: "OK", Inputs: []string{"/var/log/nginx.log"}}}, s.Logs.Sources)
+
+	assert.Equal(t, map[string]int64{"container_id": 4}, s.Tagger.Queries)
+	assert.Equal(t, int64(3), s.Tagger.CacheHits)
+}
@@ -43,6 +43,7 @@ func FormatStatus(data []byte) (string, error) {
 	aggregatorStats := stats["aggregatorStats"]
 	dogstatsdStats := stats["dogstatsdStats"]
 	jmxStats := stats["JMXStatus"]
+	jmxProcessStats := stats["JMXProcessStatus"]
 	logsStats := stats["logsStats"]
 	dcaStats := stats["clusterAgentStatus"]
 	endpointsInfos := stats["endpointsInfos"]
@@ -50,7 +51,7 @@ func FormatStatus(data []byte) (string, error) {
 	stats["title"] = title
 	renderHeader(b, stats)
 	renderChecksStats(b, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, "")
-	renderJMXFetchStatus(b, jmxStats)
+	renderJMXFetchStatus(b, jmxStats, jmxProcessStats)
 	renderForwarderStatus(b, forwarderStats)
 	renderEndpointsInfos(b, endpointsInfos)
 	renderLogsStatus(b, logsStats)
@@ -195,9 +196,10 @@ func renderCheckStats(data []byte, checkName string) (string, error) {
 	return b.String(), nil
 }
 
-func renderJMXFetchStatus(w io.Writer, jmxStats interface{}) {
+func renderJMXFetchStatus(w io.Writer, jmxStats, jmxProcessStats interface{}) {
 	stats := make(map[string]interface{})
 	stats["JMXStatus"] = jmxStats
+	stats["JMXProcessStatus"] = jmxProcessStats
 	t := template.Must(template.New("jmxfetch.tmpl").Funcs(fmap).ParseFiles(filepath.Join(templateFolder, "jmxfetch.tmpl")))
 
 	err := t.Execute(w, stats)
@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/fips"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -64,6 +65,7 @@ func GetStatus() (map[string]interface{}, error) {
 	stats["pid"] = os.Getpid()
 	pythonVersion := host.GetPythonVersion()
 	stats["python_version"] = strings.Split(pythonVersion, " ")[0]
+	stats["fips_mode"] = fips.Enabled()
 	stats["agent_start"] = startTime.Format(timeFormat)
 	stats["platform"] = platformPayload
 	stats["hostinfo"] = host.GetStatusInformation()
@@ -71,6 +73,7 @@ func GetStatus() (map[string]interface{}, error) {
 	stats["time"] = now.Format(timeFormat)
 
 	stats["JMXStatus"] = GetJMXStatus()
+	stats["JMXProcessStatus"] = GetJMXProcessStatus()
 
 	stats["logsStats"] = logs.GetStatus()
 
@@ -312,6 +315,16 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 		stats["pythonInit"] = nil
 	}
 
+	taggerData := expvar.Get("tagger")
+	if taggerData != nil {
+		taggerStatsJSON := []byte(taggerData.String())
+		taggerStats := make(map[string]interface{})
+		json.Unmarshal(taggerStatsJSON, &taggerStats)
+		stats["taggerStats"] = taggerStats
+	} else {
+		stats["taggerStats"] = nil
+	}
+
 	hostnameStatsJSON := []byte(expvar.Get("hostname").String())
 	hostnameStats := make(map[string]interface{})
 	json.Unmarshal(hostnameStatsJSON, &hostnameStats)
@@ -6,14 +6,17 @@
 package collectors
 
 import (
+	"path/filepath"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/tmplvar"
 )
 
 var templateVariables = map[string]struct{}{
-	"label": {},
+	"label":      {},
+	"annotation": {},
 }
 
 // retrieveMappingFromConfig gets a stringmapstring config key and
@@ -40,3 +43,16 @@ func resolveTag(tmpl, label string) string {
 	}
 	return tagName
 }
+
+// addMetadataAsTags adds a tag for every entry of a label or annotation map
+// whose lower-cased name matches one of the glob patterns of the mapping. The
+// tag name is resolved from the template of the matching pattern.
+func addMetadataAsTags(tags *utils.TagList, metadata map[string]string, metadataAsTags map[string]string) {
+	for name, value := range metadata {
+		for pattern, tmpl := range metadataAsTags {
+			if ok, _ := filepath.Match(pattern, strings.ToLower(name)); ok {
+				tags.AddAuto(resolveTag(tmpl, name), value)
+			}
+		}
+	}
+}
@@ -72,6 +72,9 @@ func TestResolveTag(t *testing.T) {
 		{
 			"%%label%%%%label%%", "app", "appapp",
 		},
+		{
+			"team_%%annotation%%", "owner", "team_owner",
+		},
 		{
 			"kube_", "app", "kube_", // no template variable
 		},
@@ -98,6 +98,7 @@ func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string,
 
 // dockerExtractEnvironmentVariables contain hard-coded environment variables from:
 // - Mesos/DCOS tags (mesos, marathon, chronos)
+// - Datadog unified service tags (service, env, version)
 func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariables []string, envAsTags map[string]string) {
 	var envSplit []string
 	var envName, envValue string
@@ -128,6 +129,14 @@ func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariable
 		case "NOMAD_GROUP_NAME":
 			tags.AddLow("nomad_group", envValue)
 
+		// Datadog
+		case "DD_SERVICE":
+			tags.AddLow("service", envValue)
+		case "DD_ENV":
+			tags.AddLow("env", envValue)
+		case "DD_VERSION":
+			tags.AddLow("version", envValue)
+
 		default:
 			if tagName, found := envAsTags[strings.ToLower(envSplit[0])]; found {
 				tags.AddAuto(tagName, envValue)
@@ -228,6 +228,28 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 			expectedOrch: []string{},
 			expectedHigh: []string{},
 		},
+		{
+			testName: "extractDatadog",
+			co: &types.ContainerJSON{
+				Config: &container.Config{
+					Env: []string{
+						"DD_SERVICE=web",
+						"DD_ENV=prod",
+						"DD_VERSION=1.2",
+					},
+					Labels: map[string]string{},
+				},
+			},
+			toRecordEnvAsTags:    map[string]string{},
+			toRecordLabelsAsTags: map[string]string{},
+			expectedLow: []string{
+				"service:web",
+				"env:prod",
+				"version:1.2",
+			},
+			expectedOrch: []string{},
+			expectedHigh: []string{},
+		},
 	}
 
 	dc := &DockerCollector{}
@@ -10,7 +10,6 @@ package collectors
 import (
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -45,20 +44,10 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 		tags.AddLow("kube_namespace", pod.Metadata.Namespace)
 
 		// Pod labels
-		for name, value := range pod.Metadata.Labels {
-			for pattern, tmpl := range c.labelsAsTags {
-				if ok, _ := filepath.Match(pattern, strings.ToLower(name)); ok {
-					tags.AddAuto(resolveTag(tmpl, name), value)
-				}
-			}
-		}
+		addMetadataAsTags(tags, pod.Metadata.Labels, c.labelsAsTags)
 
 		// Pod annotations
-		for name, value := range pod.Metadata.Annotations {
-			if tagName, found := c.annotationsAsTags[strings.ToLower(name)]; found {
-				tags.AddAuto(tagName, value)
-			}
-		}
+		addMetadataAsTags(tags, pod.Metadata.Annotations, c.annotationsAsTags)
 		if podTags, found := extractTagsFromMap(podTagsAnnotation, pod.Metadata.Annotations); found {
 			for tagName, value := range podTags {
 				tags.AddAuto(tagName, value)
@@ -33,7 +33,9 @@ type KubeMetadataCollector struct {
 	lastUpdate time.Time
 	updateFreq time.Duration
 
-	clusterAgentEnabled bool
+	clusterAgentEnabled   bool
+	collectClusterTags    bool
+	namespaceLabelsAsTags map[string]string
 }
 
 // Detect tries to connect to the kubelet and the API Server if the DCA is not used or the DCA.
@@ -65,6 +67,8 @@ func (c *KubeMetadataCollector) Detect(out chan<- []*TagInfo) (CollectionMode, e
 		}
 	}
 	c.infoOut = out
+	c.namespaceLabelsAsTags = retrieveMappingFromConfig("kubernetes_namespace_labels_as_tags")
+	c.collectClusterTags = config.Datadog.GetBool("cluster_agent.collect_cluster_tags")
 	c.updateFreq = time.Duration(config.Datadog.GetInt("kubernetes_metadata_tag_update_freq")) * time.Second
 	return PullCollection, nil
 }
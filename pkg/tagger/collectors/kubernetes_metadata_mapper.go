@@ -36,6 +36,7 @@ func (c *KubeMetadataCollector) getTagInfos(pods []*kubelet.Pod) []*TagInfo {
 	}
 	var tagInfo []*TagInfo
 	var metadataNames []string
+	namespaceLabels := make(map[string]map[string]string)
 	var tag []string
 	for _, po := range pods {
 		if kubelet.IsPodReady(po) == false {
@@ -78,6 +79,18 @@ func (c *KubeMetadataCollector) getTagInfos(pods []*kubelet.Pod) []*TagInfo {
 			}
 		}
 
+		if len(c.namespaceLabelsAsTags) > 0 {
+			labels, found := namespaceLabels[po.Metadata.Namespace]
+			if !found {
+				labels, err = c.getNamespaceLabels(po.Metadata.Namespace)
+				if err != nil {
+					log.Debugf("Could not fetch the labels of the namespace %s: %s", po.Metadata.Namespace, err)
+				}
+				namespaceLabels[po.Metadata.Namespace] = labels
+			}
+			addMetadataAsTags(tagList, labels, c.namespaceLabelsAsTags)
+		}
+
 		low, orchestrator, high := tagList.Compute()
 		// Register the tags for the pod itself
 		if po.Metadata.UID != "" {
@@ -128,6 +141,27 @@ func (c *KubeMetadataCollector) getMetadaNames(getPodMetaDataFromApiServerFunc f
 	return metadataNames, err
 }
 
+// getNamespaceLabels returns the labels of a namespace from the DCA if it is
+// used, or from the API server. With cluster_agent.collect_cluster_tags, the
+// labels distributed with the cluster tags of the DCA are used when they are
+// available to avoid a query per namespace.
+func (c *KubeMetadataCollector) getNamespaceLabels(ns string) (map[string]string, error) {
+	if c.isClusterAgentEnabled() {
+		if c.collectClusterTags {
+			if clusterTags, err := c.dcaClient.GetClusterTags(); err == nil {
+				if labels, found := clusterTags.NamespaceLabels[ns]; found {
+					return labels, nil
+				}
+			}
+		}
+		return c.dcaClient.GetNamespaceLabels(ns)
+	}
+	if c.apiClient == nil {
+		return nil, fmt.Errorf("no connection to the API server")
+	}
+	return c.apiClient.NamespaceLabels(ns)
+}
+
 // addToCacheMetadataMapping is acting like the DCA at the node level.
 func (c *KubeMetadataCollector) addToCacheMetadataMapping(kubeletPodList []*kubelet.Pod) error {
 	if len(kubeletPodList) == 0 {
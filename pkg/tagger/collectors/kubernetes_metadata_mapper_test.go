@@ -32,6 +32,12 @@ type FakeDCAClient struct {
 	NodeLabel    map[string]string
 	NodeLabelErr error
 
+	NamespaceLabels    map[string]string
+	NamespaceLabelsErr error
+
+	ClusterTags    apiv1.ClusterTags
+	ClusterTagsErr error
+
 	PodMetadataForNode    apiv1.NamespacesPodsStringsSet
 	PodMetadataForNodeErr error
 
@@ -60,6 +66,12 @@ func (f *FakeDCAClient) GetVersion() (version.Version, error) {
 func (f *FakeDCAClient) GetNodeLabels(nodeName string) (map[string]string, error) {
 	return f.NodeLabel, f.NodeLabelErr
 }
+func (f *FakeDCAClient) GetNamespaceLabels(ns string) (map[string]string, error) {
+	return f.NamespaceLabels, f.NamespaceLabelsErr
+}
+func (f *FakeDCAClient) GetClusterTags() (apiv1.ClusterTags, error) {
+	return f.ClusterTags, f.ClusterTagsErr
+}
 func (f *FakeDCAClient) GetPodsMetadataForNode(nodeName string) (apiv1.NamespacesPodsStringsSet, error) {
 	return f.PodMetadataForNode, f.PodMetadataForNodeErr
 }
@@ -277,13 +289,15 @@ func TestKubeMetadataCollector_getTagInfos(t *testing.T) {
 	kubeUtilFake := &kubelet.KubeUtil{}
 
 	type fields struct {
-		kubeUtil            *kubelet.KubeUtil
-		apiClient           *apiserver.APIClient
-		infoOut             chan<- []*TagInfo
-		dcaClient           clusteragent.DCAClientInterface
-		lastUpdate          time.Time
-		updateFreq          time.Duration
-		clusterAgentEnabled bool
+		kubeUtil              *kubelet.KubeUtil
+		apiClient             *apiserver.APIClient
+		infoOut               chan<- []*TagInfo
+		dcaClient             clusteragent.DCAClientInterface
+		lastUpdate            time.Time
+		updateFreq            time.Duration
+		clusterAgentEnabled   bool
+		collectClusterTags    bool
+		namespaceLabelsAsTags map[string]string
 	}
 	type args struct {
 		pods []*kubelet.Pod
@@ -320,6 +334,119 @@ func TestKubeMetadataCollector_getTagInfos(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "namespace labels as tags",
+			args: args{
+				pods: pods,
+			},
+			fields: fields{
+				kubeUtil:            kubeUtilFake,
+				clusterAgentEnabled: true,
+				dcaClient: &FakeDCAClient{
+					LocalVersion:            version.Version{Major: 1, Minor: 3},
+					KubernetesMetadataNames: []string{"svc1"},
+					NamespaceLabels: map[string]string{
+						"team":                    "infra",
+						"billing.example.com/org": "platform",
+						"ignored":                 "value",
+					},
+				},
+				namespaceLabelsAsTags: map[string]string{
+					"team":                  "kube_namespace_team",
+					"billing.example.com/*": "ns_%%label%%",
+				},
+			},
+			want: []*TagInfo{
+				{
+					Source:               kubeMetadataCollectorName,
+					Entity:               kubelet.PodUIDToEntityName("foouid"),
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"kube_service:svc1",
+						"kube_namespace_team:infra",
+						"ns_billing.example.com/org:platform",
+					},
+				},
+			},
+		},
+		{
+			name: "namespace labels from the cluster tags",
+			args: args{
+				pods: pods,
+			},
+			fields: fields{
+				kubeUtil:            kubeUtilFake,
+				clusterAgentEnabled: true,
+				collectClusterTags:  true,
+				dcaClient: &FakeDCAClient{
+					LocalVersion:            version.Version{Major: 1, Minor: 3},
+					KubernetesMetadataNames: []string{"svc1"},
+					ClusterTags: apiv1.ClusterTags{
+						Version: "1",
+						NamespaceLabels: map[string]map[string]string{
+							"default": {"team": "infra"},
+						},
+					},
+					NamespaceLabels: map[string]string{
+						"team": "not-used",
+					},
+				},
+				namespaceLabelsAsTags: map[string]string{
+					"team": "kube_namespace_team",
+				},
+			},
+			want: []*TagInfo{
+				{
+					Source:               kubeMetadataCollectorName,
+					Entity:               kubelet.PodUIDToEntityName("foouid"),
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"kube_service:svc1",
+						"kube_namespace_team:infra",
+					},
+				},
+			},
+		},
+		{
+			name: "cluster tags not collected",
+			args: args{
+				pods: pods,
+			},
+			fields: fields{
+				kubeUtil:            kubeUtilFake,
+				clusterAgentEnabled: true,
+				dcaClient: &FakeDCAClient{
+					LocalVersion:            version.Version{Major: 1, Minor: 3},
+					KubernetesMetadataNames: []string{"svc1"},
+					ClusterTags: apiv1.ClusterTags{
+						Version: "1",
+						NamespaceLabels: map[string]map[string]string{
+							"default": {"team": "infra"},
+						},
+					},
+					NamespaceLabels: map[string]string{
+						"team": "billing",
+					},
+				},
+				namespaceLabelsAsTags: map[string]string{
+					"team": "kube_namespace_team",
+				},
+			},
+			want: []*TagInfo{
+				{
+					Source:               kubeMetadataCollectorName,
+					Entity:               kubelet.PodUIDToEntityName("foouid"),
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"kube_service:svc1",
+						"kube_namespace_team:billing",
+					},
+				},
+			},
+		},
 		{
 			name: "clusterAgentEnabled enable but client init failed",
 			args: args{
@@ -344,13 +471,15 @@ func TestKubeMetadataCollector_getTagInfos(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &KubeMetadataCollector{
-				kubeUtil:            tt.fields.kubeUtil,
-				apiClient:           tt.fields.apiClient,
-				infoOut:             tt.fields.infoOut,
-				dcaClient:           tt.fields.dcaClient,
-				lastUpdate:          tt.fields.lastUpdate,
-				updateFreq:          tt.fields.updateFreq,
-				clusterAgentEnabled: tt.fields.clusterAgentEnabled,
+				kubeUtil:              tt.fields.kubeUtil,
+				apiClient:             tt.fields.apiClient,
+				infoOut:               tt.fields.infoOut,
+				dcaClient:             tt.fields.dcaClient,
+				lastUpdate:            tt.fields.lastUpdate,
+				updateFreq:            tt.fields.updateFreq,
+				clusterAgentEnabled:   tt.fields.clusterAgentEnabled,
+				collectClusterTags:    tt.fields.collectClusterTags,
+				namespaceLabelsAsTags: tt.fields.namespaceLabelsAsTags,
 			}
 
 			got := c.getTagInfos(tt.args.pods)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -28,28 +29,35 @@ var ChecksCardinality collectors.TagCardinality
 // dogstatsd.
 var DogstatsdCardinality collectors.TagCardinality
 
+// LogsCardinality defines the cardinality of tags we should add to the logs
+// collected from containers.
+var LogsCardinality = collectors.HighCardinality
+
+// ProcessCardinality defines the cardinality of tags we should send for the
+// containers collected by the process-agent.
+var ProcessCardinality = collectors.HighCardinality
+
 // Init must be called once config is available, call it in your cmd
 func Init() {
 	initOnce.Do(func() {
-		var err error
-		checkCard := config.Datadog.GetString("checks_tag_cardinality")
-		dsdCard := config.Datadog.GetString("dogstatsd_tag_cardinality")
-
-		ChecksCardinality, err = stringToTagCardinality(checkCard)
-		if err != nil {
-			log.Warnf("failed to parse check tag cardinality, defaulting to low. Error: %s", err)
-			ChecksCardinality = collectors.LowCardinality
-		}
-		DogstatsdCardinality, err = stringToTagCardinality(dsdCard)
-		if err != nil {
-			log.Warnf("failed to parse dogstatsd tag cardinality, defaulting to low. Error: %s", err)
-			DogstatsdCardinality = collectors.LowCardinality
-		}
+		InitCardinalities()
+		setSourcePrecedence(config.Datadog.GetStringSlice("tag_source_precedence"))
+		deletionGracePeriod = config.Datadog.GetDuration("tagger_deletion_grace_period") * time.Second
 
 		defaultTagger.Init(collectors.DefaultCatalog)
 	})
 }
 
+// InitCardinalities sets the tag cardinalities from the config. It is called
+// by Init, the processes following the tagger of the core agent call it
+// instead.
+func InitCardinalities() {
+	ChecksCardinality = cardinalityFromConfig("checks_tag_cardinality", collectors.LowCardinality)
+	DogstatsdCardinality = cardinalityFromConfig("dogstatsd_tag_cardinality", collectors.LowCardinality)
+	LogsCardinality = cardinalityFromConfig("logs_tag_cardinality", collectors.HighCardinality)
+	ProcessCardinality = cardinalityFromConfig("process_tag_cardinality", collectors.HighCardinality)
+}
+
 // Tag queries the defaultTagger to get entity tags from cache or sources.
 // It can return tags at high cardinality (with tags about individual containers),
 // or at orchestrator cardinality (pod/task level)
@@ -67,11 +75,45 @@ func List(cardinality collectors.TagCardinality) response.TaggerListResponse {
 	return defaultTagger.List(cardinality)
 }
 
+// Subscribe returns a channel receiving the tags of all the entities of the
+// defaultTagger, then their changes
+func Subscribe(cardinality collectors.TagCardinality) chan []EntityEvent {
+	return defaultTagger.Subscribe(cardinality)
+}
+
+// Unsubscribe closes the channel of a subscriber of the defaultTagger
+func Unsubscribe(ch chan []EntityEvent) {
+	defaultTagger.Unsubscribe(ch)
+}
+
 // GetEntityHash returns the hash for the tags associated with the given entity
 func GetEntityHash(entity string) string {
 	return defaultTagger.GetEntityHash(entity)
 }
 
+// cardinalityFromConfig returns the TagCardinality set by a config key, or the
+// fallback if it can't be parsed.
+func cardinalityFromConfig(key string, fallback collectors.TagCardinality) collectors.TagCardinality {
+	cardinality, err := stringToTagCardinality(config.Datadog.GetString(key))
+	if err != nil {
+		log.Warnf("failed to parse %s, defaulting to %s. Error: %s", key, tagCardinalityToString(fallback), err)
+		return fallback
+	}
+	return cardinality
+}
+
+// tagCardinalityToString is the reverse of stringToTagCardinality
+func tagCardinalityToString(c collectors.TagCardinality) string {
+	switch c {
+	case collectors.HighCardinality:
+		return "high"
+	case collectors.OrchestratorCardinality:
+		return "orchestrator"
+	default:
+		return "low"
+	}
+}
+
 // stringToTagCardinality extracts a TagCardinality from a string.
 // In case of failure to parse, returns an error and defaults to Low.
 func stringToTagCardinality(c string) (collectors.TagCardinality, error) {
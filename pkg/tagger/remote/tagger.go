@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package remote implements a tagger following the entities streamed by the
+tagger of the core agent over its gRPC api, for the other agent processes.
+*/
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/api/ipc"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	minRetryDelay = 1 * time.Second
+	maxRetryDelay = 1 * time.Minute
+)
+
+// For testing purpose
+var (
+	connect = func(socketPath string) (pb.TaggerClient, io.Closer, error) {
+		client, err := ipc.NewClient(socketPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client.Tagger, client, nil
+	}
+	retryDelay = minRetryDelay
+)
+
+// Tagger holds a copy of the entities of the tagger of the core agent. The
+// stream is opened again when it breaks, the copy is replaced by the
+// snapshot the new stream starts with.
+type Tagger struct {
+	socketPath  string
+	cardinality pb.TagCardinality
+
+	sync.RWMutex
+	entities map[string][]string
+	synced   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTagger returns a Tagger following the tagger served on socketPath, at
+// the given cardinality
+func NewTagger(socketPath string, cardinality collectors.TagCardinality) *Tagger {
+	return &Tagger{
+		socketPath:  socketPath,
+		cardinality: toPBCardinality(cardinality),
+		entities:    make(map[string][]string),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start follows the tagger of the core agent in the background
+func (t *Tagger) Start() {
+	go t.run()
+}
+
+// Stop closes the stream and waits for the Tagger to stop
+func (t *Tagger) Stop() {
+	close(t.stop)
+	<-t.done
+}
+
+// Tag returns the tags of an entity. It errors until the first snapshot of
+// the entities is received.
+func (t *Tagger) Tag(entity string) ([]string, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if !t.synced {
+		return nil, fmt.Errorf("the entities of the core agent tagger are not received yet")
+	}
+	tags := t.entities[entity]
+	return append([]string(nil), tags...), nil
+}
+
+// run follows the stream until the Tagger is stopped, opening it again
+// with an exponential backoff when it breaks
+func (t *Tagger) run() {
+	defer close(t.done)
+
+	delay := retryDelay
+	for {
+		received, err := t.follow()
+		select {
+		case <-t.stop:
+			return
+		default:
+		}
+
+		if received {
+			delay = retryDelay
+		}
+		log.Warnf("Lost the tagger stream of the core agent, subscribing again in %s: %s", delay, err)
+
+		select {
+		case <-t.stop:
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// follow applies the responses of a new stream until it breaks, it returns
+// whether any was received
+func (t *Tagger) follow() (bool, error) {
+	client, closer, err := connect(t.socketPath)
+	if err != nil {
+		return false, err
+	}
+	defer closer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-t.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	stream, err := client.StreamEntities(ctx, &pb.StreamTagsRequest{Cardinality: t.cardinality})
+	if err != nil {
+		return false, err
+	}
+
+	snapshot := true
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			return !snapshot, err
+		}
+		t.apply(response.Events, snapshot)
+		snapshot = false
+	}
+}
+
+// apply updates the copy of the entities, it is replaced by the snapshot
+// the streams start with
+func (t *Tagger) apply(events []*pb.StreamTagsEvent, snapshot bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	if snapshot {
+		t.entities = make(map[string][]string, len(events))
+		t.synced = true
+	}
+	for _, event := range events {
+		if event.Entity == nil {
+			continue
+		}
+		switch event.Type {
+		case pb.EventType_ADDED, pb.EventType_MODIFIED:
+			t.entities[event.Entity.Id] = event.Entity.Tags
+		case pb.EventType_DELETED:
+			delete(t.entities, event.Entity.Id)
+		}
+	}
+}
+
+func toPBCardinality(cardinality collectors.TagCardinality) pb.TagCardinality {
+	switch cardinality {
+	case collectors.OrchestratorCardinality:
+		return pb.TagCardinality_ORCHESTRATOR
+	case collectors.HighCardinality:
+		return pb.TagCardinality_HIGH
+	}
+	return pb.TagCardinality_LOW
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package remote
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/pb"
+	"github.com/DataDog/datadog-agent/pkg/tagger/server"
+)
+
+// fakeStream replays the responses it is given, then the error breaking it
+type fakeStream struct {
+	grpc.ClientStream
+	ctx       context.Context
+	responses chan *pb.StreamTagsResponse
+	err       error
+}
+
+func (s *fakeStream) Recv() (*pb.StreamTagsResponse, error) {
+	select {
+	case response, ok := <-s.responses:
+		if !ok {
+			return nil, s.err
+		}
+		return response, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// fakeClient opens the streams queued by the test
+type fakeClient struct {
+	t           *testing.T
+	cardinality pb.TagCardinality
+	streams     chan *fakeStream
+}
+
+func (c *fakeClient) StreamEntities(ctx context.Context, req *pb.StreamTagsRequest, opts ...grpc.CallOption) (pb.Tagger_StreamEntitiesClient, error) {
+	assert.Equal(c.t, c.cardinality, req.Cardinality)
+	stream := <-c.streams
+	stream.ctx = ctx
+	return stream, nil
+}
+
+func newFakeStream(err error, responses ...*pb.StreamTagsResponse) *fakeStream {
+	stream := &fakeStream{
+		responses: make(chan *pb.StreamTagsResponse, len(responses)),
+		err:       err,
+	}
+	for _, response := range responses {
+		stream.responses <- response
+	}
+	return stream
+}
+
+func added(entity string, tags ...string) *pb.StreamTagsEvent {
+	return &pb.StreamTagsEvent{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: entity, Tags: tags}}
+}
+
+// waitForTags polls the tags of an entity until they match
+func waitForTags(t *testing.T, tagger *Tagger, entity string, expected []string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tags, err := tagger.Tag(entity)
+		if err == nil && assert.ObjectsAreEqual(expected, tags) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the tags of %s are %v (%v), expected %v", entity, tags, err, expected)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTaggerResubscribe(t *testing.T) {
+	client := &fakeClient{t: t, cardinality: pb.TagCardinality_ORCHESTRATOR, streams: make(chan *fakeStream, 2)}
+	originalConnect := connect
+	connect = func(socketPath string) (pb.TaggerClient, io.Closer, error) {
+		assert.Equal(t, "/var/run/datadog/agent.sock", socketPath)
+		return client, ioutil.NopCloser(nil), nil
+	}
+	retryDelay = time.Millisecond
+	defer func() {
+		connect = originalConnect
+		retryDelay = minRetryDelay
+	}()
+
+	tagger := NewTagger("/var/run/datadog/agent.sock", collectors.OrchestratorCardinality)
+	_, err := tagger.Tag("container_id://foo")
+	assert.Error(t, err, "the tagger isn't synced before the first snapshot")
+
+	// the first stream is aborted after a change, the subscriber fell behind
+	first := newFakeStream(status.Error(codes.Aborted, "the subscriber fell behind the tagger"),
+		&pb.StreamTagsResponse{Events: []*pb.StreamTagsEvent{
+			added("container_id://foo", "image_name:redis"),
+			added("container_id://bar", "image_name:nginx"),
+		}},
+		&pb.StreamTagsResponse{Events: []*pb.StreamTagsEvent{
+			{Type: pb.EventType_MODIFIED, Entity: &pb.Entity{Id: "container_id://foo", Tags: []string{"image_name:redis", "env:prod"}}},
+		}},
+	)
+	close(first.responses)
+	// the deletion of bar was missed, the snapshot of the next stream
+	// replaces the entities
+	second := newFakeStream(nil,
+		&pb.StreamTagsResponse{Events: []*pb.StreamTagsEvent{
+			added("container_id://foo", "image_name:redis", "env:prod"),
+			added("container_id://baz", "image_name:postgres"),
+		}},
+	)
+	client.streams <- first
+	client.streams <- second
+
+	tagger.Start()
+	defer tagger.Stop()
+
+	waitForTags(t, tagger, "container_id://baz", []string{"image_name:postgres"})
+	waitForTags(t, tagger, "container_id://foo", []string{"image_name:redis", "env:prod"})
+	waitForTags(t, tagger, "container_id://bar", nil)
+	assert.Len(t, client.streams, 0)
+}
+
+func TestTaggerEmptySnapshot(t *testing.T) {
+	tagger := NewTagger("/var/run/datadog/agent.sock", collectors.HighCardinality)
+	tagger.apply([]*pb.StreamTagsEvent{added("container_id://foo", "image_name:redis")}, true)
+
+	// an empty snapshot removes all the entities
+	tagger.apply(nil, true)
+	tags, err := tagger.Tag("container_id://foo")
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+}
+
+func TestToPBCardinality(t *testing.T) {
+	for _, cardinality := range []collectors.TagCardinality{collectors.LowCardinality, collectors.OrchestratorCardinality, collectors.HighCardinality} {
+		converted, err := server.ToTagCardinality(toPBCardinality(cardinality))
+		assert.NoError(t, err)
+		assert.Equal(t, cardinality, converted)
+	}
+}
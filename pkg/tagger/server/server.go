@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package server implements the gRPC service streaming the entities of the
+tagger to the other agent processes, so that they don't have to collect the
+tags of the containers themselves.
+*/
+package server
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/pb"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// For testing purpose
+var (
+	subscribe   = tagger.Subscribe
+	unsubscribe = tagger.Unsubscribe
+)
+
+// Server implements the Tagger gRPC service with the default tagger
+type Server struct{}
+
+// NewServer returns a new Server
+func NewServer() *Server {
+	return &Server{}
+}
+
+// StreamEntities sends the tags of all the entities of the tagger, then
+// their changes, until the subscriber disconnects. The first response is
+// always the snapshot of the entities. The stream is aborted
+// when the subscriber can't keep up, it has to open a new one to get a new
+// snapshot of the entities.
+func (s *Server) StreamEntities(req *pb.StreamTagsRequest, stream pb.Tagger_StreamEntitiesServer) error {
+	cardinality, err := ToTagCardinality(req.Cardinality)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ch := subscribe(cardinality)
+	defer unsubscribe(ch)
+
+	// the snapshot is sent even when the tagger is empty, the subscribers
+	// reset their copy of the entities on the first response of a stream
+	snapshot := true
+	for {
+		select {
+		case events, ok := <-ch:
+			if !ok {
+				return status.Error(codes.Aborted, "the subscriber fell behind the tagger, a new stream must be opened")
+			}
+			response := &pb.StreamTagsResponse{
+				Events: make([]*pb.StreamTagsEvent, 0, len(events)),
+			}
+			for _, event := range events {
+				// the remote subscribers only follow the entities, they
+				// are notified when the deletion happens
+				if event.EventType == tagger.EventTypeDeletionPending {
+					continue
+				}
+				response.Events = append(response.Events, toStreamTagsEvent(event))
+			}
+			if len(response.Events) == 0 && !snapshot {
+				continue
+			}
+			snapshot = false
+			if err := stream.Send(response); err != nil {
+				log.Debugf("Could not send the tagger events to a subscriber: %s", err)
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// ToTagCardinality converts a tag cardinality of the gRPC api
+func ToTagCardinality(cardinality pb.TagCardinality) (collectors.TagCardinality, error) {
+	switch cardinality {
+	case pb.TagCardinality_LOW:
+		return collectors.LowCardinality, nil
+	case pb.TagCardinality_ORCHESTRATOR:
+		return collectors.OrchestratorCardinality, nil
+	case pb.TagCardinality_HIGH:
+		return collectors.HighCardinality, nil
+	}
+	return collectors.LowCardinality, fmt.Errorf("unknown tag cardinality %d", cardinality)
+}
+
+func toStreamTagsEvent(event tagger.EntityEvent) *pb.StreamTagsEvent {
+	var eventType pb.EventType
+	switch event.EventType {
+	case tagger.EventTypeAdded:
+		eventType = pb.EventType_ADDED
+	case tagger.EventTypeModified:
+		eventType = pb.EventType_MODIFIED
+	case tagger.EventTypeDeleted:
+		eventType = pb.EventType_DELETED
+	}
+	return &pb.StreamTagsEvent{
+		Type: eventType,
+		Entity: &pb.Entity{
+			Id:   event.Entity,
+			Hash: event.Hash,
+			Tags: event.Tags,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/pb"
+)
+
+// fakeStream records the responses sent to a subscriber
+type fakeStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *pb.StreamTagsResponse
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) Send(response *pb.StreamTagsResponse) error {
+	s.responses <- response
+	return nil
+}
+
+func TestStreamEntities(t *testing.T) {
+	ch := make(chan []tagger.EntityEvent, 2)
+	unsubscribed := false
+	subscribe = func(cardinality collectors.TagCardinality) chan []tagger.EntityEvent {
+		assert.Equal(t, collectors.OrchestratorCardinality, cardinality)
+		return ch
+	}
+	unsubscribe = func(c chan []tagger.EntityEvent) {
+		assert.Equal(t, ch, c)
+		unsubscribed = true
+	}
+	defer func() {
+		subscribe = tagger.Subscribe
+		unsubscribe = tagger.Unsubscribe
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeStream{ctx: ctx, responses: make(chan *pb.StreamTagsResponse, 2)}
+	done := make(chan error)
+	go func() {
+		done <- NewServer().StreamEntities(&pb.StreamTagsRequest{Cardinality: pb.TagCardinality_ORCHESTRATOR}, stream)
+	}()
+
+	ch <- []tagger.EntityEvent{
+		{EventType: tagger.EventTypeAdded, Entity: "container_id://foo", Tags: []string{"image_name:redis"}, Hash: "1a"},
+		{EventType: tagger.EventTypeAdded, Entity: "container_id://bar", Tags: []string{"image_name:nginx"}, Hash: "2b"},
+	}
+	response := <-stream.responses
+	require.Len(t, response.Events, 2)
+	assert.Equal(t, &pb.StreamTagsEvent{
+		Type:   pb.EventType_ADDED,
+		Entity: &pb.Entity{Id: "container_id://foo", Hash: "1a", Tags: []string{"image_name:redis"}},
+	}, response.Events[0])
+
+	// pending deletions are not sent, only the deletion itself
+	ch <- []tagger.EntityEvent{{EventType: tagger.EventTypeDeletionPending, Entity: "container_id://bar", Tags: []string{"image_name:nginx"}, Hash: "2b"}}
+	ch <- []tagger.EntityEvent{{EventType: tagger.EventTypeDeleted, Entity: "container_id://bar"}}
+	response = <-stream.responses
+	assert.Equal(t, []*pb.StreamTagsEvent{{
+		Type:   pb.EventType_DELETED,
+		Entity: &pb.Entity{Id: "container_id://bar"},
+	}}, response.Events)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.True(t, unsubscribed)
+
+	// the stream is aborted when the subscriber is dropped by the tagger
+	close(ch)
+	stream = &fakeStream{ctx: context.Background(), responses: make(chan *pb.StreamTagsResponse)}
+	err := NewServer().StreamEntities(&pb.StreamTagsRequest{Cardinality: pb.TagCardinality_ORCHESTRATOR}, stream)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
+
+func TestStreamEntitiesInvalidCardinality(t *testing.T) {
+	stream := &fakeStream{ctx: context.Background()}
+	err := NewServer().StreamEntities(&pb.StreamTagsRequest{Cardinality: 42}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamEntitiesEmptySnapshot(t *testing.T) {
+	ch := make(chan []tagger.EntityEvent, 1)
+	subscribe = func(cardinality collectors.TagCardinality) chan []tagger.EntityEvent { return ch }
+	unsubscribe = func(c chan []tagger.EntityEvent) {}
+	defer func() {
+		subscribe = tagger.Subscribe
+		unsubscribe = tagger.Unsubscribe
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &fakeStream{ctx: ctx, responses: make(chan *pb.StreamTagsResponse, 1)}
+	go NewServer().StreamEntities(&pb.StreamTagsRequest{Cardinality: pb.TagCardinality_HIGH}, stream)
+
+	// the subscribers reset their entities even when the tagger is empty
+	ch <- []tagger.EntityEvent{}
+	response := <-stream.responses
+	assert.Empty(t, response.Events)
+}
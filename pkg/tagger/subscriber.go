@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package tagger
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// number of event bundles buffered for a subscriber before it's dropped
+const subscriberBufferSize = 100
+
+// EventType is the type of an entity event
+type EventType int
+
+const (
+	// EventTypeAdded is sent when an entity is added to the store
+	EventTypeAdded EventType = iota
+	// EventTypeModified is sent when the tags of an entity change
+	EventTypeModified
+	// EventTypeDeleted is sent when an entity is removed from the store
+	EventTypeDeleted
+	// EventTypeDeletionPending is sent with the final tags of an entity when
+	// its deletion is scheduled. The entity is still tagged until it is
+	// deleted, after the tagger_deletion_grace_period.
+	EventTypeDeletionPending
+)
+
+// EntityEvent is a change of the tags of an entity, at the cardinality of
+// the subscriber. The tags of deleted entities are empty.
+type EntityEvent struct {
+	EventType EventType
+	Entity    string
+	Tags      []string
+	Hash      string
+}
+
+// subscribe returns a channel receiving the tags of all the entities, then
+// the changes of the store. The channel is closed when the subscriber falls
+// behind, it has to subscribe again to receive a new snapshot.
+func (s *tagStore) subscribe(cardinality collectors.TagCardinality) chan []EntityEvent {
+	ch := make(chan []EntityEvent, subscriberBufferSize)
+
+	// the store is locked until the subscriber is registered so that no
+	// change is missed between the snapshot and the first event
+	s.storeMutex.RLock()
+	defer s.storeMutex.RUnlock()
+
+	snapshot := make([]EntityEvent, 0, len(s.store))
+	for entity, storedTags := range s.store {
+		snapshot = append(snapshot, newEntityEvent(EventTypeAdded, entity, storedTags, cardinality))
+	}
+	ch <- snapshot
+
+	s.subscribersMutex.Lock()
+	s.subscribers[ch] = cardinality
+	s.subscribersMutex.Unlock()
+
+	return ch
+}
+
+// unsubscribe closes the channel of a subscriber
+func (s *tagStore) unsubscribe(ch chan []EntityEvent) {
+	s.subscribersMutex.Lock()
+	defer s.subscribersMutex.Unlock()
+
+	if _, found := s.subscribers[ch]; found {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// hasSubscribers returns whether the changes of the store are followed
+func (s *tagStore) hasSubscribers() bool {
+	s.subscribersMutex.RLock()
+	defer s.subscribersMutex.RUnlock()
+	return len(s.subscribers) > 0
+}
+
+// notify sends an event to the subscribers, the store must be locked. The
+// subscribers whose buffer is full are dropped instead of blocking the
+// tagger.
+func (s *tagStore) notify(eventType EventType, entity string, storedTags *entityTags) {
+	s.subscribersMutex.Lock()
+	defer s.subscribersMutex.Unlock()
+
+	for ch, cardinality := range s.subscribers {
+		event := EntityEvent{EventType: eventType, Entity: entity}
+		if storedTags != nil {
+			event = newEntityEvent(eventType, entity, storedTags, cardinality)
+		}
+		select {
+		case ch <- []EntityEvent{event}:
+		default:
+			log.Warnf("Dropping a tagger subscriber falling behind, it will have to resubscribe")
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func newEntityEvent(eventType EventType, entity string, storedTags *entityTags, cardinality collectors.TagCardinality) EntityEvent {
+	tags, _, hash := storedTags.get(cardinality)
+	return EntityEvent{
+		EventType: eventType,
+		Entity:    entity,
+		Tags:      copyArray(tags),
+		Hash:      hash,
+	}
+}
@@ -199,10 +199,11 @@ func (t *Tagger) registerCollectors(replies []collectorReply) {
 
 func (t *Tagger) pull() {
 	t.RLock()
-	for _, puller := range t.pullers {
+	for name, puller := range t.pullers {
 		err := puller.Pull()
 		if err != nil {
 			log.Warnf("%s", err.Error())
+			pullErrors.Add(name, 1)
 		}
 	}
 	t.RUnlock()
@@ -225,12 +226,15 @@ func (t *Tagger) Tag(entity string, cardinality collectors.TagCardinality) ([]st
 	if entity == "" {
 		return nil, fmt.Errorf("empty entity ID")
 	}
+	queries.Add(entityKind(entity), 1)
 	cachedTags, sources, _ := t.tagStore.lookup(entity, cardinality)
 
 	if len(sources) == len(t.fetchers) {
 		// All sources sent data to cache
+		cacheHits.Add(1)
 		return copyArray(cachedTags), nil
 	}
+	cacheMisses.Add(1)
 	// Else, partial cache miss, query missing data
 	// TODO: get logging on that to make sure we should optimize
 	tagArrays := [][]string{cachedTags}
@@ -250,6 +254,7 @@ IterCollectors:
 			log.Debugf("entity %s not found in %s, skipping: %v", entity, name, err)
 		case err != nil:
 			log.Warnf("error collecting from %s: %s", name, err)
+			fetchErrors.Add(name, 1)
 			continue // don't store empty tags, retry next time
 		}
 		tagArrays = append(tagArrays, low)
@@ -275,6 +280,18 @@ IterCollectors:
 	return copyArray(computedTags), nil
 }
 
+// Subscribe returns a channel receiving the tags of all the entities at the
+// given cardinality, then their changes. The channel is closed when the
+// subscriber falls behind, it has to subscribe again for a new snapshot.
+func (t *Tagger) Subscribe(cardinality collectors.TagCardinality) chan []EntityEvent {
+	return t.tagStore.subscribe(cardinality)
+}
+
+// Unsubscribe stops the events sent to a subscriber and closes its channel
+func (t *Tagger) Unsubscribe(ch chan []EntityEvent) {
+	t.tagStore.unsubscribe(ch)
+}
+
 // List the content of the tagger
 func (t *Tagger) List(cardinality collectors.TagCardinality) response.TaggerListResponse {
 	r := response.TaggerListResponse{
@@ -288,6 +305,10 @@ func (t *Tagger) List(cardinality collectors.TagCardinality) response.TaggerList
 		tags, sources, _ := et.get(cardinality)
 		entity.Tags = copyArray(tags)
 		entity.Sources = copyArray(sources)
+		entity.SourceTags = et.tagsBySource(cardinality)
+		if conflicts := et.conflicts(cardinality); len(conflicts) > 0 {
+			entity.Conflicts = conflicts
+		}
 		r.Entities[entityID] = entity
 	}
 
@@ -6,6 +6,7 @@
 package tagger
 
 import (
+	"expvar"
 	"fmt"
 	"sort"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
@@ -310,3 +312,83 @@ func TestSafeCache(t *testing.T) {
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2", "low3"}, tags2)
 }
+
+func TestTelemetry(t *testing.T) {
+	c := &DummyCollector{}
+	c.On("Detect", mock.Anything).Return(collectors.FetchOnlyCollection, nil)
+	catalog := collectors.Catalog{
+		"fetcher": func() collectors.Collector { return c },
+	}
+	tagger := newTagger()
+	tagger.Init(catalog)
+
+	value := func(m *expvar.Map, key string) int64 {
+		if v, ok := m.Get(key).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	queriesBefore := value(&queries, "container_id")
+	hitsBefore, missesBefore := cacheHits.Value(), cacheMisses.Value()
+	errorsBefore := value(&fetchErrors, "fetcher")
+	prunedBefore := prunedEntities.Value()
+
+	c.On("Fetch", "container_id://foo").Return([]string{}, []string{}, []string{}, fmt.Errorf("test failure")).Once()
+	c.On("Fetch", "container_id://foo").Return([]string{"low"}, []string{}, []string{}, nil).Once()
+	for i := 0; i < 3; i++ {
+		_, err := tagger.Tag("container_id://foo", collectors.LowCardinality)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(3), value(&queries, "container_id")-queriesBefore)
+	assert.Equal(t, int64(1), cacheHits.Value()-hitsBefore)
+	assert.Equal(t, int64(2), cacheMisses.Value()-missesBefore)
+	assert.Equal(t, int64(1), value(&fetchErrors, "fetcher")-errorsBefore)
+
+	tagger.tagStore.processTagInfo(&collectors.TagInfo{Entity: "container_id://foo", Source: "fetcher", DeleteEntity: true})
+	tagger.tagStore.prune()
+	assert.Equal(t, int64(1), prunedEntities.Value()-prunedBefore)
+
+	assert.Equal(t, "kubernetes_pod_uid", entityKind("kubernetes_pod_uid://1234"))
+	assert.Equal(t, "unknown", entityKind("entity_name"))
+}
+
+func TestList(t *testing.T) {
+	tagger := newTagger()
+	tagger.tagStore.processTagInfo(&collectors.TagInfo{
+		Entity:       "container_id://foo",
+		Source:       "docker",
+		LowCardTags:  []string{"image_name:redis"},
+		HighCardTags: []string{"container_id:foo"},
+	})
+	tagger.tagStore.processTagInfo(&collectors.TagInfo{
+		Entity:               "container_id://foo",
+		Source:               "kubelet",
+		LowCardTags:          []string{"kube_namespace:default"},
+		OrchestratorCardTags: []string{"pod_name:redis-1"},
+	})
+
+	list := tagger.List(collectors.OrchestratorCardinality)
+	assert.Len(t, list.Entities, 1)
+	entity := list.Entities["container_id://foo"]
+	assert.ElementsMatch(t, []string{"image_name:redis", "kube_namespace:default", "pod_name:redis-1"}, entity.Tags)
+	assert.ElementsMatch(t, []string{"docker", "kubelet"}, entity.Sources)
+	assert.Equal(t, map[string][]string{
+		"docker":  {"image_name:redis"},
+		"kubelet": {"kube_namespace:default", "pod_name:redis-1"},
+	}, entity.SourceTags)
+}
+
+func TestCardinalityFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("logs_tag_cardinality", "high")
+
+	mockConfig.Set("logs_tag_cardinality", "orchestrator")
+	assert.Equal(t, collectors.OrchestratorCardinality, cardinalityFromConfig("logs_tag_cardinality", collectors.HighCardinality))
+
+	mockConfig.Set("logs_tag_cardinality", "LOW")
+	assert.Equal(t, collectors.LowCardinality, cardinalityFromConfig("logs_tag_cardinality", collectors.HighCardinality))
+
+	mockConfig.Set("logs_tag_cardinality", "container")
+	assert.Equal(t, collectors.HighCardinality, cardinalityFromConfig("logs_tag_cardinality", collectors.HighCardinality))
+}
@@ -7,12 +7,22 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
+// sourcePrecedence holds the priorities of the sources listed in the
+// tag_source_precedence option, overriding the collector priorities
+var sourcePrecedence map[string]collectors.CollectorPriority
+
+// deletionGracePeriod is how long the tags of a deleted entity are kept, so
+// that the consumers can still tag the last metrics of a container or pod
+var deletionGracePeriod time.Duration
+
 // entityTags holds the tag information for a given entity
 type entityTags struct {
 	sync.RWMutex
@@ -21,9 +31,10 @@ type entityTags struct {
 	highCardTags         map[string][]string
 	cacheValid           bool
 	cachedSource         []string
-	cachedAll            []string // Low + orchestrator + high
-	cachedOrchestrator   []string // Low + orchestrator (subslice of cachedAll)
-	cachedLow            []string // Sub-slice of cachedAll
+	cachedAll            []string                 // Low + orchestrator + high
+	cachedOrchestrator   []string                 // Low + orchestrator (subslice of cachedAll)
+	cachedLow            []string                 // Sub-slice of cachedAll
+	cachedConflicts      map[string][]tagPriority // by tag key, when sources disagree on its value
 	tagsHash             string
 }
 
@@ -33,13 +44,24 @@ type tagStore struct {
 	storeMutex    sync.RWMutex
 	store         map[string]*entityTags
 	toDeleteMutex sync.RWMutex
-	toDelete      map[string]struct{} // set emulation
+	toDelete      map[string]*pendingDeletion // by entity
+
+	subscribersMutex sync.RWMutex
+	subscribers      map[chan []EntityEvent]collectors.TagCardinality
+}
+
+// pendingDeletion is the deletion of an entity, requested by some of its
+// sources
+type pendingDeletion struct {
+	since   time.Time
+	sources map[string]struct{}
 }
 
 func newTagStore() *tagStore {
 	return &tagStore{
-		store:    make(map[string]*entityTags),
-		toDelete: make(map[string]struct{}),
+		store:       make(map[string]*entityTags),
+		toDelete:    make(map[string]*pendingDeletion),
+		subscribers: make(map[chan []EntityEvent]collectors.TagCardinality),
 	}
 }
 
@@ -54,13 +76,24 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 		return fmt.Errorf("empty source name, skipping message")
 	}
 	if info.DeleteEntity {
-		s.toDeleteMutex.Lock()
-		s.toDelete[info.Entity] = struct{}{}
-		s.toDeleteMutex.Unlock()
+		s.scheduleDeletion(info.Entity, info.Source)
 		return nil
 	}
 
-	// TODO: check if real change
+	// an entity added again before it is pruned, like a restarted
+	// container, is not deleted anymore. Only the sources that deleted it
+	// can add it again: the other sources may still be reporting the tags
+	// they collected before the deletion.
+	s.toDeleteMutex.Lock()
+	deletion, cancelled := s.toDelete[info.Entity]
+	if cancelled {
+		_, cancelled = deletion.sources[info.Source]
+	}
+	if cancelled {
+		delete(s.toDelete, info.Entity)
+	}
+	s.toDeleteMutex.Unlock()
+
 	s.storeMutex.Lock()
 	defer s.storeMutex.Unlock()
 	storedTags, exist := s.store[info.Entity]
@@ -73,12 +106,28 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 		s.store[info.Entity] = storedTags
 	}
 
+	// the hashes are only compared to notify the subscribers of real changes
+	subscribed := s.hasSubscribers()
+	var previousHash string
+	if subscribed && exist {
+		_, _, previousHash = storedTags.get(collectors.HighCardinality)
+	}
+
 	storedTags.Lock()
-	defer storedTags.Unlock()
 	storedTags.lowCardTags[info.Source] = info.LowCardTags
 	storedTags.orchestratorCardTags[info.Source] = info.OrchestratorCardTags
 	storedTags.highCardTags[info.Source] = info.HighCardTags
 	storedTags.cacheValid = false
+	storedTags.Unlock()
+
+	if !subscribed {
+		return nil
+	}
+	if !exist {
+		s.notify(EventTypeAdded, info.Entity, storedTags)
+	} else if _, _, hash := storedTags.get(collectors.HighCardinality); cancelled || hash != previousHash {
+		s.notify(EventTypeModified, info.Entity, storedTags)
+	}
 
 	return nil
 }
@@ -98,8 +147,34 @@ func computeTagsHash(tags []string) string {
 	return hash
 }
 
-// prune will lock the store and delete tags for the entity previously
-// passed as delete. This is to be called regularly from the user class.
+// scheduleDeletion queues the deletion of an entity requested by one of its
+// sources, its tags are still returned until it is pruned after the grace
+// period. The subscribers are notified with the final tags of the entity.
+func (s *tagStore) scheduleDeletion(entity, source string) {
+	s.toDeleteMutex.Lock()
+	deletion, pending := s.toDelete[entity]
+	if !pending {
+		deletion = &pendingDeletion{since: time.Now(), sources: make(map[string]struct{})}
+		s.toDelete[entity] = deletion
+	}
+	deletion.sources[source] = struct{}{}
+	s.toDeleteMutex.Unlock()
+
+	if pending || !s.hasSubscribers() {
+		return
+	}
+
+	s.storeMutex.RLock()
+	defer s.storeMutex.RUnlock()
+	if storedTags, found := s.store[entity]; found {
+		s.notify(EventTypeDeletionPending, entity, storedTags)
+	}
+}
+
+// prune will lock the store and delete tags for the entities previously
+// passed as delete, once their grace period is over. This is to be called
+// regularly from the user class, the entities are kept until the first
+// prune after their grace period.
 func (s *tagStore) prune() error {
 	s.toDeleteMutex.Lock()
 	defer s.toDeleteMutex.Unlock()
@@ -110,14 +185,22 @@ func (s *tagStore) prune() error {
 
 	s.storeMutex.Lock()
 	defer s.storeMutex.Unlock()
-	for entity := range s.toDelete {
-		delete(s.store, entity)
+	deadline := time.Now().Add(-deletionGracePeriod)
+	pruned := 0
+	for entity, deletion := range s.toDelete {
+		if deletion.since.After(deadline) {
+			continue
+		}
+		if _, found := s.store[entity]; found {
+			delete(s.store, entity)
+			s.notify(EventTypeDeleted, entity, nil)
+			prunedEntities.Add(1)
+		}
+		delete(s.toDelete, entity)
+		pruned++
 	}
 
-	log.Debugf("pruned %d removed entities, %d remaining", len(s.toDelete), len(s.store))
-
-	// Start fresh
-	s.toDelete = make(map[string]struct{})
+	log.Debugf("pruned %d removed entities, %d pending deletion, %d remaining", pruned, len(s.toDelete), len(s.store))
 
 	return nil
 }
@@ -139,8 +222,10 @@ func (s *tagStore) lookup(entity string, cardinality collectors.TagCardinality)
 
 type tagPriority struct {
 	tag         string                       // full tag
-	priority    collectors.CollectorPriority // collector priority
+	source      string                       // name of the source sending the tag
+	priority    collectors.CollectorPriority // collector or configured source priority
 	cardinality collectors.TagCardinality    // cardinality level of the tag (low, orchestrator, high)
+	dropped     bool                         // true if a higher priority source sent the same tag key
 }
 
 func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []string, string) {
@@ -177,17 +262,17 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 	var lowCardTags []string
 	var orchestratorCardTags []string
 	var highCardTags []string
-	for _, tags := range tagPrioMapper {
+	conflicts := make(map[string][]tagPriority)
+	for tagName, tags := range tagPrioMapper {
 		for i := 0; i < len(tags); i++ {
-			insert := true
 			for j := 0; j < len(tags); j++ {
 				// if we find a duplicate tag with higher priority we do not insert the tag
 				if i != j && tags[i].priority < tags[j].priority {
-					insert = false
+					tags[i].dropped = true
 					break
 				}
 			}
-			if !insert {
+			if tags[i].dropped {
 				continue
 			}
 			if tags[i].cardinality == collectors.HighCardinality {
@@ -199,6 +284,9 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 			}
 			lowCardTags = append(lowCardTags, tags[i].tag)
 		}
+		if hasConflictingValues(tags) {
+			conflicts[tagName] = tags
+		}
 	}
 
 	tags := append(lowCardTags, orchestratorCardTags...)
@@ -210,6 +298,7 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 	e.cachedAll = tags
 	e.cachedLow = e.cachedAll[:len(lowCardTags)]
 	e.cachedOrchestrator = e.cachedAll[:len(lowCardTags)+len(orchestratorCardTags)]
+	e.cachedConflicts = conflicts
 	e.tagsHash = computeTagsHash(e.cachedAll)
 
 	if cardinality == collectors.HighCardinality {
@@ -220,19 +309,118 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 	return lowCardTags, sources, e.tagsHash
 }
 
-func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, cardinality collectors.TagCardinality) {
-	priority, found := collectors.CollectorPriorities[source]
-	if !found {
-		log.Warnf("Tagger: %s collector has no defined priority, assuming low", source)
-		priority = collectors.NodeRuntime
+// tagsBySource returns the tags sent by every source, up to the given
+// cardinality, before the tags of the sources are merged
+func (e *entityTags) tagsBySource(cardinality collectors.TagCardinality) map[string][]string {
+	e.RLock()
+	defer e.RUnlock()
+
+	tags := make(map[string][]string, len(e.lowCardTags))
+	for source, low := range e.lowCardTags {
+		tags[source] = copyArray(low)
+		if cardinality == collectors.OrchestratorCardinality || cardinality == collectors.HighCardinality {
+			tags[source] = append(tags[source], e.orchestratorCardTags[source]...)
+		}
+		if cardinality == collectors.HighCardinality {
+			tags[source] = append(tags[source], e.highCardTags[source]...)
+		}
+	}
+	return tags
+}
+
+// conflicts returns, by tag key, the tags sent with different values by
+// several sources, up to the given cardinality. The tags that were not kept
+// because of a higher priority source are reported as dropped.
+func (e *entityTags) conflicts(cardinality collectors.TagCardinality) map[string]response.TaggerListConflict {
+	// make sure the conflicts are computed for the current tags
+	e.get(cardinality)
+
+	e.RLock()
+	defer e.RUnlock()
+
+	conflicts := make(map[string]response.TaggerListConflict)
+	for tagName, tags := range e.cachedConflicts {
+		var filtered []tagPriority
+		for _, t := range tags {
+			if t.cardinality <= cardinality {
+				filtered = append(filtered, t)
+			}
+		}
+		if !hasConflictingValues(filtered) {
+			continue
+		}
+		conflict := response.TaggerListConflict{
+			Kept:    make(map[string][]string),
+			Dropped: make(map[string][]string),
+		}
+		kept := make(map[string]struct{})
+		for _, t := range filtered {
+			if !t.dropped {
+				kept[t.tag] = struct{}{}
+				conflict.Kept[t.source] = append(conflict.Kept[t.source], t.tag)
+			}
+		}
+		for _, t := range filtered {
+			// dropping a value that is kept from another source is not a conflict
+			if _, found := kept[t.tag]; t.dropped && !found {
+				conflict.Dropped[t.source] = append(conflict.Dropped[t.source], t.tag)
+			}
+		}
+		conflicts[tagName] = conflict
+	}
+	return conflicts
+}
+
+// hasConflictingValues returns whether tags sharing the same key were sent
+// with different values by different sources
+func hasConflictingValues(tags []tagPriority) bool {
+	for i := 0; i < len(tags); i++ {
+		for j := i + 1; j < len(tags); j++ {
+			if tags[i].source != tags[j].source && tags[i].tag != tags[j].tag {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, cardinality collectors.TagCardinality) {
+	priority := sourcePriority(source)
 
 	for _, t := range tags {
 		tagName := strings.Split(t, ":")[0]
 		tagPrioMapper[tagName] = append(tagPrioMapper[tagName], tagPriority{
 			tag:         t,
+			source:      source,
 			priority:    priority,
 			cardinality: cardinality,
 		})
 	}
 }
+
+// sourcePriority returns the priority of the tags of a source: the sources
+// listed in tag_source_precedence come first, in their configured order,
+// then the other sources by collector priority.
+func sourcePriority(source string) collectors.CollectorPriority {
+	if priority, found := sourcePrecedence[source]; found {
+		return priority
+	}
+	priority, found := collectors.CollectorPriorities[source]
+	if !found {
+		log.Warnf("Tagger: %s collector has no defined priority, assuming low", source)
+		priority = collectors.NodeRuntime
+	}
+	return priority
+}
+
+// setSourcePrecedence ranks the given sources, highest priority first,
+// above all the collector priorities
+func setSourcePrecedence(sources []string) {
+	sourcePrecedence = make(map[string]collectors.CollectorPriority, len(sources))
+	for rank, source := range sources {
+		if _, found := sourcePrecedence[source]; found {
+			continue
+		}
+		sourcePrecedence[source] = collectors.ClusterOrchestrator + collectors.CollectorPriority(len(sources)-rank)
+	}
+}
@@ -6,12 +6,15 @@
 package tagger
 
 import (
+	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
@@ -183,6 +186,170 @@ func (s *StoreTestSuite) TestPrune() {
 
 }
 
+func (s *StoreTestSuite) TestPruneGracePeriod() {
+	deletionGracePeriod = time.Minute
+	defer func() { deletionGracePeriod = 0 }()
+
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test1",
+		LowCardTags: []string{"low"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{Source: "source1", Entity: "test1", DeleteEntity: true})
+
+	// the entity is still tagged during the grace period
+	s.store.prune()
+	tags, _, _ := s.store.lookup("test1", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"low"}, tags)
+
+	// deleting it again does not extend the grace period
+	s.store.toDeleteMutex.Lock()
+	deletionTime := s.store.toDelete["test1"].since.Add(-2 * time.Minute)
+	s.store.toDelete["test1"].since = deletionTime
+	s.store.toDeleteMutex.Unlock()
+	s.store.processTagInfo(&collectors.TagInfo{Source: "source1", Entity: "test1", DeleteEntity: true})
+	assert.Equal(s.T(), deletionTime, s.store.toDelete["test1"].since)
+
+	s.store.prune()
+	tags, _, _ = s.store.lookup("test1", collectors.LowCardinality)
+	assert.Nil(s.T(), tags)
+	assert.Len(s.T(), s.store.toDelete, 0)
+}
+
+func (s *StoreTestSuite) TestPruneCancelledDeletion() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test1",
+		LowCardTags: []string{"low"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{Source: "source1", Entity: "test1", DeleteEntity: true})
+	ch := s.store.subscribe(collectors.LowCardinality)
+	<-ch
+
+	// the entity is added again before it is pruned
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test1",
+		LowCardTags: []string{"low"},
+	})
+	events := <-ch
+	assert.Equal(s.T(), []EntityEvent{{EventType: EventTypeModified, Entity: "test1", Tags: []string{"low"}, Hash: computeTagsHash([]string{"low"})}}, events)
+
+	s.store.prune()
+	tags, _, _ := s.store.lookup("test1", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"low"}, tags)
+	assert.Len(s.T(), s.store.toDelete, 0)
+	s.store.unsubscribe(ch)
+}
+
+func (s *StoreTestSuite) TestPruneDeletionOtherSource() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test1",
+		LowCardTags: []string{"low"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source2",
+		Entity:      "test1",
+		LowCardTags: []string{"other"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{Source: "source1", Entity: "test1", DeleteEntity: true})
+
+	// another source still reporting the entity doesn't cancel its deletion
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source2",
+		Entity:      "test1",
+		LowCardTags: []string{"other"},
+	})
+	s.store.toDeleteMutex.RLock()
+	assert.Len(s.T(), s.store.toDelete, 1)
+	s.store.toDeleteMutex.RUnlock()
+
+	s.store.prune()
+	tags, _, _ := s.store.lookup("test1", collectors.LowCardinality)
+	assert.Nil(s.T(), tags)
+	assert.Len(s.T(), s.store.toDelete, 0)
+}
+
+func (s *StoreTestSuite) TestSubscribe() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:       "source1",
+		Entity:       "test1",
+		LowCardTags:  []string{"low"},
+		HighCardTags: []string{"high"},
+	})
+
+	// the snapshot is sent first
+	ch := s.store.subscribe(collectors.LowCardinality)
+	events := <-ch
+	assert.Len(s.T(), events, 1)
+	assert.Equal(s.T(), EventTypeAdded, events[0].EventType)
+	assert.Equal(s.T(), "test1", events[0].Entity)
+	assert.Equal(s.T(), []string{"low"}, events[0].Tags)
+	assert.NotEmpty(s.T(), events[0].Hash)
+
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test2",
+		LowCardTags: []string{"low"},
+	})
+	events = <-ch
+	assert.Equal(s.T(), []EntityEvent{{EventType: EventTypeAdded, Entity: "test2", Tags: []string{"low"}, Hash: computeTagsHash([]string{"low"})}}, events)
+
+	// the tags are unchanged, no event is sent
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:       "source1",
+		Entity:       "test1",
+		LowCardTags:  []string{"low"},
+		HighCardTags: []string{"high"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:       "source1",
+		Entity:       "test1",
+		LowCardTags:  []string{"low"},
+		HighCardTags: []string{"other"},
+	})
+	events = <-ch
+	assert.Equal(s.T(), EventTypeModified, events[0].EventType)
+	assert.Equal(s.T(), "test1", events[0].Entity)
+	assert.Equal(s.T(), computeTagsHash([]string{"low", "other"}), events[0].Hash)
+
+	s.store.processTagInfo(&collectors.TagInfo{Source: "source1", Entity: "test2", DeleteEntity: true})
+	events = <-ch
+	assert.Equal(s.T(), []EntityEvent{{EventType: EventTypeDeletionPending, Entity: "test2", Tags: []string{"low"}, Hash: computeTagsHash([]string{"low"})}}, events)
+	s.store.prune()
+	events = <-ch
+	assert.Equal(s.T(), []EntityEvent{{EventType: EventTypeDeleted, Entity: "test2"}}, events)
+	assert.Len(s.T(), ch, 0)
+
+	s.store.unsubscribe(ch)
+	_, open := <-ch
+	assert.False(s.T(), open)
+	assert.False(s.T(), s.store.hasSubscribers())
+}
+
+func (s *StoreTestSuite) TestSubscriberFallingBehind() {
+	ch := s.store.subscribe(collectors.HighCardinality)
+	for i := 0; i <= subscriberBufferSize; i++ {
+		s.store.processTagInfo(&collectors.TagInfo{
+			Source:      "source1",
+			Entity:      fmt.Sprintf("test%d", i),
+			LowCardTags: []string{"low"},
+		})
+	}
+
+	// the channel is closed once its buffer is full
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.Equal(s.T(), subscriberBufferSize, received)
+	assert.False(s.T(), s.store.hasSubscribers())
+
+	// unsubscribing a dropped subscriber is a no-op
+	s.store.unsubscribe(ch)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
@@ -300,3 +467,69 @@ func TestDigest(t *testing.T) {
 		assert.Equal(t, beforeShuffle, computeTagsHash(tags))
 	}
 }
+
+func TestSourcePrecedence(t *testing.T) {
+	collectors.CollectorPriorities = map[string]collectors.CollectorPriority{
+		"docker":                  collectors.NodeRuntime,
+		"kubelet":                 collectors.NodeOrchestrator,
+		"kube-metadata-collector": collectors.ClusterOrchestrator,
+	}
+	defer setSourcePrecedence(nil)
+
+	newEntity := func() *entityTags {
+		return &entityTags{
+			lowCardTags: map[string][]string{
+				"docker":                  {"env:docker", "image_name:redis"},
+				"kubelet":                 {"env:kubelet", "kube_namespace:default"},
+				"kube-metadata-collector": {"kube_service:redis"},
+			},
+			orchestratorCardTags: make(map[string][]string),
+			highCardTags: map[string][]string{
+				"docker": {"container_name:redis"},
+			},
+		}
+	}
+
+	// collector priorities: the kubelet wins over docker
+	etags := newEntity()
+	tags, _, _ := etags.get(collectors.HighCardinality)
+	assert.ElementsMatch(t, []string{"env:kubelet", "image_name:redis", "kube_namespace:default", "kube_service:redis", "container_name:redis"}, tags)
+	assert.Equal(t, map[string]response.TaggerListConflict{
+		"env": {
+			Kept:    map[string][]string{"kubelet": {"env:kubelet"}},
+			Dropped: map[string][]string{"docker": {"env:docker"}},
+		},
+	}, etags.conflicts(collectors.HighCardinality))
+
+	// configured precedence: docker wins over every collector
+	setSourcePrecedence([]string{"docker", "unknown", "docker"})
+	etags = newEntity()
+	tags, _, _ = etags.get(collectors.LowCardinality)
+	assert.ElementsMatch(t, []string{"env:docker", "image_name:redis", "kube_namespace:default", "kube_service:redis"}, tags)
+	assert.Equal(t, map[string]response.TaggerListConflict{
+		"env": {
+			Kept:    map[string][]string{"docker": {"env:docker"}},
+			Dropped: map[string][]string{"kubelet": {"env:kubelet"}},
+		},
+	}, etags.conflicts(collectors.LowCardinality))
+
+	// sources of the same priority keep all their values
+	setSourcePrecedence([]string{"kubelet"})
+	etags = newEntity()
+	etags.lowCardTags["kube-metadata-collector"] = []string{"env:kubelet", "kube_service:redis"}
+	tags, _, _ = etags.get(collectors.LowCardinality)
+	assert.Contains(t, tags, "env:kubelet")
+	assert.NotContains(t, tags, "env:docker")
+	assert.Equal(t, map[string]response.TaggerListConflict{
+		"env": {
+			Kept:    map[string][]string{"kubelet": {"env:kubelet"}},
+			Dropped: map[string][]string{"docker": {"env:docker"}},
+		},
+	}, etags.conflicts(collectors.LowCardinality))
+
+	// no conflict when the sources agree on the value
+	etags = newEntity()
+	etags.lowCardTags["docker"] = []string{"env:kubelet"}
+	etags.get(collectors.LowCardinality)
+	assert.Empty(t, etags.conflicts(collectors.LowCardinality))
+}
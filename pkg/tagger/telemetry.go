@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package tagger
+
+import (
+	"expvar"
+	"strings"
+)
+
+var (
+	taggerExpvars  = expvar.NewMap("tagger")
+	queries        = expvar.Map{} // by entity kind
+	cacheHits      = expvar.Int{}
+	cacheMisses    = expvar.Int{}
+	prunedEntities = expvar.Int{}
+	fetchErrors    = expvar.Map{} // by collector
+	pullErrors     = expvar.Map{} // by collector
+)
+
+func init() {
+	taggerExpvars.Set("Queries", &queries)
+	taggerExpvars.Set("CacheHits", &cacheHits)
+	taggerExpvars.Set("CacheMisses", &cacheMisses)
+	taggerExpvars.Set("PrunedEntities", &prunedEntities)
+	taggerExpvars.Set("FetchErrors", &fetchErrors)
+	taggerExpvars.Set("PullErrors", &pullErrors)
+}
+
+// entityKind returns the prefix of an entity ID, like container_id or
+// kubernetes_pod_uid
+func entityKind(entity string) string {
+	if i := strings.Index(entity, "://"); i > 0 {
+		return entity[:i]
+	}
+	return "unknown"
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Counter tracks how many times something is happening.
+type Counter interface {
+	// Inc increments the counter for the given tags values.
+	Inc(tagsValue ...string)
+	// Add adds the given value, which must be positive, to the counter for
+	// the given tags values.
+	Add(value float64, tagsValue ...string)
+	// WithValues returns the counter of the given tags values, to be
+	// resolved once by the code incrementing it for every sample.
+	WithValues(tagsValue ...string) SimpleCounter
+}
+
+// SimpleCounter is the counter of given tags values.
+type SimpleCounter interface {
+	// Inc increments the counter.
+	Inc()
+	// Add adds the given value, which must be positive, to the counter.
+	Add(value float64)
+}
+
+// NewCounter creates a Counter registered under subsystem_name, with the
+// given tags.
+func NewCounter(subsystem, name string, tags []string, help string) Counter {
+	c := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		},
+		tags,
+	)
+	return &promCounter{pc: register(c).(*prometheus.CounterVec)}
+}
+
+type promCounter struct {
+	pc *prometheus.CounterVec
+}
+
+func (c *promCounter) Inc(tagsValue ...string) {
+	c.pc.WithLabelValues(tagsValue...).Inc()
+}
+
+func (c *promCounter) Add(value float64, tagsValue ...string) {
+	c.pc.WithLabelValues(tagsValue...).Add(value)
+}
+
+func (c *promCounter) WithValues(tagsValue ...string) SimpleCounter {
+	return c.pc.WithLabelValues(tagsValue...)
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Gauge tracks the value of one health metric of the agent.
+type Gauge interface {
+	// Set sets the value of the gauge for the given tags values.
+	Set(value float64, tagsValue ...string)
+	// Inc increments the gauge for the given tags values.
+	Inc(tagsValue ...string)
+	// Dec decrements the gauge for the given tags values.
+	Dec(tagsValue ...string)
+}
+
+// NewGauge creates a Gauge registered under subsystem_name, with the given
+// tags.
+func NewGauge(subsystem, name string, tags []string, help string) Gauge {
+	g := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		},
+		tags,
+	)
+	return &promGauge{pg: register(g).(*prometheus.GaugeVec)}
+}
+
+type promGauge struct {
+	pg *prometheus.GaugeVec
+}
+
+func (g *promGauge) Set(value float64, tagsValue ...string) {
+	g.pg.WithLabelValues(tagsValue...).Set(value)
+}
+
+func (g *promGauge) Inc(tagsValue ...string) {
+	g.pg.WithLabelValues(tagsValue...).Inc()
+}
+
+func (g *promGauge) Dec(tagsValue ...string) {
+	g.pg.WithLabelValues(tagsValue...).Dec()
+}
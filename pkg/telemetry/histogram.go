@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Histogram tracks the distribution of values, like durations.
+type Histogram interface {
+	// Observe samples the value for the given tags values.
+	Observe(value float64, tagsValue ...string)
+}
+
+// NewHistogram creates a Histogram registered under subsystem_name, with
+// the given tags. The default buckets of Prometheus, suited to durations
+// in seconds, are used when buckets is nil.
+func NewHistogram(subsystem, name string, tags []string, help string, buckets []float64) Histogram {
+	h := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		},
+		tags,
+	)
+	return &promHistogram{ph: register(h).(*prometheus.HistogramVec)}
+}
+
+type promHistogram struct {
+	ph *prometheus.HistogramVec
+}
+
+func (h *promHistogram) Observe(value float64, tagsValue ...string) {
+	h.ph.WithLabelValues(tagsValue...).Observe(value)
+}
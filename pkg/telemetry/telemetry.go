@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package telemetry holds the registry of the internal metrics of the agent.
+The subsystems register their counters, gauges and histograms in it, and
+the registry is exposed in the Prometheus exposition format for the
+self-monitoring of the agent.
+*/
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(prometheus.NewGoCollector())
+	return r
+}
+
+// Handler returns an http handler exposing the metrics of the registry in
+// the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// register registers a collector, returning the collector already
+// registered under the same name if any, so that a metric can be declared
+// by several instances of a subsystem.
+func register(c prometheus.Collector) prometheus.Collector {
+	if err := registry.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package telemetry
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	counter := NewCounter("test", "requests", []string{"state"}, "Number of requests.")
+	counter.Inc("ok")
+	counter.Add(2, "ok")
+	counter.Inc("error")
+	counter.WithValues("blocked").Add(3)
+	gauge := NewGauge("test", "queue_size", nil, "Size of the queue.")
+	gauge.Set(5)
+	gauge.Dec()
+	histogram := NewHistogram("test", "duration_seconds", nil, "Duration of the requests.", []float64{1, 10})
+	histogram.Observe(3)
+
+	// declaring the same metric again returns the registered one
+	NewCounter("test", "requests", []string{"state"}, "Number of requests.").Inc("ok")
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/telemetry", nil))
+	body, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `test_requests{state="ok"} 4`)
+	assert.Contains(t, string(body), `test_requests{state="error"} 1`)
+	assert.Contains(t, string(body), `test_requests{state="blocked"} 3`)
+	assert.Contains(t, string(body), "test_queue_size 4")
+	assert.Contains(t, string(body), `test_duration_seconds_bucket{le="10"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/api/ipc"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/config/legacy"
 	"github.com/DataDog/datadog-agent/pkg/trace/flags"
@@ -167,10 +168,24 @@ func (c *AgentConfig) validate() error {
 // when it can not be obtained by any other means. It is replaced in tests.
 var fallbackHostnameFunc = os.Hostname
 
+// grpcHostnameFunc specifies the function to use for obtaining the hostname
+// over the gRPC api of the infrastructure agent. It is replaced in tests.
+var grpcHostnameFunc = ipc.GetHostname
+
 // acquireHostname attempts to acquire a hostname for this configuration. It
-// tries to shell out to the infrastructure agent for this, if DD_AGENT_BIN is
-// set, otherwise falling back to os.Hostname.
+// asks the infrastructure agent over its gRPC api if cmd_grpc_socket is set,
+// else tries to shell out to it, if DD_AGENT_BIN is set, otherwise falling
+// back to os.Hostname.
 func (c *AgentConfig) acquireHostname() error {
+	if socketPath := config.Datadog.GetString("cmd_grpc_socket"); socketPath != "" {
+		hostname, err := grpcHostnameFunc(socketPath)
+		if err == nil && hostname != "" {
+			c.Hostname = hostname
+			return nil
+		}
+		log.Infof("Error retrieving the agent hostname over gRPC, falling back to the agent binary: %v", err)
+	}
+
 	var cmd *exec.Cmd
 	if c.DDAgentBin != "" {
 		// Agent 6
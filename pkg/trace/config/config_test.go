@@ -1,12 +1,14 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/api/ipc"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/writer/backoff"
 	writerconfig "github.com/DataDog/datadog-agent/pkg/trace/writer/config"
@@ -46,6 +48,45 @@ func TestConfigHostname(t *testing.T) {
 		assert.Equal(host, cfg.Hostname)
 	})
 
+	t.Run("grpc", func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv("DD_CMD_GRPC_SOCKET", "/var/run/datadog/agent.sock")
+		defer os.Unsetenv("DD_CMD_GRPC_SOCKET")
+		assert.NoError(err)
+		grpcHostnameFunc = func(socketPath string) (string, error) {
+			assert.Equal("/var/run/datadog/agent.sock", socketPath)
+			return "grpc-host", nil
+		}
+		defer func() {
+			grpcHostnameFunc = ipc.GetHostname
+		}()
+		cfg, err := Load("./testdata/multi_api_keys.ini")
+		assert.NoError(err)
+		assert.Equal("grpc-host", cfg.Hostname)
+	})
+
+	t.Run("grpc error", func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv("DD_CMD_GRPC_SOCKET", "/var/run/datadog/agent.sock")
+		defer os.Unsetenv("DD_CMD_GRPC_SOCKET")
+		assert.NoError(err)
+		grpcHostnameFunc = func(string) (string, error) {
+			return "", errors.New("unreachable")
+		}
+		fallbackHostnameFunc = func() (string, error) {
+			return "fallback-host", nil
+		}
+		defer func() {
+			grpcHostnameFunc = ipc.GetHostname
+			fallbackHostnameFunc = os.Hostname
+		}()
+		cfg, err := Load("./testdata/multi_api_keys.ini")
+		assert.NoError(err)
+		assert.Equal("fallback-host", cfg.Hostname)
+	})
+
 	t.Run("file", func(t *testing.T) {
 		defer cleanConfig()()
 		assert := assert.New(t)
@@ -30,6 +30,29 @@ func GetHostAlias() (string, error) {
 	return res, err
 }
 
+// GetTags returns the instance ID, the region and the instance type of the
+// ECS instance from the Alibaba Metadata api
+func GetTags() ([]string, error) {
+	tags := []string{}
+
+	for _, item := range []struct {
+		tag      string
+		endpoint string
+	}{
+		{"instance-id", "/latest/meta-data/instance-id"},
+		{"region", "/latest/meta-data/region-id"},
+		{"instance-type", "/latest/meta-data/instance/instance-type"},
+	} {
+		value, err := getResponse(metadataURL + item.endpoint)
+		if err != nil {
+			return tags, fmt.Errorf("unable to query metadata endpoint: %s", err)
+		}
+		tags = append(tags, fmt.Sprintf("%s:%s", item.tag, value))
+	}
+
+	return tags, nil
+}
+
 func getResponseWithMaxLength(endpoint string, maxLength int) (string, error) {
 	result, err := getResponse(endpoint)
 	if err != nil {
@@ -30,3 +30,25 @@ func TestGetHostname(t *testing.T) {
 	assert.Equal(t, expected, val)
 	assert.Equal(t, lastRequest.URL.Path, "/latest/meta-data/instance-id")
 }
+
+func TestGetTags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		switch r.URL.Path {
+		case "/latest/meta-data/instance-id":
+			io.WriteString(w, "i-rj9aql2pwopjn4sm24ix")
+		case "/latest/meta-data/region-id":
+			io.WriteString(w, "cn-hangzhou")
+		case "/latest/meta-data/instance/instance-type":
+			io.WriteString(w, "ecs.g5.large")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"instance-id:i-rj9aql2pwopjn4sm24ix", "region:cn-hangzhou", "instance-type:ecs.g5.large"}, tags)
+}
@@ -49,6 +49,36 @@ func GetClusterName() (string, error) {
 	return clusterName, nil
 }
 
+// GetSubscriptionID returns the ID of the subscription of the VM from the Azure Metadata api
+func GetSubscriptionID() (string, error) {
+	res, err := getResponse(metadataURL + "/metadata/instance/compute/subscriptionId?api-version=2017-08-01&format=text")
+	if err != nil {
+		return "", fmt.Errorf("unable to query metadata endpoint: %s", err)
+	}
+	if res == "" {
+		return "", errors.New("empty subscription ID")
+	}
+	return res, nil
+}
+
+// GetTags returns the tags of the VM from the Azure Metadata api, they are
+// formatted as "key1:value1;key2:value2"
+func GetTags() ([]string, error) {
+	tags := []string{}
+
+	all, err := getResponse(metadataURL + "/metadata/instance/compute/tags?api-version=2017-08-01&format=text")
+	if err != nil {
+		return tags, fmt.Errorf("unable to query metadata endpoint: %s", err)
+	}
+
+	for _, tag := range strings.Split(all, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
 func getResponseWithMaxLength(endpoint string, maxLength int) (string, error) {
 	result, err := getResponse(endpoint)
 	if err != nil {
@@ -50,3 +50,37 @@ func TestGetClusterName(t *testing.T) {
 	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/resourceGroupName")
 	assert.Equal(t, lastRequest.URL.RawQuery, "api-version=2017-08-01&format=text")
 }
+
+func TestGetSubscriptionID(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "8c56d827-5f07-45ce-8f2b-6c5001db5c6f")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetSubscriptionID()
+	assert.Nil(t, err)
+	assert.Equal(t, "8c56d827-5f07-45ce-8f2b-6c5001db5c6f", val)
+	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/subscriptionId")
+	assert.Equal(t, lastRequest.URL.RawQuery, "api-version=2017-08-01&format=text")
+}
+
+func TestGetTags(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "env:prod;team:infra;")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"env:prod", "team:infra"}, tags)
+	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/tags")
+	assert.Equal(t, lastRequest.URL.RawQuery, "api-version=2017-08-01&format=text")
+}
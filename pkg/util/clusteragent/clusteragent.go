@@ -14,6 +14,7 @@ import (
 	"os"
 
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -46,6 +47,8 @@ type DCAClientInterface interface {
 
 	GetVersion() (version.Version, error)
 	GetNodeLabels(nodeName string) (map[string]string, error)
+	GetNamespaceLabels(ns string) (map[string]string, error)
+	GetClusterTags() (apiv1.ClusterTags, error)
 	GetPodsMetadataForNode(nodeName string) (apiv1.NamespacesPodsStringsSet, error)
 	GetKubernetesMetadataNames(nodeName, ns, podName string) ([]string, error)
 
@@ -62,8 +65,11 @@ type DCAClient struct {
 	clusterAgentAPIEndpoint       string          // ${SCHEME}://${clusterAgentHost}:${PORT}
 	ClusterAgentVersion           version.Version // Version of the cluster-agent we're connected to
 	clusterAgentAPIClient         *http.Client
-	clusterAgentAPIRequestHeaders http.Header
+	clusterAgentAPIRequestHeaders http.Header // Replaced when the auth token rotates, never modified
+	headersMutex                  sync.Mutex
+	authToken                     *security.ClusterAgentAuthToken
 	leaderClient                  *leaderClient
+	clusterTags                   clusterTagsCache
 }
 
 // resetGlobalClusterAgentClient is a helper to remove the current DCAClient global
@@ -99,13 +105,11 @@ func (c *DCAClient) init() error {
 		return err
 	}
 
-	authToken, err := security.GetClusterAgentAuthToken()
+	c.authToken, err = security.NewClusterAgentAuthToken()
 	if err != nil {
 		return err
 	}
-
-	c.clusterAgentAPIRequestHeaders = http.Header{}
-	c.clusterAgentAPIRequestHeaders.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", authToken))
+	c.setRequestHeaders(c.authToken.Get())
 
 	// TODO remove insecure
 	c.clusterAgentAPIClient = util.GetClient(false)
@@ -124,6 +128,29 @@ func (c *DCAClient) init() error {
 	return nil
 }
 
+// setRequestHeaders replaces the headers sent with the requests
+func (c *DCAClient) setRequestHeaders(authToken string) {
+	headers := http.Header{}
+	headers.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", authToken))
+	c.clusterAgentAPIRequestHeaders = headers
+}
+
+// requestHeaders returns the headers to send with the requests, updated
+// with the current auth token
+func (c *DCAClient) requestHeaders() http.Header {
+	c.headersMutex.Lock()
+	defer c.headersMutex.Unlock()
+	if c.authToken == nil {
+		return c.clusterAgentAPIRequestHeaders
+	}
+
+	authToken := c.authToken.Get()
+	if c.clusterAgentAPIRequestHeaders.Get(authorizationHeaderKey) != fmt.Sprintf("Bearer %s", authToken) {
+		c.setRequestHeaders(authToken)
+	}
+	return c.clusterAgentAPIRequestHeaders
+}
+
 // Version returns ClusterAgentVersion already stored in the DCAClient
 func (c *DCAClient) Version() version.Version {
 	return c.ClusterAgentVersion
@@ -209,7 +236,7 @@ func (c *DCAClient) GetVersion() (version.Version, error) {
 	if err != nil {
 		return version, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
 
 	resp, err := c.clusterAgentAPIClient.Do(req)
 	if err != nil {
@@ -244,7 +271,40 @@ func (c *DCAClient) GetNodeLabels(nodeName string) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
+
+	resp, err := c.clusterAgentAPIClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, &labels)
+	return labels, err
+}
+
+// GetNamespaceLabels returns the namespace labels from the Cluster Agent.
+func (c *DCAClient) GetNamespaceLabels(ns string) (map[string]string, error) {
+	const dcaNamespaceMeta = "api/v1/tags/namespace"
+	var err error
+	var labels map[string]string
+
+	// https://host:port/api/v1/tags/namespace/{ns}
+	rawURL := fmt.Sprintf("%s/%s/%s", c.clusterAgentAPIEndpoint, dcaNamespaceMeta, ns)
+
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.requestHeaders()
 
 	resp, err := c.clusterAgentAPIClient.Do(req)
 	if err != nil {
@@ -300,7 +360,7 @@ func (c *DCAClient) GetPodsMetadataForNode(nodeName string) (apiv1.NamespacesPod
 	if err != nil {
 		return nil, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
 
 	resp, err := c.clusterAgentAPIClient.Do(req)
 	if err != nil {
@@ -346,7 +406,7 @@ func (c *DCAClient) GetKubernetesMetadataNames(nodeName, ns, podName string) ([]
 	if err != nil {
 		return metadataNames, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
 
 	resp, err := c.clusterAgentAPIClient.Do(req)
 	if err != nil {
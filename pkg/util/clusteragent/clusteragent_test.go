@@ -86,7 +86,8 @@ func newDummyClusterAgent() (*dummyClusterAgent, error) {
 			},
 		},
 		rawResponses: map[string]string{
-			"/version": `{"Major":0, "Minor":0, "Patch":0, "Pre":"test", "Meta":"test", "Commit":"1337"}`,
+			"/version":                   `{"Major":0, "Minor":0, "Patch":0, "Pre":"test", "Meta":"test", "Commit":"1337"}`,
+			"/api/v1/tags/namespace/foo": `{"team":"infra","env":"prod"}`,
 		},
 		token:    config.Datadog.GetString("cluster_agent.auth_token"),
 		requests: make(chan *http.Request, 100),
@@ -120,6 +121,12 @@ func (d *dummyClusterAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, url.String(), http.StatusFound)
 	}
 
+	// Handle the versioned cluster tags
+	if r.URL.Path == "/api/v1/tags/cluster" && r.Header.Get("If-None-Match") == `"1"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	// Handle raw responses if listed
 	d.RLock()
 	response, found := d.rawResponses[r.URL.Path]
@@ -414,6 +421,79 @@ func (suite *clusterAgentSuite) TestGetKubernetesNodeLabels() {
 	}
 }
 
+func (suite *clusterAgentSuite) TestGetNamespaceLabels() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	mockConfig.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	labels, err := ca.GetNamespaceLabels("foo")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), map[string]string{"team": "infra", "env": "prod"}, labels)
+
+	_, err = ca.GetNamespaceLabels("fake")
+	assert.Equal(suite.T(), fmt.Errorf("unexpected status code from cluster agent: 404"), err)
+}
+
+func (suite *clusterAgentSuite) TestGetClusterTags() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	dca.rawResponses["/api/v1/tags/cluster"] = `{"version":"1","tags":["kube_cluster_name:foo"],"namespace_labels":{"default":{"team":"infra"}}}`
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	mockConfig.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	for dca.PopRequest() != nil {
+	}
+
+	expected := apiv1.ClusterTags{
+		Version:         "1",
+		Tags:            []string{"kube_cluster_name:foo"},
+		NamespaceLabels: map[string]map[string]string{"default": {"team": "infra"}},
+	}
+	clusterTags, err := ca.GetClusterTags()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), expected, clusterTags)
+	r := dca.PopRequest()
+	require.NotNil(suite.T(), r)
+	assert.Equal(suite.T(), "", r.Header.Get("If-None-Match"))
+
+	// the tags are cached
+	clusterTags, err = ca.GetClusterTags()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), expected, clusterTags)
+	assert.Nil(suite.T(), dca.PopRequest())
+
+	// then revalidated with their version
+	globalClusterAgentClient.clusterTags.lastFetch = time.Time{}
+	clusterTags, err = ca.GetClusterTags()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), expected, clusterTags)
+	r = dca.PopRequest()
+	require.NotNil(suite.T(), r)
+	assert.Equal(suite.T(), `"1"`, r.Header.Get("If-None-Match"))
+	assert.Equal(suite.T(), "", globalClusterAgentClient.clusterAgentAPIRequestHeaders.Get("If-None-Match"))
+
+	// the last tags are kept while the cluster agent can't be reached
+	ts.Close()
+	globalClusterAgentClient.clusterTags.lastFetch = time.Time{}
+	clusterTags, err = ca.GetClusterTags()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), expected, clusterTags)
+}
+
 func (suite *clusterAgentSuite) TestGetKubernetesMetadataNames() {
 	dca, err := newDummyClusterAgent()
 	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
@@ -50,7 +50,7 @@ func (c *DCAClient) doPostClusterCheckStatus(nodeName string, status types.NodeS
 	if err != nil {
 		return response, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
 
 	resp, err := c.leaderClient.Do(req)
 	if err != nil {
@@ -94,7 +94,7 @@ func (c *DCAClient) doGetClusterCheckConfigs(nodeName string) (types.ConfigRespo
 	if err != nil {
 		return configs, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
 
 	resp, err := c.leaderClient.Do(req)
 	if err != nil {
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package clusteragent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apiv1 "github.com/DataDog/datadog-agent/pkg/clusteragent/api/v1"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// clusterTagsRefreshInterval is the minimum interval between two queries of
+// the cluster tags, the tags are only sent back when their version changed.
+const clusterTagsRefreshInterval = time.Minute
+
+// clusterTagsCache holds the last cluster tags received from the Cluster Agent.
+type clusterTagsCache struct {
+	sync.Mutex
+	tags      apiv1.ClusterTags
+	err       error
+	lastFetch time.Time
+}
+
+// GetClusterTags returns the tags common to all the nodes of the cluster from
+// the Cluster Agent. They are cached, and the last tags received are kept
+// while the Cluster Agent can't be reached.
+func (c *DCAClient) GetClusterTags() (apiv1.ClusterTags, error) {
+	c.clusterTags.Lock()
+	defer c.clusterTags.Unlock()
+
+	if time.Since(c.clusterTags.lastFetch) < clusterTagsRefreshInterval {
+		return c.clusterTags.tags, c.clusterTags.err
+	}
+	c.clusterTags.lastFetch = time.Now()
+
+	tags, err := c.doGetClusterTags(c.clusterTags.tags.Version)
+	if err != nil {
+		log.Debugf("Could not refresh the cluster tags: %v", err)
+		if c.clusterTags.tags.Version == "" {
+			c.clusterTags.err = err
+		}
+		return c.clusterTags.tags, c.clusterTags.err
+	}
+	if tags != nil {
+		log.Debugf("Cluster tags updated to version %s", tags.Version)
+		c.clusterTags.tags = *tags
+	}
+	c.clusterTags.err = nil
+	return c.clusterTags.tags, nil
+}
+
+// doGetClusterTags queries the cluster tags, nil is returned when they didn't
+// change since version.
+func (c *DCAClient) doGetClusterTags(version string) (*apiv1.ClusterTags, error) {
+	const dcaClusterTagsPath = "api/v1/tags/cluster"
+
+	// https://host:port/api/v1/tags/cluster
+	rawURL := fmt.Sprintf("%s/%s", c.clusterAgentAPIEndpoint, dcaClusterTagsPath)
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// copy the headers to not leak If-None-Match into the other requests
+	req.Header = http.Header{}
+	for key, values := range c.requestHeaders() {
+		req.Header[key] = values
+	}
+	if version != "" {
+		req.Header.Set("If-None-Match", strconv.Quote(version))
+	}
+
+	resp, err := c.clusterAgentAPIClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	tags := &apiv1.ClusterTags{}
+	if err = json.NewDecoder(resp.Body).Decode(tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
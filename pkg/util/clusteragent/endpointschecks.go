@@ -44,7 +44,7 @@ func (c *DCAClient) doGetEndpointsCheckConfigs(nodeName string) (types.ConfigRes
 	if err != nil {
 		return configs, err
 	}
-	req.Header = c.clusterAgentAPIRequestHeaders
+	req.Header = c.requestHeaders()
 
 	resp, err := c.leaderClient.Do(req)
 	if err != nil {
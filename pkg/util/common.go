@@ -6,7 +6,6 @@
 package util
 
 import (
-	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -171,6 +170,9 @@ func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error
 				log.Error(err.Error())
 				return nil, err
 			}
+			if proxyURL.User == nil && p.Username != "" {
+				proxyURL.User = url.UserPassword(p.Username, p.Password)
+			}
 			userInfo := ""
 			if proxyURL.User != nil {
 				if _, isSet := proxyURL.User.Password(); isSet {
@@ -191,12 +193,12 @@ func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error
 
 // CreateHTTPTransport creates an *http.Transport for use in the agent
 func CreateHTTPTransport() *http.Transport {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
-	}
-
-	if config.Datadog.GetBool("force_tls_12") {
-		tlsConfig.MinVersion = tls.VersionTLS12
+	tlsConfig, err := buildTLSConfig()
+	if err != nil {
+		// the agent refuses to start with invalid TLS settings, the other
+		// processes refuse to connect with them
+		log.Errorf("Invalid TLS configuration, the TLS connections will fail: %s", err)
+		tlsConfig = refusingTLSConfig(err)
 	}
 
 	// Most of the following timeouts are a copy of Golang http.DefaultTransport
@@ -221,8 +223,22 @@ func CreateHTTPTransport() *http.Transport {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
-	if proxies := config.GetProxies(); proxies != nil {
-		transport.Proxy = GetProxyTransportFunc(proxies)
+	if proxies := config.GetProxies(); proxies != nil && IsConnectionAuth(proxies.Auth) {
+		setupProxyDialer(transport, proxies)
+	} else {
+		// the proxy settings are read for every request, so that the
+		// transports use them as soon as they are reloaded
+		transport.Proxy = proxyFromConfig
 	}
 	return transport
 }
+
+// proxyFromConfig returns the proxy to use for a request with the current
+// proxy settings
+func proxyFromConfig(r *http.Request) (*url.URL, error) {
+	proxies := config.GetProxies()
+	if proxies == nil {
+		return nil, nil
+	}
+	return GetProxyTransportFunc(proxies)(r)
+}
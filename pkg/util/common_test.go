@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"testing"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 
 	"gopkg.in/yaml.v2"
 )
@@ -98,6 +100,28 @@ func TestBadScheme(t *testing.T) {
 	assert.Nil(t, proxyURL)
 }
 
+func TestDiagnoseProxies(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	domains := []string{"https://app.datadoghq.com"}
+
+	proxies := &config.Proxy{HTTPS: "http://" + l.Addr().String()}
+	assert.NoError(t, diagnoseProxies(proxies, domains))
+
+	proxies = &config.Proxy{HTTPS: "proxy.com"}
+	err = diagnoseProxies(proxies, domains)
+	require.IsType(t, &diagnosis.Failure{}, err)
+	assert.Contains(t, err.Error(), "invalid https proxy URL")
+
+	addr := l.Addr().String()
+	l.Close()
+	proxies = &config.Proxy{HTTP: "http://" + addr}
+	err = diagnoseProxies(proxies, domains)
+	require.IsType(t, &diagnosis.Failure{}, err)
+	assert.Contains(t, err.Error(), "unable to connect to the http proxy")
+}
+
 func TestJSONConverter(t *testing.T) {
 
 	checks := []string{
@@ -159,3 +183,22 @@ func TestCreateHTTPTransport(t *testing.T) {
 	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
 	assert.Equal(t, transport.TLSClientConfig.MinVersion, uint16(tls.VersionTLS12))
 }
+
+func TestCreateHTTPTransportReloadedProxy(t *testing.T) {
+	config.Mock()
+	defer config.ReloadProxies(nil)
+
+	r, err := http.NewRequest("GET", "https://test.com", nil)
+	require.Nil(t, err)
+	transport := CreateHTTPTransport()
+
+	config.ReloadProxies(&config.Proxy{HTTPS: "https://proxy:3128"})
+	proxyURL, err := transport.Proxy(r)
+	require.Nil(t, err)
+	assert.Equal(t, "https://proxy:3128", proxyURL.String())
+
+	config.ReloadProxies(nil)
+	proxyURL, err = transport.Proxy(r)
+	assert.Nil(t, err)
+	assert.Nil(t, proxyURL)
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package compression
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Compression kinds, zlib and zstd are only available when the agent is
+// built with the corresponding build tag
+const (
+	NoneKind = "none"
+	ZlibKind = "zlib"
+	ZstdKind = "zstd"
+)
+
+// DefaultLevel lets the compression method choose its default level
+const DefaultLevel = 0
+
+// compressor implements a compression method
+type compressor interface {
+	compress(dst []byte, src []byte, level int) ([]byte, error)
+	decompress(dst []byte, src []byte) ([]byte, error)
+	compressBound(sourceLen int) int
+	contentEncoding() string
+}
+
+var (
+	compressors = map[string]compressor{
+		NoneKind: noneCompressor{},
+	}
+
+	// kinds in order of preference when none is configured
+	defaultKinds = []string{ZlibKind, ZstdKind, NoneKind}
+
+	current      compressor
+	currentKind  string
+	currentLevel = DefaultLevel
+
+	// ContentEncoding describes the HTTP header value associated with the
+	// compression method. Empty when payloads are not compressed.
+	// var instead of const to ease testing
+	ContentEncoding string
+)
+
+func init() {
+	useDefault()
+}
+
+// registerCompressor makes a compression method available, it's called by
+// the init functions of the build-tagged implementations
+func registerCompressor(kind string, c compressor) {
+	compressors[kind] = c
+	useDefault()
+}
+
+// useDefault selects the preferred compression method available
+func useDefault() {
+	for _, kind := range defaultKinds {
+		if _, found := compressors[kind]; found {
+			use(kind, DefaultLevel)
+			return
+		}
+	}
+}
+
+func use(kind string, level int) {
+	current = compressors[kind]
+	currentKind = kind
+	currentLevel = level
+	ContentEncoding = current.contentEncoding()
+}
+
+// Configure selects the compression method and level used for payloads. An
+// empty kind keeps the default method of this build.
+func Configure(kind string, level int) error {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		kind = currentKind
+	}
+	if _, found := compressors[kind]; !found {
+		return fmt.Errorf("compression kind %q is not available in this build, available kinds: %s", kind, strings.Join(AvailableKinds(), ", "))
+	}
+	use(kind, level)
+	return nil
+}
+
+// Kind returns the compression method in use
+func Kind() string {
+	return currentKind
+}
+
+// AvailableKinds returns the compression methods available in this build
+func AvailableKinds() []string {
+	kinds := make([]string, 0, len(compressors))
+	for kind := range compressors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Compress will compress the data with the configured compression method
+func Compress(dst []byte, src []byte) ([]byte, error) {
+	return current.compress(dst, src, currentLevel)
+}
+
+// Decompress will decompress the data with the configured compression method
+func Decompress(dst []byte, src []byte) ([]byte, error) {
+	return current.decompress(dst, src)
+}
+
+// CompressBound returns the worst case size needed for a destination buffer
+func CompressBound(sourceLen int) int {
+	return current.compressBound(sourceLen)
+}
+
+// noneCompressor does not compress anything
+type noneCompressor struct{}
+
+func (noneCompressor) compress(dst []byte, src []byte, level int) ([]byte, error) {
+	dst = src
+	return dst, nil
+}
+
+func (noneCompressor) decompress(dst []byte, src []byte) ([]byte, error) {
+	dst = src
+	return dst, nil
+}
+
+func (noneCompressor) compressBound(sourceLen int) int {
+	return sourceLen
+}
+
+func (noneCompressor) contentEncoding() string {
+	return ""
+}
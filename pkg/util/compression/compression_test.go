@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	initialKind := Kind()
+	defer Configure(initialKind, DefaultLevel)
+
+	// empty kind keeps the default one
+	require.NoError(t, Configure("", DefaultLevel))
+	assert.Equal(t, initialKind, Kind())
+
+	require.NoError(t, Configure(" NONE ", DefaultLevel))
+	assert.Equal(t, NoneKind, Kind())
+	assert.Equal(t, "", ContentEncoding)
+
+	assert.Error(t, Configure("lzma", DefaultLevel))
+	assert.Equal(t, NoneKind, Kind())
+}
+
+func TestRoundTrip(t *testing.T) {
+	initialKind := Kind()
+	defer use(initialKind, DefaultLevel)
+
+	payload := bytes.Repeat([]byte(`{"metric":"system.load.1","points":[[1558000000,0.5]]},`), 100)
+	for _, kind := range AvailableKinds() {
+		for _, level := range []int{DefaultLevel, 1} {
+			use(kind, level)
+
+			compressed, err := Compress(nil, payload)
+			require.NoError(t, err, kind)
+			assert.True(t, len(compressed) <= CompressBound(len(payload)), kind)
+			if kind != NoneKind {
+				assert.True(t, len(compressed) < len(payload), kind)
+			}
+
+			decompressed, err := Decompress(nil, compressed)
+			require.NoError(t, err, kind)
+			assert.Equal(t, payload, decompressed, kind)
+		}
+	}
+}
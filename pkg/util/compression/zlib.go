@@ -13,15 +13,23 @@ import (
 	"io/ioutil"
 )
 
-// ContentEncoding describes the HTTP header value associated with the compression method
-// var instead of const to ease testing
-var ContentEncoding = "deflate"
+func init() {
+	registerCompressor(ZlibKind, zlibCompressor{})
+}
+
+type zlibCompressor struct{}
 
-// Compress will compress the data with zlib
-func Compress(dst []byte, src []byte) ([]byte, error) {
+// compress will compress the data with zlib
+func (zlibCompressor) compress(dst []byte, src []byte, level int) ([]byte, error) {
+	if level == DefaultLevel {
+		level = zlib.DefaultCompression
+	}
 	var b bytes.Buffer
-	w := zlib.NewWriter(&b)
-	_, err := w.Write(src)
+	w, err := zlib.NewWriterLevel(&b, level)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(src)
 	if err != nil {
 		return nil, err
 	}
@@ -33,8 +41,8 @@ func Compress(dst []byte, src []byte) ([]byte, error) {
 	return dst, nil
 }
 
-// Decompress will decompress the data with zlib
-func Decompress(dst []byte, src []byte) ([]byte, error) {
+// decompress will decompress the data with zlib
+func (zlibCompressor) decompress(dst []byte, src []byte) ([]byte, error) {
 	r, err := zlib.NewReader(bytes.NewReader(src))
 	if err != nil {
 		return nil, err
@@ -48,8 +56,12 @@ func Decompress(dst []byte, src []byte) ([]byte, error) {
 	return dst, nil
 }
 
-//  CompressBound returns the worst case size needed for a destination buffer
-func CompressBound(sourceLen int) int {
+// compressBound returns the worst case size needed for a destination buffer
+func (zlibCompressor) compressBound(sourceLen int) int {
 	// From https://code.woboq.org/gcc/zlib/compress.c.html#compressBound
 	return sourceLen + (sourceLen >> 12) + (sourceLen >> 14) + (sourceLen >> 25) + 13
 }
+
+func (zlibCompressor) contentEncoding() string {
+	return "deflate"
+}
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build zlib
+
+package compression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZlibIsDefault(t *testing.T) {
+	initialKind := Kind()
+	defer Configure(initialKind, DefaultLevel)
+
+	useDefault()
+	assert.Equal(t, ZlibKind, Kind())
+	assert.Equal(t, "deflate", ContentEncoding)
+
+	// invalid levels are reported
+	require.NoError(t, Configure(ZlibKind, 42))
+	_, err := Compress(nil, []byte("foo"))
+	assert.Error(t, err)
+}
@@ -11,24 +11,34 @@ import (
 	"github.com/DataDog/zstd"
 )
 
-// TODO: the intake still uses a pre-v1 (unstable) version of the zstd compression format.
-// The agent shouldn't use zstd compression until the intake supports a stable v1 format.
+// The payloads are compressed with the v1 zstd format of the vendored
+// DataDog/zstd release, the zstd build tag is only included explicitly in the
+// builds sending to an intake that accepts it.
 
-// ContentEncoding describes the HTTP header value associated with the compression method
-// var instead of const to ease testing
-var ContentEncoding = "zstd"
+func init() {
+	registerCompressor(ZstdKind, zstdCompressor{})
+}
+
+type zstdCompressor struct{}
 
-// Compress will compress the data with zstd
-func Compress(dst []byte, src []byte) ([]byte, error) {
-	return zstd.Compress(dst, src)
+// compress will compress the data with zstd
+func (zstdCompressor) compress(dst []byte, src []byte, level int) ([]byte, error) {
+	if level == DefaultLevel {
+		level = zstd.DefaultCompression
+	}
+	return zstd.CompressLevel(dst, src, level)
 }
 
-// Decompress will decompress the data with zstd
-func Decompress(dst []byte, src []byte) ([]byte, error) {
+// decompress will decompress the data with zstd
+func (zstdCompressor) decompress(dst []byte, src []byte) ([]byte, error) {
 	return zstd.Decompress(dst, src)
 }
 
-// CompressBound returns the worst case size needed for a destination buffer
-func CompressBound(sourceLen int) int {
+// compressBound returns the worst case size needed for a destination buffer
+func (zstdCompressor) compressBound(sourceLen int) int {
 	return zstd.CompressBound(sourceLen)
 }
+
+func (zstdCompressor) contentEncoding() string {
+	return "zstd"
+}
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build zstd
+
+package compression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureZstd(t *testing.T) {
+	initialKind := Kind()
+	defer Configure(initialKind, DefaultLevel)
+
+	require.NoError(t, Configure(" ZSTD ", 19))
+	assert.Equal(t, ZstdKind, Kind())
+	assert.Equal(t, "zstd", ContentEncoding)
+
+	compressed, err := Compress(nil, []byte("foo"))
+	require.NoError(t, err)
+	decompressed, err := Decompress(nil, compressed)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(decompressed))
+}
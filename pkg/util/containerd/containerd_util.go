@@ -33,14 +33,16 @@ var (
 )
 
 // ContainerdItf is the interface implementing a subset of methods that leverage the Containerd api.
+// The queries of the namespaced resources are run against the namespace they're given.
 type ContainerdItf interface {
-	Containers() ([]containerd.Container, error)
+	Containers(namespace string) ([]containerd.Container, error)
 	GetEvents() containerd.EventService
-	Info(ctn containerd.Container) (containers.Container, error)
-	ImageSize(ctn containerd.Container) (int64, error)
+	Info(namespace string, ctn containerd.Container) (containers.Container, error)
+	ImageSize(namespace string, ctn containerd.Container) (int64, error)
+	ListImages(namespace string) ([]containerd.Image, error)
 	Metadata() (containerd.Version, error)
 	Namespace() string
-	TaskMetrics(ctn containerd.Container) (*types.Metric, error)
+	TaskMetrics(namespace string, ctn containerd.Container) (*types.Metric, error)
 }
 
 // ContainerdUtil is the util used to interact with the Containerd api.
@@ -83,6 +85,7 @@ func GetContainerdUtil() (ContainerdItf, error) {
 	return globalContainerdUtil, nil
 }
 
+// Namespace returns the namespace set with `containerd_namespace`
 func (c *ContainerdUtil) Namespace() string {
 	return c.namespace
 }
@@ -131,19 +134,19 @@ func (c *ContainerdUtil) GetEvents() containerd.EventService {
 	return c.cl.EventService()
 }
 
-// Containers interfaces with the containerd api to get the list of Containers.
-func (c *ContainerdUtil) Containers() ([]containerd.Container, error) {
+// Containers interfaces with the containerd api to get the list of Containers of a namespace.
+func (c *ContainerdUtil) Containers(namespace string) ([]containerd.Container, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
 	defer cancel()
-	ctxNamespace := namespaces.WithNamespace(ctx, c.namespace)
+	ctxNamespace := namespaces.WithNamespace(ctx, namespace)
 	return c.cl.Containers(ctxNamespace)
 }
 
 // ImageSize interfaces with the containerd api to get the size of an image
-func (c *ContainerdUtil) ImageSize(ctn containerd.Container) (int64, error) {
+func (c *ContainerdUtil) ImageSize(namespace string, ctn containerd.Container) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
 	defer cancel()
-	ctxNamespace := namespaces.WithNamespace(ctx, c.namespace)
+	ctxNamespace := namespaces.WithNamespace(ctx, namespace)
 
 	img, err := ctn.Image(ctxNamespace)
 	if err != nil {
@@ -152,20 +155,28 @@ func (c *ContainerdUtil) ImageSize(ctn containerd.Container) (int64, error) {
 	return img.Size(ctxNamespace)
 }
 
+// ListImages interfaces with the containerd api to get the list of images of a namespace
+func (c *ContainerdUtil) ListImages(namespace string) ([]containerd.Image, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	ctxNamespace := namespaces.WithNamespace(ctx, namespace)
+	return c.cl.ListImages(ctxNamespace)
+}
+
 // Info interfaces with the containerd api to get Container info
-func (c *ContainerdUtil) Info(ctn containerd.Container) (containers.Container, error) {
+func (c *ContainerdUtil) Info(namespace string, ctn containerd.Container) (containers.Container, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
 	defer cancel()
-	ctxNamespace := namespaces.WithNamespace(ctx, c.namespace)
+	ctxNamespace := namespaces.WithNamespace(ctx, namespace)
 
 	return ctn.Info(ctxNamespace)
 }
 
 // TaskMetrics interfaces with the containerd api to get the metrics from a container
-func (c *ContainerdUtil) TaskMetrics(ctn containerd.Container) (*types.Metric, error) {
+func (c *ContainerdUtil) TaskMetrics(namespace string, ctn containerd.Container) (*types.Metric, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
 	defer cancel()
-	ctxNamespace := namespaces.WithNamespace(ctx, c.namespace)
+	ctxNamespace := namespaces.WithNamespace(ctx, namespace)
 
 	t, errTask := ctn.Task(ctxNamespace, nil)
 	if errTask != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/containerd/containerd/api/types"
 	"github.com/containerd/containerd/cio"
 	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/namespaces"
 	"github.com/containerd/typeurl"
 	prototypes "github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/require"
@@ -28,7 +29,7 @@ type mockContainer struct {
 	mockTask   func() (containerd.Task, error)
 	mockImage  func() (containerd.Image, error)
 	mockLabels func() (map[string]string, error)
-	mockInfo   func() (containers.Container, error)
+	mockInfo   func(ctx context.Context) (containers.Container, error)
 }
 
 // Task is from the containerd.Container interface
@@ -47,8 +48,8 @@ func (cs *mockContainer) Labels(context.Context) (map[string]string, error) {
 }
 
 // Info is from the containerd.Container interface
-func (cs *mockContainer) Info(context.Context) (containers.Container, error) {
-	return cs.mockInfo()
+func (cs *mockContainer) Info(ctx context.Context) (containers.Container, error) {
+	return cs.mockInfo(ctx)
 }
 
 type mockTaskStruct struct {
@@ -75,17 +76,21 @@ func (i *mockImage) Size(ctx context.Context) (int64, error) {
 func TestInfo(t *testing.T) {
 	mockUtil := ContainerdUtil{}
 	cs := &mockContainer{
-		mockInfo: func() (containers.Container, error) {
+		mockInfo: func(ctx context.Context) (containers.Container, error) {
+			// the query is run against the given namespace
+			ns, _ := namespaces.Namespace(ctx)
 			ctn := containers.Container{
-				Image: "foo",
+				Image:  "foo",
+				Labels: map[string]string{"namespace": ns},
 			}
 			return ctn, nil
 		},
 	}
 	ctn := containerd.Container(cs)
-	c, err := mockUtil.Info(ctn)
+	c, err := mockUtil.Info("moby", ctn)
 	require.NoError(t, err)
 	require.Equal(t, "foo", c.Image)
+	require.Equal(t, "moby", c.Labels["namespace"])
 }
 
 func TestImageSize(t *testing.T) {
@@ -99,7 +104,7 @@ func TestImageSize(t *testing.T) {
 		},
 	}
 	ctn := containerd.Container(cs)
-	c, err := mockUtil.ImageSize(ctn)
+	c, err := mockUtil.ImageSize("k8s.io", ctn)
 	require.NoError(t, err)
 	require.Equal(t, int64(12), c)
 }
@@ -164,7 +169,7 @@ func TestTaskMetrics(t *testing.T) {
 
 			cton := containerd.Container(ctn)
 
-			m, e := mockUtil.TaskMetrics(cton)
+			m, e := mockUtil.TaskMetrics("k8s.io", cton)
 			if e != nil {
 				require.Equal(t, e, test.taskMetricError)
 				return
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build cri,linux
+
+package collectors
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/cri"
+)
+
+const (
+	criCollectorName = "cri"
+)
+
+// CRICollector lists containers from the CRI socket and populates
+// performance metric from the linux cgroups. It is used on the hosts
+// running containerd or cri-o without docker.
+type CRICollector struct {
+	criUtil *cri.CRIUtil
+}
+
+// Detect tries to connect to the CRI socket
+func (c *CRICollector) Detect() error {
+	util, err := cri.GetUtil()
+	if err != nil {
+		return err
+	}
+	c.criUtil = util
+	return nil
+}
+
+// List gets all running containers
+func (c *CRICollector) List() ([]*containers.Container, error) {
+	return c.criUtil.ListRunningContainers()
+}
+
+// UpdateMetrics updates metrics on an existing list of containers
+func (c *CRICollector) UpdateMetrics(cList []*containers.Container) error {
+	return c.criUtil.UpdateContainerMetrics(cList)
+}
+
+func criFactory() Collector {
+	return &CRICollector{}
+}
+
+func init() {
+	registerCollector(criCollectorName, criFactory, NodeFallback)
+}
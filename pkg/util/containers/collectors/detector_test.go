@@ -234,6 +234,30 @@ func (suite *DetectorTestSuite) TestConfigureTwoByPrio() {
 	assert.Nil(suite.T(), d.detected)
 }
 
+// TestConfigureFallback makes sure the fallback collectors
+// are only used when no other collector is available
+func (suite *DetectorTestSuite) TestConfigureFallback() {
+	one := registerMock("one", NodeFallback)
+	one.On("Detect").Return(nil).Once()
+	two := registerMock("two", NodeOrchestrator)
+	two.On("Detect").Return(willRetryError).Once()
+	two.On("Detect").Return(nil).Once()
+
+	d := NewDetector("")
+
+	// First run only detects the fallback collector
+	c, n, err := d.GetPreferred()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "one", n)
+	assert.Equal(suite.T(), one, c)
+
+	// Second run detects two and uses it instead
+	c, n, err = d.GetPreferred()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "two", n)
+	assert.Equal(suite.T(), two, c)
+}
+
 func TestDetectorTestSuite(t *testing.T) {
 	suite.Run(t, new(DetectorTestSuite))
 }
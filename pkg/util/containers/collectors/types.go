@@ -18,8 +18,9 @@ type Collector interface {
 type CollectorPriority int
 
 // List of collector priorities
-// Order is reverse from the tagger: docker > kubelet
+// Order is reverse from the tagger: docker > kubelet > cri
 const (
-	NodeOrchestrator CollectorPriority = iota
+	NodeFallback CollectorPriority = iota
+	NodeOrchestrator
 	NodeRuntime
 )
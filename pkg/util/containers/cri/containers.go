@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build cri,linux
+
+package cri
+
+import (
+	"fmt"
+	"time"
+
+	pb "k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// kubeNamespaceLabel is set by the kubelet on the containers of the pods
+const kubeNamespaceLabel = "io.kubernetes.pod.namespace"
+
+// ListRunningContainers lists all non-excluded running containers from the
+// CRI, and retrieves their performance metrics from the linux cgroups
+func (c *CRIUtil) ListRunningContainers() ([]*containers.Container, error) {
+	criContainers, err := c.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("could not list containers: %s", err)
+	}
+
+	filter, err := containers.GetSharedFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	cgByContainer, err := metrics.ScrapeAllCgroups()
+	if err != nil {
+		return nil, fmt.Errorf("could not get cgroups: %s", err)
+	}
+
+	var ctrList []*containers.Container
+
+	for _, ctr := range criContainers {
+		container := parseContainer(ctr, c.Runtime)
+		if container == nil {
+			// Only running containers have cgroups
+			log.Tracef("Skipping container %s in state %s", ctr.Id, ctr.State)
+			continue
+		}
+		if filter.IsExcluded(container.Name, container.Image, ctr.Labels[kubeNamespaceLabel]) {
+			continue
+		}
+		cgroup, ok := cgByContainer[container.ID]
+		if !ok {
+			log.Debugf("No cgroup found for container %s, skipping", container.ID)
+			continue
+		}
+		container.SetCgroups(cgroup)
+		ctrList = append(ctrList, container)
+
+		err = container.FillCgroupLimits()
+		if err != nil {
+			log.Debugf("Cannot get limits for container %s: %s, skipping", container.ID, err)
+			continue
+		}
+	}
+	err = c.UpdateContainerMetrics(ctrList)
+	return ctrList, err
+}
+
+// UpdateContainerMetrics updates cgroup / network performance metrics for
+// a provided list of Container objects
+func (c *CRIUtil) UpdateContainerMetrics(ctrList []*containers.Container) error {
+	for _, container := range ctrList {
+		err := container.FillCgroupMetrics()
+		if err != nil {
+			log.Debugf("Cannot get metrics for container %s: %s", container.ID, err)
+			continue
+		}
+		err = container.FillNetworkMetrics(nil)
+		if err != nil {
+			log.Debugf("Cannot get network stats for container %s: %s", container.ID, err)
+			continue
+		}
+	}
+	return nil
+}
+
+// parseContainer converts a running CRI container, it returns nil for the
+// containers in other states
+func parseContainer(ctr *pb.Container, runtime string) *containers.Container {
+	if ctr.State != pb.ContainerState_CONTAINER_RUNNING {
+		return nil
+	}
+	c := &containers.Container{
+		Type:     "cri",
+		ID:       ctr.Id,
+		EntityID: containers.BuildEntityName(runtime, ctr.Id),
+		ImageID:  ctr.ImageRef,
+		State:    containers.ContainerRunningState,
+		Health:   containers.ContainerUnknownHealth,
+		Created:  time.Unix(0, ctr.CreatedAt).Unix(),
+	}
+	if ctr.Metadata != nil {
+		c.Name = ctr.Metadata.Name
+	}
+	if ctr.Image != nil {
+		c.Image = ctr.Image.Image
+	}
+	return c
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build cri,linux
+
+package cri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func TestParseContainer(t *testing.T) {
+	ctr := &pb.Container{
+		Id:       "3e8fb8fa3a4b7ca4fa6b4a1a9c5e3f0f6c2c5a1cbbf5a9d2bd1c1e9f4d1e3b8c",
+		Metadata: &pb.ContainerMetadata{Name: "redis"},
+		Image:    &pb.ImageSpec{Image: "docker.io/library/redis:5"},
+		ImageRef: "sha256:0f88f9be5839",
+		State:    pb.ContainerState_CONTAINER_RUNNING,
+		// 2019-05-20T14:30:00.5Z
+		CreatedAt: 1558362600500000000,
+	}
+
+	assert.Equal(t, &containers.Container{
+		Type:     "cri",
+		ID:       "3e8fb8fa3a4b7ca4fa6b4a1a9c5e3f0f6c2c5a1cbbf5a9d2bd1c1e9f4d1e3b8c",
+		EntityID: "containerd://3e8fb8fa3a4b7ca4fa6b4a1a9c5e3f0f6c2c5a1cbbf5a9d2bd1c1e9f4d1e3b8c",
+		Name:     "redis",
+		Image:    "docker.io/library/redis:5",
+		ImageID:  "sha256:0f88f9be5839",
+		State:    containers.ContainerRunningState,
+		Health:   containers.ContainerUnknownHealth,
+		Created:  1558362600,
+	}, parseContainer(ctr, containers.RuntimeNameContainerd))
+
+	for _, state := range []pb.ContainerState{
+		pb.ContainerState_CONTAINER_CREATED,
+		pb.ContainerState_CONTAINER_EXITED,
+		pb.ContainerState_CONTAINER_UNKNOWN,
+	} {
+		ctr.State = state
+		assert.Nil(t, parseContainer(ctr, containers.RuntimeNameContainerd), state.String())
+	}
+}
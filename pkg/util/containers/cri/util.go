@@ -97,6 +97,18 @@ func GetUtil() (*CRIUtil, error) {
 	return globalCRIUtil, nil
 }
 
+// ListContainers sends a ListContainersRequest to the server, and returns every container, whatever its state
+func (c *CRIUtil) ListContainers() ([]*pb.Container, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	request := &pb.ListContainersRequest{Filter: &pb.ContainerFilter{}}
+	r, err := c.client.ListContainers(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetContainers(), nil
+}
+
 // ListContainerStats sends a ListContainerStatsRequest to the server, and parses the returned response
 func (c *CRIUtil) ListContainerStats() (map[string]*pb.ContainerStats, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 const (
@@ -28,39 +29,58 @@ const (
 	// - gcrio.azureedge.net/google_containers/pause-amd64
 	pauseContainerAzure   = `image:(.*)azureedge\.net(/google_containers/|/)pause(.*)`
 	pauseContainerRancher = `image:rancher/pause(.*)`
+
+	// KubeNamespaceLabel is the label set by the kubelet on docker containers,
+	// holding the namespace of their pod. It allows to filter docker containers
+	// on their kubernetes namespace.
+	KubeNamespaceLabel = "io.kubernetes.pod.namespace"
+)
+
+const (
+	imageFilterPrefix         = "image:"
+	nameFilterPrefix          = "name:"
+	kubeNamespaceFilterPrefix = "kube_namespace:"
 )
 
 // Filter holds the state for the container filtering logic
 type Filter struct {
-	Enabled        bool
-	ImageWhitelist []*regexp.Regexp
-	NameWhitelist  []*regexp.Regexp
-	ImageBlacklist []*regexp.Regexp
-	NameBlacklist  []*regexp.Regexp
+	Enabled            bool
+	ImageWhitelist     []*regexp.Regexp
+	NameWhitelist      []*regexp.Regexp
+	NamespaceWhitelist []*regexp.Regexp
+	ImageBlacklist     []*regexp.Regexp
+	NameBlacklist      []*regexp.Regexp
+	NamespaceBlacklist []*regexp.Regexp
 }
 
 var sharedFilter *Filter
 
-func parseFilters(filters []string) (imageFilters, nameFilters []*regexp.Regexp, err error) {
+func parseFilters(filters []string) (imageFilters, nameFilters, namespaceFilters []*regexp.Regexp, err error) {
 	for _, filter := range filters {
+		var pat string
+		var target *[]*regexp.Regexp
 		switch {
-		case strings.HasPrefix(filter, "image:"):
-			pat := strings.TrimPrefix(filter, "image:")
-			r, err := regexp.Compile(strings.TrimPrefix(pat, "image:"))
-			if err != nil {
-				return nil, nil, fmt.Errorf("invalid regex '%s': %s", pat, err)
-			}
-			imageFilters = append(imageFilters, r)
-		case strings.HasPrefix(filter, "name:"):
-			pat := strings.TrimPrefix(filter, "name:")
-			r, err := regexp.Compile(pat)
-			if err != nil {
-				return nil, nil, fmt.Errorf("invalid regex '%s': %s", pat, err)
-			}
-			nameFilters = append(nameFilters, r)
+		case strings.HasPrefix(filter, imageFilterPrefix):
+			pat = strings.TrimPrefix(filter, imageFilterPrefix)
+			target = &imageFilters
+		case strings.HasPrefix(filter, nameFilterPrefix):
+			pat = strings.TrimPrefix(filter, nameFilterPrefix)
+			target = &nameFilters
+		case strings.HasPrefix(filter, kubeNamespaceFilterPrefix):
+			pat = strings.TrimPrefix(filter, kubeNamespaceFilterPrefix)
+			target = &namespaceFilters
+		default:
+			log.Warnf("Container filter %q is unknown, ignoring it. Supported prefixes are %s, %s and %s",
+				filter, imageFilterPrefix, nameFilterPrefix, kubeNamespaceFilterPrefix)
+			continue
+		}
+		r, err := regexp.Compile(pat)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid regex '%s': %s", pat, err)
 		}
+		*target = append(*target, r)
 	}
-	return imageFilters, nameFilters, nil
+	return imageFilters, nameFilters, namespaceFilters, nil
 }
 
 // GetSharedFilter allows to share the result of NewFilterFromConfig
@@ -85,32 +105,33 @@ func ResetSharedFilter() {
 
 // NewFilter creates a new container filter from a two slices of
 // regexp patterns for a whitelist and blacklist. Each pattern should have
-// the following format: "field:pattern" where field can be: [image, name].
+// the following format: "field:pattern" where field can be: [image, name, kube_namespace].
 // An error is returned if any of the expression don't compile.
 func NewFilter(whitelist, blacklist []string) (*Filter, error) {
-	iwl, nwl, err := parseFilters(whitelist)
+	iwl, nwl, nswl, err := parseFilters(whitelist)
 	if err != nil {
 		return nil, err
 	}
-	ibl, nbl, err := parseFilters(blacklist)
+	ibl, nbl, nsbl, err := parseFilters(blacklist)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Filter{
-		Enabled:        len(whitelist) > 0 || len(blacklist) > 0,
-		ImageWhitelist: iwl,
-		NameWhitelist:  nwl,
-		ImageBlacklist: ibl,
-		NameBlacklist:  nbl,
+		Enabled:            len(whitelist) > 0 || len(blacklist) > 0,
+		ImageWhitelist:     iwl,
+		NameWhitelist:      nwl,
+		NamespaceWhitelist: nswl,
+		ImageBlacklist:     ibl,
+		NameBlacklist:      nbl,
+		NamespaceBlacklist: nsbl,
 	}, nil
 }
 
 // NewFilterFromConfig creates a new container filter, sourcing patterns
 // from the pkg/config options
 func NewFilterFromConfig() (*Filter, error) {
-	whitelist := config.Datadog.GetStringSlice("ac_include")
-	blacklist := config.Datadog.GetStringSlice("ac_exclude")
+	whitelist, blacklist := filtersFromConfig()
 
 	if config.Datadog.GetBool("exclude_pause_container") {
 		blacklist = append(blacklist,
@@ -130,14 +151,24 @@ func NewFilterFromConfig() (*Filter, error) {
 // from the pkg/config options, but ignoring the exclude_pause_container option, for
 // use in autodiscovery
 func NewFilterFromConfigIncludePause() (*Filter, error) {
-	whitelist := config.Datadog.GetStringSlice("ac_include")
-	blacklist := config.Datadog.GetStringSlice("ac_exclude")
+	whitelist, blacklist := filtersFromConfig()
 	return NewFilter(whitelist, blacklist)
 }
 
+// filtersFromConfig merges the container_include/container_exclude options
+// with their legacy ac_include/ac_exclude counterparts.
+func filtersFromConfig() (whitelist, blacklist []string) {
+	whitelist = append(whitelist, config.Datadog.GetStringSlice("container_include")...)
+	whitelist = append(whitelist, config.Datadog.GetStringSlice("ac_include")...)
+	blacklist = append(blacklist, config.Datadog.GetStringSlice("container_exclude")...)
+	blacklist = append(blacklist, config.Datadog.GetStringSlice("ac_exclude")...)
+	return whitelist, blacklist
+}
+
 // IsExcluded returns a bool indicating if the container should be excluded
-// based on the filters in the containerFilter instance.
-func (cf Filter) IsExcluded(containerName, containerImage string) bool {
+// based on the filters in the containerFilter instance. The podNamespace
+// argument is empty for containers not managed by kubernetes.
+func (cf Filter) IsExcluded(containerName, containerImage, podNamespace string) bool {
 	if !cf.Enabled {
 		return false
 	}
@@ -153,6 +184,13 @@ func (cf Filter) IsExcluded(containerName, containerImage string) bool {
 			return false
 		}
 	}
+	if podNamespace != "" {
+		for _, r := range cf.NamespaceWhitelist {
+			if r.MatchString(podNamespace) {
+				return false
+			}
+		}
+	}
 
 	// Check if blacklisted
 	for _, r := range cf.ImageBlacklist {
@@ -165,5 +203,31 @@ func (cf Filter) IsExcluded(containerName, containerImage string) bool {
 			return true
 		}
 	}
+	if podNamespace != "" {
+		for _, r := range cf.NamespaceBlacklist {
+			if r.MatchString(podNamespace) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsNamespaceExcluded returns a bool indicating if a whole kubernetes
+// namespace should be excluded, based on the kube_namespace filters only.
+func (cf Filter) IsNamespaceExcluded(podNamespace string) bool {
+	if !cf.Enabled || podNamespace == "" {
+		return false
+	}
+	for _, r := range cf.NamespaceWhitelist {
+		if r.MatchString(podNamespace) {
+			return false
+		}
+	}
+	for _, r := range cf.NamespaceBlacklist {
+		if r.MatchString(podNamespace) {
+			return true
+		}
+	}
 	return false
 }
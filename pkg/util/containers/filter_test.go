@@ -127,7 +127,7 @@ func TestFilter(t *testing.T) {
 
 			var allowed []string
 			for _, c := range containers {
-				if !f.IsExcluded(c.Name, c.Image) {
+				if !f.IsExcluded(c.Name, c.Image, "") {
 					allowed = append(allowed, c.ID)
 				}
 			}
@@ -144,16 +144,16 @@ func TestNewFilterFromConfig(t *testing.T) {
 	f, err := NewFilterFromConfig()
 	require.NoError(t, err)
 
-	assert.True(t, f.IsExcluded("dd-152462", "dummy:latest"))
-	assert.False(t, f.IsExcluded("dd-152462", "apache:latest"))
-	assert.False(t, f.IsExcluded("dummy", "dummy"))
-	assert.True(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1"))
-	assert.True(t, f.IsExcluded("dummy", "rancher/pause-amd64:3.1"))
+	assert.True(t, f.IsExcluded("dd-152462", "dummy:latest", ""))
+	assert.False(t, f.IsExcluded("dd-152462", "apache:latest", ""))
+	assert.False(t, f.IsExcluded("dummy", "dummy", ""))
+	assert.True(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1", ""))
+	assert.True(t, f.IsExcluded("dummy", "rancher/pause-amd64:3.1", ""))
 
 	config.Datadog.SetDefault("exclude_pause_container", false)
 	f, err = NewFilterFromConfig()
 	require.NoError(t, err)
-	assert.False(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1"))
+	assert.False(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1", ""))
 
 	config.Datadog.SetDefault("exclude_pause_container", true)
 	config.Datadog.SetDefault("ac_include", []string{})
@@ -168,13 +168,100 @@ func TestNewFilterFromConfigIncludePause(t *testing.T) {
 	f, err := NewFilterFromConfigIncludePause()
 	require.NoError(t, err)
 
-	assert.True(t, f.IsExcluded("dd-152462", "dummy:latest"))
-	assert.False(t, f.IsExcluded("dd-152462", "apache:latest"))
-	assert.False(t, f.IsExcluded("dummy", "dummy"))
-	assert.False(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1"))
-	assert.False(t, f.IsExcluded("dummy", "rancher/pause-amd64:3.1"))
+	assert.True(t, f.IsExcluded("dd-152462", "dummy:latest", ""))
+	assert.False(t, f.IsExcluded("dd-152462", "apache:latest", ""))
+	assert.False(t, f.IsExcluded("dummy", "dummy", ""))
+	assert.False(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1", ""))
+	assert.False(t, f.IsExcluded("dummy", "rancher/pause-amd64:3.1", ""))
 
 	config.Datadog.SetDefault("exclude_pause_container", true)
 	config.Datadog.SetDefault("ac_include", []string{})
 	config.Datadog.SetDefault("ac_exclude", []string{})
 }
+
+func TestFilterNamespace(t *testing.T) {
+	for name, tc := range map[string]struct {
+		whitelist []string
+		blacklist []string
+		name      string
+		image     string
+		namespace string
+		excluded  bool
+	}{
+		"no namespace filter": {
+			blacklist: []string{"name:foo"},
+			name:      "bar",
+			namespace: "default",
+			excluded:  false,
+		},
+		"namespace excluded": {
+			blacklist: []string{"kube_namespace:kube-system"},
+			name:      "bar",
+			namespace: "kube-system",
+			excluded:  true,
+		},
+		"namespace regex excluded": {
+			blacklist: []string{"kube_namespace:^dev-.*"},
+			name:      "bar",
+			namespace: "dev-team",
+			excluded:  true,
+		},
+		"not a kube container": {
+			blacklist: []string{"kube_namespace:.*"},
+			name:      "bar",
+			namespace: "",
+			excluded:  false,
+		},
+		"namespace included overrides name exclusion": {
+			whitelist: []string{"kube_namespace:prod"},
+			blacklist: []string{"name:.*"},
+			name:      "bar",
+			namespace: "prod",
+			excluded:  false,
+		},
+		"image included overrides namespace exclusion": {
+			whitelist: []string{"image:redis"},
+			blacklist: []string{"kube_namespace:.*"},
+			name:      "bar",
+			image:     "redis:latest",
+			namespace: "prod",
+			excluded:  false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewFilter(tc.whitelist, tc.blacklist)
+			require.NoError(t, err)
+			assert.Equal(t, tc.excluded, f.IsExcluded(tc.name, tc.image, tc.namespace))
+		})
+	}
+}
+
+func TestIsNamespaceExcluded(t *testing.T) {
+	f, err := NewFilter([]string{"kube_namespace:kube-public"}, []string{"kube_namespace:kube-.*", "name:.*"})
+	require.NoError(t, err)
+
+	assert.True(t, f.IsNamespaceExcluded("kube-system"))
+	assert.False(t, f.IsNamespaceExcluded("kube-public"))
+	assert.False(t, f.IsNamespaceExcluded("default"))
+	assert.False(t, f.IsNamespaceExcluded(""))
+}
+
+func TestNewFilterFromConfigContainerInclude(t *testing.T) {
+	config.Datadog.SetDefault("exclude_pause_container", false)
+	config.Datadog.SetDefault("container_include", []string{"kube_namespace:prod"})
+	config.Datadog.SetDefault("container_exclude", []string{"kube_namespace:.*"})
+	config.Datadog.SetDefault("ac_exclude", []string{"name:dd-.*"})
+
+	f, err := NewFilterFromConfig()
+	require.NoError(t, err)
+
+	assert.True(t, f.IsExcluded("dummy", "dummy", "dev"))
+	assert.False(t, f.IsExcluded("dummy", "dummy", "prod"))
+	assert.True(t, f.IsExcluded("dd-152462", "dummy", ""))
+	assert.False(t, f.IsExcluded("dummy", "dummy", ""))
+
+	config.Datadog.SetDefault("exclude_pause_container", true)
+	config.Datadog.SetDefault("container_include", []string{})
+	config.Datadog.SetDefault("container_exclude", []string{})
+	config.Datadog.SetDefault("ac_exclude", []string{})
+}
@@ -32,10 +32,14 @@ var (
 	dindCgroupRe = regexp.MustCompile("^\\/docker\\/[0-9a-f]{64}(\\/docker\\/[0-9a-f]{64})")
 )
 
+// unifiedHierarchy is the target of the cgroup v2 hierarchy in the mounts
+// and paths of a cgroup, all the controllers it holds share it
+const unifiedHierarchy = "unified"
+
 // ContainerStartTime gets the stat for cgroup directory and use the mtime for that dir to determine the start time for the container
 // this should work because the cgroup dir for the container would be created only when it's started
 func (c ContainerCgroup) ContainerStartTime() (int64, error) {
-	cgroupDir := c.cgroupFilePath("cpuacct", "")
+	cgroupDir := c.cgroupFilePath(c.hierarchy("cpuacct"), "")
 	if !pathExists(cgroupDir) {
 		return 0, fmt.Errorf("could not get cgroup dir, directory doesn't exist")
 	}
@@ -73,6 +77,28 @@ func (c ContainerCgroup) cgroupFilePath(target, file string) string {
 	return filepath.Join(mount, targetPath, file)
 }
 
+// hierarchy returns the target of the hierarchy holding a controller: the
+// controller itself with cgroup v1, or the unified hierarchy when the
+// controller is only available with cgroup v2, like on hosts running in
+// unified mode.
+func (c ContainerCgroup) hierarchy(controller string) string {
+	if c.unified(controller) {
+		return unifiedHierarchy
+	}
+	return controller
+}
+
+// unified returns whether a controller is only available in the cgroup v2
+// unified hierarchy. Hosts in hybrid mode mount the unified hierarchy
+// without controllers next to the cgroup v1 ones.
+func (c ContainerCgroup) unified(controller string) bool {
+	if _, found := c.Mounts[controller]; found {
+		return false
+	}
+	_, found := c.Mounts[unifiedHierarchy]
+	return found
+}
+
 // function to get the mount point of all cgroup. by default it should be under /sys/fs/cgroup but
 // it could be mounted anywhere else if manually defined. Example cgroup entries in /proc/mounts would be
 //	 cgroup /sys/fs/cgroup/cpuset cgroup rw,relatime,cpuset 0 0
@@ -85,7 +111,10 @@ func (c ContainerCgroup) cgroupFilePath(target, file string) string {
 //	 cgroup /sys/fs/cgroup/perf_event cgroup rw,relatime,perf_event 0 0
 //	 cgroup /sys/fs/cgroup/hugetlb cgroup rw,relatime,hugetlb 0 0
 //
-// Returns a map for every target (cpuset, cpu, cpuacct) => path
+// The cgroup v2 unified hierarchy is a single mount, for every controller:
+//	 cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0
+//
+// Returns a map for every target (cpuset, cpu, cpuacct, unified) => path
 func cgroupMountPoints() (map[string]string, error) {
 	mountsFile := "/proc/mounts"
 	if !pathExists(mountsFile) {
@@ -106,15 +135,22 @@ func parseCgroupMountPoints(r io.Reader) map[string]string {
 	for scanner.Scan() {
 		mount := scanner.Text()
 		tokens := strings.Split(mount, " ")
-		// Check if the filesystem type is 'cgroup'
-		if len(tokens) >= 3 && tokens[2] == "cgroup" {
-			cgroupPath := tokens[1]
-
-			// Ignore mountpoints not mounted under /{host/}sys
-			if !strings.HasPrefix(cgroupPath, cgroupRoot) {
-				continue
-			}
+		if len(tokens) < 3 {
+			continue
+		}
+		cgroupPath := tokens[1]
+		// Ignore mountpoints not mounted under /{host/}sys, the unified
+		// hierarchy can be mounted on the cgroup root itself
+		if !strings.HasPrefix(cgroupPath+"/", cgroupRoot) {
+			continue
+		}
 
+		if tokens[2] == "cgroup2" {
+			mountPoints[unifiedHierarchy] = cgroupPath
+			continue
+		}
+		// Check if the filesystem type is 'cgroup'
+		if tokens[2] == "cgroup" {
 			// Target can be comma-separate values like cpu,cpuacct
 			tsp := strings.Split(path.Base(cgroupPath), ",")
 			for _, target := range tsp {
@@ -218,6 +254,10 @@ func ReadCgroupsForPath(pidCgroupPath, prefix string) (string, map[string]string
 // 8:memory:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 // 7:blkio:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 //
+// The cgroup v2 unified hierarchy has no controller list:
+//
+// 0::/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope
+//
 // Returns the common containerID and a mapping of target => path
 // If the first line doesn't have a valid container ID we will return an empty string
 func parseCgroupPaths(r io.Reader, prefix string) (string, map[string]string, error) {
@@ -239,6 +279,10 @@ func parseCgroupPaths(r io.Reader, prefix string) (string, map[string]string, er
 		if len(sp) < 3 {
 			continue
 		}
+		if sp[0] == "0" && sp[1] == "" {
+			paths[unifiedHierarchy] = sp[2]
+			continue
+		}
 		// Target can be comma-separate values like cpu,cpuacct
 		tsp := strings.Split(sp[1], ",")
 		for _, target := range tsp {
@@ -112,6 +112,32 @@ func TestParseCgroupMountPoints(t *testing.T) {
 				"systemd":    "/sys/fs/cgroup/systemd",
 			},
 		},
+		{
+			// cgroup v2 in unified mode
+			contents: []string{
+				"sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0",
+				"cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime,nsdelegate 0 0",
+			},
+			expected: map[string]string{
+				"unified": "/sys/fs/cgroup",
+			},
+		},
+		{
+			// cgroup v2 in hybrid mode
+			contents: []string{
+				"tmpfs /sys/fs/cgroup tmpfs ro,nosuid,nodev,noexec,mode=755 0 0",
+				"cgroup2 /sys/fs/cgroup/unified cgroup2 rw,nosuid,nodev,noexec,relatime,nsdelegate 0 0",
+				"cgroup /sys/fs/cgroup/systemd cgroup rw,nosuid,nodev,noexec,relatime,xattr,name=systemd 0 0",
+				"cgroup /sys/fs/cgroup/memory cgroup rw,nosuid,nodev,noexec,relatime,memory 0 0",
+				"cgroup /sys/fs/cgroup/pids cgroup rw,nosuid,nodev,noexec,relatime,pids 0 0",
+			},
+			expected: map[string]string{
+				"unified": "/sys/fs/cgroup/unified",
+				"systemd": "/sys/fs/cgroup/systemd",
+				"memory":  "/sys/fs/cgroup/memory",
+				"pids":    "/sys/fs/cgroup/pids",
+			},
+		},
 		{
 			contents: []string{
 				"",
@@ -255,6 +281,16 @@ func TestParseCgroupPaths(t *testing.T) {
 				"cpuset":       "/docker/af1c1c0b02c6e45e0b6cb6151cd68fd02c7a6d91ad70d9bd72ccec8e83607841",
 			},
 		},
+		{
+			// cgroup v2
+			contents: []string{
+				"0::/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope",
+			},
+			expectedContainer: "47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e",
+			expectedPaths: map[string]string{
+				"unified": "/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope",
+			},
+		},
 	} {
 		contents := strings.NewReader(strings.Join(tc.contents, "\n"))
 		c, p, err := parseCgroupPaths(contents, "")
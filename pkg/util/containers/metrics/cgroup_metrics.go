@@ -26,6 +26,9 @@ const NanoToUserHZDivisor float64 = 1e9 / 100
 // Mem returns the memory statistics for a Cgroup. If the cgroup file is not
 // available then we return an empty stats file.
 func (c ContainerCgroup) Mem() (*CgroupMemStat, error) {
+	if c.unified("memory") {
+		return c.memV2()
+	}
 	ret := &CgroupMemStat{ContainerID: c.ContainerID}
 	statfile := c.cgroupFilePath("memory", "memory.stat")
 
@@ -115,6 +118,9 @@ func (c ContainerCgroup) Mem() (*CgroupMemStat, error) {
 // MemLimit returns the memory limit of the cgroup, if it exists. If the file does not
 // exist or there is no limit then this will default to 0.
 func (c ContainerCgroup) MemLimit() (uint64, error) {
+	if c.unified("memory") {
+		return c.memLimitV2("memory.max")
+	}
 	v, err := c.ParseSingleStat("memory", "memory.limit_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -134,6 +140,9 @@ func (c ContainerCgroup) MemLimit() (uint64, error) {
 // FailedMemoryCount returns the number of times this cgroup reached its memory limit, if it exists.
 // If the file does not exist or there is no limit, then this will default to 0
 func (c ContainerCgroup) FailedMemoryCount() (uint64, error) {
+	if c.unified("memory") {
+		return c.failedMemoryCountV2()
+	}
 	v, err := c.ParseSingleStat("memory", "memory.failcnt")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -148,6 +157,9 @@ func (c ContainerCgroup) FailedMemoryCount() (uint64, error) {
 // KernelMemoryUsage returns the number of bytes of kernel memory used by this cgroup, if it exists.
 // If the file does not exist or there is an error, then this will default to 0
 func (c ContainerCgroup) KernelMemoryUsage() (uint64, error) {
+	if c.unified("memory") {
+		return c.kernelMemoryUsageV2()
+	}
 	v, err := c.ParseSingleStat("memory", "memory.kmem.usage_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -162,6 +174,10 @@ func (c ContainerCgroup) KernelMemoryUsage() (uint64, error) {
 // SoftMemLimit returns the soft memory limit of the cgroup, if it exists. If the file does not
 // exist or there is no limit then this will default to 0.
 func (c ContainerCgroup) SoftMemLimit() (uint64, error) {
+	// cgroup v2 protects the memory under memory.low like the soft limit
+	if c.unified("memory") {
+		return c.memLimitV2("memory.low")
+	}
 	v, err := c.ParseSingleStat("memory", "memory.soft_limit_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -181,6 +197,9 @@ func (c ContainerCgroup) SoftMemLimit() (uint64, error) {
 // CPU returns the CPU status for this cgroup instance
 // If the cgroup file does not exist then we just log debug return nothing.
 func (c ContainerCgroup) CPU() (*CgroupTimesStat, error) {
+	if c.unified("cpuacct") {
+		return c.cpuV2()
+	}
 	ret := &CgroupTimesStat{ContainerID: c.ContainerID}
 	statfile := c.cgroupFilePath("cpuacct", "cpuacct.stat")
 	f, err := os.Open(statfile)
@@ -232,7 +251,8 @@ func (c ContainerCgroup) CPU() (*CgroupTimesStat, error) {
 // throttle/limited because of CPU quota / limit
 // If the cgroup file does not exist then we just log debug and return 0.
 func (c ContainerCgroup) CPUNrThrottled() (uint64, error) {
-	statfile := c.cgroupFilePath("cpu", "cpu.stat")
+	// cgroup v2 keeps the same format
+	statfile := c.cgroupFilePath(c.hierarchy("cpu"), "cpu.stat")
 	f, err := os.Open(statfile)
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s", statfile)
@@ -266,6 +286,9 @@ func (c ContainerCgroup) CPUNrThrottled() (uint64, error) {
 // If the limits files aren't available (on older version) then
 // we'll return the default value of 100.
 func (c ContainerCgroup) CPULimit() (float64, error) {
+	if c.unified("cpu") {
+		return c.cpuLimitV2()
+	}
 	periodFile := c.cgroupFilePath("cpu", "cpu.cfs_period_us")
 	quotaFile := c.cgroupFilePath("cpu", "cpu.cfs_quota_us")
 	plines, err := readLines(periodFile)
@@ -314,6 +337,9 @@ func (c ContainerCgroup) CPULimit() (float64, error) {
 // 252:0 Total 58945536
 //
 func (c ContainerCgroup) IO() (*CgroupIOStat, error) {
+	if c.unified("blkio") {
+		return c.ioV2()
+	}
 	ret := &CgroupIOStat{
 		ContainerID:      c.ContainerID,
 		DeviceReadBytes:  make(map[string]uint64),
@@ -378,10 +404,10 @@ func (c ContainerCgroup) IO() (*CgroupIOStat, error) {
 // Although the metric is called `pid.current`, it also tracks
 // threads, and not only task-group-pids
 func (c ContainerCgroup) ThreadCount() (uint64, error) {
-	v, err := c.ParseSingleStat("pids", "pids.current")
+	v, err := c.ParseSingleStat(c.hierarchy("pids"), "pids.current")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
-			c.cgroupFilePath("pids", "pids.current"))
+			c.cgroupFilePath(c.hierarchy("pids"), "pids.current"))
 		return 0, nil
 	} else if err != nil {
 		return 0, err
@@ -395,7 +421,7 @@ func (c ContainerCgroup) ThreadCount() (uint64, error) {
 //
 // If `max` is found, the method returns 0 as-in "no limit"
 func (c ContainerCgroup) ThreadLimit() (uint64, error) {
-	statFile := c.cgroupFilePath("pids", "pids.max")
+	statFile := c.cgroupFilePath(c.hierarchy("pids"), "pids.max")
 	lines, err := readLines(statFile)
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s", statFile)
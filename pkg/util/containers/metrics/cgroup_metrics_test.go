@@ -224,3 +224,144 @@ func TestThreadCount(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, value, uint64(123))
 }
+
+func TestMemV2(t *testing.T) {
+	tempFolder, err := newTempFolder("mem-v2")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "unified")
+
+	// No file
+	memStat, err := cgroup.Mem()
+	assert.Nil(t, err)
+	assert.Equal(t, memStat.ContainerID, "dummy")
+	assert.Equal(t, memStat.RSS, uint64(0))
+
+	memStats := dummyCgroupStat{
+		"anon":         1200,
+		"file":         3400,
+		"kernel_stack": 10,
+		"slab":         20,
+		"pgmajfault":   3,
+	}
+	tempFolder.add("unified/memory.stat", memStats.String())
+	tempFolder.add("unified/memory.current", "5000")
+	tempFolder.add("unified/memory.max", "8192")
+	tempFolder.add("unified/memory.swap.current", "100")
+	tempFolder.add("unified/memory.swap.max", "max")
+	tempFolder.add("unified/memory.low", "1024")
+	tempFolder.add("unified/memory.events", "low 0\nhigh 0\nmax 4\noom 1\noom_kill 1")
+
+	memStat, err = cgroup.Mem()
+	assert.Nil(t, err)
+	assert.Equal(t, memStat.RSS, uint64(1200))
+	assert.Equal(t, memStat.TotalRSS, uint64(1200))
+	assert.Equal(t, memStat.Cache, uint64(3400))
+	assert.Equal(t, memStat.Pgmajfault, uint64(3))
+	assert.Equal(t, memStat.MemUsageInBytes, uint64(5000))
+	assert.Equal(t, memStat.HierarchicalMemoryLimit, uint64(8192))
+	assert.Equal(t, memStat.HierarchicalMemSWLimit, uint64(0))
+	assert.Equal(t, memStat.Swap, uint64(100))
+	assert.True(t, memStat.SwapPresent)
+
+	value, err := cgroup.MemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(8192))
+
+	value, err = cgroup.SoftMemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(1024))
+
+	value, err = cgroup.FailedMemoryCount()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(4))
+
+	value, err = cgroup.KernelMemoryUsage()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(30))
+
+	// No limit
+	tempFolder.add("unified/memory.max", "max")
+	value, err = cgroup.MemLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(0))
+}
+
+func TestCPUV2(t *testing.T) {
+	tempFolder, err := newTempFolder("cpu-v2")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cpuStats := dummyCgroupStat{
+		"usage_usec":     915266418,
+		"user_usec":      641400000,
+		"system_usec":    183270000,
+		"nr_periods":     0,
+		"nr_throttled":   10,
+		"throttled_usec": 18327,
+	}
+	tempFolder.add("unified/cpu.stat", cpuStats.String())
+	tempFolder.add("unified/cpu.weight", "100")
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "unified")
+
+	timeStat, err := cgroup.CPU()
+	assert.Nil(t, err)
+	assert.Equal(t, timeStat.ContainerID, "dummy")
+	assert.Equal(t, timeStat.User, uint64(64140))
+	assert.Equal(t, timeStat.System, uint64(18327))
+	assert.Equal(t, timeStat.Shares, uint64(2597))
+	assert.InDelta(t, timeStat.UsageTotal, 91526.6418, 0.0000001)
+
+	value, err := cgroup.CPUNrThrottled()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(10))
+}
+
+func TestCPULimitV2(t *testing.T) {
+	tempFolder, err := newTempFolder("cpu-limit-v2")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "unified")
+
+	// No file
+	limit, err := cgroup.CPULimit()
+	assert.Nil(t, err)
+	assert.Equal(t, limit, float64(100))
+
+	// No limit
+	tempFolder.add("unified/cpu.max", "max 100000")
+	limit, err = cgroup.CPULimit()
+	assert.Nil(t, err)
+	assert.Equal(t, limit, float64(100))
+
+	// Invalid file
+	tempFolder.add("unified/cpu.max", "50000")
+	_, err = cgroup.CPULimit()
+	assert.NotNil(t, err)
+
+	tempFolder.add("unified/cpu.max", "50000 100000")
+	limit, err = cgroup.CPULimit()
+	assert.Nil(t, err)
+	assert.Equal(t, limit, float64(50))
+}
+
+func TestThreadCountV2(t *testing.T) {
+	tempFolder, err := newTempFolder("thread-count-v2")
+	assert.Nil(t, err)
+	defer tempFolder.removeAll()
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "unified")
+
+	tempFolder.add("unified/pids.current", "123")
+	value, err := cgroup.ThreadCount()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(123))
+
+	tempFolder.add("unified/pids.max", "max")
+	value, err = cgroup.ThreadLimit()
+	assert.Nil(t, err)
+	assert.Equal(t, value, uint64(0))
+}
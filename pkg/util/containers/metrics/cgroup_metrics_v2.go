@@ -0,0 +1,276 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build linux
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// MicroToUserHZDivisor holds the divisor to convert the cgroup v2 cpu.stat
+// times, in microseconds, to USER_HZ (1/100)
+const MicroToUserHZDivisor float64 = 1e6 / 100
+
+// The cgroup v2 files are documented in
+// https://www.kernel.org/doc/Documentation/cgroup-v2.txt
+
+// memV2 returns the memory statistics of a cgroup v2. Its statistics are
+// hierarchical, they are reported as both the cgroup and the total ones.
+func (c ContainerCgroup) memV2() (*CgroupMemStat, error) {
+	ret := &CgroupMemStat{ContainerID: c.ContainerID}
+	stats, err := c.parseKeyValueStat(unifiedHierarchy, "memory.stat")
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedHierarchy, "memory.stat"))
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	ret.RSS, ret.TotalRSS = stats["anon"], stats["anon"]
+	ret.Cache, ret.TotalCache = stats["file"], stats["file"]
+	ret.RSSHuge, ret.TotalRSSHuge = stats["anon_thp"], stats["anon_thp"]
+	ret.MappedFile, ret.TotalMappedFile = stats["file_mapped"], stats["file_mapped"]
+	ret.Pgfault, ret.TotalPgFault = stats["pgfault"], stats["pgfault"]
+	ret.Pgmajfault, ret.TotalPgMajFault = stats["pgmajfault"], stats["pgmajfault"]
+	ret.InactiveAnon, ret.TotalInactiveAnon = stats["inactive_anon"], stats["inactive_anon"]
+	ret.ActiveAnon, ret.TotalActiveAnon = stats["active_anon"], stats["active_anon"]
+	ret.InactiveFile, ret.TotalInactiveFile = stats["inactive_file"], stats["inactive_file"]
+	ret.ActiveFile, ret.TotalActiveFile = stats["active_file"], stats["active_file"]
+	ret.Unevictable, ret.TotalUnevictable = stats["unevictable"], stats["unevictable"]
+
+	if usage, err := c.ParseSingleStat(unifiedHierarchy, "memory.current"); err == nil {
+		ret.MemUsageInBytes = usage
+	}
+	// the swap controller is optional
+	if swap, err := c.ParseSingleStat(unifiedHierarchy, "memory.swap.current"); err == nil {
+		ret.Swap = swap
+		ret.SwapPresent = true
+	}
+
+	memLimit, err := c.parseMaxStat(unifiedHierarchy, "memory.max")
+	if err == nil {
+		ret.HierarchicalMemoryLimit = memLimit
+	}
+	// memory.swap.max only limits the swap, memsw limits both
+	swapLimit, err := c.parseMaxStat(unifiedHierarchy, "memory.swap.max")
+	if err == nil && memLimit > 0 && swapLimit > 0 {
+		ret.HierarchicalMemSWLimit = memLimit + swapLimit
+	}
+	return ret, nil
+}
+
+// memLimitV2 returns a memory limit of a cgroup v2, 0 without limit
+func (c ContainerCgroup) memLimitV2(file string) (uint64, error) {
+	v, err := c.parseMaxStat(unifiedHierarchy, file)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedHierarchy, file))
+		return 0, nil
+	}
+	return v, err
+}
+
+// failedMemoryCountV2 returns the number of times the cgroup was about to
+// go over its memory limit
+func (c ContainerCgroup) failedMemoryCountV2() (uint64, error) {
+	events, err := c.parseKeyValueStat(unifiedHierarchy, "memory.events")
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedHierarchy, "memory.events"))
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return events["max"], nil
+}
+
+// kernelMemoryUsageV2 returns the kernel memory used by the cgroup, cgroup
+// v2 doesn't account it separately from the rest of the memory
+func (c ContainerCgroup) kernelMemoryUsageV2() (uint64, error) {
+	stats, err := c.parseKeyValueStat(unifiedHierarchy, "memory.stat")
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedHierarchy, "memory.stat"))
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return stats["kernel_stack"] + stats["slab"], nil
+}
+
+// cpuV2 returns the CPU times of a cgroup v2. The shares are converted from
+// the CPU weight the way runc converts shares to a weight.
+func (c ContainerCgroup) cpuV2() (*CgroupTimesStat, error) {
+	ret := &CgroupTimesStat{ContainerID: c.ContainerID}
+	stats, err := c.parseKeyValueStat(unifiedHierarchy, "cpu.stat")
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath(unifiedHierarchy, "cpu.stat"))
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	ret.User = uint64(float64(stats["user_usec"]) / MicroToUserHZDivisor)
+	ret.System = uint64(float64(stats["system_usec"]) / MicroToUserHZDivisor)
+	ret.UsageTotal = float64(stats["usage_usec"]) / MicroToUserHZDivisor
+
+	// the cpu controller is optional, cpu.stat is always there
+	weight, err := c.ParseSingleStat(unifiedHierarchy, "cpu.weight")
+	if err == nil && weight > 0 {
+		ret.Shares = 2 + (weight-1)*262142/9999
+	} else {
+		log.Debugf("Missing cpu weight stat for %s: %v", c.ContainerID, err)
+	}
+	return ret, nil
+}
+
+// cpuLimitV2 returns the CPU limit of a cgroup v2, from its quota and period:
+//
+//	50000 100000
+//
+// The quota is `max` without limit.
+func (c ContainerCgroup) cpuLimitV2() (float64, error) {
+	statFile := c.cgroupFilePath(unifiedHierarchy, "cpu.max")
+	lines, err := readLines(statFile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statFile)
+		return 100, nil
+	} else if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(lines[0])
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("wrong file format: %s", statFile)
+	}
+	if fields[0] == "max" {
+		return 100, nil
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	// default cpu limit is 100%
+	limit := 100.0
+	if (period > 0) && (quota > 0) {
+		limit = (quota / period) * 100.0
+	}
+	return limit, nil
+}
+
+// ioV2 returns the disk read and write bytes of a cgroup v2.
+// Format:
+//
+// 8:16 rbytes=1130496 wbytes=0 rios=17 wios=0 dbytes=0 dios=0
+// 8:0 rbytes=37858816 wbytes=671846400 rios=1263 wios=42817 dbytes=0 dios=0
+//
+func (c ContainerCgroup) ioV2() (*CgroupIOStat, error) {
+	ret := &CgroupIOStat{
+		ContainerID:      c.ContainerID,
+		DeviceReadBytes:  make(map[string]uint64),
+		DeviceWriteBytes: make(map[string]uint64),
+	}
+
+	statfile := c.cgroupFilePath(unifiedHierarchy, "io.stat")
+	f, err := os.Open(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Get device id->name mapping
+	var devices map[string]string
+	mapping, err := getDiskDeviceMapping()
+	if err != nil {
+		log.Debugf("Cannot get per-device stats: %s", err)
+		// devices will stay nil, lookups are safe in nil maps
+	} else {
+		devices = mapping.idToName
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		deviceName := devices[fields[0]]
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			value, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch kv[0] {
+			case "rbytes":
+				ret.ReadBytes += value
+				if deviceName != "" {
+					ret.DeviceReadBytes[deviceName] = value
+				}
+			case "wbytes":
+				ret.WriteBytes += value
+				if deviceName != "" {
+					ret.DeviceWriteBytes[deviceName] = value
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ret, fmt.Errorf("error reading %s: %s", statfile, err)
+	}
+	return ret, nil
+}
+
+// parseKeyValueStat reads a flat keyed cgroup stat file, like memory.stat
+func (c ContainerCgroup) parseKeyValueStat(target, file string) (map[string]uint64, error) {
+	statFile := c.cgroupFilePath(target, file)
+	lines, err := readLines(statFile)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]uint64, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		stats[fields[0]] = value
+	}
+	return stats, nil
+}
+
+// parseMaxStat reads a single-value cgroup v2 limit file, `max` meaning no
+// limit is converted to 0
+func (c ContainerCgroup) parseMaxStat(target, file string) (uint64, error) {
+	statFile := c.cgroupFilePath(target, file)
+	lines, err := readLines(statFile)
+	if err != nil {
+		return 0, err
+	}
+	if len(lines) != 1 {
+		return 0, fmt.Errorf("wrong file format: %s", statFile)
+	}
+	if lines[0] == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(lines[0], 10, 64)
+}
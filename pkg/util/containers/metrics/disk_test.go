@@ -182,6 +182,44 @@ func (s *DiskMappingTestSuite) TestContainerCgroupIOFailedMapping() {
 	assert.EqualValues(s.T(), expectedStats, ioStat)
 }
 
+func (s *DiskMappingTestSuite) TestContainerCgroupIOV2() {
+	s.proc.add("diskstats", detab(`
+        8       0 sda 24398 2788 1317975 40488 25201 46267 1584744 142336 0 22352 182660
+        8      16 sdb 189 0 4063 220 0 0 0 0 0 112 204
+    `))
+
+	tempFolder, err := newTempFolder("io-stats-v2")
+	assert.Nil(s.T(), err)
+	defer tempFolder.removeAll()
+
+	// 55:0 is unknown, don't report per-device but keep in sum
+	tempFolder.add("unified/io.stat", detab(`
+		8:16 rbytes=1130496 wbytes=0 rios=17 wios=0 dbytes=0 dios=0
+		8:0 rbytes=37858816 wbytes=671846400 rios=1263 wios=42817 dbytes=0 dios=0
+		55:0 rbytes=55 wbytes=55 rios=1 wios=1 dbytes=0 dios=0
+	`))
+
+	cgroup := newDummyContainerCgroup(tempFolder.RootPath, "unified")
+
+	expectedStats := &CgroupIOStat{
+		ContainerID: "dummy",
+		ReadBytes:   uint64(1130496 + 37858816 + 55),
+		WriteBytes:  uint64(0 + 671846400 + 55),
+		DeviceReadBytes: map[string]uint64{
+			"sda": 37858816,
+			"sdb": 1130496,
+		},
+		DeviceWriteBytes: map[string]uint64{
+			"sda": 671846400,
+			"sdb": 0,
+		},
+	}
+
+	ioStat, err := cgroup.IO()
+	assert.Nil(s.T(), err)
+	assert.EqualValues(s.T(), expectedStats, ioStat)
+}
+
 func TestDiskMappingTestSuite(t *testing.T) {
 	suite.Run(t, new(DiskMappingTestSuite))
 }
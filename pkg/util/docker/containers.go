@@ -131,7 +131,7 @@ func (d *DockerUtil) dockerContainers(cfg *ContainerListConfig) ([]*containers.C
 			log.Warnf("Can't resolve image name %s: %s", c.Image, err)
 		}
 
-		excluded := d.cfg.filter.IsExcluded(c.Names[0], image)
+		excluded := d.cfg.filter.IsExcluded(c.Names[0], image, c.Labels[containers.KubeNamespaceLabel])
 		if excluded && !cfg.FlagExcluded {
 			continue
 		}
@@ -8,12 +8,21 @@
 package docker
 
 import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 func init() {
 	diagnosis.Register("Docker availability", diagnose)
+	diagnosis.Register("Docker socket permissions", diagnoseSocket)
 }
 
 // diagnose the docker availability on the system
@@ -33,3 +42,33 @@ func diagnose() error {
 	}
 	return err
 }
+
+// diagnoseSocket checks that the agent is allowed to connect to the docker socket
+func diagnoseSocket() error {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = client.DefaultDockerHost
+	}
+	if !strings.HasPrefix(host, "unix://") {
+		return diagnosis.Skip("docker is not reached through a unix socket: %s", host)
+	}
+	path := strings.TrimPrefix(host, "unix://")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return diagnosis.Skip("no docker socket at %s", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		log.Errorf("unable to connect to the docker socket %s: %s", path, err)
+		if opErr, ok := err.(*net.OpError); ok && os.IsPermission(opErr.Err) {
+			return diagnosis.Fail(
+				fmt.Errorf("permission denied on the docker socket %s", path),
+				"add the user running the agent to the docker group, or mount the socket in the agent container",
+			)
+		}
+		return err
+	}
+	conn.Close()
+	log.Infof("the agent can connect to the docker socket %s", path)
+	return nil
+}
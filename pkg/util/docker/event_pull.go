@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/docker/docker/api/types"
@@ -68,7 +69,7 @@ func (d *DockerUtil) processContainerEvent(msg events.Message) (*ContainerEvent,
 			log.Warnf("can't resolve image name %s: %s", imageName, err)
 		}
 	}
-	if d.cfg.filter.IsExcluded(containerName, imageName) {
+	if d.cfg.filter.IsExcluded(containerName, imageName, msg.Actor.Attributes[containers.KubeNamespaceLabel]) {
 		log.Tracef("events from %s are skipped as the image is excluded for the event collection", containerName)
 		return nil, nil
 	}
@@ -95,7 +96,12 @@ func (d *DockerUtil) processContainerEvent(msg events.Message) (*ContainerEvent,
 
 	// Fix the "exec_start: /bin/sh -c true" case
 	if strings.Contains(event.Action, ":") {
-		event.Action = strings.SplitN(event.Action, ":", 2)[0]
+		parts := strings.SplitN(event.Action, ":", 2)
+		event.Action = parts[0]
+		// Keep the status of the "health_status: unhealthy" events
+		if event.Action == "health_status" {
+			event.Attributes[HealthStatusAttribute] = strings.TrimSpace(parts[1])
+		}
 	}
 
 	return event, nil
@@ -170,6 +170,35 @@ func TestProcessContainerEvent(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			// Keep the health status
+			source: events.Message{
+				Type: "container",
+				Actor: events.Actor{
+					ID: "test_id",
+					Attributes: map[string]string{
+						"name":  "test_name",
+						"image": "test_image",
+					},
+				},
+				Action:   "health_status: unhealthy",
+				Time:     timestamp.Unix(),
+				TimeNano: timestamp.UnixNano(),
+			},
+			event: &ContainerEvent{
+				ContainerID:   "test_id",
+				ContainerName: "test_name",
+				ImageName:     "test_image",
+				Action:        "health_status",
+				Timestamp:     timestamp,
+				Attributes: map[string]string{
+					"name":          "test_name",
+					"image":         "test_image",
+					"health_status": "unhealthy",
+				},
+			},
+			err: nil,
+		},
 	} {
 		t.Logf("test case %d", nb)
 		event, err := dockerUtil.processContainerEvent(tc.source)
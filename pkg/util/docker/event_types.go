@@ -21,6 +21,10 @@ type ContainerEvent struct {
 	Attributes    map[string]string
 }
 
+// HealthStatusAttribute is the attribute holding the new status of the
+// container in the health_status events
+const HealthStatusAttribute = "health_status"
+
 // ContainerEntityName returns the event's container as a tagger entity name
 func (ev *ContainerEvent) ContainerEntityName() string {
 	return ContainerIDToEntityName(ev.ContainerID)
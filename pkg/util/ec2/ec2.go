@@ -6,6 +6,7 @@
 package ec2
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -61,6 +62,26 @@ func getMetadataItem(endpoint string) (string, error) {
 	return string(all), nil
 }
 
+// GetAccountID returns the ID of the AWS account of the current EC2 instance
+func GetAccountID() (string, error) {
+	res, err := getResponse(instanceIdentityURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch EC2 API, %s", err)
+	}
+
+	defer res.Body.Close()
+	var identity struct {
+		AccountID string `json:"accountId"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&identity); err != nil {
+		return "", fmt.Errorf("unable to unmarshall json, %s", err)
+	}
+	if identity.AccountID == "" {
+		return "", errors.New("no account ID in the instance identity document")
+	}
+	return identity.AccountID, nil
+}
+
 // GetClusterName returns the name of the cluster containing the current EC2 instance
 func GetClusterName() (string, error) {
 	tags, err := GetTags()
@@ -89,27 +110,52 @@ func extractClusterName(tags []string) (string, error) {
 }
 
 func getResponse(url string) (*http.Response, error) {
-	client := http.Client{
-		Timeout: timeout,
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
+	res, err := doMetadataRequest(url)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if res.StatusCode == 401 && res.Request.Header.Get(tokenHeader) != "" {
+		// the token expired or was revoked, retry once with a new one
+		res.Body.Close()
+		token.invalidate()
+		res, err = doMetadataRequest(url)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if res.StatusCode != 200 {
+		res.Body.Close()
 		return nil, fmt.Errorf("status code %d trying to fetch %s", res.StatusCode, url)
 	}
 
 	return res, nil
 }
 
+// doMetadataRequest queries the metadata API with an IMDSv2 session token
+// when one is available, and without one (IMDSv1) otherwise
+func doMetadataRequest(url string) (*http.Response, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Datadog.GetBool("ec2_prefer_imdsv2") {
+		if t, err := token.get(); err == nil {
+			req.Header.Set(tokenHeader, t)
+		} else {
+			log.Debugf("Querying the EC2 metadata API with IMDSv1: %s", err)
+		}
+	}
+
+	return client.Do(req)
+}
+
 // IsDefaultHostname returns whether the given hostname is a default one for EC2
 func IsDefaultHostname(hostname string) bool {
 	hostname = strings.ToLower(hostname)
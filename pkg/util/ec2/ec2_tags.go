@@ -13,9 +13,12 @@ import (
 	"io/ioutil"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // GetTags grabs the host tags from the EC2 api
@@ -29,7 +32,7 @@ func GetTags() ([]string, error) {
 
 	iamParams, err := getSecurityCreds()
 	if err != nil {
-		return tags, err
+		return tags, fmt.Errorf("unable to get the credentials of the IAM role of the instance, one with the ec2:DescribeTags permission must be attached to collect the EC2 tags: %s", err)
 	}
 
 	awsCreds := credentials.NewStaticCredentials(iamParams.AccessKeyId,
@@ -54,6 +57,9 @@ func GetTags() ([]string, error) {
 		}},
 	})
 	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "UnauthorizedOperation" {
+			log.Warnf("The IAM role of the instance needs the ec2:DescribeTags permission to collect the EC2 tags")
+		}
 		return tags, fmt.Errorf("unable to get tags from aws, %s", err)
 	}
 
@@ -12,8 +12,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestIsDefaultHostname(t *testing.T) {
@@ -63,6 +67,145 @@ func TestGetHostname(t *testing.T) {
 	assert.Equal(t, lastRequest.URL.Path, "/hostname")
 }
 
+func TestGetAccountID(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, `{"accountId": "123456789012", "region": "us-east-1"}`)
+		lastRequest = r
+	}))
+	defer ts.Close()
+	instanceIdentityURL = ts.URL + "/document/"
+
+	val, err := GetAccountID()
+	assert.Nil(t, err)
+	assert.Equal(t, "123456789012", val)
+	assert.Equal(t, lastRequest.URL.Path, "/document/")
+}
+
+func TestGetInstanceIDIMDSv2(t *testing.T) {
+	expected := "i-0123456789abcdef0"
+	tokenRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			assert.Equal(t, "/api/token", r.URL.Path)
+			assert.Equal(t, "21600", r.Header.Get(tokenTTLHeader))
+			tokenRequests++
+			io.WriteString(w, "my-token")
+		case "GET":
+			if r.Header.Get(tokenHeader) != "my-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, expected)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	tokenURL = ts.URL + "/api/token"
+	token = &ec2Token{}
+
+	val, err := GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val)
+
+	// the token is cached
+	val, err = GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val)
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestGetInstanceIDIMDSv1Fallback(t *testing.T) {
+	expected := "i-0123456789abcdef0"
+	tokenRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			// IMDSv2 isn't supported
+			tokenRequests++
+			w.WriteHeader(http.StatusForbidden)
+		case "GET":
+			assert.Empty(t, r.Header.Get(tokenHeader))
+			io.WriteString(w, expected)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	tokenURL = ts.URL + "/api/token"
+	token = &ec2Token{}
+
+	val, err := GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val)
+
+	// the failure is cached
+	val, err = GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val)
+	assert.Equal(t, 1, tokenRequests)
+
+	// IMDSv2 is tried again once the negative result expired
+	token.unavailableUntil = time.Now()
+	_, err = GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tokenRequests)
+}
+
+func TestGetInstanceIDIMDSv2Disabled(t *testing.T) {
+	config.Datadog.Set("ec2_prefer_imdsv2", false)
+	defer config.Datadog.Set("ec2_prefer_imdsv2", true)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "GET", r.Method)
+		io.WriteString(w, "i-0123456789abcdef0")
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	tokenURL = ts.URL + "/api/token"
+	token = &ec2Token{}
+
+	_, err := GetInstanceID()
+	assert.Nil(t, err)
+}
+
+func TestGetInstanceIDTokenRenewal(t *testing.T) {
+	expected := "i-0123456789abcdef0"
+	tokenRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			tokenRequests++
+			io.WriteString(w, fmt.Sprintf("token-%d", tokenRequests))
+		case "GET":
+			// only the last token is valid
+			if r.Header.Get(tokenHeader) != fmt.Sprintf("token-%d", tokenRequests) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, expected)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	tokenURL = ts.URL + "/api/token"
+	token = &ec2Token{value: "revoked", expirationDate: time.Now().Add(time.Hour)}
+
+	// the revoked token is renewed
+	val, err := GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val)
+	assert.Equal(t, 1, tokenRequests)
+
+	// the expired token is renewed
+	token.expirationDate = time.Now()
+	val, err = GetInstanceID()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, val)
+	assert.Equal(t, 2, tokenRequests)
+}
+
 func TestExtractClusterName(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package ec2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	tokenHeader    = "X-aws-ec2-metadata-token"
+	tokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+)
+
+// declare these as vars not const to ease testing
+var (
+	tokenURL = "http://169.254.169.254/latest/api/token"
+	// tokenLifetime is the lifetime requested for the IMDSv2 session tokens
+	tokenLifetime = 6 * time.Hour
+	// tokenRenewalMargin is how long before its expiration a token is renewed
+	tokenRenewalMargin = time.Minute
+	// tokenUnavailableTTL is how long IMDSv1 is used after a token request failed
+	tokenUnavailableTTL = 5 * time.Minute
+
+	token = &ec2Token{}
+)
+
+// ec2Token caches the IMDSv2 session token, and the failure to get one so
+// that the instances only supporting IMDSv1 don't pay for a token request
+// every time the metadata API is queried.
+type ec2Token struct {
+	sync.Mutex
+	value            string
+	expirationDate   time.Time
+	unavailableUntil time.Time
+}
+
+// get returns the cached token, or fetches a new one when it's about to
+// expire. An error means the metadata API must be queried with IMDSv1.
+func (t *ec2Token) get() (string, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	if now.Before(t.expirationDate) {
+		return t.value, nil
+	}
+	if now.Before(t.unavailableUntil) {
+		return "", fmt.Errorf("no IMDSv2 token available until %s", t.unavailableUntil)
+	}
+
+	value, err := fetchToken()
+	if err != nil {
+		t.unavailableUntil = now.Add(tokenUnavailableTTL)
+		return "", err
+	}
+	t.value = value
+	t.expirationDate = now.Add(tokenLifetime - tokenRenewalMargin)
+	return t.value, nil
+}
+
+// invalidate drops the cached token, for instance after it was refused
+func (t *ec2Token) invalidate() {
+	t.Lock()
+	defer t.Unlock()
+
+	t.value = ""
+	t.expirationDate = time.Time{}
+}
+
+func fetchToken() (string, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	req, err := http.NewRequest("PUT", tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(tokenTTLHeader, strconv.Itoa(int(tokenLifetime.Seconds())))
+
+	res, err := client.Do(req)
+	if err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			// The response to the PUT request is dropped when it needs more hops
+			// than allowed by the instance, like when the agent runs in a
+			// container with a bridge network.
+			log.Infof("Timed out fetching an IMDSv2 token, the hop limit of the instance metadata (HttpPutResponseHopLimit) may be too low, falling back to IMDSv1")
+		}
+		return "", fmt.Errorf("unable to fetch an IMDSv2 token, %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return "", fmt.Errorf("status code %d trying to fetch an IMDSv2 token", res.StatusCode)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the IMDSv2 token, %s", err)
+	}
+	return string(all), nil
+}
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+/*
+Package fips reports whether the agent runs in FIPS mode. In FIPS mode, the
+agent is built with the `fips` build tag and a Go toolchain backed by the
+FIPS validated BoringCrypto module, so that all the TLS connections of the
+agent (forwarder, logs, IPC api, kubelet and API server clients) go through
+it, restricted to the FIPS approved settings.
+*/
+package fips
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// Check asserts that the agent runs in FIPS mode when it's built for it
+// or when `fips_mode` is set, so that it doesn't fall back silently to
+// the non validated crypto of Go.
+func Check() error {
+	if !fipsBuild && !config.Datadog.GetBool("fips_mode") {
+		return nil
+	}
+	if !fipsBuild {
+		return fmt.Errorf("fips_mode is set but the agent isn't built with the fips build tag")
+	}
+	if !Enabled() {
+		return fmt.Errorf("the agent is built with the fips build tag but the FIPS validated crypto module isn't in use, make sure it's built with a BoringCrypto toolchain")
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build fips
+
+package fips
+
+import (
+	"crypto/boring"
+
+	// restricts crypto/tls to the FIPS approved versions, cipher suites,
+	// curves and certificates
+	_ "crypto/tls/fipsonly"
+)
+
+const fipsBuild = true
+
+// Enabled returns whether the crypto of the agent is provided by the FIPS
+// validated BoringCrypto module
+func Enabled() bool {
+	return boring.Enabled()
+}
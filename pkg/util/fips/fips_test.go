@@ -0,0 +1,26 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build !fips
+
+package fips
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestCheck(t *testing.T) {
+	mockConfig := config.Mock()
+	assert.False(t, Enabled())
+	assert.NoError(t, Check())
+
+	mockConfig.Set("fips_mode", true)
+	defer mockConfig.Set("fips_mode", false)
+	assert.Error(t, Check())
+}
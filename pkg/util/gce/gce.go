@@ -27,6 +27,7 @@ type gceMetadata struct {
 
 type gceInstanceMetadata struct {
 	ID          int64
+	Name        string
 	Tags        []string
 	Zone        string
 	MachineType string
@@ -65,6 +66,16 @@ func GetHostAlias() (string, error) {
 	return fmt.Sprintf("%s.%s", instanceName, projectID), nil
 }
 
+// GetProjectID returns the ID of the project of the current GCE instance
+func GetProjectID() (string, error) {
+	projectID, err := getResponseWithMaxLength(metadataURL+"/project/project-id",
+		config.Datadog.GetInt("metadata_endpoints_max_hostname_size"))
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve project ID from GCE: %s", err)
+	}
+	return projectID, nil
+}
+
 // GetClusterName returns the name of the cluster containing the current GCE instance
 func GetClusterName() (string, error) {
 	clusterName, err := getResponseWithMaxLength(metadataURL+"/instance/attributes/cluster-name",
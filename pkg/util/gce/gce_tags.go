@@ -10,7 +10,19 @@ package gce
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// declare these as vars not const to ease testing
+var (
+	computeAPIURL     = "https://www.googleapis.com/compute/v1"
+	computeAPITimeout = 5 * time.Second
 )
 
 // Slice of attributes to exclude from the tags (because they're too long, useless or sensitive)
@@ -63,9 +75,75 @@ func GetTags() ([]string, error) {
 		}
 	}
 
+	if config.Datadog.GetBool("collect_gce_labels") {
+		labels, err := getLabels(metadata)
+		if err != nil {
+			log.Debugf("No GCE labels: %s", err)
+		}
+		for k, v := range labels {
+			tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+		}
+	}
+
 	return tags, nil
 }
 
+// getLabels gets the labels of the instance from the Compute Engine API, as
+// they are not exposed by the metadata server. The service account of the
+// instance needs the compute.instances.get permission.
+func getLabels(metadata gceMetadata) (map[string]string, error) {
+	if metadata.Instance.Name == "" || metadata.Instance.Zone == "" || metadata.Project.ProjectID == "" {
+		return nil, fmt.Errorf("missing the name, zone or project of the instance")
+	}
+
+	tokenResponse, err := getResponse(metadataURL + "/instance/service-accounts/default/token")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a token for the service account of the instance: %s", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.Unmarshal([]byte(tokenResponse), &token); err != nil {
+		return nil, fmt.Errorf("unable to parse the token of the service account of the instance: %s", err)
+	}
+
+	zone := metadata.Instance.Zone[strings.LastIndex(metadata.Instance.Zone, "/")+1:]
+	url := fmt.Sprintf("%s/projects/%s/zones/%s/instances/%s", computeAPIURL, metadata.Project.ProjectID, zone, metadata.Instance.Name)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+token.AccessToken)
+
+	client := http.Client{
+		Timeout: computeAPITimeout,
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusForbidden {
+		log.Warnf("The service account of the instance needs the compute.instances.get permission and the compute read-only scope to collect the GCE labels")
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d trying to GET %s", res.StatusCode, url)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the instance from the Compute Engine API: %s", err)
+	}
+	var instance struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err = json.Unmarshal(all, &instance); err != nil {
+		return nil, fmt.Errorf("unable to parse the instance from the Compute Engine API: %s", err)
+	}
+	return instance.Labels, nil
+}
+
 // isAttributeExcluded returns whether the attribute key should be excluded from the tags
 func isAttributeExcluded(attr string) bool {
 	for _, excluded := range excludedAttributes {
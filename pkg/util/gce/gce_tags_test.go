@@ -17,6 +17,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestGetHostTags(t *testing.T) {
@@ -61,3 +63,47 @@ func TestGetHostTags(t *testing.T) {
 		assert.Contains(t, expectedTags, tag)
 	}
 }
+
+func TestGetHostTagsWithLabels(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("collect_gce_labels", true)
+	defer mockConfig.Set("collect_gce_labels", false)
+
+	content, err := ioutil.ReadFile("test/gce_metadata.json")
+	require.NoError(t, err)
+	forbidden := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			io.WriteString(w, string(content))
+		case "/instance/service-accounts/default/token":
+			assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			io.WriteString(w, `{"access_token":"secret","expires_in":3599,"token_type":"Bearer"}`)
+		case "/projects/test-project/zones/us-east1-b/instances/dd-test":
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			if forbidden {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			io.WriteString(w, `{"name":"dd-test","labels":{"env":"prod","team":"infra"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	computeAPIURL = ts.URL
+
+	tags, err := GetTags()
+	require.NoError(t, err)
+	assert.Contains(t, tags, "env:prod")
+	assert.Contains(t, tags, "team:infra")
+	assert.Contains(t, tags, "project:test-project")
+
+	// the other tags are still collected without the permission to get the labels
+	forbidden = true
+	tags, err = GetTags()
+	require.NoError(t, err)
+	assert.NotContains(t, tags, "env:prod")
+	assert.Contains(t, tags, "project:test-project")
+}
@@ -70,6 +70,22 @@ func TestGetHostAliases(t *testing.T) {
 	assert.Equal(t, "gce-instance-name.gce-project", val)
 }
 
+func TestGetProjectID(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "test-project")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetProjectID()
+	assert.Nil(t, err)
+	assert.Equal(t, "test-project", val)
+	assert.Equal(t, "/project/project-id", lastRequest.URL.Path)
+}
+
 func TestGetClusterName(t *testing.T) {
 	expected := "test-cluster-name"
 	var lastRequest *http.Request
@@ -95,138 +95,202 @@ func Fqdn(hostname string) string {
 	return hostname
 }
 
-// GetHostname retrieve the host name for the Agent, trying to query these
-// environments/api, in order:
-// * GCE
-// * Docker
-// * kubernetes
-// * os
-// * EC2
-func GetHostname() (string, error) {
-	cacheHostnameKey := cache.BuildAgentKey("hostname")
-	if cacheHostname, found := cache.Cache.Get(cacheHostnameKey); found {
-		return cacheHostname.(string), nil
-	}
+// HostnameCandidate is the outcome of a provider of the hostname resolution
+type HostnameCandidate struct {
+	Provider string `json:"provider"`
+	Hostname string `json:"hostname,omitempty"`
+	// Skipped explains why the provider wasn't queried
+	Skipped string `json:"skipped,omitempty"`
+	// Error explains why the provider didn't give a hostname
+	Error string `json:"error,omitempty"`
+}
 
-	var hostName string
-	var err error
-	var provider string
+// HostnameResolution explains how the hostname was resolved: the provider
+// used, and the outcome of every provider of the chain, in order
+type HostnameResolution struct {
+	Hostname   string              `json:"hostname"`
+	Provider   string              `json:"provider"`
+	Candidates []HostnameCandidate `json:"candidates"`
+}
 
-	// try the name provided in the configuration file
-	configName := config.Datadog.GetString("hostname")
-	err = ValidHostname(configName)
-	if err == nil {
-		cache.Cache.Set(cacheHostnameKey, configName, cache.NoExpiration)
-		hostnameProvider.Set("configuration")
-		return configName, err
-	}
+// hostnameProviderStep is a link of the hostname resolution chain
+type hostnameProviderStep struct {
+	name string
+	// stopIfSuccessful ends the resolution with the hostname of this provider,
+	// otherwise it replaces the hostname resolved by the previous providers
+	stopIfSuccessful bool
+	// get returns the hostname of the provider, or why it was skipped. The
+	// hostname resolved by the previous providers is passed along, and pinned
+	// is true when the provider was chosen with hostname_provider, in which
+	// case the conditions on the previous providers are ignored.
+	get func(previous string, pinned bool) (hostname string, skipped string, err error)
+}
 
-	expErr := new(expvar.String)
-	expErr.Set(err.Error())
-	hostnameErrors.Set("configuration/environment", expErr)
+// hostnameProviderChain returns the providers of the hostname resolution, in
+// order, the fqdn being saved to warn about the upcoming default change
+func hostnameProviderChain(fqdn *string) []hostnameProviderStep {
+	return []hostnameProviderStep{
+		{
+			name:             "configuration",
+			stopIfSuccessful: true,
+			get: func(previous string, pinned bool) (string, string, error) {
+				configName := config.Datadog.GetString("hostname")
+				if err := ValidHostname(configName); err != nil {
+					return "", "", err
+				}
+				return configName, "", nil
+			},
+		},
+		{
+			name:             "gce",
+			stopIfSuccessful: true,
+			get: func(previous string, pinned bool) (string, string, error) {
+				getGCEHostname, found := hostname.ProviderCatalog["gce"]
+				if !found {
+					return "", "the GCE provider is not available", nil
+				}
+				log.Debug("GetHostname trying GCE metadata...")
+				name, err := getGCEHostname()
+				return name, "", err
+			},
+		},
+		{
+			name: "fqdn",
+			get: func(previous string, pinned bool) (string, string, error) {
+				log.Debug("GetHostname trying FQDN/`hostname -f`...")
+				name, err := getSystemFQDN()
+				*fqdn = name
+				if !pinned && !config.Datadog.GetBool("hostname_fqdn") {
+					return "", "hostname_fqdn is disabled", nil
+				}
+				return name, "", err
+			},
+		},
+		{
+			name: "container",
+			get: func(previous string, pinned bool) (string, string, error) {
+				isContainerized, containerName := getContainerHostname()
+				if !isContainerized {
+					return "", "the agent is not running in a container", nil
+				}
+				if containerName == "" {
+					return "", "", fmt.Errorf("Unable to get hostname from container API")
+				}
+				return containerName, "", nil
+			},
+		},
+		{
+			name: "os",
+			get: func(previous string, pinned bool) (string, string, error) {
+				if !pinned && previous != "" {
+					return "", "a hostname was resolved by a previous provider", nil
+				}
+				log.Debug("GetHostname trying os...")
+				name, err := os.Hostname()
+				return name, "", err
+			},
+		},
+		{
+			// We use the instance id if we're on an ECS cluster or we're on EC2
+			// and the hostname is one of the default ones
+			name: "aws",
+			get: func(previous string, pinned bool) (string, string, error) {
+				getEC2Hostname, found := hostname.ProviderCatalog["ec2"]
+				if !found {
+					return "", "the EC2 provider is not available", nil
+				}
+				log.Debug("GetHostname trying EC2 metadata...")
+				if !pinned && !ecs.IsECSInstance() && !ec2.IsDefaultHostname(previous) {
+					return "", "", fmt.Errorf("not retrieving hostname from AWS: the host is not an ECS instance, and other providers already retrieve non-default hostnames")
+				}
+				instanceID, err := getEC2Hostname()
+				if err != nil {
+					return "", "", fmt.Errorf("Unable to determine hostname from EC2: %s", err)
+				}
+				if err = ValidHostname(instanceID); err != nil {
+					return "", "", fmt.Errorf("EC2 instance ID is not a valid hostname: %s", err)
+				}
+				return instanceID, "", nil
+			},
+		},
+	}
+}
 
-	log.Debugf("Unable to get the hostname from the config file: %s", err)
-	log.Debug("Trying to determine a reliable host name automatically...")
+// ResolveHostname resolves the host name for the Agent, without the cache,
+// and explains how it was resolved. These environments/api are queried, in
+// order:
+// * configuration
+// * GCE
+// * FQDN, when hostname_fqdn is enabled
+// * Docker/kubernetes, when the agent is containerized
+// * os, when no hostname was found yet
+// * EC2, when the hostname is a default EC2 one or on ECS
+// When hostname_provider is set, only the configuration and that provider
+// are used.
+func ResolveHostname() (*HostnameResolution, error) {
+	resolution := &HostnameResolution{}
 
 	// if fargate we strip the hostname
 	if ecs.IsFargateInstance() {
-		cache.Cache.Set(cacheHostnameKey, "", cache.NoExpiration)
-		return "", nil
-	}
-
-	// GCE metadata
-	log.Debug("GetHostname trying GCE metadata...")
-	if getGCEHostname, found := hostname.ProviderCatalog["gce"]; found {
-		gceName, err := getGCEHostname()
-		if err == nil {
-			cache.Cache.Set(cacheHostnameKey, gceName, cache.NoExpiration)
-			hostnameProvider.Set("gce")
-			return gceName, err
-		}
-		expErr := new(expvar.String)
-		expErr.Set(err.Error())
-		hostnameErrors.Set("gce", expErr)
-		log.Debug("Unable to get hostname from GCE: ", err)
+		resolution.Provider = "fargate"
+		return resolution, nil
 	}
 
-	// FQDN
-	log.Debug("GetHostname trying FQDN/`hostname -f`...")
-	fqdn, err := getSystemFQDN()
-	if config.Datadog.GetBool("hostname_fqdn") && err == nil {
-		hostName = fqdn
-		provider = "fqdn"
-	} else {
-		if err != nil {
-			expErr := new(expvar.String)
-			expErr.Set(err.Error())
-			hostnameErrors.Set("fqdn", expErr)
+	var fqdn string
+	chain := hostnameProviderChain(&fqdn)
+
+	pinnedProvider := config.Datadog.GetString("hostname_provider")
+	if pinnedProvider != "" {
+		found := false
+		for _, step := range chain {
+			found = found || step.name == pinnedProvider
+		}
+		if !found {
+			return resolution, fmt.Errorf("unknown hostname_provider %q", pinnedProvider)
 		}
-		log.Debug("Unable to get FQDN from system: ", err)
 	}
 
-	isContainerized, containerName := getContainerHostname()
-	if isContainerized {
-		if containerName != "" {
-			hostName = containerName
-			provider = "container"
-		} else {
-			expErr := new(expvar.String)
-			expErr.Set("Unable to get hostname from container API")
-			hostnameErrors.Set("container", expErr)
+	stopped := false
+	for _, step := range chain {
+		candidate := HostnameCandidate{Provider: step.name}
+
+		if stopped {
+			candidate.Skipped = fmt.Sprintf("the hostname was resolved by %s", resolution.Provider)
+			resolution.Candidates = append(resolution.Candidates, candidate)
+			continue
 		}
-	}
 
-	if hostName == "" {
-		// os
-		log.Debug("GetHostname trying os...")
-		systemName, err := os.Hostname()
-		if err == nil {
-			hostName = systemName
-			provider = "os"
-		} else {
-			expErr := new(expvar.String)
-			expErr.Set(err.Error())
-			hostnameErrors.Set("os", expErr)
-			log.Debug("Unable to get hostname from OS: ", err)
+		pinned := pinnedProvider == step.name
+		if pinnedProvider != "" && !pinned && step.name != "configuration" {
+			candidate.Skipped = fmt.Sprintf("hostname_provider is set to %s", pinnedProvider)
+			resolution.Candidates = append(resolution.Candidates, candidate)
+			continue
 		}
-	}
 
-	/* at this point we've either the hostname from the os or an empty string */
-
-	// We use the instance id if we're on an ECS cluster or we're on EC2
-	// and the hostname is one of the default ones
-	if getEC2Hostname, found := hostname.ProviderCatalog["ec2"]; found {
-		log.Debug("GetHostname trying EC2 metadata...")
-		if ecs.IsECSInstance() || ec2.IsDefaultHostname(hostName) {
-			instanceID, err := getEC2Hostname()
-			if err == nil {
-				err = ValidHostname(instanceID)
-				if err == nil {
-					hostName = instanceID
-					provider = "aws"
-				} else {
-					expErr := new(expvar.String)
-					expErr.Set(err.Error())
-					hostnameErrors.Set("aws", expErr)
-					log.Debug("EC2 instance ID is not a valid hostname: ", err)
-				}
-			} else {
-				expErr := new(expvar.String)
-				expErr.Set(err.Error())
-				hostnameErrors.Set("aws", expErr)
-				log.Debug("Unable to determine hostname from EC2: ", err)
-			}
-		} else {
-			err := fmt.Errorf("not retrieving hostname from AWS: the host is not an ECS instance, and other providers already retrieve non-default hostnames")
-			log.Debug(err.Error())
-			expErr := new(expvar.String)
-			expErr.Set(err.Error())
-			hostnameErrors.Set("aws", expErr)
+		name, skipped, err := step.get(resolution.Hostname, pinned)
+		switch {
+		case skipped != "":
+			candidate.Skipped = skipped
+		case err != nil:
+			candidate.Error = err.Error()
+			log.Debugf("Unable to get the hostname from %s: %s", step.name, err)
+		case name == "":
+			candidate.Error = "empty hostname"
+		default:
+			candidate.Hostname = name
+			resolution.Hostname = name
+			resolution.Provider = step.name
+			stopped = step.stopIfSuccessful
 		}
+		resolution.Candidates = append(resolution.Candidates, candidate)
+	}
+
+	if resolution.Provider == "configuration" || resolution.Provider == "gce" {
+		return resolution, nil
 	}
 
 	h, err := os.Hostname()
-	if err == nil && !config.Datadog.GetBool("hostname_fqdn") && fqdn != "" && hostName == h && h != fqdn {
+	if err == nil && !config.Datadog.GetBool("hostname_fqdn") && fqdn != "" && resolution.Hostname == h && h != fqdn {
 		if runtime.GOOS != "windows" {
 			// REMOVEME: This should be removed when the default `hostname_fqdn` is set to true
 			log.Warnf("DEPRECATION NOTICE: The agent resolved your hostname as '%s'. However in a future version, it will be resolved as '%s' by default. To enable the future behavior, please enable the `hostname_fqdn` flag in the configuration. For more information: https://dtdg.co/flag-hostname-fqdn", h, fqdn)
@@ -236,19 +300,41 @@ func GetHostname() (string, error) {
 	}
 
 	// If at this point we don't have a name, bail out
-	if hostName == "" {
-		err = fmt.Errorf("unable to reliably determine the host name. You can define one in the agent config file or in your hosts file")
+	if resolution.Hostname == "" {
+		return resolution, fmt.Errorf("unable to reliably determine the host name. You can define one in the agent config file or in your hosts file")
+	}
+	return resolution, nil
+}
+
+// GetHostname retrieves the host name for the Agent, resolving it with
+// ResolveHostname the first time
+func GetHostname() (string, error) {
+	cacheHostnameKey := cache.BuildAgentKey("hostname")
+	if cacheHostname, found := cache.Cache.Get(cacheHostnameKey); found {
+		return cacheHostname.(string), nil
+	}
+
+	resolution, err := ResolveHostname()
+	if resolution.Provider == "" {
+		log.Debugf("Unable to resolve the hostname: %s", err)
 	} else {
-		// we got a hostname, residual errors are irrelevant now
-		err = nil
+		log.Debugf("Hostname %q resolved by the %s provider", resolution.Hostname, resolution.Provider)
 	}
 
-	cache.Cache.Set(cacheHostnameKey, hostName, cache.NoExpiration)
-	hostnameProvider.Set(provider)
+	for _, candidate := range resolution.Candidates {
+		if candidate.Error != "" {
+			expErr := new(expvar.String)
+			expErr.Set(candidate.Error)
+			hostnameErrors.Set(candidate.Provider, expErr)
+		}
+	}
 	if err != nil {
 		expErr := new(expvar.String)
-		expErr.Set(fmt.Sprintf(err.Error()))
+		expErr.Set(err.Error())
 		hostnameErrors.Set("all", expErr)
 	}
-	return hostName, err
+
+	cache.Cache.Set(cacheHostnameKey, resolution.Hostname, cache.NoExpiration)
+	hostnameProvider.Set(resolution.Provider)
+	return resolution.Hostname, err
 }
@@ -6,10 +6,14 @@
 package util
 
 import (
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestIsLocal(t *testing.T) {
@@ -31,3 +35,54 @@ func TestValidHostname(t *testing.T) {
 	err = ValidHostname("data🐕hq.com")
 	assert.NotNil(t, err)
 }
+
+func TestResolveHostnameFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("hostname", "my-host")
+	defer mockConfig.Set("hostname", "")
+
+	resolution, err := ResolveHostname()
+	require.NoError(t, err)
+	assert.Equal(t, "my-host", resolution.Hostname)
+	assert.Equal(t, "configuration", resolution.Provider)
+
+	require.Len(t, resolution.Candidates, 6)
+	assert.Equal(t, HostnameCandidate{Provider: "configuration", Hostname: "my-host"}, resolution.Candidates[0])
+	for _, candidate := range resolution.Candidates[1:] {
+		assert.Equal(t, "the hostname was resolved by configuration", candidate.Skipped)
+	}
+}
+
+func TestResolveHostnamePinnedProvider(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("hostname_provider", "os")
+	defer mockConfig.Set("hostname_provider", "")
+
+	osHostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	resolution, err := ResolveHostname()
+	require.NoError(t, err)
+	assert.Equal(t, osHostname, resolution.Hostname)
+	assert.Equal(t, "os", resolution.Provider)
+
+	for _, candidate := range resolution.Candidates {
+		switch candidate.Provider {
+		case "configuration":
+			assert.Equal(t, "hostname is empty", candidate.Error)
+		case "os":
+			assert.Equal(t, osHostname, candidate.Hostname)
+		default:
+			assert.Equal(t, "hostname_provider is set to os", candidate.Skipped)
+		}
+	}
+}
+
+func TestResolveHostnameUnknownPinnedProvider(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("hostname_provider", "foo")
+	defer mockConfig.Set("hostname_provider", "")
+
+	_, err := ResolveHostname()
+	assert.EqualError(t, err, `unknown hostname_provider "foo"`)
+}
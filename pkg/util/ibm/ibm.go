@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package ibm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// declare these as vars not const to ease testing
+var (
+	metadataURL = "http://169.254.169.254"
+	timeout     = 300 * time.Millisecond
+)
+
+// apiVersion is the version of the metadata service requested by the agent
+const apiVersion = "2022-03-01"
+
+type ibmInstanceMetadata struct {
+	ID      string `json:"id"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	Zone struct {
+		Name string `json:"name"`
+	} `json:"zone"`
+}
+
+// GetHostAlias returns the instance ID from the IBM Cloud Metadata api
+func GetHostAlias() (string, error) {
+	metadata, err := getInstanceMetadata()
+	if err != nil {
+		return "", fmt.Errorf("IBM HostAliases: unable to query metadata endpoint: %s", err)
+	}
+	if len(metadata.ID) > config.Datadog.GetInt("metadata_endpoints_max_hostname_size") {
+		return "", fmt.Errorf("IBM HostAliases: instance ID with length > to %v", config.Datadog.GetInt("metadata_endpoints_max_hostname_size"))
+	}
+	return metadata.ID, nil
+}
+
+// GetTags returns the instance ID, the region and the profile of the VPC
+// instance from the IBM Cloud Metadata api
+func GetTags() ([]string, error) {
+	metadata, err := getInstanceMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("unable to query metadata endpoint: %s", err)
+	}
+
+	return []string{
+		fmt.Sprintf("instance-id:%s", metadata.ID),
+		fmt.Sprintf("region:%s", regionFromZone(metadata.Zone.Name)),
+		fmt.Sprintf("instance-type:%s", metadata.Profile.Name),
+	}, nil
+}
+
+// regionFromZone returns the region of a zone, like "us-south" for "us-south-1"
+func regionFromZone(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+func getInstanceMetadata() (*ibmInstanceMetadata, error) {
+	token, err := getToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", metadataURL+"/metadata/v1/instance?version="+apiVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	all, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &ibmInstanceMetadata{}
+	if err := json.Unmarshal(all, metadata); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the instance metadata: %s", err)
+	}
+	return metadata, nil
+}
+
+// getToken returns a short-lived token, the metadata service of IBM Cloud
+// can't be queried without one
+func getToken() (string, error) {
+	req, err := http.NewRequest("PUT", metadataURL+"/instance_identity/v1/token?version="+apiVersion, bytes.NewBufferString(`{"expires_in": 300}`))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Metadata-Flavor", "ibm")
+	req.Header.Add("Content-Type", "application/json")
+
+	all, err := doRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to get a token: %s", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(all, &token); err != nil {
+		return "", fmt.Errorf("unable to unmarshal the token: %s", err)
+	}
+	return token.AccessToken, nil
+}
+
+func doRequest(req *http.Request) ([]byte, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d trying to %s %s", res.StatusCode, req.Method, req.URL)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading response from ibm metadata endpoint: %s", err)
+	}
+
+	return all, nil
+}
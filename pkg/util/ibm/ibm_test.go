@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package ibm
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMetadataServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, apiVersion, r.URL.Query().Get("version"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/instance_identity/v1/token":
+			assert.Equal(t, "ibm", r.Header.Get("Metadata-Flavor"))
+			io.WriteString(w, `{"access_token": "my-token", "expires_in": 300}`)
+		case r.Method == "GET" && r.URL.Path == "/metadata/v1/instance":
+			if r.Header.Get("Authorization") != "Bearer my-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, `{
+				"id": "0717_5b6bb9f5-2c59-4cb4-8b5a-bbc8ba6e6e0a",
+				"name": "my-instance",
+				"profile": {"name": "bx2-2x8"},
+				"zone": {"name": "us-south-1"}
+			}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGetHostAlias(t *testing.T) {
+	ts := newMetadataServer(t)
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetHostAlias()
+	assert.Nil(t, err)
+	assert.Equal(t, "0717_5b6bb9f5-2c59-4cb4-8b5a-bbc8ba6e6e0a", val)
+}
+
+func TestGetTags(t *testing.T) {
+	ts := newMetadataServer(t)
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"instance-id:0717_5b6bb9f5-2c59-4cb4-8b5a-bbc8ba6e6e0a",
+		"region:us-south",
+		"instance-type:bx2-2x8",
+	}, tags)
+}
+
+func TestRegionFromZone(t *testing.T) {
+	assert.Equal(t, "eu-de", regionFromZone("eu-de-2"))
+	assert.Equal(t, "", regionFromZone(""))
+}
@@ -39,11 +39,12 @@ var (
 )
 
 const (
-	configMapDCAToken         = "datadogtoken"
-	tokenTime                 = "tokenTimestamp"
-	tokenKey                  = "tokenKey"
-	metadataMapExpire         = 2 * time.Minute
-	metadataMapperCachePrefix = "KubernetesMetadataMapping"
+	configMapDCAToken          = "datadogtoken"
+	tokenTime                  = "tokenTimestamp"
+	tokenKey                   = "tokenKey"
+	metadataMapExpire          = 2 * time.Minute
+	metadataMapperCachePrefix  = "KubernetesMetadataMapping"
+	namespaceLabelsCachePrefix = "KubernetesNamespaceLabels"
 )
 
 // APIClient provides authenticated access to the
@@ -211,6 +212,7 @@ func (c *APIClient) ComponentStatuses() (*v1.ComponentStatusList, error) {
 }
 
 // GetTokenFromConfigmap returns the value of the `tokenValue` from the `tokenKey` in the ConfigMap `configMapDCAToken` if its timestamp is less than tokenTimeout old.
+// The boolean reports whether the ConfigMap exists, so that the token can be stored in it even when it's not set yet.
 func (c *APIClient) GetTokenFromConfigmap(token string, tokenTimeout int64) (string, bool, error) {
 	namespace := common.GetResourcesNamespace()
 	tokenConfigMap, err := c.Cl.CoreV1().ConfigMaps(namespace).Get(configMapDCAToken, metav1.GetOptions{})
@@ -223,8 +225,8 @@ func (c *APIClient) GetTokenFromConfigmap(token string, tokenTimeout int64) (str
 	eventTokenKey := fmt.Sprintf("%s.%s", token, tokenKey)
 	tokenValue, found := tokenConfigMap.Data[eventTokenKey]
 	if !found {
-		log.Errorf("%s was not found in the ConfigMap %s", eventTokenKey, configMapDCAToken)
-		return "", found, ErrNotFound
+		log.Infof("%s was not found in the ConfigMap %s, it will be set", eventTokenKey, configMapDCAToken)
+		return "", true, ErrNotFound
 	}
 	log.Infof("%s is %q", token, tokenValue)
 
@@ -260,6 +262,9 @@ func (c *APIClient) UpdateTokenInConfigmap(token, tokenValue string) error {
 		return err
 	}
 
+	if tokenConfigMap.Data == nil {
+		tokenConfigMap.Data = make(map[string]string)
+	}
 	eventTokenKey := fmt.Sprintf("%s.%s", token, tokenKey)
 	tokenConfigMap.Data[eventTokenKey] = tokenValue
 
@@ -284,6 +289,23 @@ func (c *APIClient) NodeLabels(nodeName string) (map[string]string, error) {
 	return node.Labels, nil
 }
 
+// NamespaceLabels is used to fetch the labels attached to a given namespace.
+// They are cached as namespaces are shared by many pods and rarely change.
+func (c *APIClient) NamespaceLabels(ns string) (map[string]string, error) {
+	cacheKey := cache.BuildAgentKey(namespaceLabelsCachePrefix, ns)
+	if cached, found := cache.Cache.Get(cacheKey); found {
+		if labels, ok := cached.(map[string]string); ok {
+			return labels, nil
+		}
+	}
+	namespace, err := c.Cl.CoreV1().Namespaces().Get(ns, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cache.Cache.Set(cacheKey, namespace.Labels, metadataMapExpire)
+	return namespace.Labels, nil
+}
+
 // GetNodeForPod retrieves a pod and returns the name of the node it is scheduled on
 func (c *APIClient) GetNodeForPod(namespace, pod_name string) (string, error) {
 	pod, err := c.Cl.CoreV1().Pods(namespace).Get(pod_name, metav1.GetOptions{})
@@ -57,3 +57,9 @@ func GetNodeLabels(nodeName string) (map[string]string, error) {
 	log.Errorf("GetNodeLabels not implemented %s", ErrNotCompiled.Error())
 	return nil, nil
 }
+
+// GetNamespaceLabels retrieves the labels of the queried namespace.
+func GetNamespaceLabels(ns string) (map[string]string, error) {
+	log.Errorf("GetNamespaceLabels not implemented %s", ErrNotCompiled.Error())
+	return nil, nil
+}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+)
+
+func TestTokenConfigmap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	cl := &APIClient{Cl: client, timeoutSeconds: 5}
+
+	// without ConfigMap, the token can't be stored
+	_, found, err := cl.GetTokenFromConfigmap("event", 3600)
+	assert.Equal(t, ErrNotFound, err)
+	assert.False(t, found)
+
+	// an empty ConfigMap gets the token once it's updated
+	_, err = client.CoreV1().ConfigMaps(common.GetResourcesNamespace()).Create(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: configMapDCAToken},
+	})
+	require.NoError(t, err)
+	_, found, err = cl.GetTokenFromConfigmap("event", 3600)
+	assert.Equal(t, ErrNotFound, err)
+	assert.True(t, found)
+
+	require.NoError(t, cl.UpdateTokenInConfigmap("event", "1234"))
+	token, found, err := cl.GetTokenFromConfigmap("event", 3600)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "1234", token)
+}
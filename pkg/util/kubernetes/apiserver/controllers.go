@@ -8,10 +8,13 @@
 package apiserver
 
 import (
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/orchestrator"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/hpa"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 )
@@ -34,6 +37,10 @@ var controllerCatalog = map[string]controllerFuncs{
 		func() bool { return config.Datadog.GetBool("cluster_checks.enabled") },
 		startServicesInformer,
 	},
+	"orchestrator": {
+		func() bool { return config.Datadog.GetBool("orchestrator_explorer.enabled") },
+		startOrchestratorController,
+	},
 }
 
 type ControllerContext struct {
@@ -101,3 +108,23 @@ func startServicesInformer(ctx ControllerContext) error {
 
 	return nil
 }
+
+func startOrchestratorController(ctx ControllerContext) error {
+	// The UID of the kube-system namespace identifies the cluster
+	kubeSystem, err := ctx.Client.CoreV1().Namespaces().Get("kube-system", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	sender := orchestrator.NewSender(clustername.GetClusterName(), string(kubeSystem.UID))
+	orchestratorController := NewOrchestratorController(
+		ctx.InformerFactory.Core().V1().Pods(),
+		ctx.InformerFactory.Apps().V1().Deployments(),
+		ctx.InformerFactory.Apps().V1().ReplicaSets(),
+		ctx.InformerFactory.Core().V1().Nodes(),
+		ctx.LeaderElector,
+		sender,
+	)
+	go orchestratorController.Run(ctx.StopCh)
+
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/leaderelection"
 	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -34,6 +35,15 @@ const (
 	clientTimeout              = 2 * time.Second
 )
 
+// Resources the leader election lock can be held on, see the
+// `leader_election_resource` option.
+const (
+	autoLock             = "auto"
+	configMapsLock       = "configmaps"
+	leasesLock           = "leases"
+	configMapsLeasesLock = "configmapsleases"
+)
+
 var (
 	globalLeaderEngine *LeaderEngine
 )
@@ -52,6 +62,8 @@ type LeaderEngine struct {
 	LeaseName           string
 	LeaderNamespace     string
 	coreClient          corev1.CoreV1Interface
+	leaseClient         leaseClient
+	lockType            string
 	ServiceName         string
 	leaderIdentityMutex sync.RWMutex
 	leaderElector       *leaderelection.LeaderElector
@@ -128,12 +140,25 @@ func (le *LeaderEngine) init() error {
 	}
 
 	le.coreClient = apiClient.Cl.CoreV1().(*corev1.CoreV1Client)
+	le.leaseClient = &restLeaseClient{client: apiClient.Cl.Discovery().RESTClient()}
+
+	le.lockType = config.Datadog.GetString("leader_election_resource")
+	switch le.lockType {
+	case autoLock:
+		le.lockType = le.detectLockType(apiClient.Cl.Discovery())
+	case configMapsLock, leasesLock, configMapsLeasesLock:
+	default:
+		return fmt.Errorf("unknown leader election resource %q", le.lockType)
+	}
+	log.Debugf("Leader election resource: %s", le.lockType)
 
-	// check if we can get ConfigMap.
-	_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
-	if err != nil && errors.IsNotFound(err) == false {
-		log.Errorf("Cannot retrieve ConfigMap from the %s namespace: %s", le.LeaderNamespace, err)
-		return err
+	if le.lockType != leasesLock {
+		// check if we can get ConfigMap.
+		_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) == false {
+			log.Errorf("Cannot retrieve ConfigMap from the %s namespace: %s", le.LeaderNamespace, err)
+			return err
+		}
 	}
 
 	le.leaderElector, err = le.newElection()
@@ -145,6 +170,23 @@ func (le *LeaderEngine) init() error {
 	return nil
 }
 
+// detectLockType returns the lock to use when the resource is auto: the
+// Lease when the apiserver serves them and the agent is allowed to get them,
+// the ConfigMap otherwise. The ConfigMap is kept up to date along with the
+// Lease so that the agents still using it follow the same leader during a
+// rolling update.
+func (le *LeaderEngine) detectLockType(discoveryCl discovery.DiscoveryInterface) string {
+	if _, err := discoveryCl.ServerResourcesForGroupVersion(leaseGroupVersion); err != nil {
+		log.Debugf("Leases are not available, using the ConfigMap for the leader election: %s", err)
+		return configMapsLock
+	}
+	if _, err := le.leaseClient.Get(le.LeaderNamespace, le.LeaseName); err != nil && !errors.IsNotFound(err) {
+		log.Infof("Cannot retrieve Lease from the %s namespace, using the ConfigMap for the leader election: %s", le.LeaderNamespace, err)
+		return configMapsLock
+	}
+	return configMapsLeasesLock
+}
+
 // EnsureLeaderElectionRuns start the Leader election process if not already running,
 // return nil if the process is effectively running
 func (le *LeaderEngine) EnsureLeaderElectionRuns() error {
@@ -226,6 +268,7 @@ func (le *LeaderEngine) IsLeader() bool {
 }
 
 // GetLeaderElectionRecord is used in for the Flare and for the Status commands.
+// The record of the Lease is returned when it exists, the one of the ConfigMap otherwise.
 func GetLeaderElectionRecord() (leaderDetails rl.LeaderElectionRecord, err error) {
 	var led rl.LeaderElectionRecord
 	client, err := apiserver.GetAPIClient()
@@ -233,9 +276,17 @@ func GetLeaderElectionRecord() (leaderDetails rl.LeaderElectionRecord, err error
 		return led, err
 	}
 
+	leaderNamespace := common.GetResourcesNamespace()
+	leases := &restLeaseClient{client: client.Cl.Discovery().RESTClient()}
+	leaderElectionLease, err := leases.Get(leaderNamespace, defaultLeaseName)
+	if err == nil {
+		log.Debugf("LeaderElection lease is %#v", leaderElectionLease)
+		return *leaseSpecToRecord(&leaderElectionLease.Spec), nil
+	}
+	log.Debugf("Cannot get the LeaderElection lease, falling back to the cm: %s", err)
+
 	c := client.Cl.CoreV1()
 
-	leaderNamespace := common.GetResourcesNamespace()
 	leaderElectionCM, err := c.ConfigMaps(leaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
 	if err != nil {
 		return led, err
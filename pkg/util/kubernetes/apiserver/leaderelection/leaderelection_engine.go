@@ -40,9 +40,9 @@ func (le *LeaderEngine) getCurrentLeader() (string, *v1.ConfigMap, error) {
 	return electionRecord.HolderIdentity, configMap, err
 }
 
-// newElection creates an election.
-// If `namespace`/`election` does not exist, it is created.
-func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
+// newConfigMapLock returns a lock on the `namespace`/`election` ConfigMap,
+// the ConfigMap is created if it does not exist.
+func (le *LeaderEngine) newConfigMapLock(resourceLockConfig rl.ResourceLockConfig) (rl.Interface, error) {
 	// We first want to check if the ConfigMap the Leader Election is based on exists.
 	_, err := le.coreClient.ConfigMaps(le.LeaderNamespace).Get(le.LeaseName, metav1.GetOptions{})
 
@@ -68,12 +68,59 @@ func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("Current registered leader is %q, building leader elector %q as candidate", currentLeader, le.HolderIdentity)
+	log.Debugf("Current registered leader in the configmap/%s is %q", le.LeaseName, currentLeader)
+
+	return rl.New(
+		rl.ConfigMapsResourceLock,
+		configMap.ObjectMeta.Namespace,
+		configMap.ObjectMeta.Name,
+		le.coreClient,
+		resourceLockConfig,
+	)
+}
+
+// newLeaseLock returns a lock on the `namespace`/`election` Lease.
+func (le *LeaderEngine) newLeaseLock(resourceLockConfig rl.ResourceLockConfig) rl.Interface {
+	return &leaseLock{
+		leaseMeta: metav1.ObjectMeta{
+			Name:      le.LeaseName,
+			Namespace: le.LeaderNamespace,
+		},
+		client:     le.leaseClient,
+		lockConfig: resourceLockConfig,
+	}
+}
+
+// newLock returns the lock of the election according to the lock type.
+func (le *LeaderEngine) newLock(resourceLockConfig rl.ResourceLockConfig) (rl.Interface, error) {
+	switch le.lockType {
+	case leasesLock:
+		return le.newLeaseLock(resourceLockConfig), nil
+	case configMapsLeasesLock:
+		configMapLock, err := le.newConfigMapLock(resourceLockConfig)
+		if err != nil {
+			return nil, err
+		}
+		return &multiLock{
+			primary:   le.newLeaseLock(resourceLockConfig),
+			secondary: configMapLock,
+		}, nil
+	default:
+		return le.newConfigMapLock(resourceLockConfig)
+	}
+}
+
+// newElection creates an election.
+// If `namespace`/`election` does not exist, it is created.
+// The lock is a ConfigMap, a Lease, or both while migrating from the
+// ConfigMap to the Lease.
+func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 	callbacks := ld.LeaderCallbacks{
 		OnNewLeader: func(identity string) {
 			le.leaderIdentityMutex.Lock()
 			le.leaderIdentity = identity
 			le.leaderIdentityMutex.Unlock()
+			leaderTransitions.Inc()
 
 			log.Infof("New leader %q", identity)
 		},
@@ -81,6 +128,7 @@ func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 			le.leaderIdentityMutex.Lock()
 			le.leaderIdentity = le.HolderIdentity
 			le.leaderIdentityMutex.Unlock()
+			isLeader.Set(1)
 
 			log.Infof("Started leading as %q...", le.HolderIdentity)
 		},
@@ -90,6 +138,7 @@ func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 			le.leaderIdentityMutex.Lock()
 			le.leaderIdentity = ""
 			le.leaderIdentityMutex.Unlock()
+			isLeader.Set(0)
 
 			log.Infof("Stopped leading %q", le.HolderIdentity)
 		},
@@ -105,16 +154,11 @@ func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 		Identity:      le.HolderIdentity,
 		EventRecorder: evRec,
 	}
-	leaderElectorInterface, err := rl.New(
-		rl.ConfigMapsResourceLock,
-		configMap.ObjectMeta.Namespace,
-		configMap.ObjectMeta.Name,
-		le.coreClient,
-		resourceLockConfig,
-	)
+	leaderElectorInterface, err := le.newLock(resourceLockConfig)
 	if err != nil {
 		return nil, err
 	}
+	log.Debugf("Building leader elector %q as candidate on %s", le.HolderIdentity, leaderElectorInterface.Describe())
 
 	electionConfig := ld.LeaderElectionConfig{
 		Lock:          leaderElectorInterface,
@@ -0,0 +1,200 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderelection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The vendored client-go predates the coordination.k8s.io API group, the
+// Lease objects are handled through the raw REST client.
+const (
+	leaseGroupVersion = "coordination.k8s.io/v1beta1"
+	leaseKind         = "Lease"
+)
+
+// lease is the subset of a coordination.k8s.io Lease used by the leader election.
+type lease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              leaseSpec `json:"spec,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       *string           `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32            `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *metav1.MicroTime `json:"acquireTime,omitempty"`
+	RenewTime            *metav1.MicroTime `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32            `json:"leaseTransitions,omitempty"`
+}
+
+// leaseClient gets, creates and updates the Lease objects.
+type leaseClient interface {
+	Get(namespace, name string) (*lease, error)
+	Create(l *lease) (*lease, error)
+	Update(l *lease) (*lease, error)
+}
+
+// restLeaseClient implements leaseClient on top of a REST client of the apiserver.
+type restLeaseClient struct {
+	client rest.Interface
+}
+
+func leasesPath(namespace string) string {
+	return fmt.Sprintf("/apis/%s/namespaces/%s/leases", leaseGroupVersion, namespace)
+}
+
+func (c *restLeaseClient) Get(namespace, name string) (*lease, error) {
+	raw, err := c.client.Get().AbsPath(leasesPath(namespace), name).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+	return decodeLease(raw)
+}
+
+func (c *restLeaseClient) Create(l *lease) (*lease, error) {
+	body, err := encodeLease(l)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.client.Post().AbsPath(leasesPath(l.Namespace)).SetHeader("Content-Type", "application/json").Body(body).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+	return decodeLease(raw)
+}
+
+func (c *restLeaseClient) Update(l *lease) (*lease, error) {
+	body, err := encodeLease(l)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.client.Put().AbsPath(leasesPath(l.Namespace), l.Name).SetHeader("Content-Type", "application/json").Body(body).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+	return decodeLease(raw)
+}
+
+func encodeLease(l *lease) ([]byte, error) {
+	l.APIVersion = leaseGroupVersion
+	l.Kind = leaseKind
+	return json.Marshal(l)
+}
+
+func decodeLease(raw []byte) (*lease, error) {
+	l := &lease{}
+	if err := json.Unmarshal(raw, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// leaseLock implements the resourcelock.Interface with a Lease object.
+type leaseLock struct {
+	leaseMeta  metav1.ObjectMeta
+	client     leaseClient
+	lockConfig rl.ResourceLockConfig
+	lease      *lease
+}
+
+// Get returns the election record from the Lease spec.
+func (ll *leaseLock) Get() (*rl.LeaderElectionRecord, error) {
+	l, err := ll.client.Get(ll.leaseMeta.Namespace, ll.leaseMeta.Name)
+	if err != nil {
+		return nil, err
+	}
+	ll.lease = l
+	return leaseSpecToRecord(&l.Spec), nil
+}
+
+// Create attempts to create a Lease holding the election record.
+func (ll *leaseLock) Create(ler rl.LeaderElectionRecord) error {
+	l, err := ll.client.Create(&lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ll.leaseMeta.Name,
+			Namespace: ll.leaseMeta.Namespace,
+		},
+		Spec: recordToLeaseSpec(&ler),
+	})
+	if err != nil {
+		return err
+	}
+	ll.lease = l
+	return nil
+}
+
+// Update will update the spec of the existing Lease.
+func (ll *leaseLock) Update(ler rl.LeaderElectionRecord) error {
+	if ll.lease == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+	ll.lease.Spec = recordToLeaseSpec(&ler)
+	l, err := ll.client.Update(ll.lease)
+	if err != nil {
+		return err
+	}
+	ll.lease = l
+	return nil
+}
+
+// RecordEvent logs the event, the Lease type is unknown to the event recorder.
+func (ll *leaseLock) RecordEvent(s string) {
+	log.Debugf("%s: %s", ll.Describe(), s)
+}
+
+// Describe is used to convert details on the current resource lock into a string.
+func (ll *leaseLock) Describe() string {
+	return fmt.Sprintf("%v/%v", ll.leaseMeta.Namespace, ll.leaseMeta.Name)
+}
+
+// Identity returns the Identity of the lock.
+func (ll *leaseLock) Identity() string {
+	return ll.lockConfig.Identity
+}
+
+func leaseSpecToRecord(spec *leaseSpec) *rl.LeaderElectionRecord {
+	record := &rl.LeaderElectionRecord{}
+	if spec.HolderIdentity != nil {
+		record.HolderIdentity = *spec.HolderIdentity
+	}
+	if spec.LeaseDurationSeconds != nil {
+		record.LeaseDurationSeconds = int(*spec.LeaseDurationSeconds)
+	}
+	if spec.LeaseTransitions != nil {
+		record.LeaderTransitions = int(*spec.LeaseTransitions)
+	}
+	if spec.AcquireTime != nil {
+		record.AcquireTime = metav1.Time{Time: spec.AcquireTime.Time}
+	}
+	if spec.RenewTime != nil {
+		record.RenewTime = metav1.Time{Time: spec.RenewTime.Time}
+	}
+	return record
+}
+
+func recordToLeaseSpec(ler *rl.LeaderElectionRecord) leaseSpec {
+	holderIdentity := ler.HolderIdentity
+	leaseDurationSeconds := int32(ler.LeaseDurationSeconds)
+	leaseTransitions := int32(ler.LeaderTransitions)
+	return leaseSpec{
+		HolderIdentity:       &holderIdentity,
+		LeaseDurationSeconds: &leaseDurationSeconds,
+		AcquireTime:          &metav1.MicroTime{Time: ler.AcquireTime.Time},
+		RenewTime:            &metav1.MicroTime{Time: ler.RenewTime.Time},
+		LeaseTransitions:     &leaseTransitions,
+	}
+}
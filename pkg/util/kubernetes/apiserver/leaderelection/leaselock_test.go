@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderelection
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+var leasesResource = schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}
+
+// fakeLeaseClient stores the leases in memory.
+type fakeLeaseClient struct {
+	sync.Mutex
+	leases map[string]lease
+}
+
+func newFakeLeaseClient() *fakeLeaseClient {
+	return &fakeLeaseClient{leases: make(map[string]lease)}
+}
+
+func (c *fakeLeaseClient) Get(namespace, name string) (*lease, error) {
+	c.Lock()
+	defer c.Unlock()
+	l, found := c.leases[namespace+"/"+name]
+	if !found {
+		return nil, errors.NewNotFound(leasesResource, name)
+	}
+	return &l, nil
+}
+
+func (c *fakeLeaseClient) Create(l *lease) (*lease, error) {
+	c.Lock()
+	defer c.Unlock()
+	key := l.Namespace + "/" + l.Name
+	if _, found := c.leases[key]; found {
+		return nil, errors.NewAlreadyExists(leasesResource, l.Name)
+	}
+	c.leases[key] = *l
+	return l, nil
+}
+
+func (c *fakeLeaseClient) Update(l *lease) (*lease, error) {
+	c.Lock()
+	defer c.Unlock()
+	key := l.Namespace + "/" + l.Name
+	if _, found := c.leases[key]; !found {
+		return nil, errors.NewNotFound(leasesResource, l.Name)
+	}
+	c.leases[key] = *l
+	return l, nil
+}
+
+func makeRecord(holderIdentity string, renewTime time.Time) rl.LeaderElectionRecord {
+	return rl.LeaderElectionRecord{
+		HolderIdentity:       holderIdentity,
+		LeaseDurationSeconds: 60,
+		AcquireTime:          metav1.NewTime(renewTime.Add(-time.Minute)),
+		RenewTime:            metav1.NewTime(renewTime),
+		LeaderTransitions:    1,
+	}
+}
+
+func TestLeaseLock(t *testing.T) {
+	client := newFakeLeaseClient()
+	lock := &leaseLock{
+		leaseMeta:  metav1.ObjectMeta{Name: "datadog-leader-election", Namespace: "default"},
+		client:     client,
+		lockConfig: rl.ResourceLockConfig{Identity: "foo"},
+	}
+	assert.Equal(t, "foo", lock.Identity())
+	assert.Equal(t, "default/datadog-leader-election", lock.Describe())
+
+	_, err := lock.Get()
+	assert.True(t, errors.IsNotFound(err))
+	assert.NotNil(t, lock.Update(makeRecord("foo", time.Now())))
+
+	now := time.Now().Truncate(time.Microsecond)
+	require.NoError(t, lock.Create(makeRecord("foo", now)))
+	record, err := lock.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", record.HolderIdentity)
+	assert.Equal(t, 60, record.LeaseDurationSeconds)
+	assert.Equal(t, 1, record.LeaderTransitions)
+	assert.True(t, now.Equal(record.RenewTime.Time))
+
+	require.NoError(t, lock.Update(makeRecord("bar", now.Add(time.Second))))
+	l, err := client.Get("default", "datadog-leader-election")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", *l.Spec.HolderIdentity)
+	assert.True(t, now.Add(time.Second).Equal(l.Spec.RenewTime.Time))
+}
+
+func TestMultiLock(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	leases := newFakeLeaseClient()
+	lockConfig := rl.ResourceLockConfig{Identity: "foo"}
+	le := &LeaderEngine{
+		HolderIdentity:  "foo",
+		LeaseName:       "datadog-leader-election",
+		LeaderNamespace: "default",
+		coreClient:      client.CoreV1(),
+		leaseClient:     leases,
+		lockType:        configMapsLeasesLock,
+	}
+	lock, err := le.newLock(lockConfig)
+	require.NoError(t, err)
+	require.IsType(t, &multiLock{}, lock)
+
+	// an agent still using the configmap is leading
+	now := time.Now()
+	configMapLock, err := le.newConfigMapLock(rl.ResourceLockConfig{Identity: "bar"})
+	require.NoError(t, err)
+	_, err = configMapLock.Get()
+	require.NoError(t, err)
+	require.NoError(t, configMapLock.Update(makeRecord("bar", now)))
+
+	record, err := lock.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", record.HolderIdentity)
+
+	// the update writes both the lease and the configmap
+	require.NoError(t, lock.Update(makeRecord("foo", now.Add(time.Second))))
+	l, err := leases.Get("default", "datadog-leader-election")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", *l.Spec.HolderIdentity)
+	record, err = configMapLock.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", record.HolderIdentity)
+
+	// the most recently renewed record wins when the holders differ
+	_, err = configMapLock.Get()
+	require.NoError(t, err)
+	require.NoError(t, configMapLock.Update(makeRecord("bar", now.Add(2*time.Second))))
+	record, err = lock.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", record.HolderIdentity)
+
+	require.NoError(t, lock.Update(makeRecord("foo", now.Add(3*time.Second))))
+	record, err = lock.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", record.HolderIdentity)
+}
+
+func TestNewLeaseAcquiringLeases(t *testing.T) {
+	const leaseName = "datadog-leader-election"
+
+	client := fake.NewSimpleClientset()
+	leases := newFakeLeaseClient()
+
+	le := &LeaderEngine{
+		HolderIdentity:  "foo",
+		LeaseName:       leaseName,
+		LeaderNamespace: "default",
+		LeaseDuration:   1 * time.Second,
+
+		coreClient:  client.CoreV1(),
+		leaseClient: leases,
+		lockType:    leasesLock,
+	}
+
+	var err error
+	le.leaderElector, err = le.newElection()
+	require.NoError(t, err)
+
+	le.EnsureLeaderElectionRuns()
+	l, err := leases.Get("default", leaseName)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", *l.Spec.HolderIdentity)
+	assert.Equal(t, int32(0), *l.Spec.LeaseTransitions)
+	require.True(t, le.IsLeader())
+
+	// the configmap is not used
+	_, err = client.CoreV1().ConfigMaps("default").Get(leaseName, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
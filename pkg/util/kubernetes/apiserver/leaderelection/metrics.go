@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderelection
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	isLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: "leader_election",
+			Name:      "is_leader",
+			Help:      "1 if this agent is the leader, 0 otherwise.",
+		},
+	)
+	leaderTransitions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: "leader_election",
+			Name:      "transitions",
+			Help:      "Number of leader changes observed.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(isLeader)
+	prometheus.MustRegister(leaderTransitions)
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderelection
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// multiLock writes the election record to both a primary and a secondary lock,
+// it allows migrating from one lock to another while agents using either of
+// them are running: the leader keeps both locks up to date.
+type multiLock struct {
+	primary   rl.Interface
+	secondary rl.Interface
+
+	primaryFound   bool
+	secondaryFound bool
+}
+
+// Get returns the election record of the primary lock, or the one of the
+// secondary lock when the primary doesn't exist yet. When the locks are held
+// by different identities, the most recently renewed record is returned.
+func (ml *multiLock) Get() (*rl.LeaderElectionRecord, error) {
+	primary, primaryErr := ml.primary.Get()
+	if primaryErr != nil && !errors.IsNotFound(primaryErr) {
+		return nil, primaryErr
+	}
+	secondary, secondaryErr := ml.secondary.Get()
+	if secondaryErr != nil && !errors.IsNotFound(secondaryErr) {
+		return nil, secondaryErr
+	}
+	ml.primaryFound = primaryErr == nil
+	ml.secondaryFound = secondaryErr == nil
+
+	switch {
+	case !ml.primaryFound && !ml.secondaryFound:
+		return nil, primaryErr
+	case !ml.primaryFound:
+		// only the agents using the secondary lock ran so far
+		return secondary, nil
+	case !ml.secondaryFound:
+		return primary, nil
+	case primary.HolderIdentity != secondary.HolderIdentity && secondary.RenewTime.After(primary.RenewTime.Time):
+		return secondary, nil
+	default:
+		return primary, nil
+	}
+}
+
+// Create attempts to create both locks.
+func (ml *multiLock) Create(ler rl.LeaderElectionRecord) error {
+	if err := ml.primary.Create(ler); err != nil {
+		return err
+	}
+	ml.primaryFound = true
+	if err := ml.secondary.Create(ler); err != nil {
+		return err
+	}
+	ml.secondaryFound = true
+	return nil
+}
+
+// Update updates both locks, creating the ones that don't exist yet.
+func (ml *multiLock) Update(ler rl.LeaderElectionRecord) error {
+	if err := updateOrCreate(ml.primary, ml.primaryFound, ler); err != nil {
+		return err
+	}
+	ml.primaryFound = true
+	if err := updateOrCreate(ml.secondary, ml.secondaryFound, ler); err != nil {
+		return err
+	}
+	ml.secondaryFound = true
+	return nil
+}
+
+func updateOrCreate(lock rl.Interface, found bool, ler rl.LeaderElectionRecord) error {
+	if found {
+		return lock.Update(ler)
+	}
+	return lock.Create(ler)
+}
+
+// RecordEvent records the event on the primary lock.
+func (ml *multiLock) RecordEvent(s string) {
+	ml.primary.RecordEvent(s)
+}
+
+// Describe is used to convert details on the current resource lock into a string.
+func (ml *multiLock) Describe() string {
+	return fmt.Sprintf("%s,%s", ml.primary.Describe(), ml.secondary.Describe())
+}
+
+// Identity returns the Identity of the lock.
+func (ml *multiLock) Identity() string {
+	return ml.primary.Identity()
+}
@@ -328,3 +328,15 @@ func GetNodeLabels(nodeName string) (map[string]string, error) {
 	}
 	return node.Labels, nil
 }
+
+// GetNamespaceLabels retrieves the labels of the queried namespace.
+func GetNamespaceLabels(ns string) (map[string]string, error) {
+	as, err := GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Datadog.GetBool("kubernetes_collect_metadata_tags") {
+		return nil, log.Errorf("Metadata collection is disabled on the Cluster Agent")
+	}
+	return as.NamespaceLabels(ns)
+}
@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/orchestrator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	defaultOrchestratorFlushInterval = 10 * time.Second
+	defaultOrchestratorResyncPeriod  = 5 * time.Minute
+)
+
+type manifestSender interface {
+	Send(manifests []*model.Manifest) error
+}
+
+// OrchestratorController watches the pods, deployments, replicasets and nodes
+// of the cluster and ships their scrubbed manifests to the orchestrator intake.
+// The changes are sent every flush interval and all the resources every resync
+// period. Only the leader sends the manifests.
+type OrchestratorController struct {
+	podLister              corelisters.PodLister
+	podListerSynced        cache.InformerSynced
+	deploymentLister       appslisters.DeploymentLister
+	deploymentListerSynced cache.InformerSynced
+	replicaSetLister       appslisters.ReplicaSetLister
+	replicaSetListerSynced cache.InformerSynced
+	nodeLister             corelisters.NodeLister
+	nodeListerSynced       cache.InformerSynced
+
+	le            LeaderElectorInterface
+	scrubber      *orchestrator.DataScrubber
+	sender        manifestSender
+	flushInterval time.Duration
+	resyncPeriod  time.Duration
+
+	m       sync.Mutex
+	pending map[string]*model.Manifest // Changed resources to send, indexed by UID
+}
+
+// NewOrchestratorController returns a new OrchestratorController
+func NewOrchestratorController(
+	podInformer coreinformers.PodInformer,
+	deploymentInformer appsinformers.DeploymentInformer,
+	replicaSetInformer appsinformers.ReplicaSetInformer,
+	nodeInformer coreinformers.NodeInformer,
+	le LeaderElectorInterface,
+	sender manifestSender,
+) *OrchestratorController {
+	c := &OrchestratorController{
+		le:            le,
+		scrubber:      orchestrator.NewDefaultDataScrubber(),
+		sender:        sender,
+		flushInterval: orchestratorInterval("orchestrator_explorer.flush_interval", defaultOrchestratorFlushInterval),
+		resyncPeriod:  orchestratorInterval("orchestrator_explorer.resync_period", defaultOrchestratorResyncPeriod),
+		pending:       make(map[string]*model.Manifest),
+	}
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.add,
+		UpdateFunc: c.update,
+		DeleteFunc: c.delete,
+	}
+
+	podInformer.Informer().AddEventHandler(handlers)
+	c.podLister = podInformer.Lister()
+	c.podListerSynced = podInformer.Informer().HasSynced
+
+	deploymentInformer.Informer().AddEventHandler(handlers)
+	c.deploymentLister = deploymentInformer.Lister()
+	c.deploymentListerSynced = deploymentInformer.Informer().HasSynced
+
+	replicaSetInformer.Informer().AddEventHandler(handlers)
+	c.replicaSetLister = replicaSetInformer.Lister()
+	c.replicaSetListerSynced = replicaSetInformer.Informer().HasSynced
+
+	nodeInformer.Informer().AddEventHandler(handlers)
+	c.nodeLister = nodeInformer.Lister()
+	c.nodeListerSynced = nodeInformer.Informer().HasSynced
+
+	return c
+}
+
+// orchestratorInterval returns a setting in seconds, the default when it's
+// not positive
+func orchestratorInterval(key string, defaultInterval time.Duration) time.Duration {
+	interval := config.Datadog.GetDuration(key) * time.Second
+	if interval <= 0 {
+		log.Warnf("Invalid %s %v, using %v", key, interval, defaultInterval)
+		return defaultInterval
+	}
+	return interval
+}
+
+// Run sends the manifests until stopCh is closed
+func (c *OrchestratorController) Run(stopCh <-chan struct{}) {
+	log.Infof("Starting orchestrator controller")
+	defer log.Infof("Stopping orchestrator controller")
+
+	if !cache.WaitForCacheSync(stopCh, c.podListerSynced, c.deploymentListerSynced, c.replicaSetListerSynced, c.nodeListerSynced) {
+		return
+	}
+
+	flushTicker := time.NewTicker(c.flushInterval)
+	defer flushTicker.Stop()
+	resyncTicker := time.NewTicker(c.resyncPeriod)
+	defer resyncTicker.Stop()
+
+	c.resync()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-flushTicker.C:
+			c.flush()
+		case <-resyncTicker.C:
+			c.resync()
+		}
+	}
+}
+
+func (c *OrchestratorController) add(obj interface{}) {
+	c.enqueue(obj, false)
+}
+
+func (c *OrchestratorController) update(oldObj, newObj interface{}) {
+	oldMeta, err := meta.Accessor(oldObj)
+	if err != nil {
+		return
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return
+	}
+	if oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+		// Periodic resync of the informer, the resource did not change
+		return
+	}
+	c.enqueue(newObj, false)
+}
+
+func (c *OrchestratorController) delete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	c.enqueue(obj, true)
+}
+
+// enqueue records the manifest of the resource until the next flush
+func (c *OrchestratorController) enqueue(obj interface{}, deleted bool) {
+	manifest, err := orchestrator.BuildManifest(obj, c.scrubber)
+	if err != nil {
+		log.Debugf("Could not build the manifest of %#v: %s", obj, err)
+		return
+	}
+	manifest.Deleted = deleted
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.pending[manifest.Uid] = manifest
+}
+
+// flush sends the manifests of the resources changed since the last flush
+func (c *OrchestratorController) flush() {
+	c.m.Lock()
+	pending := c.pending
+	c.pending = make(map[string]*model.Manifest)
+	c.m.Unlock()
+
+	if len(pending) == 0 || !c.le.IsLeader() {
+		return
+	}
+	manifests := make([]*model.Manifest, 0, len(pending))
+	for _, manifest := range pending {
+		manifests = append(manifests, manifest)
+	}
+	log.Debugf("Sending %d changed manifests", len(manifests))
+	c.sender.Send(manifests)
+}
+
+// resync sends the manifests of all the resources
+func (c *OrchestratorController) resync() {
+	if !c.le.IsLeader() {
+		return
+	}
+	var objects []interface{}
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("Could not list the pods: %s", err)
+	}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	deployments, err := c.deploymentLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("Could not list the deployments: %s", err)
+	}
+	for _, deployment := range deployments {
+		objects = append(objects, deployment)
+	}
+	replicaSets, err := c.replicaSetLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("Could not list the replicasets: %s", err)
+	}
+	for _, replicaSet := range replicaSets {
+		objects = append(objects, replicaSet)
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("Could not list the nodes: %s", err)
+	}
+	for _, node := range nodes {
+		objects = append(objects, node)
+	}
+
+	manifests := make([]*model.Manifest, 0, len(objects))
+	for _, obj := range objects {
+		manifest, err := orchestrator.BuildManifest(obj, c.scrubber)
+		if err != nil {
+			log.Debugf("Could not build the manifest of %#v: %s", obj, err)
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	log.Debugf("Resyncing %d manifests", len(manifests))
+	c.sender.Send(manifests)
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/model"
+)
+
+type fakeManifestSender struct {
+	sync.Mutex
+	sent [][]*model.Manifest
+}
+
+func (s *fakeManifestSender) Send(manifests []*model.Manifest) error {
+	s.Lock()
+	defer s.Unlock()
+	s.sent = append(s.sent, manifests)
+	return nil
+}
+
+func (s *fakeManifestSender) reset() [][]*model.Manifest {
+	s.Lock()
+	defer s.Unlock()
+	sent := s.sent
+	s.sent = nil
+	return sent
+}
+
+func newFakeOrchestratorController(t *testing.T, le LeaderElectorInterface, objects ...interface{}) (*OrchestratorController, *fakeManifestSender, *fake.Clientset, chan struct{}) {
+	client := fake.NewSimpleClientset()
+	for _, obj := range objects {
+		var err error
+		switch o := obj.(type) {
+		case *corev1.Pod:
+			_, err = client.CoreV1().Pods(o.Namespace).Create(o)
+		case *corev1.Node:
+			_, err = client.CoreV1().Nodes().Create(o)
+		}
+		require.NoError(t, err)
+	}
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	sender := &fakeManifestSender{}
+	c := NewOrchestratorController(
+		informerFactory.Core().V1().Pods(),
+		informerFactory.Apps().V1().Deployments(),
+		informerFactory.Apps().V1().ReplicaSets(),
+		informerFactory.Core().V1().Nodes(),
+		le,
+		sender,
+	)
+	stop := make(chan struct{})
+	informerFactory.Start(stop)
+	require.True(t, cache.WaitForCacheSync(stop, c.podListerSynced, c.deploymentListerSynced, c.replicaSetListerSynced, c.nodeListerSynced))
+	return c, sender, client, stop
+}
+
+func pendingCount(c *OrchestratorController) int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return len(c.pending)
+}
+
+func waitForPending(t *testing.T, c *OrchestratorController, count int) {
+	for i := 0; i < 100; i++ {
+		if pendingCount(c) == count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, count, pendingCount(c))
+}
+
+func TestOrchestratorControllerResync(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "pod-uid", Namespace: "default", Name: "redis"}}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{UID: "node-uid", Name: "node1"}}
+	c, sender, _, stop := newFakeOrchestratorController(t, alwaysLeader, pod, node)
+	defer close(stop)
+
+	c.resync()
+	sent := sender.reset()
+	require.Len(t, sent, 1)
+	uids := []string{}
+	for _, manifest := range sent[0] {
+		uids = append(uids, manifest.Uid)
+	}
+	assert.ElementsMatch(t, []string{"pod-uid", "node-uid"}, uids)
+
+	// Only the leader sends the manifests
+	c.le = &fakeLeaderElector{false}
+	c.resync()
+	assert.Len(t, sender.reset(), 0)
+}
+
+func TestOrchestratorControllerFlush(t *testing.T) {
+	c, sender, client, stop := newFakeOrchestratorController(t, alwaysLeader)
+	defer close(stop)
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{UID: "deploy-uid", Namespace: "default", Name: "redis"}}
+	_, err := client.AppsV1().Deployments("default").Create(deployment)
+	require.NoError(t, err)
+	waitForPending(t, c, 1)
+
+	c.flush()
+	sent := sender.reset()
+	require.Len(t, sent, 1)
+	require.Len(t, sent[0], 1)
+	assert.Equal(t, "deploy-uid", sent[0][0].Uid)
+	assert.Equal(t, model.ManifestType_deployment, sent[0][0].Type)
+	assert.False(t, sent[0][0].Deleted)
+
+	// Nothing changed
+	c.flush()
+	assert.Len(t, sender.reset(), 0)
+
+	err = client.AppsV1().Deployments("default").Delete("redis", &metav1.DeleteOptions{})
+	require.NoError(t, err)
+	waitForPending(t, c, 1)
+
+	c.flush()
+	sent = sender.reset()
+	require.Len(t, sent, 1)
+	assert.True(t, sent[0][0].Deleted)
+}
+
+func TestOrchestratorControllerIntervals(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("orchestrator_explorer.flush_interval", 10)
+	defer mockConfig.Set("orchestrator_explorer.resync_period", 300)
+
+	mockConfig.Set("orchestrator_explorer.flush_interval", 20)
+	mockConfig.Set("orchestrator_explorer.resync_period", 600)
+	c, _, _, stop := newFakeOrchestratorController(t, alwaysLeader)
+	close(stop)
+	assert.Equal(t, 20*time.Second, c.flushInterval)
+	assert.Equal(t, 10*time.Minute, c.resyncPeriod)
+
+	// Invalid intervals fall back to the defaults
+	mockConfig.Set("orchestrator_explorer.flush_interval", 0)
+	mockConfig.Set("orchestrator_explorer.resync_period", -1)
+	c, _, _, stop = newFakeOrchestratorController(t, alwaysLeader)
+	close(stop)
+	assert.Equal(t, defaultOrchestratorFlushInterval, c.flushInterval)
+	assert.Equal(t, defaultOrchestratorResyncPeriod, c.resyncPeriod)
+}
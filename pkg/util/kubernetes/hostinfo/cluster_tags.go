@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package hostinfo
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+)
+
+// GetClusterTags gets the tags common to all the nodes of the cluster from the
+// cluster agent
+func GetClusterTags() ([]string, error) {
+	if !config.Datadog.GetBool("cluster_agent.enabled") || !config.Datadog.GetBool("cluster_agent.collect_cluster_tags") {
+		return nil, nil
+	}
+
+	cl, err := clusteragent.GetClusterAgentClient()
+	if err != nil {
+		return nil, err
+	}
+	clusterTags, err := cl.GetClusterTags()
+	if err != nil {
+		return nil, err
+	}
+	return clusterTags.Tags, nil
+}
@@ -36,6 +36,7 @@ type ProcessorInterface interface {
 // Processor embeds the configuration to refresh metrics from Datadog and process HPA structs to ExternalMetrics.
 type Processor struct {
 	externalMaxAge time.Duration
+	batchSize      int
 	datadogClient  DatadogClient
 }
 
@@ -44,6 +45,7 @@ func NewProcessor(datadogCl DatadogClient) (*Processor, error) {
 	externalMaxAge := math.Max(config.Datadog.GetFloat64("external_metrics_provider.max_age"), 3*config.Datadog.GetFloat64("external_metrics_provider.rollup"))
 	return &Processor{
 		externalMaxAge: time.Duration(externalMaxAge) * time.Second,
+		batchSize:      config.Datadog.GetInt("external_metrics_provider.max_metrics_per_query"),
 		datadogClient:  datadogCl,
 	}, nil
 }
@@ -100,14 +102,49 @@ func (p *Processor) ProcessHPAs(hpa *autoscalingv2.HorizontalPodAutoscaler) map[
 	return externalMetrics
 }
 
-// validateExternalMetric queries Datadog to validate the availability and value of one or more external metrics
+// validateExternalMetric queries Datadog to validate the availability and value of one or more external metrics.
+// The metrics shared by several HPAs are queried once, in batches of at most batchSize metrics.
+// The metrics of a batch that could not be queried are missing from the result.
 func (p *Processor) validateExternalMetric(emList map[string]custommetrics.ExternalMetricValue) (processed map[string]Point, err error) {
-	var batch []string
+	var queries []string
+	seen := make(map[string]bool)
 	for _, e := range emList {
 		q := getKey(e.MetricName, e.Labels)
-		batch = append(batch, q)
+		if seen[q] {
+			continue
+		}
+		seen[q] = true
+		queries = append(queries, q)
+	}
+	sort.Strings(queries)
+
+	processed = make(map[string]Point)
+	for _, batch := range makeBatches(queries, p.batchSize) {
+		points, batchErr := p.queryDatadogExternal(batch)
+		if batchErr != nil {
+			err = batchErr
+		}
+		for name, point := range points {
+			processed[name] = point
+		}
+	}
+	return processed, err
+}
+
+// makeBatches splits the queries in batches of at most size queries, in a single batch when size is not positive.
+func makeBatches(queries []string, size int) [][]string {
+	if len(queries) == 0 {
+		return nil
+	}
+	if size <= 0 {
+		return [][]string{queries}
+	}
+	var batches [][]string
+	for len(queries) > size {
+		batches = append(batches, queries[:size])
+		queries = queries[size:]
 	}
-	return p.queryDatadogExternal(batch)
+	return append(batches, queries)
 }
 
 func invalidate(emList map[string]custommetrics.ExternalMetricValue) (invList map[string]custommetrics.ExternalMetricValue) {
@@ -10,6 +10,7 @@ package hpa
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -354,3 +355,38 @@ func TestInvalidate(t *testing.T) {
 		require.WithinDuration(t, time.Now(), time.Unix(e.Timestamp, 0), 5*time.Second)
 	}
 }
+
+func TestValidateExternalMetricBatches(t *testing.T) {
+	var queries []string
+	datadogClient := &fakeDatadogClient{
+		queryMetricsFunc: func(from, to int64, query string) ([]datadog.Series, error) {
+			queries = append(queries, query)
+			if strings.Contains(query, "m3") {
+				return nil, fmt.Errorf("API error 400 Bad Request")
+			}
+			return nil, nil
+		},
+	}
+	hpaCl := &Processor{datadogClient: datadogClient, externalMaxAge: maxAge, batchSize: 2}
+
+	emList := map[string]custommetrics.ExternalMetricValue{
+		"id1": {MetricName: "m1", Labels: map[string]string{"foo": "bar"}},
+		"id2": {MetricName: "m2", Labels: map[string]string{"foo": "bar"}},
+		"id3": {MetricName: "m3", Labels: map[string]string{"foo": "bar"}},
+		// the metric shared by several HPAs is queried once
+		"id4": {MetricName: "m1", Labels: map[string]string{"foo": "bar"}},
+	}
+	processed, err := hpaCl.validateExternalMetric(emList)
+	require.Error(t, err)
+	require.Len(t, queries, 2)
+	require.Len(t, processed, 2)
+	require.Contains(t, processed, "m1{foo:bar}")
+	require.Contains(t, processed, "m2{foo:bar}")
+}
+
+func TestMakeBatches(t *testing.T) {
+	require.Nil(t, makeBatches(nil, 2))
+	require.Equal(t, [][]string{{"a", "b", "c"}}, makeBatches([]string{"a", "b", "c"}, 0))
+	require.Equal(t, [][]string{{"a", "b"}, {"c"}}, makeBatches([]string{"a", "b", "c"}, 2))
+	require.Equal(t, [][]string{{"a", "b"}}, makeBatches([]string{"a", "b"}, 2))
+}
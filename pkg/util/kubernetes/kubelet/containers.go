@@ -33,7 +33,7 @@ func (ku *KubeUtil) ListContainers() ([]*containers.Container, error) {
 
 	for _, pod := range pods {
 		for _, c := range pod.Status.Containers {
-			if ku.filter.IsExcluded(c.Name, c.Image) {
+			if ku.filter.IsExcluded(c.Name, c.Image, pod.Metadata.Namespace) {
 				continue
 			}
 			container, err := parseContainerInPod(c, pod)
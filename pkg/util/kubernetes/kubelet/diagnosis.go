@@ -9,12 +9,17 @@
 package kubelet
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 func init() {
 	diagnosis.Register("Kubelet availability", diagnose)
+	diagnosis.Register("Kubelet credentials permissions", diagnoseCredentials)
 }
 
 // diagnose the API server availability
@@ -25,3 +30,37 @@ func diagnose() error {
 	}
 	return err
 }
+
+// diagnoseCredentials checks that the agent can read the files it uses to
+// authenticate to the kubelet
+func diagnoseCredentials() error {
+	found := 0
+	for _, key := range []string{"kubelet_auth_token_path", "kubelet_client_crt", "kubelet_client_key", "kubelet_client_ca"} {
+		path := config.Datadog.GetString(key)
+		if path == "" {
+			continue
+		}
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			log.Debugf("%s: %s doesn't exist", key, path)
+			continue
+		}
+		found++
+		if err != nil {
+			log.Errorf("%s: unable to read %s: %s", key, path, err)
+			if os.IsPermission(err) {
+				return diagnosis.Fail(
+					fmt.Errorf("permission denied on %s", path),
+					fmt.Sprintf("give the user running the agent read access to %s, set in %s", path, key),
+				)
+			}
+			return err
+		}
+		f.Close()
+		log.Infof("%s: %s is readable", key, path)
+	}
+	if found == 0 {
+		return diagnosis.Skip("no kubelet credentials found")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// +build kubelet
+
+package kubelet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsSummaryUnmarshal(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/summary.json")
+	require.NoError(t, err)
+
+	summary := &StatsSummary{}
+	require.NoError(t, json.Unmarshal(data, summary))
+	require.Len(t, summary.Pods, 2)
+
+	redis := summary.Pods[0]
+	assert.Equal(t, PodReference{
+		Name:      "redis-75586d7d7c-mcczh",
+		Namespace: "default",
+		UID:       "ee5cd9c3-59f4-11e9-8a76-42010a840fe2",
+	}, redis.PodRef)
+	require.NotNil(t, redis.Network)
+	assert.Equal(t, "eth0", redis.Network.Name)
+	assert.EqualValues(t, 60224, *redis.Network.RxBytes)
+	assert.EqualValues(t, 1, *redis.Network.TxErrors)
+	assert.Len(t, redis.Network.Interfaces, 2)
+	require.NotNil(t, redis.EphemeralStorage)
+	assert.EqualValues(t, 40960, *redis.EphemeralStorage.UsedBytes)
+
+	// the kubelet doesn't report the usage of the host network pods
+	assert.Nil(t, summary.Pods[1].Network)
+	assert.Nil(t, summary.Pods[1].EphemeralStorage)
+}
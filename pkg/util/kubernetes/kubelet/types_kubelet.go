@@ -116,3 +116,45 @@ type ContainerStateTerminated struct {
 	StartedAt  time.Time `json:"startedAt"`
 	FinishedAt time.Time `json:"finishedAt"`
 }
+
+// StatsSummary contains fields for unmarshalling the /stats/summary payload
+type StatsSummary struct {
+	Pods []PodStats `json:"pods"`
+}
+
+// PodStats holds the resource usage of a pod
+type PodStats struct {
+	PodRef           PodReference  `json:"podRef"`
+	Network          *NetworkStats `json:"network,omitempty"`
+	EphemeralStorage *FsStats      `json:"ephemeral-storage,omitempty"`
+}
+
+// PodReference identifies the pod of a PodStats
+type PodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+}
+
+// NetworkStats holds the network usage of a pod. The top-level counters are
+// the ones of the default interface.
+type NetworkStats struct {
+	InterfaceStats
+	Interfaces []InterfaceStats `json:"interfaces,omitempty"`
+}
+
+// InterfaceStats holds the counters of a network interface
+type InterfaceStats struct {
+	Name     string  `json:"name"`
+	RxBytes  *uint64 `json:"rxBytes,omitempty"`
+	RxErrors *uint64 `json:"rxErrors,omitempty"`
+	TxBytes  *uint64 `json:"txBytes,omitempty"`
+	TxErrors *uint64 `json:"txErrors,omitempty"`
+}
+
+// FsStats holds the usage of a filesystem
+type FsStats struct {
+	AvailableBytes *uint64 `json:"availableBytes,omitempty"`
+	CapacityBytes  *uint64 `json:"capacityBytes,omitempty"`
+	UsedBytes      *uint64 `json:"usedBytes,omitempty"`
+}
@@ -537,9 +537,10 @@ func UnregisterAdditionalLogger(n string) error {
 	return errors.New("cannot unregister: logger not initialized")
 }
 
-func changeLogLevel(level string) error {
+// ChangeLogLevel changes the level of the logger
+func ChangeLogLevel(level string) error {
 	if logger == nil {
-		return errors.New("logger initialized, cant set log-level")
+		return errors.New("logger not initialized, cant set log-level")
 	}
 
 	return logger.changeLogLevel(level)
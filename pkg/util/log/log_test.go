@@ -150,7 +150,7 @@ func TestWarnNotNil(t *testing.T) {
 
 	assert.NotNil(t, Warn("test"))
 
-	changeLogLevel("info")
+	ChangeLogLevel("info")
 
 	assert.NotNil(t, Warn("test"))
 }
@@ -166,7 +166,7 @@ func TestWarnfNotNil(t *testing.T) {
 
 	assert.NotNil(t, Warn("test"))
 
-	changeLogLevel("info")
+	ChangeLogLevel("info")
 
 	assert.NotNil(t, Warn("test"))
 }
@@ -182,7 +182,7 @@ func TestErrorNotNil(t *testing.T) {
 
 	assert.NotNil(t, Error("test"))
 
-	changeLogLevel("info")
+	ChangeLogLevel("info")
 
 	assert.NotNil(t, Error("test"))
 }
@@ -198,7 +198,7 @@ func TestErrorfNotNil(t *testing.T) {
 
 	assert.NotNil(t, Errorf("test"))
 
-	changeLogLevel("info")
+	ChangeLogLevel("info")
 
 	assert.NotNil(t, Errorf("test"))
 }
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// declare these as vars not const to ease testing
+var (
+	metadataURL = "http://169.254.169.254/opc/v2"
+	timeout     = 300 * time.Millisecond
+)
+
+type oracleInstanceMetadata struct {
+	ID                  string `json:"id"`
+	CanonicalRegionName string `json:"canonicalRegionName"`
+	Shape               string `json:"shape"`
+}
+
+// GetHostAlias returns the instance OCID from the Oracle Cloud Metadata api
+func GetHostAlias() (string, error) {
+	res, err := getResponseWithMaxLength(metadataURL+"/instance/id",
+		config.Datadog.GetInt("metadata_endpoints_max_hostname_size"))
+	if err != nil {
+		return "", fmt.Errorf("Oracle HostAliases: unable to query metadata endpoint: %s", err)
+	}
+	return res, nil
+}
+
+// GetTags returns the instance OCID, the region and the shape of the
+// instance from the Oracle Cloud Metadata api
+func GetTags() ([]string, error) {
+	all, err := getResponse(metadataURL + "/instance/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to query metadata endpoint: %s", err)
+	}
+
+	var metadata oracleInstanceMetadata
+	if err := json.Unmarshal([]byte(all), &metadata); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the instance metadata: %s", err)
+	}
+
+	return []string{
+		fmt.Sprintf("instance-id:%s", metadata.ID),
+		fmt.Sprintf("region:%s", metadata.CanonicalRegionName),
+		fmt.Sprintf("instance-type:%s", metadata.Shape),
+	}, nil
+}
+
+func getResponseWithMaxLength(endpoint string, maxLength int) (string, error) {
+	result, err := getResponse(endpoint)
+	if err != nil {
+		return result, err
+	}
+	if len(result) > maxLength {
+		return "", fmt.Errorf("%v gave a response with length > to %v", endpoint, maxLength)
+	}
+	return result, err
+}
+
+func getResponse(url string) (string, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// the v2 metadata endpoints require this header
+	req.Header.Add("Authorization", "Bearer Oracle")
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return "", fmt.Errorf("status code %d trying to GET %s", res.StatusCode, url)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error while reading response from oracle metadata endpoint: %s", err)
+	}
+
+	return string(all), nil
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package oracle
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const instanceID = "ocid1.instance.oc1.iad.anuwcljrbnvdfxyca4gg3gwiwl5fzjnkfthmn7fxbfixr36gnwbuh7ddusnq"
+
+func TestGetHostAlias(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, instanceID)
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetHostAlias()
+	assert.Nil(t, err)
+	assert.Equal(t, instanceID, val)
+	assert.Equal(t, "/instance/id", lastRequest.URL.Path)
+	assert.Equal(t, "Bearer Oracle", lastRequest.Header.Get("Authorization"))
+}
+
+func TestGetTags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"availabilityDomain": "EMIr:US-ASHBURN-AD-1",
+			"canonicalRegionName": "us-ashburn-1",
+			"id": "`+instanceID+`",
+			"region": "iad",
+			"shape": "VM.Standard2.1"
+		}`)
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"instance-id:" + instanceID, "region:us-ashburn-1", "instance-type:VM.Standard2.1"}, tags)
+}
@@ -0,0 +1,419 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package util
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-ntlmssp"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Authentication schemes supported with the proxies
+const (
+	ProxyAuthBasic     = "basic"
+	ProxyAuthNTLM      = "ntlm"
+	ProxyAuthNegotiate = "negotiate"
+)
+
+// tunnelDialTimeout bounds the dials of the HTTPS connections through the
+// proxies, from the connection to the proxy to the TLS handshake with the
+// remote server
+const tunnelDialTimeout = 60 * time.Second
+
+// IsConnectionAuth returns whether the proxy authentication scheme
+// authenticates connections rather than requests, in which case the
+// connections have to be tunneled through the proxy by the agent itself.
+func IsConnectionAuth(auth string) bool {
+	switch strings.ToLower(auth) {
+	case ProxyAuthNTLM, ProxyAuthNegotiate:
+		return true
+	case "", ProxyAuthBasic:
+		return false
+	default:
+		log.Warnf("Unknown proxy authentication scheme '%s', using basic authentication", auth)
+		return false
+	}
+}
+
+// proxyCredentials returns the credentials to authenticate with a proxy. As
+// for basic authentication, the credentials embedded in the proxy URL take
+// precedence over the `proxy.username` and `proxy.password` settings.
+func proxyCredentials(p *config.Proxy, proxyURL *url.URL) (string, string) {
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		return proxyURL.User.Username(), password
+	}
+	return p.Username, p.Password
+}
+
+// proxyDialer tunnels the connections through the proxies with CONNECT,
+// authenticating every tunnel with the NTLM handshake. The handshake spans
+// several requests on the same connection, which http.Transport can't do.
+type proxyDialer struct {
+	proxies   *config.Proxy
+	proxyFunc func(*http.Request) (*url.URL, error)
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	tlsConfig *tls.Config
+	// timeout bounds the CONNECT exchanges and the TLS handshakes, it's the
+	// TLS handshake timeout of the transport
+	timeout time.Duration
+}
+
+// setupProxyDialer replaces the proxy function of the transport by a dialer
+// tunneling the connections through the proxies.
+func setupProxyDialer(transport *http.Transport, proxies *config.Proxy) {
+	d := &proxyDialer{
+		proxies:   proxies,
+		proxyFunc: GetProxyTransportFunc(proxies),
+		dial:      transport.DialContext,
+		tlsConfig: transport.TLSClientConfig,
+		timeout:   transport.TLSHandshakeTimeout,
+	}
+	transport.Proxy = nil
+	transport.DialContext = d.dialContext
+	transport.DialTLS = d.dialTLS
+}
+
+// dialContext dials the plain HTTP connections
+func (d *proxyDialer) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d.dialThroughProxy(ctx, network, addr, "http")
+}
+
+// dialTLS dials the HTTPS connections, the TLS session is established
+// through the tunnel. http.Transport doesn't pass the context of the request
+// to DialTLS before Go 1.14, so the whole dial is bounded by tunnelDialTimeout
+// instead.
+func (d *proxyDialer) dialTLS(network, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tunnelDialTimeout)
+	defer cancel()
+
+	conn, err := d.dialThroughProxy(ctx, network, addr, "https")
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := d.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tlsConfig.ServerName = host
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := d.withDeadline(ctx, conn, tlsConn.Handshake); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func (d *proxyDialer) dialThroughProxy(ctx context.Context, network, addr, scheme string) (net.Conn, error) {
+	proxyURL, err := d.proxyFunc(&http.Request{URL: &url.URL{Scheme: scheme, Host: addr}})
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return d.dial(ctx, network, addr)
+	}
+
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		if proxyURL.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "443")
+		} else {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+		}
+	}
+
+	conn, err := d.dial(ctx, network, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		// the proxy is trusted like the intake, it may be the TLS gateway
+		proxyTLSConfig := d.tlsConfig.Clone()
+		proxyTLSConfig.ServerName = proxyURL.Hostname()
+		tlsConn := tls.Client(conn, proxyTLSConfig)
+		if err := d.withDeadline(ctx, conn, tlsConn.Handshake); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	user, password := proxyCredentials(d.proxies, proxyURL)
+	var tunnel net.Conn
+	err = d.withDeadline(ctx, conn, func() error {
+		var err error
+		tunnel, err = connectWithNTLM(conn, addr, strings.ToLower(d.proxies.Auth), user, password)
+		return err
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not tunnel through proxy %s: %s", proxyURL.Host, err)
+	}
+	return tunnel, nil
+}
+
+// withDeadline runs a handshake on the connection, aborting it when the
+// context is done or when the timeout of the dialer elapses, so that a
+// stalled proxy can't block the transport.
+func (d *proxyDialer) withDeadline(ctx context.Context, conn net.Conn, handshake func() error) error {
+	deadline, hasDeadline := ctx.Deadline()
+	if d.timeout > 0 {
+		if timeout := time.Now().Add(d.timeout); !hasDeadline || timeout.Before(deadline) {
+			deadline, hasDeadline = timeout, true
+		}
+	}
+	if hasDeadline {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			// unblock the pending reads and writes
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+	err := handshake()
+	close(stop)
+	<-stopped
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+// connectWithNTLM opens a tunnel to addr through the proxy the connection is
+// established with, authenticating with the NTLM handshake: the first CONNECT
+// carries the NEGOTIATE message, the proxy answers with a CHALLENGE message
+// and the second CONNECT carries the AUTHENTICATE message. With the
+// `negotiate` auth, the messages are wrapped in SPNEGO tokens offering NTLM
+// as the only mechanism.
+func connectWithNTLM(conn net.Conn, addr, auth, user, password string) (net.Conn, error) {
+	scheme := "NTLM"
+	if auth == ProxyAuthNegotiate {
+		scheme = "Negotiate"
+	}
+	user, domain, domainNeeded := ntlmssp.GetDomain(user)
+
+	br := bufio.NewReader(conn)
+
+	negotiate, err := ntlmssp.NewNegotiateMessage(domain, "")
+	if err != nil {
+		return nil, err
+	}
+	if auth == ProxyAuthNegotiate {
+		if negotiate, err = spnegoInitToken(negotiate); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := connect(conn, br, addr, scheme, negotiate)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		// the proxy didn't ask for credentials
+		return newBufferedConn(conn, br), nil
+	}
+	if resp.StatusCode != http.StatusProxyAuthRequired {
+		return nil, fmt.Errorf("unexpected response to CONNECT: %s", resp.Status)
+	}
+	if resp.Close {
+		return nil, fmt.Errorf("the proxy closed the connection during the %s handshake", scheme)
+	}
+
+	challenge, err := parseChallenge(resp.Header, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if auth == ProxyAuthNegotiate {
+		if challenge, err = spnegoResponseToken(challenge); err != nil {
+			return nil, err
+		}
+	}
+	authenticate, err := ntlmssp.ProcessChallenge(challenge, user, password, domainNeeded)
+	if err != nil {
+		return nil, err
+	}
+	if auth == ProxyAuthNegotiate {
+		if authenticate, err = spnegoRespToken(authenticate); err != nil {
+			return nil, err
+		}
+	}
+	resp, err = connect(conn, br, addr, scheme, authenticate)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s authentication failed: %s", scheme, resp.Status)
+	}
+	return newBufferedConn(conn, br), nil
+}
+
+// connect sends a CONNECT request carrying an authentication token and
+// reads the response of the proxy, discarding its body.
+func connect(conn net.Conn, br *bufio.Reader, addr, scheme string, token []byte) (*http.Response, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	req.Header.Set("Proxy-Authorization", scheme+" "+base64.StdEncoding.EncodeToString(token))
+	req.Header.Set("Proxy-Connection", "Keep-Alive")
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return resp, nil
+}
+
+// parseChallenge extracts the CHALLENGE message from the Proxy-Authenticate
+// headers of a proxy response.
+func parseChallenge(header http.Header, scheme string) ([]byte, error) {
+	for _, value := range header["Proxy-Authenticate"] {
+		fields := strings.SplitN(strings.TrimSpace(value), " ", 2)
+		if !strings.EqualFold(fields[0], scheme) || len(fields) != 2 {
+			continue
+		}
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(fields[1]))
+	}
+	return nil, fmt.Errorf("the proxy didn't send a %s challenge", scheme)
+}
+
+// bufferedConn is a connection whose first bytes were already read in a
+// buffer while reading the response to CONNECT.
+type bufferedConn struct {
+	net.Conn
+	br *bufio.Reader
+}
+
+func newBufferedConn(conn net.Conn, br *bufio.Reader) net.Conn {
+	if br.Buffered() == 0 {
+		return conn
+	}
+	return &bufferedConn{Conn: conn, br: br}
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.br.Read(b)
+}
+
+// SPNEGO (RFC 4178) tokens carrying the NTLM messages. Kerberos isn't
+// supported: NTLM is the only mechanism offered to the proxy.
+var (
+	spnegoOID = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 2}
+	ntlmOID   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 2, 10}
+)
+
+const ntlmSignature = "NTLMSSP\x00"
+
+type negTokenInit struct {
+	MechTypes []asn1.ObjectIdentifier `asn1:"explicit,tag:0"`
+	MechToken []byte                  `asn1:"explicit,optional,tag:2"`
+}
+
+type negTokenResp struct {
+	NegState      asn1.Enumerated       `asn1:"explicit,optional,tag:0"`
+	SupportedMech asn1.ObjectIdentifier `asn1:"explicit,optional,tag:1"`
+	ResponseToken []byte                `asn1:"explicit,optional,tag:2"`
+	MechListMIC   []byte                `asn1:"explicit,optional,tag:3"`
+}
+
+// spnegoInitToken wraps the NTLM NEGOTIATE message in the initial SPNEGO
+// token: the SPNEGO OID followed by a NegTokenInit, in a GSS-API frame.
+func spnegoInitToken(negotiate []byte) ([]byte, error) {
+	init, err := asn1.Marshal(negTokenInit{
+		MechTypes: []asn1.ObjectIdentifier{ntlmOID},
+		MechToken: negotiate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	init, err = asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: init})
+	if err != nil {
+		return nil, err
+	}
+	oid, err := asn1.Marshal(spnegoOID)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassApplication, Tag: 0, IsCompound: true, Bytes: append(oid, init...)})
+}
+
+// spnegoRespToken wraps the NTLM AUTHENTICATE message in a NegTokenResp
+func spnegoRespToken(authenticate []byte) ([]byte, error) {
+	resp, err := asn1.Marshal(negTokenResp{ResponseToken: authenticate})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, IsCompound: true, Bytes: resp})
+}
+
+// spnegoResponseToken extracts the NTLM CHALLENGE message from the
+// NegTokenResp sent by the proxy. Some proxies send the raw NTLM message
+// under the Negotiate scheme, it's returned as is.
+func spnegoResponseToken(token []byte) ([]byte, error) {
+	if strings.HasPrefix(string(token), ntlmSignature) {
+		return token, nil
+	}
+
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(token, &raw); err != nil {
+		return nil, fmt.Errorf("invalid Negotiate challenge: %s", err)
+	}
+	if raw.Class != asn1.ClassContextSpecific || raw.Tag != 1 {
+		return nil, fmt.Errorf("invalid Negotiate challenge: not a NegTokenResp")
+	}
+	var resp negTokenResp
+	if _, err := asn1.Unmarshal(raw.Bytes, &resp); err != nil {
+		return nil, fmt.Errorf("invalid Negotiate challenge: %s", err)
+	}
+	if len(resp.SupportedMech) > 0 && !resp.SupportedMech.Equal(ntlmOID) {
+		return nil, fmt.Errorf("the proxy selected the %s mechanism, only NTLM is supported", resp.SupportedMech)
+	}
+	if len(resp.ResponseToken) == 0 {
+		return nil, fmt.Errorf("the proxy didn't send a NTLM challenge")
+	}
+	return resp.ResponseToken, nil
+}
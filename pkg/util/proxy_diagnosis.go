@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const proxyDialTimeout = 5 * time.Second
+
+func init() {
+	diagnosis.Register("Proxy configuration", diagnoseProxy)
+}
+
+// diagnoseProxy checks that the configured proxies are reachable, and logs
+// the proxy used for each intake endpoint
+func diagnoseProxy() error {
+	proxies := config.GetProxies()
+	if proxies == nil {
+		return diagnosis.Skip("no proxy configured")
+	}
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return err
+	}
+	domains := make([]string, 0, len(keysPerDomain))
+	for domain := range keysPerDomain {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	return diagnoseProxies(proxies, domains)
+}
+
+func diagnoseProxies(proxies *config.Proxy, domains []string) error {
+	for _, scheme := range []string{"http", "https"} {
+		proxy := proxies.HTTP
+		if scheme == "https" {
+			proxy = proxies.HTTPS
+		}
+		if proxy == "" {
+			continue
+		}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil || proxyURL.Host == "" {
+			return diagnosis.Fail(
+				fmt.Errorf("invalid %s proxy URL", scheme),
+				fmt.Sprintf("set proxy.%s to an URL like http://proxy.example.com:3128", scheme),
+			)
+		}
+		address := proxyURL.Host
+		if proxyURL.Port() == "" {
+			address = net.JoinHostPort(proxyURL.Hostname(), defaultPort(proxyURL.Scheme))
+		}
+		conn, err := net.DialTimeout("tcp", address, proxyDialTimeout)
+		if err != nil {
+			log.Errorf("unable to connect to the %s proxy %s: %s", scheme, address, err)
+			return diagnosis.Fail(
+				fmt.Errorf("unable to connect to the %s proxy %s", scheme, address),
+				fmt.Sprintf("make sure the proxy set in proxy.%s is up and reachable from this host", scheme),
+			)
+		}
+		conn.Close()
+		log.Infof("successfully connected to the %s proxy %s", scheme, address)
+	}
+
+	proxyFunc := GetProxyTransportFunc(proxies)
+	for _, domain := range domains {
+		req, err := http.NewRequest("GET", domain, nil)
+		if err != nil {
+			log.Warnf("invalid intake URL %s: %s", domain, err)
+			continue
+		}
+		proxyURL, err := proxyFunc(req)
+		if err != nil {
+			return diagnosis.Fail(err, "fix the proxy settings")
+		}
+		if proxyURL == nil {
+			log.Infof("%s is reached without proxy", domain)
+			if req.URL.Scheme == "https" && proxies.HTTPS == "" && proxies.HTTP != "" {
+				log.Warnf("only proxy.http is set, while %s uses https: set proxy.https to send the data through the proxy", domain)
+			}
+			continue
+		}
+		log.Infof("%s is reached through the proxy %s", domain, proxyURL.Host)
+	}
+	return nil
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
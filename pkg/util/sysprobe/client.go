@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package sysprobe queries the system-probe for the checks of the agent
+package sysprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const defaultSocketPath = "/opt/datadog-agent/run/sysprobe.sock"
+
+// SocketPath returns the path of the unix socket of the system-probe,
+// set by system_probe_config.sysprobe_socket
+func SocketPath() string {
+	socketPath := config.Datadog.GetString("system_probe_config.sysprobe_socket")
+	if socketPath == "" {
+		socketPath = defaultSocketPath
+	}
+	return socketPath
+}
+
+// NewClient returns a client to the unix socket of the system-probe
+func NewClient() *http.Client {
+	socketPath := SocketPath()
+
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:    2,
+			IdleConnTimeout: 30 * time.Second,
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", socketPath)
+			},
+		},
+	}
+}
+
+// GetCheck decodes into v the payload served by the system-probe for a check.
+// The system-probe only serves the checks enabled in system_probe_config.
+func GetCheck(client *http.Client, check string, v interface{}) error {
+	resp, err := client.Get(fmt.Sprintf("http://unix/check/%s", check))
+	if err != nil {
+		return fmt.Errorf("could not query the system-probe: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from the system-probe for the %s check", resp.StatusCode, check)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// GetDebug returns the payload served by the system-probe on one of its
+// debugging endpoints: status, stats, ebpf, offsets or errors.
+func GetDebug(client *http.Client, name string) ([]byte, error) {
+	resp, err := client.Get(fmt.Sprintf("http://unix/debug/%s", name))
+	if err != nil {
+		return nil, fmt.Errorf("could not query the system-probe: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from the system-probe for %s", resp.StatusCode, name)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// tlsVersions maps the values of `min_tls_version` to the TLS versions
+var tlsVersions = map[string]uint16{
+	"tlsv1.0": tls.VersionTLS10,
+	"tlsv1.1": tls.VersionTLS11,
+	"tlsv1.2": tls.VersionTLS12,
+	"tlsv1.3": 0x0304, // tls.VersionTLS13, only defined since go 1.12
+}
+
+// CheckTLSConfig returns an error when the TLS settings of the agent are
+// invalid, the agent refuses to start rather than connecting with weaker
+// settings than the ones configured.
+func CheckTLSConfig() error {
+	_, err := buildTLSConfig()
+	return err
+}
+
+// buildTLSConfig returns the TLS configuration of the agent transports, or
+// an error when a setting is invalid.
+func buildTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
+	}
+
+	if version := config.Datadog.GetString("min_tls_version"); version != "" {
+		v, err := parseTLSVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_tls_version: %s", err)
+		}
+		tlsConfig.MinVersion = v
+	}
+	if config.Datadog.GetBool("force_tls_12") && tlsConfig.MinVersion < tls.VersionTLS12 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if path := config.Datadog.GetString("tls_ca_bundle"); path != "" {
+		pool, err := loadCABundle(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls_ca_bundle: %s", err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certFile := config.Datadog.GetString("tls_client_cert")
+	keyFile := config.Datadog.GetString("tls_client_key")
+	if certFile != "" || keyFile != "" {
+		cert, err := loadClientCertificate(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// refusingTLSConfig returns a TLS configuration failing every handshake
+// with err, for the transports built with invalid TLS settings
+func refusingTLSConfig(err error) *tls.Config {
+	return &tls.Config{
+		VerifyPeerCertificate: func([][]byte, [][]*x509.Certificate) error {
+			return fmt.Errorf("refusing to connect with an invalid TLS configuration: %s", err)
+		},
+	}
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	if v, found := tlsVersions[strings.ToLower(version)]; found {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version '%s', valid versions are tlsv1.0, tlsv1.1, tlsv1.2 and tlsv1.3", version)
+}
+
+// loadCABundle returns the system certificate authorities along with the ones
+// of the PEM bundle, so that TLS-intercepting gateways can be trusted.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		log.Debugf("Unable to load the system certificate authorities, only trusting %s: %v", path, err)
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificate found in %s", path)
+	}
+	return pool, nil
+}
+
+func loadClientCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	if certFile == "" || keyFile == "" {
+		return tls.Certificate{}, fmt.Errorf("both tls_client_cert and tls_client_key must be set")
+	}
+	return tls.LoadX509KeyPair(certFile, keyFile)
+}
@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// writeCertificate generates a certificate for 127.0.0.1 signed by parent,
+// self-signed if parent is nil, and writes it and its key to dir.
+func writeCertificate(t *testing.T, dir, name string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return cert, key
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	v, err := parseTLSVersion("tlsv1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS11), v)
+	v, err = parseTLSVersion("TLSv1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), v)
+	v, err = parseTLSVersion("tlsv1.3")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x0304), v)
+	_, err = parseTLSVersion("sslv3")
+	assert.Error(t, err)
+}
+
+func TestBuildTLSConfigMinVersion(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("min_tls_version", "")
+	defer mockConfig.Set("force_tls_12", false)
+
+	mockConfig.Set("min_tls_version", "tlsv1.1")
+	tlsConfig, err := buildTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS11), tlsConfig.MinVersion)
+
+	// force_tls_12 takes precedence
+	mockConfig.Set("force_tls_12", true)
+	tlsConfig, err = buildTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+
+	// invalid versions are refused
+	mockConfig.Set("force_tls_12", false)
+	mockConfig.Set("min_tls_version", "sslv3")
+	_, err = buildTLSConfig()
+	assert.Error(t, err)
+	assert.Error(t, CheckTLSConfig())
+}
+
+func TestBuildTLSConfigInvalidFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	require.NoError(t, ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600))
+
+	mockConfig := config.Mock()
+	defer mockConfig.Set("tls_ca_bundle", "")
+	defer mockConfig.Set("tls_client_cert", "")
+	defer mockConfig.Set("tls_client_key", "")
+
+	mockConfig.Set("tls_ca_bundle", notPEM)
+	_, err = buildTLSConfig()
+	assert.Error(t, err)
+
+	mockConfig.Set("tls_ca_bundle", "")
+	mockConfig.Set("tls_client_cert", filepath.Join(dir, "missing.crt"))
+	_, err = buildTLSConfig()
+	assert.Error(t, err)
+	assert.Error(t, CheckTLSConfig())
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca, caKey := writeCertificate(t, dir, "ca", true, nil, nil)
+	writeCertificate(t, dir, "server", false, ca, caKey)
+	writeCertificate(t, dir, "client", false, ca, caKey)
+
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	mockConfig := config.Mock()
+	defer mockConfig.Set("tls_ca_bundle", "")
+	defer mockConfig.Set("tls_client_cert", "")
+	defer mockConfig.Set("tls_client_key", "")
+
+	// the server certificate isn't trusted
+	client := &http.Client{Transport: CreateHTTPTransport()}
+	_, err = client.Get(ts.URL)
+	assert.Error(t, err)
+
+	// the transports built with invalid settings refuse to connect, even
+	// without verifying the server certificate
+	mockConfig.Set("skip_ssl_validation", true)
+	mockConfig.Set("tls_ca_bundle", filepath.Join(dir, "missing.crt"))
+	client = &http.Client{Transport: CreateHTTPTransport()}
+	_, err = client.Get(ts.URL)
+	assert.Error(t, err)
+	mockConfig.Set("skip_ssl_validation", false)
+
+	// the server certificate is trusted but the client has no certificate
+	mockConfig.Set("tls_ca_bundle", filepath.Join(dir, "ca.crt"))
+	client = &http.Client{Transport: CreateHTTPTransport()}
+	_, err = client.Get(ts.URL)
+	assert.Error(t, err)
+
+	mockConfig.Set("tls_client_cert", filepath.Join(dir, "client.crt"))
+	mockConfig.Set("tls_client_key", filepath.Join(dir, "client.key"))
+	client = &http.Client{Transport: CreateHTTPTransport()}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
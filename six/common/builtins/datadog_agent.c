@@ -15,6 +15,7 @@ static cb_get_hostname_t cb_get_hostname = NULL;
 static cb_get_clustername_t cb_get_clustername = NULL;
 static cb_log_t cb_log = NULL;
 static cb_set_external_tags_t cb_set_external_tags = NULL;
+static cb_set_check_metadata_t cb_set_check_metadata = NULL;
 
 // forward declarations
 static PyObject *get_version(PyObject *self, PyObject *args);
@@ -24,6 +25,7 @@ static PyObject *get_hostname(PyObject *self, PyObject *args);
 static PyObject *get_clustername(PyObject *self, PyObject *args);
 static PyObject *log_message(PyObject *self, PyObject *args);
 static PyObject *set_external_tags(PyObject *self, PyObject *args);
+static PyObject *set_check_metadata(PyObject *self, PyObject *args);
 
 static PyMethodDef methods[] = {
     { "get_version", get_version, METH_NOARGS, "Get Agent version." },
@@ -33,6 +35,7 @@ static PyMethodDef methods[] = {
     { "get_clustername", get_clustername, METH_NOARGS, "Get the cluster name." },
     { "log", log_message, METH_VARARGS, "Log a message through the agent logger." },
     { "set_external_tags", set_external_tags, METH_VARARGS, "Send external host tags." },
+    { "set_check_metadata", set_check_metadata, METH_VARARGS, "Set metadata of a check instance." },
     { NULL, NULL } // guards
 };
 
@@ -90,6 +93,11 @@ void _set_set_external_tags_cb(cb_set_external_tags_t cb)
     cb_set_external_tags = cb;
 }
 
+void _set_set_check_metadata_cb(cb_set_check_metadata_t cb)
+{
+    cb_set_check_metadata = cb;
+}
+
 PyObject *get_version(PyObject *self, PyObject *args)
 {
     if (cb_get_version == NULL) {
@@ -246,6 +254,24 @@ static PyObject *log_message(PyObject *self, PyObject *args)
     Py_RETURN_NONE;
 }
 
+static PyObject *set_check_metadata(PyObject *self, PyObject *args)
+{
+    // callback must be set
+    if (cb_set_check_metadata == NULL) {
+        Py_RETURN_NONE;
+    }
+
+    char *check_id, *name, *value;
+
+    // datadog_agent.set_check_metadata(check_id, name, value)
+    if (!PyArg_ParseTuple(args, "sss", &check_id, &name, &value)) {
+        return NULL;
+    }
+
+    cb_set_check_metadata(check_id, name, value);
+    Py_RETURN_NONE;
+}
+
 // set_external_tags receive the following data:
 // [('hostname', {'source_type': ['tag1', 'tag2']})]
 static PyObject *set_external_tags(PyObject *self, PyObject *args)
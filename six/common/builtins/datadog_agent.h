@@ -29,6 +29,7 @@ void _set_get_hostname_cb(cb_get_hostname_t);
 void _set_get_clustername_cb(cb_get_clustername_t);
 void _set_log_cb(cb_log_t);
 void _set_set_external_tags_cb(cb_set_external_tags_t);
+void _set_set_check_metadata_cb(cb_set_check_metadata_t);
 
 // provide a non-static entry point for the `headers` method; headers is duplicated
 // in the `util` module; allow it to be called directly
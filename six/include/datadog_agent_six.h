@@ -67,6 +67,7 @@ DATADOG_AGENT_SIX_API void set_get_hostname_cb(six_t *, cb_get_hostname_t);
 DATADOG_AGENT_SIX_API void set_get_clustername_cb(six_t *, cb_get_clustername_t);
 DATADOG_AGENT_SIX_API void set_log_cb(six_t *, cb_log_t);
 DATADOG_AGENT_SIX_API void set_set_external_tags_cb(six_t *, cb_set_external_tags_t);
+DATADOG_AGENT_SIX_API void set_set_check_metadata_cb(six_t *, cb_set_check_metadata_t);
 
 // _UTIL API
 DATADOG_AGENT_SIX_API void set_get_subprocess_output_cb(six_t *six, cb_get_subprocess_output_t cb);
@@ -72,6 +72,7 @@ public:
     virtual void setGetClusternameCb(cb_get_clustername_t) = 0;
     virtual void setLogCb(cb_log_t) = 0;
     virtual void setSetExternalTagsCb(cb_set_external_tags_t) = 0;
+    virtual void setSetCheckMetadataCb(cb_set_check_metadata_t) = 0;
 
     // _util API
     virtual void setSubprocessOutputCb(cb_get_subprocess_output_t) = 0;
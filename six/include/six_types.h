@@ -94,6 +94,8 @@ typedef void (*cb_get_clustername_t)(char **);
 typedef void (*cb_log_t)(char *, int);
 // (hostname, source_type_name, list of tags)
 typedef void (*cb_set_external_tags_t)(char *, char *, char **);
+// (check_id, name, value)
+typedef void (*cb_set_check_metadata_t)(char *, char *, char *);
 
 // _util
 // (argv, argc, raise, stdout, stderr, ret_code, exception)
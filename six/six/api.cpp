@@ -363,6 +363,11 @@ void set_set_external_tags_cb(six_t *six, cb_set_external_tags_t cb)
     AS_TYPE(Six, six)->setSetExternalTagsCb(cb);
 }
 
+void set_set_check_metadata_cb(six_t *six, cb_set_check_metadata_t cb)
+{
+    AS_TYPE(Six, six)->setSetCheckMetadataCb(cb);
+}
+
 char *get_integration_list(six_t *six)
 {
     return AS_TYPE(Six, six)->getIntegrationList();
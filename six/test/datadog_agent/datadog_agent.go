@@ -26,6 +26,7 @@ import (
 // extern void getClustername(char **);
 // extern void doLog(char*, int);
 // extern void setExternalHostTags(char*, char*, char**);
+// extern void setCheckMetadata(char*, char*, char*);
 //
 // static void initDatadogAgentTests(six_t *six) {
 //    set_get_version_cb(six, getVersion);
@@ -35,6 +36,7 @@ import (
 //    set_get_clustername_cb(six, getClustername);
 //    set_log_cb(six, doLog);
 //    set_set_external_tags_cb(six, setExternalHostTags);
+//    set_set_check_metadata_cb(six, setCheckMetadata);
 // }
 import "C"
 
@@ -183,3 +185,9 @@ func setExternalHostTags(hostname *C.char, sourceType *C.char, tags **C.char) {
 	f.WriteString(strings.Join(tagsStrings, ","))
 	f.WriteString("\n")
 }
+
+//export setCheckMetadata
+func setCheckMetadata(checkID, name, value *C.char) {
+	data := []byte(strings.Join([]string{C.GoString(checkID), C.GoString(name), C.GoString(value)}, ","))
+	ioutil.WriteFile(tmpfile.Name(), data, 0644)
+}
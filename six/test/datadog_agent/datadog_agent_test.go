@@ -3,6 +3,7 @@ package testdatadogagent
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -120,6 +121,32 @@ func TestLog(t *testing.T) {
 	}
 }
 
+func TestSetCheckMetadata(t *testing.T) {
+	code := `
+	datadog_agent.set_check_metadata("redis:123", "version.raw", "5.0.6")
+	`
+	out, err := run(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "redis:123,version.raw,5.0.6" {
+		t.Errorf("Unexpected printed value: '%s'", out)
+	}
+}
+
+func TestSetCheckMetadataNotString(t *testing.T) {
+	code := `
+	datadog_agent.set_check_metadata("redis:123", "version.raw", 5)
+	`
+	out, err := run(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "TypeError:") {
+		t.Errorf("Unexpected printed value: '%s'", out)
+	}
+}
+
 func TestSetExternalTags(t *testing.T) {
 	code := `
 	tags = [
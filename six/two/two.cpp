@@ -732,6 +732,11 @@ void Two::setSetExternalTagsCb(cb_set_external_tags_t cb)
     _set_set_external_tags_cb(cb);
 }
 
+void Two::setSetCheckMetadataCb(cb_set_check_metadata_t cb)
+{
+    _set_set_check_metadata_cb(cb);
+}
+
 void Two::setSubprocessOutputCb(cb_get_subprocess_output_t cb)
 {
     _set_get_subprocess_output_cb(cb);
@@ -59,6 +59,7 @@ public:
     void setGetClusternameCb(cb_get_clustername_t);
     void setLogCb(cb_log_t);
     void setSetExternalTagsCb(cb_set_external_tags_t);
+    void setSetCheckMetadataCb(cb_set_check_metadata_t);
 
     // _util API
     virtual void setSubprocessOutputCb(cb_get_subprocess_output_t);
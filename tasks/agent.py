@@ -53,12 +53,25 @@ AGENT_CORECHECKS = [
     "go_expvar",
     "io",
     "jmx",
+    "journald",
+    "kernel_limits",
+    "kubelet_summary",
     "kubernetes_apiserver",
+    "live_processes",
     "load",
     "memory",
     "ntp",
+    "nvml",
+    "oom_kill",
+    "sensors",
+    "snmp_core",
+    "synthetic",
+    "tcp_queue_length",
+    "tls",
     "uptime",
+    "wineventlog",
     "winproc",
+    "winservices",
 ]
 
 PUPPY_CORECHECKS = [
@@ -69,16 +82,19 @@ PUPPY_CORECHECKS = [
     "memory",
     "network",
     "ntp",
+    "oom_kill",
     "uptime",
 ]
 
 @task
 def build(ctx, rebuild=False, race=False, build_include=None, build_exclude=None,
           puppy=False, development=True, precompile_only=False, skip_assets=False,
-          embedded_path=None, six_root=None, python_home_2=None, python_home_3=None):
+          embedded_path=None, six_root=None, python_home_2=None, python_home_3=None,
+          fips=False):
     """
     Build the agent. If the bits to include in the build are not specified,
-    the values from `invoke.yaml` will be used.
+    the values from `invoke.yaml` will be used. With `--fips`, the agent is
+    built in FIPS mode, it requires a Go toolchain backed by BoringCrypto.
 
     Example invokation:
         inv agent.build --build-exclude=systemd
@@ -126,6 +142,10 @@ def build(ctx, rebuild=False, race=False, build_include=None, build_exclude=None
     else:
         build_tags = get_build_tags(build_include, build_exclude)
 
+    if fips:
+        # not part of ALL_TAGS, the FIPS mode is never built by default
+        build_tags = list(build_tags) + ["fips"]
+
     cmd = "go build {race_opt} {build_type} -tags \"{go_build_tags}\" "
 
     cmd += "-o {agent_bin} -gcflags=\"{gcflags}\" -ldflags=\"{ldflags}\" {REPO_PATH}/cmd/agent"
@@ -350,3 +370,18 @@ def version(ctx, url_safe=False, git_sha_length=7):
                     (the windows builder and the default ubuntu version have such an incompatibility)
     """
     print(get_version(ctx, include_git=True, url_safe=url_safe, git_sha_length=git_sha_length))
+
+
+@task
+def protobuf(ctx):
+    """
+    Compile the protobuf files of the gRPC api between the agent processes
+    """
+    cmd = "protoc {proto_dir}/{proto} -I {gopath}/src -I vendor -I {proto_dir} --gogofaster_out=plugins=grpc:{gopath}/src"
+    protos = [
+        (os.path.join(".", "pkg", "tagger", "pb"), "tagger.proto"),
+        (os.path.join(".", "pkg", "api", "pb"), "api.proto"),
+    ]
+
+    for proto_dir, proto in protos:
+        ctx.run(cmd.format(gopath=os.environ["GOPATH"], proto_dir=proto_dir, proto=proto))
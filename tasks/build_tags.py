@@ -21,14 +21,23 @@ ALL_TAGS = set([
     "kubeapiserver",
     "kubelet",
     "log",
+    "nvml",
     "netcgo", # Force the use of the CGO resolver. This will also have the effect of making the binary non-static
     "process",
     "systemd",
     "zk",
     "zlib",
+    "zstd",
     "secrets",
 ])
 
+# OPT_IN_TAGS lists the tags that "all" doesn't enable, they're only enabled
+# when explicitly included
+OPT_IN_TAGS = set([
+    "nvml", # links the NVIDIA Management Library bindings
+    "zstd", # the intake of the payloads must accept zstd
+])
+
 # PUPPY_TAGS lists the tags needed when building the Puppy Agent
 PUPPY_TAGS = set([
     "zlib",
@@ -41,6 +50,7 @@ LINUX_ONLY_TAGS = [
     "cri",
     "containerd",
     "netcgo",
+    "nvml",
 ]
 
 REDHAT_AND_DEBIAN_ONLY_TAGS = [
@@ -83,7 +93,8 @@ def get_build_tags(include, exclude):
     """
     # special case, include == all
     if "all" in include:
-        return list(ALL_TAGS - set(exclude))
+        opt_in = OPT_IN_TAGS.intersection(set(include))
+        return list((ALL_TAGS - OPT_IN_TAGS).union(opt_in) - set(exclude))
 
     # filter out unrecognised tags
     include = ALL_TAGS.intersection(set(include))
@@ -98,7 +109,7 @@ def audit_tag_impact(ctx, build_exclude=None, csv=False):
     """
     build_exclude = [] if build_exclude is None else build_exclude.split(",")
 
-    tags_to_audit = ALL_TAGS.difference(set(build_exclude)).difference(set(PUPPY_TAGS))
+    tags_to_audit = ALL_TAGS.difference(set(build_exclude)).difference(set(PUPPY_TAGS)).difference(OPT_IN_TAGS)
 
     max_size = _compute_build_size(ctx, build_exclude=','.join(build_exclude))
     print("size with all tags is {} kB".format(max_size / 1000))
@@ -83,7 +83,8 @@ def protobuf(ctx):
             )
         )
 
-    cmd = "protoc {proto_dir}/agent.proto -I {gopath}/src -I vendor -I {proto_dir} --gogofaster_out {gopath}/src"
+    cmd = "protoc {proto_dir}/{proto_file} -I {gopath}/src -I vendor -I {proto_dir} --gogofaster_out {gopath}/src"
     proto_dir = os.path.join(".", "pkg", "process", "proto")
 
-    ctx.run(cmd.format(gopath=os.environ["GOPATH"], proto_dir=proto_dir))
+    for proto_file in ["agent.proto", "orchestrator.proto"]:
+        ctx.run(cmd.format(gopath=os.environ["GOPATH"], proto_dir=proto_dir, proto_file=proto_file))
@@ -289,9 +289,10 @@ shared_examples_for "a running Agent with no errors" do
     end
 
     json_info_output = json_info
-    expect(json_info_output).to have_key("runnerStats")
-    expect(json_info_output['runnerStats']).to have_key("Checks")
-    expect(json_info_output['runnerStats']['Checks']).not_to be_empty
+    expect(json_info_output).to have_key("schema_version")
+    expect(json_info_output).to have_key("collector")
+    expect(json_info_output['collector']).to have_key("checks")
+    expect(json_info_output['collector']['checks']).not_to be_empty
   end
 
   it 'has an info command' do